@@ -0,0 +1,768 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/featureflag"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+)
+
+const compactBackupOp = "COMPACT BACKUP"
+
+// compactBackupPlanHook implements sql.PlanHookFn.
+func compactBackupPlanHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	compactStmt, ok := stmt.(*tree.CompactBackup)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	if err := featureflag.CheckEnabled(
+		ctx,
+		p.ExecCfg(),
+		featureBackupEnabled,
+		compactBackupOp,
+	); err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	subdirFn, err := p.TypeAsString(ctx, compactStmt.Subdir, compactBackupOp)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	collectionFn, err := p.TypeAsString(ctx, compactStmt.InCollection, compactBackupOp)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	expected := map[string]sql.KVStringOptValidate{
+		backupOptEncPassphrase: sql.KVStringOptRequireValue,
+		backupOptEncKMS:        sql.KVStringOptRequireValue,
+		backupOptDetached:      sql.KVStringOptRequireNoValue,
+	}
+	optsFn, err := p.TypeAsStringOpts(ctx, compactStmt.Options, expected)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	// Whether the job runs detached determines the result columns, so it has to
+	// be known before the options are evaluated.
+	var detached bool
+	for _, opt := range compactStmt.Options {
+		if string(opt.Key) == backupOptDetached {
+			detached = true
+		}
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer span.Finish()
+
+		if !(p.ExtendedEvalContext().TxnImplicit || detached) {
+			return errors.Errorf("%s cannot be used inside a transaction without DETACHED option",
+				compactBackupOp)
+		}
+
+		if err := p.RequireAdminRole(ctx, compactBackupOp); err != nil {
+			return err
+		}
+		if err := utilccl.CheckEnterpriseEnabled(
+			p.ExecCfg().Settings, p.ExecCfg().ClusterID(), p.ExecCfg().Organization(), compactBackupOp,
+		); err != nil {
+			return err
+		}
+		// Nodes running older versions can't run the compaction job.
+		if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.BackupCompaction) {
+			return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+				`%s requires all nodes to be upgraded to %s`,
+				compactBackupOp, clusterversion.ByKey(clusterversion.BackupCompaction))
+		}
+
+		subdir, err := subdirFn()
+		if err != nil {
+			return err
+		}
+		collection, err := collectionFn()
+		if err != nil {
+			return err
+		}
+		opts, err := optsFn()
+		if err != nil {
+			return err
+		}
+
+		encryptionParams := backupEncryptionParams{encryptMode: noEncryption}
+		if pw, ok := opts[backupOptEncPassphrase]; ok {
+			encryptionParams.encryptMode = passphrase
+			encryptionParams.encryptionPassphrase = []byte(pw)
+		}
+		if kmsURI, ok := opts[backupOptEncKMS]; ok {
+			if encryptionParams.encryptMode != noEncryption {
+				return errors.New("cannot have both encryption_passphrase and kms option set")
+			}
+			encryptionParams.encryptMode = kms
+			encryptionParams.kmsURIs = []string{kmsURI}
+			encryptionParams.kmsEnv = &backupKMSEnv{
+				settings: p.ExecCfg().Settings,
+				conf:     &p.ExecCfg().ExternalIODirConfig,
+			}
+		}
+
+		mkStore := p.ExecCfg().DistSQLSrv.ExternalStorageFromURI
+		baseURI, err := appendPathToURI(collection, subdir)
+		if err != nil {
+			return err
+		}
+		encryption, err := getEncryptionFromBase(ctx, p.User(), mkStore, baseURI, encryptionParams)
+		if err != nil {
+			return err
+		}
+
+		manifests, err := loadBackupChainForCompaction(ctx, mkStore, p.User(), baseURI, encryption)
+		if err != nil {
+			return err
+		}
+		if len(manifests) < 2 {
+			return errors.Errorf("backup %s has no incremental layers to compact", subdir)
+		}
+		endTime := manifests[len(manifests)-1].EndTime
+
+		// The compacted backup is named the way BACKUP INTO would have named a
+		// full backup taken at the end time of the chain.
+		destSubdir := endTime.GoTime().Format(dateBasedIntoFolderName)
+		destURI, err := appendPathToURI(collection, destSubdir)
+		if err != nil {
+			return err
+		}
+		if err := func() error {
+			destStore, err := mkStore(ctx, destURI, p.User())
+			if err != nil {
+				return err
+			}
+			defer destStore.Close()
+			return checkForPreviousBackup(ctx, destStore, destURI)
+		}(); err != nil {
+			return err
+		}
+
+		description, err := compactBackupJobDescription(p, compactStmt, collection, opts)
+		if err != nil {
+			return err
+		}
+		jr := jobs.Record{
+			Description: description,
+			Username:    p.User(),
+			Details: jobspb.BackupCompactionDetails{
+				CollectionURI: collection,
+				Subdir:        subdir,
+				DestSubdir:    destSubdir,
+				StartTime:     manifests[0].StartTime,
+				EndTime:       endTime,
+				Encryption:    encryption,
+			},
+			Progress: jobspb.BackupCompactionProgress{},
+		}
+
+		if detached {
+			aj, err := p.ExecCfg().JobRegistry.CreateAdoptableJobWithTxn(
+				ctx, jr, p.ExtendedEvalContext().Txn)
+			if err != nil {
+				return err
+			}
+			resultsCh <- tree.Datums{tree.NewDInt(tree.DInt(*aj.ID()))}
+			return nil
+		}
+
+		var sj *jobs.StartableJob
+		if err := p.ExecCfg().DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) (err error) {
+			sj, err = p.ExecCfg().JobRegistry.CreateStartableJobWithTxn(ctx, jr, txn, resultsCh)
+			return err
+		}); err != nil {
+			if sj != nil {
+				if cleanupErr := sj.CleanupOnRollback(ctx); cleanupErr != nil {
+					log.Warningf(ctx, "failed to cleanup StartableJob: %v", cleanupErr)
+				}
+			}
+			return err
+		}
+		return sj.Run(ctx)
+	}
+
+	if detached {
+		return fn, utilccl.DetachedJobExecutionResultHeader, nil, false, nil
+	}
+	return fn, utilccl.BulkJobExecutionResultHeader, nil, false, nil
+}
+
+// compactBackupJobDescription renders the statement of a backup compaction job
+// with the storage and KMS credentials and the passphrase redacted.
+func compactBackupJobDescription(
+	p sql.PlanHookState, stmt *tree.CompactBackup, collection string, opts map[string]string,
+) (string, error) {
+	sanitizedCollection, err := cloudimpl.SanitizeExternalStorageURI(collection, nil /* extraParams */)
+	if err != nil {
+		return "", err
+	}
+	c := &tree.CompactBackup{
+		Subdir:       stmt.Subdir,
+		InCollection: tree.NewDString(sanitizedCollection),
+	}
+	for _, opt := range stmt.Options {
+		switch string(opt.Key) {
+		case backupOptEncPassphrase:
+			opt.Value = tree.NewDString("redacted")
+		case backupOptEncKMS:
+			redactedURI, err := cloudimpl.RedactKMSURI(opts[backupOptEncKMS])
+			if err != nil {
+				return "", err
+			}
+			opt.Value = tree.NewDString(redactedURI)
+		}
+		c.Options = append(c.Options, opt)
+	}
+	ann := p.ExtendedEvalContext().Annotations
+	return tree.AsStringWithFQNames(c, ann), nil
+}
+
+// appendPathToURI returns uri with p appended to its path.
+func appendPathToURI(uri, p string) (string, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	parsed.Path = path.Join(parsed.Path, p)
+	return parsed.String(), nil
+}
+
+// loadBackupChainForCompaction reads the manifests of the full backup at
+// baseURI and of the incremental layers appended to it, in order. The Dir of
+// each manifest points at the directory holding the files of its layer.
+func loadBackupChainForCompaction(
+	ctx context.Context,
+	mkStore cloud.ExternalStorageFromURIFactory,
+	user security.SQLUsername,
+	baseURI string,
+	encryption *jobspb.BackupEncryptionOptions,
+) ([]BackupManifest, error) {
+	baseStore, err := mkStore(ctx, baseURI, user)
+	if err != nil {
+		return nil, err
+	}
+	defer baseStore.Close()
+
+	full, err := readBackupManifestFromStore(ctx, baseStore, encryption)
+	if err != nil {
+		return nil, err
+	}
+	incDirs, err := findPriorBackupLocations(ctx, baseStore)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := []BackupManifest{full}
+	for _, incDir := range incDirs {
+		incURI, err := appendPathToURI(baseURI, incDir)
+		if err != nil {
+			return nil, err
+		}
+		m, err := ReadBackupManifestFromURI(ctx, incURI, user, mkStore, encryption)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading incremental backup %s", incDir)
+		}
+		manifests = append(manifests, m)
+	}
+
+	for i := range manifests {
+		m := &manifests[i]
+		if len(m.PartitionDescriptorFilenames) > 0 || len(m.LocalityKVs) > 0 {
+			return nil, errors.New("cannot compact locality-aware backups")
+		}
+		if m.MVCCFilter != full.MVCCFilter {
+			return nil, errors.New(
+				"cannot compact a backup whose layers do not all have the same revision_history setting")
+		}
+		// Statistics are not needed to compact a chain and can be large.
+		if i < len(manifests)-1 {
+			m.DeprecatedStatistics = nil
+		}
+	}
+	return manifests, nil
+}
+
+type backupCompactionResumer struct {
+	job *jobs.Job
+}
+
+var _ jobs.Resumer = &backupCompactionResumer{}
+
+// Resume is part of the jobs.Resumer interface.
+func (r *backupCompactionResumer) Resume(
+	ctx context.Context, execCtx interface{}, resultsCh chan<- tree.Datums,
+) error {
+	details := r.job.Details().(jobspb.BackupCompactionDetails)
+	p := execCtx.(sql.JobExecContext)
+
+	res, err := compactBackupChain(ctx, p.ExecCfg(), p.User(), r.job, details)
+	if err != nil {
+		return err
+	}
+
+	resultsCh <- tree.Datums{
+		tree.NewDInt(tree.DInt(*r.job.ID())),
+		tree.NewDString(string(jobs.StatusSucceeded)),
+		tree.NewDFloat(tree.DFloat(1.0)),
+		tree.NewDInt(tree.DInt(res.Rows)),
+		tree.NewDInt(tree.DInt(res.IndexEntries)),
+		tree.NewDInt(tree.DInt(res.DataSize)),
+	}
+	return nil
+}
+
+// OnFailOrCancel is part of the jobs.Resumer interface. The files of a
+// compacted backup are only referenced once its manifest has been written, so
+// a failed compaction leaves nothing behind that a restore could pick up.
+func (r *backupCompactionResumer) OnFailOrCancel(context.Context, interface{}) error {
+	return nil
+}
+
+// compactBackupChain writes the backup chain described by details out as a
+// single full backup. Only the external storage holding the chain is read, so
+// the compaction does not depend on the cluster still holding the data that
+// was backed up.
+func compactBackupChain(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	user security.SQLUsername,
+	job *jobs.Job,
+	details jobspb.BackupCompactionDetails,
+) (RowCount, error) {
+	mkStore := execCfg.DistSQLSrv.ExternalStorageFromURI
+	baseURI, err := appendPathToURI(details.CollectionURI, details.Subdir)
+	if err != nil {
+		return RowCount{}, err
+	}
+	destURI, err := appendPathToURI(details.CollectionURI, details.DestSubdir)
+	if err != nil {
+		return RowCount{}, err
+	}
+	destStore, err := mkStore(ctx, destURI, user)
+	if err != nil {
+		return RowCount{}, err
+	}
+	defer destStore.Close()
+
+	// The manifest is written last, so if it exists a previous attempt of this
+	// job has already completed the compaction.
+	if exists, err := containsManifest(ctx, destStore); err != nil {
+		return RowCount{}, err
+	} else if exists {
+		m, err := readBackupManifestFromStore(ctx, destStore, details.Encryption)
+		if err != nil {
+			return RowCount{}, err
+		}
+		return m.EntryCounts, nil
+	}
+
+	manifests, err := loadBackupChainForCompaction(ctx, mkStore, user, baseURI, details.Encryption)
+	if err != nil {
+		return RowCount{}, err
+	}
+	// Layers may have been appended to the chain since the job was planned.
+	for i := range manifests {
+		if manifests[i].EndTime == details.EndTime {
+			manifests = manifests[:i+1]
+			break
+		}
+	}
+	last := manifests[len(manifests)-1]
+	if last.EndTime != details.EndTime {
+		return RowCount{}, errors.Errorf("backup %s no longer has a layer ending at %s",
+			details.Subdir, details.EndTime)
+	}
+
+	var encryptionKey []byte
+	if details.Encryption != nil {
+		encryptionKey, err = getEncryptionKey(ctx, details.Encryption, execCfg.Settings,
+			destStore.ExternalIOConf())
+		if err != nil {
+			return RowCount{}, err
+		}
+	}
+
+	importSpans, _, err := makeImportSpans(last.Spans, manifests, nil, /* backupLocalityInfo */
+		keys.MinKey, user, errOnMissingRange)
+	if err != nil {
+		return RowCount{}, errors.Wrapf(err, "making import requests for %d backups", len(manifests))
+	}
+
+	pkIDs := make(map[uint64]bool)
+	for i := range last.Descriptors {
+		if t := descpb.TableFromDescriptor(&last.Descriptors[i], hlc.Timestamp{}); t != nil {
+			pkIDs[roachpb.BulkOpSummaryID(uint64(t.ID), uint64(t.PrimaryIndex.ID))] = true
+		}
+	}
+
+	w := &compactedBackupWriter{
+		store:          destStore,
+		factory:        execCfg.DistSQLSrv.ExternalStorage,
+		encryptionKey:  encryptionKey,
+		allRevisions:   last.MVCCFilter == MVCCFilter_All,
+		targetFileSize: storageccl.ExportRequestTargetFileSize.Get(&execCfg.Settings.SV),
+		pkIDs:          pkIDs,
+	}
+	defer w.close()
+	for i, entry := range importSpans {
+		flushed, err := w.addEntry(ctx, entry)
+		if err != nil {
+			return RowCount{}, err
+		}
+		if flushed {
+			if err := job.FractionProgressed(ctx,
+				jobs.FractionUpdater(float32(i+1)/float32(len(importSpans)))); err != nil {
+				log.Warningf(ctx, "failed to update job progress: %v", err)
+			}
+		}
+	}
+	if err := w.flush(ctx); err != nil {
+		return RowCount{}, err
+	}
+
+	compacted := last
+	compacted.StartTime = manifests[0].StartTime
+	compacted.RevisionStartTime = manifests[0].RevisionStartTime
+	compacted.IntroducedSpans = nil
+	compacted.Files = w.files
+	compacted.EntryCounts = RowCount{}
+	for _, f := range w.files {
+		compacted.EntryCounts.add(f.EntryCounts)
+	}
+	compacted.Dir = roachpb.ExternalStorage{}
+	compacted.ID = uuid.MakeV4()
+	compacted.BuildInfo = build.GetInfo()
+	if compacted.MVCCFilter == MVCCFilter_All {
+		compacted.DescriptorChanges = nil
+		for i := range manifests {
+			compacted.DescriptorChanges = append(compacted.DescriptorChanges, manifests[i].DescriptorChanges...)
+		}
+	}
+
+	if err := copyBackupMetadataForCompaction(ctx, execCfg, user, baseURI, last, destStore); err != nil {
+		return RowCount{}, err
+	}
+	if err := writeBackupManifest(ctx, execCfg.Settings, destStore, backupManifestName,
+		details.Encryption, &compacted); err != nil {
+		return RowCount{}, err
+	}
+	return compacted.EntryCounts, nil
+}
+
+// copyBackupMetadataForCompaction copies the encryption info of the full
+// backup and the table statistics of the last layer of a chain to the
+// directory of its compacted backup. The statistics are copied as is, since
+// they are encrypted with the same key the compacted backup uses.
+func copyBackupMetadataForCompaction(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	user security.SQLUsername,
+	baseURI string,
+	last BackupManifest,
+	dest cloud.ExternalStorage,
+) error {
+	baseStore, err := execCfg.DistSQLSrv.ExternalStorageFromURI(ctx, baseURI, user)
+	if err != nil {
+		return err
+	}
+	defer baseStore.Close()
+	if encInfo, err := readEncryptionOptions(ctx, baseStore); err == nil {
+		if err := writeEncryptionInfoIfNotExists(ctx, encInfo, dest); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, cloudimpl.ErrFileDoesNotExist) {
+		return err
+	}
+
+	lastStore, err := execCfg.DistSQLSrv.ExternalStorage(ctx, last.Dir)
+	if err != nil {
+		return err
+	}
+	defer lastStore.Close()
+	copied := make(map[string]struct{})
+	for _, filename := range last.StatisticsFilenames {
+		if _, ok := copied[filename]; ok {
+			continue
+		}
+		copied[filename] = struct{}{}
+		if err := func() error {
+			r, err := lastStore.ReadFile(ctx, filename)
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+			contents, err := ioutil.ReadAll(r)
+			if err != nil {
+				return err
+			}
+			return dest.WriteFile(ctx, filename, bytes.NewReader(contents))
+		}(); err != nil {
+			return errors.Wrapf(err, "copying %s", filename)
+		}
+	}
+	return nil
+}
+
+// compactedBackupWriter merges the files of the layers of a backup chain, one
+// import span at a time, into the SSTs of a full backup.
+type compactedBackupWriter struct {
+	store          cloud.ExternalStorage
+	factory        cloud.ExternalStorageFactory
+	encryptionKey  []byte
+	allRevisions   bool
+	targetFileSize int64
+	pkIDs          map[uint64]bool
+
+	// files are the files written so far.
+	files []BackupManifest_File
+
+	// The SST currently being built, the span it covers and the rows in it.
+	sstFile *storage.MemFile
+	sst     storage.SSTWriter
+	span    roachpb.Span
+	rows    storage.RowCounter
+	// prev is the last key added to the SST. The same revision of a key may be
+	// held by more than one layer, but it can only be written once.
+	prev storage.MVCCKey
+}
+
+// addEntry adds the merged contents of the files of entry to the compacted
+// backup, and reports whether this caused a file to be written out.
+func (w *compactedBackupWriter) addEntry(
+	ctx context.Context, entry execinfrapb.RestoreSpanEntry,
+) (bool, error) {
+	var flushed bool
+	// Keep the span of each file contiguous.
+	if w.sst.DataSize > 0 && !entry.Span.Key.Equal(w.span.EndKey) {
+		if err := w.flush(ctx); err != nil {
+			return false, err
+		}
+		flushed = true
+	}
+	if w.sstFile == nil {
+		w.sstFile = &storage.MemFile{}
+		w.sst = storage.MakeBackupSSTWriter(w.sstFile)
+	}
+	if w.sst.DataSize == 0 {
+		w.span.Key = entry.Span.Key
+	}
+
+	var iters []storage.SimpleMVCCIterator
+	defer func() {
+		for _, iter := range iters {
+			iter.Close()
+		}
+	}()
+	for _, file := range entry.Files {
+		iter, err := w.openFile(ctx, file)
+		if err != nil {
+			return false, err
+		}
+		iters = append(iters, iter)
+	}
+
+	iter := storage.MakeMultiIterator(iters)
+	defer iter.Close()
+	endKeyMVCC := storage.MVCCKey{Key: entry.Span.EndKey}
+	for iter.SeekGE(storage.MVCCKey{Key: entry.Span.Key}); ; {
+		ok, err := iter.Valid()
+		if err != nil {
+			return false, err
+		}
+		if !ok || !iter.UnsafeKey().Less(endKeyMVCC) {
+			break
+		}
+		key, value := iter.UnsafeKey(), iter.UnsafeValue()
+		// Unless all revisions are kept, only the newest revision of each key is
+		// kept, and deleted keys are left out entirely.
+		if (w.allRevisions || len(value) > 0) && !key.Equal(w.prev) {
+			if err := w.put(key, value); err != nil {
+				return false, err
+			}
+		}
+		if w.allRevisions {
+			iter.Next()
+		} else {
+			iter.NextKey()
+		}
+	}
+	w.span.EndKey = entry.Span.EndKey
+
+	if w.sst.DataSize >= w.targetFileSize {
+		if err := w.flush(ctx); err != nil {
+			return false, err
+		}
+		flushed = true
+	}
+	return flushed, nil
+}
+
+func (w *compactedBackupWriter) put(key storage.MVCCKey, value []byte) error {
+	if err := w.rows.Count(key.Key); err != nil {
+		return errors.Wrapf(err, "decoding %s", key)
+	}
+	if key.Timestamp.IsEmpty() {
+		if err := w.sst.PutUnversioned(key.Key, value); err != nil {
+			return errors.Wrapf(err, "adding key %s", key)
+		}
+	} else {
+		if err := w.sst.PutMVCC(key, value); err != nil {
+			return errors.Wrapf(err, "adding key %s", key)
+		}
+	}
+	w.prev.Key = append(w.prev.Key[:0], key.Key...)
+	w.prev.Timestamp = key.Timestamp
+	return nil
+}
+
+// openFile reads, decrypts and verifies a file of a layer of the chain.
+func (w *compactedBackupWriter) openFile(
+	ctx context.Context, file roachpb.ImportRequest_File,
+) (storage.SimpleMVCCIterator, error) {
+	dir, err := w.factory(ctx, file.Dir)
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+
+	const maxAttempts = 3
+	var fileContents []byte
+	if err := retry.WithMaxAttempts(ctx, base.DefaultRetryOptions(), maxAttempts, func() error {
+		f, err := dir.ReadFile(ctx, file.Path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		fileContents, err = ioutil.ReadAll(f)
+		return err
+	}); err != nil {
+		return nil, errors.Wrapf(err, "fetching %q", file.Path)
+	}
+
+	if w.encryptionKey != nil {
+		fileContents, err = storageccl.DecryptFile(fileContents, w.encryptionKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(file.Sha512) > 0 {
+		checksum, err := storageccl.SHA512ChecksumData(fileContents)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(checksum, file.Sha512) {
+			return nil, errors.Errorf("checksum mismatch for %s", file.Path)
+		}
+	}
+	return storage.NewMemSSTIterator(fileContents, false)
+}
+
+// flush writes the SST being built, if it is not empty, to the destination.
+func (w *compactedBackupWriter) flush(ctx context.Context) error {
+	if w.sstFile == nil || w.sst.DataSize == 0 {
+		return nil
+	}
+	if err := w.sst.Finish(); err != nil {
+		return err
+	}
+	data := w.sstFile.Data()
+	checksum, err := storageccl.SHA512ChecksumData(data)
+	if err != nil {
+		return err
+	}
+	if w.encryptionKey != nil {
+		data, err = storageccl.EncryptFile(data, w.encryptionKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The files are named by their position in the compacted backup, so that a
+	// retried job overwrites the files of the attempt before it.
+	filename := fmt.Sprintf("%d.sst", len(w.files)+1)
+	const maxAttempts = 3
+	if err := retry.WithMaxAttempts(ctx, base.DefaultRetryOptions(), maxAttempts, func() error {
+		return w.store.WriteFile(ctx, filename, bytes.NewReader(data))
+	}); err != nil {
+		return errors.Wrapf(err, "writing %q", filename)
+	}
+
+	w.rows.BulkOpSummary.DataSize = w.sst.DataSize
+	w.files = append(w.files, BackupManifest_File{
+		Span:        w.span,
+		Path:        filename,
+		Sha512:      checksum,
+		EntryCounts: countRows(w.rows.BulkOpSummary, w.pkIDs),
+	})
+	w.close()
+	w.sstFile = nil
+	w.span = roachpb.Span{}
+	w.rows = storage.RowCounter{}
+	return nil
+}
+
+func (w *compactedBackupWriter) close() {
+	if w.sstFile != nil {
+		w.sst.Close()
+	}
+}
+
+func init() {
+	sql.AddPlanHook(compactBackupPlanHook)
+	jobs.RegisterConstructor(
+		jobspb.TypeBackupCompaction,
+		func(job *jobs.Job, _ *cluster.Settings) jobs.Resumer {
+			return &backupCompactionResumer{
+				job: job,
+			}
+		},
+	)
+}
@@ -59,6 +59,8 @@ const (
 	backupOptEncPassphrase   = "encryption_passphrase"
 	backupOptEncKMS          = "kms"
 	backupOptWithPrivileges  = "privileges"
+	backupOptCheckData       = "check_data"
+	backupOptDetached        = "detached"
 	localityURLParam         = "COCKROACH_LOCALITY"
 	defaultLocalityValue     = "default"
 )
@@ -674,6 +676,36 @@ func checkPrivilegesForBackup(
 	return nil
 }
 
+// checkPrivilegesForKMS checks that none of the KMS URIs rely on implicit
+// access, such as a key read from the node's filesystem, unless the user has
+// the admin role.
+func checkPrivilegesForKMS(ctx context.Context, p sql.PlanHookState, kmsURIs []string) error {
+	knobs := p.ExecCfg().BackupRestoreTestingKnobs
+	if knobs != nil && knobs.AllowImplicitAccess {
+		return nil
+	}
+	for _, uri := range kmsURIs {
+		hasExplicitAuth, uriScheme, err := cloud.AccessIsWithExplicitAuth(uri)
+		if err != nil {
+			return err
+		}
+		if hasExplicitAuth {
+			continue
+		}
+		hasAdmin, err := p.HasAdminRole(ctx)
+		if err != nil {
+			return err
+		}
+		if !hasAdmin {
+			return pgerror.Newf(
+				pgcode.InsufficientPrivilege,
+				"only users with the admin role are allowed to use the specified %s KMS URI",
+				uriScheme)
+		}
+	}
+	return nil
+}
+
 // backupPlanHook implements PlanHookFn.
 func backupPlanHook(
 	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
@@ -856,6 +888,9 @@ func backupPlanHook(
 			if err != nil {
 				return err
 			}
+			if err := checkPrivilegesForKMS(ctx, p, encryptionParams.kmsURIs); err != nil {
+				return err
+			}
 			if err := requireEnterprise("encryption"); err != nil {
 				return err
 			}
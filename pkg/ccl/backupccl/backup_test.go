@@ -12,6 +12,7 @@ import (
 	"bytes"
 	"context"
 	gosql "database/sql"
+	"encoding/hex"
 	"fmt"
 	"hash/crc32"
 	"io"
@@ -38,6 +39,7 @@ import (
 	_ "github.com/cockroachdb/cockroach/pkg/ccl/kvccl"
 	_ "github.com/cockroachdb/cockroach/pkg/ccl/partitionccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/config/zonepb"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
@@ -48,6 +50,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
@@ -2000,6 +2003,37 @@ func TestRestoreFailDatabaseCleanup(t *testing.T) {
 	)
 }
 
+// TestBackupUserDefinedFunctions checks that BACKUP refuses to back up a
+// database containing user-defined functions, or a view calling them, which it
+// does not support yet, rather than silently leaving them out.
+func TestBackupUserDefinedFunctions(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	_, _, sqlDB, _, cleanupFn := BackupRestoreTestSetup(t, singleNode, 0, InitManualReplication)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `
+CREATE DATABASE d;
+CREATE TABLE d.t (a INT);
+CREATE FUNCTION d.add_one(a INT) RETURNS INT LANGUAGE SQL AS 'SELECT a + 1';
+CREATE VIEW d.v AS SELECT d.add_one(a) FROM d.t;
+`)
+
+	const expectedErr = `cannot back up database "d": it contains function "add_one"`
+	sqlDB.ExpectErr(t, expectedErr, `BACKUP DATABASE d TO $1`, LocalFoo+"/db")
+	sqlDB.ExpectErr(t, expectedErr, `BACKUP TO $1`, LocalFoo+"/cluster")
+
+	sqlDB.ExpectErr(t, `cannot back up view "v": it calls user-defined functions`,
+		`BACKUP TABLE d.v TO $1`, LocalFoo+"/view")
+
+	// Backing up the other tables of the database is still allowed.
+	sqlDB.Exec(t, `BACKUP TABLE d.t TO $1`, LocalFoo+"/table")
+
+	sqlDB.Exec(t, `DROP FUNCTION d.add_one CASCADE`)
+	sqlDB.Exec(t, `BACKUP DATABASE d TO $1`, LocalFoo+"/db")
+}
+
 func TestBackupRestoreUserDefinedSchemas(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -4367,6 +4401,50 @@ func TestRegionalKMSEncryptedBackup(t *testing.T) {
 	})
 }
 
+// This test performs an encrypted BACKUP and RESTORE using the local file KMS,
+// which lets the KMS flow be exercised without access to a hosted KMS.
+func TestFileKMSEncryptedBackup(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx, _, sqlDB, rawDir, cleanupFn := BackupRestoreTestSetup(t, MultiNode, 3, InitManualReplication)
+	defer cleanupFn()
+
+	// Key paths are resolved relative to the external IO directory.
+	require.NoError(t, os.MkdirAll(filepath.Join(rawDir, "keys"), 0755))
+	writeKey := func(name string, seed byte) string {
+		key := make([]byte, 32)
+		for i := range key {
+			key[i] = seed + byte(i)
+		}
+		keyPath := filepath.Join(rawDir, "keys", name)
+		require.NoError(t, ioutil.WriteFile(keyPath, []byte(hex.EncodeToString(key)), 0600))
+		return "file:///keys/" + name
+	}
+	kmsURI := writeKey("key", 1)
+	wrongKMSURI := writeKey("wrong-key", 2)
+
+	setupBackupEncryptedTest(ctx, t, sqlDB)
+
+	backupLoc := LocalFoo + "/x"
+	backupLocInc := LocalFoo + "/inc1/x"
+	sqlDB.Exec(t, `BACKUP TO $1 WITH kms=$2`, backupLoc, kmsURI)
+	sqlDB.Exec(t, `UPDATE neverappears.neverappears SET other = 'neverappears'`)
+	sqlDB.Exec(t, `BACKUP TO $1 INCREMENTAL FROM $2 WITH kms=$3`, backupLocInc, backupLoc, kmsURI)
+
+	checkBackupStatsEncrypted(t, rawDir)
+	checkBackupFilesEncrypted(t, rawDir)
+
+	before := sqlDB.QueryStr(t, `SHOW EXPERIMENTAL_FINGERPRINTS FROM TABLE neverappears.neverappears`)
+	sqlDB.Exec(t, `DROP DATABASE neverappears CASCADE`)
+
+	sqlDB.ExpectErr(t, `one of the provided URIs was not used when encrypting the base BACKUP`,
+		fmt.Sprintf(`SHOW BACKUP $1 WITH kms='%s'`, wrongKMSURI), backupLoc)
+	sqlDB.Exec(t, `RESTORE DATABASE neverappears FROM $1, $2 WITH kms=$3`,
+		backupLoc, backupLocInc, kmsURI)
+	sqlDB.CheckQueryResults(t, `SHOW EXPERIMENTAL_FINGERPRINTS FROM TABLE neverappears.neverappears`, before)
+}
+
 type testKMSEnv struct {
 	settings         *cluster.Settings
 	externalIOConfig *base.ExternalIODirConfig
@@ -4635,6 +4713,142 @@ func TestRestoreInto(t *testing.T) {
 	sqlDB.CheckQueryResults(t, `SELECT * FROM "data 2".bank`, expected)
 }
 
+func TestRestoreTableAs(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 10
+	_, _, sqlDB, _, cleanupFn := BackupRestoreTestSetup(t, singleNode, numAccounts, InitManualReplication)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `CREATE DATABASE d2`)
+	sqlDB.Exec(t, `CREATE TYPE data.greeting AS ENUM ('hi', 'hello')`)
+	sqlDB.Exec(t, `CREATE TABLE data.greetings (a INT PRIMARY KEY, g data.greeting)`)
+	sqlDB.Exec(t, `INSERT INTO data.greetings VALUES (1, 'hi'), (2, 'hello')`)
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, LocalFoo)
+	backedUp := sqlDB.QueryStr(t, `SELECT * FROM data.bank ORDER BY id`)
+	sqlDB.Exec(t, `DELETE FROM data.bank WHERE id < 5`)
+
+	// The table is restored next to the table it was backed up from, under a
+	// new descriptor ID.
+	sqlDB.Exec(t, `RESTORE TABLE data.bank AS data.bank_restored FROM $1`, LocalFoo)
+	require.Equal(t, backedUp, sqlDB.QueryStr(t, `SELECT * FROM data.bank_restored ORDER BY id`))
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM data.bank`, [][]string{{"5"}})
+	sqlDB.CheckQueryResults(t,
+		`SELECT 'data.bank'::REGCLASS::INT = 'data.bank_restored'::REGCLASS::INT`, [][]string{{"false"}})
+
+	// A two-part name is qualified by the schema of the table if its prefix
+	// names it, and by the database otherwise.
+	sqlDB.Exec(t, `RESTORE TABLE data.bank AS public.bank_public FROM $1`, LocalFoo)
+	require.Equal(t, backedUp, sqlDB.QueryStr(t, `SELECT * FROM data.public.bank_public ORDER BY id`))
+
+	// The types the table depends on are restored along with it into another
+	// database.
+	sqlDB.Exec(t, `RESTORE TABLE data.greetings AS d2.greetings2 FROM $1`, LocalFoo)
+	sqlDB.CheckQueryResults(t, `SELECT a, g::STRING FROM d2.greetings2 ORDER BY a`,
+		[][]string{{"1", "hi"}, {"2", "hello"}})
+	sqlDB.CheckQueryResults(t, `SELECT 'hello'::d2.greeting`, [][]string{{"hello"}})
+
+	sqlDB.ExpectErr(t, `relation "bank" already exists`,
+		`RESTORE TABLE data.bank AS data.bank FROM $1`, LocalFoo)
+	sqlDB.ExpectErr(t, `cannot use "into_db" option with RESTORE TABLE ... AS`,
+		`RESTORE TABLE data.bank AS bank2 FROM $1 WITH into_db = 'd2'`, LocalFoo)
+	sqlDB.ExpectErr(t, `cannot restore table "bank" into schema "sc": it was backed up in schema "public"`,
+		`RESTORE TABLE data.bank AS d2.sc.bank FROM $1`, LocalFoo)
+}
+
+func TestBackupCompaction(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 50
+	_, _, sqlDB, _, cleanupFn := BackupRestoreTestSetup(t, singleNode, numAccounts, InitManualReplication)
+	defer cleanupFn()
+
+	t.Run("latest", func(t *testing.T) {
+		const collection = LocalFoo + "/latest"
+		sqlDB.Exec(t, `BACKUP DATABASE data INTO $1`, collection)
+		sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1 WHERE id < 10`)
+		sqlDB.Exec(t, `BACKUP DATABASE data INTO LATEST IN $1`, collection)
+		sqlDB.Exec(t, `DELETE FROM data.bank WHERE id >= 40`)
+		sqlDB.Exec(t, `CREATE TABLE data.added (a INT PRIMARY KEY)`)
+		sqlDB.Exec(t, `INSERT INTO data.added VALUES (1), (2)`)
+		sqlDB.Exec(t, `BACKUP DATABASE data INTO LATEST IN $1`, collection)
+		expectedBank := sqlDB.QueryStr(t, `SELECT * FROM data.bank ORDER BY id`)
+		expectedAdded := sqlDB.QueryStr(t, `SELECT * FROM data.added ORDER BY a`)
+
+		full := sqlDB.QueryStr(t, `SHOW BACKUPS IN $1`, collection)[0][0]
+		sqlDB.Exec(t, `COMPACT BACKUP $1 IN $2`, full, collection)
+		backups := sqlDB.QueryStr(t, `SHOW BACKUPS IN $1`, collection)
+		require.Len(t, backups, 2)
+		compacted := backups[1][0]
+
+		// The compacted backup is a single full backup that holds the same data
+		// as the chain it was compacted from.
+		require.Equal(t, [][]string{{"1"}}, sqlDB.QueryStr(t,
+			`SELECT count(DISTINCT end_time) FROM [SHOW BACKUP $1 IN $2]`, compacted, collection))
+		sqlDB.Exec(t, `CREATE DATABASE latest`)
+		sqlDB.Exec(t, `RESTORE TABLE data.* FROM $1 IN $2 WITH into_db = 'latest'`, compacted, collection)
+		require.Equal(t, expectedBank, sqlDB.QueryStr(t, `SELECT * FROM latest.bank ORDER BY id`))
+		require.Equal(t, expectedAdded, sqlDB.QueryStr(t, `SELECT * FROM latest.added ORDER BY a`))
+
+		sqlDB.ExpectErr(t, `already contains a BACKUP_MANIFEST file`,
+			`COMPACT BACKUP $1 IN $2`, full, collection)
+		sqlDB.ExpectErr(t, `has no incremental layers to compact`,
+			`COMPACT BACKUP $1 IN $2`, compacted, collection)
+	})
+
+	t.Run("revision-history", func(t *testing.T) {
+		const collection = LocalFoo + "/revision-history"
+		sqlDB.Exec(t, `BACKUP DATABASE data INTO $1 WITH revision_history, encryption_passphrase = 'abc'`,
+			collection)
+		sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1 WHERE id < 20`)
+		var ts string
+		sqlDB.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&ts)
+		expectedBank := sqlDB.QueryStr(t, `SELECT * FROM data.bank ORDER BY id`)
+		sqlDB.Exec(t, `DELETE FROM data.bank WHERE id < 5`)
+		sqlDB.Exec(t, `BACKUP DATABASE data INTO LATEST IN $1 WITH revision_history, encryption_passphrase = 'abc'`,
+			collection)
+
+		full := sqlDB.QueryStr(t, `SHOW BACKUPS IN $1`, collection)[0][0]
+		sqlDB.ExpectErr(t, `file appears encrypted`, `COMPACT BACKUP $1 IN $2`, full, collection)
+		sqlDB.Exec(t, `COMPACT BACKUP $1 IN $2 WITH encryption_passphrase = 'abc'`, full, collection)
+		compacted := sqlDB.QueryStr(t, `SHOW BACKUPS IN $1`, collection)[1][0]
+
+		// The revisions of every layer are kept, so the compacted backup can be
+		// restored as of a time inside of the incremental layer.
+		sqlDB.Exec(t, `CREATE DATABASE revs`)
+		sqlDB.Exec(t, fmt.Sprintf(`RESTORE TABLE data.bank FROM $1 IN $2 AS OF SYSTEM TIME %s
+			WITH into_db = 'revs', encryption_passphrase = 'abc'`, ts), compacted, collection)
+		require.Equal(t, expectedBank, sqlDB.QueryStr(t, `SELECT * FROM revs.bank ORDER BY id`))
+	})
+}
+
+func TestBackupCompactionMixedVersion(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	oldVersion := clusterversion.ByKey(clusterversion.BackupCompaction - 1)
+	st := cluster.MakeTestingClusterSettingsWithVersions(
+		clusterversion.TestingBinaryVersion, oldVersion, false /* initializeVersion */)
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Settings: st,
+		Knobs: base.TestingKnobs{
+			Server: &server.TestingKnobs{
+				BinaryVersionOverride:          oldVersion,
+				DisableAutomaticVersionUpgrade: 1,
+			},
+		},
+	})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	// Backups can't be compacted until all nodes can run the compaction job.
+	sqlDB.ExpectErr(t, `COMPACT BACKUP requires all nodes to be upgraded to 20.2-40`,
+		`COMPACT BACKUP $1 IN $2`, `/2021/01/01-000000.00`, LocalFoo)
+}
+
 func TestRestoreDatabaseVersusTable(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -810,6 +810,52 @@ func resolveTargetDB(
 	return database.Name, nil
 }
 
+// renameRestoredTable gives the single table restored by a RESTORE TABLE ...
+// AS statement the name it is restored under, and returns the database it is
+// restored into. The table keeps the schema it was backed up in, but may be
+// restored into another database.
+func renameRestoredTable(
+	asTable *tree.UnresolvedObjectName,
+	databasesByID map[descpb.ID]*dbdesc.Mutable,
+	schemasByID map[descpb.ID]*schemadesc.Mutable,
+	tablesByID map[descpb.ID]*tabledesc.Mutable,
+) (string, error) {
+	if len(tablesByID) != 1 {
+		return "", errors.Errorf("RESTORE TABLE ... AS can only restore a single table, found %d",
+			len(tablesByID))
+	}
+	var table *tabledesc.Mutable
+	for _, desc := range tablesByID {
+		table = desc
+	}
+	database, ok := databasesByID[table.GetParentID()]
+	if !ok {
+		return "", errors.Errorf("no database with ID %d in backup for object %q (%d)",
+			table.GetParentID(), table.GetName(), table.GetID())
+	}
+	schemaName := tree.PublicSchema
+	if sc, ok := schemasByID[table.GetParentSchemaID()]; ok {
+		schemaName = sc.GetName()
+	}
+
+	newName := asTable.ToTableName()
+	targetDB := database.GetName()
+	if newName.ExplicitCatalog {
+		if newName.Schema() != schemaName {
+			return "", errors.Errorf("cannot restore table %q into schema %q: it was backed up in schema %q",
+				table.GetName(), newName.Schema(), schemaName)
+		}
+		targetDB = newName.Catalog()
+	} else if newName.ExplicitSchema && newName.Schema() != schemaName {
+		// As when resolving object names, a two-part name is qualified by the
+		// schema of the table if the prefix names it, and by the database
+		// otherwise.
+		targetDB = newName.Schema()
+	}
+	table.SetName(newName.Table())
+	return targetDB, nil
+}
+
 // maybeUpgradeTableDescsInBackupManifests updates the backup descriptors'
 // table descriptors to use the newer 19.2-style foreign key representation,
 // if they are not already upgraded. This requires resolving cross-table FK
@@ -929,7 +975,8 @@ func rewriteTypeDescs(types []*typedesc.Mutable, descriptorRewrites DescRewriteM
 			}
 		}
 		switch t := typ.Kind; t {
-		case descpb.TypeDescriptor_ENUM, descpb.TypeDescriptor_MULTIREGION_ENUM:
+		case descpb.TypeDescriptor_ENUM, descpb.TypeDescriptor_MULTIREGION_ENUM,
+			descpb.TypeDescriptor_COMPOSITE, descpb.TypeDescriptor_DOMAIN:
 			if rw, ok := descriptorRewrites[typ.ArrayTypeID]; ok {
 				typ.ArrayTypeID = rw.ID
 			}
@@ -978,7 +1025,8 @@ func maybeRewriteSchemaID(
 
 // RewriteTableDescs mutates tables to match the ID and privilege specified
 // in descriptorRewrites, as well as adjusting cross-table references to use the
-// new IDs. overrideDB can be specified to set database names in views.
+// new IDs and renaming the tables restored under a new name. overrideDB can be
+// specified to set database names in views.
 func RewriteTableDescs(
 	tables []*tabledesc.Mutable, descriptorRewrites DescRewriteMap, overrideDB string,
 ) error {
@@ -1003,6 +1051,9 @@ func RewriteTableDescs(
 			}
 		}
 
+		if tableRewrite.NewName != "" {
+			table.SetName(tableRewrite.NewName)
+		}
 		table.ID = tableRewrite.ID
 		table.UnexposedParentSchemaID = maybeRewriteSchemaID(table.GetParentSchemaID(),
 			descriptorRewrites, table.IsTemporary())
@@ -1243,6 +1294,7 @@ func restoreJobDescription(
 		DescriptorCoverage: restore.DescriptorCoverage,
 		AsOf:               restore.AsOf,
 		Targets:            restore.Targets,
+		AsTable:            restore.AsTable,
 		From:               make([]tree.StringOrPlaceholderOptList, len(restore.From)),
 	}
 
@@ -1318,6 +1370,10 @@ func restorePlanHook(
 
 	var intoDBFn func() (string, error)
 	if restoreStmt.Options.IntoDB != nil {
+		if restoreStmt.AsTable != nil {
+			return nil, nil, nil, false, errors.Errorf("cannot use %q option with RESTORE TABLE ... AS",
+				restoreOptIntoDB)
+		}
 		intoDBFn, err = p.TypeAsString(ctx, restoreStmt.Options.IntoDB, "RESTORE")
 		if err != nil {
 			return nil, nil, nil, false, err
@@ -1394,6 +1450,9 @@ func restorePlanHook(
 			if err != nil {
 				return err
 			}
+			if err := checkPrivilegesForKMS(ctx, p, kms); err != nil {
+				return err
+			}
 		}
 
 		var intoDB string
@@ -1609,6 +1668,18 @@ func doRestorePlan(
 	if err != nil {
 		return err
 	}
+	// A table restored under a new name is restored as if into_db named the
+	// database it is restored into, which is where the user-defined schemas
+	// and types it depends on are then looked up or restored.
+	rewriteOpts := restoreStmt.Options
+	if restoreStmt.AsTable != nil {
+		intoDB, err = renameRestoredTable(restoreStmt.AsTable, databasesByID, schemasByID,
+			filteredTablesByID)
+		if err != nil {
+			return err
+		}
+		rewriteOpts.IntoDB = tree.NewDString(intoDB)
+	}
 	descriptorRewrites, err := allocateDescriptorRewrites(
 		ctx,
 		p,
@@ -1618,12 +1689,19 @@ func doRestorePlan(
 		typesByID,
 		restoreDBs,
 		restoreStmt.DescriptorCoverage,
-		restoreStmt.Options,
+		rewriteOpts,
 		intoDB,
 	)
 	if err != nil {
 		return err
 	}
+	if restoreStmt.AsTable != nil {
+		// The job restores the descriptors as they appear in the backup, so the
+		// new name travels with the rewrite of the table.
+		for id, table := range filteredTablesByID {
+			descriptorRewrites[id].NewName = table.GetName()
+		}
+	}
 	description, err := restoreJobDescription(p, restoreStmt, from, restoreStmt.Options, intoDB, kms)
 	if err != nil {
 		return err
@@ -9,7 +9,10 @@
 package backupccl
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"io/ioutil"
 	"net/url"
 	"path"
 	"strings"
@@ -31,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
@@ -94,6 +98,7 @@ func showBackupPlanHook(
 		backupOptEncPassphrase:  sql.KVStringOptRequireValue,
 		backupOptEncKMS:         sql.KVStringOptRequireValue,
 		backupOptWithPrivileges: sql.KVStringOptRequireNoValue,
+		backupOptCheckData:      sql.KVStringOptRequireNoValue,
 	}
 	optsFn, err := p.TypeAsStringOpts(ctx, backup.Options, expected)
 	if err != nil {
@@ -110,6 +115,11 @@ func showBackupPlanHook(
 		shower = backupShowerRanges
 	case tree.BackupFileDetails:
 		shower = backupShowerFiles
+	case tree.BackupValidateDetails:
+		_, checkData := opts[backupOptCheckData]
+		shower = backupShowerValidate(checkData)
+	case tree.BackupRowDetails:
+		shower = backupShowerRows(p, backup.Table, backup.Keys)
 	default:
 		shower = backupShowerDefault(ctx, p, backup.ShouldIncludeSchemas, opts)
 	}
@@ -157,6 +167,9 @@ func showBackupPlanHook(
 			encryption = &jobspb.BackupEncryptionOptions{Mode: jobspb.EncryptionMode_Passphrase,
 				Key: encryptionKey}
 		} else if kms, ok := opts[backupOptEncKMS]; ok {
+			if err := checkPrivilegesForKMS(ctx, p, []string{kms}); err != nil {
+				return err
+			}
 			opts, err := readEncryptionOptions(ctx, store)
 			if err != nil {
 				return err
@@ -209,7 +222,12 @@ func showBackupPlanHook(
 			return err
 		}
 
-		datums, err := shower.fn(manifests)
+		datums, err := shower.fn(ctx, backupChain{
+			store:      store,
+			incPaths:   incPaths,
+			manifests:  manifests,
+			encryption: encryption,
+		})
 		if err != nil {
 			return err
 		}
@@ -226,9 +244,23 @@ func showBackupPlanHook(
 	return fn, shower.header, nil, false, nil
 }
 
+// backupChain is a backup and its incremental layers, as read by SHOW BACKUP.
+type backupChain struct {
+	// store is the external storage of the location of the backup.
+	store cloud.ExternalStorage
+	// incPaths are the paths of the manifests of the incremental layers of the
+	// backup in store.
+	incPaths []string
+	// manifests are the manifests of the backup and of its incremental layers,
+	// in order.
+	manifests []BackupManifest
+	// encryption, if non-nil, holds the options needed to decrypt the backup.
+	encryption *jobspb.BackupEncryptionOptions
+}
+
 type backupShower struct {
 	header colinfo.ResultColumns
-	fn     func([]BackupManifest) ([]tree.Datums, error)
+	fn     func(context.Context, backupChain) ([]tree.Datums, error)
 }
 
 func backupShowerHeaders(showSchemas bool, opts map[string]string) colinfo.ResultColumns {
@@ -257,9 +289,9 @@ func backupShowerDefault(
 ) backupShower {
 	return backupShower{
 		header: backupShowerHeaders(showSchemas, opts),
-		fn: func(manifests []BackupManifest) ([]tree.Datums, error) {
+		fn: func(ctx context.Context, chain backupChain) ([]tree.Datums, error) {
 			var rows []tree.Datums
-			for _, manifest := range manifests {
+			for _, manifest := range chain.manifests {
 				// Map database ID to descriptor name.
 				dbIDToName := make(map[descpb.ID]string)
 				schemaIDToName := make(map[descpb.ID]string)
@@ -457,8 +489,8 @@ var backupShowerRanges = backupShower{
 		{Name: "end_key", Typ: types.Bytes},
 	},
 
-	fn: func(manifests []BackupManifest) (rows []tree.Datums, err error) {
-		for _, manifest := range manifests {
+	fn: func(_ context.Context, chain backupChain) (rows []tree.Datums, err error) {
+		for _, manifest := range chain.manifests {
 			for _, span := range manifest.Spans {
 				rows = append(rows, tree.Datums{
 					tree.NewDString(span.Key.String()),
@@ -483,8 +515,8 @@ var backupShowerFiles = backupShower{
 		{Name: "rows", Typ: types.Int},
 	},
 
-	fn: func(manifests []BackupManifest) (rows []tree.Datums, err error) {
-		for _, manifest := range manifests {
+	fn: func(_ context.Context, chain backupChain) (rows []tree.Datums, err error) {
+		for _, manifest := range chain.manifests {
 			for _, file := range manifest.Files {
 				rows = append(rows, tree.Datums{
 					tree.NewDString(file.Path),
@@ -501,6 +533,140 @@ var backupShowerFiles = backupShower{
 	},
 }
 
+// backupShowerValidate checks that the backup can be restored, without
+// restoring it: that the incremental layers of the backup follow each other,
+// and that every file referenced by the manifests exists and matches the
+// checksum recorded in the manifest. If checkData is true, the data in every
+// file is also iterated to check that it decodes. Each problem found is
+// returned as a row; a backup without problems returns no rows.
+//
+// Only the files in the location of the backup are validated. The files of a
+// locality-aware backup which were written to the location of their locality
+// are reported as not validated.
+func backupShowerValidate(checkData bool) backupShower {
+	return backupShower{
+		header: colinfo.ResultColumns{
+			{Name: "backup_path", Typ: types.String},
+			{Name: "file", Typ: types.String},
+			{Name: "problem", Typ: types.String},
+		},
+
+		fn: func(ctx context.Context, chain backupChain) (rows []tree.Datums, err error) {
+			var encryptionKey []byte
+			if chain.encryption != nil {
+				encryptionKey, err = getEncryptionKey(ctx, chain.encryption,
+					chain.store.Settings(), chain.store.ExternalIOConf())
+				if err != nil {
+					return nil, err
+				}
+			}
+			for i, manifest := range chain.manifests {
+				// The base backup is at the root of the location, and each incremental
+				// layer in the directory of its manifest.
+				dir := "/"
+				if i > 0 {
+					dir = path.Join("/", path.Dir(chain.incPaths[i-1]))
+				}
+				report := func(file string, problem string) {
+					rows = append(rows, tree.Datums{
+						tree.NewDString(dir), nullIfEmpty(file), tree.NewDString(problem),
+					})
+				}
+
+				if i > 0 {
+					if prev := chain.manifests[i-1]; !manifest.StartTime.Equal(prev.EndTime) {
+						report("", fmt.Sprintf("start time %s does not match end time %s of the previous backup",
+							manifest.StartTime, prev.EndTime))
+					}
+				}
+				localityKVs := make(map[string]struct{}, len(manifest.LocalityKVs))
+				for _, kv := range manifest.LocalityKVs {
+					localityKVs[kv] = struct{}{}
+				}
+				for _, file := range manifest.Files {
+					if _, ok := localityKVs[file.LocalityKV]; ok {
+						report(file.Path, fmt.Sprintf("not validated: stored in the backup of locality %s",
+							file.LocalityKV))
+						continue
+					}
+					if err := validateBackupFile(
+						ctx, chain.store, path.Join(dir, file.Path), file, encryptionKey, checkData,
+					); err != nil {
+						if ctxErr := ctx.Err(); ctxErr != nil {
+							return nil, ctxErr
+						}
+						report(file.Path, err.Error())
+					}
+				}
+			}
+			return rows, nil
+		},
+	}
+}
+
+// validateBackupFile checks that the file of a backup at the given path of the
+// store exists and matches the checksum recorded in its manifest, and, if
+// checkData is true, that its data decodes and belongs to its span. The
+// returned error describes the first problem found.
+func validateBackupFile(
+	ctx context.Context,
+	store cloud.ExternalStorage,
+	filePath string,
+	file BackupManifest_File,
+	encryptionKey []byte,
+	checkData bool,
+) error {
+	r, err := store.ReadFile(ctx, filePath)
+	if err != nil {
+		if errors.Is(err, cloudimpl.ErrFileDoesNotExist) {
+			return errors.New("file does not exist")
+		}
+		return errors.Wrap(err, "reading file")
+	}
+	defer r.Close()
+	fileContents, err := ioutil.ReadAll(r)
+	if err != nil {
+		return errors.Wrap(err, "reading file")
+	}
+	if encryptionKey != nil {
+		fileContents, err = storageccl.DecryptFile(fileContents, encryptionKey)
+		if err != nil {
+			return errors.Wrap(err, "decrypting file")
+		}
+	}
+	if len(file.Sha512) > 0 {
+		checksum, err := storageccl.SHA512ChecksumData(fileContents)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(checksum, file.Sha512) {
+			return errors.New("checksum mismatch")
+		}
+	}
+	if !checkData {
+		return nil
+	}
+
+	// The sstables only contain MVCC data and no intents, so using an MVCC
+	// iterator is sufficient. It verifies the checksums of the values.
+	iter, err := storage.NewMemSSTIterator(fileContents, true /* verify */)
+	if err != nil {
+		return errors.Wrap(err, "opening sstable")
+	}
+	defer iter.Close()
+	for iter.SeekGE(storage.MVCCKey{}); ; iter.Next() {
+		if ok, err := iter.Valid(); err != nil {
+			return errors.Wrap(err, "reading sstable")
+		} else if !ok {
+			break
+		}
+		if key := iter.UnsafeKey().Key; !file.Span.ContainsKey(key) {
+			return errors.Newf("key %s is outside of the span %s of the file", key, file.Span)
+		}
+	}
+	return nil
+}
+
 // showBackupPlanHook implements PlanHookFn.
 func showBackupsInCollectionPlanHook(
 	ctx context.Context, backup *tree.ShowBackup, p sql.PlanHookState,
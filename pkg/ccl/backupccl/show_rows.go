@@ -0,0 +1,391 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
+)
+
+// backupShowerRows returns the shower of SHOW BACKUP ROWS, which lists the
+// revisions of the rows with the given primary keys held by a backup taken
+// with revision history, so that a few rows can be recovered without restoring
+// their whole table.
+func backupShowerRows(
+	p sql.PlanHookState, table *tree.UnresolvedObjectName, keyExprs tree.Exprs,
+) backupShower {
+	return backupShower{
+		header: colinfo.ResultColumns{
+			{Name: "key", Typ: types.String},
+			{Name: "mvcc_timestamp", Typ: types.Decimal},
+			{Name: "deleted", Typ: types.Bool},
+			{Name: "row", Typ: types.Jsonb},
+		},
+
+		fn: func(ctx context.Context, chain backupChain) ([]tree.Datums, error) {
+			for _, manifest := range chain.manifests {
+				if manifest.MVCCFilter != MVCCFilter_All {
+					return nil, errors.Errorf("SHOW BACKUP ROWS requires a backup taken with the %s option",
+						backupOptRevisionHistory)
+				}
+			}
+			tableDesc, err := resolveBackupTable(ctx, p, chain.manifests, table)
+			if err != nil {
+				return nil, err
+			}
+			keys, err := encodeBackupRowKeys(ctx, p, tableDesc, keyExprs)
+			if err != nil {
+				return nil, err
+			}
+			revisions, err := readBackupRowRevisions(ctx, chain, keys)
+			if err != nil {
+				return nil, err
+			}
+
+			rf, err := makeBackupRowFetcher(ctx, p, tableDesc)
+			if err != nil {
+				return nil, err
+			}
+			loc := p.ExtendedEvalContext().GetLocation()
+			var rows []tree.Datums
+			for i, key := range keys {
+				for _, r := range rowRevisions(revisions[i]) {
+					datums := tree.Datums{
+						tree.NewDString(key.pretty),
+						tree.TimestampToDecimalDatum(r.timestamp),
+						tree.MakeDBool(len(r.kvs) == 0),
+						tree.DNull,
+					}
+					if len(r.kvs) > 0 {
+						if err := rf.StartScanFrom(ctx, &row.SpanKVFetcher{KVs: r.kvs}); err != nil {
+							return nil, err
+						}
+						decoded, _, _, err := rf.NextRowDecoded(ctx)
+						if err != nil {
+							return nil, err
+						}
+						if decoded == nil {
+							return nil, errors.AssertionFailedf("no row decoded for key %s", key.pretty)
+						}
+						b := json.NewObjectBuilder(len(decoded))
+						for j, d := range decoded {
+							v, err := tree.AsJSON(d, loc)
+							if err != nil {
+								return nil, err
+							}
+							b.Add(tableDesc.Columns[j].Name, v)
+						}
+						datums[3] = tree.NewDJSON(b.Build())
+					}
+					rows = append(rows, datums)
+				}
+			}
+			return rows, nil
+		},
+	}
+}
+
+// resolveBackupTable returns the descriptor of the given table as of the end
+// of the backup, with the metadata of its user-defined types installed.
+func resolveBackupTable(
+	ctx context.Context,
+	p sql.PlanHookState,
+	manifests []BackupManifest,
+	table *tree.UnresolvedObjectName,
+) (*tabledesc.Immutable, error) {
+	allDescs, _ := loadSQLDescsFromBackupsAtTime(manifests, hlc.Timestamp{})
+	matched, err := descriptorsMatchingTargets(ctx, p.CurrentDatabase(), p.CurrentSearchPath(),
+		allDescs, tree.TargetList{Tables: tree.TablePatterns{table.ToUnresolvedName()}})
+	if err != nil {
+		return nil, err
+	}
+	var desc catalog.TableDescriptor
+	for _, d := range matched.descs {
+		if t, ok := d.(catalog.TableDescriptor); ok {
+			desc = t
+		}
+	}
+	if desc == nil {
+		return nil, errors.Errorf("table %q does not exist in the backup", tree.ErrString(table))
+	}
+
+	typesByID := make(backupTypeResolver)
+	for _, d := range allDescs {
+		if t, ok := d.(catalog.TypeDescriptor); ok {
+			typesByID[t.GetID()] = t
+		}
+	}
+	tableDesc := protoutil.Clone(desc.TableDesc()).(*descpb.TableDescriptor)
+	if err := typedesc.HydrateTypesInTableDescriptor(ctx, tableDesc, typesByID); err != nil {
+		return nil, err
+	}
+	return tabledesc.NewImmutable(*tableDesc), nil
+}
+
+// backupTypeResolver resolves the user-defined types of a backup by ID.
+type backupTypeResolver map[descpb.ID]catalog.TypeDescriptor
+
+// GetTypeDescriptor implements the catalog.TypeDescriptorResolver interface.
+func (r backupTypeResolver) GetTypeDescriptor(
+	_ context.Context, id descpb.ID,
+) (tree.TypeName, catalog.TypeDescriptor, error) {
+	typ, ok := r[id]
+	if !ok {
+		return tree.TypeName{}, nil, errors.Errorf("type with ID %d does not exist in the backup", id)
+	}
+	return tree.MakeUnqualifiedTypeName(tree.Name(typ.GetName())), typ, nil
+}
+
+// backupRowKey is the span of the keys of a row of a table.
+type backupRowKey struct {
+	roachpb.Span
+	// pretty is the primary key of the row, as shown to the user.
+	pretty string
+}
+
+// encodeBackupRowKeys returns the spans of the rows of the table with the
+// given primary keys. The keys of tables with a single primary key column are
+// values of that column, and the keys of other tables are tuples.
+func encodeBackupRowKeys(
+	ctx context.Context, p sql.PlanHookState, tableDesc *tabledesc.Immutable, keyExprs tree.Exprs,
+) ([]backupRowKey, error) {
+	codec := p.ExecCfg().Codec
+	index := tableDesc.GetPrimaryIndex()
+	var colMap catalog.TableColMap
+	for i, id := range index.ColumnIDs {
+		colMap.Set(id, i)
+	}
+	keyPrefix := rowenc.MakeIndexKeyPrefix(codec, tableDesc, index.ID)
+
+	keys := make([]backupRowKey, len(keyExprs))
+	for i, keyExpr := range keyExprs {
+		exprs := tree.Exprs{keyExpr}
+		if len(index.ColumnIDs) > 1 {
+			tuple, ok := keyExpr.(*tree.Tuple)
+			if !ok || len(tuple.Exprs) != len(index.ColumnIDs) {
+				return nil, errors.Errorf("the primary key of %s has %d columns: %s is not a tuple of %d values",
+					tableDesc.Name, len(index.ColumnIDs), tree.AsString(keyExpr), len(index.ColumnIDs))
+			}
+			exprs = tuple.Exprs
+		}
+		values := make([]tree.Datum, len(exprs))
+		pretty := make([]string, len(exprs))
+		for j, expr := range exprs {
+			col, err := tableDesc.FindColumnByID(index.ColumnIDs[j])
+			if err != nil {
+				return nil, err
+			}
+			typedExpr, err := tree.TypeCheckAndRequire(ctx, expr, p.SemaCtx(), col.Type, "SHOW BACKUP ROWS")
+			if err != nil {
+				return nil, err
+			}
+			values[j], err = typedExpr.Eval(&p.ExtendedEvalContext().EvalContext)
+			if err != nil {
+				return nil, err
+			}
+			if values[j] == tree.DNull {
+				return nil, errors.Errorf("primary key column %s of %s cannot be NULL", col.Name, tableDesc.Name)
+			}
+			pretty[j] = tree.AsString(values[j])
+		}
+		span, _, err := rowenc.EncodePartialIndexSpan(
+			tableDesc, index, len(index.ColumnIDs), colMap, values, keyPrefix,
+		)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = backupRowKey{Span: span, pretty: strings.Join(pretty, ", ")}
+		if len(pretty) > 1 {
+			keys[i].pretty = "(" + keys[i].pretty + ")"
+		}
+	}
+	return keys, nil
+}
+
+// readBackupRowRevisions returns, for each of the given rows, the revisions of
+// its keys found in the files of the backup and its incremental layers.
+func readBackupRowRevisions(
+	ctx context.Context, chain backupChain, keys []backupRowKey,
+) ([][]storageccl.VersionedValues, error) {
+	var encryptionKey []byte
+	if chain.encryption != nil {
+		var err error
+		encryptionKey, err = getEncryptionKey(ctx, chain.encryption,
+			chain.store.Settings(), chain.store.ExternalIOConf())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	revisions := make([][]storageccl.VersionedValues, len(keys))
+	for i, manifest := range chain.manifests {
+		dir := "/"
+		if i > 0 {
+			dir = path.Join("/", path.Dir(chain.incPaths[i-1]))
+		}
+		for _, file := range manifest.Files {
+			var overlapping []int
+			for k := range keys {
+				if file.Span.Overlaps(keys[k].Span) {
+					overlapping = append(overlapping, k)
+				}
+			}
+			if len(overlapping) == 0 {
+				continue
+			}
+			if file.LocalityKV != "" {
+				return nil, errors.Errorf("SHOW BACKUP ROWS does not support locality-aware backups")
+			}
+			sst, err := readBackupFile(ctx, chain, path.Join(dir, file.Path), encryptionKey)
+			if err != nil {
+				return nil, err
+			}
+			for _, k := range overlapping {
+				values, err := storageccl.GetAllRevisionsFromSST(sst, keys[k].Key, keys[k].EndKey)
+				if err != nil {
+					return nil, errors.Wrapf(err, "reading %s", file.Path)
+				}
+				revisions[k] = append(revisions[k], values...)
+			}
+		}
+	}
+	return revisions, nil
+}
+
+// readBackupFile reads the file of a backup at the given path of its store,
+// decrypting it if encryptionKey is non-nil.
+func readBackupFile(
+	ctx context.Context, chain backupChain, filePath string, encryptionKey []byte,
+) ([]byte, error) {
+	r, err := chain.store.ReadFile(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	contents, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if encryptionKey != nil {
+		return storageccl.DecryptFile(contents, encryptionKey)
+	}
+	return contents, nil
+}
+
+// rowRevision is the state of a row at a timestamp at which one of its column
+// families was written or deleted.
+type rowRevision struct {
+	timestamp hlc.Timestamp
+	// kvs are the live column families of the row at timestamp, in key order.
+	// The row was deleted at timestamp if there are none.
+	kvs []roachpb.KeyValue
+}
+
+// rowRevisions assembles the revisions of the column families of a row into
+// the revisions of the row, ordered from the oldest to the newest.
+func rowRevisions(families []storageccl.VersionedValues) []rowRevision {
+	// The same family may have been read from several files.
+	byKey := make(map[string]map[hlc.Timestamp]roachpb.Value)
+	var familyKeys []roachpb.Key
+	var timestamps []hlc.Timestamp
+	seenTimestamps := make(map[hlc.Timestamp]struct{})
+	for _, family := range families {
+		values, ok := byKey[string(family.Key)]
+		if !ok {
+			values = make(map[hlc.Timestamp]roachpb.Value)
+			byKey[string(family.Key)] = values
+			familyKeys = append(familyKeys, family.Key)
+		}
+		for _, v := range family.Values {
+			values[v.Timestamp] = v
+			if _, ok := seenTimestamps[v.Timestamp]; !ok {
+				seenTimestamps[v.Timestamp] = struct{}{}
+				timestamps = append(timestamps, v.Timestamp)
+			}
+		}
+	}
+	sort.Slice(familyKeys, func(i, j int) bool { return familyKeys[i].Compare(familyKeys[j]) < 0 })
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i].Less(timestamps[j]) })
+
+	revisions := make([]rowRevision, len(timestamps))
+	for i, ts := range timestamps {
+		revisions[i].timestamp = ts
+		for _, key := range familyKeys {
+			// The family holds the newest of its values written at or before ts.
+			var latest roachpb.Value
+			for valueTS, v := range byKey[string(key)] {
+				if valueTS.LessEq(ts) && latest.Timestamp.Less(valueTS) {
+					latest = v
+				}
+			}
+			if len(latest.RawBytes) > 0 {
+				revisions[i].kvs = append(revisions[i].kvs, roachpb.KeyValue{Key: key, Value: latest})
+			}
+		}
+	}
+	return revisions
+}
+
+// makeBackupRowFetcher returns a Fetcher decoding all the public columns of
+// the rows of the table.
+func makeBackupRowFetcher(
+	ctx context.Context, p sql.PlanHookState, tableDesc *tabledesc.Immutable,
+) (*row.Fetcher, error) {
+	var colIdxMap catalog.TableColMap
+	var valNeededForCol util.FastIntSet
+	for colIdx := range tableDesc.Columns {
+		colIdxMap.Set(tableDesc.Columns[colIdx].ID, colIdx)
+	}
+	valNeededForCol.AddRange(0, len(tableDesc.Columns)-1)
+
+	var rf row.Fetcher
+	if err := rf.Init(
+		ctx,
+		p.ExecCfg().Codec,
+		false, /* reverse */
+		descpb.ScanLockingStrength_FOR_NONE,
+		descpb.ScanLockingWaitPolicy_BLOCK,
+		false, /* isCheck */
+		&rowenc.DatumAlloc{},
+		nil, /* memMonitor */
+		row.FetcherTableArgs{
+			Spans:            tableDesc.AllIndexSpans(p.ExecCfg().Codec),
+			Desc:             tableDesc,
+			Index:            tableDesc.GetPrimaryIndex(),
+			ColIdxMap:        colIdxMap,
+			IsSecondaryIndex: false,
+			Cols:             tableDesc.Columns,
+			ValNeededForCol:  valNeededForCol,
+		},
+	); err != nil {
+		return nil, err
+	}
+	return &rf, nil
+}
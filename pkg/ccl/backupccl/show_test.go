@@ -12,7 +12,10 @@ import (
 	"context"
 	gosql "database/sql"
 	"fmt"
+	"io/ioutil"
 	"net/url"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -410,6 +413,105 @@ func TestShowBackups(t *testing.T) {
 	require.Equal(t, 3, len(b2))
 }
 
+func TestShowBackupValidate(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 11
+	_, _, sqlDB, tempDir, cleanupFn := BackupRestoreTestSetup(t, singleNode, numAccounts, InitManualReplication)
+	defer cleanupFn()
+
+	const collection = LocalFoo + "/validate"
+	sqlDB.Exec(t, `BACKUP data.bank INTO $1`, collection)
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1`)
+	sqlDB.Exec(t, `BACKUP data.bank INTO LATEST IN $1`, collection)
+	subdir := sqlDB.QueryStr(t, `SHOW BACKUPS IN $1`, collection)[0][0]
+	backup := collection + "/" + subdir
+
+	// A backup which was left alone has no problems.
+	require.Empty(t, sqlDB.QueryStr(t, `SHOW BACKUP VALIDATE $1`, backup))
+	require.Empty(t, sqlDB.QueryStr(t, `SHOW BACKUP VALIDATE $1 WITH check_data`, backup))
+
+	backupDir := filepath.Join(tempDir, "foo", "validate", subdir)
+	fullFiles, err := filepath.Glob(filepath.Join(backupDir, "*.sst"))
+	require.NoError(t, err)
+	require.NotEmpty(t, fullFiles)
+	incFiles, err := filepath.Glob(filepath.Join(backupDir, "[0-9]*", "[0-9]*.[0-9][0-9]", "*.sst"))
+	require.NoError(t, err)
+	require.NotEmpty(t, incFiles)
+	incDir, err := filepath.Rel(backupDir, filepath.Dir(incFiles[0]))
+	require.NoError(t, err)
+
+	// Corrupt a file of the full backup and remove a file of the incremental
+	// layer. Both are reported, by the layer of the backup they belong to.
+	corrupted, err := ioutil.ReadFile(fullFiles[0])
+	require.NoError(t, err)
+	for i := len(corrupted) / 2; i < len(corrupted)/2+8 && i < len(corrupted); i++ {
+		corrupted[i] ^= 0xff
+	}
+	require.NoError(t, ioutil.WriteFile(fullFiles[0], corrupted, 0644))
+	require.NoError(t, os.Remove(incFiles[0]))
+
+	expected := [][]string{
+		{"/", filepath.Base(fullFiles[0]), "checksum mismatch"},
+		{"/" + incDir, filepath.Base(incFiles[0]), "file does not exist"},
+	}
+	require.Equal(t, expected, sqlDB.QueryStr(t, `SHOW BACKUP VALIDATE $1`, backup))
+	require.Equal(t, expected, sqlDB.QueryStr(t, `SHOW BACKUP VALIDATE $1 WITH check_data`, backup))
+	sqlDB.ExpectErr(t, `checksum mismatch`, `RESTORE data.bank FROM $1 WITH into_db = 'defaultdb'`, backup)
+}
+
+func TestShowBackupRows(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const numAccounts = 1
+	_, _, sqlDB, _, cleanupFn := BackupRestoreTestSetup(t, singleNode, numAccounts, InitManualReplication)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `CREATE TYPE data.color AS ENUM ('red', 'blue')`)
+	sqlDB.Exec(t, `CREATE TABLE data.t (
+		a INT PRIMARY KEY, b STRING, c data.color, FAMILY f1 (a, b), FAMILY f2 (c)
+	)`)
+	sqlDB.Exec(t, `INSERT INTO data.t VALUES (1, 'x', 'red'), (2, 'y', 'blue')`)
+
+	const collection = LocalFoo + "/rows"
+	sqlDB.Exec(t, `BACKUP data.t INTO $1 WITH revision_history`, collection)
+	sqlDB.Exec(t, `UPDATE data.t SET b = 'z' WHERE a = 1`)
+	sqlDB.Exec(t, `UPDATE data.t SET c = 'blue' WHERE a = 1`)
+	sqlDB.Exec(t, `DELETE FROM data.t WHERE a = 2`)
+	sqlDB.Exec(t, `BACKUP data.t INTO LATEST IN $1 WITH revision_history`, collection)
+	subdir := sqlDB.QueryStr(t, `SHOW BACKUPS IN $1`, collection)[0][0]
+	backup := collection + "/" + subdir
+
+	// The revisions of each row are listed from the oldest to the newest, across
+	// the layers of the backup, with the values of all the column families of
+	// the row at each revision.
+	require.Equal(t, [][]string{
+		{"1", "false", `{"a": 1, "b": "x", "c": "red"}`},
+		{"1", "false", `{"a": 1, "b": "z", "c": "red"}`},
+		{"1", "false", `{"a": 1, "b": "z", "c": "blue"}`},
+		{"2", "false", `{"a": 2, "b": "y", "c": "blue"}`},
+		{"2", "true", "NULL"},
+	}, sqlDB.QueryStr(t,
+		`SELECT key, deleted, row FROM [SHOW BACKUP ROWS FOR TABLE data.t KEYS (1, 2, 3) FROM $1]`, backup))
+
+	// The timestamp of the newest revision of a row is the one it has now.
+	require.Equal(t, [][]string{{"true"}}, sqlDB.QueryStr(t,
+		`SELECT max(mvcc_timestamp) = (SELECT crdb_internal_mvcc_timestamp FROM data.t WHERE a = 1)
+		   FROM [SHOW BACKUP ROWS FOR TABLE data.t KEYS (1) FROM $1]`, backup))
+
+	sqlDB.ExpectErr(t, `table "data.nope" does not exist`,
+		`SHOW BACKUP ROWS FOR TABLE data.nope KEYS (1) FROM $1`, backup)
+	sqlDB.ExpectErr(t, `could not parse "one" as type int`,
+		`SHOW BACKUP ROWS FOR TABLE data.t KEYS ('one') FROM $1`, backup)
+
+	const latest = LocalFoo + "/latest"
+	sqlDB.Exec(t, `BACKUP data.t TO $1`, latest)
+	sqlDB.ExpectErr(t, `SHOW BACKUP ROWS requires a backup taken with the revision_history option`,
+		`SHOW BACKUP ROWS FOR TABLE data.t KEYS (1) FROM $1`, latest)
+}
+
 func TestShowBackupTenants(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
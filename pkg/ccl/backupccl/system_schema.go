@@ -185,6 +185,9 @@ var systemTableBackupConfiguration = map[string]systemBackupConfiguration{
 	systemschema.NamespaceTable.Name: {
 		includeInClusterBackup: optOutOfClusterBackup,
 	},
+	systemschema.NotificationsTable.Name: {
+		includeInClusterBackup: optOutOfClusterBackup,
+	},
 	systemschema.DeprecatedNamespaceTable.Name: {
 		includeInClusterBackup: optOutOfClusterBackup,
 	},
@@ -23,6 +23,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/systemschema"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
@@ -720,7 +722,14 @@ func ResolveTargetsToDescriptors(
 	}
 
 	if targets == nil {
-		return fullClusterTargetsBackup(allDescs)
+		descs, dbIDs, err := fullClusterTargetsBackup(allDescs)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := checkNoFunctionsInBackup(allDescs, descs, dbIDs); err != nil {
+			return nil, nil, err
+		}
+		return descs, dbIDs, nil
 	}
 
 	var matched descriptorsMatched
@@ -728,6 +737,13 @@ func ResolveTargetsToDescriptors(
 		p.CurrentDatabase(), p.CurrentSearchPath(), allDescs, *targets); err != nil {
 		return nil, nil, err
 	}
+	requestedDBIDs := make([]descpb.ID, 0, len(matched.requestedDBs))
+	for _, db := range matched.requestedDBs {
+		requestedDBIDs = append(requestedDBIDs, db.GetID())
+	}
+	if err := checkNoFunctionsInBackup(allDescs, matched.descs, requestedDBIDs); err != nil {
+		return nil, nil, err
+	}
 
 	// Ensure interleaved tables appear after their parent. Since parents must be
 	// created before their children, simply sorting by ID accomplishes this.
@@ -794,6 +810,45 @@ func fullClusterTargets(
 	return fullClusterDescs, fullClusterDBs, nil
 }
 
+// checkNoFunctionsInBackup returns an error if any of the given databases
+// contains a user-defined function, or if any of the descriptors to back up is
+// a view calling one. BACKUP does not handle function descriptors yet, so
+// rather than silently leaving them out of the backup we refuse to back up
+// such objects.
+func checkNoFunctionsInBackup(
+	allDescs []catalog.Descriptor, descs []catalog.Descriptor, dbIDs []descpb.ID,
+) error {
+	dbs := make(map[descpb.ID]struct{}, len(dbIDs))
+	for _, id := range dbIDs {
+		dbs[id] = struct{}{}
+	}
+	dbNames := make(map[descpb.ID]string)
+	for _, desc := range allDescs {
+		if db, ok := desc.(catalog.DatabaseDescriptor); ok {
+			dbNames[db.GetID()] = db.GetName()
+		}
+	}
+	for _, desc := range allDescs {
+		fn, ok := desc.(catalog.FunctionDescriptor)
+		if !ok || fn.Dropped() {
+			continue
+		}
+		if _, ok := dbs[fn.GetParentID()]; ok {
+			return pgerror.Newf(pgcode.FeatureNotSupported,
+				"cannot back up database %q: it contains function %q and BACKUP does not "+
+					"support user-defined functions", dbNames[fn.GetParentID()], fn.GetName())
+		}
+	}
+	for _, desc := range descs {
+		if tbl, ok := desc.(catalog.TableDescriptor); ok && len(tbl.TableDesc().DependsOnFunctions) > 0 {
+			return pgerror.Newf(pgcode.FeatureNotSupported,
+				"cannot back up view %q: it calls user-defined functions and BACKUP does not "+
+					"support them", tbl.GetName())
+		}
+	}
+	return nil
+}
+
 func lookupDatabaseID(
 	ctx context.Context, txn *kv.Txn, codec keys.SQLCodec, name string,
 ) (descpb.ID, error) {
@@ -76,12 +76,38 @@ type avroLogicalType struct {
 	Scale       int            `json:"scale,omitempty"`
 }
 
+// avroLogicalTypeUUID is the logical type of UUIDs. The avro library doesn't
+// know about it, so it treats UUIDs as plain strings.
+const avroLogicalTypeUUID = `uuid`
+
+// avroEnumType is our representation of the schema of an avro enum.
+// Serializing it to JSON gives the standard schema representation.
+type avroEnumType struct {
+	SchemaType string   `json:"type"`
+	Name       string   `json:"name"`
+	Symbols    []string `json:"symbols"`
+}
+
+// avroArrayType is our representation of the schema of an avro array.
+// Serializing it to JSON gives the standard schema representation.
+type avroArrayType struct {
+	SchemaType string         `json:"type"`
+	Items      avroSchemaType `json:"items"`
+}
+
 func avroUnionKey(t avroSchemaType) string {
 	switch s := t.(type) {
 	case string:
 		return s
 	case avroLogicalType:
+		if s.LogicalType == avroLogicalTypeUUID {
+			return avroUnionKey(s.SchemaType)
+		}
 		return avroUnionKey(s.SchemaType) + `.` + s.LogicalType
+	case *avroEnumType:
+		return s.Name
+	case *avroArrayType:
+		return s.SchemaType
 	case *avroRecord:
 		return s.Name
 	default:
@@ -141,8 +167,12 @@ type avroEnvelopeRecord struct {
 }
 
 // columnDescToAvroSchema converts a column descriptor into its corresponding
-// avro field schema.
-func columnDescToAvroSchema(colDesc *descpb.ColumnDescriptor) (*avroSchemaField, error) {
+// avro field schema. Named avro types (i.e. enums) are named after the column,
+// in the namespace of the given record name, so that they are unique in any
+// schema the record is part of.
+func columnDescToAvroSchema(
+	colDesc *descpb.ColumnDescriptor, recordName string,
+) (*avroSchemaField, error) {
 	schema := &avroSchemaField{
 		Name:     SQLNameToAvroName(colDesc.Name),
 		Metadata: colDesc.SQLStringNotHumanReadable(),
@@ -150,38 +180,69 @@ func columnDescToAvroSchema(colDesc *descpb.ColumnDescriptor) (*avroSchemaField,
 		typ:      colDesc.Type,
 	}
 
-	var avroType avroSchemaType
-	switch colDesc.Type.Family() {
+	avroType, encodeFn, decodeFn, err := typeToAvroSchema(colDesc.Type, recordName+`.`+schema.Name)
+	if err != nil {
+		return nil, errors.Wrapf(err, `column %s`, colDesc.Name)
+	}
+	// Make every field optional by unioning it with null, so that all schema
+	// evolutions for a table are considered "backward compatible" by avro. This
+	// means that the Avro type doesn't mirror the column's nullability, but it
+	// makes it much easier to work with long histories of table data afterward,
+	// especially for things like loading into analytics databases.
+	schema.SchemaType, encodeFn, decodeFn = nullableAvroSchema(avroType, encodeFn, decodeFn)
+	schema.encodeFn = func(d tree.Datum) (interface{}, error) {
+		encoded, err := encodeFn(d)
+		if err != nil {
+			return nil, errors.Wrapf(err, `column %s`, colDesc.Name)
+		}
+		return encoded, nil
+	}
+	schema.decodeFn = decodeFn
+	return schema, nil
+}
+
+// typeToAvroSchema returns the avro schema of the given SQL type, along with
+// the functions converting between its non-NULL datums and their avro native
+// representations. The given full name is used for named avro types.
+func typeToAvroSchema(
+	typ *types.T, fullName string,
+) (
+	avroType avroSchemaType,
+	encodeFn func(tree.Datum) (interface{}, error),
+	decodeFn func(interface{}) (tree.Datum, error),
+	_ error,
+) {
+	switch typ.Family() {
 	case types.IntFamily:
 		avroType = avroSchemaLong
-		schema.encodeFn = func(d tree.Datum) (interface{}, error) {
+		encodeFn = func(d tree.Datum) (interface{}, error) {
 			return int64(*d.(*tree.DInt)), nil
 		}
-		schema.decodeFn = func(x interface{}) (tree.Datum, error) {
+		decodeFn = func(x interface{}) (tree.Datum, error) {
 			return tree.NewDInt(tree.DInt(x.(int64))), nil
 		}
 	case types.BoolFamily:
 		avroType = avroSchemaBoolean
-		schema.encodeFn = func(d tree.Datum) (interface{}, error) {
+		encodeFn = func(d tree.Datum) (interface{}, error) {
 			return bool(*d.(*tree.DBool)), nil
 		}
-		schema.decodeFn = func(x interface{}) (tree.Datum, error) {
+		decodeFn = func(x interface{}) (tree.Datum, error) {
 			return tree.MakeDBool(tree.DBool(x.(bool))), nil
 		}
 	case types.FloatFamily:
 		avroType = avroSchemaDouble
-		schema.encodeFn = func(d tree.Datum) (interface{}, error) {
+		encodeFn = func(d tree.Datum) (interface{}, error) {
 			return float64(*d.(*tree.DFloat)), nil
 		}
-		schema.decodeFn = func(x interface{}) (tree.Datum, error) {
+		decodeFn = func(x interface{}) (tree.Datum, error) {
 			return tree.NewDFloat(tree.DFloat(x.(float64))), nil
 		}
 	case types.Box2DFamily:
 		avroType = avroSchemaString
-		schema.encodeFn = func(d tree.Datum) (interface{}, error) {
+		encodeFn = func(d tree.Datum) (interface{}, error) {
 			return d.(*tree.DBox2D).CartesianBoundingBox.Repr(), nil
 		}
-		schema.decodeFn = func(x interface{}) (tree.Datum, error) {
+		decodeFn = func(x interface{}) (tree.Datum, error) {
 			b, err := geo.ParseCartesianBoundingBox(x.(string))
 			if err != nil {
 				return nil, err
@@ -190,10 +251,10 @@ func columnDescToAvroSchema(colDesc *descpb.ColumnDescriptor) (*avroSchemaField,
 		}
 	case types.GeographyFamily:
 		avroType = avroSchemaBytes
-		schema.encodeFn = func(d tree.Datum) (interface{}, error) {
+		encodeFn = func(d tree.Datum) (interface{}, error) {
 			return []byte(d.(*tree.DGeography).EWKB()), nil
 		}
-		schema.decodeFn = func(x interface{}) (tree.Datum, error) {
+		decodeFn = func(x interface{}) (tree.Datum, error) {
 			g, err := geo.ParseGeographyFromEWKBUnsafe(geopb.EWKB(x.([]byte)))
 			if err != nil {
 				return nil, err
@@ -202,10 +263,10 @@ func columnDescToAvroSchema(colDesc *descpb.ColumnDescriptor) (*avroSchemaField,
 		}
 	case types.GeometryFamily:
 		avroType = avroSchemaBytes
-		schema.encodeFn = func(d tree.Datum) (interface{}, error) {
+		encodeFn = func(d tree.Datum) (interface{}, error) {
 			return []byte(d.(*tree.DGeometry).EWKB()), nil
 		}
-		schema.decodeFn = func(x interface{}) (tree.Datum, error) {
+		decodeFn = func(x interface{}) (tree.Datum, error) {
 			g, err := geo.ParseGeometryFromEWKBUnsafe(geopb.EWKB(x.([]byte)))
 			if err != nil {
 				return nil, err
@@ -214,18 +275,18 @@ func columnDescToAvroSchema(colDesc *descpb.ColumnDescriptor) (*avroSchemaField,
 		}
 	case types.StringFamily:
 		avroType = avroSchemaString
-		schema.encodeFn = func(d tree.Datum) (interface{}, error) {
+		encodeFn = func(d tree.Datum) (interface{}, error) {
 			return string(*d.(*tree.DString)), nil
 		}
-		schema.decodeFn = func(x interface{}) (tree.Datum, error) {
+		decodeFn = func(x interface{}) (tree.Datum, error) {
 			return tree.NewDString(x.(string)), nil
 		}
 	case types.BytesFamily:
 		avroType = avroSchemaBytes
-		schema.encodeFn = func(d tree.Datum) (interface{}, error) {
+		encodeFn = func(d tree.Datum) (interface{}, error) {
 			return []byte(*d.(*tree.DBytes)), nil
 		}
-		schema.decodeFn = func(x interface{}) (tree.Datum, error) {
+		decodeFn = func(x interface{}) (tree.Datum, error) {
 			return tree.NewDBytes(tree.DBytes(x.([]byte))), nil
 		}
 	case types.DateFamily:
@@ -233,16 +294,15 @@ func columnDescToAvroSchema(colDesc *descpb.ColumnDescriptor) (*avroSchemaField,
 			SchemaType:  avroSchemaInt,
 			LogicalType: `date`,
 		}
-		schema.encodeFn = func(d tree.Datum) (interface{}, error) {
+		encodeFn = func(d tree.Datum) (interface{}, error) {
 			date := *d.(*tree.DDate)
 			if !date.IsFinite() {
-				return nil, errors.Errorf(
-					`column %s: infinite date not yet supported with avro`, colDesc.Name)
+				return nil, errors.New(`infinite date not yet supported with avro`)
 			}
 			// The avro library requires us to return this as a time.Time.
 			return date.ToTime()
 		}
-		schema.decodeFn = func(x interface{}) (tree.Datum, error) {
+		decodeFn = func(x interface{}) (tree.Datum, error) {
 			// The avro library hands this back as a time.Time.
 			return tree.NewDDateFromTime(x.(time.Time))
 		}
@@ -251,12 +311,12 @@ func columnDescToAvroSchema(colDesc *descpb.ColumnDescriptor) (*avroSchemaField,
 			SchemaType:  avroSchemaLong,
 			LogicalType: `time-micros`,
 		}
-		schema.encodeFn = func(d tree.Datum) (interface{}, error) {
+		encodeFn = func(d tree.Datum) (interface{}, error) {
 			// The avro library requires us to return this as a time.Duration.
 			duration := time.Duration(*d.(*tree.DTime)) * time.Microsecond
 			return duration, nil
 		}
-		schema.decodeFn = func(x interface{}) (tree.Datum, error) {
+		decodeFn = func(x interface{}) (tree.Datum, error) {
 			// The avro library hands this back as a time.Duration.
 			micros := x.(time.Duration) / time.Microsecond
 			return tree.MakeDTime(timeofday.TimeOfDay(micros)), nil
@@ -265,10 +325,10 @@ func columnDescToAvroSchema(colDesc *descpb.ColumnDescriptor) (*avroSchemaField,
 		avroType = avroSchemaString
 		// We cannot encode this as a long, as it does not encode
 		// timezone correctly.
-		schema.encodeFn = func(d tree.Datum) (interface{}, error) {
+		encodeFn = func(d tree.Datum) (interface{}, error) {
 			return d.(*tree.DTimeTZ).TimeTZ.String(), nil
 		}
-		schema.decodeFn = func(x interface{}) (tree.Datum, error) {
+		decodeFn = func(x interface{}) (tree.Datum, error) {
 			d, _, err := tree.ParseDTimeTZ(nil, x.(string), time.Microsecond)
 			return d, err
 		}
@@ -277,10 +337,10 @@ func columnDescToAvroSchema(colDesc *descpb.ColumnDescriptor) (*avroSchemaField,
 			SchemaType:  avroSchemaLong,
 			LogicalType: `timestamp-micros`,
 		}
-		schema.encodeFn = func(d tree.Datum) (interface{}, error) {
+		encodeFn = func(d tree.Datum) (interface{}, error) {
 			return d.(*tree.DTimestamp).Time, nil
 		}
-		schema.decodeFn = func(x interface{}) (tree.Datum, error) {
+		decodeFn = func(x interface{}) (tree.Datum, error) {
 			return tree.MakeDTimestamp(x.(time.Time), time.Microsecond)
 		}
 	case types.TimestampTZFamily:
@@ -288,84 +348,145 @@ func columnDescToAvroSchema(colDesc *descpb.ColumnDescriptor) (*avroSchemaField,
 			SchemaType:  avroSchemaLong,
 			LogicalType: `timestamp-micros`,
 		}
-		schema.encodeFn = func(d tree.Datum) (interface{}, error) {
+		encodeFn = func(d tree.Datum) (interface{}, error) {
 			return d.(*tree.DTimestampTZ).Time, nil
 		}
-		schema.decodeFn = func(x interface{}) (tree.Datum, error) {
+		decodeFn = func(x interface{}) (tree.Datum, error) {
 			return tree.MakeDTimestampTZ(x.(time.Time), time.Microsecond)
 		}
 	case types.DecimalFamily:
-		if colDesc.Type.Precision() == 0 {
-			return nil, errors.Errorf(
-				`column %s: decimal with no precision not yet supported with avro`, colDesc.Name)
+		if typ.Precision() == 0 {
+			// The avro decimal logical type needs a precision, so decimals
+			// without one are encoded as strings, which roundtrip exactly.
+			avroType = avroSchemaString
+			encodeFn = func(d tree.Datum) (interface{}, error) {
+				return d.(*tree.DDecimal).Decimal.String(), nil
+			}
+			decodeFn = func(x interface{}) (tree.Datum, error) {
+				return tree.ParseDDecimal(x.(string))
+			}
+			break
 		}
 		avroType = avroLogicalType{
 			SchemaType:  avroSchemaBytes,
 			LogicalType: `decimal`,
-			Precision:   int(colDesc.Type.Precision()),
-			Scale:       int(colDesc.Type.Width()),
+			Precision:   int(typ.Precision()),
+			Scale:       int(typ.Width()),
 		}
-		schema.encodeFn = func(d tree.Datum) (interface{}, error) {
+		encodeFn = func(d tree.Datum) (interface{}, error) {
 			dec := d.(*tree.DDecimal).Decimal
 			// TODO(dan): For the cases that the avro defined decimal format
-			// would not roundtrip, serialize the decimal as a string. Also
-			// support the unspecified precision/scale case in this branch. We
-			// can't currently do this without surgery to the avro library we're
+			// would not roundtrip, serialize the decimal as a string. We can't
+			// currently do this without surgery to the avro library we're
 			// using and that's too scary leading up to 2.1.0.
-			rat, err := decimalToRat(dec, colDesc.Type.Width())
+			rat, err := decimalToRat(dec, typ.Width())
 			if err != nil {
 				return nil, err
 			}
 			return &rat, nil
 		}
-		schema.decodeFn = func(x interface{}) (tree.Datum, error) {
-			return &tree.DDecimal{Decimal: ratToDecimal(*x.(*big.Rat), colDesc.Type.Width())}, nil
+		decodeFn = func(x interface{}) (tree.Datum, error) {
+			return &tree.DDecimal{Decimal: ratToDecimal(*x.(*big.Rat), typ.Width())}, nil
 		}
 	case types.UuidFamily:
-		// Should be logical type of "uuid", but the avro library doesn't support
-		// that yet.
-		avroType = avroSchemaString
-		schema.encodeFn = func(d tree.Datum) (interface{}, error) {
+		avroType = avroLogicalType{
+			SchemaType:  avroSchemaString,
+			LogicalType: avroLogicalTypeUUID,
+		}
+		encodeFn = func(d tree.Datum) (interface{}, error) {
 			return d.(*tree.DUuid).UUID.String(), nil
 		}
-		schema.decodeFn = func(x interface{}) (tree.Datum, error) {
+		decodeFn = func(x interface{}) (tree.Datum, error) {
 			return tree.ParseDUuidFromString(x.(string))
 		}
 	case types.INetFamily:
 		avroType = avroSchemaString
-		schema.encodeFn = func(d tree.Datum) (interface{}, error) {
+		encodeFn = func(d tree.Datum) (interface{}, error) {
 			return d.(*tree.DIPAddr).IPAddr.String(), nil
 		}
-		schema.decodeFn = func(x interface{}) (tree.Datum, error) {
+		decodeFn = func(x interface{}) (tree.Datum, error) {
 			return tree.ParseDIPAddrFromINetString(x.(string))
 		}
 	case types.JsonFamily:
 		avroType = avroSchemaString
-		schema.encodeFn = func(d tree.Datum) (interface{}, error) {
+		encodeFn = func(d tree.Datum) (interface{}, error) {
 			return d.(*tree.DJSON).JSON.String(), nil
 		}
-		schema.decodeFn = func(x interface{}) (tree.Datum, error) {
+		decodeFn = func(x interface{}) (tree.Datum, error) {
 			return tree.ParseDJSON(x.(string))
 		}
+	case types.EnumFamily:
+		if typ.TypeMeta.EnumData == nil {
+			return nil, nil, nil, errors.AssertionFailedf(`enum type %s is not hydrated`, typ.SQLString())
+		}
+		// Avro enum symbols have the same syntax as avro names, so the logical
+		// representations of the enum values are escaped like SQL names.
+		reps := typ.TypeMeta.EnumData.LogicalRepresentations
+		enum := &avroEnumType{SchemaType: `enum`, Name: fullName, Symbols: make([]string, len(reps))}
+		for i, rep := range reps {
+			enum.Symbols[i] = SQLNameToAvroName(rep)
+		}
+		avroType = enum
+		encodeFn = func(d tree.Datum) (interface{}, error) {
+			return SQLNameToAvroName(d.(*tree.DEnum).LogicalRep), nil
+		}
+		decodeFn = func(x interface{}) (tree.Datum, error) {
+			return tree.MakeDEnumFromLogicalRepresentation(typ, AvroNameToSQLName(x.(string)))
+		}
+	case types.ArrayFamily:
+		itemType, itemEncodeFn, itemDecodeFn, err := typeToAvroSchema(typ.ArrayContents(), fullName)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		// Arrays can hold NULLs, so their items are optional like fields.
+		itemType, itemEncodeFn, itemDecodeFn = nullableAvroSchema(itemType, itemEncodeFn, itemDecodeFn)
+		avroType = &avroArrayType{SchemaType: `array`, Items: itemType}
+		encodeFn = func(d tree.Datum) (interface{}, error) {
+			arr := d.(*tree.DArray)
+			items := make([]interface{}, len(arr.Array))
+			for i, item := range arr.Array {
+				var err error
+				if items[i], err = itemEncodeFn(item); err != nil {
+					return nil, err
+				}
+			}
+			return items, nil
+		}
+		decodeFn = func(x interface{}) (tree.Datum, error) {
+			arr := tree.NewDArray(typ.ArrayContents())
+			for _, item := range x.([]interface{}) {
+				d, err := itemDecodeFn(item)
+				if err != nil {
+					return nil, err
+				}
+				if err := arr.Append(d); err != nil {
+					return nil, err
+				}
+			}
+			return arr, nil
+		}
 	default:
-		return nil, errors.Errorf(`column %s: type %s not yet supported with avro`,
-			colDesc.Name, colDesc.Type.SQLString())
+		return nil, nil, nil, errors.Errorf(`type %s not yet supported with avro`, typ.SQLString())
 	}
-	schema.SchemaType = avroType
+	return avroType, encodeFn, decodeFn, nil
+}
 
-	// Make every field optional by unioning it with null, so that all schema
-	// evolutions for a table are considered "backward compatible" by avro. This
-	// means that the Avro type doesn't mirror the column's nullability, but it
-	// makes it much easier to work with long histories of table data afterward,
-	// especially for things like loading into analytics databases.
-	{
-		// The default for a union type is the default for the first element of
-		// the union.
-		schema.SchemaType = []avroSchemaType{avroSchemaNull, avroType}
-		encodeFn := schema.encodeFn
-		decodeFn := schema.decodeFn
-		unionKey := avroUnionKey(avroType)
-		schema.encodeFn = func(d tree.Datum) (interface{}, error) {
+// nullableAvroSchema unions the given avro schema with null, and wraps the
+// given functions to convert NULLs to and from avro null.
+func nullableAvroSchema(
+	avroType avroSchemaType,
+	encodeFn func(tree.Datum) (interface{}, error),
+	decodeFn func(interface{}) (tree.Datum, error),
+) (
+	avroSchemaType,
+	func(tree.Datum) (interface{}, error),
+	func(interface{}) (tree.Datum, error),
+) {
+	// The default for a union type is the default for the first element of the
+	// union.
+	unionKey := avroUnionKey(avroType)
+	return []avroSchemaType{avroSchemaNull, avroType},
+		func(d tree.Datum) (interface{}, error) {
 			if d == tree.DNull {
 				return goavro.Union(avroSchemaNull, nil), nil
 			}
@@ -374,16 +495,13 @@ func columnDescToAvroSchema(colDesc *descpb.ColumnDescriptor) (*avroSchemaField,
 				return nil, err
 			}
 			return goavro.Union(unionKey, encoded), nil
-		}
-		schema.decodeFn = func(x interface{}) (tree.Datum, error) {
+		},
+		func(x interface{}) (tree.Datum, error) {
 			if x == nil {
 				return tree.DNull, nil
 			}
 			return decodeFn(x.(map[string]interface{})[unionKey])
 		}
-	}
-
-	return schema, nil
 }
 
 // indexToAvroSchema converts a column descriptor into its corresponding avro
@@ -406,7 +524,7 @@ func indexToAvroSchema(
 			return nil, errors.Errorf(`unknown column id: %d`, colID)
 		}
 		col := tableDesc.GetColumnAtIdx(colIdx)
-		field, err := columnDescToAvroSchema(col)
+		field, err := columnDescToAvroSchema(col, schema.Name)
 		if err != nil {
 			return nil, err
 		}
@@ -452,7 +570,7 @@ func tableToAvroSchema(
 	}
 	for colIdx := range tableDesc.GetPublicColumns() {
 		col := tableDesc.GetColumnAtIdx(colIdx)
-		field, err := columnDescToAvroSchema(col)
+		field, err := columnDescToAvroSchema(col, schema.Name)
 		if err != nil {
 			return nil, err
 		}
@@ -170,6 +170,21 @@ func TestAvroSchema(t *testing.T) {
 			schema: `(a INT PRIMARY KEY, b DECIMAL (3,2), c DECIMAL (2, 1))`,
 			values: `(1, 1.23, 4.5)`,
 		},
+		{
+			name:   `UNBOUNDED_DECIMAL`,
+			schema: `(a INT PRIMARY KEY, b DECIMAL)`,
+			values: `(1, 1.23), (2, 12345678901234567890.123456789), (3, 'NaN'), (4, '-Infinity')`,
+		},
+		{
+			name:   `INT_ARRAY`,
+			schema: `(a INT PRIMARY KEY, b INT[])`,
+			values: `(1, ARRAY[1, NULL, 3]), (2, ARRAY[]), (3, NULL)`,
+		},
+		{
+			name:   `STRING_ARRAY`,
+			schema: `(a INT PRIMARY KEY, b STRING[])`,
+			values: `(1, ARRAY['a', NULL]), (2, ARRAY[])`,
+		},
 	}
 	// Generate a test for each column type with a random datum of that type.
 	for _, typ := range types.OidToType {
@@ -313,22 +328,25 @@ func TestAvroSchema(t *testing.T) {
 			`TIMETZ`:       `["null","string"]`,
 			`TIMESTAMP`:    `["null",{"type":"long","logicalType":"timestamp-micros"}]`,
 			`TIMESTAMPTZ`:  `["null",{"type":"long","logicalType":"timestamp-micros"}]`,
-			`UUID`:         `["null","string"]`,
+			`UUID`:         `["null",{"type":"string","logicalType":"uuid"}]`,
+			`DECIMAL`:      `["null","string"]`,
 			`DECIMAL(3,2)`: `["null",{"type":"bytes","logicalType":"decimal","precision":3,"scale":2}]`,
+			`INT8[]`:       `["null",{"type":"array","items":["null","long"]}]`,
+			`STRING[]`:     `["null",{"type":"array","items":["null","string"]}]`,
 		}
 
-		for _, typ := range types.Scalar {
+		typs := append([]*types.T(nil), types.Scalar...)
+		typs = append(typs, types.MakeDecimal(3, 2), types.IntArray, types.StringArray)
+		for _, typ := range typs {
 			switch typ.Family() {
 			case types.IntervalFamily, types.OidFamily, types.BitFamily:
 				continue
-			case types.DecimalFamily:
-				typ = types.MakeDecimal(3, 2)
 			}
 
 			colType := typ.SQLString()
 			tableDesc, err := parseTableDesc(`CREATE TABLE foo (pk INT PRIMARY KEY, a ` + colType + `)`)
 			require.NoError(t, err)
-			field, err := columnDescToAvroSchema(tableDesc.GetColumnAtIdx(1))
+			field, err := columnDescToAvroSchema(tableDesc.GetColumnAtIdx(1), `foo`)
 			require.NoError(t, err)
 			schema, err := json.Marshal(field.SchemaType)
 			require.NoError(t, err)
@@ -412,6 +430,11 @@ func TestAvroSchema(t *testing.T) {
 				sql:  `1.2`,
 				avro: `{"bytes.decimal":"\f"}`},
 
+			{sqlType: `DECIMAL`, sql: `NULL`, avro: `null`},
+			{sqlType: `DECIMAL`,
+				sql:  `1.20`,
+				avro: `{"string":"1.20"}`},
+
 			{sqlType: `UUID`, sql: `NULL`, avro: `null`},
 			{sqlType: `UUID`,
 				sql:  `'27f4f4c9-e35a-45dd-9b79-5ff0f9b5fbb0'`,
@@ -441,6 +464,11 @@ func TestAvroSchema(t *testing.T) {
 			{sqlType: `JSONB`,
 				sql:  `'{"b": 1}'`,
 				avro: `{"string":"{\"b\": 1}"}`},
+
+			{sqlType: `INT[]`, sql: `NULL`, avro: `null`},
+			{sqlType: `INT[]`,
+				sql:  `ARRAY[1, NULL]`,
+				avro: `{"array":[{"long":1},null]}`},
 		}
 
 		for _, test := range goldens {
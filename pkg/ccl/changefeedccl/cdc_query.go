@@ -0,0 +1,331 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+// projectedRow holds the columns computed by the projection of a CDC query
+// (see cdcQuery) from a row of the watched table.
+type projectedRow struct {
+	names  []string
+	datums tree.Datums
+}
+
+// cdcQuery evaluates the projection and the filter of a CREATE CHANGEFEED ...
+// AS SELECT statement on the rows decoded by a changefeed.
+//
+// The expressions of the query refer to the columns of the watched table by
+// name, so they are compiled separately for each version of the table
+// descriptor that rows are decoded with. A query that references a column
+// which is dropped fails to compile for the versions of the table without
+// that column.
+//
+// A cdcQuery is not threadsafe.
+type cdcQuery struct {
+	sel     *tree.SelectClause
+	tn      tree.TableName
+	evalCtx *tree.EvalContext
+	plans   map[idVersion]*cdcQueryPlan
+	alloc   rowenc.DatumAlloc
+}
+
+// cdcQueryPlan holds the expressions of a cdcQuery compiled for one version
+// of the table descriptor.
+type cdcQueryPlan struct {
+	names []string
+	exprs []tree.TypedExpr
+	// filter is nil if the query has no WHERE clause.
+	filter tree.TypedExpr
+	row    cdcQueryRow
+}
+
+// cdcQueryRow is the tree.IndexedVarContainer through which the compiled
+// expressions of a cdcQueryPlan access the columns of the row being
+// evaluated.
+type cdcQueryRow struct {
+	cols   []descpb.ColumnDescriptor
+	datums rowenc.EncDatumRow
+	alloc  *rowenc.DatumAlloc
+}
+
+var _ tree.IndexedVarContainer = (*cdcQueryRow)(nil)
+
+// IndexedVarEval implements the tree.IndexedVarContainer interface.
+func (r *cdcQueryRow) IndexedVarEval(idx int, _ *tree.EvalContext) (tree.Datum, error) {
+	datum := &r.datums[idx]
+	if err := datum.EnsureDecoded(r.cols[idx].Type, r.alloc); err != nil {
+		return nil, err
+	}
+	return datum.Datum, nil
+}
+
+// IndexedVarResolvedType implements the tree.IndexedVarContainer interface.
+func (r *cdcQueryRow) IndexedVarResolvedType(idx int) *types.T {
+	return r.cols[idx].Type
+}
+
+// IndexedVarNodeFormatter implements the tree.IndexedVarContainer interface.
+func (r *cdcQueryRow) IndexedVarNodeFormatter(idx int) tree.NodeFormatter {
+	n := tree.Name(r.cols[idx].Name)
+	return &n
+}
+
+// parseCDCQuery parses the SELECT clause of a CREATE CHANGEFEED ... AS SELECT
+// statement, as stored in the Select field of the ChangefeedDetails.
+func parseCDCQuery(sql string) (*tree.SelectClause, error) {
+	stmt, err := parser.ParseOne(sql)
+	if err != nil {
+		return nil, err
+	}
+	if sel, ok := stmt.AST.(*tree.Select); ok {
+		if clause, ok := sel.Select.(*tree.SelectClause); ok {
+			return clause, nil
+		}
+	}
+	return nil, errors.AssertionFailedf(`expected a SELECT clause: %s`, sql)
+}
+
+// makeCDCQuery returns a cdcQuery that evaluates the given SELECT clause,
+// which must select from a single table, using the given EvalContext.
+func makeCDCQuery(sel *tree.SelectClause, evalCtx *tree.EvalContext) (*cdcQuery, error) {
+	if len(sel.From.Tables) != 1 {
+		return nil, errors.AssertionFailedf(`expected a single table: %s`, tree.AsString(sel))
+	}
+	aliased, ok := sel.From.Tables[0].(*tree.AliasedTableExpr)
+	if !ok {
+		return nil, errors.AssertionFailedf(`expected a table name: %s`, tree.AsString(sel))
+	}
+	tn, ok := aliased.Expr.(*tree.TableName)
+	if !ok {
+		return nil, errors.AssertionFailedf(`expected a table name: %s`, tree.AsString(sel))
+	}
+	return &cdcQuery{
+		sel:     sel,
+		tn:      *tn,
+		evalCtx: evalCtx,
+		plans:   make(map[idVersion]*cdcQueryPlan),
+	}, nil
+}
+
+// validateCDCQuery checks that the query of a CREATE CHANGEFEED ... AS SELECT
+// statement can be evaluated on the rows of the given table.
+func validateCDCQuery(
+	ctx context.Context,
+	evalCtx *tree.EvalContext,
+	sel *tree.SelectClause,
+	desc catalog.TableDescriptor,
+) error {
+	q, err := makeCDCQuery(sel, evalCtx)
+	if err != nil {
+		return err
+	}
+	_, err = q.compile(ctx, desc)
+	return err
+}
+
+// planForDesc returns the plan of the query for the given version of the
+// table descriptor, compiling it if necessary.
+func (q *cdcQuery) planForDesc(
+	ctx context.Context, desc catalog.TableDescriptor,
+) (*cdcQueryPlan, error) {
+	idVer := idVersion{id: desc.GetID(), version: desc.GetVersion()}
+	if p, ok := q.plans[idVer]; ok {
+		return p, nil
+	}
+	p, err := q.compile(ctx, desc)
+	if err != nil {
+		return nil, err
+	}
+	q.plans[idVer] = p
+	return p, nil
+}
+
+// compile resolves the column references of the query against the public
+// columns of the given table descriptor, which match 1:1 the datums of the
+// rows decoded with it, and type checks the resulting expressions.
+func (q *cdcQuery) compile(
+	ctx context.Context, desc catalog.TableDescriptor,
+) (*cdcQueryPlan, error) {
+	cols := desc.GetPublicColumns()
+	p := &cdcQueryPlan{row: cdcQueryRow{cols: cols, alloc: &q.alloc}}
+
+	source := colinfo.NewSourceInfoForSingleTable(
+		q.tn, colinfo.ResultColumnsFromColDescs(desc.GetID(), cols),
+	)
+	ivarHelper := tree.MakeIndexedVarHelper(&p.row, len(cols))
+	semaCtx := tree.MakeSemaContext()
+	semaCtx.IVarContainer = &p.row
+	searchPath := q.evalCtx.SessionData.SearchPath
+
+	typeCheck := func(expr tree.Expr, required *types.T, op string) (tree.TypedExpr, error) {
+		var v schemaexpr.NameResolutionVisitor
+		expr, err := schemaexpr.ResolveNamesUsingVisitor(&v, expr, source, ivarHelper, searchPath)
+		if err != nil {
+			return nil, err
+		}
+		// The query is evaluated by the change aggregators, once per change to
+		// a row, with neither the session nor the transaction of the statement,
+		// so it may only use immutable functions and operators.
+		semaCtx.Properties.Require(op, tree.RejectSpecial|tree.RejectSubqueries|
+			tree.RejectStableOperators|tree.RejectVolatileFunctions)
+		return tree.TypeCheckAndRequire(ctx, expr, &semaCtx, required, op)
+	}
+
+	for _, target := range q.sel.Exprs {
+		if vn, ok := target.Expr.(tree.VarName); ok {
+			vn, err := vn.NormalizeVarName()
+			if err != nil {
+				return nil, err
+			}
+			switch vn.(type) {
+			case tree.UnqualifiedStar, *tree.AllColumnsSelector:
+				for i := range cols {
+					p.names = append(p.names, cols[i].Name)
+					p.exprs = append(p.exprs, ivarHelper.IndexedVar(i))
+				}
+				continue
+			}
+		}
+		name, err := tree.GetRenderColName(searchPath, target)
+		if err != nil {
+			return nil, err
+		}
+		expr, err := typeCheck(target.Expr, types.Any, "SELECT")
+		if err != nil {
+			return nil, err
+		}
+		p.names = append(p.names, name)
+		p.exprs = append(p.exprs, expr)
+	}
+
+	// The projection is emitted as a JSON object, whose keys must be unique.
+	seen := make(map[string]struct{}, len(p.names))
+	for _, name := range p.names {
+		if _, ok := seen[name]; ok {
+			return nil, pgerror.Newf(pgcode.DuplicateColumn,
+				`column %q specified more than once in CHANGEFEED query`, name)
+		}
+		seen[name] = struct{}{}
+	}
+
+	if q.sel.Where != nil {
+		filter, err := typeCheck(q.sel.Where.Expr, types.Bool, "WHERE")
+		if err != nil {
+			return nil, err
+		}
+		p.filter = filter
+	}
+	return p, nil
+}
+
+// hasFilter returns whether the query has a WHERE clause. It may be called on
+// a nil cdcQuery.
+func (q *cdcQuery) hasFilter() bool {
+	return q != nil && q.sel.Where != nil
+}
+
+// eval evaluates the query on the given row, setting its projection and the
+// projection of its previous value, if any. It returns false if the row is
+// filtered out by the query.
+//
+// Deletions are never filtered out, since only the primary key columns of a
+// deleted row are known. A row which is updated so that it no longer passes
+// the filter is turned into a deletion if its previous value passed it, so
+// that consumers of the changefeed know it left the set of watched rows. The
+// previous value of the row must be set for this, see withPrevValues.
+func (q *cdcQuery) eval(ctx context.Context, row *encodeRow) (bool, error) {
+	if !row.deleted {
+		p, err := q.planForDesc(ctx, row.tableDesc)
+		if err != nil {
+			return false, err
+		}
+		matches, err := p.matches(q.evalCtx, row.datums)
+		if err != nil {
+			return false, err
+		}
+		if matches {
+			if row.projection, err = p.project(q.evalCtx, row.datums); err != nil {
+				return false, err
+			}
+		} else {
+			if row.prevDatums == nil || row.prevDeleted {
+				return false, nil
+			}
+			prevPlan, err := q.planForDesc(ctx, row.prevTableDesc)
+			if err != nil {
+				return false, err
+			}
+			if prevMatches, err := prevPlan.matches(q.evalCtx, row.prevDatums); err != nil {
+				return false, err
+			} else if !prevMatches {
+				return false, nil
+			}
+			row.deleted = true
+		}
+	}
+	if row.prevDatums != nil && !row.prevDeleted {
+		p, err := q.planForDesc(ctx, row.prevTableDesc)
+		if err != nil {
+			return false, err
+		}
+		if row.prevProjection, err = p.project(q.evalCtx, row.prevDatums); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// matches returns whether the given row passes the filter of the plan.
+func (p *cdcQueryPlan) matches(evalCtx *tree.EvalContext, datums rowenc.EncDatumRow) (bool, error) {
+	if p.filter == nil {
+		return true, nil
+	}
+	d, err := p.eval(evalCtx, datums, p.filter)
+	if err != nil {
+		return false, err
+	}
+	return d == tree.DBoolTrue, nil
+}
+
+func (p *cdcQueryPlan) eval(
+	evalCtx *tree.EvalContext, datums rowenc.EncDatumRow, expr tree.TypedExpr,
+) (tree.Datum, error) {
+	p.row.datums = datums
+	evalCtx.PushIVarContainer(&p.row)
+	defer evalCtx.PopIVarContainer()
+	return expr.Eval(evalCtx)
+}
+
+func (p *cdcQueryPlan) project(
+	evalCtx *tree.EvalContext, datums rowenc.EncDatumRow,
+) (*projectedRow, error) {
+	projection := &projectedRow{names: p.names, datums: make(tree.Datums, len(p.exprs))}
+	for i, expr := range p.exprs {
+		d, err := p.eval(evalCtx, datums, expr)
+		if err != nil {
+			return nil, err
+		}
+		projection.datums[i] = d
+	}
+	return projection, nil
+}
@@ -192,8 +192,12 @@ func (c *sinklessFeed) Resume() error {
 	// but it's convenient to accept the `CREATE CHANGEFEED` syntax from the
 	// test, so we can keep the current abstraction of running each test over
 	// both types. This bit turns what we received into the real sinkless
-	// syntax.
-	create := strings.Replace(c.create, `CREATE CHANGEFEED`, `EXPERIMENTAL CHANGEFEED`, 1)
+	// syntax. The `CREATE CHANGEFEED ... AS SELECT` syntax has no EXPERIMENTAL
+	// variant; it is already sinkless when it has no INTO clause.
+	create := c.create
+	if !strings.Contains(create, ` AS SELECT `) {
+		create = strings.Replace(create, `CREATE CHANGEFEED`, `EXPERIMENTAL CHANGEFEED`, 1)
+	}
 	if !c.latestResolved.IsEmpty() {
 		// NB: The TODO in Next means c.latestResolved is currently never set for
 		// non-json feeds.
@@ -540,6 +544,8 @@ func (f *cloudFeedFactory) Server() serverutils.TestServerInterface {
 type cloudFeedEntry struct {
 	topic          string
 	value, payload []byte
+	// keyInValue is true if the key of the entry is in its JSON value.
+	keyInValue bool
 }
 
 type cloudFeed struct {
@@ -616,15 +622,16 @@ func (c *cloudFeed) Next() (*TestFeedMessage, error) {
 			if len(m.Value) > 0 {
 				// Cloud storage sinks default the `WITH key_in_value` option so that
 				// the key is recoverable. Extract it out of the value (also removing it
-				// so the output matches the other sinks). Note that this assumes the
-				// format is json, this will have to be fixed once we add format=avro
-				// support to cloud storage.
+				// so the output matches the other sinks). Other formats, like csv,
+				// leave the key out of the value and are returned as is.
 				//
 				// TODO(dan): Leave the key in the value if the TestFeed user
 				// specifically requested it.
-				var err error
-				if m.Key, m.Value, err = extractKeyFromJSONValue(m.Value); err != nil {
-					return nil, err
+				if e.keyInValue {
+					var err error
+					if m.Key, m.Value, err = extractKeyFromJSONValue(m.Value); err != nil {
+						return nil, err
+					}
 				}
 
 				seenKey := m.Topic + m.Partition + string(m.Key) + string(m.Value)
@@ -696,13 +703,14 @@ func (c *cloudFeed) walkDir(path string, info os.FileInfo, err error) error {
 		return err
 	}
 	defer f.Close()
-	// NB: This is the logic for JSON. Avro will involve parsing an
-	// "Object Container File".
+	// NB: This is the logic for JSON and CSV (as long as no field contains a
+	// newline). Avro will involve parsing an "Object Container File".
 	s := bufio.NewScanner(f)
 	for s.Scan() {
 		c.rows = append(c.rows, cloudFeedEntry{
-			topic: topic,
-			value: append([]byte(nil), s.Bytes()...),
+			topic:      topic,
+			value:      append([]byte(nil), s.Bytes()...),
+			keyInValue: strings.HasSuffix(path, `.ndjson`),
 		})
 	}
 	return nil
@@ -21,8 +21,10 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/hydratedtables"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/lease"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/row"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/util/bufalloc"
@@ -51,9 +53,13 @@ type emitEntry struct {
 	bufferGetTimestamp time.Time
 }
 
-// kvsToRows gets changed kvs from a closure and converts them into sql rows. It
-// returns a closure that may be repeatedly called to advance the changefeed.
-// The returned closure is not threadsafe.
+// kvsToRows gets changed kvs from a closure and converts them into sql rows. If
+// query is non-nil, rows it filters out are skipped and the others are
+// projected by it. It returns a closure that may be repeatedly called to
+// advance the changefeed. The returned closure is not threadsafe.
+//
+// The previous values of the kvs must be provided by the closure if
+// withPrevValues is true.
 func kvsToRows(
 	ctx context.Context,
 	codec keys.SQLCodec,
@@ -62,35 +68,29 @@ func kvsToRows(
 	leaseMgr *lease.Manager,
 	hydratedTables *hydratedtables.Cache,
 	details jobspb.ChangefeedDetails,
+	query *cdcQuery,
 	inputFn func(context.Context) (kvfeed.Event, error),
 ) func(context.Context) ([]emitEntry, error) {
 	_, withDiff := details.Opts[changefeedbase.OptDiff]
+	// The previous values of the rows are only decoded when they are emitted or
+	// filtered, even if the closure provides them for other reasons.
+	withPrev := withDiff || query.hasFilter()
+	_, withKeyColumns := details.Opts[changefeedbase.OptKeyColumn]
 	rfCache := newRowFetcherCache(ctx, codec, settings, leaseMgr, hydratedTables, db)
 
 	var kvs row.SpanKVFetcher
-	appendEmitEntryForKV := func(
+	appendEmitEntryForRow := func(
 		ctx context.Context,
 		output []emitEntry,
-		kv roachpb.KeyValue,
-		prevVal roachpb.Value,
+		desc *tabledesc.Immutable,
+		target jobspb.ChangefeedTarget,
+		family *descpb.ColumnFamilyDescriptor,
+		kv, prevKV roachpb.KeyValue,
 		schemaTimestamp hlc.Timestamp,
 		prevSchemaTimestamp hlc.Timestamp,
 		bufferGetTimestamp time.Time,
 	) ([]emitEntry, error) {
-
-		desc, err := rfCache.TableDescForKey(ctx, kv.Key, schemaTimestamp)
-		if err != nil {
-			return nil, err
-		}
-		if _, ok := details.Targets[desc.ID]; !ok {
-			// This kv is for an interleaved table that we're not watching.
-			if log.V(3) {
-				log.Infof(ctx, `skipping key from unwatched table %s: %s`, desc.Name, kv.Key)
-			}
-			return nil, nil
-		}
-
-		rf, err := rfCache.RowFetcherForTableDesc(desc)
+		rf, err := rfCache.RowFetcherForTableDesc(desc, family)
 		if err != nil {
 			return nil, err
 		}
@@ -99,7 +99,9 @@ func kvsToRows(
 		var r emitEntry
 		r.bufferGetTimestamp = bufferGetTimestamp
 		{
-			// TODO(dan): Handle tables with multiple column families.
+			// Each kv holds one column family of a row. When the table has
+			// several families, only the columns of the watched family (and the
+			// primary key) are decoded from it.
 			// Reuse kvs to save allocations.
 			kvs.KVs = kvs.KVs[:0]
 			kvs.KVs = append(kvs.KVs, kv)
@@ -114,7 +116,11 @@ func kvsToRows(
 			if r.row.datums == nil {
 				return nil, errors.AssertionFailedf("unexpected empty datums")
 			}
-			r.row.datums = append(rowenc.EncDatumRow(nil), r.row.datums...)
+			if family != nil {
+				r.row.datums, r.row.tableDesc = rfCache.ProjectColumnFamily(desc, family, r.row.datums)
+			} else {
+				r.row.datums = append(rowenc.EncDatumRow(nil), r.row.datums...)
+			}
 			r.row.deleted = rf.RowIsDeleted()
 			r.row.updated = schemaTimestamp
 
@@ -131,25 +137,27 @@ func kvsToRows(
 		}
 
 		// Get prev value, if necessary.
-		if withDiff {
-			prevRF := rf
+		if withPrev {
+			prevRF, prevDesc, prevFamily := rf, desc, family
 			if prevSchemaTimestamp != schemaTimestamp {
 				// If the previous value is being interpreted under a different
 				// version of the schema, fetch the correct table descriptor and
 				// create a new row.Fetcher with it.
-				prevDesc, err := rfCache.TableDescForKey(ctx, kv.Key, prevSchemaTimestamp)
+				prevDesc, err = rfCache.TableDescForKey(ctx, kv.Key, prevSchemaTimestamp)
+				if err != nil {
+					return nil, err
+				}
+				prevFamily, err = changefeedbase.TargetFamily(target, prevDesc)
 				if err != nil {
 					return nil, err
 				}
 
-				prevRF, err = rfCache.RowFetcherForTableDesc(prevDesc)
+				prevRF, err = rfCache.RowFetcherForTableDesc(prevDesc, prevFamily)
 				if err != nil {
 					return nil, err
 				}
 			}
 
-			prevKV := roachpb.KeyValue{Key: kv.Key, Value: prevVal}
-			// TODO(dan): Handle tables with multiple column families.
 			// Reuse kvs to save allocations.
 			kvs.KVs = kvs.KVs[:0]
 			kvs.KVs = append(kvs.KVs, prevKV)
@@ -163,7 +171,12 @@ func kvsToRows(
 			if r.row.prevDatums == nil {
 				return nil, errors.AssertionFailedf("unexpected empty datums")
 			}
-			r.row.prevDatums = append(rowenc.EncDatumRow(nil), r.row.prevDatums...)
+			if prevFamily != nil {
+				r.row.prevDatums, r.row.prevTableDesc = rfCache.ProjectColumnFamily(
+					prevDesc, prevFamily, r.row.prevDatums)
+			} else {
+				r.row.prevDatums = append(rowenc.EncDatumRow(nil), r.row.prevDatums...)
+			}
 			r.row.prevDeleted = prevRF.RowIsDeleted()
 
 			// Assert that we don't get a second row from the row.Fetcher. We
@@ -178,10 +191,109 @@ func kvsToRows(
 			}
 		}
 
+		// Key the row by the columns of the key_column option, if any.
+		if withKeyColumns {
+			if err := overrideKeyColumns(rfCache, target, details.Opts, &r.row); err != nil {
+				return nil, err
+			}
+		}
+
+		// Apply the filter and the projection of the changefeed's query, if any.
+		if query != nil {
+			if keep, err := query.eval(ctx, &r.row); err != nil {
+				return nil, err
+			} else if !keep {
+				return output, nil
+			}
+		}
+		if !withDiff {
+			// The previous value was only decoded to evaluate the filter of the
+			// query, it must not be emitted.
+			r.row.prevDatums, r.row.prevTableDesc, r.row.prevProjection = nil, nil, nil
+			r.row.prevDeleted = false
+		}
+
 		output = append(output, r)
 		return output, nil
 	}
 
+	// The changes to the rows of tables watched on a column family other than
+	// the first one are emitted once the kvs of both families are known.
+	var familyChanges familyRowChanges
+	appendEmitEntryForFamilyChange := func(
+		ctx context.Context, output []emitEntry, c *familyRowChange,
+	) ([]emitEntry, error) {
+		kv, prevKV, ok := c.kvs()
+		if !ok {
+			if log.V(3) {
+				log.Infof(ctx, `skipping key which doesn't change column family %d of %s: %s`,
+					c.family.ID, c.desc.Name, c.rowKey)
+			}
+			return output, nil
+		}
+		return appendEmitEntryForRow(
+			ctx, output, c.desc, c.target, c.family, kv, prevKV,
+			c.schemaTimestamp, c.prevSchemaTimestamp, c.bufferGetTimestamp)
+	}
+
+	appendEmitEntryForKV := func(
+		ctx context.Context,
+		output []emitEntry,
+		kv roachpb.KeyValue,
+		prevVal roachpb.Value,
+		schemaTimestamp hlc.Timestamp,
+		prevSchemaTimestamp hlc.Timestamp,
+		backfillTimestamp hlc.Timestamp,
+		bufferGetTimestamp time.Time,
+	) ([]emitEntry, error) {
+
+		desc, err := rfCache.TableDescForKey(ctx, kv.Key, schemaTimestamp)
+		if err != nil {
+			return nil, err
+		}
+		target, ok := details.Targets[desc.ID]
+		if !ok {
+			// This kv is for an interleaved table that we're not watching.
+			if log.V(3) {
+				log.Infof(ctx, `skipping key from unwatched table %s: %s`, desc.Name, kv.Key)
+			}
+			return nil, nil
+		}
+		family, err := changefeedbase.TargetFamily(target, desc)
+		if err != nil {
+			return nil, err
+		}
+		prevKV := roachpb.KeyValue{Key: kv.Key, Value: prevVal}
+		if family != nil {
+			familyID, err := decodeFamilyID(kv.Key)
+			if err != nil {
+				return nil, err
+			}
+			// The kvs of the first column family tell whether the rows exist,
+			// so they are needed to watch any other family.
+			if familyID != family.ID && (familyID != 0 || family.ID == 0) {
+				// This kv is for a column family that we're not watching.
+				if log.V(3) {
+					log.Infof(ctx, `skipping key from unwatched column family %d of %s: %s`,
+						familyID, desc.Name, kv.Key)
+				}
+				return nil, nil
+			}
+			if family.ID != 0 {
+				c, err := familyChanges.add(
+					desc, target, family, familyID, kv, prevVal,
+					schemaTimestamp, prevSchemaTimestamp, backfillTimestamp, bufferGetTimestamp)
+				if err != nil || c == nil {
+					return output, err
+				}
+				return appendEmitEntryForFamilyChange(ctx, output, c)
+			}
+		}
+		return appendEmitEntryForRow(
+			ctx, output, desc, target, family, kv, prevKV,
+			schemaTimestamp, prevSchemaTimestamp, bufferGetTimestamp)
+	}
+
 	var output []emitEntry
 	return func(ctx context.Context) ([]emitEntry, error) {
 		// Reuse output to save allocations.
@@ -199,18 +311,26 @@ func kvsToRows(
 				}
 				schemaTimestamp := kv.Value.Timestamp
 				prevSchemaTimestamp := schemaTimestamp
-				if backfillTs := input.BackfillTimestamp(); !backfillTs.IsEmpty() {
+				backfillTs := input.BackfillTimestamp()
+				if !backfillTs.IsEmpty() {
 					schemaTimestamp = backfillTs
 					prevSchemaTimestamp = schemaTimestamp.Prev()
 				}
 				output, err = appendEmitEntryForKV(
 					ctx, output, kv, input.PrevValue(),
-					schemaTimestamp, prevSchemaTimestamp,
+					schemaTimestamp, prevSchemaTimestamp, backfillTs,
 					input.BufferGetTimestamp())
 				if err != nil {
 					return nil, err
 				}
 			case kvfeed.ResolvedEvent:
+				// All the kvs of the rows changed up to the resolved timestamp
+				// have been seen, so their changes must be emitted before it.
+				for _, c := range familyChanges.takeResolved(input.Resolved()) {
+					if output, err = appendEmitEntryForFamilyChange(ctx, output, c); err != nil {
+						return nil, err
+					}
+				}
 				output = append(output, emitEntry{
 					resolved:           input.Resolved(),
 					bufferGetTimestamp: input.BufferGetTimestamp(),
@@ -223,6 +343,228 @@ func kvsToRows(
 	}
 }
 
+// withPrevValues returns whether the previous values of changed kvs are needed
+// by a changefeed, either to emit them (WITH diff), to evaluate the filter of
+// its query on them, or to tell apart the changes to the rows of the tables it
+// watches on a column family, see familyRowChanges.
+func withPrevValues(details jobspb.ChangefeedDetails, query *cdcQuery) bool {
+	_, withDiff := details.Opts[changefeedbase.OptDiff]
+	if withDiff || query.hasFilter() {
+		return true
+	}
+	for _, target := range details.Targets {
+		if target.FamilyName != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// familyRowChanges holds the changes to the rows of the tables watched on a
+// column family other than the first one, until the kvs of both families of
+// the rows are known at the timestamps of the changes.
+//
+// Column families other than the first one have no kv when all their columns
+// are NULL, whereas the first family has one as long as the row exists. So a
+// row is only inserted or deleted when the kv of its first family is, and a
+// missing kv of the watched family decodes into NULL columns. Telling these
+// changes apart requires the kvs of both families along with their previous
+// values, which the kv feed provides (see withPrevValues) but not necessarily
+// next to each other. They have all been seen once the timestamp of the change
+// is resolved.
+type familyRowChanges struct {
+	byKey   map[familyRowKey]*familyRowChange
+	changes []*familyRowChange
+}
+
+type familyRowKey struct {
+	row string
+	ts  hlc.Timestamp
+}
+
+// familyRowChange is a change to a row of a table watched on a column family
+// other than the first one.
+type familyRowChange struct {
+	desc   *tabledesc.Immutable
+	target jobspb.ChangefeedTarget
+	family *descpb.ColumnFamilyDescriptor
+	rowKey roachpb.Key
+	// ts is the timestamp of the change, or of the backfill which emitted it.
+	ts                  hlc.Timestamp
+	backfill            bool
+	schemaTimestamp     hlc.Timestamp
+	prevSchemaTimestamp hlc.Timestamp
+	bufferGetTimestamp  time.Time
+
+	// first and watched are the kvs of the first and of the watched families
+	// of the row, if they changed.
+	first, watched *familyKVChange
+}
+
+// familyKVChange is a changed kv along with its previous value.
+type familyKVChange struct {
+	kv        roachpb.KeyValue
+	prevValue roachpb.Value
+}
+
+// add records the change to the given kv, which belongs either to the first
+// family or to the given watched family of a row of the table watched by the
+// given target. It returns the change if the row can be emitted right away,
+// without waiting for the kv of its other family.
+func (c *familyRowChanges) add(
+	desc *tabledesc.Immutable,
+	target jobspb.ChangefeedTarget,
+	family *descpb.ColumnFamilyDescriptor,
+	familyID descpb.FamilyID,
+	kv roachpb.KeyValue,
+	prevVal roachpb.Value,
+	schemaTimestamp, prevSchemaTimestamp, backfillTimestamp hlc.Timestamp,
+	bufferGetTimestamp time.Time,
+) (*familyRowChange, error) {
+	prefixLen, err := keys.GetRowPrefixLength(kv.Key)
+	if err != nil {
+		return nil, err
+	}
+	rc := &familyRowChange{
+		desc:                desc,
+		target:              target,
+		family:              family,
+		rowKey:              kv.Key[:prefixLen:prefixLen],
+		ts:                  kv.Value.Timestamp,
+		backfill:            !backfillTimestamp.IsEmpty(),
+		schemaTimestamp:     schemaTimestamp,
+		prevSchemaTimestamp: prevSchemaTimestamp,
+		bufferGetTimestamp:  bufferGetTimestamp,
+	}
+	kvc := &familyKVChange{kv: kv, prevValue: prevVal}
+	if rc.backfill {
+		rc.ts = backfillTimestamp
+	} else if kv.Value.IsPresent() && prevVal.IsPresent() {
+		if familyID == 0 {
+			// The row was neither inserted nor deleted, so only a change to the
+			// kv of its watched family affects the changefeed.
+			return nil, nil
+		}
+		// The row existed before and after the change.
+		rc.watched = kvc
+		return rc, nil
+	}
+
+	key := familyRowKey{row: string(rc.rowKey), ts: rc.ts}
+	if existing, ok := c.byKey[key]; ok {
+		rc = existing
+	} else {
+		if c.byKey == nil {
+			c.byKey = make(map[familyRowKey]*familyRowChange)
+		}
+		c.byKey[key] = rc
+		c.changes = append(c.changes, rc)
+	}
+	if familyID == 0 {
+		rc.first = kvc
+	} else {
+		rc.watched = kvc
+	}
+	return nil, nil
+}
+
+// takeResolved removes and returns the changes to the rows in the given span
+// at or before its resolved timestamp, in the order they were added.
+func (c *familyRowChanges) takeResolved(resolved *jobspb.ResolvedSpan) []*familyRowChange {
+	var taken []*familyRowChange
+	changes := c.changes[:0]
+	for _, rc := range c.changes {
+		if rc.ts.LessEq(resolved.Timestamp) && resolved.Span.ContainsKey(rc.rowKey) {
+			taken = append(taken, rc)
+			delete(c.byKey, familyRowKey{row: string(rc.rowKey), ts: rc.ts})
+			continue
+		}
+		changes = append(changes, rc)
+	}
+	for i := len(changes); i < len(c.changes); i++ {
+		c.changes[i] = nil
+	}
+	c.changes = changes
+	return taken
+}
+
+// kvs returns the kvs from which the new and previous values of the changed row
+// are decoded. ok is false if the change doesn't affect the watched family.
+//
+// A row exists unless the kv of its first family is deleted. The kvs of a
+// backfill hold the values of the rows at the time of the backfill, and their
+// previous values are the same when they are provided.
+func (rc *familyRowChange) kvs() (kv, prevKV roachpb.KeyValue, ok bool) {
+	existed, exists := true, true
+	if rc.first != nil {
+		existed, exists = rc.first.prevValue.IsPresent(), rc.first.kv.Value.IsPresent()
+		if !existed && !exists {
+			return kv, prevKV, false
+		}
+		if existed == exists && rc.watched == nil && !rc.backfill {
+			// Only the columns of the first family of the row changed.
+			return kv, prevKV, false
+		}
+	}
+	var value, prevValue roachpb.Value
+	if rc.watched != nil {
+		value, prevValue = rc.watched.kv.Value, rc.watched.prevValue
+	}
+	return rc.familyKV(value, exists), rc.familyKV(prevValue, existed), true
+}
+
+// familyKV returns the kv from which the row is decoded given the value of the
+// kv of its watched family, and whether it exists. It decodes into NULL columns
+// if the value is missing, and into a deleted row if the row doesn't exist.
+func (rc *familyRowChange) familyKV(value roachpb.Value, exists bool) roachpb.KeyValue {
+	if exists && value.IsPresent() {
+		return roachpb.KeyValue{Key: rc.watched.kv.Key, Value: value}
+	}
+	kv := roachpb.KeyValue{Key: keys.MakeFamilyKey(rc.rowKey, 0)}
+	if exists {
+		kv.Value.SetTuple(nil)
+	}
+	kv.Value.Timestamp = rc.ts
+	return kv
+}
+
+// overrideKeyColumns makes the given row of the table watched by the given
+// target keyed by the columns of the key_column option, by replacing the table
+// descriptors which describe it. Deleted rows only hold their primary key
+// columns, so their other key columns are copied from their previous value.
+func overrideKeyColumns(
+	rfCache *rowFetcherCache,
+	target jobspb.ChangefeedTarget,
+	opts map[string]string,
+	row *encodeRow,
+) error {
+	var err error
+	if row.tableDesc, err = rfCache.OverrideKeyColumns(row.tableDesc, target, opts); err != nil {
+		return err
+	}
+	if row.prevTableDesc == nil {
+		return nil
+	}
+	if row.prevTableDesc, err = rfCache.OverrideKeyColumns(row.prevTableDesc, target, opts); err != nil {
+		return err
+	}
+	if !row.deleted || row.prevDeleted {
+		return nil
+	}
+	colIdxByID := row.tableDesc.ColumnIdxMap()
+	prevColIdxByID := row.prevTableDesc.ColumnIdxMap()
+	for _, colID := range row.tableDesc.GetPrimaryIndex().ColumnIDs {
+		colIdx, ok := colIdxByID.Get(colID)
+		if !ok {
+			return errors.Errorf(`unknown column id: %d`, colID)
+		}
+		if prevColIdx, ok := prevColIdxByID.Get(colID); ok {
+			row.datums[colIdx] = row.prevDatums[prevColIdx]
+		}
+	}
+	return nil
+}
+
 // emitEntries connects to a sink, receives rows from a closure, and repeatedly
 // emits them to the sink. It returns a closure that may be repeatedly called to
 // advance the changefeed and which returns span-level resolved timestamp
@@ -428,6 +770,7 @@ func makeSpansToProtect(codec keys.SQLCodec, targets jobspb.ChangefeedTargets) [
 func initialScanFromOptions(opts map[string]string) bool {
 	_, cursor := opts[changefeedbase.OptCursor]
 	_, initialScan := opts[changefeedbase.OptInitialScan]
+	_, initialScanOnly := opts[changefeedbase.OptInitialScanOnly]
 	_, noInitialScan := opts[changefeedbase.OptNoInitialScan]
-	return (cursor && initialScan) || (!cursor && !noInitialScan)
+	return (cursor && (initialScan || initialScanOnly)) || (!cursor && !noInitialScan)
 }
@@ -69,7 +69,7 @@ func distChangefeedFlow(
 	resultsCh chan<- tree.Datums,
 ) error {
 	var err error
-	details, err = validateDetails(details)
+	details, err = validateDetails(ctx, execCtx.ExecCfg().Settings, details)
 	if err != nil {
 		return err
 	}
@@ -11,7 +11,6 @@ package changefeedccl
 import (
 	"context"
 	"fmt"
-	"math"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
@@ -58,6 +57,8 @@ type changeAggregator struct {
 
 	// encoder is the Encoder to use for key and value serialization.
 	encoder Encoder
+	// query, if non-nil, filters and projects the rows of the changefeed.
+	query *cdcQuery
 	// sink is the Sink to write rows to. Resolved timestamps are never written
 	// by changeAggregator.
 	sink Sink
@@ -142,6 +143,15 @@ func newChangeAggregatorProcessor(
 	if ca.encoder, err = getEncoder(ca.spec.Feed.Opts); err != nil {
 		return nil, err
 	}
+	if ca.spec.Feed.Select != `` {
+		sel, err := parseCDCQuery(ca.spec.Feed.Select)
+		if err != nil {
+			return nil, err
+		}
+		if ca.query, err = makeCDCQuery(sel, flowCtx.NewEvalCtx()); err != nil {
+			return nil, err
+		}
+	}
 
 	return ca, nil
 }
@@ -193,22 +203,25 @@ func (ca *changeAggregator) Start(ctx context.Context) context.Context {
 	// It seems like we should also be able to use `ca.ProcessorBase.MemMonitor`
 	// for the poller, but there is a race between the flow's MemoryMonitor
 	// getting Stopped and `changeAggregator.Close`, which causes panics. Not sure
-	// what to do about this yet.
-	kvFeedMemMonCapacity := kvfeed.MemBufferDefaultCapacity
+	// what to do about this yet. Instead, the monitor is a child of the
+	// server's changefeed monitor, which bounds the memory used by all of the
+	// changefeeds on this node.
+	kvFeedMemMonLimit := changefeedbase.PerChangefeedMemLimit.Get(&ca.flowCtx.Cfg.Settings.SV)
 	if knobs.MemBufferCapacity != 0 {
-		kvFeedMemMonCapacity = knobs.MemBufferCapacity
+		kvFeedMemMonLimit = knobs.MemBufferCapacity
 	}
-	kvFeedMemMon := mon.NewMonitorInheritWithLimit("kvFeed", math.MaxInt64, ca.ProcessorBase.MemMonitor)
-	kvFeedMemMon.Start(ctx, nil /* pool */, mon.MakeStandaloneBudget(kvFeedMemMonCapacity))
+	pool := ca.flowCtx.Cfg.ChangefeedMonitor
+	kvFeedMemMon := mon.NewMonitorInheritWithLimit("kvFeed", kvFeedMemMonLimit, pool)
+	kvFeedMemMon.SetMetrics(metrics.MemCurBytes, nil /* maxHist */)
+	kvFeedMemMon.Start(ctx, pool, mon.BoundAccount{})
 	ca.kvFeedMemMon = kvFeedMemMon
 
 	buf := kvfeed.MakeChanBuffer()
 	leaseMgr := ca.flowCtx.Cfg.LeaseManager.(*lease.Manager)
-	_, withDiff := ca.spec.Feed.Opts[changefeedbase.OptDiff]
 	kvfeedCfg := makeKVFeedCfg(ca.flowCtx.Cfg, leaseMgr, ca.kvFeedMemMon, ca.spec,
-		spans, withDiff, buf, metrics)
+		spans, withPrevValues(ca.spec.Feed, ca.query), buf, metrics)
 	cfg := ca.flowCtx.Cfg
-	rowsFn := kvsToRows(ctx, cfg.Codec, cfg.Settings, cfg.DB, leaseMgr, cfg.HydratedTables, ca.spec.Feed, buf.Get)
+	rowsFn := kvsToRows(ctx, cfg.Codec, cfg.Settings, cfg.DB, leaseMgr, cfg.HydratedTables, ca.spec.Feed, ca.query, buf.Get)
 	ca.tickFn = emitEntries(ca.flowCtx.Cfg.Settings, ca.spec.Feed,
 		kvfeedCfg.InitialHighWater, sf, ca.encoder, ca.sink, rowsFn, knobs, metrics)
 	ca.startKVFeed(ctx, kvfeedCfg)
@@ -252,6 +265,7 @@ func makeKVFeedCfg(
 	schemaChangePolicy := changefeedbase.SchemaChangePolicy(
 		spec.Feed.Opts[changefeedbase.OptSchemaChangePolicy])
 	initialHighWater, needsInitialScan := getKVFeedInitialParameters(spec)
+	_, initialScanOnly := spec.Feed.Opts[changefeedbase.OptInitialScanOnly]
 	kvfeedCfg := kvfeed.Config{
 		Sink:               buf,
 		Settings:           cfg.Settings,
@@ -265,8 +279,10 @@ func makeKVFeedCfg(
 		Metrics:            &metrics.KVFeedMetrics,
 		MM:                 mm,
 		InitialHighWater:   initialHighWater,
+		EndTime:            spec.Feed.EndTime,
 		WithDiff:           withDiff,
 		NeedsInitialScan:   needsInitialScan,
+		InitialScanOnly:    initialScanOnly,
 		SchemaChangeEvents: schemaChangeEvents,
 		SchemaChangePolicy: schemaChangePolicy,
 	}
@@ -646,10 +662,30 @@ func (cf *changeFrontier) shouldFailOnSchemaChange() bool {
 // shouldFailOnSchemaChange checks the job's spec to determine whether it should
 // install protected timestamps when encountering scan boundaries.
 func (cf *changeFrontier) shouldProtectBoundaries() bool {
+	if cf.isInitialScanOnly() || cf.endTimeReached() {
+		return false
+	}
 	policy := changefeedbase.SchemaChangePolicy(cf.spec.Feed.Opts[changefeedbase.OptSchemaChangePolicy])
 	return policy == changefeedbase.OptSchemaChangePolicyBackfill
 }
 
+// isInitialScanOnly checks the job's spec to determine whether the changefeed
+// finishes once its initial scan is done. The end of the initial scan is
+// marked by a scan boundary.
+func (cf *changeFrontier) isInitialScanOnly() bool {
+	_, initialScanOnly := cf.spec.Feed.Opts[changefeedbase.OptInitialScanOnly]
+	return initialScanOnly
+}
+
+// endTimeReached returns true if the changefeed has an end time and the
+// spanFrontier is at the boundary emitted for it, i.e. everything before the
+// end time has been emitted.
+func (cf *changeFrontier) endTimeReached() bool {
+	endTime := cf.spec.Feed.EndTime
+	return !endTime.IsEmpty() && cf.schemaChangeBoundaryReached() &&
+		endTime.Prev().LessEq(cf.schemaChangeBoundary)
+}
+
 // Next is part of the RowSource interface.
 func (cf *changeFrontier) Next() (rowenc.EncDatumRow, *execinfrapb.ProducerMetadata) {
 	for cf.State == execinfra.StateRunning {
@@ -659,6 +695,18 @@ func (cf *changeFrontier) Next() (rowenc.EncDatumRow, *execinfrapb.ProducerMetad
 			return cf.ProcessRowHelper(cf.resolvedBuf.Pop()), nil
 		}
 
+		if cf.schemaChangeBoundaryReached() && cf.isInitialScanOnly() {
+			// Everything has been emitted and the high-water checkpointed, so
+			// the changefeed is done.
+			log.Infof(cf.Ctx, "initial scan completed at %v", cf.schemaChangeBoundary)
+			cf.MoveToDraining(nil /* err */)
+			break
+		}
+		if cf.endTimeReached() {
+			log.Infof(cf.Ctx, "changefeed reached its end time %v", cf.spec.Feed.EndTime)
+			cf.MoveToDraining(nil /* err */)
+			break
+		}
 		if cf.schemaChangeBoundaryReached() && cf.shouldFailOnSchemaChange() {
 			// TODO(ajwerner): make this more useful by at least informing the client
 			// of which tables changed.
@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/docs"
 	"github.com/cockroachdb/cockroach/pkg/featureflag"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
@@ -167,27 +168,39 @@ func changefeedPlanHook(
 			}
 			statementTime = initialHighWater
 		}
-
-		// For now, disallow targeting a database or wildcard table selection.
-		// Getting it right as tables enter and leave the set over time is
-		// tricky.
-		if len(changefeedStmt.Targets.Databases) > 0 {
-			return errors.Errorf(`CHANGEFEED cannot target %s`,
-				tree.AsString(&changefeedStmt.Targets))
+		var endTime hlc.Timestamp
+		if e, ok := opts[changefeedbase.OptEndTime]; ok {
+			// Unlike the cursor, the end time is allowed to be in the future.
+			asOf := tree.AsOfClause{Expr: tree.NewStrVal(e)}
+			var err error
+			if endTime, err = tree.EvalAsOfTimestamp(
+				ctx, asOf, p.SemaCtx(), &p.ExtendedEvalContext().EvalContext,
+			); err != nil {
+				return err
+			}
+			if endTime.LessEq(statementTime) {
+				return errors.Errorf(`%s %s must be after the changefeed's start time %s`,
+					changefeedbase.OptEndTime, endTime.AsOfSystemTime(), statementTime.AsOfSystemTime())
+			}
 		}
-		for _, t := range changefeedStmt.Targets.Tables {
-			p, err := t.NormalizeTablePattern()
+
+		// For now, disallow targeting a wildcard table selection. Getting it
+		// right as tables enter and leave the set over time is tricky.
+		var targetList tree.TargetList
+		for _, t := range changefeedStmt.Targets {
+			p, err := t.TableName.NormalizeTablePattern()
 			if err != nil {
 				return err
 			}
 			if _, ok := p.(*tree.TableName); !ok {
-				return errors.Errorf(`CHANGEFEED cannot target %s`, tree.AsString(t))
+				return errors.Errorf(`CHANGEFEED cannot target %s`, tree.AsString(t.TableName))
 			}
+			targetList.Tables = append(targetList.Tables, t.TableName)
 		}
 
 		// This grabs table descriptors once to get their ids.
 		targetDescs, _, err := backupccl.ResolveTargetsToDescriptors(
-			ctx, p, statementTime, &changefeedStmt.Targets)
+			ctx, p, statementTime, &targetList)
 		if err != nil {
 			return errors.Wrap(err, "failed to resolve targets in the CHANGEFEED stmt")
 		}
@@ -196,15 +209,25 @@ func changefeedPlanHook(
 				return err
 			}
 		}
+		// The column families are resolved separately, one target at a time,
+		// since the descriptors above aren't in the order of the targets.
+		families, err := resolveChangefeedTargetFamilies(ctx, p, statementTime, changefeedStmt.Targets)
+		if err != nil {
+			return err
+		}
 		targets := make(jobspb.ChangefeedTargets, len(targetDescs))
 		for _, desc := range targetDescs {
 			if table, isTable := desc.(catalog.TableDescriptor); isTable {
 				targets[table.GetID()] = jobspb.ChangefeedTarget{
 					StatementTimeName: table.GetName(),
+					FamilyName:        families[table.GetID()],
 				}
 				if err := validateChangefeedTable(targets, table); err != nil {
 					return err
 				}
+				if _, err := changefeedbase.KeyColumnIDs(opts, targets[table.GetID()], table); err != nil {
+					return err
+				}
 			}
 		}
 
@@ -213,6 +236,18 @@ func changefeedPlanHook(
 			Opts:          opts,
 			SinkURI:       sinkURI,
 			StatementTime: statementTime,
+			EndTime:       endTime,
+		}
+		if changefeedStmt.Select != nil {
+			// The grammar guarantees that there is a single target table.
+			for _, desc := range targetDescs {
+				if table, isTable := desc.(catalog.TableDescriptor); isTable {
+					if err := validateCDCQuery(ctx, &p.ExtendedEvalContext().EvalContext, changefeedStmt.Select, table); err != nil {
+						return err
+					}
+				}
+			}
+			details.Select = tree.AsStringWithFlags(changefeedStmt.Select, tree.FmtParsable)
 		}
 		progress := jobspb.Progress{
 			Progress: &jobspb.Progress_HighWater{},
@@ -229,8 +264,10 @@ func changefeedPlanHook(
 		//   and `format` if the user didn't specify them.
 		// - Then `getEncoder` is run to return any configuration errors.
 		// - Then the changefeed is opted in to `OptKeyInValue` for any cloud
-		//   storage sink. Kafka etc have a key and value field in each message but
-		//   cloud storage sinks don't have anywhere to put the key. So if the key
+		//   storage or webhook sink. Kafka etc have a key and value field in each
+		//   message but cloud storage sinks don't have anywhere to put the key
+		//   (webhook sinks additionally get `OptTopicInValue`, since a single
+		//   endpoint receives the rows of every table). So if the key
 		//   is not in the value, then for DELETEs there is no way to recover which
 		//   key was deleted. We could make the user explicitly pass this option for
 		//   every cloud storage sink and error if they don't, but that seems
@@ -250,9 +287,15 @@ func changefeedPlanHook(
 		if err != nil {
 			return err
 		}
-		if details, err = validateDetails(details); err != nil {
+		if details, err = validateDetails(ctx, p.ExecCfg().Settings, details); err != nil {
 			return err
 		}
+		if details.Select != `` {
+			if format := details.Opts[changefeedbase.OptFormat]; format != string(changefeedbase.OptFormatJSON) {
+				return errors.Errorf(`CREATE CHANGEFEED ... AS SELECT is incompatible with %s=%s`,
+					changefeedbase.OptFormat, format)
+			}
+		}
 
 		if _, err := getEncoder(details.Opts); err != nil {
 			return err
@@ -260,6 +303,19 @@ func changefeedPlanHook(
 		if isCloudStorageSink(parsedSink) {
 			details.Opts[changefeedbase.OptKeyInValue] = ``
 		}
+		if isWebhookSink(parsedSink) {
+			// Like cloud storage sinks, webhook sinks have nowhere to put the key
+			// and the topic of a row other than its value.
+			details.Opts[changefeedbase.OptKeyInValue] = ``
+			details.Opts[changefeedbase.OptTopicInValue] = ``
+		} else {
+			for _, opt := range changefeedbase.WebhookSinkOptions {
+				if _, ok := details.Opts[opt]; ok {
+					return errors.Errorf(`%s is only usable with a %s sink`,
+						opt, changefeedbase.SinkSchemeWebhookHTTPS)
+				}
+			}
+		}
 
 		// Feature telemetry
 		telemetrySink := parsedSink.Scheme
@@ -418,20 +474,35 @@ func changefeedJobDescription(
 	c := &tree.CreateChangefeed{
 		Targets: changefeed.Targets,
 		SinkURI: tree.NewDString(cleanedSinkURI),
+		Select:  changefeed.Select,
 	}
 	for k, v := range opts {
 		opt := tree.KVOption{Key: tree.Name(k)}
+		switch k {
+		case changefeedbase.OptWebhookAuthHeader:
+			v = `redacted`
+		case changefeedbase.OptConfluentSchemaRegistry:
+			if v, err = redactSchemaRegistryURL(v); err != nil {
+				return "", err
+			}
+		}
 		if len(v) > 0 {
 			opt.Value = tree.NewDString(v)
 		}
 		c.Options = append(c.Options, opt)
 	}
 	sort.Slice(c.Options, func(i, j int) bool { return c.Options[i].Key < c.Options[j].Key })
+	if c.Select != nil {
+		// The table name of the query is printed as written, like the targets.
+		return tree.AsString(c), nil
+	}
 	ann := p.ExtendedEvalContext().Annotations
 	return tree.AsStringWithFQNames(c, ann), nil
 }
 
-func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails, error) {
+func validateDetails(
+	ctx context.Context, st *cluster.Settings, details jobspb.ChangefeedDetails,
+) (jobspb.ChangefeedDetails, error) {
 	if details.Opts == nil {
 		// The proto MarshalTo method omits the Opts field if the map is empty.
 		// So, if no options were specified by the user, Opts will be nil when
@@ -478,11 +549,22 @@ func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails
 	{
 		_, withInitialScan := details.Opts[changefeedbase.OptInitialScan]
 		_, noInitialScan := details.Opts[changefeedbase.OptNoInitialScan]
+		_, initialScanOnly := details.Opts[changefeedbase.OptInitialScanOnly]
 		if withInitialScan && noInitialScan {
 			return jobspb.ChangefeedDetails{}, errors.Errorf(
 				`cannot specify both %s and %s`, changefeedbase.OptInitialScan,
 				changefeedbase.OptNoInitialScan)
 		}
+		if initialScanOnly && noInitialScan {
+			return jobspb.ChangefeedDetails{}, errors.Errorf(
+				`cannot specify both %s and %s`, changefeedbase.OptInitialScanOnly,
+				changefeedbase.OptNoInitialScan)
+		}
+		if _, endTime := details.Opts[changefeedbase.OptEndTime]; initialScanOnly && endTime {
+			return jobspb.ChangefeedDetails{}, errors.Errorf(
+				`cannot specify both %s and %s`, changefeedbase.OptInitialScanOnly,
+				changefeedbase.OptEndTime)
+		}
 	}
 	{
 		const opt = changefeedbase.OptEnvelope
@@ -503,16 +585,76 @@ func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails
 		switch v := changefeedbase.FormatType(details.Opts[opt]); v {
 		case ``, changefeedbase.OptFormatJSON:
 			details.Opts[opt] = string(changefeedbase.OptFormatJSON)
-		case changefeedbase.OptFormatAvro:
-			// No-op.
+		case changefeedbase.OptFormatAvro, changefeedbase.OptFormatDeprecatedAvro:
+			// Nodes running older versions only know the format as
+			// experimental_avro, so it's only persisted as avro once they have
+			// all been upgraded.
+			if st.Version.IsActive(ctx, clusterversion.ChangefeedAvroFormat) {
+				details.Opts[opt] = string(changefeedbase.OptFormatAvro)
+			} else {
+				details.Opts[opt] = string(changefeedbase.OptFormatDeprecatedAvro)
+			}
+		case changefeedbase.OptFormatCSV, changefeedbase.OptFormatProtobuf:
+			// Nodes running older versions don't know these formats.
+			if !st.Version.IsActive(ctx, clusterversion.ChangefeedCSVAndProtobufFormats) {
+				return jobspb.ChangefeedDetails{}, pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+					`%s=%s requires all nodes to be upgraded to %s`, opt, v,
+					clusterversion.ByKey(clusterversion.ChangefeedCSVAndProtobufFormats))
+			}
 		default:
 			return jobspb.ChangefeedDetails{}, errors.Errorf(
 				`unknown %s: %s`, opt, v)
 		}
 	}
+	// Nodes running older versions ignore the end time of a changefeed, and
+	// would run it forever.
+	if !details.EndTime.IsEmpty() && !st.Version.IsActive(ctx, clusterversion.ChangefeedEndTime) {
+		return jobspb.ChangefeedDetails{}, pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			`%s requires all nodes to be upgraded to %s`, changefeedbase.OptEndTime,
+			clusterversion.ByKey(clusterversion.ChangefeedEndTime))
+	}
+	// Nodes running older versions ignore the query of a changefeed, and would
+	// emit the rows of its table unfiltered.
+	if details.Select != `` && !st.Version.IsActive(ctx, clusterversion.ChangefeedQueries) {
+		return jobspb.ChangefeedDetails{}, pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			`CREATE CHANGEFEED ... AS SELECT requires all nodes to be upgraded to %s`,
+			clusterversion.ByKey(clusterversion.ChangefeedQueries))
+	}
 	return details, nil
 }
 
+// resolveChangefeedTargetFamilies returns the name of the column family
+// watched by each target of a changefeed by the ID of its table, or the empty
+// string for the tables which are watched in their entirety.
+func resolveChangefeedTargetFamilies(
+	ctx context.Context,
+	p sql.PlanHookState,
+	statementTime hlc.Timestamp,
+	targets tree.ChangefeedTargets,
+) (map[descpb.ID]string, error) {
+	families := make(map[descpb.ID]string, len(targets))
+	for _, t := range targets {
+		descs, _, err := backupccl.ResolveTargetsToDescriptors(
+			ctx, p, statementTime, &tree.TargetList{Tables: tree.TablePatterns{t.TableName}})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to resolve targets in the CHANGEFEED stmt")
+		}
+		for _, desc := range descs {
+			table, isTable := desc.(catalog.TableDescriptor)
+			if !isTable {
+				continue
+			}
+			if family, ok := families[table.GetID()]; ok && family != string(t.FamilyName) {
+				return nil, errors.Errorf(
+					`CHANGEFEED cannot target %s more than once with different column families`,
+					table.GetName())
+			}
+			families[table.GetID()] = string(t.FamilyName)
+		}
+	}
+	return families, nil
+}
+
 func validateChangefeedTable(
 	targets jobspb.ChangefeedTargets, tableDesc catalog.TableDescriptor,
 ) error {
@@ -538,10 +680,15 @@ func validateChangefeedTable(
 	if tableDesc.IsSequence() {
 		return errors.Errorf(`CHANGEFEED cannot target sequences: %s`, tableDesc.GetName())
 	}
-	if families := tableDesc.GetFamilies(); len(families) != 1 {
-		return errors.Errorf(
+	if t.FamilyName != `` {
+		if _, err := changefeedbase.TargetFamily(t, tableDesc); err != nil {
+			return err
+		}
+	} else if families := tableDesc.GetFamilies(); len(families) != 1 {
+		return errors.WithHint(errors.Errorf(
 			`CHANGEFEEDs are currently supported on tables with exactly 1 column family: %s has %d`,
-			tableDesc.GetName(), len(families))
+			tableDesc.GetName(), len(families)),
+			`use CHANGEFEED FOR TABLE ... FAMILY to watch one of its column families`)
 	}
 
 	if tableDesc.GetState() == descpb.DescriptorState_DROP {
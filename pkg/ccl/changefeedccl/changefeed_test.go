@@ -28,6 +28,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/cdctest"
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
@@ -40,6 +41,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/server"
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsql"
@@ -155,6 +157,87 @@ func TestChangefeedDiff(t *testing.T) {
 	t.Run(`cloudstorage`, cloudStorageTest(testFn))
 }
 
+func TestChangefeedQuery(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING, c INT)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (0, 'zero', 0), (1, 'one', 1)`)
+
+		foo := feed(t, f, `CREATE CHANGEFEED WITH diff AS SELECT a, upper(b) AS b, c * 10 FROM foo WHERE c > 0`)
+		defer closeFeed(t, foo)
+
+		assertPayloads(t, foo, []string{
+			`foo: [1]->{"after": {"?column?": 10, "a": 1, "b": "ONE"}, "before": null}`,
+		})
+
+		// Rows that do not pass the filter are skipped.
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (2, 'two', 2), (3, 'three', -3)`)
+		sqlDB.Exec(t, `UPDATE foo SET c = 4 WHERE a = 3`)
+		assertPayloads(t, foo, []string{
+			`foo: [2]->{"after": {"?column?": 20, "a": 2, "b": "TWO"}, "before": null}`,
+			`foo: [3]->{"after": {"?column?": 40, "a": 3, "b": "THREE"}, "before": {"?column?": -30, "a": 3, "b": "THREE"}}`,
+		})
+
+		// Rows updated so that they no longer pass the filter are emitted as
+		// deletions, but not rows that did not pass it either before.
+		sqlDB.Exec(t, `UPDATE foo SET c = -1 WHERE a = 1`)
+		sqlDB.Exec(t, `UPDATE foo SET c = -2 WHERE a = 0`)
+		assertPayloads(t, foo, []string{
+			`foo: [1]->{"after": null, "before": {"?column?": 10, "a": 1, "b": "ONE"}}`,
+		})
+
+		// Deletions are always emitted.
+		sqlDB.Exec(t, `DELETE FROM foo WHERE a IN (0, 2)`)
+		assertPayloads(t, foo, []string{
+			`foo: [0]->{"after": null, "before": {"?column?": -20, "a": 0, "b": "ZERO"}}`,
+			`foo: [2]->{"after": null, "before": {"?column?": 20, "a": 2, "b": "TWO"}}`,
+		})
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+	t.Run(`cloudstorage`, cloudStorageTest(testFn))
+}
+
+func TestChangefeedQueryStar(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (0, 'zero'), (1, 'one')`)
+
+		foo := feed(t, f, `CREATE CHANGEFEED AS SELECT *, a + 1 AS next FROM foo WHERE b LIKE 'o%'`)
+		defer closeFeed(t, foo)
+
+		assertPayloads(t, foo, []string{
+			`foo: [1]->{"after": {"a": 1, "b": "one", "next": 2}}`,
+		})
+
+		// The star is expanded with the columns of each version of the table.
+		sqlDB.Exec(t, `ALTER TABLE foo ADD COLUMN c INT DEFAULT 7`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (2, 'other', 8)`)
+		assertPayloads(t, foo, []string{
+			`foo: [1]->{"after": {"a": 1, "b": "one", "c": 7, "next": 2}}`,
+			`foo: [2]->{"after": {"a": 2, "b": "other", "c": 8, "next": 3}}`,
+		})
+
+		// Rows leaving the filter are emitted as deletions without the diff
+		// option too.
+		sqlDB.Exec(t, `UPDATE foo SET b = 'none' WHERE a = 1`)
+		assertPayloads(t, foo, []string{
+			`foo: [1]->{"after": null}`,
+		})
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+}
+
 func TestChangefeedEnvelope(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -420,6 +503,67 @@ func TestChangefeedInitialScan(t *testing.T) {
 	t.Run(`enterprise`, enterpriseTest(testFn))
 }
 
+func TestChangefeedInitialScanOnly(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1, 'a'), (2, 'b')`)
+
+		sqlDB.ExpectErr(t, `cannot specify both initial_scan_only and no_initial_scan`,
+			`EXPERIMENTAL CHANGEFEED FOR foo WITH initial_scan_only, no_initial_scan`)
+
+		foo := feed(t, f, `CREATE CHANGEFEED FOR foo WITH initial_scan_only`)
+		defer closeFeed(t, foo)
+		assertPayloads(t, foo, []string{
+			`foo: [1]->{"after": {"a": 1, "b": "a"}}`,
+			`foo: [2]->{"after": {"a": 2, "b": "b"}}`,
+		})
+
+		// Once the scan is done, the changefeed finishes on its own.
+		expectFinished(t, sqlDB, foo)
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+}
+
+func TestChangefeedEndTime(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+
+		var tsStart, tsEnd string
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1, 'before')`)
+		sqlDB.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&tsStart)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (2, 'during')`)
+		sqlDB.Exec(t, `UPDATE foo SET b = 'during' WHERE a = 1`)
+		sqlDB.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&tsEnd)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (3, 'after')`)
+
+		sqlDB.ExpectErr(t, `end_time .* must be after the changefeed's start time`,
+			`EXPERIMENTAL CHANGEFEED FOR foo WITH cursor=$1, end_time=$2`, tsEnd, tsStart)
+		sqlDB.ExpectErr(t, `cannot specify both initial_scan_only and end_time`,
+			`EXPERIMENTAL CHANGEFEED FOR foo WITH initial_scan_only, cursor=$1, end_time=$2`, tsStart, tsEnd)
+
+		foo := feed(t, f, `CREATE CHANGEFEED FOR foo WITH cursor=$1, end_time=$2`, tsStart, tsEnd)
+		defer closeFeed(t, foo)
+		assertPayloads(t, foo, []string{
+			`foo: [1]->{"after": {"a": 1, "b": "during"}}`,
+			`foo: [2]->{"after": {"a": 2, "b": "during"}}`,
+		})
+		expectFinished(t, sqlDB, foo)
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+}
+
 func TestChangefeedUserDefinedTypes(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
@@ -1080,6 +1224,157 @@ func TestChangefeedColumnFamily(t *testing.T) {
 	t.Run(`enterprise`, enterpriseTest(testFn))
 }
 
+func TestChangefeedColumnFamilyTarget(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (
+			a INT PRIMARY KEY, b STRING, c STRING, FAMILY f_ab (a, b), FAMILY f_c (c)
+		)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (0, 'b0', 'c0'), (-1, 'b', NULL)`)
+
+		// A column family without any value holds NULL columns.
+		fooC := feed(t, f, `CREATE CHANGEFEED FOR foo FAMILY f_c WITH diff`)
+		defer closeFeed(t, fooC)
+		assertPayloads(t, fooC, []string{
+			`foo: [-1]->{"after": {"a": -1, "c": null}, "before": null}`,
+			`foo: [0]->{"after": {"a": 0, "c": "c0"}, "before": null}`,
+		})
+
+		// Changes to the other column families are not emitted.
+		sqlDB.Exec(t, `UPDATE foo SET b = 'b1' WHERE a = 0`)
+		sqlDB.Exec(t, `UPDATE foo SET c = 'c1' WHERE a = 0`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1, 'b1', NULL)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (2, 'b2', 'c2')`)
+		assertPayloads(t, fooC, []string{
+			`foo: [0]->{"after": {"a": 0, "c": "c1"}, "before": {"a": 0, "c": "c0"}}`,
+			`foo: [1]->{"after": {"a": 1, "c": null}, "before": null}`,
+			`foo: [2]->{"after": {"a": 2, "c": "c2"}, "before": null}`,
+		})
+
+		// Setting all the columns of the family to NULL doesn't delete the row.
+		sqlDB.Exec(t, `UPDATE foo SET c = NULL WHERE a = 2`)
+		sqlDB.Exec(t, `UPDATE foo SET c = 'c1' WHERE a = 1`)
+		assertPayloads(t, fooC, []string{
+			`foo: [1]->{"after": {"a": 1, "c": "c1"}, "before": {"a": 1, "c": null}}`,
+			`foo: [2]->{"after": {"a": 2, "c": null}, "before": {"a": 2, "c": "c2"}}`,
+		})
+
+		// Deleting the row does, whether the family has values or not.
+		sqlDB.Exec(t, `DELETE FROM foo WHERE a IN (-1, 0)`)
+		assertPayloads(t, fooC, []string{
+			`foo: [-1]->{"after": null, "before": {"a": -1, "c": null}}`,
+			`foo: [0]->{"after": null, "before": {"a": 0, "c": "c1"}}`,
+		})
+
+		// Adding a column family to the table doesn't affect the changefeed.
+		fooAB := feed(t, f, `CREATE CHANGEFEED FOR TABLE foo FAMILY f_ab`)
+		defer closeFeed(t, fooAB)
+		assertPayloads(t, fooAB, []string{
+			`foo: [1]->{"after": {"a": 1, "b": "b1"}}`,
+			`foo: [2]->{"after": {"a": 2, "b": "b2"}}`,
+		})
+		sqlDB.Exec(t, `ALTER TABLE foo ADD COLUMN d STRING CREATE FAMILY f_d`)
+		sqlDB.Exec(t, `UPDATE foo SET d = 'd2' WHERE a = 2`)
+		sqlDB.Exec(t, `UPDATE foo SET b = 'b3' WHERE a = 2`)
+		assertPayloads(t, fooAB, []string{
+			`foo: [2]->{"after": {"a": 2, "b": "b3"}}`,
+		})
+
+		// Without the diff option, insertions, deletions and families set to
+		// NULL are still told apart.
+		fooCNoDiff := feed(t, f, `CREATE CHANGEFEED FOR foo FAMILY f_c`)
+		defer closeFeed(t, fooCNoDiff)
+		assertPayloads(t, fooCNoDiff, []string{
+			`foo: [1]->{"after": {"a": 1, "c": "c1"}}`,
+			`foo: [2]->{"after": {"a": 2, "c": null}}`,
+		})
+		sqlDB.Exec(t, `UPDATE foo SET b = 'b4' WHERE a = 1`)
+		sqlDB.Exec(t, `UPDATE foo SET c = NULL WHERE a = 1`)
+		sqlDB.Exec(t, `DELETE FROM foo WHERE a = 2`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (3, 'b3', NULL)`)
+		sqlDB.Exec(t, `BEGIN;
+			INSERT INTO foo VALUES (4, 'b4', NULL);
+			UPDATE foo SET c = 'c4' WHERE a = 4;
+			COMMIT`)
+		assertPayloads(t, fooCNoDiff, []string{
+			`foo: [1]->{"after": {"a": 1, "c": null}}`,
+			`foo: [2]->{"after": null}`,
+			`foo: [3]->{"after": {"a": 3, "c": null}}`,
+			`foo: [4]->{"after": {"a": 4, "c": "c4"}}`,
+		})
+
+		sqlDB.ExpectErr(t, `column family f_nope does not exist in table foo`,
+			`EXPERIMENTAL CHANGEFEED FOR foo FAMILY f_nope`)
+		sqlDB.ExpectErr(t, `CHANGEFEED cannot target foo more than once with different column families`,
+			`EXPERIMENTAL CHANGEFEED FOR foo FAMILY f_ab, foo FAMILY f_c`)
+		sqlDB.ExpectErr(t, `CHANGEFEED cannot target foo more than once with different column families`,
+			`EXPERIMENTAL CHANGEFEED FOR foo FAMILY f_ab, foo`)
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+}
+
+func TestChangefeedKeyColumn(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, tenant STRING, b STRING)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (0, 't0', 'initial')`)
+
+		// Columns outside of the primary key need the previous values of the
+		// rows to key deleted rows.
+		foo := feed(t, f, `CREATE CHANGEFEED FOR foo WITH key_column='tenant', diff`)
+		defer closeFeed(t, foo)
+		assertPayloads(t, foo, []string{
+			`foo: ["t0"]->{"after": {"a": 0, "b": "initial", "tenant": "t0"}, "before": null}`,
+		})
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1, 't1', 'b1'), (2, 't1', 'b2')`)
+		sqlDB.Exec(t, `UPDATE foo SET b = 'updated' WHERE a = 0`)
+		assertPayloads(t, foo, []string{
+			`foo: ["t0"]->{"after": {"a": 0, "b": "updated", "tenant": "t0"}, "before": {"a": 0, "b": "initial", "tenant": "t0"}}`,
+			`foo: ["t1"]->{"after": {"a": 1, "b": "b1", "tenant": "t1"}, "before": null}`,
+			`foo: ["t1"]->{"after": {"a": 2, "b": "b2", "tenant": "t1"}, "before": null}`,
+		})
+		sqlDB.Exec(t, `DELETE FROM foo WHERE a = 1`)
+		assertPayloads(t, foo, []string{
+			`foo: ["t1"]->{"after": null, "before": {"a": 1, "b": "b1", "tenant": "t1"}}`,
+		})
+
+		// Keys are made of the columns in the given order, and the primary key
+		// columns don't need the diff option.
+		fooTenantA := feed(t, f, `CREATE CHANGEFEED FOR foo WITH key_column='tenant, a', diff`)
+		defer closeFeed(t, fooTenantA)
+		assertPayloads(t, fooTenantA, []string{
+			`foo: ["t0", 0]->{"after": {"a": 0, "b": "updated", "tenant": "t0"}, "before": null}`,
+			`foo: ["t1", 2]->{"after": {"a": 2, "b": "b2", "tenant": "t1"}, "before": null}`,
+		})
+		fooA := feed(t, f, `CREATE CHANGEFEED FOR foo WITH key_column='a', envelope='key_only'`)
+		defer closeFeed(t, fooA)
+		assertPayloads(t, fooA, []string{
+			`foo: [0]->`,
+			`foo: [2]->`,
+		})
+
+		sqlDB.ExpectErr(t, `key_column for table foo: column "nope" does not exist`,
+			`EXPERIMENTAL CHANGEFEED FOR foo WITH key_column='nope'`)
+		sqlDB.ExpectErr(t, `key_column tenant is not in the primary key of foo`,
+			`EXPERIMENTAL CHANGEFEED FOR foo WITH key_column='tenant'`)
+		sqlDB.ExpectErr(t, `key_column lists column a more than once`,
+			`EXPERIMENTAL CHANGEFEED FOR foo WITH key_column='a,a'`)
+		sqlDB.ExpectErr(t, `key_column must be a comma-separated list of column names: "a,"`,
+			`EXPERIMENTAL CHANGEFEED FOR foo WITH key_column='a,'`)
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+}
+
 func TestChangefeedStopOnSchemaChange(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -1955,19 +2250,29 @@ func TestChangefeedErrors(t *testing.T) {
 
 	// TODO(dan): These two tests shouldn't need initial data in the table
 	// to pass.
-	sqlDB.Exec(t, `CREATE TABLE dec (a DECIMAL PRIMARY KEY)`)
-	sqlDB.Exec(t, `INSERT INTO dec VALUES (1.0)`)
-	sqlDB.ExpectErr(
-		t, `pq: column a: decimal with no precision`,
-		`EXPERIMENTAL CHANGEFEED FOR dec WITH format=$1, confluent_schema_registry=$2`,
-		changefeedbase.OptFormatAvro, `bar`,
-	)
 	sqlDB.Exec(t, `CREATE TABLE "oid" (a OID PRIMARY KEY)`)
 	sqlDB.Exec(t, `INSERT INTO "oid" VALUES (3::OID)`)
 	sqlDB.ExpectErr(
 		t, `pq: column a: type OID not yet supported with avro`,
 		`EXPERIMENTAL CHANGEFEED FOR "oid" WITH format=$1, confluent_schema_registry=$2`,
-		changefeedbase.OptFormatAvro, `bar`,
+		changefeedbase.OptFormatAvro, `http://bar`,
+	)
+
+	// The schema registry is contacted over HTTP or HTTPS.
+	sqlDB.ExpectErr(
+		t, `confluent_schema_registry must be an http or https URL: kafka://bar`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH format=$2, confluent_schema_registry=$3`,
+		`kafka://nope`, changefeedbase.OptFormatAvro, `kafka://bar`,
+	)
+	sqlDB.ExpectErr(
+		t, `ca_cert requires an https confluent_schema_registry`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH format=$2, confluent_schema_registry=$3`,
+		`kafka://nope`, changefeedbase.OptFormatAvro, `http://bar?ca_cert=Zm9v`,
+	)
+	sqlDB.ExpectErr(
+		t, `unknown confluent_schema_registry query parameter: nope`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH format=$2, confluent_schema_registry=$3`,
+		`kafka://nope`, changefeedbase.OptFormatAvro, `https://bar?nope=1`,
 	)
 
 	// Check that confluent_schema_registry is only accepted if format is avro.
@@ -2075,22 +2380,112 @@ func TestChangefeedErrors(t *testing.T) {
 
 	// The avro format doesn't support key_in_value yet.
 	sqlDB.ExpectErr(
-		t, `key_in_value is not supported with format=experimental_avro`,
-		`CREATE CHANGEFEED FOR foo INTO $1 WITH key_in_value, format='experimental_avro'`,
+		t, `key_in_value is not supported with format=avro`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH key_in_value, format='avro'`,
 		`kafka://nope`,
 	)
 
 	// The cloudStorageSink is particular about the options it will work with.
 	sqlDB.ExpectErr(
-		t, `this sink is incompatible with format=experimental_avro`,
+		t, `this sink is incompatible with format=avro`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH format='avro', confluent_schema_registry=$2`,
+		`experimental-nodelocal://0/bar`, `https://schemareg-nope`,
+	)
+	// The deprecated name of the avro format is still accepted.
+	sqlDB.ExpectErr(
+		t, `this sink is incompatible with format=avro`,
 		`CREATE CHANGEFEED FOR foo INTO $1 WITH format='experimental_avro', confluent_schema_registry=$2`,
-		`experimental-nodelocal://0/bar`, `schemareg-nope`,
+		`experimental-nodelocal://0/bar`, `https://schemareg-nope`,
 	)
 	sqlDB.ExpectErr(
 		t, `this sink is incompatible with envelope=key_only`,
 		`CREATE CHANGEFEED FOR foo INTO $1 WITH envelope='key_only'`,
 		`experimental-nodelocal://0/bar`,
 	)
+	sqlDB.ExpectErr(
+		t, `this sink is incompatible with format=protobuf`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH format='protobuf'`,
+		`experimental-nodelocal://0/bar`,
+	)
+	sqlDB.ExpectErr(
+		t, `diff is not supported with format=csv`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH format='csv', diff`,
+		`experimental-nodelocal://0/bar`,
+	)
+
+	// So is the webhookSink.
+	sqlDB.ExpectErr(
+		t, `this sink is incompatible with format=avro`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH format='avro', confluent_schema_registry=$2`,
+		`webhook-https://fake-host`, `https://schemareg-nope`,
+	)
+	sqlDB.ExpectErr(
+		t, `this sink is incompatible with format=csv`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH format='csv'`,
+		`webhook-https://fake-host`,
+	)
+	sqlDB.ExpectErr(
+		t, `param ca_cert must be base 64 encoded`,
+		`CREATE CHANGEFEED FOR foo INTO $1`, `webhook-https://fake-host?ca_cert=!`,
+	)
+	sqlDB.ExpectErr(
+		t, `invalid webhook_sink_config: flush thresholds cannot be negative`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH webhook_sink_config=$2`,
+		`webhook-https://fake-host`, `{"Flush": {"Messages": -1}}`,
+	)
+	sqlDB.ExpectErr(
+		t, `webhook_sink_config is only usable with a webhook-https sink`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH webhook_sink_config=$2`,
+		`kafka://nope`, `{"Flush": {"Messages": 100}}`,
+	)
+	sqlDB.ExpectErr(
+		t, `webhook_auth_header is only usable with a webhook-https sink`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH webhook_auth_header='Basic Zm9v'`,
+		`experimental-nodelocal://0/bar`,
+	)
+
+	// The pubsubSink needs credentials unless it's pointed at an emulator.
+	sqlDB.ExpectErr(
+		t, `param AUTH must be set to specified or implicit`,
+		`CREATE CHANGEFEED FOR foo INTO $1`, `pubsub://proj`,
+	)
+	sqlDB.ExpectErr(
+		t, `this sink is incompatible with envelope=key_only`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH envelope='key_only'`,
+		`pubsub://proj?endpoint=http://localhost:8085`,
+	)
+
+	// The query of a CREATE CHANGEFEED ... AS SELECT statement is checked
+	// against the table.
+	sqlDB.ExpectErr(
+		t, `column "nope" does not exist`,
+		`CREATE CHANGEFEED INTO $1 AS SELECT nope FROM foo`, `kafka://nope`,
+	)
+	sqlDB.ExpectErr(
+		t, `argument of WHERE must be type bool, not type int`,
+		`CREATE CHANGEFEED INTO $1 AS SELECT a FROM foo WHERE a`, `kafka://nope`,
+	)
+	sqlDB.ExpectErr(
+		t, `aggregate functions are not allowed in SELECT`,
+		`CREATE CHANGEFEED INTO $1 AS SELECT max(a) FROM foo`, `kafka://nope`,
+	)
+	sqlDB.ExpectErr(
+		t, `column "a" specified more than once in CHANGEFEED query`,
+		`CREATE CHANGEFEED INTO $1 AS SELECT a, b AS a FROM foo`, `kafka://nope`,
+	)
+	sqlDB.ExpectErr(
+		t, `volatile functions are not allowed in SELECT`,
+		`CREATE CHANGEFEED INTO $1 AS SELECT a, random() FROM foo`, `kafka://nope`,
+	)
+	sqlDB.ExpectErr(
+		t, `context-dependent operators are not allowed in WHERE`,
+		`CREATE CHANGEFEED INTO $1 AS SELECT a FROM foo WHERE now() > '2021-01-01'`, `kafka://nope`,
+	)
+	sqlDB.ExpectErr(
+		t, `CREATE CHANGEFEED ... AS SELECT is incompatible with format=avro`,
+		`CREATE CHANGEFEED INTO $1 WITH format='avro', confluent_schema_registry=$2 AS SELECT a FROM foo`,
+		`kafka://nope`, `https://schemareg-nope`,
+	)
 
 	// WITH key_in_value requires envelope=wrapped
 	sqlDB.ExpectErr(
@@ -2154,12 +2549,109 @@ func TestChangefeedDescription(t *testing.T) {
 		if description != expected {
 			t.Errorf(`got "%s" expected "%s"`, description, expected)
 		}
+
+		sqlDB.QueryRow(t,
+			`CREATE CHANGEFEED INTO $1 WITH updated AS SELECT a FROM foo WHERE a > 0`, sink.String(),
+		).Scan(&jobID)
+		sqlDB.QueryRow(t,
+			`SELECT description FROM [SHOW JOBS] WHERE job_id = $1`, jobID,
+		).Scan(&description)
+		expected = `CREATE CHANGEFEED INTO '` + sink.String() +
+			`' WITH updated AS SELECT a FROM foo WHERE a > 0`
+		if description != expected {
+			t.Errorf(`got "%s" expected "%s"`, description, expected)
+		}
 	}
 
 	// Only the enterprise version uses jobs.
 	t.Run(`enterprise`, enterpriseTest(testFn))
 }
 
+func TestValidateDetailsAvroFormat(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	validateFormat := func(t *testing.T, st *cluster.Settings, format changefeedbase.FormatType) string {
+		details, err := validateDetails(ctx, st, jobspb.ChangefeedDetails{
+			Opts: map[string]string{changefeedbase.OptFormat: string(format)},
+		})
+		require.NoError(t, err)
+		return details.Opts[changefeedbase.OptFormat]
+	}
+
+	// Both spellings of the format are persisted as avro once the cluster is
+	// upgraded, and as experimental_avro until then.
+	st := cluster.MakeTestingClusterSettings()
+	require.Equal(t, `avro`, validateFormat(t, st, changefeedbase.OptFormatAvro))
+	require.Equal(t, `avro`, validateFormat(t, st, changefeedbase.OptFormatDeprecatedAvro))
+
+	oldVersion := clusterversion.ByKey(clusterversion.ChangefeedAvroFormat - 1)
+	st = cluster.MakeTestingClusterSettingsWithVersions(oldVersion, oldVersion, true /* initializeVersion */)
+	require.Equal(t, `experimental_avro`, validateFormat(t, st, changefeedbase.OptFormatAvro))
+	require.Equal(t, `experimental_avro`, validateFormat(t, st, changefeedbase.OptFormatDeprecatedAvro))
+}
+
+func TestValidateDetailsSelect(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	details := jobspb.ChangefeedDetails{Select: `SELECT a FROM foo`}
+
+	// Queries are rejected until the cluster is upgraded.
+	_, err := validateDetails(ctx, cluster.MakeTestingClusterSettings(), details)
+	require.NoError(t, err)
+
+	oldVersion := clusterversion.ByKey(clusterversion.ChangefeedQueries - 1)
+	st := cluster.MakeTestingClusterSettingsWithVersions(oldVersion, oldVersion, true /* initializeVersion */)
+	_, err = validateDetails(ctx, st, details)
+	require.EqualError(t, err,
+		`CREATE CHANGEFEED ... AS SELECT requires all nodes to be upgraded to 20.2-36`)
+}
+
+func TestValidateDetailsEndTime(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	details := jobspb.ChangefeedDetails{EndTime: hlc.Timestamp{WallTime: 1}}
+
+	// End times are rejected until the cluster is upgraded.
+	_, err := validateDetails(ctx, cluster.MakeTestingClusterSettings(), details)
+	require.NoError(t, err)
+
+	oldVersion := clusterversion.ByKey(clusterversion.ChangefeedEndTime - 1)
+	st := cluster.MakeTestingClusterSettingsWithVersions(oldVersion, oldVersion, true /* initializeVersion */)
+	_, err = validateDetails(ctx, st, details)
+	require.EqualError(t, err, `end_time requires all nodes to be upgraded to 20.2-38`)
+}
+
+func TestValidateDetailsCSVAndProtobufFormats(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	validateFormat := func(st *cluster.Settings, format changefeedbase.FormatType) error {
+		_, err := validateDetails(ctx, st, jobspb.ChangefeedDetails{
+			Opts: map[string]string{changefeedbase.OptFormat: string(format)},
+		})
+		return err
+	}
+
+	// The formats are rejected until the cluster is upgraded.
+	st := cluster.MakeTestingClusterSettings()
+	require.NoError(t, validateFormat(st, changefeedbase.OptFormatCSV))
+	require.NoError(t, validateFormat(st, changefeedbase.OptFormatProtobuf))
+
+	oldVersion := clusterversion.ByKey(clusterversion.ChangefeedCSVAndProtobufFormats - 1)
+	st = cluster.MakeTestingClusterSettingsWithVersions(oldVersion, oldVersion, true /* initializeVersion */)
+	require.EqualError(t, validateFormat(st, changefeedbase.OptFormatCSV),
+		`format=csv requires all nodes to be upgraded to 20.2-34`)
+	require.EqualError(t, validateFormat(st, changefeedbase.OptFormatProtobuf),
+		`format=protobuf requires all nodes to be upgraded to 20.2-34`)
+}
+
 func TestChangefeedPauseUnpause(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -2774,15 +3266,37 @@ func TestChangefeedMemBufferCapacity(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
 
-	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+	// Set the budget high enough for a few rows but not for all of the rows
+	// inserted by testChangefeedMemLimit.
+	testChangefeedMemLimit(t, func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
 		knobs := f.Server().(*server.TestServer).Cfg.TestingKnobs.
 			DistSQL.(*execinfra.TestingKnobs).
 			Changefeed.(*TestingKnobs)
-		// The RowContainer used internally by the memBuffer seems to request from
-		// the budget in 10240 chunks. Set this number high enough for one but not
-		// for a second. I'd love to be able to derive this from constants, but I
-		// don't see how to do that without a refactor.
 		knobs.MemBufferCapacity = 20000
+	})
+}
+
+func TestChangefeedNodeMemLimit(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	// The changefeeds of a node are limited by changefeed.memory.limit, which
+	// applies to running servers.
+	testChangefeedMemLimit(t, func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlutils.MakeSQLRunner(db).Exec(t, `SET CLUSTER SETTING changefeed.memory.limit = '20000'`)
+	})
+}
+
+// testChangefeedMemLimit checks that a changefeed whose buffer is limited by
+// the given function waits for room in the buffer when it is full.
+func testChangefeedMemLimit(
+	t *testing.T, setLimit func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory),
+) {
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		setLimit(t, db, f)
+		knobs := f.Server().(*server.TestServer).Cfg.TestingKnobs.
+			DistSQL.(*execinfra.TestingKnobs).
+			Changefeed.(*TestingKnobs)
 		beforeEmitRowCh := make(chan struct{}, 1)
 		knobs.BeforeEmitRow = func(ctx context.Context) error {
 			select {
@@ -2792,7 +3306,8 @@ func TestChangefeedMemBufferCapacity(t *testing.T) {
 			}
 			return nil
 		}
-		defer close(beforeEmitRowCh)
+		registry := f.Server().JobRegistry().(*jobs.Registry)
+		metrics := registry.MetricsStruct().Changefeed.(*Metrics)
 
 		sqlDB := sqlutils.MakeSQLRunner(db)
 		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
@@ -2807,12 +3322,26 @@ func TestChangefeedMemBufferCapacity(t *testing.T) {
 			`foo: [0]->{"after": {"a": 0, "b": "small"}}`,
 		})
 
-		// Put enough data in to overflow the buffer and verify that at some point
-		// we get the "memory budget exceeded" error.
+		// Put enough data in to overflow the buffer while the sink is blocked
+		// and verify that the changefeed waits for room in the buffer instead of
+		// failing with a "memory budget exceeded" error.
+		entriesIn := metrics.KVFeedMetrics.BufferEntriesIn.Count()
 		sqlDB.Exec(t, `INSERT INTO foo SELECT i, 'foofoofoo' FROM generate_series(1, $1) AS g(i)`, 1000)
-		if _, err := foo.Next(); !testutils.IsError(err, `memory budget exceeded`) {
-			t.Fatalf(`expected "memory budget exceeded" error got: %v`, err)
+		testutils.SucceedsSoon(t, func() error {
+			if metrics.KVFeedMetrics.BufferEntriesIn.Count() == entriesIn {
+				return errors.New(`waiting for the buffer to fill`)
+			}
+			return nil
+		})
+		require.NotZero(t, metrics.MemCurBytes.Value())
+		close(beforeEmitRowCh)
+		var expected []string
+		for i := 1; i <= 1000; i++ {
+			expected = append(expected, fmt.Sprintf(`foo: [%d]->{"after": {"a": %d, "b": "foofoofoo"}}`, i, i))
 		}
+		assertPayloads(t, foo, expected)
+		// The buffer was full at some point.
+		require.NotZero(t, metrics.KVFeedMetrics.BufferPushbackNanos.Count())
 	}
 
 	// The mem buffer is only used with RangeFeed.
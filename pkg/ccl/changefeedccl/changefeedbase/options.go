@@ -28,9 +28,12 @@ type SchemaChangePolicy string
 const (
 	OptConfluentSchemaRegistry  = `confluent_schema_registry`
 	OptCursor                   = `cursor`
+	OptEndTime                  = `end_time`
 	OptEnvelope                 = `envelope`
 	OptFormat                   = `format`
+	OptKeyColumn                = `key_column`
 	OptKeyInValue               = `key_in_value`
+	OptTopicInValue             = `topic_in_value`
 	OptResolvedTimestamps       = `resolved`
 	OptUpdatedTimestamps        = `updated`
 	OptDiff                     = `diff`
@@ -38,6 +41,9 @@ const (
 	OptSchemaChangeEvents       = `schema_change_events`
 	OptSchemaChangePolicy       = `schema_change_policy`
 	OptProtectDataFromGCOnPause = `protect_data_from_gc_on_pause`
+	OptWebhookAuthHeader        = `webhook_auth_header`
+	OptWebhookClientTimeout     = `webhook_client_timeout`
+	OptWebhookSinkConfig        = `webhook_sink_config`
 
 	// OptSchemaChangeEventClassColumnChange corresponds to all schema change
 	// events which add or remove any column.
@@ -67,26 +73,38 @@ const (
 	// cursor is specified. This option is useful to create a changefeed which
 	// subscribes only to new messages.
 	OptNoInitialScan = `no_initial_scan`
+	// OptInitialScanOnly makes the changefeed perform an initial scan, and then
+	// finish successfully once everything it scanned has been emitted, instead
+	// of watching the tables for changes. If used in conjunction with a cursor,
+	// the initial scan is performed at the cursor timestamp.
+	OptInitialScanOnly = `initial_scan_only`
 
 	OptEnvelopeKeyOnly       EnvelopeType = `key_only`
 	OptEnvelopeRow           EnvelopeType = `row`
 	OptEnvelopeDeprecatedRow EnvelopeType = `deprecated_row`
 	OptEnvelopeWrapped       EnvelopeType = `wrapped`
 
-	OptFormatJSON FormatType = `json`
-	OptFormatAvro FormatType = `experimental_avro`
+	OptFormatJSON           FormatType = `json`
+	OptFormatAvro           FormatType = `avro`
+	OptFormatDeprecatedAvro FormatType = `experimental_avro`
+	OptFormatCSV            FormatType = `csv`
+	OptFormatProtobuf       FormatType = `protobuf`
 
 	SinkParamCACert           = `ca_cert`
 	SinkParamClientCert       = `client_cert`
 	SinkParamClientKey        = `client_key`
 	SinkParamFileSize         = `file_size`
+	SinkParamPubsubEndpoint   = `endpoint`
 	SinkParamSchemaTopic      = `schema_topic`
 	SinkParamTLSEnabled       = `tls_enabled`
 	SinkParamSkipTLSVerify    = `insecure_tls_skip_verify`
+	SinkParamTopicName        = `topic_name`
 	SinkParamTopicPrefix      = `topic_prefix`
 	SinkSchemeBuffer          = ``
 	SinkSchemeExperimentalSQL = `experimental-sql`
 	SinkSchemeKafka           = `kafka`
+	SinkSchemePubsub          = `pubsub`
+	SinkSchemeWebhookHTTPS    = `webhook-https`
 	SinkParamSASLEnabled      = `sasl_enabled`
 	SinkParamSASLHandshake    = `sasl_handshake`
 	SinkParamSASLUser         = `sasl_user`
@@ -98,9 +116,12 @@ const (
 var ChangefeedOptionExpectValues = map[string]sql.KVStringOptValidate{
 	OptConfluentSchemaRegistry:  sql.KVStringOptRequireValue,
 	OptCursor:                   sql.KVStringOptRequireValue,
+	OptEndTime:                  sql.KVStringOptRequireValue,
 	OptEnvelope:                 sql.KVStringOptRequireValue,
 	OptFormat:                   sql.KVStringOptRequireValue,
+	OptKeyColumn:                sql.KVStringOptRequireValue,
 	OptKeyInValue:               sql.KVStringOptRequireNoValue,
+	OptTopicInValue:             sql.KVStringOptRequireNoValue,
 	OptResolvedTimestamps:       sql.KVStringOptAny,
 	OptUpdatedTimestamps:        sql.KVStringOptRequireNoValue,
 	OptDiff:                     sql.KVStringOptRequireNoValue,
@@ -109,5 +130,16 @@ var ChangefeedOptionExpectValues = map[string]sql.KVStringOptValidate{
 	OptSchemaChangePolicy:       sql.KVStringOptRequireValue,
 	OptInitialScan:              sql.KVStringOptRequireNoValue,
 	OptNoInitialScan:            sql.KVStringOptRequireNoValue,
+	OptInitialScanOnly:          sql.KVStringOptRequireNoValue,
 	OptProtectDataFromGCOnPause: sql.KVStringOptRequireNoValue,
+	OptWebhookAuthHeader:        sql.KVStringOptRequireValue,
+	OptWebhookClientTimeout:     sql.KVStringOptRequireValue,
+	OptWebhookSinkConfig:        sql.KVStringOptRequireValue,
+}
+
+// WebhookSinkOptions are the options that only apply to webhook sinks.
+var WebhookSinkOptions = []string{
+	OptWebhookAuthHeader,
+	OptWebhookClientTimeout,
+	OptWebhookSinkConfig,
 }
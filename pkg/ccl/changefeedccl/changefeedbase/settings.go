@@ -25,3 +25,13 @@ var TableDescriptorPollInterval = settings.RegisterDurationSetting(
 	1*time.Second,
 	settings.NonNegativeDuration,
 )
+
+// PerChangefeedMemLimit controls how much data can be buffered by a single
+// changefeed on each node. All of the changefeeds on a node share the SQL
+// memory pool, so this only prevents a single one from exhausting it.
+var PerChangefeedMemLimit = settings.RegisterByteSizeSetting(
+	"changefeed.memory.per_changefeed_limit",
+	"the amount of memory which can be used to buffer the changes of a single changefeed on each node",
+	512<<20, // 512 MiB
+	settings.PositiveInt,
+)
@@ -9,8 +9,11 @@
 package changefeedbase
 
 import (
+	"strings"
+
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/errors"
@@ -40,10 +43,15 @@ func ValidateTable(targets jobspb.ChangefeedTargets, tableDesc *tabledesc.Immuta
 	if tableDesc.IsSequence() {
 		return errors.Errorf(`CHANGEFEED cannot target sequences: %s`, tableDesc.Name)
 	}
-	if len(tableDesc.Families) != 1 {
-		return errors.Errorf(
+	if t.FamilyName != `` {
+		if _, err := TargetFamily(t, tableDesc); err != nil {
+			return err
+		}
+	} else if len(tableDesc.Families) != 1 {
+		return errors.WithHint(errors.Errorf(
 			`CHANGEFEEDs are currently supported on tables with exactly 1 column family: %s has %d`,
-			tableDesc.Name, len(tableDesc.Families))
+			tableDesc.Name, len(tableDesc.Families)),
+			`use CHANGEFEED FOR TABLE ... FAMILY to watch one of its column families`)
 	}
 
 	if tableDesc.State == descpb.DescriptorState_DROP {
@@ -60,3 +68,83 @@ func ValidateTable(targets jobspb.ChangefeedTargets, tableDesc *tabledesc.Immuta
 
 	return nil
 }
+
+// TargetFamily returns the column family of the table watched by the given
+// target, or nil if the target watches all the column families of the table.
+func TargetFamily(
+	target jobspb.ChangefeedTarget, tableDesc catalog.TableDescriptor,
+) (*descpb.ColumnFamilyDescriptor, error) {
+	if target.FamilyName == `` {
+		return nil, nil
+	}
+	families := tableDesc.GetFamilies()
+	for i := range families {
+		if families[i].Name == target.FamilyName {
+			return &families[i], nil
+		}
+	}
+	return nil, errors.Errorf(`column family %s does not exist in table %s`,
+		target.FamilyName, tableDesc.GetName())
+}
+
+// KeyColumnIDs returns the IDs of the columns which make up the keys of the
+// messages emitted for the table watched by the given target, as given by the
+// key_column option, or nil if the option wasn't specified, in which case the
+// keys are made of the primary key columns.
+//
+// Deleted rows only hold their primary key columns, so key columns outside of
+// the primary key are taken from the previous value of the row, which requires
+// the diff option.
+func KeyColumnIDs(
+	opts map[string]string, target jobspb.ChangefeedTarget, tableDesc catalog.TableDescriptor,
+) ([]descpb.ColumnID, error) {
+	o, ok := opts[OptKeyColumn]
+	if !ok {
+		return nil, nil
+	}
+	family, err := TargetFamily(target, tableDesc)
+	if err != nil {
+		return nil, err
+	}
+	var pkColIDs, familyColIDs catalog.TableColSet
+	for _, colID := range tableDesc.GetPrimaryIndex().ColumnIDs {
+		pkColIDs.Add(colID)
+	}
+	if family != nil {
+		for _, colID := range family.ColumnIDs {
+			familyColIDs.Add(colID)
+		}
+	}
+	_, withDiff := opts[OptDiff]
+
+	var colIDs []descpb.ColumnID
+	var seen catalog.TableColSet
+	for _, name := range strings.Split(o, `,`) {
+		name = strings.TrimSpace(name)
+		if name == `` {
+			return nil, errors.Errorf(`%s must be a comma-separated list of column names: %q`,
+				OptKeyColumn, o)
+		}
+		col, err := tableDesc.FindActiveColumnByName(name)
+		if err != nil {
+			return nil, errors.Wrapf(err, `%s for table %s`, OptKeyColumn, tableDesc.GetName())
+		}
+		if seen.Contains(col.ID) {
+			return nil, errors.Errorf(`%s lists column %s more than once`, OptKeyColumn, name)
+		}
+		seen.Add(col.ID)
+		if !pkColIDs.Contains(col.ID) {
+			if family != nil && !familyColIDs.Contains(col.ID) {
+				return nil, errors.Errorf(`%s %s is not in the primary key nor in column family %s of %s`,
+					OptKeyColumn, name, family.Name, tableDesc.GetName())
+			}
+			if !withDiff {
+				return nil, errors.WithHint(errors.Errorf(
+					`%s %s is not in the primary key of %s`, OptKeyColumn, name, tableDesc.GetName()),
+					`the diff option is required to key deleted rows by columns outside of the primary key`)
+			}
+		}
+		colIDs = append(colIDs, col.ID)
+	}
+	return colIDs, nil
+}
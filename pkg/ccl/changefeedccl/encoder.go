@@ -13,27 +13,20 @@ import (
 	"context"
 	"encoding/binary"
 	gojson "encoding/json"
-	"io/ioutil"
-	"net/url"
-	"path/filepath"
 	"time"
 
-	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
-	"github.com/cockroachdb/cockroach/pkg/util/httputil"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
-	"github.com/cockroachdb/cockroach/pkg/util/log"
-	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/errors"
 )
 
 const (
-	confluentSchemaContentType   = `application/vnd.schemaregistry.v1+json`
 	confluentSubjectSuffixKey    = `-key`
 	confluentSubjectSuffixValue  = `-value`
 	confluentAvroWireFormatMagic = byte(0)
@@ -61,6 +54,13 @@ type encodeRow struct {
 	// prevTableDesc is a TableDescriptor for the table containing `prevDatums`.
 	// It's valid for interpreting the row at `updated.Prev()`.
 	prevTableDesc catalog.TableDescriptor
+	// projection, if non-nil, holds the columns that the query of a CREATE
+	// CHANGEFEED ... AS SELECT statement computed from `datums`. They replace
+	// the columns of the table in encoded values. Keys are still made of the
+	// primary key columns in `datums`.
+	projection *projectedRow
+	// prevProjection, if non-nil, is the projection of `prevDatums`.
+	prevProjection *projectedRow
 }
 
 // Encoder turns a row into a serialized changefeed key, value, or resolved
@@ -86,8 +86,12 @@ func getEncoder(opts map[string]string) (Encoder, error) {
 	switch changefeedbase.FormatType(opts[changefeedbase.OptFormat]) {
 	case ``, changefeedbase.OptFormatJSON:
 		return makeJSONEncoder(opts)
-	case changefeedbase.OptFormatAvro:
+	case changefeedbase.OptFormatAvro, changefeedbase.OptFormatDeprecatedAvro:
 		return newConfluentAvroEncoder(opts)
+	case changefeedbase.OptFormatCSV:
+		return makeCSVEncoder(opts)
+	case changefeedbase.OptFormatProtobuf:
+		return newProtobufEncoder(opts)
 	default:
 		return nil, errors.Errorf(`unknown %s: %s`, changefeedbase.OptFormat, opts[changefeedbase.OptFormat])
 	}
@@ -98,7 +102,7 @@ func getEncoder(opts map[string]string) (Encoder, error) {
 // to its value. Updated timestamps in rows and resolved timestamp payloads are
 // stored in a sub-object under the `__crdb__` key in the top-level JSON object.
 type jsonEncoder struct {
-	updatedField, beforeField, wrapped, keyOnly, keyInValue bool
+	updatedField, beforeField, wrapped, keyOnly, keyInValue, topicInValue bool
 
 	alloc rowenc.DatumAlloc
 	buf   bytes.Buffer
@@ -122,6 +126,11 @@ func makeJSONEncoder(opts map[string]string) (*jsonEncoder, error) {
 		return nil, errors.Errorf(`%s is only usable with %s=%s`,
 			changefeedbase.OptKeyInValue, changefeedbase.OptEnvelope, changefeedbase.OptEnvelopeWrapped)
 	}
+	_, e.topicInValue = opts[changefeedbase.OptTopicInValue]
+	if e.topicInValue && !e.wrapped {
+		return nil, errors.Errorf(`%s is only usable with %s=%s`,
+			changefeedbase.OptTopicInValue, changefeedbase.OptEnvelope, changefeedbase.OptEnvelopeWrapped)
+	}
 	return e, nil
 }
 
@@ -169,7 +178,12 @@ func (e *jsonEncoder) EncodeValue(_ context.Context, row encodeRow) ([]byte, err
 	}
 
 	var after map[string]interface{}
-	if !row.deleted {
+	if row.projection != nil {
+		var err error
+		if after, err = projectionAsJSON(row.projection); err != nil {
+			return nil, err
+		}
+	} else if !row.deleted {
 		columns := row.tableDesc.GetPublicColumns()
 		after = make(map[string]interface{}, len(columns))
 		for i := range columns {
@@ -187,7 +201,12 @@ func (e *jsonEncoder) EncodeValue(_ context.Context, row encodeRow) ([]byte, err
 	}
 
 	var before map[string]interface{}
-	if row.prevDatums != nil && !row.prevDeleted {
+	if row.prevProjection != nil {
+		var err error
+		if before, err = projectionAsJSON(row.prevProjection); err != nil {
+			return nil, err
+		}
+	} else if row.prevDatums != nil && !row.prevDeleted {
 		columns := row.prevTableDesc.GetPublicColumns()
 		before = make(map[string]interface{}, len(columns))
 		for i := range columns {
@@ -225,6 +244,9 @@ func (e *jsonEncoder) EncodeValue(_ context.Context, row encodeRow) ([]byte, err
 			}
 			jsonEntries[`key`] = keyEntries
 		}
+		if e.topicInValue {
+			jsonEntries[`topic`] = row.tableDesc.GetName()
+		}
 	} else {
 		jsonEntries = after
 	}
@@ -249,6 +271,19 @@ func (e *jsonEncoder) EncodeValue(_ context.Context, row encodeRow) ([]byte, err
 	return e.buf.Bytes(), nil
 }
 
+// projectionAsJSON returns a JSON object mapping every column of the given
+// projection to its value.
+func projectionAsJSON(projection *projectedRow) (map[string]interface{}, error) {
+	entries := make(map[string]interface{}, len(projection.names))
+	for i, name := range projection.names {
+		var err error
+		if entries[name], err = tree.AsJSON(projection.datums[i], time.UTC); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
 // EncodeResolvedTimestamp implements the Encoder interface.
 func (e *jsonEncoder) EncodeResolvedTimestamp(
 	_ context.Context, _ string, resolved hlc.Timestamp,
@@ -271,21 +306,44 @@ func (e *jsonEncoder) EncodeResolvedTimestamp(
 // JSON format. Keys are the primary key columns in a record. Values are all
 // columns in a record.
 type confluentAvroEncoder struct {
-	registryURL                        string
+	schemaRegistry                     *confluentSchemaRegistry
 	updatedField, beforeField, keyOnly bool
 
-	keyCache      map[tableIDAndVersion]confluentRegisteredKeySchema
-	valueCache    map[tableIDAndVersionPair]confluentRegisteredEnvelopeSchema
+	keyCache      map[avroSchemaKey]confluentRegisteredKeySchema
+	valueCache    map[avroSchemaKeyPair]confluentRegisteredEnvelopeSchema
 	resolvedCache map[string]confluentRegisteredEnvelopeSchema
 }
 
 type tableIDAndVersion uint64
-type tableIDAndVersionPair [2]tableIDAndVersion // [before, after]
 
 func makeTableIDAndVersion(id descpb.ID, version descpb.DescriptorVersion) tableIDAndVersion {
 	return tableIDAndVersion(id)<<32 + tableIDAndVersion(version)
 }
 
+// avroSchemaKey identifies the avro schema of a version of a table. The schema
+// also depends on the user-defined types of the columns of the table, e.g. on
+// the values of an ENUM, which change without bumping the version of the table
+// descriptor. The versions of these types are part of the key, encoded in
+// typeVersions.
+type avroSchemaKey struct {
+	tableIDAndVersion
+	typeVersions string
+}
+
+type avroSchemaKeyPair [2]avroSchemaKey // [before, after]
+
+func makeAvroSchemaKey(desc catalog.TableDescriptor) avroSchemaKey {
+	key := avroSchemaKey{tableIDAndVersion: makeTableIDAndVersion(desc.GetID(), desc.GetVersion())}
+	var typeVersions []byte
+	for _, col := range desc.GetPublicColumns() {
+		if col.Type.UserDefined() {
+			typeVersions = encoding.EncodeUvarintAscending(typeVersions, uint64(col.Type.TypeMeta.Version))
+		}
+	}
+	key.typeVersions = string(typeVersions)
+	return key
+}
+
 type confluentRegisteredKeySchema struct {
 	schema     *avroDataRecord
 	registryID int32
@@ -299,7 +357,7 @@ type confluentRegisteredEnvelopeSchema struct {
 var _ Encoder = &confluentAvroEncoder{}
 
 func newConfluentAvroEncoder(opts map[string]string) (*confluentAvroEncoder, error) {
-	e := &confluentAvroEncoder{registryURL: opts[changefeedbase.OptConfluentSchemaRegistry]}
+	e := &confluentAvroEncoder{}
 
 	switch opts[changefeedbase.OptEnvelope] {
 	case string(changefeedbase.OptEnvelopeKeyOnly):
@@ -324,21 +382,30 @@ func newConfluentAvroEncoder(opts map[string]string) (*confluentAvroEncoder, err
 		return nil, errors.Errorf(`%s is not supported with %s=%s`,
 			changefeedbase.OptKeyInValue, changefeedbase.OptFormat, changefeedbase.OptFormatAvro)
 	}
+	if _, ok := opts[changefeedbase.OptTopicInValue]; ok {
+		return nil, errors.Errorf(`%s is not supported with %s=%s`,
+			changefeedbase.OptTopicInValue, changefeedbase.OptFormat, changefeedbase.OptFormatAvro)
+	}
 
-	if len(e.registryURL) == 0 {
+	registryURL := opts[changefeedbase.OptConfluentSchemaRegistry]
+	if len(registryURL) == 0 {
 		return nil, errors.Errorf(`WITH option %s is required for %s=%s`,
 			changefeedbase.OptConfluentSchemaRegistry, changefeedbase.OptFormat, changefeedbase.OptFormatAvro)
 	}
+	var err error
+	if e.schemaRegistry, err = newConfluentSchemaRegistry(registryURL); err != nil {
+		return nil, err
+	}
 
-	e.keyCache = make(map[tableIDAndVersion]confluentRegisteredKeySchema)
-	e.valueCache = make(map[tableIDAndVersionPair]confluentRegisteredEnvelopeSchema)
+	e.keyCache = make(map[avroSchemaKey]confluentRegisteredKeySchema)
+	e.valueCache = make(map[avroSchemaKeyPair]confluentRegisteredEnvelopeSchema)
 	e.resolvedCache = make(map[string]confluentRegisteredEnvelopeSchema)
 	return e, nil
 }
 
 // EncodeKey implements the Encoder interface.
 func (e *confluentAvroEncoder) EncodeKey(ctx context.Context, row encodeRow) ([]byte, error) {
-	cacheKey := makeTableIDAndVersion(row.tableDesc.GetID(), row.tableDesc.GetVersion())
+	cacheKey := makeAvroSchemaKey(row.tableDesc)
 	registered, ok := e.keyCache[cacheKey]
 	if !ok {
 		var err error
@@ -373,11 +440,11 @@ func (e *confluentAvroEncoder) EncodeValue(ctx context.Context, row encodeRow) (
 		return nil, nil
 	}
 
-	var cacheKey tableIDAndVersionPair
+	var cacheKey avroSchemaKeyPair
 	if e.beforeField && row.prevTableDesc != nil {
-		cacheKey[0] = makeTableIDAndVersion(row.prevTableDesc.GetID(), row.prevTableDesc.GetVersion())
+		cacheKey[0] = makeAvroSchemaKey(row.prevTableDesc)
 	}
-	cacheKey[1] = makeTableIDAndVersion(row.tableDesc.GetID(), row.tableDesc.GetVersion())
+	cacheKey[1] = makeAvroSchemaKey(row.tableDesc)
 	registered, ok := e.valueCache[cacheKey]
 	if !ok {
 		var beforeDataSchema *avroDataRecord
@@ -473,63 +540,5 @@ func (e *confluentAvroEncoder) EncodeResolvedTimestamp(
 func (e *confluentAvroEncoder) register(
 	ctx context.Context, schema *avroRecord, subject string,
 ) (int32, error) {
-	type confluentSchemaVersionRequest struct {
-		Schema string `json:"schema"`
-	}
-	type confluentSchemaVersionResponse struct {
-		ID int32 `json:"id"`
-	}
-
-	url, err := url.Parse(e.registryURL)
-	if err != nil {
-		return 0, err
-	}
-	url.Path = filepath.Join(url.EscapedPath(), `subjects`, subject, `versions`)
-
-	schemaStr := schema.codec.Schema()
-	if log.V(1) {
-		log.Infof(ctx, "registering avro schema %s %s", url, schemaStr)
-	}
-
-	req := confluentSchemaVersionRequest{Schema: schemaStr}
-	var buf bytes.Buffer
-	if err := gojson.NewEncoder(&buf).Encode(req); err != nil {
-		return 0, err
-	}
-
-	var id int32
-
-	// Since network services are often a source of flakes, add a few retries here
-	// before we give up and return an error that will bubble up and tear down the
-	// entire changefeed, though that error is marked as retryable so that the job
-	// itself can attempt to start the changefeed again. TODO(dt): If the registry
-	// is down or constantly returning errors, we can't make progress. Continuing
-	// to indicate that we're "running" in this case can be misleading, as we
-	// really aren't anymore. Right now the MO in CDC is try and try again
-	// forever, so doing so here is consistent with the behavior elsewhere, but we
-	// should revisit this more broadly as this pattern can easily mask real,
-	// actionable issues in the operator's environment that which they might be
-	// able to resolve if we made them visible in a failure instead.
-	if err := retry.WithMaxAttempts(ctx, base.DefaultRetryOptions(), 3, func() error {
-		resp, err := httputil.Post(ctx, url.String(), confluentSchemaContentType, &buf)
-		if err != nil {
-			return errors.Wrap(err, "contacting confluent schema registry")
-		}
-		defer resp.Body.Close()
-		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-			body, _ := ioutil.ReadAll(resp.Body)
-			return errors.Errorf(`registering schema to %s %s: %s`, url.String(), resp.Status, body)
-		}
-		var res confluentSchemaVersionResponse
-		if err := gojson.NewDecoder(resp.Body).Decode(&res); err != nil {
-			return errors.Wrap(err, "decoding confluent schema registry reply")
-		}
-		id = res.ID
-		return nil
-	}); err != nil {
-		log.Warningf(ctx, "%+v", err)
-		return 0, MarkRetryableError(err)
-	}
-
-	return id, nil
+	return e.schemaRegistry.RegisterSchemaForSubject(ctx, subject, schema.codec.Schema())
 }
@@ -0,0 +1,149 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding/csv"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/errors"
+)
+
+// csvEncoder encodes changefeed entries as CSV records, without a header.
+// Keys are the primary key columns of a row. Values start with an operation
+// field, csvOpUpsert or csvOpDelete, followed by every column of a row in the
+// order of the table descriptor, whatever the envelope: the primary key
+// columns are always part of the value, so `key_in_value` is a no-op. NULLs
+// are encoded as empty fields.
+//
+// Deleted rows only hold their primary key columns, so their other columns are
+// encoded as empty fields and all the records of a table have the same number
+// of fields. Resolved timestamps are encoded as JSON, like those of the
+// jsonEncoder with the wrapped envelope.
+type csvEncoder struct {
+	alloc  rowenc.DatumAlloc
+	buf    bytes.Buffer
+	writer *csv.Writer
+	record []string
+}
+
+var _ Encoder = &csvEncoder{}
+
+// The values of the operation field of the records of rows.
+const (
+	csvOpUpsert = `upsert`
+	csvOpDelete = `delete`
+)
+
+func makeCSVEncoder(opts map[string]string) (*csvEncoder, error) {
+	switch changefeedbase.EnvelopeType(opts[changefeedbase.OptEnvelope]) {
+	case changefeedbase.OptEnvelopeRow, changefeedbase.OptEnvelopeWrapped:
+	default:
+		return nil, errors.Errorf(`%s=%s is not supported with %s=%s`,
+			changefeedbase.OptEnvelope, opts[changefeedbase.OptEnvelope],
+			changefeedbase.OptFormat, changefeedbase.OptFormatCSV)
+	}
+	for _, opt := range []string{
+		changefeedbase.OptDiff, changefeedbase.OptUpdatedTimestamps, changefeedbase.OptTopicInValue,
+	} {
+		if _, ok := opts[opt]; ok {
+			return nil, errors.Errorf(`%s is not supported with %s=%s`,
+				opt, changefeedbase.OptFormat, changefeedbase.OptFormatCSV)
+		}
+	}
+	e := &csvEncoder{}
+	e.writer = csv.NewWriter(&e.buf)
+	return e, nil
+}
+
+// EncodeKey implements the Encoder interface.
+func (e *csvEncoder) EncodeKey(_ context.Context, row encodeRow) ([]byte, error) {
+	e.record = e.record[:0]
+	if err := e.appendPrimaryKey(row); err != nil {
+		return nil, err
+	}
+	return e.writeRecord()
+}
+
+// EncodeValue implements the Encoder interface.
+func (e *csvEncoder) EncodeValue(_ context.Context, row encodeRow) ([]byte, error) {
+	e.record = append(e.record[:0], csvOpUpsert)
+	if row.deleted {
+		e.record[0] = csvOpDelete
+	}
+	primaryIndex := row.tableDesc.GetPrimaryIndex()
+	columns := row.tableDesc.GetPublicColumns()
+	for i := range columns {
+		if row.deleted && !descpb.ColumnIDs(primaryIndex.ColumnIDs).Contains(columns[i].ID) {
+			e.record = append(e.record, ``)
+			continue
+		}
+		field, err := e.formatField(&columns[i], row.datums[i])
+		if err != nil {
+			return nil, err
+		}
+		e.record = append(e.record, field)
+	}
+	return e.writeRecord()
+}
+
+// EncodeResolvedTimestamp implements the Encoder interface.
+func (e *csvEncoder) EncodeResolvedTimestamp(
+	ctx context.Context, topic string, resolved hlc.Timestamp,
+) ([]byte, error) {
+	return (&jsonEncoder{wrapped: true}).EncodeResolvedTimestamp(ctx, topic, resolved)
+}
+
+// appendPrimaryKey appends the primary key columns of the given row to the
+// current record.
+func (e *csvEncoder) appendPrimaryKey(row encodeRow) error {
+	colIdxByID := row.tableDesc.ColumnIdxMap()
+	for _, colID := range row.tableDesc.GetPrimaryIndex().ColumnIDs {
+		idx, ok := colIdxByID.Get(colID)
+		if !ok {
+			return errors.Errorf(`unknown column id: %d`, colID)
+		}
+		field, err := e.formatField(row.tableDesc.GetColumnAtIdx(idx), row.datums[idx])
+		if err != nil {
+			return err
+		}
+		e.record = append(e.record, field)
+	}
+	return nil
+}
+
+func (e *csvEncoder) formatField(col *descpb.ColumnDescriptor, datum rowenc.EncDatum) (string, error) {
+	if err := datum.EnsureDecoded(col.Type, &e.alloc); err != nil {
+		return ``, err
+	}
+	if datum.Datum == tree.DNull {
+		return ``, nil
+	}
+	return tree.AsStringWithFlags(datum.Datum, tree.FmtExport), nil
+}
+
+// writeRecord encodes the current record, without its trailing newline: the
+// sinks delimit records themselves.
+func (e *csvEncoder) writeRecord() ([]byte, error) {
+	e.buf.Reset()
+	if err := e.writer.Write(e.record); err != nil {
+		return nil, err
+	}
+	e.writer.Flush()
+	if err := e.writer.Error(); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(e.buf.Bytes(), []byte{'\n'}), nil
+}
@@ -0,0 +1,334 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const (
+	protobufPackage = `cockroach.changefeed`
+
+	protobufRowMessage      = `Row`
+	protobufKeyMessage      = `Key`
+	protobufEnvelopeMessage = `Envelope`
+	protobufResolvedMessage = `Resolved`
+
+	protobufAfterField    = 1
+	protobufBeforeField   = 2
+	protobufUpdatedField  = 3
+	protobufResolvedField = 1
+)
+
+// protobufEncoder encodes changefeed entries as protocol buffers, whose
+// descriptors are generated for each version of a table. The descriptor of a
+// table version is a proto2 file in the `cockroach.changefeed.<table>`
+// package, with the following messages:
+//
+//  - `Key` holds the primary key columns of a row and is used for keys.
+//  - `Row` holds every column of a row and is used for values with the row
+//    envelope.
+//  - `Envelope` holds the new value of a row in its `after` field, the old one
+//    in its `before` field (WITH diff) and the mvcc timestamp of the change in
+//    its `updated` field (WITH updated). It is used for values with the
+//    wrapped envelope.
+//
+// The fields of `Key` and `Row` are numbered with the IDs of the columns, so
+// that a consumer can decode the messages of any version of a table with the
+// descriptor of any other version: the columns which are not part of that
+// version are skipped as unknown fields, or are missing. NULLs are missing
+// fields. Columns are encoded as the scalar type matching their SQL type (bool,
+// int64, double, string or bytes), and as strings in their textual
+// representation otherwise.
+//
+// Resolved timestamps are encoded as the `cockroach.changefeed.Resolved`
+// message, whose `resolved` field holds the timestamp.
+type protobufEncoder struct {
+	updatedField, beforeField, wrapped, keyOnly bool
+
+	schemaCache map[tableIDAndVersion]*protobufTableSchema
+	resolved    protoreflect.MessageDescriptor
+
+	alloc rowenc.DatumAlloc
+	buf   []byte
+}
+
+var _ Encoder = &protobufEncoder{}
+
+// protobufTableSchema holds the messages generated for a version of a table.
+type protobufTableSchema struct {
+	key, row, envelope protoreflect.MessageDescriptor
+}
+
+func newProtobufEncoder(opts map[string]string) (*protobufEncoder, error) {
+	e := &protobufEncoder{
+		keyOnly: changefeedbase.EnvelopeType(opts[changefeedbase.OptEnvelope]) == changefeedbase.OptEnvelopeKeyOnly,
+		wrapped: changefeedbase.EnvelopeType(opts[changefeedbase.OptEnvelope]) == changefeedbase.OptEnvelopeWrapped,
+	}
+	_, e.updatedField = opts[changefeedbase.OptUpdatedTimestamps]
+	if e.updatedField && !e.wrapped {
+		return nil, errors.Errorf(`%s is only usable with %s=%s`,
+			changefeedbase.OptUpdatedTimestamps, changefeedbase.OptEnvelope, changefeedbase.OptEnvelopeWrapped)
+	}
+	_, e.beforeField = opts[changefeedbase.OptDiff]
+	if e.beforeField && !e.wrapped {
+		return nil, errors.Errorf(`%s is only usable with %s=%s`,
+			changefeedbase.OptDiff, changefeedbase.OptEnvelope, changefeedbase.OptEnvelopeWrapped)
+	}
+	for _, opt := range []string{changefeedbase.OptKeyInValue, changefeedbase.OptTopicInValue} {
+		if _, ok := opts[opt]; ok {
+			return nil, errors.Errorf(`%s is not supported with %s=%s`,
+				opt, changefeedbase.OptFormat, changefeedbase.OptFormatProtobuf)
+		}
+	}
+
+	resolved, err := protobufResolvedDescriptor()
+	if err != nil {
+		return nil, err
+	}
+	e.resolved = resolved
+	e.schemaCache = make(map[tableIDAndVersion]*protobufTableSchema)
+	return e, nil
+}
+
+// EncodeKey implements the Encoder interface.
+func (e *protobufEncoder) EncodeKey(_ context.Context, row encodeRow) ([]byte, error) {
+	schema, err := e.schemaForDesc(row.tableDesc)
+	if err != nil {
+		return nil, err
+	}
+	key, err := e.rowToMessage(schema.key, row.tableDesc, row.datums)
+	if err != nil {
+		return nil, err
+	}
+	return e.marshal(key)
+}
+
+// EncodeValue implements the Encoder interface.
+func (e *protobufEncoder) EncodeValue(_ context.Context, row encodeRow) ([]byte, error) {
+	if e.keyOnly || (!e.wrapped && row.deleted) {
+		return nil, nil
+	}
+	schema, err := e.schemaForDesc(row.tableDesc)
+	if err != nil {
+		return nil, err
+	}
+	if !e.wrapped {
+		after, err := e.rowToMessage(schema.row, row.tableDesc, row.datums)
+		if err != nil {
+			return nil, err
+		}
+		return e.marshal(after)
+	}
+
+	envelope := dynamicpb.NewMessage(schema.envelope)
+	fields := schema.envelope.Fields()
+	if !row.deleted {
+		after, err := e.rowToMessage(schema.row, row.tableDesc, row.datums)
+		if err != nil {
+			return nil, err
+		}
+		envelope.Set(fields.ByNumber(protobufAfterField), protoreflect.ValueOfMessage(after))
+	}
+	if e.beforeField && row.prevDatums != nil && !row.prevDeleted {
+		// The fields are numbered with column IDs, so the previous value can be
+		// encoded with the message of the current version of the table.
+		before, err := e.rowToMessage(schema.row, row.prevTableDesc, row.prevDatums)
+		if err != nil {
+			return nil, err
+		}
+		envelope.Set(fields.ByNumber(protobufBeforeField), protoreflect.ValueOfMessage(before))
+	}
+	if e.updatedField {
+		envelope.Set(fields.ByNumber(protobufUpdatedField),
+			protoreflect.ValueOfString(row.updated.AsOfSystemTime()))
+	}
+	return e.marshal(envelope)
+}
+
+// EncodeResolvedTimestamp implements the Encoder interface.
+func (e *protobufEncoder) EncodeResolvedTimestamp(
+	_ context.Context, _ string, resolved hlc.Timestamp,
+) ([]byte, error) {
+	m := dynamicpb.NewMessage(e.resolved)
+	m.Set(e.resolved.Fields().ByNumber(protobufResolvedField),
+		protoreflect.ValueOfString(tree.TimestampToDecimalDatum(resolved).Decimal.String()))
+	return e.marshal(m)
+}
+
+func (e *protobufEncoder) schemaForDesc(
+	tableDesc catalog.TableDescriptor,
+) (*protobufTableSchema, error) {
+	cacheKey := makeTableIDAndVersion(tableDesc.GetID(), tableDesc.GetVersion())
+	if schema, ok := e.schemaCache[cacheKey]; ok {
+		return schema, nil
+	}
+	file, err := protodesc.NewFile(tableToProtobufDescriptor(tableDesc), nil /* resolver */)
+	if err != nil {
+		return nil, errors.Wrapf(err, `generating protobuf descriptor for table %s`, tableDesc.GetName())
+	}
+	messages := file.Messages()
+	schema := &protobufTableSchema{
+		key:      messages.ByName(protobufKeyMessage),
+		row:      messages.ByName(protobufRowMessage),
+		envelope: messages.ByName(protobufEnvelopeMessage),
+	}
+	e.schemaCache[cacheKey] = schema
+	return schema, nil
+}
+
+// rowToMessage returns a message of the given type holding the columns of the
+// given row which have a field in the message.
+func (e *protobufEncoder) rowToMessage(
+	md protoreflect.MessageDescriptor, tableDesc catalog.TableDescriptor, datums rowenc.EncDatumRow,
+) (*dynamicpb.Message, error) {
+	m := dynamicpb.NewMessage(md)
+	fields := md.Fields()
+	for i, col := range tableDesc.GetPublicColumns() {
+		field := fields.ByNumber(protoreflect.FieldNumber(col.ID))
+		if field == nil {
+			continue
+		}
+		datum := datums[i]
+		if err := datum.EnsureDecoded(col.Type, &e.alloc); err != nil {
+			return nil, err
+		}
+		if datum.Datum == tree.DNull {
+			continue
+		}
+		m.Set(field, datumToProtobufValue(field.Kind(), datum.Datum))
+	}
+	return m, nil
+}
+
+func (e *protobufEncoder) marshal(m proto.Message) ([]byte, error) {
+	var err error
+	e.buf, err = proto.MarshalOptions{Deterministic: true}.MarshalAppend(e.buf[:0], m)
+	return e.buf, err
+}
+
+// tableToProtobufDescriptor generates the protobuf descriptor of the given
+// version of a table. See protobufEncoder for its layout.
+func tableToProtobufDescriptor(tableDesc catalog.TableDescriptor) *descriptorpb.FileDescriptorProto {
+	name := SQLNameToAvroName(tableDesc.GetName())
+	pkg := protobufPackage + `.` + name
+
+	row := &descriptorpb.DescriptorProto{Name: proto.String(protobufRowMessage)}
+	for _, col := range tableDesc.GetPublicColumns() {
+		row.Field = append(row.Field, columnToProtobufField(&col))
+	}
+	key := &descriptorpb.DescriptorProto{Name: proto.String(protobufKeyMessage)}
+	for _, colID := range tableDesc.GetPrimaryIndex().ColumnIDs {
+		for _, col := range tableDesc.GetPublicColumns() {
+			if col.ID == colID {
+				key.Field = append(key.Field, columnToProtobufField(&col))
+			}
+		}
+	}
+	rowType := `.` + pkg + `.` + protobufRowMessage
+	envelope := &descriptorpb.DescriptorProto{
+		Name: proto.String(protobufEnvelopeMessage),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			protobufField(`after`, protobufAfterField, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, rowType),
+			protobufField(`before`, protobufBeforeField, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, rowType),
+			protobufField(`updated`, protobufUpdatedField, descriptorpb.FieldDescriptorProto_TYPE_STRING, ``),
+		},
+	}
+
+	return &descriptorpb.FileDescriptorProto{
+		Name:        proto.String(fmt.Sprintf(`%s.v%d.proto`, name, tableDesc.GetVersion())),
+		Package:     proto.String(pkg),
+		Syntax:      proto.String(`proto2`),
+		MessageType: []*descriptorpb.DescriptorProto{key, row, envelope},
+	}
+}
+
+// protobufResolvedDescriptor returns the descriptor of the message which
+// resolved timestamps are encoded as.
+func protobufResolvedDescriptor() (protoreflect.MessageDescriptor, error) {
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String(`resolved.proto`),
+		Package: proto.String(protobufPackage),
+		Syntax:  proto.String(`proto2`),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String(protobufResolvedMessage),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				protobufField(`resolved`, protobufResolvedField, descriptorpb.FieldDescriptorProto_TYPE_STRING, ``),
+			},
+		}},
+	}, nil /* resolver */)
+	if err != nil {
+		return nil, err
+	}
+	return file.Messages().ByName(protobufResolvedMessage), nil
+}
+
+func columnToProtobufField(col *descpb.ColumnDescriptor) *descriptorpb.FieldDescriptorProto {
+	var typ descriptorpb.FieldDescriptorProto_Type
+	switch col.Type.Family() {
+	case types.BoolFamily:
+		typ = descriptorpb.FieldDescriptorProto_TYPE_BOOL
+	case types.IntFamily:
+		typ = descriptorpb.FieldDescriptorProto_TYPE_INT64
+	case types.FloatFamily:
+		typ = descriptorpb.FieldDescriptorProto_TYPE_DOUBLE
+	case types.BytesFamily:
+		typ = descriptorpb.FieldDescriptorProto_TYPE_BYTES
+	default:
+		typ = descriptorpb.FieldDescriptorProto_TYPE_STRING
+	}
+	return protobufField(SQLNameToAvroName(col.Name), int32(col.ID), typ, ``)
+}
+
+func protobufField(
+	name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string,
+) *descriptorpb.FieldDescriptorProto {
+	f := &descriptorpb.FieldDescriptorProto{
+		Name:   proto.String(name),
+		Number: proto.Int32(number),
+		Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:   typ.Enum(),
+	}
+	if typeName != `` {
+		f.TypeName = proto.String(typeName)
+	}
+	return f
+}
+
+// datumToProtobufValue converts a non-NULL datum to the value of a field of
+// the given kind, as generated by columnToProtobufField.
+func datumToProtobufValue(kind protoreflect.Kind, d tree.Datum) protoreflect.Value {
+	switch kind {
+	case protoreflect.BoolKind:
+		return protoreflect.ValueOfBool(bool(*d.(*tree.DBool)))
+	case protoreflect.Int64Kind:
+		return protoreflect.ValueOfInt64(int64(*d.(*tree.DInt)))
+	case protoreflect.DoubleKind:
+		return protoreflect.ValueOfFloat64(float64(*d.(*tree.DFloat)))
+	case protoreflect.BytesKind:
+		return protoreflect.ValueOfBytes([]byte(*d.(*tree.DBytes)))
+	default:
+		return protoreflect.ValueOfString(tree.AsStringWithFlags(d, tree.FmtExport))
+	}
+}
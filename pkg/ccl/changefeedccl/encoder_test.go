@@ -21,6 +21,8 @@ import (
 	"github.com/cockroachdb/cockroach-go/crdb"
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/cdctest"
 	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
@@ -34,6 +36,11 @@ import (
 	"github.com/cockroachdb/errors"
 	"github.com/linkedin/goavro/v2"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
 )
 
 func TestEncoders(t *testing.T) {
@@ -121,46 +128,46 @@ func TestEncoders(t *testing.T) {
 			delete:   `[1]->{"after": null, "before": {"a": 1, "b": "bar"}, "updated": "1.0000000002"}`,
 			resolved: `{"resolved":"1.0000000002"}`,
 		},
-		`format=experimental_avro,envelope=key_only`: {
+		`format=avro,envelope=key_only`: {
 			insert:   `{"a":{"long":1}}->`,
 			delete:   `{"a":{"long":1}}->`,
 			resolved: `{"resolved":{"string":"1.0000000002"}}`,
 		},
-		`format=experimental_avro,envelope=key_only,updated`: {
+		`format=avro,envelope=key_only,updated`: {
 			err: `updated is only usable with envelope=wrapped`,
 		},
-		`format=experimental_avro,envelope=key_only,diff`: {
+		`format=avro,envelope=key_only,diff`: {
 			err: `diff is only usable with envelope=wrapped`,
 		},
-		`format=experimental_avro,envelope=key_only,updated,diff`: {
+		`format=avro,envelope=key_only,updated,diff`: {
 			err: `updated is only usable with envelope=wrapped`,
 		},
-		`format=experimental_avro,envelope=row`: {
-			err: `envelope=row is not supported with format=experimental_avro`,
+		`format=avro,envelope=row`: {
+			err: `envelope=row is not supported with format=avro`,
 		},
-		`format=experimental_avro,envelope=row,updated`: {
-			err: `envelope=row is not supported with format=experimental_avro`,
+		`format=avro,envelope=row,updated`: {
+			err: `envelope=row is not supported with format=avro`,
 		},
-		`format=experimental_avro,envelope=row,diff`: {
-			err: `envelope=row is not supported with format=experimental_avro`,
+		`format=avro,envelope=row,diff`: {
+			err: `envelope=row is not supported with format=avro`,
 		},
-		`format=experimental_avro,envelope=row,updated,diff`: {
-			err: `envelope=row is not supported with format=experimental_avro`,
+		`format=avro,envelope=row,updated,diff`: {
+			err: `envelope=row is not supported with format=avro`,
 		},
-		`format=experimental_avro,envelope=wrapped`: {
+		`format=avro,envelope=wrapped`: {
 			insert: `{"a":{"long":1}}->` +
 				`{"after":{"foo":{"a":{"long":1},"b":{"string":"bar"}}}}`,
 			delete:   `{"a":{"long":1}}->{"after":null}`,
 			resolved: `{"resolved":{"string":"1.0000000002"}}`,
 		},
-		`format=experimental_avro,envelope=wrapped,updated`: {
+		`format=avro,envelope=wrapped,updated`: {
 			insert: `{"a":{"long":1}}->` +
 				`{"after":{"foo":{"a":{"long":1},"b":{"string":"bar"}}},` +
 				`"updated":{"string":"1.0000000002"}}`,
 			delete:   `{"a":{"long":1}}->{"after":null,"updated":{"string":"1.0000000002"}}`,
 			resolved: `{"resolved":{"string":"1.0000000002"}}`,
 		},
-		`format=experimental_avro,envelope=wrapped,diff`: {
+		`format=avro,envelope=wrapped,diff`: {
 			insert: `{"a":{"long":1}}->` +
 				`{"after":{"foo":{"a":{"long":1},"b":{"string":"bar"}}},` +
 				`"before":null}`,
@@ -169,7 +176,7 @@ func TestEncoders(t *testing.T) {
 				`"before":{"foo_before":{"a":{"long":1},"b":{"string":"bar"}}}}`,
 			resolved: `{"resolved":{"string":"1.0000000002"}}`,
 		},
-		`format=experimental_avro,envelope=wrapped,updated,diff`: {
+		`format=avro,envelope=wrapped,updated,diff`: {
 			insert: `{"a":{"long":1}}->` +
 				`{"after":{"foo":{"a":{"long":1},"b":{"string":"bar"}}},` +
 				`"before":null,` +
@@ -257,6 +264,296 @@ func TestEncoders(t *testing.T) {
 	}
 }
 
+func TestJSONEncoderTopicInValue(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	tableDesc, err := parseTableDesc(`CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+	require.NoError(t, err)
+	row := encodeRow{
+		datums: rowenc.EncDatumRow{
+			rowenc.EncDatum{Datum: tree.NewDInt(1)},
+			rowenc.EncDatum{Datum: tree.NewDString(`bar`)},
+		},
+		tableDesc: tableDesc,
+	}
+
+	e, err := makeJSONEncoder(map[string]string{
+		changefeedbase.OptEnvelope:     string(changefeedbase.OptEnvelopeWrapped),
+		changefeedbase.OptKeyInValue:   ``,
+		changefeedbase.OptTopicInValue: ``,
+	})
+	require.NoError(t, err)
+	value, err := e.EncodeValue(context.Background(), row)
+	require.NoError(t, err)
+	require.Equal(t, `{"after": {"a": 1, "b": "bar"}, "key": [1], "topic": "foo"}`, string(value))
+
+	_, err = makeJSONEncoder(map[string]string{
+		changefeedbase.OptEnvelope:     string(changefeedbase.OptEnvelopeRow),
+		changefeedbase.OptTopicInValue: ``,
+	})
+	require.EqualError(t, err, `topic_in_value is only usable with envelope=wrapped`)
+
+	_, err = getEncoder(map[string]string{
+		changefeedbase.OptFormat:       string(changefeedbase.OptFormatAvro),
+		changefeedbase.OptEnvelope:     string(changefeedbase.OptEnvelopeWrapped),
+		changefeedbase.OptTopicInValue: ``,
+	})
+	require.EqualError(t, err, `topic_in_value is not supported with format=avro`)
+}
+
+func TestCSVEncoder(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	tableDesc, err := parseTableDesc(
+		`CREATE TABLE foo (a INT, b STRING, c DECIMAL, d STRING, PRIMARY KEY (b, a))`)
+	require.NoError(t, err)
+	row := encodeRow{
+		datums: rowenc.EncDatumRow{
+			rowenc.EncDatum{Datum: tree.NewDInt(1)},
+			rowenc.EncDatum{Datum: tree.NewDString(`bar, "baz"`)},
+			rowenc.EncDatum{Datum: tree.DNull},
+			rowenc.EncDatum{Datum: tree.NewDString(`qux`)},
+		},
+		tableDesc: tableDesc,
+	}
+
+	e, err := getEncoder(map[string]string{
+		changefeedbase.OptFormat:     string(changefeedbase.OptFormatCSV),
+		changefeedbase.OptEnvelope:   string(changefeedbase.OptEnvelopeWrapped),
+		changefeedbase.OptKeyInValue: ``,
+	})
+	require.NoError(t, err)
+	key, err := e.EncodeKey(ctx, row)
+	require.NoError(t, err)
+	require.Equal(t, `"bar, ""baz""",1`, string(key))
+	value, err := e.EncodeValue(ctx, row)
+	require.NoError(t, err)
+	require.Equal(t, `upsert,1,"bar, ""baz""",,qux`, string(value))
+	resolved, err := e.EncodeResolvedTimestamp(ctx, `foo`, hlc.Timestamp{WallTime: 1, Logical: 2})
+	require.NoError(t, err)
+	require.Equal(t, `{"resolved":"1.0000000002"}`, string(resolved))
+
+	// Deleted rows only hold their primary key columns, their other columns
+	// are empty fields.
+	row.deleted = true
+	row.datums[2], row.datums[3] = rowenc.EncDatum{}, rowenc.EncDatum{}
+	value, err = e.EncodeValue(ctx, row)
+	require.NoError(t, err)
+	require.Equal(t, `delete,1,"bar, ""baz""",,`, string(value))
+
+	for opt, expectedErr := range map[string]string{
+		changefeedbase.OptDiff:              `diff is not supported with format=csv`,
+		changefeedbase.OptUpdatedTimestamps: `updated is not supported with format=csv`,
+		changefeedbase.OptTopicInValue:      `topic_in_value is not supported with format=csv`,
+	} {
+		_, err := getEncoder(map[string]string{
+			changefeedbase.OptFormat:   string(changefeedbase.OptFormatCSV),
+			changefeedbase.OptEnvelope: string(changefeedbase.OptEnvelopeWrapped),
+			opt:                        ``,
+		})
+		require.EqualError(t, err, expectedErr)
+	}
+	_, err = getEncoder(map[string]string{
+		changefeedbase.OptFormat:   string(changefeedbase.OptFormatCSV),
+		changefeedbase.OptEnvelope: string(changefeedbase.OptEnvelopeKeyOnly),
+	})
+	require.EqualError(t, err, `envelope=key_only is not supported with format=csv`)
+}
+
+func TestCSVEncoderCloudStorage(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1, 'a'), (2, NULL)`)
+
+		foo := feed(t, f, `CREATE CHANGEFEED FOR foo WITH format=$1, resolved`,
+			changefeedbase.OptFormatCSV)
+		defer closeFeed(t, foo)
+		assertPayloads(t, foo, []string{
+			`foo: ->upsert,1,a`,
+			`foo: ->upsert,2,`,
+		})
+
+		sqlDB.Exec(t, `UPSERT INTO foo VALUES (2, 'b, c')`)
+		assertPayloads(t, foo, []string{
+			`foo: ->upsert,2,"b, c"`,
+		})
+
+		sqlDB.Exec(t, `DELETE FROM foo WHERE a = 1`)
+		assertPayloads(t, foo, []string{
+			`foo: ->delete,1,`,
+		})
+		expectResolvedTimestamp(t, foo)
+	}
+
+	t.Run(`cloudstorage`, cloudStorageTest(testFn))
+}
+
+// protobufToJSON decodes an encoded protobuf message of the given type and
+// returns it as JSON.
+func protobufToJSON(t testing.TB, md protoreflect.MessageDescriptor, b []byte) string {
+	t.Helper()
+	m := dynamicpb.NewMessage(md)
+	require.NoError(t, proto.Unmarshal(b, m))
+	j, err := protojson.Marshal(m)
+	require.NoError(t, err)
+	// protojson randomizes its whitespace, so reformat it.
+	var native interface{}
+	require.NoError(t, gojson.Unmarshal(j, &native))
+	reformatted, err := cdctest.ReformatJSON(native)
+	require.NoError(t, err)
+	return string(reformatted)
+}
+
+func TestProtobufEncoder(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	tableDesc, err := parseTableDesc(
+		`CREATE TABLE foo (a INT PRIMARY KEY, b STRING, c FLOAT, d BYTES, e BOOL, f DECIMAL)`)
+	require.NoError(t, err)
+	row := rowenc.EncDatumRow{
+		rowenc.EncDatum{Datum: tree.NewDInt(1)},
+		rowenc.EncDatum{Datum: tree.NewDString(`bar`)},
+		rowenc.EncDatum{Datum: tree.NewDFloat(1.5)},
+		rowenc.EncDatum{Datum: tree.NewDBytes(`baz`)},
+		rowenc.EncDatum{Datum: tree.DBoolTrue},
+		rowenc.EncDatum{Datum: tree.DNull},
+	}
+	ts := hlc.Timestamp{WallTime: 1, Logical: 2}
+
+	file, err := protodesc.NewFile(tableToProtobufDescriptor(tableDesc), nil /* resolver */)
+	require.NoError(t, err)
+	require.Equal(t, protoreflect.FullName(`cockroach.changefeed.foo`), file.Package())
+	keyDesc := file.Messages().ByName(protobufKeyMessage)
+	rowDesc := file.Messages().ByName(protobufRowMessage)
+	envelopeDesc := file.Messages().ByName(protobufEnvelopeMessage)
+	for i, col := range tableDesc.GetPublicColumns() {
+		field := rowDesc.Fields().Get(i)
+		require.Equal(t, protoreflect.Name(col.Name), field.Name())
+		require.Equal(t, protoreflect.FieldNumber(col.ID), field.Number())
+	}
+
+	e, err := getEncoder(map[string]string{
+		changefeedbase.OptFormat:            string(changefeedbase.OptFormatProtobuf),
+		changefeedbase.OptEnvelope:          string(changefeedbase.OptEnvelopeWrapped),
+		changefeedbase.OptDiff:              ``,
+		changefeedbase.OptUpdatedTimestamps: ``,
+	})
+	require.NoError(t, err)
+
+	rowInsert := encodeRow{datums: row, updated: ts, tableDesc: tableDesc, prevTableDesc: tableDesc}
+	key, err := e.EncodeKey(ctx, rowInsert)
+	require.NoError(t, err)
+	require.Equal(t, `{"a": "1"}`, protobufToJSON(t, keyDesc, key))
+	value, err := e.EncodeValue(ctx, rowInsert)
+	require.NoError(t, err)
+	require.Equal(t,
+		`{"after": {"a": "1", "b": "bar", "c": 1.5, "d": "YmF6", "e": true}, "updated": "1.0000000002"}`,
+		protobufToJSON(t, envelopeDesc, value))
+
+	rowDelete := encodeRow{
+		datums: row, deleted: true, prevDatums: row, updated: ts, tableDesc: tableDesc, prevTableDesc: tableDesc,
+	}
+	value, err = e.EncodeValue(ctx, rowDelete)
+	require.NoError(t, err)
+	require.Equal(t,
+		`{"before": {"a": "1", "b": "bar", "c": 1.5, "d": "YmF6", "e": true}, "updated": "1.0000000002"}`,
+		protobufToJSON(t, envelopeDesc, value))
+
+	resolved, err := e.EncodeResolvedTimestamp(ctx, `foo`, ts)
+	require.NoError(t, err)
+	resolvedDesc, err := protobufResolvedDescriptor()
+	require.NoError(t, err)
+	require.Equal(t, `{"resolved": "1.0000000002"}`, protobufToJSON(t, resolvedDesc, resolved))
+
+	e, err = getEncoder(map[string]string{
+		changefeedbase.OptFormat:   string(changefeedbase.OptFormatProtobuf),
+		changefeedbase.OptEnvelope: string(changefeedbase.OptEnvelopeRow),
+	})
+	require.NoError(t, err)
+	value, err = e.EncodeValue(ctx, rowInsert)
+	require.NoError(t, err)
+	require.Equal(t, `{"a": "1", "b": "bar", "c": 1.5, "d": "YmF6", "e": true}`,
+		protobufToJSON(t, rowDesc, value))
+	value, err = e.EncodeValue(ctx, rowDelete)
+	require.NoError(t, err)
+	require.Nil(t, value)
+
+	for opts, expectedErr := range map[[2]string]string{
+		{string(changefeedbase.OptEnvelopeRow), changefeedbase.OptDiff}:              `diff is only usable with envelope=wrapped`,
+		{string(changefeedbase.OptEnvelopeRow), changefeedbase.OptUpdatedTimestamps}: `updated is only usable with envelope=wrapped`,
+		{string(changefeedbase.OptEnvelopeWrapped), changefeedbase.OptKeyInValue}:    `key_in_value is not supported with format=protobuf`,
+		{string(changefeedbase.OptEnvelopeWrapped), changefeedbase.OptTopicInValue}:  `topic_in_value is not supported with format=protobuf`,
+	} {
+		_, err := getEncoder(map[string]string{
+			changefeedbase.OptFormat:   string(changefeedbase.OptFormatProtobuf),
+			changefeedbase.OptEnvelope: opts[0],
+			opts[1]:                    ``,
+		})
+		require.EqualError(t, err, expectedErr)
+	}
+}
+
+func TestProtobufEncoderSchemaChange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING, c INT)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1, 'a', 10)`)
+
+		foo := feed(t, f, `CREATE CHANGEFEED FOR foo WITH format=$1, diff`,
+			changefeedbase.OptFormatProtobuf)
+		defer closeFeed(t, foo)
+
+		nextValue := func() []byte {
+			t.Helper()
+			for {
+				m, err := foo.Next()
+				require.NoError(t, err)
+				if len(m.Value) > 0 {
+					return m.Value
+				}
+			}
+		}
+
+		// Decode every value with the descriptor of the first version of the
+		// table: the columns added later are unknown fields, and the dropped ones
+		// are missing.
+		tableDesc := catalogkv.TestingGetTableDescriptor(
+			f.Server().DB(), keys.SystemSQLCodec, `d`, `foo`)
+		envelopeDesc := func() protoreflect.MessageDescriptor {
+			file, err := protodesc.NewFile(tableToProtobufDescriptor(tableDesc), nil /* resolver */)
+			require.NoError(t, err)
+			return file.Messages().ByName(protobufEnvelopeMessage)
+		}()
+
+		require.Equal(t, `{"after": {"a": "1", "b": "a", "c": "10"}}`,
+			protobufToJSON(t, envelopeDesc, nextValue()))
+
+		sqlDB.Exec(t, `ALTER TABLE foo ADD COLUMN d STRING DEFAULT 'x'`)
+		sqlDB.Exec(t, `ALTER TABLE foo DROP COLUMN c`)
+		sqlDB.Exec(t, `UPSERT INTO foo VALUES (1, 'b', 'y')`)
+		// Skip the backfills of the schema changes. The before value of the last
+		// update was encoded with the version of the table which has no c.
+		const expected = `{"after": {"a": "1", "b": "b"}, "before": {"a": "1", "b": "a"}}`
+		for protobufToJSON(t, envelopeDesc, nextValue()) != expected {
+		}
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+}
+
 type testSchemaRegistry struct {
 	server *httptest.Server
 	mu     struct {
@@ -419,6 +716,45 @@ func TestAvroMigrateToUnsupportedColumn(t *testing.T) {
 	t.Run(`enterprise`, enterpriseTest(testFn))
 }
 
+func TestAvroTypes(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		reg := makeTestSchemaRegistry()
+		defer reg.Close()
+
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TYPE status AS ENUM ('open', 'closed')`)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b status, c INT[], d UUID, e DECIMAL)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES
+			(1, 'open', ARRAY[1, NULL], '27f4f4c9-e35a-45dd-9b79-5ff0f9b5fbb0', 1.50),
+			(2, NULL, ARRAY[], NULL, NULL)`)
+
+		foo := feed(t, f, `CREATE CHANGEFEED FOR foo `+
+			`WITH format=$1, confluent_schema_registry=$2`,
+			changefeedbase.OptFormatAvro, reg.server.URL)
+		defer closeFeed(t, foo)
+		assertPayloadsAvro(t, reg, foo, []string{
+			`foo: {"a":{"long":1}}->{"after":{"foo":{"a":{"long":1},"b":{"foo.b":"open"},` +
+				`"c":{"array":[{"long":1},null]},"d":{"string":"27f4f4c9-e35a-45dd-9b79-5ff0f9b5fbb0"},` +
+				`"e":{"string":"1.50"}}}}`,
+			`foo: {"a":{"long":2}}->{"after":{"foo":{"a":{"long":2},"b":null,"c":{"array":[]},"d":null,"e":null}}}`,
+		})
+
+		// Adding a value to the ENUM changes the avro schema of the table
+		// without bumping the version of its descriptor.
+		sqlDB.Exec(t, `ALTER TYPE status ADD VALUE 'pending'`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (3, 'pending', NULL, NULL, NULL)`)
+		assertPayloadsAvro(t, reg, foo, []string{
+			`foo: {"a":{"long":3}}->{"after":{"foo":{"a":{"long":3},"b":{"foo.b":"pending"},"c":null,"d":null,"e":null}}}`,
+		})
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+}
+
 func TestAvroLedger(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -51,6 +51,31 @@ func waitForSchemaChange(
 	})
 }
 
+// expectFinished waits for a changefeed which is expected to complete on its
+// own to do so: enterprise changefeeds succeed and sinkless changefeeds end
+// their result stream.
+func expectFinished(t testing.TB, sqlDB *sqlutils.SQLRunner, f cdctest.TestFeed) {
+	t.Helper()
+	e, ok := f.(*cdctest.TableFeed)
+	if !ok {
+		m, err := f.Next()
+		if err != nil {
+			t.Fatal(err)
+		} else if m != nil {
+			t.Fatalf(`unexpected message %s: %s -> %s`, m.Topic, m.Key, m.Value)
+		}
+		return
+	}
+	testutils.SucceedsSoon(t, func() error {
+		var status string
+		sqlDB.QueryRow(t, `SELECT status FROM [SHOW JOBS] WHERE job_id = $1`, e.JobID).Scan(&status)
+		if status != "succeeded" {
+			return fmt.Errorf("Job %d had status %s, wanted 'succeeded'", e.JobID, status)
+		}
+		return nil
+	})
+}
+
 func readNextMessages(t testing.TB, f cdctest.TestFeed, numMessages int, stripTs bool) []string {
 	t.Helper()
 
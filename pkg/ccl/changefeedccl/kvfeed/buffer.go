@@ -11,15 +11,11 @@ package kvfeed
 import (
 	"context"
 	"time"
+	"unsafe"
 
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
-	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
-	"github.com/cockroachdb/cockroach/pkg/sql/rowcontainer"
-	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
-	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
-	"github.com/cockroachdb/cockroach/pkg/sql/types"
-	"github.com/cockroachdb/cockroach/pkg/util/envutil"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
@@ -195,57 +191,62 @@ func (b *chanBuffer) Get(ctx context.Context) (Event, error) {
 	}
 }
 
-// MemBufferDefaultCapacity is the default capacity for a memBuffer for a single
-// changefeed.
-//
-// TODO(dan): It would be better if all changefeeds shared a single capacity
-// that was given by the operater at startup, like we do for RocksDB and SQL.
-var MemBufferDefaultCapacity = envutil.EnvOrDefaultBytes(
-	"COCKROACH_CHANGEFEED_BUFFER_CAPACITY", 1<<30) // 1GB
-
-var memBufferColTypes = []*types.T{
-	types.Bytes, // KV.Key
-	types.Bytes, // KV.Value
-	types.Bytes, // KV.PrevValue
-	types.Bytes, // span.Key
-	types.Bytes, // span.EndKey
-	types.Int,   // ts.WallTime
-	types.Int,   // ts.Logical
-	types.Int,   // ts.Flags
+// eventMemOverhead is the memory used by an Event regardless of the size of
+// the keys and values it holds.
+var eventMemOverhead = int64(unsafe.Sizeof(Event{}))
+
+// memSize returns the approximate amount of memory used by the event.
+func (b *Event) memSize() int64 {
+	size := eventMemOverhead + int64(len(b.kv.Key)+len(b.kv.Value.RawBytes)+len(b.prevVal.RawBytes))
+	if b.resolved != nil {
+		size += int64(unsafe.Sizeof(*b.resolved)) + int64(len(b.resolved.Span.Key)+len(b.resolved.Span.EndKey))
+	}
+	return size
 }
 
+// pushbackInitialBackoff and pushbackMaxBackoff bound the delay between
+// attempts to add an entry to a memBuffer whose memory budget is exhausted.
+const (
+	pushbackInitialBackoff = 10 * time.Millisecond
+	pushbackMaxBackoff     = time.Second
+)
+
 // memBuffer is an in-memory buffer for changed KV and Resolved timestamp
 // events. It's size is limited only by the BoundAccount passed to the
-// constructor. memBuffer is only for use with single-producer single-consumer.
+// constructor: once the account cannot grow any further, writers block until
+// the reader, or the other users of the account's pool, make room, which
+// pushes back on the rangefeeds feeding the buffer. memBuffer is only for use
+// with single-producer single-consumer.
 type memBuffer struct {
 	metrics *Metrics
 
 	mu struct {
 		syncutil.Mutex
-		entries rowcontainer.RowContainer
+		entries []Event
+		acc     mon.BoundAccount
 	}
 	// signalCh can be selected on to learn when an entry is written to
 	// mu.entries.
 	signalCh chan struct{}
-
-	allocMu struct {
-		syncutil.Mutex
-		a rowenc.DatumAlloc
-	}
+	// consumedCh can be selected on to learn when an entry is removed from
+	// mu.entries.
+	consumedCh chan struct{}
 }
 
 func makeMemBuffer(acc mon.BoundAccount, metrics *Metrics) *memBuffer {
 	b := &memBuffer{
-		metrics:  metrics,
-		signalCh: make(chan struct{}, 1),
+		metrics:    metrics,
+		signalCh:   make(chan struct{}, 1),
+		consumedCh: make(chan struct{}, 1),
 	}
-	b.mu.entries.Init(acc, colinfo.ColTypeInfoFromColTypes(memBufferColTypes), 0 /* rowCapacity */)
+	b.mu.acc = acc
 	return b
 }
 
 func (b *memBuffer) Close(ctx context.Context) {
 	b.mu.Lock()
-	b.mu.entries.Close(ctx)
+	b.mu.entries = nil
+	b.mu.acc.Close(ctx)
 	b.mu.Unlock()
 }
 
@@ -254,113 +255,102 @@ func (b *memBuffer) Close(ctx context.Context) {
 func (b *memBuffer) AddKV(
 	ctx context.Context, kv roachpb.KeyValue, prevVal roachpb.Value, backfillTimestamp hlc.Timestamp,
 ) error {
-	b.allocMu.Lock()
-	prevValDatum := tree.DNull
-	if prevVal.IsPresent() {
-		prevValDatum = b.allocMu.a.NewDBytes(tree.DBytes(prevVal.RawBytes))
-	}
-	row := tree.Datums{
-		b.allocMu.a.NewDBytes(tree.DBytes(kv.Key)),
-		b.allocMu.a.NewDBytes(tree.DBytes(kv.Value.RawBytes)),
-		prevValDatum,
-		tree.DNull,
-		tree.DNull,
-		b.allocMu.a.NewDInt(tree.DInt(kv.Value.Timestamp.WallTime)),
-		b.allocMu.a.NewDInt(tree.DInt(kv.Value.Timestamp.Logical)),
-		b.allocMu.a.NewDInt(tree.DInt(kv.Value.Timestamp.Flags)),
-	}
-	b.allocMu.Unlock()
-	return b.addRow(ctx, row)
+	return b.addEvent(ctx, Event{
+		kv:                kv,
+		prevVal:           prevVal,
+		backfillTimestamp: backfillTimestamp,
+	})
 }
 
 // AddResolved inserts a Resolved timestamp notification in the buffer.
 func (b *memBuffer) AddResolved(
 	ctx context.Context, span roachpb.Span, ts hlc.Timestamp, boundaryReached bool,
 ) error {
-	b.allocMu.Lock()
-	row := tree.Datums{
-		tree.DNull,
-		tree.DNull,
-		tree.DNull,
-		b.allocMu.a.NewDBytes(tree.DBytes(span.Key)),
-		b.allocMu.a.NewDBytes(tree.DBytes(span.EndKey)),
-		b.allocMu.a.NewDInt(tree.DInt(ts.WallTime)),
-		b.allocMu.a.NewDInt(tree.DInt(ts.Logical)),
-		b.allocMu.a.NewDInt(tree.DInt(ts.Flags)),
-	}
-	b.allocMu.Unlock()
-	return b.addRow(ctx, row)
+	return b.addEvent(ctx, Event{resolved: &jobspb.ResolvedSpan{Span: span, Timestamp: ts, BoundaryReached: boundaryReached}})
 }
 
 // Get returns an entry from the buffer. They are handed out in an order that
 // (if it is maintained all the way to the sink) meets our external guarantees.
 func (b *memBuffer) Get(ctx context.Context) (Event, error) {
-	row, err := b.getRow(ctx)
-	if err != nil {
-		return Event{}, err
-	}
-	e := Event{bufferGetTimestamp: timeutil.Now()}
-	ts := hlc.Timestamp{
-		WallTime: int64(*row[5].(*tree.DInt)),
-		Logical:  int32(*row[6].(*tree.DInt)),
-		Flags:    uint32(*row[7].(*tree.DInt)),
-	}
-	if row[2] != tree.DNull {
-		e.prevVal = roachpb.Value{
-			RawBytes: []byte(*row[2].(*tree.DBytes)),
+	for {
+		var e Event
+		var ok bool
+		b.mu.Lock()
+		if len(b.mu.entries) > 0 {
+			e, ok = b.mu.entries[0], true
+			b.mu.entries[0] = Event{}
+			b.mu.entries = b.mu.entries[1:]
+			b.mu.acc.Shrink(ctx, e.memSize())
 		}
-	}
-	if row[0] != tree.DNull {
-		e.kv = roachpb.KeyValue{
-			Key: []byte(*row[0].(*tree.DBytes)),
-			Value: roachpb.Value{
-				RawBytes:  []byte(*row[1].(*tree.DBytes)),
-				Timestamp: ts,
-			},
+		b.mu.Unlock()
+		if ok {
+			b.metrics.BufferEntriesOut.Inc(1)
+			select {
+			case b.consumedCh <- struct{}{}:
+			default:
+				// Already signaled, don't need to signal again.
+			}
+			e.bufferGetTimestamp = timeutil.Now()
+			return e, nil
 		}
-		return e, nil
-	}
-	e.resolved = &jobspb.ResolvedSpan{
-		Span: roachpb.Span{
-			Key:    []byte(*row[3].(*tree.DBytes)),
-			EndKey: []byte(*row[4].(*tree.DBytes)),
-		},
-		Timestamp: ts,
-	}
-	return e, nil
-}
 
-func (b *memBuffer) addRow(ctx context.Context, row tree.Datums) error {
-	b.mu.Lock()
-	_, err := b.mu.entries.AddRow(ctx, row)
-	b.mu.Unlock()
-	b.metrics.BufferEntriesIn.Inc(1)
-	select {
-	case b.signalCh <- struct{}{}:
-	default:
-		// Already signaled, don't need to signal again.
+		select {
+		case <-ctx.Done():
+			return Event{}, ctx.Err()
+		case <-b.signalCh:
+		}
 	}
-	return err
 }
 
-func (b *memBuffer) getRow(ctx context.Context) (tree.Datums, error) {
+func (b *memBuffer) addEvent(ctx context.Context, e Event) error {
+	size := e.memSize()
+	var pushbackStart time.Time
+	var timer timeutil.Timer
+	defer timer.Stop()
+	backoff := pushbackInitialBackoff
 	for {
-		var row tree.Datums
 		b.mu.Lock()
-		if b.mu.entries.Len() > 0 {
-			row = b.mu.entries.At(0)
-			b.mu.entries.PopFirst(ctx)
+		err := b.mu.acc.Grow(ctx, size)
+		if err == nil {
+			b.mu.entries = append(b.mu.entries, e)
 		}
+		tooLarge := err != nil && len(b.mu.entries) == 0 &&
+			b.mu.acc.Monitor().AllocationExceedsLimit(size)
 		b.mu.Unlock()
-		if row != nil {
-			b.metrics.BufferEntriesOut.Inc(1)
-			return row, nil
+		if err == nil {
+			break
 		}
-
+		// If the memory budget is exhausted, wait for room. The budget is
+		// shared with the other changefeeds on the node, which do not signal
+		// this buffer when they release memory, so we also retry with a
+		// backoff rather than only waiting for the reader. There is nothing to
+		// wait for if the entry exceeds the limit of the buffer on its own.
+		if tooLarge || !sqlerrors.IsOutOfMemoryError(err) {
+			return err
+		}
+		if pushbackStart.IsZero() {
+			pushbackStart = timeutil.Now()
+		}
+		timer.Reset(backoff)
 		select {
 		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-b.signalCh:
+			return ctx.Err()
+		case <-b.consumedCh:
+		case <-timer.C:
+			timer.Read = true
+			if backoff *= 2; backoff > pushbackMaxBackoff {
+				backoff = pushbackMaxBackoff
+			}
 		}
 	}
+	if !pushbackStart.IsZero() {
+		b.metrics.BufferPushbackNanos.Inc(timeutil.Since(pushbackStart).Nanoseconds())
+	}
+	b.metrics.BufferEntriesIn.Inc(1)
+	select {
+	case b.signalCh <- struct{}{}:
+	default:
+		// Already signaled, don't need to signal again.
+	}
+	return nil
 }
@@ -0,0 +1,131 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package kvfeed
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemBufferPushback(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	settings := cluster.MakeTestingClusterSettings()
+	makeBuffer := func(budget int64) (*memBuffer, *mon.BytesMonitor, *Metrics) {
+		mm := mon.NewMonitor(
+			"test", mon.MemoryResource,
+			nil /* curCount */, nil /* maxHist */, 1 /* increment */, math.MaxInt64, settings,
+		)
+		mm.Start(ctx, nil /* pool */, mon.MakeStandaloneBudget(budget))
+		metrics := MakeMetrics(time.Minute)
+		return makeMemBuffer(mm.MakeBoundAccount(), &metrics), mm, &metrics
+	}
+	value := strings.Repeat("a", 1<<10)
+	kv := func(i int) roachpb.KeyValue {
+		key := encoding.EncodeUvarintAscending(keys.SystemSQLCodec.TablePrefix(42), uint64(i))
+		return roachpb.KeyValue{
+			Key:   key,
+			Value: roachpb.Value{RawBytes: []byte(value), Timestamp: hlc.Timestamp{WallTime: int64(i)}},
+		}
+	}
+
+	t.Run("writes wait for room", func(t *testing.T) {
+		const budget = 64 << 10
+		const numEntries = 1000
+		buf, mm, metrics := makeBuffer(budget)
+		defer mm.Stop(ctx)
+		defer buf.Close(ctx)
+
+		g := ctxgroup.WithContext(ctx)
+		g.GoCtx(func(ctx context.Context) error {
+			for i := 0; i < numEntries; i++ {
+				if err := buf.AddKV(ctx, kv(i), roachpb.Value{}, hlc.Timestamp{}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		// Only start reading once the buffer holds a good part of its budget,
+		// which is a fraction of what is written.
+		testutils.SucceedsSoon(t, func() error {
+			if mm.AllocBytes() < budget/2 {
+				return errors.New("waiting for the buffer to fill")
+			}
+			return nil
+		})
+		for i := 0; i < numEntries; i++ {
+			e, err := buf.Get(ctx)
+			require.NoError(t, err)
+			require.Equal(t, kv(i).Key, e.KV().Key)
+		}
+		require.NoError(t, g.Wait())
+		require.Equal(t, int64(numEntries), metrics.BufferEntriesIn.Count())
+	})
+
+	t.Run("writes wait for room in a shared budget", func(t *testing.T) {
+		const budget = 16 << 10
+		pool := mon.NewMonitor(
+			"pool", mon.MemoryResource,
+			nil /* curCount */, nil /* maxHist */, 1 /* increment */, math.MaxInt64, settings,
+		)
+		pool.Start(ctx, nil /* pool */, mon.MakeStandaloneBudget(budget))
+		defer pool.Stop(ctx)
+		mm := mon.NewMonitorInheritWithLimit("test", budget, pool)
+		mm.Start(ctx, pool, mon.BoundAccount{})
+		defer mm.Stop(ctx)
+		metrics := MakeMetrics(time.Minute)
+		buf := makeMemBuffer(mm.MakeBoundAccount(), &metrics)
+		defer buf.Close(ctx)
+
+		// Another user of the pool holds all of the budget, so the write has to
+		// wait even though the buffer is empty.
+		other := pool.MakeBoundAccount()
+		require.NoError(t, other.Grow(ctx, budget))
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- buf.AddKV(ctx, kv(0), roachpb.Value{}, hlc.Timestamp{})
+		}()
+		select {
+		case err := <-errCh:
+			t.Fatalf("expected the write to wait, got %v", err)
+		case <-time.After(100 * time.Millisecond):
+		}
+		other.Close(ctx)
+		require.NoError(t, <-errCh)
+		e, err := buf.Get(ctx)
+		require.NoError(t, err)
+		require.Equal(t, kv(0).Key, e.KV().Key)
+	})
+
+	t.Run("entry larger than the budget", func(t *testing.T) {
+		buf, mm, _ := makeBuffer(1 << 9)
+		defer mm.Stop(ctx)
+		defer buf.Close(ctx)
+
+		err := buf.AddKV(ctx, kv(0), roachpb.Value{}, hlc.Timestamp{})
+		require.True(t, sqlerrors.IsOutOfMemoryError(err), "unexpected error: %v", err)
+	})
+}
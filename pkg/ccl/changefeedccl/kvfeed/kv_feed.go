@@ -57,9 +57,19 @@ type Config struct {
 	// been seen.
 	NeedsInitialScan bool
 
+	// If true, the feed stops after the initial scan (if any) instead of
+	// running rangefeeds: all of the spans are resolved up to InitialHighWater
+	// as a boundary once the scan is done.
+	InitialScanOnly bool
+
 	// InitialHighWater is the timestamp from which new events are guaranteed to
 	// be produced.
 	InitialHighWater hlc.Timestamp
+
+	// EndTime, if set, is the timestamp at which the feed stops: events at or
+	// after it are not emitted and all of the spans are resolved up to just
+	// before it as a boundary.
+	EndTime hlc.Timestamp
 }
 
 // Run will run the kvfeed. The feed runs synchronously and returns an
@@ -94,8 +104,8 @@ func Run(ctx context.Context, cfg Config) error {
 	f := newKVFeed(
 		cfg.Sink, cfg.Spans,
 		cfg.SchemaChangeEvents, cfg.SchemaChangePolicy,
-		cfg.NeedsInitialScan, cfg.WithDiff,
-		cfg.InitialHighWater,
+		cfg.NeedsInitialScan, cfg.InitialScanOnly, cfg.WithDiff,
+		cfg.InitialHighWater, cfg.EndTime,
 		cfg.Codec,
 		sf, sc, pff, bf)
 	g.GoCtx(f.run)
@@ -108,10 +118,27 @@ func Run(ctx context.Context, cfg Config) error {
 		log.Infof(ctx, "stopping changefeed due to schema change at %v", scErr.ts)
 		<-ctx.Done()
 		err = nil
+	} else if errors.Is(err, errInitialScanCompleted) {
+		log.Infof(ctx, "stopping changefeed after its initial scan")
+		<-ctx.Done()
+		err = nil
+	} else if errors.Is(err, errEndTimeReached) {
+		log.Infof(ctx, "stopping changefeed at its end time %v", cfg.EndTime)
+		<-ctx.Done()
+		err = nil
 	}
 	return err
 }
 
+// errInitialScanCompleted is a sentinel error to indicate to Run() that the
+// feed is stopping because it only performs an initial scan. Like
+// schemaChangeDetectedError, it is handled entirely in this package.
+var errInitialScanCompleted = errors.New("initial scan completed")
+
+// errEndTimeReached is a sentinel error to indicate to Run() that the feed is
+// stopping because it has resolved all of its spans up to its end time.
+var errEndTimeReached = errors.New("end time reached")
+
 // schemaChangeDetectedError is a sentinel error to indicate to Run() that the
 // schema change is stopping due to a schema change. This is handy to trigger
 // the context group to stop; the error is handled entirely in this package.
@@ -132,7 +159,9 @@ type kvFeed struct {
 	spans               []roachpb.Span
 	withDiff            bool
 	withInitialBackfill bool
+	initialScanOnly     bool
 	initialHighWater    hlc.Timestamp
+	endTime             hlc.Timestamp
 	sink                EventBufferWriter
 	codec               keys.SQLCodec
 
@@ -151,8 +180,8 @@ func newKVFeed(
 	spans []roachpb.Span,
 	schemaChangeEvents changefeedbase.SchemaChangeEventClass,
 	schemaChangePolicy changefeedbase.SchemaChangePolicy,
-	withInitialBackfill, withDiff bool,
-	initialHighWater hlc.Timestamp,
+	withInitialBackfill, initialScanOnly, withDiff bool,
+	initialHighWater, endTime hlc.Timestamp,
 	codec keys.SQLCodec,
 	tf schemaFeed,
 	sc kvScanner,
@@ -163,8 +192,10 @@ func newKVFeed(
 		sink:                sink,
 		spans:               spans,
 		withInitialBackfill: withInitialBackfill,
+		initialScanOnly:     initialScanOnly,
 		withDiff:            withDiff,
 		initialHighWater:    initialHighWater,
+		endTime:             endTime,
 		schemaChangeEvents:  schemaChangeEvents,
 		schemaChangePolicy:  schemaChangePolicy,
 		codec:               codec,
@@ -179,14 +210,29 @@ func (f *kvFeed) run(ctx context.Context) (err error) {
 	// highWater represents the point in time at or before which we know
 	// we've seen all events or is the initial starting time of the feed.
 	highWater := f.initialHighWater
+	if f.initialScanOnly {
+		return f.runInitialScanOnly(ctx, highWater)
+	}
 	for i := 0; ; i++ {
 		initialScan := i == 0
 		if err = f.scanIfShould(ctx, initialScan, highWater); err != nil {
 			return err
 		}
-		highWater, err = f.runUntilTableEvent(ctx, highWater)
-		if err != nil {
-			return err
+		if !f.endTimeReached(highWater) {
+			highWater, err = f.runUntilTableEvent(ctx, highWater)
+			if err != nil {
+				return err
+			}
+		}
+		if f.endTimeReached(highWater) {
+			// Resolve all of the spans as a boundary regardless of the policy so
+			// that the higher layers know that the feed is done.
+			for _, span := range f.spans {
+				if err := f.sink.AddResolved(ctx, span, highWater, true); err != nil {
+					return err
+				}
+			}
+			return errEndTimeReached
 		}
 
 		// Resolve all of the spans as a boundary if the policy indicates that
@@ -205,6 +251,29 @@ func (f *kvFeed) run(ctx context.Context) (err error) {
 	}
 }
 
+// runInitialScanOnly performs the initial scan of the feed, if it still needs
+// one, and then resolves all of the spans up to the high-water as a boundary,
+// which marks the end of the feed to the higher layers.
+func (f *kvFeed) runInitialScanOnly(ctx context.Context, highWater hlc.Timestamp) error {
+	if f.withInitialBackfill {
+		if err := f.scanIfShould(ctx, true /* initialScan */, highWater); err != nil {
+			return err
+		}
+	}
+	for _, span := range f.spans {
+		if err := f.sink.AddResolved(ctx, span, highWater, true); err != nil {
+			return err
+		}
+	}
+	return errInitialScanCompleted
+}
+
+// endTimeReached returns true if the feed has an end time and everything
+// before it has been seen as of the given high-water.
+func (f *kvFeed) endTimeReached(highWater hlc.Timestamp) bool {
+	return !f.endTime.IsEmpty() && f.endTime.Prev().LessEq(highWater)
+}
+
 func (f *kvFeed) scanIfShould(
 	ctx context.Context, initialScan bool, highWater hlc.Timestamp,
 ) error {
@@ -280,7 +349,7 @@ func (f *kvFeed) runUntilTableEvent(
 	g := ctxgroup.WithContext(ctx)
 	physicalCfg := physicalConfig{Spans: f.spans, Timestamp: startFrom, WithDiff: f.withDiff}
 	g.GoCtx(func(ctx context.Context) error {
-		return copyFromSourceToSinkUntilTableEvent(ctx, f.sink, memBuf, physicalCfg, f.tableFeed)
+		return copyFromSourceToSinkUntilTableEvent(ctx, f.sink, memBuf, physicalCfg, f.tableFeed, f.endTime)
 	})
 	g.GoCtx(func(ctx context.Context) error {
 		return f.physicalFeed.Run(ctx, memBuf, physicalCfg)
@@ -299,6 +368,8 @@ func (f *kvFeed) runUntilTableEvent(
 		// We'll need to do this to ensure that a resolved timestamp propagates
 		// when we're trying to exit.
 		return tErr.Timestamp().Prev(), nil
+	} else if eErr := (*errEndTimeBoundaryReached)(nil); errors.As(err, &eErr) {
+		return eErr.Timestamp().Prev(), nil
 	} else {
 		return hlc.Timestamp{}, err
 	}
@@ -312,17 +383,43 @@ func (e *errBoundaryReached) Error() string {
 	return "scan boundary reached: " + e.String()
 }
 
+// errEndTimeBoundaryReached is returned by copyFromSourceToSinkUntilTableEvent
+// once all of the spans have been resolved up to the end time of the feed.
+type errEndTimeBoundaryReached struct {
+	endTime hlc.Timestamp
+}
+
+// Timestamp returns the end time, which like the timestamp of a table event
+// is the first timestamp which is not emitted.
+func (e *errEndTimeBoundaryReached) Timestamp() hlc.Timestamp {
+	return e.endTime
+}
+
+func (e *errEndTimeBoundaryReached) Error() string {
+	return "end time boundary reached: " + e.endTime.String()
+}
+
+// scanBoundaryError is implemented by the errors with which
+// copyFromSourceToSinkUntilTableEvent stops.
+type scanBoundaryError interface {
+	error
+	Timestamp() hlc.Timestamp
+}
+
 // copyFromSourceToSinkUntilTableEvents will pull read entries from source and
 // publish them to sink if there is no table event from the schemaFeed. If a
 // tableEvent occurs then the function will return once all of the spans have
 // been resolved up to the event. The first such event will be returned as
-// *errBoundaryReached. A nil error will never be returned.
+// *errBoundaryReached. If endTime is set and no table event precedes it, the
+// function instead returns *errEndTimeBoundaryReached once all of the spans
+// have been resolved up to the end time. A nil error will never be returned.
 func copyFromSourceToSinkUntilTableEvent(
 	ctx context.Context,
 	sink EventBufferWriter,
 	source EventBufferReader,
 	cfg physicalConfig,
 	tables schemaFeed,
+	endTime hlc.Timestamp,
 ) error {
 	// Maintain a local spanfrontier to tell when all the component rangefeeds
 	// being watched have reached the Scan boundary.
@@ -330,17 +427,23 @@ func copyFromSourceToSinkUntilTableEvent(
 	for _, span := range cfg.Spans {
 		frontier.Forward(span, cfg.Timestamp)
 	}
+	var scanBoundary scanBoundaryError
+	if !endTime.IsEmpty() {
+		scanBoundary = &errEndTimeBoundaryReached{endTime: endTime}
+	}
 	var (
-		scanBoundary         *errBoundaryReached
 		checkForScanBoundary = func(ts hlc.Timestamp) error {
-			if scanBoundary != nil {
+			// A table event boundary is final; an end time boundary still gives
+			// way to any table event which precedes it.
+			if _, ok := scanBoundary.(*errBoundaryReached); ok {
 				return nil
 			}
 			nextEvents, err := tables.Peek(ctx, ts)
 			if err != nil {
 				return err
 			}
-			if len(nextEvents) > 0 {
+			if len(nextEvents) > 0 && (scanBoundary == nil ||
+				nextEvents[0].Timestamp().Less(scanBoundary.Timestamp())) {
 				scanBoundary = &errBoundaryReached{nextEvents[0]}
 			}
 			return nil
@@ -76,10 +76,12 @@ func TestKVFeed(t *testing.T) {
 	type testCase struct {
 		name               string
 		needsInitialScan   bool
+		initialScanOnly    bool
 		withDiff           bool
 		schemaChangeEvents changefeedbase.SchemaChangeEventClass
 		schemaChangePolicy changefeedbase.SchemaChangePolicy
 		initialHighWater   hlc.Timestamp
+		endTime            hlc.Timestamp
 		spans              []roachpb.Span
 		events             []roachpb.RangeFeedEvent
 
@@ -113,8 +115,8 @@ func TestKVFeed(t *testing.T) {
 		tf := newRawTableFeed(tc.descs, tc.initialHighWater)
 		f := newKVFeed(buf, tc.spans,
 			tc.schemaChangeEvents, tc.schemaChangePolicy,
-			tc.needsInitialScan, tc.withDiff,
-			tc.initialHighWater,
+			tc.needsInitialScan, tc.initialScanOnly, tc.withDiff,
+			tc.initialHighWater, tc.endTime,
 			keys.SystemSQLCodec,
 			&tf, sf, rangefeedFactory(ref.run), bufferFactory)
 		ctx, cancel := context.WithCancel(context.Background())
@@ -139,7 +141,8 @@ func TestKVFeed(t *testing.T) {
 			return nil
 		})
 		// Wait for the feed to fail rather than canceling it.
-		if tc.schemaChangePolicy == changefeedbase.OptSchemaChangePolicyStop {
+		if tc.schemaChangePolicy == changefeedbase.OptSchemaChangePolicyStop ||
+			tc.initialScanOnly || !tc.endTime.IsEmpty() {
 			testG.Go(func() error {
 				_ = g.Wait()
 				return nil
@@ -249,6 +252,89 @@ func TestKVFeed(t *testing.T) {
 			expEvents: 2,
 			expErrRE:  "schema change ...",
 		},
+		{
+			name:               "initial scan only",
+			schemaChangeEvents: changefeedbase.OptSchemaChangeEventClassDefault,
+			schemaChangePolicy: changefeedbase.OptSchemaChangePolicyBackfill,
+			needsInitialScan:   true,
+			initialScanOnly:    true,
+			initialHighWater:   ts(2),
+			spans: []roachpb.Span{
+				tableSpan(42),
+			},
+			events: []roachpb.RangeFeedEvent{
+				kvEvent(42, "a", "b", ts(3)),
+			},
+			expScans: []hlc.Timestamp{
+				ts(2),
+			},
+			descs: []*tabledesc.Immutable{
+				makeTableDesc(42, 1, ts(1), 2),
+			},
+			expEvents: 1,
+			expErrRE:  "initial scan completed",
+		},
+		{
+			name:               "initial scan only - already scanned",
+			schemaChangeEvents: changefeedbase.OptSchemaChangeEventClassDefault,
+			schemaChangePolicy: changefeedbase.OptSchemaChangePolicyBackfill,
+			initialScanOnly:    true,
+			initialHighWater:   ts(2),
+			spans: []roachpb.Span{
+				tableSpan(42),
+			},
+			events: []roachpb.RangeFeedEvent{
+				kvEvent(42, "a", "b", ts(3)),
+			},
+			descs: []*tabledesc.Immutable{
+				makeTableDesc(42, 1, ts(1), 2),
+			},
+			expEvents: 1,
+			expErrRE:  "initial scan completed",
+		},
+		{
+			name:               "end time",
+			schemaChangeEvents: changefeedbase.OptSchemaChangeEventClassDefault,
+			schemaChangePolicy: changefeedbase.OptSchemaChangePolicyBackfill,
+			needsInitialScan:   true,
+			initialHighWater:   ts(2),
+			endTime:            ts(5),
+			spans: []roachpb.Span{
+				tableSpan(42),
+			},
+			events: []roachpb.RangeFeedEvent{
+				kvEvent(42, "a", "b", ts(3)),
+				checkpointEvent(tableSpan(42), ts(4)),
+				kvEvent(42, "a", "b", ts(5)), // filtered as it is at the end time
+				checkpointEvent(tableSpan(42), ts(6)),
+			},
+			expScans: []hlc.Timestamp{
+				ts(2),
+			},
+			descs: []*tabledesc.Immutable{
+				makeTableDesc(42, 1, ts(1), 2),
+			},
+			expEvents: 3,
+			expErrRE:  "end time reached",
+		},
+		{
+			name:               "end time - already reached",
+			schemaChangeEvents: changefeedbase.OptSchemaChangeEventClassDefault,
+			schemaChangePolicy: changefeedbase.OptSchemaChangePolicyBackfill,
+			initialHighWater:   ts(5),
+			endTime:            ts(5),
+			spans: []roachpb.Span{
+				tableSpan(42),
+			},
+			events: []roachpb.RangeFeedEvent{
+				kvEvent(42, "a", "b", ts(6)),
+			},
+			descs: []*tabledesc.Immutable{
+				makeTableDesc(42, 1, ts(1), 2),
+			},
+			expEvents: 1,
+			expErrRE:  "end time reached",
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			runTest(t, tc)
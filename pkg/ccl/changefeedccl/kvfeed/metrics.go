@@ -29,6 +29,12 @@ var (
 		Measurement: "Entries",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaChangefeedBufferPushbackNanos = metric.Metadata{
+		Name:        "changefeed.buffer_pushback_nanos",
+		Help:        "Total time spent waiting while the buffer between raft and changefeed sinks was full",
+		Measurement: "Nanoseconds",
+		Unit:        metric.Unit_NANOSECONDS,
+	}
 	metaChangefeedPollRequestNanos = metric.Metadata{
 		Name:        "changefeed.poll_request_nanos",
 		Help:        "Time spent fetching changes",
@@ -44,14 +50,16 @@ var (
 type Metrics struct {
 	BufferEntriesIn      *metric.Counter
 	BufferEntriesOut     *metric.Counter
+	BufferPushbackNanos  *metric.Counter
 	PollRequestNanosHist *metric.Histogram
 }
 
 // MakeMetrics constructs a Metrics struct with the provided histogram window.
 func MakeMetrics(histogramWindow time.Duration) Metrics {
 	return Metrics{
-		BufferEntriesIn:  metric.NewCounter(metaChangefeedBufferEntriesIn),
-		BufferEntriesOut: metric.NewCounter(metaChangefeedBufferEntriesOut),
+		BufferEntriesIn:     metric.NewCounter(metaChangefeedBufferEntriesIn),
+		BufferEntriesOut:    metric.NewCounter(metaChangefeedBufferEntriesOut),
+		BufferPushbackNanos: metric.NewCounter(metaChangefeedBufferPushbackNanos),
 		// Metrics for changefeed performance debugging: - PollRequestNanos and
 		// PollRequestNanosHist, things are first
 		//   fetched with some limited concurrency. We're interested in both the
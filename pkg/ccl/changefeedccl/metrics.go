@@ -132,6 +132,12 @@ var (
 		Measurement: "Nanoseconds",
 		Unit:        metric.Unit_NANOSECONDS,
 	}
+	metaChangefeedMemCurBytes = metric.Metadata{
+		Name:        "changefeed.mem.current",
+		Help:        "Current memory used to buffer changes by all changefeeds",
+		Measurement: "Memory",
+		Unit:        metric.Unit_BYTES,
+	}
 	metaChangefeedRunning = metric.Metadata{
 		Name:        "changefeed.running",
 		Help:        "Number of currently running changefeeds, including sinkless",
@@ -165,7 +171,8 @@ type Metrics struct {
 	EmitNanos          *metric.Counter
 	FlushNanos         *metric.Counter
 
-	Running *metric.Gauge
+	Running     *metric.Gauge
+	MemCurBytes *metric.Gauge
 
 	mu struct {
 		syncutil.Mutex
@@ -193,6 +200,7 @@ func MakeMetrics(histogramWindow time.Duration) metric.Struct {
 		EmitNanos:          metric.NewCounter(metaChangefeedEmitNanos),
 		FlushNanos:         metric.NewCounter(metaChangefeedFlushNanos),
 		Running:            metric.NewGauge(metaChangefeedRunning),
+		MemCurBytes:        metric.NewGauge(metaChangefeedMemCurBytes),
 	}
 	m.mu.resolved = make(map[int]hlc.Timestamp)
 	m.mu.id = 1 // start the first id at 1 so we can detect initialization
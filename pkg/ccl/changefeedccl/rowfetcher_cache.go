@@ -11,6 +11,8 @@ package changefeedccl
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -34,10 +36,15 @@ import (
 // and returns a Fetcher initialized with that table. This Fetcher's
 // StartScanFrom can be used to turn that key (or all the keys making up the
 // column families of one row) into a row.
+//
+// A table is either watched in its entirety or by one of its column families
+// by a changefeed, so the Fetchers are cached by table version.
 type rowFetcherCache struct {
 	codec    keys.SQLCodec
 	leaseMgr *lease.Manager
 	fetchers map[idVersion]*row.Fetcher
+	families map[idVersion]familyProjection
+	keyDescs map[idVersion]*tabledesc.Immutable
 
 	collection *descs.Collection
 	db         *kv.DB
@@ -64,6 +71,8 @@ func newRowFetcherCache(
 		collection: descs.NewCollection(settings, leaseMgr, hydratedTables),
 		db:         db,
 		fetchers:   make(map[idVersion]*row.Fetcher),
+		families:   make(map[idVersion]familyProjection),
+		keyDescs:   make(map[idVersion]*tabledesc.Immutable),
 	}
 }
 
@@ -139,8 +148,11 @@ func (c *rowFetcherCache) TableDescForKey(
 	return tableDesc, nil
 }
 
+// RowFetcherForTableDesc returns a Fetcher for the given version of a table.
+// If family is non-nil, the Fetcher only decodes the primary key columns and
+// the columns of that column family.
 func (c *rowFetcherCache) RowFetcherForTableDesc(
-	tableDesc *tabledesc.Immutable,
+	tableDesc *tabledesc.Immutable, family *descpb.ColumnFamilyDescriptor,
 ) (*row.Fetcher, error) {
 	idVer := idVersion{id: tableDesc.ID, version: tableDesc.Version}
 	// Ensure that all user defined types are up to date with the cached
@@ -152,12 +164,17 @@ func (c *rowFetcherCache) RowFetcherForTableDesc(
 		tableDesc.UserDefinedTypeColsHaveSameVersion(rf.GetTables()[0].(*tabledesc.Immutable)) {
 		return rf, nil
 	}
-	// TODO(dan): Allow for decoding a subset of the columns.
 	var colIdxMap catalog.TableColMap
 	var valNeededForCol util.FastIntSet
 	for colIdx := range tableDesc.Columns {
 		colIdxMap.Set(tableDesc.Columns[colIdx].ID, colIdx)
-		valNeededForCol.Add(colIdx)
+	}
+	if family == nil {
+		valNeededForCol.AddRange(0, len(tableDesc.Columns)-1)
+	} else {
+		for _, colIdx := range familyColumnIdxs(tableDesc, family) {
+			valNeededForCol.Add(colIdx)
+		}
 	}
 
 	var rf row.Fetcher
@@ -188,3 +205,108 @@ func (c *rowFetcherCache) RowFetcherForTableDesc(
 	c.fetchers[idVer] = &rf
 	return &rf, nil
 }
+
+// decodeFamilyID returns the ID of the column family of the given primary
+// index key.
+func decodeFamilyID(key roachpb.Key) (descpb.FamilyID, error) {
+	prefixLen, err := keys.GetRowPrefixLength(key)
+	if err != nil {
+		return 0, err
+	}
+	_, familyID, err := encoding.DecodeUvarintAscending(key[prefixLen:])
+	if err != nil {
+		return 0, err
+	}
+	return descpb.FamilyID(familyID), nil
+}
+
+// familyProjection restricts the rows of a table to the primary key columns
+// and the columns of one of its column families.
+type familyProjection struct {
+	// tableDesc is a copy of the table descriptor with only the projected
+	// columns, which is used to encode the projected rows.
+	tableDesc *tabledesc.Immutable
+	// colIdxs are the indexes of the projected columns in the table descriptor.
+	colIdxs []int
+}
+
+// ProjectColumnFamily restricts a row decoded by the Fetcher returned by
+// RowFetcherForTableDesc for the given family to the columns of that family.
+// It returns the projected row, and a table descriptor which describes it.
+func (c *rowFetcherCache) ProjectColumnFamily(
+	tableDesc *tabledesc.Immutable, family *descpb.ColumnFamilyDescriptor, datums rowenc.EncDatumRow,
+) (rowenc.EncDatumRow, *tabledesc.Immutable) {
+	idVer := idVersion{id: tableDesc.ID, version: tableDesc.Version}
+	p, ok := c.families[idVer]
+	if !ok || p.tableDesc.Families[0].ID != family.ID ||
+		!tableDesc.UserDefinedTypeColsHaveSameVersion(p.tableDesc) {
+		p.colIdxs = familyColumnIdxs(tableDesc, family)
+		projected := *tableDesc.TableDesc()
+		projected.Columns = make([]descpb.ColumnDescriptor, len(p.colIdxs))
+		for i, colIdx := range p.colIdxs {
+			projected.Columns[i] = tableDesc.Columns[colIdx]
+		}
+		projected.Families = []descpb.ColumnFamilyDescriptor{*family}
+		p.tableDesc = tabledesc.NewImmutable(projected)
+		c.families[idVer] = p
+	}
+	projectedDatums := make(rowenc.EncDatumRow, len(p.colIdxs))
+	for i, colIdx := range p.colIdxs {
+		projectedDatums[i] = datums[colIdx]
+	}
+	return projectedDatums, p.tableDesc
+}
+
+// familyColumnIdxs returns the indexes of the primary key columns and of the
+// columns of the given column family in the table descriptor, in order.
+func familyColumnIdxs(
+	tableDesc *tabledesc.Immutable, family *descpb.ColumnFamilyDescriptor,
+) []int {
+	var colIDs catalog.TableColSet
+	for _, colID := range tableDesc.GetPrimaryIndex().ColumnIDs {
+		colIDs.Add(colID)
+	}
+	for _, colID := range family.ColumnIDs {
+		colIDs.Add(colID)
+	}
+	var colIdxs []int
+	for colIdx := range tableDesc.Columns {
+		if colIDs.Contains(tableDesc.Columns[colIdx].ID) {
+			colIdxs = append(colIdxs, colIdx)
+		}
+	}
+	return colIdxs
+}
+
+// OverrideKeyColumns returns a copy of the given table descriptor, which
+// describes the rows of the table watched by the given target, whose primary
+// index is made of the columns given by the key_column option instead. The
+// encoders build the keys of the rows from the primary index, so the rows
+// described by the copy are keyed by these columns.
+func (c *rowFetcherCache) OverrideKeyColumns(
+	tableDesc catalog.TableDescriptor, target jobspb.ChangefeedTarget, opts map[string]string,
+) (catalog.TableDescriptor, error) {
+	idVer := idVersion{id: tableDesc.GetID(), version: tableDesc.GetVersion()}
+	if keyDesc, ok := c.keyDescs[idVer]; ok && tableDesc.UserDefinedTypeColsHaveSameVersion(keyDesc) {
+		return keyDesc, nil
+	}
+	keyColIDs, err := changefeedbase.KeyColumnIDs(opts, target, tableDesc)
+	if err != nil {
+		return nil, err
+	}
+	keyed := *tableDesc.TableDesc()
+	keyed.PrimaryIndex.ColumnIDs = keyColIDs
+	keyed.PrimaryIndex.ColumnNames = make([]string, len(keyColIDs))
+	keyed.PrimaryIndex.ColumnDirections = make([]descpb.IndexDescriptor_Direction, len(keyColIDs))
+	for i, colID := range keyColIDs {
+		col, err := tableDesc.FindColumnByID(colID)
+		if err != nil {
+			return nil, err
+		}
+		keyed.PrimaryIndex.ColumnNames[i] = col.Name
+		keyed.PrimaryIndex.ColumnDirections[i] = descpb.IndexDescriptor_ASC
+	}
+	keyDesc := tabledesc.NewImmutable(keyed)
+	c.keyDescs[idVer] = keyDesc
+	return keyDesc, nil
+}
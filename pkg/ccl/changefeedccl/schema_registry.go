@@ -0,0 +1,257 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	gojson "encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/util/httputil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	confluentSchemaContentType = `application/vnd.schemaregistry.v1+json`
+
+	// schemaRegistryTimeout bounds each request to the schema registry.
+	schemaRegistryTimeout = 10 * time.Second
+	// schemaRegistryMaxAttempts is the number of times a registration is tried
+	// before the changefeed gives up with a retryable error.
+	schemaRegistryMaxAttempts = 5
+)
+
+// confluentSchemaRegistry is a client of the Confluent schema registry REST
+// API, as used by the confluentAvroEncoder.
+//
+// The registry is addressed with an `http` or `https` URL. Credentials in the
+// URL are sent with HTTP basic authentication, and the base64 encoded PEM
+// certificate in its `ca_cert` query parameter, if any, is trusted on top of
+// the system's root CAs.
+//
+// The IDs of the registered schemas are cached, so that registering a schema
+// which was already registered under a subject doesn't contact the registry.
+// A confluentSchemaRegistry is not threadsafe.
+type confluentSchemaRegistry struct {
+	url                *url.URL
+	username, password string
+	client             *httputil.Client
+	retryOpts          retry.Options
+
+	// ids caches the IDs of the registered schemas by subject and schema.
+	ids map[confluentSubjectSchema]int32
+}
+
+type confluentSubjectSchema struct {
+	subject, schema string
+}
+
+func newConfluentSchemaRegistry(registryURL string) (*confluentSchemaRegistry, error) {
+	u, err := parseSchemaRegistryURL(registryURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, `parsing %s`, changefeedbase.OptConfluentSchemaRegistry)
+	}
+	if u.Scheme != `http` && u.Scheme != `https` {
+		return nil, errors.Errorf(`%s must be an http or https URL: %s`,
+			changefeedbase.OptConfluentSchemaRegistry, registryURL)
+	}
+
+	r := &confluentSchemaRegistry{
+		retryOpts: base.DefaultRetryOptions(),
+		ids:       make(map[confluentSubjectSchema]int32),
+	}
+	r.retryOpts.MaxRetries = schemaRegistryMaxAttempts - 1
+	if u.User != nil {
+		r.username = u.User.Username()
+		r.password, _ = u.User.Password()
+		u.User = nil
+	}
+
+	var tlsConfig *tls.Config
+	q := u.Query()
+	if caCertB64 := q.Get(changefeedbase.SinkParamCACert); caCertB64 != `` {
+		if u.Scheme != `https` {
+			return nil, errors.Errorf(`%s requires an https %s`,
+				changefeedbase.SinkParamCACert, changefeedbase.OptConfluentSchemaRegistry)
+		}
+		caCert, err := base64.StdEncoding.DecodeString(caCertB64)
+		if err != nil {
+			return nil, errors.Errorf(`param %s must be base 64 encoded: %s`,
+				changefeedbase.SinkParamCACert, err)
+		}
+		caCertPool, err := x509.SystemCertPool()
+		if err != nil || caCertPool == nil {
+			caCertPool = x509.NewCertPool()
+		}
+		if !caCertPool.AppendCertsFromPEM(caCert) {
+			return nil, errors.Errorf(`invalid %s: no certificates found`, changefeedbase.SinkParamCACert)
+		}
+		tlsConfig = &tls.Config{RootCAs: caCertPool}
+	}
+	q.Del(changefeedbase.SinkParamCACert)
+	for k := range q {
+		return nil, errors.Errorf(`unknown %s query parameter: %s`,
+			changefeedbase.OptConfluentSchemaRegistry, k)
+	}
+	u.RawQuery = ``
+	r.url = u
+
+	r.client = &httputil.Client{Client: &http.Client{
+		Timeout: schemaRegistryTimeout,
+		Transport: &http.Transport{
+			DialContext:     (&net.Dialer{Timeout: schemaRegistryTimeout}).DialContext,
+			TLSClientConfig: tlsConfig,
+		},
+	}}
+	return r, nil
+}
+
+// parseSchemaRegistryURL parses the given schema registry URL. URLs without a
+// scheme, e.g. `localhost:8081`, have always been accepted and are interpreted
+// as http URLs.
+func parseSchemaRegistryURL(registryURL string) (*url.URL, error) {
+	if !strings.Contains(registryURL, `://`) {
+		registryURL = `http://` + registryURL
+	}
+	return url.Parse(registryURL)
+}
+
+// redactSchemaRegistryURL returns the given schema registry URL with the
+// password of its credentials, if any, redacted.
+func redactSchemaRegistryURL(registryURL string) (string, error) {
+	u, err := parseSchemaRegistryURL(registryURL)
+	if err != nil {
+		return ``, err
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), `redacted`)
+	}
+	return u.String(), nil
+}
+
+// RegisterSchemaForSubject registers the given schema under the given subject
+// and returns its ID.
+//
+// Since network services are often a source of flakes, failed requests are
+// retried a few times before giving up with an error that tears down the
+// changefeed. That error is marked as retryable so that the job itself can
+// attempt to start the changefeed again. Requests rejected by the registry
+// (e.g. because the schema is invalid or the credentials are wrong) are not
+// retried.
+func (r *confluentSchemaRegistry) RegisterSchemaForSubject(
+	ctx context.Context, subject string, schema string,
+) (int32, error) {
+	key := confluentSubjectSchema{subject: subject, schema: schema}
+	if id, ok := r.ids[key]; ok {
+		return id, nil
+	}
+
+	type confluentSchemaVersionRequest struct {
+		Schema string `json:"schema"`
+	}
+	type confluentSchemaVersionResponse struct {
+		ID int32 `json:"id"`
+	}
+
+	u := *r.url
+	u.Path = path.Join(u.EscapedPath(), `subjects`, subject, `versions`)
+	if log.V(1) {
+		log.Infof(ctx, "registering avro schema %s %s", u.String(), schema)
+	}
+	body, err := gojson.Marshal(confluentSchemaVersionRequest{Schema: schema})
+	if err != nil {
+		return 0, err
+	}
+
+	// TODO(dt): If the registry is down or constantly returning errors, we
+	// can't make progress. Continuing to indicate that we're "running" in this
+	// case can be misleading, as we really aren't anymore. Right now the MO in
+	// CDC is try and try again forever, so doing so here is consistent with the
+	// behavior elsewhere, but we should revisit this more broadly as this
+	// pattern can easily mask real, actionable issues in the operator's
+	// environment that which they might be able to resolve if we made them
+	// visible in a failure instead.
+	var id int32
+	var lastErr error
+	for attempt := retry.StartWithCtx(ctx, r.retryOpts); attempt.Next(); {
+		var res confluentSchemaVersionResponse
+		lastErr = r.post(ctx, u.String(), body, &res)
+		if lastErr == nil {
+			id = res.ID
+			break
+		}
+		if errors.HasType(lastErr, (*schemaRegistryRejectedError)(nil)) {
+			return 0, lastErr
+		}
+		log.Warningf(ctx, "%+v", lastErr)
+	}
+	if lastErr != nil {
+		return 0, MarkRetryableError(lastErr)
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.ids[key] = id
+	return id, nil
+}
+
+// schemaRegistryRejectedError is returned when the schema registry rejects a
+// request with a client error, which retrying won't fix.
+type schemaRegistryRejectedError struct {
+	msg string
+}
+
+func (e *schemaRegistryRejectedError) Error() string { return e.msg }
+
+func (r *confluentSchemaRegistry) post(
+	ctx context.Context, url string, body []byte, res interface{},
+) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(`Content-Type`, confluentSchemaContentType)
+	if r.username != `` || r.password != `` {
+		req.SetBasicAuth(r.username, r.password)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "contacting confluent schema registry")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		msg := fmt.Sprintf(`registering schema to %s %s: %s`, url, resp.Status, respBody)
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+			resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+			return &schemaRegistryRejectedError{msg: msg}
+		}
+		return errors.New(msg)
+	}
+	if err := gojson.NewDecoder(resp.Body).Decode(res); err != nil {
+		return errors.Wrap(err, "decoding confluent schema registry reply")
+	}
+	return nil
+}
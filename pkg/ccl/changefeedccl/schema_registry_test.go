@@ -0,0 +1,188 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfluentSchemaRegistry(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+
+	// newRegistry returns a client of the given server with fast retries.
+	newRegistry := func(t *testing.T, registryURL string) *confluentSchemaRegistry {
+		r, err := newConfluentSchemaRegistry(registryURL)
+		require.NoError(t, err)
+		r.retryOpts.InitialBackoff = time.Millisecond
+		r.retryOpts.MaxBackoff = time.Millisecond
+		return r
+	}
+
+	t.Run("register", func(t *testing.T) {
+		pathRE := regexp.MustCompile(`^/base/subjects/[a-z]+-value/versions$`)
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			_, auth := r.Header[`Authorization`]
+			if !pathRE.MatchString(r.URL.Path) || auth ||
+				r.Header.Get(`Content-Type`) != confluentSchemaContentType {
+				http.Error(w, fmt.Sprintf(`unexpected request: %s %v`, r.URL.Path, r.Header), http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"id":7}`)
+		}))
+		defer server.Close()
+
+		r := newRegistry(t, server.URL+`/base`)
+		for i := 0; i < 2; i++ {
+			id, err := r.RegisterSchemaForSubject(ctx, `foo-value`, `"string"`)
+			require.NoError(t, err)
+			require.Equal(t, int32(7), id)
+		}
+		// The ID of the schema was cached.
+		require.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+		_, err := r.RegisterSchemaForSubject(ctx, `bar-value`, `"string"`)
+		require.NoError(t, err)
+		require.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	})
+
+	t.Run("basic auth", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if user, pass, ok := r.BasicAuth(); !ok || user != `user` || pass != `pass` {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"id":1}`)
+		}))
+		defer server.Close()
+
+		u, err := url.Parse(server.URL)
+		require.NoError(t, err)
+		u.User = url.UserPassword(`user`, `pass`)
+		id, err := newRegistry(t, u.String()).RegisterSchemaForSubject(ctx, `foo-value`, `"string"`)
+		require.NoError(t, err)
+		require.Equal(t, int32(1), id)
+
+		u.User = url.UserPassword(`user`, `wrong`)
+		_, err = newRegistry(t, u.String()).RegisterSchemaForSubject(ctx, `foo-value`, `"string"`)
+		require.Regexp(t, `401 Unauthorized`, err)
+
+		redacted, err := redactSchemaRegistryURL(u.String())
+		require.NoError(t, err)
+		require.Equal(t, `http://user:redacted@`+u.Host, redacted)
+	})
+
+	t.Run("tls", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"id":1}`)
+		}))
+		defer server.Close()
+
+		// The certificate of the test server isn't trusted by default.
+		_, err := newRegistry(t, server.URL).RegisterSchemaForSubject(ctx, `foo-value`, `"string"`)
+		require.Regexp(t, `certificate signed by unknown authority`, err)
+
+		caCert := pem.EncodeToMemory(&pem.Block{Type: `CERTIFICATE`, Bytes: server.Certificate().Raw})
+		registryURL := server.URL + `?ca_cert=` + url.QueryEscape(base64.StdEncoding.EncodeToString(caCert))
+		id, err := newRegistry(t, registryURL).RegisterSchemaForSubject(ctx, `foo-value`, `"string"`)
+		require.NoError(t, err)
+		require.Equal(t, int32(1), id)
+	})
+
+	t.Run("retries", func(t *testing.T) {
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch atomic.AddInt32(&requests, 1) {
+			case 1, 2:
+				w.WriteHeader(http.StatusServiceUnavailable)
+			default:
+				fmt.Fprint(w, `{"id":1}`)
+			}
+		}))
+		defer server.Close()
+
+		id, err := newRegistry(t, server.URL).RegisterSchemaForSubject(ctx, `foo-value`, `"string"`)
+		require.NoError(t, err)
+		require.Equal(t, int32(1), id)
+		require.Equal(t, int32(3), atomic.LoadInt32(&requests))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var requests int32
+		var status int32 = http.StatusInternalServerError
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			w.WriteHeader(int(atomic.LoadInt32(&status)))
+		}))
+		defer server.Close()
+
+		// Server errors are retried, then returned as retryable errors.
+		_, err := newRegistry(t, server.URL).RegisterSchemaForSubject(ctx, `foo-value`, `"string"`)
+		require.Regexp(t, `500 Internal Server Error`, err)
+		require.True(t, IsRetryableError(err))
+		require.Equal(t, int32(schemaRegistryMaxAttempts), atomic.LoadInt32(&requests))
+
+		// Rejected requests are not retried.
+		atomic.StoreInt32(&requests, 0)
+		atomic.StoreInt32(&status, http.StatusUnprocessableEntity)
+		_, err = newRegistry(t, server.URL).RegisterSchemaForSubject(ctx, `foo-value`, `"string"`)
+		require.Regexp(t, `422 Unprocessable Entity`, err)
+		require.False(t, IsRetryableError(err))
+		require.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	})
+
+	t.Run("no scheme", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"id":1}`)
+		}))
+		defer server.Close()
+
+		// URLs without a scheme are http URLs.
+		u, err := url.Parse(server.URL)
+		require.NoError(t, err)
+		id, err := newRegistry(t, u.Host).RegisterSchemaForSubject(ctx, `foo-value`, `"string"`)
+		require.NoError(t, err)
+		require.Equal(t, int32(1), id)
+
+		redacted, err := redactSchemaRegistryURL(`user:pass@` + u.Host)
+		require.NoError(t, err)
+		require.Equal(t, `http://user:redacted@`+u.Host, redacted)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for registryURL, expectedErr := range map[string]string{
+			`kafka://bar`:              `confluent_schema_registry must be an http or https URL: kafka://bar`,
+			`http://bar?ca_cert=Zm9v`:  `ca_cert requires an https confluent_schema_registry`,
+			`https://bar?ca_cert=!`:    `param ca_cert must be base 64 encoded: .*`,
+			`https://bar?ca_cert=Zm9v`: `invalid ca_cert: no certificates found`,
+			`https://bar?nope=1`:       `unknown confluent_schema_registry query parameter: nope`,
+		} {
+			_, err := newConfluentSchemaRegistry(registryURL)
+			require.Regexp(t, expectedErr, err, registryURL)
+		}
+	})
+}
@@ -39,7 +39,6 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
 	"github.com/cockroachdb/cockroach/pkg/util/bufalloc"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
-	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
@@ -65,6 +64,45 @@ type Sink interface {
 	Close() error
 }
 
+// sinkFactory validates the query parameters of a sink URI and returns a
+// function that creates the sink. The parameters that the factory understands
+// are removed from q, any remaining ones are reported as unknown. Creating the
+// sink is delayed until after all the parameter verification is done.
+type sinkFactory func(
+	ctx context.Context, u *url.URL, q url.Values, env sinkEnv,
+) (makeSink func() (Sink, error), _ error)
+
+// sinkEnv is the environment in which a sink is created.
+type sinkEnv struct {
+	nodeID                     roachpb.NodeID
+	opts                       map[string]string
+	targets                    jobspb.ChangefeedTargets
+	settings                   *cluster.Settings
+	timestampOracle            timestampLowerBoundOracle
+	makeExternalStorageFromURI cloud.ExternalStorageFromURIFactory
+	user                       security.SQLUsername
+}
+
+// Mapping from sink URI scheme to its registered factory.
+var sinkFactories = make(map[string]sinkFactory)
+
+// registerSinkFactory is used by every sink implementation to register its
+// factory for the given URI schemes.
+func registerSinkFactory(factory sinkFactory, schemes ...string) {
+	for _, scheme := range schemes {
+		if _, ok := sinkFactories[scheme]; ok {
+			panic("sink factory for " + scheme + " has already been registered")
+		}
+		sinkFactories[scheme] = factory
+	}
+}
+
+func init() {
+	registerSinkFactory(bufferSinkFactory, changefeedbase.SinkSchemeBuffer)
+	registerSinkFactory(kafkaSinkFactory, changefeedbase.SinkSchemeKafka)
+	registerSinkFactory(sqlSinkFactory, changefeedbase.SinkSchemeExperimentalSQL)
+}
+
 func getSink(
 	ctx context.Context,
 	sinkURI string,
@@ -80,154 +118,155 @@ func getSink(
 	if err != nil {
 		return nil, err
 	}
+	factory, ok := sinkFactories[u.Scheme]
+	if !ok {
+		return nil, errors.Errorf(`unsupported sink: %s`, u.Scheme)
+	}
+	env := sinkEnv{
+		nodeID:                     nodeID,
+		opts:                       opts,
+		targets:                    targets,
+		settings:                   settings,
+		timestampOracle:            timestampOracle,
+		makeExternalStorageFromURI: makeExternalStorageFromURI,
+		user:                       user,
+	}
 	q := u.Query()
+	makeSink, err := factory(ctx, u, q, env)
+	if err != nil {
+		return nil, err
+	}
 
-	// Use a function here to delay creation of the sink until after we've done
-	// all the parameter verification.
-	var makeSink func() (Sink, error)
-	switch {
-	case u.Scheme == changefeedbase.SinkSchemeBuffer:
-		makeSink = func() (Sink, error) { return &bufferSink{}, nil }
-	case u.Scheme == changefeedbase.SinkSchemeKafka:
-		var cfg kafkaSinkConfig
-		cfg.kafkaTopicPrefix = q.Get(changefeedbase.SinkParamTopicPrefix)
-		q.Del(changefeedbase.SinkParamTopicPrefix)
-		if schemaTopic := q.Get(changefeedbase.SinkParamSchemaTopic); schemaTopic != `` {
-			return nil, errors.Errorf(`%s is not yet supported`, changefeedbase.SinkParamSchemaTopic)
-		}
-		q.Del(changefeedbase.SinkParamSchemaTopic)
-		if tlsBool := q.Get(changefeedbase.SinkParamTLSEnabled); tlsBool != `` {
-			var err error
-			if cfg.tlsEnabled, err = strconv.ParseBool(tlsBool); err != nil {
-				return nil, errors.Errorf(`param %s must be a bool: %s`, changefeedbase.SinkParamTLSEnabled, err)
-			}
+	for k := range q {
+		return nil, errors.Errorf(`unknown sink query parameter: %s`, k)
+	}
+
+	s, err := makeSink()
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func bufferSinkFactory(context.Context, *url.URL, url.Values, sinkEnv) (func() (Sink, error), error) {
+	return func() (Sink, error) { return &bufferSink{}, nil }, nil
+}
+
+func kafkaSinkFactory(
+	_ context.Context, u *url.URL, q url.Values, env sinkEnv,
+) (func() (Sink, error), error) {
+	var err error
+	var cfg kafkaSinkConfig
+	cfg.kafkaTopicPrefix = q.Get(changefeedbase.SinkParamTopicPrefix)
+	q.Del(changefeedbase.SinkParamTopicPrefix)
+	cfg.kafkaTopicName = q.Get(changefeedbase.SinkParamTopicName)
+	q.Del(changefeedbase.SinkParamTopicName)
+	if schemaTopic := q.Get(changefeedbase.SinkParamSchemaTopic); schemaTopic != `` {
+		return nil, errors.Errorf(`%s is not yet supported`, changefeedbase.SinkParamSchemaTopic)
+	}
+	q.Del(changefeedbase.SinkParamSchemaTopic)
+	if tlsBool := q.Get(changefeedbase.SinkParamTLSEnabled); tlsBool != `` {
+		if cfg.tlsEnabled, err = strconv.ParseBool(tlsBool); err != nil {
+			return nil, errors.Errorf(`param %s must be a bool: %s`, changefeedbase.SinkParamTLSEnabled, err)
 		}
-		q.Del(changefeedbase.SinkParamTLSEnabled)
-		if tlsVerifyBool := q.Get(changefeedbase.SinkParamSkipTLSVerify); tlsVerifyBool != `` {
-			var err error
-			if cfg.tlsSkipVerify, err = strconv.ParseBool(tlsVerifyBool); err != nil {
-				return nil, errors.Errorf(`param %s must be a bool: %s`, changefeedbase.SinkParamSkipTLSVerify, err)
-			}
+	}
+	q.Del(changefeedbase.SinkParamTLSEnabled)
+	if tlsVerifyBool := q.Get(changefeedbase.SinkParamSkipTLSVerify); tlsVerifyBool != `` {
+		if cfg.tlsSkipVerify, err = strconv.ParseBool(tlsVerifyBool); err != nil {
+			return nil, errors.Errorf(`param %s must be a bool: %s`, changefeedbase.SinkParamSkipTLSVerify, err)
 		}
-		q.Del(changefeedbase.SinkParamSkipTLSVerify)
-		if caCertHex := q.Get(changefeedbase.SinkParamCACert); caCertHex != `` {
-			// TODO(dan): There's a straightforward and unambiguous transformation
-			// between the base 64 encoding defined in RFC 4648 and the URL variant
-			// defined in the same RFC: simply replace all `+` with `-` and `/` with
-			// `_`. Consider always doing this for the user and accepting either
-			// variant.
-			if cfg.caCert, err = base64.StdEncoding.DecodeString(caCertHex); err != nil {
-				return nil, errors.Errorf(`param %s must be base 64 encoded: %s`, changefeedbase.SinkParamCACert, err)
-			}
+	}
+	q.Del(changefeedbase.SinkParamSkipTLSVerify)
+	if caCertHex := q.Get(changefeedbase.SinkParamCACert); caCertHex != `` {
+		// TODO(dan): There's a straightforward and unambiguous transformation
+		// between the base 64 encoding defined in RFC 4648 and the URL variant
+		// defined in the same RFC: simply replace all `+` with `-` and `/` with
+		// `_`. Consider always doing this for the user and accepting either
+		// variant.
+		if cfg.caCert, err = base64.StdEncoding.DecodeString(caCertHex); err != nil {
+			return nil, errors.Errorf(`param %s must be base 64 encoded: %s`, changefeedbase.SinkParamCACert, err)
 		}
-		q.Del(changefeedbase.SinkParamCACert)
-		if clientCertHex := q.Get(changefeedbase.SinkParamClientCert); clientCertHex != `` {
-			if cfg.clientCert, err = base64.StdEncoding.DecodeString(clientCertHex); err != nil {
-				return nil, errors.Errorf(`param %s must be base 64 encoded: %s`, changefeedbase.SinkParamClientCert, err)
-			}
+	}
+	q.Del(changefeedbase.SinkParamCACert)
+	if clientCertHex := q.Get(changefeedbase.SinkParamClientCert); clientCertHex != `` {
+		if cfg.clientCert, err = base64.StdEncoding.DecodeString(clientCertHex); err != nil {
+			return nil, errors.Errorf(`param %s must be base 64 encoded: %s`, changefeedbase.SinkParamClientCert, err)
 		}
-		q.Del(changefeedbase.SinkParamClientCert)
-		if clientKeyHex := q.Get(changefeedbase.SinkParamClientKey); clientKeyHex != `` {
-			if cfg.clientKey, err = base64.StdEncoding.DecodeString(clientKeyHex); err != nil {
-				return nil, errors.Errorf(`param %s must be base 64 encoded: %s`, changefeedbase.SinkParamClientKey, err)
-			}
+	}
+	q.Del(changefeedbase.SinkParamClientCert)
+	if clientKeyHex := q.Get(changefeedbase.SinkParamClientKey); clientKeyHex != `` {
+		if cfg.clientKey, err = base64.StdEncoding.DecodeString(clientKeyHex); err != nil {
+			return nil, errors.Errorf(`param %s must be base 64 encoded: %s`, changefeedbase.SinkParamClientKey, err)
 		}
-		q.Del(changefeedbase.SinkParamClientKey)
-
-		saslParam := q.Get(changefeedbase.SinkParamSASLEnabled)
-		q.Del(changefeedbase.SinkParamSASLEnabled)
-		if saslParam != `` {
-			b, err := strconv.ParseBool(saslParam)
-			if err != nil {
-				return nil, errors.Wrapf(err, `param %s must be a bool:`, changefeedbase.SinkParamSASLEnabled)
-			}
-			cfg.saslEnabled = b
+	}
+	q.Del(changefeedbase.SinkParamClientKey)
+
+	saslParam := q.Get(changefeedbase.SinkParamSASLEnabled)
+	q.Del(changefeedbase.SinkParamSASLEnabled)
+	if saslParam != `` {
+		b, err := strconv.ParseBool(saslParam)
+		if err != nil {
+			return nil, errors.Wrapf(err, `param %s must be a bool:`, changefeedbase.SinkParamSASLEnabled)
 		}
-		handshakeParam := q.Get(changefeedbase.SinkParamSASLHandshake)
-		q.Del(changefeedbase.SinkParamSASLHandshake)
-		if handshakeParam == `` {
-			cfg.saslHandshake = true
-		} else {
-			if !cfg.saslEnabled {
-				return nil, errors.Errorf(`%s must be enabled to configure SASL handshake behavior`, changefeedbase.SinkParamSASLEnabled)
-			}
-			b, err := strconv.ParseBool(handshakeParam)
-			if err != nil {
-				return nil, errors.Wrapf(err, `param %s must be a bool:`, changefeedbase.SinkParamSASLHandshake)
-			}
-			cfg.saslHandshake = b
+		cfg.saslEnabled = b
+	}
+	handshakeParam := q.Get(changefeedbase.SinkParamSASLHandshake)
+	q.Del(changefeedbase.SinkParamSASLHandshake)
+	if handshakeParam == `` {
+		cfg.saslHandshake = true
+	} else {
+		if !cfg.saslEnabled {
+			return nil, errors.Errorf(`%s must be enabled to configure SASL handshake behavior`, changefeedbase.SinkParamSASLEnabled)
 		}
-		cfg.saslUser = q.Get(changefeedbase.SinkParamSASLUser)
-		q.Del(changefeedbase.SinkParamSASLUser)
-		cfg.saslPassword = q.Get(changefeedbase.SinkParamSASLPassword)
-		q.Del(changefeedbase.SinkParamSASLPassword)
-		if cfg.saslEnabled {
-			if cfg.saslUser == `` {
-				return nil, errors.Errorf(`%s must be provided when SASL is enabled`, changefeedbase.SinkParamSASLUser)
-			}
-			if cfg.saslPassword == `` {
-				return nil, errors.Errorf(`%s must be provided when SASL is enabled`, changefeedbase.SinkParamSASLPassword)
-			}
-		} else {
-			if cfg.saslUser != `` {
-				return nil, errors.Errorf(`%s must be enabled if a SASL user is provided`, changefeedbase.SinkParamSASLEnabled)
-			}
-			if cfg.saslPassword != `` {
-				return nil, errors.Errorf(`%s must be enabled if a SASL password is provided`, changefeedbase.SinkParamSASLEnabled)
-			}
+		b, err := strconv.ParseBool(handshakeParam)
+		if err != nil {
+			return nil, errors.Wrapf(err, `param %s must be a bool:`, changefeedbase.SinkParamSASLHandshake)
 		}
-
-		makeSink = func() (Sink, error) {
-			return makeKafkaSink(cfg, u.Host, targets)
+		cfg.saslHandshake = b
+	}
+	cfg.saslUser = q.Get(changefeedbase.SinkParamSASLUser)
+	q.Del(changefeedbase.SinkParamSASLUser)
+	cfg.saslPassword = q.Get(changefeedbase.SinkParamSASLPassword)
+	q.Del(changefeedbase.SinkParamSASLPassword)
+	if cfg.saslEnabled {
+		if cfg.saslUser == `` {
+			return nil, errors.Errorf(`%s must be provided when SASL is enabled`, changefeedbase.SinkParamSASLUser)
 		}
-	case isCloudStorageSink(u):
-		fileSizeParam := q.Get(changefeedbase.SinkParamFileSize)
-		q.Del(changefeedbase.SinkParamFileSize)
-		var fileSize int64 = 16 << 20 // 16MB
-		if fileSizeParam != `` {
-			if fileSize, err = humanizeutil.ParseBytes(fileSizeParam); err != nil {
-				return nil, pgerror.Wrapf(err, pgcode.Syntax, `parsing %s`, fileSizeParam)
-			}
+		if cfg.saslPassword == `` {
+			return nil, errors.Errorf(`%s must be provided when SASL is enabled`, changefeedbase.SinkParamSASLPassword)
 		}
-		u.Scheme = strings.TrimPrefix(u.Scheme, `experimental-`)
-		// Transfer "ownership" of validating all remaining query parameters to
-		// ExternalStorage.
-		u.RawQuery = q.Encode()
-		q = url.Values{}
-		makeSink = func() (Sink, error) {
-			return makeCloudStorageSink(
-				ctx, u.String(), nodeID, fileSize, settings,
-				opts, timestampOracle, makeExternalStorageFromURI, user,
-			)
+	} else {
+		if cfg.saslUser != `` {
+			return nil, errors.Errorf(`%s must be enabled if a SASL user is provided`, changefeedbase.SinkParamSASLEnabled)
 		}
-	case u.Scheme == changefeedbase.SinkSchemeExperimentalSQL:
-		// Swap the changefeed prefix for the sql connection one that sqlSink
-		// expects.
-		u.Scheme = `postgres`
-		// TODO(dan): Make tableName configurable or based on the job ID or
-		// something.
-		tableName := `sqlsink`
-		makeSink = func() (Sink, error) {
-			return makeSQLSink(u.String(), tableName, targets)
+		if cfg.saslPassword != `` {
+			return nil, errors.Errorf(`%s must be enabled if a SASL password is provided`, changefeedbase.SinkParamSASLEnabled)
 		}
-		// Remove parameters we know about for the unknown parameter check.
-		q.Del(`sslcert`)
-		q.Del(`sslkey`)
-		q.Del(`sslmode`)
-		q.Del(`sslrootcert`)
-	default:
-		return nil, errors.Errorf(`unsupported sink: %s`, u.Scheme)
 	}
 
-	for k := range q {
-		return nil, errors.Errorf(`unknown sink query parameter: %s`, k)
-	}
+	return func() (Sink, error) {
+		return makeKafkaSink(cfg, u.Host, env.targets)
+	}, nil
+}
 
-	s, err := makeSink()
-	if err != nil {
-		return nil, err
-	}
-	return s, nil
+func sqlSinkFactory(
+	_ context.Context, u *url.URL, q url.Values, env sinkEnv,
+) (func() (Sink, error), error) {
+	// Remove parameters we know about for the unknown parameter check.
+	q.Del(`sslcert`)
+	q.Del(`sslkey`)
+	q.Del(`sslmode`)
+	q.Del(`sslrootcert`)
+	// Swap the changefeed prefix for the sql connection one that sqlSink
+	// expects.
+	dest := *u
+	dest.Scheme = `postgres`
+	// TODO(dan): Make tableName configurable or based on the job ID or
+	// something.
+	tableName := `sqlsink`
+	return func() (Sink, error) {
+		return makeSQLSink(dest.String(), tableName, env.targets)
+	}, nil
 }
 
 // errorWrapperSink delegates to another sink and marks all returned errors as
@@ -294,8 +333,20 @@ func init() {
 	sarama.Logger = &kafkaLogAdapter{ctx: ctx}
 }
 
+// topicForTable returns the name of the Kafka (or Pub/Sub) topic that the rows
+// of the given table are emitted to: the table name, escaped to be a valid
+// topic name, or the `topic_name` sink parameter, if set, which sends the rows
+// of all the tables to a single topic. Either is prefixed by `topic_prefix`.
+func topicForTable(topicPrefix, topicName, tableName string) string {
+	if topicName != `` {
+		return topicPrefix + topicName
+	}
+	return topicPrefix + SQLNameToKafkaName(tableName)
+}
+
 type kafkaSinkConfig struct {
 	kafkaTopicPrefix string
+	kafkaTopicName   string
 	tlsEnabled       bool
 	tlsSkipVerify    bool
 	caCert           []byte
@@ -336,7 +387,7 @@ func makeKafkaSink(
 	sink := &kafkaSink{cfg: cfg}
 	sink.topics = make(map[string]struct{})
 	for _, t := range targets {
-		sink.topics[cfg.kafkaTopicPrefix+SQLNameToKafkaName(t.StatementTimeName)] = struct{}{}
+		sink.topics[topicForTable(cfg.kafkaTopicPrefix, cfg.kafkaTopicName, t.StatementTimeName)] = struct{}{}
 	}
 
 	config := sarama.NewConfig()
@@ -466,7 +517,7 @@ func (s *kafkaSink) Close() error {
 func (s *kafkaSink) EmitRow(
 	ctx context.Context, table catalog.TableDescriptor, key, value []byte, updated hlc.Timestamp,
 ) error {
-	topic := s.cfg.kafkaTopicPrefix + SQLNameToKafkaName(table.GetName())
+	topic := topicForTable(s.cfg.kafkaTopicPrefix, s.cfg.kafkaTopicName, table.GetName())
 	if _, ok := s.topics[topic]; !ok {
 		return errors.Errorf(`cannot emit to undeclared topic: %s`, topic)
 	}
@@ -609,6 +660,11 @@ func (s *kafkaSink) workerLoop() {
 	}
 }
 
+// changefeedPartitioner assigns the messages of a changefeed to the partitions
+// of their topic by hashing their key, which is made of the primary key of the
+// row or of the columns given by the key_column option. All the changes to a
+// row, or to the rows sharing a key, thus go to the same partition. Messages
+// without a key, i.e. resolved timestamps, keep the partition they were given.
 type changefeedPartitioner struct {
 	hash sarama.Partitioner
 }
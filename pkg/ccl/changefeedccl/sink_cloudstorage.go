@@ -25,21 +25,61 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/errors"
 	"github.com/google/btree"
 )
 
+// cloudStorageSinkSchemes are the URI schemes of the cloud storage sinks.
+var cloudStorageSinkSchemes = []string{
+	`experimental-s3`, `experimental-gs`, `experimental-nodelocal`, `experimental-http`,
+	`experimental-https`, `experimental-azure`,
+}
+
+func init() {
+	registerSinkFactory(cloudStorageSinkFactory, cloudStorageSinkSchemes...)
+}
+
 func isCloudStorageSink(u *url.URL) bool {
-	switch u.Scheme {
-	case `experimental-s3`, `experimental-gs`, `experimental-nodelocal`, `experimental-http`,
-		`experimental-https`, `experimental-azure`:
-		return true
-	default:
-		return false
+	for _, scheme := range cloudStorageSinkSchemes {
+		if u.Scheme == scheme {
+			return true
+		}
 	}
+	return false
+}
+
+func cloudStorageSinkFactory(
+	ctx context.Context, u *url.URL, q url.Values, env sinkEnv,
+) (func() (Sink, error), error) {
+	fileSizeParam := q.Get(changefeedbase.SinkParamFileSize)
+	q.Del(changefeedbase.SinkParamFileSize)
+	var fileSize int64 = 16 << 20 // 16MB
+	if fileSizeParam != `` {
+		var err error
+		if fileSize, err = humanizeutil.ParseBytes(fileSizeParam); err != nil {
+			return nil, pgerror.Wrapf(err, pgcode.Syntax, `parsing %s`, fileSizeParam)
+		}
+	}
+	dest := *u
+	dest.Scheme = strings.TrimPrefix(dest.Scheme, `experimental-`)
+	// Transfer "ownership" of validating all remaining query parameters to
+	// ExternalStorage.
+	dest.RawQuery = q.Encode()
+	for k := range q {
+		q.Del(k)
+	}
+	return func() (Sink, error) {
+		return makeCloudStorageSink(
+			ctx, dest.String(), env.nodeID, fileSize, env.settings,
+			env.opts, env.timestampOracle, env.makeExternalStorageFromURI, env.user,
+		)
+	}, nil
 }
 
 // cloudStorageFormatTime formats times as YYYYMMDDHHMMSSNNNNNNNNNLLLLLLLLLL.
@@ -339,6 +379,12 @@ func makeCloudStorageSink(
 			_, err := w.Write([]byte{'\n'})
 			return err
 		}
+	case changefeedbase.OptFormatCSV:
+		s.ext = `.csv`
+		s.recordDelimFn = func(w io.Writer) error {
+			_, err := w.Write([]byte{'\n'})
+			return err
+		}
 	default:
 		return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
 			changefeedbase.OptFormat, opts[changefeedbase.OptFormat])
@@ -0,0 +1,348 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	gojson "encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	defaultPubsubEndpoint = `https://pubsub.googleapis.com`
+	pubsubScope           = `https://www.googleapis.com/auth/pubsub`
+
+	pubsubClientTimeout = 30 * time.Second
+	pubsubRetryMax      = 3
+	pubsubRetryBackoff  = 500 * time.Millisecond
+
+	// pubsubMaxBatchMessages and pubsubMaxBatchBytes bound the size of a
+	// publish request, which Pub/Sub limits to 1000 messages and 10MB. The data
+	// of the messages is base64 encoded in requests, which inflates it by a
+	// third.
+	pubsubMaxBatchMessages = 1000
+	pubsubMaxBatchBytes    = 6 << 20
+	// pubsubMaxOrderingKeyLen is the maximum size of an ordering key.
+	pubsubMaxOrderingKeyLen = 1 << 10
+)
+
+func init() {
+	registerSinkFactory(pubsubSinkFactory, changefeedbase.SinkSchemePubsub)
+}
+
+// pubsubMessage is a message of a Pub/Sub publish request.
+type pubsubMessage struct {
+	Data        []byte `json:"data"`
+	OrderingKey string `json:"orderingKey,omitempty"`
+}
+
+// pubsubPublishRequest is the body of a Pub/Sub publish request.
+type pubsubPublishRequest struct {
+	Messages []pubsubMessage `json:"messages"`
+}
+
+// pubsubTopicBatch holds the messages waiting to be published to a topic.
+type pubsubTopicBatch struct {
+	messages []pubsubMessage
+	bytes    int
+}
+
+// pubsubSink emits to Google Cloud Pub/Sub topics, through the REST API of
+// Pub/Sub. The sink URI is of the form `pubsub://<project>`, and rows of each
+// table are published to the topic named after the table, or to the single
+// topic given by the `topic_name` parameter, prefixed by the `topic_prefix`
+// parameter, like the topics of the Kafka sink. The topics must already exist.
+//
+// Messages are published with an ordering key derived from the encoded primary
+// key of their row, so that subscribers with message ordering enabled receive
+// the changes to a row in order. The ordering key is the key itself if it's
+// valid UTF-8 and short enough, or else the hex encoded SHA-256 hash of the key.
+// Resolved timestamps are published to every topic, without an ordering key,
+// once all the rows emitted before them have been published.
+//
+// The `endpoint` parameter overrides the URL of the Pub/Sub service, e.g. to
+// use a local Pub/Sub emulator, in which case no credentials are needed.
+// Otherwise, the `AUTH` parameter selects whether the credentials given in the
+// `CREDENTIALS` parameter (`AUTH=specified`) or the default credentials of the
+// node (`AUTH=implicit`) are used, like for Google Cloud Storage.
+//
+// The sink publishes messages synchronously: EmitRow blocks while a full batch
+// is being published. It is not concurrency-safe; all calls to Emit and Flush
+// should be from the same goroutine.
+type pubsubSink struct {
+	endpoint  string
+	project   string
+	client    *http.Client
+	retryOpts retry.Options
+
+	topicPrefix string
+	topicName   string
+	topics      map[string]*pubsubTopicBatch
+}
+
+var _ Sink = (*pubsubSink)(nil)
+
+func pubsubSinkFactory(
+	ctx context.Context, u *url.URL, q url.Values, env sinkEnv,
+) (func() (Sink, error), error) {
+	if u.Host == `` {
+		return nil, errors.Errorf(`the project must be the host of a %s sink URI`,
+			changefeedbase.SinkSchemePubsub)
+	}
+	endpoint := q.Get(changefeedbase.SinkParamPubsubEndpoint)
+	q.Del(changefeedbase.SinkParamPubsubEndpoint)
+	if endpoint != `` {
+		e, err := url.Parse(endpoint)
+		if err != nil || (e.Scheme != `http` && e.Scheme != `https`) || e.Host == `` {
+			return nil, errors.Errorf(`param %s must be an http or https URL: %s`,
+				changefeedbase.SinkParamPubsubEndpoint, endpoint)
+		}
+	}
+	topicPrefix := q.Get(changefeedbase.SinkParamTopicPrefix)
+	q.Del(changefeedbase.SinkParamTopicPrefix)
+	topicName := q.Get(changefeedbase.SinkParamTopicName)
+	q.Del(changefeedbase.SinkParamTopicName)
+
+	auth := q.Get(cloudimpl.AuthParam)
+	q.Del(cloudimpl.AuthParam)
+	credentials := q.Get(cloudimpl.CredentialsParam)
+	q.Del(cloudimpl.CredentialsParam)
+	var tokenSource oauth2.TokenSource
+	switch auth {
+	case ``:
+		if endpoint == `` {
+			return nil, errors.Errorf(`param %s must be set to %s or %s`,
+				cloudimpl.AuthParam, cloudimpl.AuthParamSpecified, cloudimpl.AuthParamImplicit)
+		}
+		if credentials != `` {
+			return nil, errors.Errorf(`param %s requires %s=%s`,
+				cloudimpl.CredentialsParam, cloudimpl.AuthParam, cloudimpl.AuthParamSpecified)
+		}
+	case cloudimpl.AuthParamSpecified:
+		if credentials == `` {
+			return nil, errors.Errorf(`%s is set to '%s', but %s is not set`,
+				cloudimpl.AuthParam, cloudimpl.AuthParamSpecified, cloudimpl.CredentialsParam)
+		}
+		decodedKey, err := base64.StdEncoding.DecodeString(credentials)
+		if err != nil {
+			return nil, errors.Wrapf(err, `decoding value of %s`, cloudimpl.CredentialsParam)
+		}
+		cfg, err := google.JWTConfigFromJSON(decodedKey, pubsubScope)
+		if err != nil {
+			return nil, errors.Wrap(err, `creating Pub/Sub oauth token source from specified credentials`)
+		}
+		tokenSource = cfg.TokenSource(ctx)
+	case cloudimpl.AuthParamImplicit:
+		if credentials != `` {
+			return nil, errors.Errorf(`param %s requires %s=%s`,
+				cloudimpl.CredentialsParam, cloudimpl.AuthParam, cloudimpl.AuthParamSpecified)
+		}
+	default:
+		return nil, errors.Errorf(`unsupported value %s for %s`, auth, cloudimpl.AuthParam)
+	}
+
+	project := u.Host
+	return func() (Sink, error) {
+		if auth == cloudimpl.AuthParamImplicit {
+			var err error
+			if tokenSource, err = google.DefaultTokenSource(ctx, pubsubScope); err != nil {
+				return nil, errors.Wrap(err, `finding default Pub/Sub credentials`)
+			}
+		}
+		return makePubsubSink(project, endpoint, topicPrefix, topicName, tokenSource, env)
+	}, nil
+}
+
+func makePubsubSink(
+	project, endpoint, topicPrefix, topicName string, tokenSource oauth2.TokenSource, env sinkEnv,
+) (*pubsubSink, error) {
+	if changefeedbase.EnvelopeType(env.opts[changefeedbase.OptEnvelope]) == changefeedbase.OptEnvelopeKeyOnly {
+		return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
+			changefeedbase.OptEnvelope, changefeedbase.OptEnvelopeKeyOnly)
+	}
+	if endpoint == `` {
+		endpoint = defaultPubsubEndpoint
+	}
+	client := &http.Client{Timeout: pubsubClientTimeout}
+	if tokenSource != nil {
+		client.Transport = &oauth2.Transport{Source: tokenSource}
+	}
+	s := &pubsubSink{
+		endpoint: endpoint,
+		project:  project,
+		client:   client,
+		retryOpts: retry.Options{
+			InitialBackoff: pubsubRetryBackoff,
+			Multiplier:     2,
+			MaxRetries:     pubsubRetryMax,
+		},
+		topicPrefix: topicPrefix,
+		topicName:   topicName,
+		topics:      make(map[string]*pubsubTopicBatch),
+	}
+	for _, t := range env.targets {
+		s.topics[topicForTable(topicPrefix, topicName, t.StatementTimeName)] = &pubsubTopicBatch{}
+	}
+	return s, nil
+}
+
+// pubsubOrderingKey returns the ordering key of the messages of the row with
+// the given encoded key.
+func pubsubOrderingKey(key []byte) string {
+	if len(key) <= pubsubMaxOrderingKeyLen && utf8.Valid(key) {
+		return string(key)
+	}
+	hash := sha256.Sum256(key)
+	return hex.EncodeToString(hash[:])
+}
+
+// EmitRow implements the Sink interface.
+func (s *pubsubSink) EmitRow(
+	ctx context.Context, table catalog.TableDescriptor, key, value []byte, _ hlc.Timestamp,
+) error {
+	topic := topicForTable(s.topicPrefix, s.topicName, table.GetName())
+	batch, ok := s.topics[topic]
+	if !ok {
+		return errors.Errorf(`cannot emit to undeclared topic: %s`, topic)
+	}
+	// The value is retained until the batch is published, so it's copied.
+	batch.messages = append(batch.messages, pubsubMessage{
+		Data:        append([]byte(nil), value...),
+		OrderingKey: pubsubOrderingKey(key),
+	})
+	batch.bytes += len(value)
+	if len(batch.messages) >= pubsubMaxBatchMessages || batch.bytes >= pubsubMaxBatchBytes {
+		return s.publishBatch(ctx, topic, batch)
+	}
+	return nil
+}
+
+// EmitResolvedTimestamp implements the Sink interface.
+func (s *pubsubSink) EmitResolvedTimestamp(
+	ctx context.Context, encoder Encoder, resolved hlc.Timestamp,
+) error {
+	// The resolved timestamp must be published after every row emitted before
+	// it.
+	if err := s.Flush(ctx); err != nil {
+		return err
+	}
+	for topic := range s.topics {
+		payload, err := encoder.EncodeResolvedTimestamp(ctx, topic, resolved)
+		if err != nil {
+			return err
+		}
+		if err := s.publish(ctx, topic, []pubsubMessage{{Data: payload}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush implements the Sink interface.
+func (s *pubsubSink) Flush(ctx context.Context) error {
+	for topic, batch := range s.topics {
+		if err := s.publishBatch(ctx, topic, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close implements the Sink interface.
+func (s *pubsubSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+func (s *pubsubSink) publishBatch(ctx context.Context, topic string, batch *pubsubTopicBatch) error {
+	if len(batch.messages) == 0 {
+		return nil
+	}
+	if err := s.publish(ctx, topic, batch.messages); err != nil {
+		return err
+	}
+	batch.messages = batch.messages[:0]
+	batch.bytes = 0
+	return nil
+}
+
+// publish publishes the given messages to a topic, retrying failed requests
+// unless they were rejected by Pub/Sub.
+func (s *pubsubSink) publish(ctx context.Context, topic string, messages []pubsubMessage) error {
+	body, err := gojson.Marshal(pubsubPublishRequest{Messages: messages})
+	if err != nil {
+		return err
+	}
+	publishURL := s.endpoint + `/v1/projects/` + url.PathEscape(s.project) +
+		`/topics/` + url.PathEscape(topic) + `:publish`
+	for r := retry.StartWithCtx(ctx, s.retryOpts); r.Next(); {
+		var status int
+		if status, err = s.post(ctx, publishURL, body); err == nil {
+			return nil
+		}
+		if status >= 400 && status < 500 &&
+			status != http.StatusRequestTimeout && status != http.StatusTooManyRequests {
+			break
+		}
+		if log.V(1) {
+			log.Infof(ctx, "retrying Pub/Sub publish request after error: %v", err)
+		}
+	}
+	if err == nil {
+		// The context was canceled before the request was sent.
+		return ctx.Err()
+	}
+	return errors.Wrapf(err, `publishing to Pub/Sub topic %s`, topic)
+}
+
+// post sends a publish request, returning the status code of the response if
+// one was received.
+func (s *pubsubSink) post(ctx context.Context, publishURL string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, publishURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", applicationTypeJSON)
+	res, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		resBody, err := ioutil.ReadAll(io.LimitReader(res.Body, maxWebhookErrorBodyLen))
+		if err != nil {
+			return res.StatusCode, errors.Wrapf(err,
+				`failed to read body for HTTP response with status: %s`, res.Status)
+		}
+		return res.StatusCode, errors.Errorf(`%s: %s`, res.Status, bytes.TrimSpace(resBody))
+	}
+	// Drain the body so that the connection can be reused.
+	_, err = io.Copy(ioutil.Discard, res.Body)
+	return res.StatusCode, err
+}
@@ -0,0 +1,228 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	gojson "encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/stretchr/testify/require"
+)
+
+// pubsubTestServer implements the publish endpoint of the Pub/Sub emulator
+// REST API, recording the messages published to its topics. Requests fail
+// with a 503 until failures runs out.
+type pubsubTestServer struct {
+	*httptest.Server
+
+	mu struct {
+		syncutil.Mutex
+		// messages holds the messages published to each topic, formatted as
+		// `<ordering key>: <data>`.
+		messages map[string][]string
+		requests int
+		failures int
+	}
+}
+
+var pubsubPublishPathRE = regexp.MustCompile(`^/v1/projects/([^/]+)/topics/([^/]+):publish$`)
+
+func makePubsubTestServer(t *testing.T, project string, topics ...string) *pubsubTestServer {
+	s := &pubsubTestServer{}
+	s.mu.messages = make(map[string][]string)
+	for _, topic := range topics {
+		s.mu.messages[topic] = nil
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.mu.requests++
+		if s.mu.failures > 0 {
+			s.mu.failures--
+			http.Error(w, `unavailable`, http.StatusServiceUnavailable)
+			return
+		}
+		m := pubsubPublishPathRE.FindStringSubmatch(r.URL.Path)
+		if r.Method != http.MethodPost || m == nil || m[1] != project {
+			http.Error(w, `not found`, http.StatusNotFound)
+			return
+		}
+		topic := m[2]
+		if _, ok := s.mu.messages[topic]; !ok {
+			http.Error(w, `topic not found: `+topic, http.StatusNotFound)
+			return
+		}
+		var req pubsubPublishRequest
+		if err := gojson.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var ids []string
+		for _, msg := range req.Messages {
+			s.mu.messages[topic] = append(s.mu.messages[topic], msg.OrderingKey+`: `+string(msg.Data))
+			ids = append(ids, fmt.Sprint(len(ids)))
+		}
+		_ = gojson.NewEncoder(w).Encode(map[string][]string{`messageIds`: ids})
+	}))
+	return s
+}
+
+func (s *pubsubTestServer) failNext(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.failures = n
+}
+
+func (s *pubsubTestServer) popMessages(topic string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	messages := s.mu.messages[topic]
+	s.mu.messages[topic] = nil
+	return messages
+}
+
+func (s *pubsubTestServer) requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mu.requests
+}
+
+func TestPubsubSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	server := makePubsubTestServer(t, `proj`, `x_foo`, `x_bar`, `x_all`)
+	defer server.Close()
+
+	targets := jobspb.ChangefeedTargets{
+		1: jobspb.ChangefeedTarget{StatementTimeName: `foo`},
+		2: jobspb.ChangefeedTarget{StatementTimeName: `bar`},
+	}
+	opts := map[string]string{
+		changefeedbase.OptFormat:   string(changefeedbase.OptFormatJSON),
+		changefeedbase.OptEnvelope: string(changefeedbase.OptEnvelopeWrapped),
+	}
+	makeSink := func(sinkURI string) (Sink, error) {
+		return getSink(ctx, sinkURI, 0 /* nodeID */, opts, targets, nil, /* settings */
+			nil /* timestampOracle */, nil /* makeExternalStorageFromURI */, security.RootUserName())
+	}
+
+	sinkURI := `pubsub://proj?topic_prefix=x_&endpoint=` + server.URL
+	sink, err := makeSink(sinkURI)
+	require.NoError(t, err)
+	sink.(*pubsubSink).retryOpts.InitialBackoff = time.Millisecond
+	defer func() { require.NoError(t, sink.Close()) }()
+
+	foo := tabledesc.NewImmutable(descpb.TableDescriptor{Name: `foo`})
+	bar := tabledesc.NewImmutable(descpb.TableDescriptor{Name: `bar`})
+
+	// Rows are buffered until the sink is flushed.
+	require.NoError(t, sink.EmitRow(ctx, foo, []byte(`[1]`), []byte(`{"after": {"a": 1}}`), hlc.Timestamp{}))
+	require.NoError(t, sink.EmitRow(ctx, bar, []byte(`[2]`), []byte(`{"after": {"a": 2}}`), hlc.Timestamp{}))
+	// Keys that aren't valid UTF-8 are hashed into ordering keys.
+	require.NoError(t, sink.EmitRow(ctx, foo, []byte{0xff}, []byte(`binary`), hlc.Timestamp{}))
+	require.Empty(t, server.popMessages(`x_foo`))
+	require.NoError(t, sink.Flush(ctx))
+	require.Equal(t, []string{
+		`[1]: {"after": {"a": 1}}`,
+		`a8100ae6aa1940d0b663bb31cd466142ebbdbd5187131b92d93818987832eb89: binary`,
+	}, server.popMessages(`x_foo`))
+	require.Equal(t, []string{`[2]: {"after": {"a": 2}}`}, server.popMessages(`x_bar`))
+
+	// Full batches are published without waiting for a flush.
+	requests := server.requests()
+	for i := 0; i < pubsubMaxBatchMessages; i++ {
+		require.NoError(t, sink.EmitRow(ctx, foo, []byte(`[1]`), []byte(`{}`), hlc.Timestamp{}))
+	}
+	require.Len(t, server.popMessages(`x_foo`), pubsubMaxBatchMessages)
+	require.Equal(t, requests+1, server.requests())
+
+	// Resolved timestamps are published to every topic after the pending rows.
+	require.NoError(t, sink.EmitRow(ctx, foo, []byte(`[3]`), []byte(`{"after": {"a": 3}}`), hlc.Timestamp{}))
+	encoder, err := makeJSONEncoder(opts)
+	require.NoError(t, err)
+	require.NoError(t, sink.EmitResolvedTimestamp(ctx, encoder, hlc.Timestamp{WallTime: 2}))
+	require.Equal(t, []string{
+		`[3]: {"after": {"a": 3}}`,
+		`: {"resolved":"2.0000000000"}`,
+	}, server.popMessages(`x_foo`))
+	require.Equal(t, []string{`: {"resolved":"2.0000000000"}`}, server.popMessages(`x_bar`))
+
+	// Failed requests are retried.
+	server.failNext(2)
+	require.NoError(t, sink.EmitRow(ctx, bar, []byte(`[4]`), []byte(`{"after": {"a": 4}}`), hlc.Timestamp{}))
+	require.NoError(t, sink.Flush(ctx))
+	require.Equal(t, []string{`[4]: {"after": {"a": 4}}`}, server.popMessages(`x_bar`))
+	server.failNext(pubsubRetryMax + 1)
+	require.NoError(t, sink.EmitRow(ctx, bar, []byte(`[5]`), []byte(`{}`), hlc.Timestamp{}))
+	require.Regexp(t, `publishing to Pub/Sub topic x_bar: 503 Service Unavailable`, sink.Flush(ctx))
+	require.NoError(t, sink.Flush(ctx))
+	require.Equal(t, []string{`[5]: {}`}, server.popMessages(`x_bar`))
+
+	// Rows can only be emitted to the topics of the targets.
+	baz := tabledesc.NewImmutable(descpb.TableDescriptor{Name: `baz`})
+	require.EqualError(t, sink.EmitRow(ctx, baz, []byte(`[1]`), []byte(`{}`), hlc.Timestamp{}),
+		`cannot emit to undeclared topic: x_baz`)
+
+	// The rows of all the tables are published to the topic given by topic_name.
+	topicNameSink, err := makeSink(`pubsub://proj?topic_prefix=x_&topic_name=all&endpoint=` + server.URL)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, topicNameSink.Close()) }()
+	require.NoError(t, topicNameSink.EmitRow(ctx, foo, []byte(`[1]`), []byte(`{"after": {"a": 1}}`), hlc.Timestamp{}))
+	require.NoError(t, topicNameSink.EmitRow(ctx, bar, []byte(`[2]`), []byte(`{"after": {"a": 2}}`), hlc.Timestamp{}))
+	require.NoError(t, topicNameSink.EmitResolvedTimestamp(ctx, encoder, hlc.Timestamp{WallTime: 3}))
+	require.Equal(t, []string{
+		`[1]: {"after": {"a": 1}}`,
+		`[2]: {"after": {"a": 2}}`,
+		`: {"resolved":"3.0000000000"}`,
+	}, server.popMessages(`x_all`))
+
+	// Topics must exist. Rejected requests aren't retried.
+	requests = server.requests()
+	noPrefixSink, err := makeSink(`pubsub://proj?endpoint=` + server.URL)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, noPrefixSink.Close()) }()
+	require.NoError(t, noPrefixSink.EmitRow(ctx, foo, []byte(`[1]`), []byte(`{}`), hlc.Timestamp{}))
+	require.Regexp(t, `404 Not Found: topic not found: foo`, noPrefixSink.Flush(ctx))
+	require.Equal(t, requests+1, server.requests())
+
+	for sinkURI, expectedErr := range map[string]string{
+		`pubsub://`:                                           `the project must be the host of a pubsub sink URI`,
+		`pubsub://proj`:                                       `param AUTH must be set to specified or implicit`,
+		`pubsub://proj?AUTH=nope`:                             `unsupported value nope for AUTH`,
+		`pubsub://proj?AUTH=specified`:                        `AUTH is set to 'specified', but CREDENTIALS is not set`,
+		`pubsub://proj?AUTH=specified&CREDENTIALS=!`:          `decoding value of CREDENTIALS: .*`,
+		`pubsub://proj?AUTH=implicit&CREDENTIALS=Zm9v`:        `param CREDENTIALS requires AUTH=specified`,
+		`pubsub://proj?endpoint=localhost:8085`:               `param endpoint must be an http or https URL: localhost:8085`,
+		`pubsub://proj?endpoint=http://localhost:8085&nope=1`: `unknown sink query parameter: nope`,
+	} {
+		_, err := makeSink(sinkURI)
+		require.Regexp(t, expectedErr, err, sinkURI)
+	}
+
+	opts[changefeedbase.OptEnvelope] = string(changefeedbase.OptEnvelopeKeyOnly)
+	_, err = makeSink(sinkURI)
+	require.EqualError(t, err, `this sink is incompatible with envelope=key_only`)
+}
@@ -10,6 +10,7 @@ package changefeedccl
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"strconv"
 	"testing"
@@ -169,6 +170,69 @@ func TestKafkaSinkEscaping(t *testing.T) {
 	require.Equal(t, sarama.ByteEncoder(`v☃`), m.Value)
 }
 
+func TestKafkaSinkTopicName(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	table := func(name string) *tabledesc.Immutable {
+		return tabledesc.NewImmutable(descpb.TableDescriptor{Name: name})
+	}
+
+	require.Equal(t, `p_foo`, topicForTable(`p_`, ``, `foo`))
+	require.Equal(t, `p_all`, topicForTable(`p_`, `all`, `foo`))
+	require.Equal(t, `all`, topicForTable(``, `all`, `☃`))
+
+	ctx := context.Background()
+	p := asyncProducerMock{
+		inputCh:     make(chan *sarama.ProducerMessage, 1),
+		successesCh: make(chan *sarama.ProducerMessage, 1),
+		errorsCh:    make(chan *sarama.ProducerError, 1),
+	}
+	sink := &kafkaSink{
+		cfg:      kafkaSinkConfig{kafkaTopicPrefix: `p_`, kafkaTopicName: `all`},
+		producer: p,
+		topics:   map[string]struct{}{`p_all`: {}},
+	}
+	sink.start()
+	defer func() { require.NoError(t, sink.Close()) }()
+
+	// The rows of all the tables go to the same topic.
+	for _, name := range []string{`foo`, `bar`} {
+		require.NoError(t, sink.EmitRow(ctx, table(name), []byte(`k`), []byte(`v`), zeroTS))
+		m := <-p.inputCh
+		require.Equal(t, `p_all`, m.Topic)
+		p.successesCh <- m
+	}
+	require.NoError(t, sink.Flush(ctx))
+}
+
+func TestChangefeedPartitioner(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numPartitions = 16
+	p := newChangefeedPartitioner(`t`)
+	partition := func(key []byte) int32 {
+		msg := &sarama.ProducerMessage{Topic: `t`, Partition: 7}
+		if key != nil {
+			msg.Key = sarama.ByteEncoder(key)
+		}
+		partition, err := p.Partition(msg, numPartitions)
+		require.NoError(t, err)
+		return partition
+	}
+
+	// Messages with the same key, e.g. the same value of the key_column
+	// columns, go to the same partition.
+	require.Equal(t, partition([]byte(`["tenant-1"]`)), partition([]byte(`["tenant-1"]`)))
+	partitions := make(map[int32]struct{})
+	for i := 0; i < 100; i++ {
+		partitions[partition([]byte(fmt.Sprintf(`["tenant-%d"]`, i)))] = struct{}{}
+	}
+	require.Greater(t, len(partitions), 1)
+	// Messages without a key, i.e. resolved timestamps, keep their partition.
+	require.Equal(t, int32(7), partition(nil))
+}
+
 type testEncoder struct{}
 
 func (testEncoder) EncodeKey(context.Context, encodeRow) ([]byte, error)   { panic(`unimplemented`) }
@@ -0,0 +1,424 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	gojson "encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/util/bufalloc"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/httputil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	applicationTypeJSON = `application/json`
+	authorizationHeader = `Authorization`
+
+	defaultWebhookClientTimeout = 3 * time.Second
+	defaultWebhookRetryMax      = 3
+	defaultWebhookRetryBackoff  = 500 * time.Millisecond
+	// maxWebhookErrorBodyLen is the number of bytes of the body of a failed
+	// response that are included in the returned error.
+	maxWebhookErrorBodyLen = 1 << 10
+)
+
+func init() {
+	registerSinkFactory(webhookSinkFactory, changefeedbase.SinkSchemeWebhookHTTPS)
+}
+
+func isWebhookSink(u *url.URL) bool {
+	return u.Scheme == changefeedbase.SinkSchemeWebhookHTTPS
+}
+
+func webhookSinkFactory(
+	_ context.Context, u *url.URL, q url.Values, env sinkEnv,
+) (func() (Sink, error), error) {
+	var tlsCfg webhookSinkTLSConfig
+	var err error
+	if tlsVerifyBool := q.Get(changefeedbase.SinkParamSkipTLSVerify); tlsVerifyBool != `` {
+		if tlsCfg.tlsSkipVerify, err = strconv.ParseBool(tlsVerifyBool); err != nil {
+			return nil, errors.Errorf(`param %s must be a bool: %s`, changefeedbase.SinkParamSkipTLSVerify, err)
+		}
+	}
+	q.Del(changefeedbase.SinkParamSkipTLSVerify)
+	if caCertHex := q.Get(changefeedbase.SinkParamCACert); caCertHex != `` {
+		if tlsCfg.caCert, err = base64.StdEncoding.DecodeString(caCertHex); err != nil {
+			return nil, errors.Errorf(`param %s must be base 64 encoded: %s`, changefeedbase.SinkParamCACert, err)
+		}
+	}
+	q.Del(changefeedbase.SinkParamCACert)
+	if clientCertHex := q.Get(changefeedbase.SinkParamClientCert); clientCertHex != `` {
+		if tlsCfg.clientCert, err = base64.StdEncoding.DecodeString(clientCertHex); err != nil {
+			return nil, errors.Errorf(`param %s must be base 64 encoded: %s`, changefeedbase.SinkParamClientCert, err)
+		}
+	}
+	q.Del(changefeedbase.SinkParamClientCert)
+	if clientKeyHex := q.Get(changefeedbase.SinkParamClientKey); clientKeyHex != `` {
+		if tlsCfg.clientKey, err = base64.StdEncoding.DecodeString(clientKeyHex); err != nil {
+			return nil, errors.Errorf(`param %s must be base 64 encoded: %s`, changefeedbase.SinkParamClientKey, err)
+		}
+	}
+	q.Del(changefeedbase.SinkParamClientKey)
+	// The remaining query parameters are part of the endpoint's URL.
+	dest := *u
+	dest.RawQuery = q.Encode()
+	for k := range q {
+		q.Del(k)
+	}
+	return func() (Sink, error) {
+		return makeWebhookSink(&dest, tlsCfg, env.opts)
+	}, nil
+}
+
+// webhookSinkPayload is the body of the requests that deliver a batch of rows
+// to a webhook sink. Resolved timestamps are delivered in requests of their
+// own, whose body is the encoded resolved timestamp.
+type webhookSinkPayload struct {
+	Payload []gojson.RawMessage `json:"payload"`
+	Length  int                 `json:"length"`
+}
+
+// webhookSinkConfig is the configuration of a webhook sink, which is given as
+// a JSON object in the `webhook_sink_config` option, e.g.
+//
+//   {"Flush": {"Messages": 100, "Frequency": "1s"}, "Retry": {"Max": 5}}
+//
+type webhookSinkConfig struct {
+	Flush webhookBatchConfig
+	Retry webhookRetryConfig
+}
+
+// webhookBatchConfig configures how rows are batched into requests. A batch
+// is sent as soon as any of the configured thresholds is reached. If none is
+// configured, every row is sent in a request of its own.
+type webhookBatchConfig struct {
+	// Messages is the maximum number of rows in a batch.
+	Messages int
+	// Bytes is the maximum total size of the encoded rows in a batch.
+	Bytes int
+	// Frequency is the maximum amount of time the first row of a batch waits to
+	// be sent. It is checked whenever a row is emitted. Pending rows are always
+	// sent when the sink is flushed, which happens at least as often as the
+	// changefeed's resolved timestamp advances.
+	Frequency jsonDuration
+}
+
+// webhookRetryConfig configures how failed requests are retried.
+type webhookRetryConfig struct {
+	// Max is the maximum number of times a failed request is retried.
+	Max int
+	// Backoff is the initial backoff between retries. It doubles after every
+	// retry.
+	Backoff jsonDuration
+}
+
+// jsonDuration is a time.Duration that is represented in JSON as a string
+// that can be parsed with time.ParseDuration, e.g. "500ms".
+type jsonDuration time.Duration
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (d *jsonDuration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := gojson.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	dur, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = jsonDuration(dur)
+	return nil
+}
+
+func makeWebhookSinkConfig(opts map[string]string) (webhookSinkConfig, error) {
+	cfg := webhookSinkConfig{
+		Retry: webhookRetryConfig{
+			Max:     defaultWebhookRetryMax,
+			Backoff: jsonDuration(defaultWebhookRetryBackoff),
+		},
+	}
+	if s, ok := opts[changefeedbase.OptWebhookSinkConfig]; ok {
+		dec := gojson.NewDecoder(strings.NewReader(s))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&cfg); err != nil {
+			return cfg, errors.Wrapf(err, `parsing %s`, changefeedbase.OptWebhookSinkConfig)
+		}
+	}
+	if cfg.Flush.Messages < 0 || cfg.Flush.Bytes < 0 || cfg.Flush.Frequency < 0 {
+		return cfg, errors.Errorf(`invalid %s: flush thresholds cannot be negative`,
+			changefeedbase.OptWebhookSinkConfig)
+	}
+	if cfg.Retry.Max < 0 || cfg.Retry.Backoff < 0 {
+		return cfg, errors.Errorf(`invalid %s: retry settings cannot be negative`,
+			changefeedbase.OptWebhookSinkConfig)
+	}
+	return cfg, nil
+}
+
+// webhookSinkTLSConfig holds the TLS parameters of a webhook sink URI.
+type webhookSinkTLSConfig struct {
+	caCert        []byte
+	clientCert    []byte
+	clientKey     []byte
+	tlsSkipVerify bool
+}
+
+// webhookSink emits to an HTTPS endpoint. Rows are POSTed in batches as a JSON
+// object of the form `{"payload": [...], "length": n}`, in the order in which
+// they were emitted. Failed requests are retried with an exponential backoff.
+//
+// The sink delivers messages synchronously: EmitRow blocks while a full batch
+// is being sent. It is not concurrency-safe; all calls to Emit and Flush
+// should be from the same goroutine.
+type webhookSink struct {
+	url        string
+	authHeader string
+	cfg        webhookSinkConfig
+	client     *httputil.Client
+
+	batch      []gojson.RawMessage
+	batchBytes int
+	batchStart time.Time
+	scratch    bufalloc.ByteAllocator
+}
+
+var _ Sink = (*webhookSink)(nil)
+
+func makeWebhookSink(
+	u *url.URL, tlsCfg webhookSinkTLSConfig, opts map[string]string,
+) (*webhookSink, error) {
+	if changefeedbase.FormatType(opts[changefeedbase.OptFormat]) != changefeedbase.OptFormatJSON {
+		return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
+			changefeedbase.OptFormat, opts[changefeedbase.OptFormat])
+	}
+	if changefeedbase.EnvelopeType(opts[changefeedbase.OptEnvelope]) != changefeedbase.OptEnvelopeWrapped {
+		return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
+			changefeedbase.OptEnvelope, opts[changefeedbase.OptEnvelope])
+	}
+	for _, opt := range []string{changefeedbase.OptKeyInValue, changefeedbase.OptTopicInValue} {
+		if _, ok := opts[opt]; !ok {
+			return nil, errors.Errorf(`this sink requires the WITH %s option`, opt)
+		}
+	}
+
+	cfg, err := makeWebhookSinkConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	timeout := defaultWebhookClientTimeout
+	if s, ok := opts[changefeedbase.OptWebhookClientTimeout]; ok {
+		if timeout, err = time.ParseDuration(s); err != nil {
+			return nil, errors.Wrapf(err, `parsing %s`, changefeedbase.OptWebhookClientTimeout)
+		}
+		if timeout <= 0 {
+			return nil, errors.Errorf(`%s must be positive: %s`,
+				changefeedbase.OptWebhookClientTimeout, s)
+		}
+	}
+	client, err := makeWebhookClient(tlsCfg, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	// Swap the changefeed prefix for the one that the HTTP client expects.
+	dest := *u
+	dest.Scheme = strings.TrimPrefix(dest.Scheme, `webhook-`)
+	return &webhookSink{
+		url:        dest.String(),
+		authHeader: opts[changefeedbase.OptWebhookAuthHeader],
+		cfg:        cfg,
+		client:     client,
+	}, nil
+}
+
+func makeWebhookClient(
+	tlsCfg webhookSinkTLSConfig, timeout time.Duration,
+) (*httputil.Client, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: tlsCfg.tlsSkipVerify}
+	if tlsCfg.caCert != nil {
+		caCertPool, err := x509.SystemCertPool()
+		if err != nil || caCertPool == nil {
+			caCertPool = x509.NewCertPool()
+		}
+		if !caCertPool.AppendCertsFromPEM(tlsCfg.caCert) {
+			return nil, errors.Errorf(`invalid %s: no certificates found`, changefeedbase.SinkParamCACert)
+		}
+		tlsConfig.RootCAs = caCertPool
+	}
+	if tlsCfg.clientCert != nil {
+		if tlsCfg.clientKey == nil {
+			return nil, errors.Errorf(`%s requires %s to be set`,
+				changefeedbase.SinkParamClientCert, changefeedbase.SinkParamClientKey)
+		}
+		cert, err := tls.X509KeyPair(tlsCfg.clientCert, tlsCfg.clientKey)
+		if err != nil {
+			return nil, errors.Errorf(`invalid client certificate data provided: %s`, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	} else if tlsCfg.clientKey != nil {
+		return nil, errors.Errorf(`%s requires %s to be set`,
+			changefeedbase.SinkParamClientKey, changefeedbase.SinkParamClientCert)
+	}
+
+	return &httputil.Client{Client: &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:     (&net.Dialer{Timeout: timeout}).DialContext,
+			TLSClientConfig: tlsConfig,
+		},
+	}}, nil
+}
+
+// EmitRow implements the Sink interface.
+func (s *webhookSink) EmitRow(
+	ctx context.Context, _ catalog.TableDescriptor, _, value []byte, _ hlc.Timestamp,
+) error {
+	if len(s.batch) == 0 {
+		s.batchStart = timeutil.Now()
+	}
+	s.scratch, value = s.scratch.Copy(value, 0 /* extraCap */)
+	s.batch = append(s.batch, value)
+	s.batchBytes += len(value)
+	if s.shouldSendBatch() {
+		return s.sendBatch(ctx)
+	}
+	return nil
+}
+
+func (s *webhookSink) shouldSendBatch() bool {
+	flush := s.cfg.Flush
+	switch {
+	case flush.Messages == 0 && flush.Bytes == 0 && flush.Frequency == 0:
+		return true
+	case flush.Messages > 0 && len(s.batch) >= flush.Messages:
+		return true
+	case flush.Bytes > 0 && s.batchBytes >= flush.Bytes:
+		return true
+	case flush.Frequency > 0 && timeutil.Since(s.batchStart) >= time.Duration(flush.Frequency):
+		return true
+	}
+	return false
+}
+
+// EmitResolvedTimestamp implements the Sink interface.
+func (s *webhookSink) EmitResolvedTimestamp(
+	ctx context.Context, encoder Encoder, resolved hlc.Timestamp,
+) error {
+	// The resolved timestamp must be delivered after every row emitted before
+	// it.
+	if err := s.sendBatch(ctx); err != nil {
+		return err
+	}
+	var noTopic string
+	payload, err := encoder.EncodeResolvedTimestamp(ctx, noTopic, resolved)
+	if err != nil {
+		return err
+	}
+	return s.send(ctx, payload)
+}
+
+// Flush implements the Sink interface.
+func (s *webhookSink) Flush(ctx context.Context) error {
+	return s.sendBatch(ctx)
+}
+
+// Close implements the Sink interface.
+func (s *webhookSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+func (s *webhookSink) sendBatch(ctx context.Context) error {
+	if len(s.batch) == 0 {
+		return nil
+	}
+	body, err := gojson.Marshal(webhookSinkPayload{Payload: s.batch, Length: len(s.batch)})
+	if err != nil {
+		return err
+	}
+	if err := s.send(ctx, body); err != nil {
+		return err
+	}
+	s.batch = s.batch[:0]
+	s.batchBytes = 0
+	s.scratch = s.scratch[:0]
+	return nil
+}
+
+// send POSTs the given body to the sink, retrying failed requests.
+func (s *webhookSink) send(ctx context.Context, body []byte) error {
+	opts := retry.Options{
+		InitialBackoff: time.Duration(s.cfg.Retry.Backoff),
+		Multiplier:     2,
+	}
+	var err error
+	attempt := 0
+	for r := retry.StartWithCtx(ctx, opts); r.Next(); attempt++ {
+		if err = s.post(ctx, body); err == nil {
+			return nil
+		}
+		if attempt >= s.cfg.Retry.Max {
+			break
+		}
+		if log.V(1) {
+			log.Infof(ctx, "retrying webhook sink request after error: %v", err)
+		}
+	}
+	if err == nil {
+		// The context was canceled before the request was sent.
+		return ctx.Err()
+	}
+	return errors.Wrapf(err, `sending to webhook sink (%d attempts)`, attempt+1)
+}
+
+func (s *webhookSink) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", applicationTypeJSON)
+	if s.authHeader != `` {
+		req.Header.Set(authorizationHeader, s.authHeader)
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		resBody, err := ioutil.ReadAll(io.LimitReader(res.Body, maxWebhookErrorBodyLen))
+		if err != nil {
+			return errors.Wrapf(err, `failed to read body for HTTP response with status: %s`, res.Status)
+		}
+		return errors.Errorf(`%s: %s`, res.Status, resBody)
+	}
+	// Drain the body so that the connection can be reused.
+	_, err = io.Copy(ioutil.Discard, res.Body)
+	return err
+}
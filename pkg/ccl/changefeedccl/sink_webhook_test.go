@@ -0,0 +1,362 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/changefeedccl/changefeedbase"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/security/securitytest"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookTestServer records the bodies of the requests it receives. Requests
+// fail with the configured status code until failures runs out.
+type webhookTestServer struct {
+	*httptest.Server
+
+	mu struct {
+		syncutil.Mutex
+		bodies   []string
+		headers  []http.Header
+		failures int
+		status   int
+		delay    time.Duration
+	}
+}
+
+func makeWebhookTestServer(t *testing.T, clientAuth tls.ClientAuthType) *webhookTestServer {
+	s := &webhookTestServer{}
+	s.Server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		s.mu.Lock()
+		delay := s.mu.delay
+		failures := s.mu.failures
+		if failures > 0 {
+			s.mu.failures--
+		} else {
+			s.mu.bodies = append(s.mu.bodies, string(body))
+			s.mu.headers = append(s.mu.headers, r.Header)
+		}
+		status := s.mu.status
+		s.mu.Unlock()
+		time.Sleep(delay)
+		if failures > 0 {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`boom`))
+		}
+	}))
+	s.TLS = &tls.Config{ClientAuth: clientAuth}
+	s.StartTLS()
+	return s
+}
+
+func (s *webhookTestServer) failNext(n int, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.failures = n
+	s.mu.status = status
+}
+
+func (s *webhookTestServer) setDelay(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.delay = d
+}
+
+func (s *webhookTestServer) popBodies() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bodies := s.mu.bodies
+	s.mu.bodies = nil
+	return bodies
+}
+
+func (s *webhookTestServer) lastHeader() http.Header {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mu.headers[len(s.mu.headers)-1]
+}
+
+func (s *webhookTestServer) sinkURL(t *testing.T) *url.URL {
+	u, err := url.Parse(s.URL)
+	require.NoError(t, err)
+	u.Scheme = changefeedbase.SinkSchemeWebhookHTTPS
+	return u
+}
+
+func (s *webhookTestServer) caCert() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.Certificate().Raw})
+}
+
+func TestWebhookSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	webhookOpts := func(extra map[string]string) map[string]string {
+		opts := map[string]string{
+			changefeedbase.OptFormat:       string(changefeedbase.OptFormatJSON),
+			changefeedbase.OptEnvelope:     string(changefeedbase.OptEnvelopeWrapped),
+			changefeedbase.OptKeyInValue:   ``,
+			changefeedbase.OptTopicInValue: ``,
+		}
+		for k, v := range extra {
+			opts[k] = v
+		}
+		return opts
+	}
+	makeSink := func(
+		t *testing.T, srv *webhookTestServer, tlsCfg webhookSinkTLSConfig, opts map[string]string,
+	) *webhookSink {
+		if tlsCfg.caCert == nil && !tlsCfg.tlsSkipVerify {
+			tlsCfg.caCert = srv.caCert()
+		}
+		s, err := makeWebhookSink(srv.sinkURL(t), tlsCfg, opts)
+		require.NoError(t, err)
+		return s
+	}
+
+	t.Run("row per request", func(t *testing.T) {
+		srv := makeWebhookTestServer(t, tls.NoClientCert)
+		defer srv.Close()
+		s := makeSink(t, srv, webhookSinkTLSConfig{}, webhookOpts(nil))
+		defer func() { require.NoError(t, s.Close()) }()
+
+		require.NoError(t, s.EmitRow(ctx, nil, nil, []byte(`{"after":{"a":1}}`), zeroTS))
+		require.NoError(t, s.EmitRow(ctx, nil, nil, []byte(`{"after":{"a":2}}`), zeroTS))
+		require.Equal(t, []string{
+			`{"payload":[{"after":{"a":1}}],"length":1}`,
+			`{"payload":[{"after":{"a":2}}],"length":1}`,
+		}, srv.popBodies())
+		require.Equal(t, applicationTypeJSON, srv.lastHeader().Get("Content-Type"))
+		require.Empty(t, srv.lastHeader().Get(authorizationHeader))
+	})
+
+	t.Run("batching", func(t *testing.T) {
+		srv := makeWebhookTestServer(t, tls.NoClientCert)
+		defer srv.Close()
+		s := makeSink(t, srv, webhookSinkTLSConfig{}, webhookOpts(map[string]string{
+			changefeedbase.OptWebhookSinkConfig: `{"Flush": {"Messages": 2}}`,
+		}))
+		defer func() { require.NoError(t, s.Close()) }()
+
+		require.NoError(t, s.EmitRow(ctx, nil, nil, []byte(`{"after":{"a":1}}`), zeroTS))
+		require.Empty(t, srv.popBodies())
+		require.NoError(t, s.EmitRow(ctx, nil, nil, []byte(`{"after":{"a":2}}`), zeroTS))
+		require.Equal(t, []string{
+			`{"payload":[{"after":{"a":1}},{"after":{"a":2}}],"length":2}`,
+		}, srv.popBodies())
+
+		// Flush sends partial batches.
+		require.NoError(t, s.EmitRow(ctx, nil, nil, []byte(`{"after":{"a":3}}`), zeroTS))
+		require.Empty(t, srv.popBodies())
+		require.NoError(t, s.Flush(ctx))
+		require.Equal(t, []string{
+			`{"payload":[{"after":{"a":3}}],"length":1}`,
+		}, srv.popBodies())
+		require.NoError(t, s.Flush(ctx))
+		require.Empty(t, srv.popBodies())
+
+		// Resolved timestamps are sent after any pending rows.
+		enc, err := makeJSONEncoder(webhookOpts(nil))
+		require.NoError(t, err)
+		require.NoError(t, s.EmitRow(ctx, nil, nil, []byte(`{"after":{"a":4}}`), zeroTS))
+		require.NoError(t, s.EmitResolvedTimestamp(ctx, enc, hlc.Timestamp{WallTime: 5}))
+		require.Equal(t, []string{
+			`{"payload":[{"after":{"a":4}}],"length":1}`,
+			`{"resolved":"5.0000000000"}`,
+		}, srv.popBodies())
+	})
+
+	t.Run("batching by bytes", func(t *testing.T) {
+		srv := makeWebhookTestServer(t, tls.NoClientCert)
+		defer srv.Close()
+		s := makeSink(t, srv, webhookSinkTLSConfig{}, webhookOpts(map[string]string{
+			changefeedbase.OptWebhookSinkConfig: `{"Flush": {"Bytes": 20, "Frequency": "1h"}}`,
+		}))
+		defer func() { require.NoError(t, s.Close()) }()
+
+		require.NoError(t, s.EmitRow(ctx, nil, nil, []byte(`{"after":{"a":1}}`), zeroTS))
+		require.Empty(t, srv.popBodies())
+		require.NoError(t, s.EmitRow(ctx, nil, nil, []byte(`{"after":{"a":2}}`), zeroTS))
+		require.Equal(t, []string{
+			`{"payload":[{"after":{"a":1}},{"after":{"a":2}}],"length":2}`,
+		}, srv.popBodies())
+	})
+
+	t.Run("retries", func(t *testing.T) {
+		srv := makeWebhookTestServer(t, tls.NoClientCert)
+		defer srv.Close()
+		s := makeSink(t, srv, webhookSinkTLSConfig{}, webhookOpts(map[string]string{
+			changefeedbase.OptWebhookSinkConfig: `{"Retry": {"Max": 2, "Backoff": "1ms"}}`,
+		}))
+		defer func() { require.NoError(t, s.Close()) }()
+
+		srv.failNext(2, http.StatusInternalServerError)
+		require.NoError(t, s.EmitRow(ctx, nil, nil, []byte(`{"after":{"a":1}}`), zeroTS))
+		require.Equal(t, []string{
+			`{"payload":[{"after":{"a":1}}],"length":1}`,
+		}, srv.popBodies())
+
+		srv.failNext(3, http.StatusBadGateway)
+		err := s.EmitRow(ctx, nil, nil, []byte(`{"after":{"a":2}}`), zeroTS)
+		require.Regexp(t, `sending to webhook sink \(3 attempts\): 502 Bad Gateway: boom`, err)
+		require.Empty(t, srv.popBodies())
+
+		// The failed batch is retried on the next flush.
+		require.NoError(t, s.Flush(ctx))
+		require.Equal(t, []string{
+			`{"payload":[{"after":{"a":2}}],"length":1}`,
+		}, srv.popBodies())
+	})
+
+	t.Run("auth header", func(t *testing.T) {
+		srv := makeWebhookTestServer(t, tls.NoClientCert)
+		defer srv.Close()
+		s := makeSink(t, srv, webhookSinkTLSConfig{}, webhookOpts(map[string]string{
+			changefeedbase.OptWebhookAuthHeader: `Basic Zm9vOmJhcg==`,
+		}))
+		defer func() { require.NoError(t, s.Close()) }()
+
+		require.NoError(t, s.EmitRow(ctx, nil, nil, []byte(`{"after":{"a":1}}`), zeroTS))
+		require.Equal(t, `Basic Zm9vOmJhcg==`, srv.lastHeader().Get(authorizationHeader))
+	})
+
+	t.Run("client timeout", func(t *testing.T) {
+		srv := makeWebhookTestServer(t, tls.NoClientCert)
+		defer srv.Close()
+		s := makeSink(t, srv, webhookSinkTLSConfig{}, webhookOpts(map[string]string{
+			changefeedbase.OptWebhookClientTimeout: `10ms`,
+			changefeedbase.OptWebhookSinkConfig:    `{"Retry": {"Max": 0}}`,
+		}))
+		defer func() { require.NoError(t, s.Close()) }()
+
+		srv.setDelay(500 * time.Millisecond)
+		err := s.EmitRow(ctx, nil, nil, []byte(`{"after":{"a":1}}`), zeroTS)
+		require.Regexp(t, `sending to webhook sink \(1 attempts\).*Client.Timeout exceeded`, err)
+	})
+
+	t.Run("tls", func(t *testing.T) {
+		srv := makeWebhookTestServer(t, tls.NoClientCert)
+		defer srv.Close()
+
+		// The certificate of the test server is not trusted by default.
+		s, err := makeWebhookSink(srv.sinkURL(t), webhookSinkTLSConfig{}, webhookOpts(map[string]string{
+			changefeedbase.OptWebhookSinkConfig: `{"Retry": {"Max": 0}}`,
+		}))
+		require.NoError(t, err)
+		err = s.EmitRow(ctx, nil, nil, []byte(`{"after":{"a":1}}`), zeroTS)
+		require.Regexp(t, `x509: certificate signed by unknown authority`, err)
+		require.NoError(t, s.Close())
+
+		s = makeSink(t, srv, webhookSinkTLSConfig{tlsSkipVerify: true}, webhookOpts(nil))
+		require.NoError(t, s.EmitRow(ctx, nil, nil, []byte(`{"after":{"a":2}}`), zeroTS))
+		require.NoError(t, s.Close())
+		require.Equal(t, []string{
+			`{"payload":[{"after":{"a":2}}],"length":1}`,
+		}, srv.popBodies())
+
+		_, err = makeWebhookSink(srv.sinkURL(t), webhookSinkTLSConfig{caCert: []byte(`foo`)}, webhookOpts(nil))
+		require.EqualError(t, err, `invalid ca_cert: no certificates found`)
+	})
+
+	t.Run("client cert", func(t *testing.T) {
+		srv := makeWebhookTestServer(t, tls.RequireAnyClientCert)
+		defer srv.Close()
+
+		clientCert, err := securitytest.Asset(filepath.Join(security.EmbeddedCertsDir, security.EmbeddedRootCert))
+		require.NoError(t, err)
+		clientKey, err := securitytest.Asset(filepath.Join(security.EmbeddedCertsDir, security.EmbeddedRootKey))
+		require.NoError(t, err)
+
+		s := makeSink(t, srv, webhookSinkTLSConfig{}, webhookOpts(map[string]string{
+			changefeedbase.OptWebhookSinkConfig: `{"Retry": {"Max": 0}}`,
+		}))
+		err = s.EmitRow(ctx, nil, nil, []byte(`{"after":{"a":1}}`), zeroTS)
+		require.Error(t, err)
+		require.NoError(t, s.Close())
+
+		s = makeSink(t, srv, webhookSinkTLSConfig{clientCert: clientCert, clientKey: clientKey}, webhookOpts(nil))
+		require.NoError(t, s.EmitRow(ctx, nil, nil, []byte(`{"after":{"a":2}}`), zeroTS))
+		require.NoError(t, s.Close())
+		require.Equal(t, []string{
+			`{"payload":[{"after":{"a":2}}],"length":1}`,
+		}, srv.popBodies())
+
+		_, err = makeWebhookSink(srv.sinkURL(t), webhookSinkTLSConfig{clientCert: clientCert}, webhookOpts(nil))
+		require.EqualError(t, err, `client_cert requires client_key to be set`)
+		_, err = makeWebhookSink(srv.sinkURL(t), webhookSinkTLSConfig{clientKey: clientKey}, webhookOpts(nil))
+		require.EqualError(t, err, `client_key requires client_cert to be set`)
+	})
+
+	t.Run("options", func(t *testing.T) {
+		u := &url.URL{Scheme: changefeedbase.SinkSchemeWebhookHTTPS, Host: `localhost`}
+		for _, tc := range []struct {
+			opts map[string]string
+			err  string
+		}{
+			{
+				opts: map[string]string{changefeedbase.OptFormat: `experimental_avro`},
+				err:  `this sink is incompatible with format=experimental_avro`,
+			},
+			{
+				opts: map[string]string{changefeedbase.OptEnvelope: `key_only`},
+				err:  `this sink is incompatible with envelope=key_only`,
+			},
+			{
+				opts: map[string]string{changefeedbase.OptWebhookSinkConfig: `{"Flush": {"Messages": -1}}`},
+				err:  `invalid webhook_sink_config: flush thresholds cannot be negative`,
+			},
+			{
+				opts: map[string]string{changefeedbase.OptWebhookSinkConfig: `{"Retry": {"Backoff": "-1s"}}`},
+				err:  `invalid webhook_sink_config: retry settings cannot be negative`,
+			},
+			{
+				opts: map[string]string{changefeedbase.OptWebhookSinkConfig: `{"Flush": {"Frequency": "soon"}}`},
+				err:  `parsing webhook_sink_config: time: invalid duration "?soon"?`,
+			},
+			{
+				opts: map[string]string{changefeedbase.OptWebhookSinkConfig: `{"Batch": {}}`},
+				err:  `parsing webhook_sink_config: json: unknown field "Batch"`,
+			},
+			{
+				opts: map[string]string{changefeedbase.OptWebhookClientTimeout: `0s`},
+				err:  `webhook_client_timeout must be positive: 0s`,
+			},
+			{
+				opts: map[string]string{changefeedbase.OptWebhookClientTimeout: `x`},
+				err:  `parsing webhook_client_timeout: time: invalid duration "?x"?`,
+			},
+		} {
+			_, err := makeWebhookSink(u, webhookSinkTLSConfig{}, webhookOpts(tc.opts))
+			require.Regexp(t, tc.err, err)
+		}
+
+		opts := webhookOpts(nil)
+		delete(opts, changefeedbase.OptTopicInValue)
+		_, err := makeWebhookSink(u, webhookSinkTLSConfig{}, opts)
+		require.EqualError(t, err, `this sink requires the WITH topic_in_value option`)
+	})
+}
@@ -47,33 +47,46 @@ func GetAllRevisions(
 
 	var res []VersionedValues
 	for _, file := range resp.(*roachpb.ExportResponse).Files {
-		iter, err := storage.NewMemSSTIterator(file.SST, false)
+		revisions, err := GetAllRevisionsFromSST(file.SST, startKey, endKey)
 		if err != nil {
 			return nil, err
 		}
-		defer iter.Close()
-		iter.SeekGE(storage.MVCCKey{Key: startKey})
+		res = append(res, revisions...)
+	}
+	return res, nil
+}
 
-		for ; ; iter.Next() {
-			if valid, err := iter.Valid(); !valid || err != nil {
-				if err != nil {
-					return nil, err
-				}
-				break
-			} else if iter.UnsafeKey().Key.Compare(endKey) >= 0 {
-				break
-			}
-			key := iter.UnsafeKey()
-			keyCopy := make([]byte, len(key.Key))
-			copy(keyCopy, key.Key)
-			key.Key = keyCopy
-			value := make([]byte, len(iter.UnsafeValue()))
-			copy(value, iter.UnsafeValue())
-			if len(res) == 0 || !res[len(res)-1].Key.Equal(key.Key) {
-				res = append(res, VersionedValues{Key: key.Key})
+// GetAllRevisionsFromSST returns all the revisions of the keys between
+// startKey and endKey stored in the given SST, such as the files of a backup
+// taken with revision history. The revisions of each key are ordered from the
+// newest to the oldest, and deletions are revisions with an empty value.
+func GetAllRevisionsFromSST(sst []byte, startKey, endKey roachpb.Key) ([]VersionedValues, error) {
+	iter, err := storage.NewMemSSTIterator(sst, false)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var res []VersionedValues
+	for iter.SeekGE(storage.MVCCKey{Key: startKey}); ; iter.Next() {
+		if valid, err := iter.Valid(); !valid || err != nil {
+			if err != nil {
+				return nil, err
 			}
-			res[len(res)-1].Values = append(res[len(res)-1].Values, roachpb.Value{Timestamp: key.Timestamp, RawBytes: value})
+			break
+		} else if iter.UnsafeKey().Key.Compare(endKey) >= 0 {
+			break
+		}
+		key := iter.UnsafeKey()
+		keyCopy := make([]byte, len(key.Key))
+		copy(keyCopy, key.Key)
+		key.Key = keyCopy
+		value := make([]byte, len(iter.UnsafeValue()))
+		copy(value, iter.UnsafeValue())
+		if len(res) == 0 || !res[len(res)-1].Key.Equal(key.Key) {
+			res = append(res, VersionedValues{Key: key.Key})
 		}
+		res[len(res)-1].Values = append(res[len(res)-1].Values, roachpb.Value{Timestamp: key.Timestamp, RawBytes: value})
 	}
 	return res, nil
 }
@@ -222,6 +222,34 @@ const (
 	// using the replicated legacy TruncatedState. It's also used in asserting
 	// that no replicated truncated state representation is found.
 	PostTruncatedAndRangeAppliedStateMigration
+	// NotificationsTable adds the system.notifications table, which backs
+	// LISTEN and NOTIFY.
+	NotificationsTable
+	// CompositeTypes enables the creation of user defined composite types.
+	CompositeTypes
+	// Domains enables the creation of domains.
+	Domains
+	// ExclusionConstraints enables the creation of EXCLUDE constraints.
+	ExclusionConstraints
+	// UserDefinedFunctions enables the creation of user-defined functions.
+	UserDefinedFunctions
+	// RowLevelTriggers enables the creation of row-level triggers.
+	RowLevelTriggers
+	// DeferrableConstraints enables the creation of deferrable foreign key and
+	// unique constraints.
+	DeferrableConstraints
+	// ChangefeedAvroFormat is when changefeeds persist their avro format as
+	// `avro` instead of `experimental_avro`.
+	ChangefeedAvroFormat
+	// ChangefeedCSVAndProtobufFormats enables changefeeds with the `csv` and
+	// `protobuf` formats.
+	ChangefeedCSVAndProtobufFormats
+	// ChangefeedQueries enables CREATE CHANGEFEED ... AS SELECT.
+	ChangefeedQueries
+	// ChangefeedEndTime enables the `end_time` option of changefeeds.
+	ChangefeedEndTime
+	// BackupCompaction enables COMPACT BACKUP and its job.
+	BackupCompaction
 
 	// Step (1): Add new versions here.
 )
@@ -368,6 +396,54 @@ var versionsSingleton = keyedVersions([]keyedVersion{
 		Key:     PostTruncatedAndRangeAppliedStateMigration,
 		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 16},
 	},
+	{
+		Key:     NotificationsTable,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 18},
+	},
+	{
+		Key:     CompositeTypes,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 20},
+	},
+	{
+		Key:     Domains,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 22},
+	},
+	{
+		Key:     ExclusionConstraints,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 24},
+	},
+	{
+		Key:     UserDefinedFunctions,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 26},
+	},
+	{
+		Key:     RowLevelTriggers,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 28},
+	},
+	{
+		Key:     DeferrableConstraints,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 30},
+	},
+	{
+		Key:     ChangefeedAvroFormat,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 32},
+	},
+	{
+		Key:     ChangefeedCSVAndProtobufFormats,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 34},
+	},
+	{
+		Key:     ChangefeedQueries,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 36},
+	},
+	{
+		Key:     ChangefeedEndTime,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 38},
+	},
+	{
+		Key:     BackupCompaction,
+		Version: roachpb.Version{Major: 20, Minor: 2, Internal: 40},
+	},
 
 	// Step (2): Add new versions here.
 })
@@ -520,6 +520,11 @@ var specs = []stmtSpec{
 		inline: []string{"opt_transaction"},
 		match:  []*regexp.Regexp{regexp.MustCompile("'COMMIT'|'END'")},
 	},
+	{
+		name:   "compact_backup",
+		stmt:   "compact_backup_stmt",
+		inline: []string{"opt_with_options"},
+	},
 	{
 		name:    "cancel_job",
 		stmt:    "cancel_jobs_stmt",
@@ -554,7 +559,7 @@ var specs = []stmtSpec{
 	},
 	{
 		name:   "create_changefeed_stmt",
-		inline: []string{"changefeed_targets", "single_table_pattern_list", "opt_changefeed_sink", "opt_with_options", "kv_option_list", "kv_option"},
+		inline: []string{"changefeed_targets", "changefeed_target_list", "opt_changefeed_sink", "opt_with_options", "kv_option_list", "kv_option"},
 		replace: map[string]string{
 			"table_option":                 "table_name",
 			"'INTO' string_or_placeholder": "'INTO' sink",
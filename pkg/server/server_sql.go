@@ -48,6 +48,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/gcjob/gcjobnotifier"
+	"github.com/cockroachdb/cockroach/pkg/sql/notify"
 	"github.com/cockroachdb/cockroach/pkg/sql/optionalnodeliveness"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
 	"github.com/cockroachdb/cockroach/pkg/sql/querycache"
@@ -314,6 +315,16 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*sqlServer, error) {
 
 	backfillMemoryMonitor := execinfra.NewMonitor(ctx, bulkMemoryMonitor, "backfill-mon")
 
+	// changefeedMemoryMonitor is the parent to the monitors of the buffers of
+	// all of the changefeeds running on this node. Its limit follows the
+	// changefeed.memory.limit setting.
+	changefeedMemoryMonitor := mon.NewMonitorInheritWithLimit(
+		"changefeed-mon", execinfra.ChangefeedMemLimit.Get(&cfg.Settings.SV), rootSQLMemoryMonitor)
+	changefeedMemoryMonitor.Start(ctx, rootSQLMemoryMonitor, mon.BoundAccount{})
+	execinfra.ChangefeedMemLimit.SetOnChange(&cfg.Settings.SV, func() {
+		changefeedMemoryMonitor.SetLimit(execinfra.ChangefeedMemLimit.Get(&cfg.Settings.SV))
+	})
+
 	// Set up the DistSQL temp engine.
 
 	useStoreSpec := cfg.TempStorageConfig.Spec
@@ -376,6 +387,7 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*sqlServer, error) {
 		VecFDSemaphore:    semaphore.New(envutil.EnvOrDefaultInt("COCKROACH_VEC_MAX_OPEN_FDS", colexec.VecMaxOpenFDsLimit)),
 		DiskMonitor:       cfg.TempStorageConfig.Mon,
 		BackfillerMonitor: backfillMemoryMonitor,
+		ChangefeedMonitor: changefeedMemoryMonitor,
 
 		ParentMemoryMonitor: rootSQLMemoryMonitor,
 		BulkAdder: func(
@@ -595,6 +607,9 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*sqlServer, error) {
 		ctx, pgServer.SQLServer, internalMemMetrics, cfg.Settings,
 	)
 	execCfg.InternalExecutor = cfg.circularInternalExecutor
+	execCfg.NotificationRegistry = notify.NewRegistry(
+		cfg.distSender, cfg.circularInternalExecutor, codec, cfg.clock, cfg.Settings, cfg.stopper,
+	)
 	stmtDiagnosticsRegistry := stmtdiagnostics.NewRegistry(
 		cfg.circularInternalExecutor,
 		cfg.db,
@@ -763,6 +778,10 @@ func (s *sqlServer) preStart(
 
 	log.Infof(ctx, "done ensuring all necessary startup migrations have run")
 
+	// Start watching for notifications. system.notifications may have been
+	// created by the migrations above.
+	s.execCfg.NotificationRegistry.Start(ctx)
+
 	// Start the sqlLivenessProvider after we've run the SQL migrations that it
 	// relies on. Jobs used by sqlmigrations can't rely on having the
 	// sqlLivenessProvider running as it was introduced in 20.2.
@@ -173,6 +173,14 @@ func NonNegativeDuration(v time.Duration) error {
 	return nil
 }
 
+// PositiveDuration can be passed to RegisterDurationSetting.
+func PositiveDuration(v time.Duration) error {
+	if v <= 0 {
+		return errors.Errorf("cannot be set to a non-positive duration: %s", v)
+	}
+	return nil
+}
+
 // NonNegativeDurationWithMaximum can be passed to RegisterDurationSetting.
 func NonNegativeDurationWithMaximum(maxValue time.Duration) func(time.Duration) error {
 	return func(v time.Duration) error {
@@ -36,6 +36,14 @@ func (p *planner) addColumnImpl(
 	if err != nil {
 		return err
 	}
+	// The existing rows would neither get the default of the domain nor be
+	// checked against its constraints.
+	if domain := toType.TypeMeta.DomainData; domain != nil &&
+		(domain.NotNull || len(domain.Checks) > 0 || domain.DefaultExpr != nil) {
+		return unimplemented.NewWithIssueDetailf(27796, "add-column-domain-constraints",
+			"adding a column of domain %s with constraints or a default is not supported",
+			toType.SQLString())
+	}
 	if supported, err := isTypeSupportedInVersion(version, toType); err != nil {
 		return err
 	} else if !supported {
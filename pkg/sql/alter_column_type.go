@@ -76,11 +76,23 @@ func AlterColumnType(
 			)
 		}
 	}
+	for i := range tableDesc.Triggers {
+		if triggerDependsOn(&tableDesc.Triggers[i], tableDesc.ID, col.ID) {
+			return dependentTriggerError("column", col.Name, tableDesc.Name, "alter type of")
+		}
+	}
 
 	typ, err := tree.ResolveType(ctx, t.ToType, params.p.semaCtx.GetTypeResolver())
 	if err != nil {
 		return err
 	}
+	// The existing values of the column would not be checked against the
+	// constraints of the domain.
+	if domain := typ.TypeMeta.DomainData; domain != nil && (domain.NotNull || len(domain.Checks) > 0) {
+		return unimplemented.NewWithIssueDetailf(27796, "alter-column-type-domain-constraints",
+			"altering the type of a column to domain %s with constraints is not supported",
+			typ.SQLString())
+	}
 
 	version := params.ExecCfg().Settings.Version.ActiveVersionOrEmpty(params.ctx)
 	if supported, err := isTypeSupportedInVersion(version, typ); err != nil {
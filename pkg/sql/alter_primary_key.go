@@ -50,6 +50,14 @@ func (p *planner) AlterPrimaryKey(
 		}
 	}
 
+	// Changing the primary key rewrites all secondary indexes, including the
+	// indexes that back exclusion constraints.
+	if len(tableDesc.ExclusionConstraints) > 0 {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"cannot change the primary key of table %q because it has exclusion constraints",
+			tableDesc.Name)
+	}
+
 	// Ensure that other schema changes on this table are not currently
 	// executing, and that other schema changes have not been performed
 	// in the current transaction.
@@ -35,6 +35,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/errors"
@@ -181,6 +182,9 @@ func (n *alterTableNode) startExec(params runParams) error {
 						"unique constraints without an index are not yet supported",
 					)
 				}
+				if d.Deferrability != tree.ConstraintNotDeferrable {
+					return errDeferrableUniqueIndex
+				}
 				if d.PrimaryKey {
 					// We only support "adding" a primary key when we are using the
 					// default rowid primary index or if a DROP PRIMARY KEY statement
@@ -336,6 +340,51 @@ func (n *alterTableNode) startExec(params runParams) error {
 				// 	return err
 				// }
 
+			case *tree.ExclusionConstraintTableDef:
+				// The constraint is enforced as soon as it is added, before the
+				// nodes which still use the previous version of the table learn
+				// about it, and existing rows are not validated against it. So it
+				// can only be added to an empty table created in the same
+				// transaction.
+				if !n.tableDesc.IsNew() {
+					return unimplemented.NewWithIssueDetail(46657, "add-constraint-exclude-existing-table",
+						"EXCLUDE constraints can only be added to tables created in the same transaction")
+				}
+				span := n.tableDesc.PrimaryIndexSpan(params.ExecCfg().Codec)
+				kvs, err := params.p.txn.Scan(params.ctx, span.Key, span.EndKey, 1)
+				if err != nil {
+					return err
+				}
+				if len(kvs) > 0 {
+					return unimplemented.NewWithIssueDetail(46657, "add-constraint-exclude-non-empty",
+						"EXCLUDE constraints can only be added to empty tables")
+				}
+				if d.Name != "" {
+					if info, err := n.tableDesc.GetConstraintInfo(params.ctx, nil); err != nil {
+						return err
+					} else if _, ok := info[string(d.Name)]; ok {
+						return pgerror.Newf(pgcode.DuplicateObject,
+							"duplicate constraint name: %q", d.Name)
+					}
+					if err := n.tableDesc.ValidateIndexNameIsUnique(string(d.Name)); err != nil {
+						return err
+					}
+				}
+				// The cluster version that enables exclusion constraints implies
+				// that the latest index encoding is available.
+				ec, idx, err := makeExclusionConstraint(
+					params.ctx, params.ExecCfg().Settings, n.tableDesc, d, descpb.EmptyArraysInInvertedIndexesVersion,
+				)
+				if err != nil {
+					return err
+				}
+				if err := n.tableDesc.AddIndexMutation(&idx, descpb.DescriptorMutation_ADD); err != nil {
+					return err
+				}
+				// The IndexID of the constraint is set once the ID of its index has
+				// been allocated below.
+				n.tableDesc.ExclusionConstraints = append(n.tableDesc.ExclusionConstraints, ec)
+
 			default:
 				return errors.AssertionFailedf(
 					"unsupported constraint: %T", t.ConstraintDef)
@@ -394,7 +443,9 @@ func (n *alterTableNode) startExec(params runParams) error {
 
 			// You can't drop a column depended on by a view unless CASCADE was
 			// specified.
-			for _, ref := range n.tableDesc.DependedOnBy {
+			// Copy out the set of dependencies as it may be overwritten in the loop.
+			dependedOnBy := append([]descpb.TableDescriptor_Reference(nil), n.tableDesc.DependedOnBy...)
+			for _, ref := range dependedOnBy {
 				found := false
 				for _, colID := range ref.ColumnIDs {
 					if colID == colToDrop.ID {
@@ -411,6 +462,15 @@ func (n *alterTableNode) startExec(params runParams) error {
 				if err != nil {
 					return err
 				}
+				if isTable, err := params.p.removeDependentTriggers(
+					params.ctx, ref.ID, n.tableDesc.ID, colToDrop.ID,
+					fmt.Sprintf("removing triggers dependent on column %q which is being dropped",
+						colToDrop.ColName()),
+				); err != nil {
+					return err
+				} else if isTable {
+					continue
+				}
 				viewDesc, err := params.p.getViewDescForCascade(
 					params.ctx, "column", string(t.Column), n.tableDesc.ParentID, ref.ID, t.DropBehavior,
 				)
@@ -425,6 +485,24 @@ func (n *alterTableNode) startExec(params runParams) error {
 				}
 			}
 
+			// You can't drop a column that a trigger of this table refers to
+			// unless CASCADE was specified.
+			for i := range n.tableDesc.Triggers {
+				if triggerDependsOn(&n.tableDesc.Triggers[i], n.tableDesc.ID, colToDrop.ID) &&
+					t.DropBehavior != tree.DropCascade {
+					return dependentTriggerError("column", string(t.Column), n.tableDesc.Name, "drop")
+				}
+			}
+			if oldDeps := removeTriggersDependingOn(
+				n.tableDesc, n.tableDesc.ID, colToDrop.ID,
+			); len(oldDeps) > 0 {
+				if err := params.p.updateTriggerBackRefs(
+					params.ctx, n.tableDesc, oldDeps, tree.AsStringWithFQNames(n.n, params.Ann()),
+				); err != nil {
+					return err
+				}
+			}
+
 			// We cannot remove this column if there are computed columns that use it.
 			computedColValidator := schemaexpr.MakeComputedColumnValidator(
 				params.ctx,
@@ -502,6 +580,19 @@ func (n *alterTableNode) startExec(params runParams) error {
 					}
 				}
 
+				// If the index backs an exclusion constraint that references the
+				// column being dropped, then the index should be dropped along with
+				// the constraint.
+				if !containsThisColumn {
+					for i := range n.tableDesc.ExclusionConstraints {
+						ec := &n.tableDesc.ExclusionConstraints[i]
+						if ec.IndexID == idx.ID && descpb.ColumnIDs(ec.ColumnIDs).Contains(colToDrop.ID) {
+							containsThisColumn = true
+							break
+						}
+					}
+				}
+
 				// Perform the DROP.
 				if containsThisColumn {
 					idxNamesToDelete = append(idxNamesToDelete, idx.Name)
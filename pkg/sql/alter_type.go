@@ -267,6 +267,9 @@ func (p *planner) performRenameTypeDesc(
 func (p *planner) renameTypeValue(
 	ctx context.Context, n *alterTypeNode, oldVal string, newVal string,
 ) error {
+	if n.desc.Kind != descpb.TypeDescriptor_ENUM {
+		return pgerror.Newf(pgcode.WrongObjectType, "%q is not an enum", n.desc.Name)
+	}
 	enumMemberIndex := -1
 
 	// Do one pass to verify that the oldVal exists and there isn't already
@@ -1851,7 +1851,7 @@ func validateFkInTxn(
 		return errors.AssertionFailedf("foreign key %s does not exist", fkName)
 	}
 
-	return validateForeignKey(ctx, tableDesc, fk, ie, txn, evalCtx.Codec)
+	return validateForeignKey(ctx, tableDesc, fk, ie, txn, evalCtx.Codec, nil /* keys */)
 }
 
 // columnBackfillInTxn backfills columns for all mutation columns in
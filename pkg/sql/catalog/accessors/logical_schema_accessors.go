@@ -176,6 +176,13 @@ func (l *LogicalSchemaAccessor) GetObjectDesc(
 		} else {
 			found, desc, err = l.tc.GetImmutableTableByName(ctx, txn, &tableName, flags)
 		}
+	case tree.FunctionObject:
+		fnName := tree.MakeNewQualifiedFunctionName(db, schema, object)
+		if flags.RequireMutable {
+			found, desc, err = l.tc.GetMutableFunctionByName(ctx, txn, &fnName, flags)
+		} else {
+			found, desc, err = l.tc.GetImmutableFunctionByName(ctx, txn, &fnName, flags)
+		}
 	default:
 		return nil, errors.AssertionFailedf("unknown desired object kind %d", flags.DesiredObjectKind)
 	}
@@ -18,6 +18,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/errors"
 	"github.com/lib/pq/oid"
 	"golang.org/x/text/language"
@@ -95,8 +96,25 @@ func ValidateColumnDefType(t *types.T) error {
 		if err := types.CheckArrayElementType(t.ArrayContents()); err != nil {
 			return err
 		}
+		if t.ArrayContents().Family() == types.TupleFamily {
+			return unimplemented.NewWithIssueDetailf(27792, "composite-array",
+				"arrays of composite types unsupported as column type: %s", t.String())
+		}
 		return ValidateColumnDefType(t.ArrayContents())
 
+	case types.TupleFamily:
+		// Anonymous tuple types can't be used for table columns, but user defined
+		// composite types can.
+		if !t.UserDefined() {
+			return pgerror.Newf(pgcode.InvalidTableDefinition,
+				"value type %s cannot be used for table columns", t.String())
+		}
+		for _, contents := range t.TupleContents() {
+			if err := ValidateColumnDefType(contents); err != nil {
+				return err
+			}
+		}
+
 	case types.BitFamily, types.IntFamily, types.FloatFamily, types.BoolFamily, types.BytesFamily, types.DateFamily,
 		types.INetFamily, types.IntervalFamily, types.JsonFamily, types.OidFamily, types.TimeFamily,
 		types.TimestampFamily, types.TimestampTZFamily, types.UuidFamily, types.TimeTZFamily,
@@ -14,6 +14,7 @@ import (
 	"strconv"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/errors"
 )
 
 // CompositeKeyMatchMethodValue allows the conversion from a
@@ -83,6 +84,23 @@ func (x ForeignKeyReference_Action) String() string {
 	}
 }
 
+// ConstraintDeferrabilityValue allows the conversion from a
+// tree.ConstraintDeferrability to a ConstraintDeferrability.
+var ConstraintDeferrabilityValue = [...]ConstraintDeferrability{
+	tree.ConstraintNotDeferrable:      ConstraintDeferrability_NotDeferrable,
+	tree.ConstraintInitiallyImmediate: ConstraintDeferrability_InitiallyImmediate,
+	tree.ConstraintInitiallyDeferred:  ConstraintDeferrability_InitiallyDeferred,
+}
+
+// ConstraintDeferrabilityType allows the conversion from a
+// ConstraintDeferrability to a tree.ConstraintDeferrability. This should match
+// ConstraintDeferrabilityValue.
+var ConstraintDeferrabilityType = [...]tree.ConstraintDeferrability{
+	ConstraintDeferrability_NotDeferrable:      tree.ConstraintNotDeferrable,
+	ConstraintDeferrability_InitiallyImmediate: tree.ConstraintInitiallyImmediate,
+	ConstraintDeferrability_InitiallyDeferred:  tree.ConstraintInitiallyDeferred,
+}
+
 // ConstraintType is used to identify the type of a constraint.
 type ConstraintType string
 
@@ -95,6 +113,8 @@ const (
 	ConstraintTypeUnique ConstraintType = "UNIQUE"
 	// ConstraintTypeCheck identifies a CHECK constraint.
 	ConstraintTypeCheck ConstraintType = "CHECK"
+	// ConstraintTypeExclusion identifies an EXCLUDE constraint.
+	ConstraintTypeExclusion ConstraintType = "EXCLUDE"
 )
 
 // ConstraintDetail describes a constraint.
@@ -107,7 +127,8 @@ type ConstraintDetail struct {
 	Details     string
 	Unvalidated bool
 
-	// Only populated for PK and Unique Constraints with an index.
+	// Only populated for PK and Unique Constraints with an index, and for
+	// Exclusion Constraints.
 	Index *IndexDescriptor
 
 	// Only populated for Unique Constraints without an index.
@@ -119,4 +140,36 @@ type ConstraintDetail struct {
 
 	// Only populated for Check Constraints.
 	CheckConstraint *TableDescriptor_CheckConstraint
+
+	// Only populated for Exclusion Constraints.
+	ExclusionConstraint *ExclusionConstraint
+}
+
+// Deferrability returns the deferrability of the constraint. Only foreign key
+// constraints and unique constraints without an index can be deferrable.
+func (c ConstraintDetail) Deferrability() ConstraintDeferrability {
+	switch {
+	case c.FK != nil:
+		return c.FK.Deferrability
+	case c.UniqueWithoutIndexConstraint != nil:
+		return c.UniqueWithoutIndexConstraint.Deferrability
+	}
+	return ConstraintDeferrability_NotDeferrable
+}
+
+// ExclusionOperators are the comparison operators that can be used in an
+// exclusion constraint. The Operators of an ExclusionConstraint are the string
+// representations of these operators.
+var ExclusionOperators = [...]tree.ComparisonOperator{tree.EQ, tree.NE, tree.Overlaps}
+
+// Operator returns the comparison operator used to compare the ith column of
+// the constraint.
+func (e *ExclusionConstraint) Operator(i int) (tree.ComparisonOperator, error) {
+	for _, op := range ExclusionOperators {
+		if op.String() == e.Operators[i] {
+			return op, nil
+		}
+	}
+	return 0, errors.AssertionFailedf(
+		"exclusion constraint %q contains invalid operator %q", e.Name, e.Operators[i])
 }
@@ -0,0 +1,278 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package funcdesc
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/redact"
+)
+
+var _ catalog.FunctionDescriptor = (*Immutable)(nil)
+var _ catalog.FunctionDescriptor = (*Mutable)(nil)
+var _ catalog.MutableDescriptor = (*Mutable)(nil)
+
+// Immutable wraps a Function descriptor and provides methods on it.
+type Immutable struct {
+	descpb.FunctionDescriptor
+
+	// isUncommittedVersion is set to true if this descriptor was created from
+	// a copy of a Mutable with an uncommitted version.
+	isUncommittedVersion bool
+}
+
+// SafeMessage makes Immutable a SafeMessager.
+func (desc *Immutable) SafeMessage() string {
+	return formatSafeMessage("funcdesc.Immutable", desc)
+}
+
+// SafeMessage makes Mutable a SafeMessager.
+func (desc *Mutable) SafeMessage() string {
+	return formatSafeMessage("funcdesc.Mutable", desc)
+}
+
+func formatSafeMessage(typeName string, desc catalog.FunctionDescriptor) string {
+	var buf redact.StringBuilder
+	buf.Printf(typeName + ": {")
+	catalog.FormatSafeDescriptorProperties(&buf, desc)
+	buf.Printf(", NumArgs: %d", len(desc.FuncDesc().Args))
+	buf.Printf("}")
+	return buf.String()
+}
+
+// Mutable is a mutable reference to a FunctionDescriptor.
+type Mutable struct {
+	Immutable
+
+	ClusterVersion *Immutable
+}
+
+var _ redact.SafeMessager = (*Immutable)(nil)
+
+// NewMutableExisting returns a Mutable from the given function descriptor with
+// the cluster version also set to the descriptor. This is for functions that
+// already exist.
+func NewMutableExisting(desc descpb.FunctionDescriptor) *Mutable {
+	return &Mutable{
+		Immutable:      makeImmutable(*protoutil.Clone(&desc).(*descpb.FunctionDescriptor)),
+		ClusterVersion: NewImmutable(desc),
+	}
+}
+
+// NewImmutable makes a new Function descriptor.
+func NewImmutable(desc descpb.FunctionDescriptor) *Immutable {
+	m := makeImmutable(desc)
+	return &m
+}
+
+func makeImmutable(desc descpb.FunctionDescriptor) Immutable {
+	return Immutable{FunctionDescriptor: desc}
+}
+
+// NewCreatedMutable returns a Mutable from the given FunctionDescriptor with
+// the cluster version being the zero function. This is for a function that is
+// created within the current transaction.
+func NewCreatedMutable(desc descpb.FunctionDescriptor) *Mutable {
+	return &Mutable{
+		Immutable: makeImmutable(desc),
+	}
+}
+
+// SetDrainingNames implements the MutableDescriptor interface.
+func (desc *Mutable) SetDrainingNames(names []descpb.NameInfo) {
+	desc.DrainingNames = names
+}
+
+// IsUncommittedVersion implements the Descriptor interface.
+func (desc *Immutable) IsUncommittedVersion() bool {
+	return desc.isUncommittedVersion
+}
+
+// GetAuditMode implements the DescriptorProto interface.
+func (desc *Immutable) GetAuditMode() descpb.TableDescriptor_AuditMode {
+	return descpb.TableDescriptor_DISABLED
+}
+
+// TypeName implements the DescriptorProto interface.
+func (desc *Immutable) TypeName() string {
+	return "function"
+}
+
+// FuncDesc implements the FunctionDescriptor interface.
+func (desc *Immutable) FuncDesc() *descpb.FunctionDescriptor {
+	return &desc.FunctionDescriptor
+}
+
+// Public implements the Descriptor interface.
+func (desc *Immutable) Public() bool {
+	return desc.State == descpb.DescriptorState_PUBLIC
+}
+
+// Adding implements the Descriptor interface.
+func (desc *Immutable) Adding() bool {
+	return false
+}
+
+// Offline implements the Descriptor interface.
+func (desc *Immutable) Offline() bool {
+	return desc.State == descpb.DescriptorState_OFFLINE
+}
+
+// Dropped implements the Descriptor interface.
+func (desc *Immutable) Dropped() bool {
+	return desc.State == descpb.DescriptorState_DROP
+}
+
+// DescriptorProto wraps a FunctionDescriptor in a Descriptor.
+func (desc *Immutable) DescriptorProto() *descpb.Descriptor {
+	return &descpb.Descriptor{
+		Union: &descpb.Descriptor_Function{
+			Function: &desc.FunctionDescriptor,
+		},
+	}
+}
+
+// NameResolutionResult implements the ObjectDescriptor interface.
+func (desc *Immutable) NameResolutionResult() {}
+
+// Validate performs validation on the FunctionDescriptor.
+func (desc *Immutable) Validate() error {
+	if err := catalog.ValidateName(desc.Name, "function"); err != nil {
+		return err
+	}
+	if desc.ID == descpb.InvalidID {
+		return errors.AssertionFailedf("invalid ID %d", errors.Safe(desc.ID))
+	}
+	if desc.ParentID == descpb.InvalidID {
+		return errors.AssertionFailedf("invalid parentID %d", errors.Safe(desc.ParentID))
+	}
+	if desc.ParentSchemaID == descpb.InvalidID {
+		return errors.AssertionFailedf("invalid parentSchemaID %d", errors.Safe(desc.ParentSchemaID))
+	}
+	for i := range desc.Args {
+		if desc.Args[i].Type == nil {
+			return errors.AssertionFailedf("argument %d has no type", errors.Safe(i+1))
+		}
+	}
+	if desc.ReturnType == nil {
+		return errors.AssertionFailedf("function has no return type")
+	}
+	if desc.Body == "" {
+		return errors.AssertionFailedf("function has no body")
+	}
+	return desc.Privileges.Validate(desc.ID, privilege.Function)
+}
+
+// MaybeIncrementVersion implements the MutableDescriptor interface.
+func (desc *Mutable) MaybeIncrementVersion() {
+	// Already incremented, no-op.
+	if desc.ClusterVersion == nil || desc.Version == desc.ClusterVersion.Version+1 {
+		return
+	}
+	desc.Version++
+	desc.ModificationTime = hlc.Timestamp{}
+}
+
+// OriginalName implements the MutableDescriptor interface.
+func (desc *Mutable) OriginalName() string {
+	if desc.ClusterVersion == nil {
+		return ""
+	}
+	return desc.ClusterVersion.Name
+}
+
+// OriginalID implements the MutableDescriptor interface.
+func (desc *Mutable) OriginalID() descpb.ID {
+	if desc.ClusterVersion == nil {
+		return descpb.InvalidID
+	}
+	return desc.ClusterVersion.ID
+}
+
+// OriginalVersion implements the MutableDescriptor interface.
+func (desc *Mutable) OriginalVersion() descpb.DescriptorVersion {
+	if desc.ClusterVersion == nil {
+		return 0
+	}
+	return desc.ClusterVersion.Version
+}
+
+// ImmutableCopy implements the MutableDescriptor interface.
+func (desc *Mutable) ImmutableCopy() catalog.Descriptor {
+	imm := NewImmutable(*protoutil.Clone(desc.FuncDesc()).(*descpb.FunctionDescriptor))
+	imm.isUncommittedVersion = desc.IsUncommittedVersion()
+	return imm
+}
+
+// IsNew implements the MutableDescriptor interface.
+func (desc *Mutable) IsNew() bool {
+	return desc.ClusterVersion == nil
+}
+
+// SetPublic implements the MutableDescriptor interface.
+func (desc *Mutable) SetPublic() {
+	desc.State = descpb.DescriptorState_PUBLIC
+	desc.OfflineReason = ""
+}
+
+// SetDropped implements the MutableDescriptor interface.
+func (desc *Mutable) SetDropped() {
+	desc.State = descpb.DescriptorState_DROP
+	desc.OfflineReason = ""
+}
+
+// SetOffline implements the MutableDescriptor interface.
+func (desc *Mutable) SetOffline(reason string) {
+	desc.State = descpb.DescriptorState_OFFLINE
+	desc.OfflineReason = reason
+}
+
+// SetName sets the name of the function. It handles installing a draining
+// name for the old name of the descriptor.
+func (desc *Mutable) SetName(name string) {
+	desc.DrainingNames = append(desc.DrainingNames, descpb.NameInfo{
+		ParentID:       desc.ParentID,
+		ParentSchemaID: desc.ParentSchemaID,
+		Name:           desc.Name,
+	})
+	desc.Name = name
+}
+
+// AddDependedOnBy records that the view or function with the given ID depends
+// on this function.
+func (desc *Mutable) AddDependedOnBy(id descpb.ID) {
+	for _, existing := range desc.DependedOnBy {
+		if existing == id {
+			return
+		}
+	}
+	desc.DependedOnBy = append(desc.DependedOnBy, id)
+}
+
+// RemoveDependedOnBy removes the back-reference to the view or function with
+// the given ID.
+func (desc *Mutable) RemoveDependedOnBy(id descpb.ID) {
+	for i := range desc.DependedOnBy {
+		if desc.DependedOnBy[i] == id {
+			desc.DependedOnBy = append(desc.DependedOnBy[:i], desc.DependedOnBy[i+1:]...)
+			return
+		}
+	}
+}
+
+// IsUncommittedVersion implements the Descriptor interface.
+func (desc *Mutable) IsUncommittedVersion() bool {
+	return desc.IsNew() || desc.GetVersion() != desc.ClusterVersion.GetVersion()
+}
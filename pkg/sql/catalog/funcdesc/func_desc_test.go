@@ -0,0 +1,105 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package funcdesc_test
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/redact"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestSafeMessage(t *testing.T) {
+	for _, tc := range []struct {
+		desc catalog.FunctionDescriptor
+		exp  string
+	}{
+		{
+			desc: funcdesc.NewImmutable(descpb.FunctionDescriptor{
+				ID:             12,
+				Version:        1,
+				ParentID:       2,
+				ParentSchemaID: 29,
+				State:          descpb.DescriptorState_OFFLINE,
+				OfflineReason:  "foo",
+				Args:           []descpb.FunctionDescriptor_Argument{{Name: "a", Type: types.Int}},
+			}),
+			exp: "funcdesc.Immutable: {ID: 12, Version: 1, ModificationTime: \"0,0\", ParentID: 2, ParentSchemaID: 29, State: OFFLINE, OfflineReason: \"foo\", NumArgs: 1}",
+		},
+		{
+			desc: funcdesc.NewCreatedMutable(descpb.FunctionDescriptor{
+				ID:             42,
+				Version:        1,
+				ParentID:       2,
+				ParentSchemaID: 29,
+			}),
+			exp: "funcdesc.Mutable: {ID: 42, Version: 1, IsUncommitted: true, ModificationTime: \"0,0\", ParentID: 2, ParentSchemaID: 29, State: PUBLIC, NumArgs: 0}",
+		},
+	} {
+		t.Run("", func(t *testing.T) {
+			redacted := string(redact.Sprint(tc.desc).Redact())
+			require.Equal(t, tc.exp, redacted)
+			{
+				var m map[string]interface{}
+				require.NoError(t, yaml.UnmarshalStrict([]byte(redacted), &m))
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() descpb.FunctionDescriptor {
+		return descpb.FunctionDescriptor{
+			Name:           "f",
+			ID:             52,
+			ParentID:       50,
+			ParentSchemaID: 29,
+			Version:        1,
+			Privileges:     descpb.NewDefaultPrivilegeDescriptor(security.RootUserName()),
+			Args:           []descpb.FunctionDescriptor_Argument{{Name: "a", Type: types.Int}},
+			ReturnType:     types.Int,
+			Body:           "SELECT a + 1",
+		}
+	}
+	for _, tc := range []struct {
+		mutate func(desc *descpb.FunctionDescriptor)
+		err    string
+	}{
+		{func(desc *descpb.FunctionDescriptor) {}, ""},
+		{func(desc *descpb.FunctionDescriptor) { desc.Name = "" }, `empty function name`},
+		{func(desc *descpb.FunctionDescriptor) { desc.ID = 0 }, `invalid ID 0`},
+		{func(desc *descpb.FunctionDescriptor) { desc.ParentID = 0 }, `invalid parentID 0`},
+		{func(desc *descpb.FunctionDescriptor) { desc.ParentSchemaID = 0 }, `invalid parentSchemaID 0`},
+		{func(desc *descpb.FunctionDescriptor) { desc.Args[0].Type = nil }, `argument 1 has no type`},
+		{func(desc *descpb.FunctionDescriptor) { desc.ReturnType = nil }, `function has no return type`},
+		{func(desc *descpb.FunctionDescriptor) { desc.Body = "" }, `function has no body`},
+		{func(desc *descpb.FunctionDescriptor) {
+			desc.Privileges.Grant(security.TestUserName(), privilege.List{privilege.USAGE})
+		}, `user testuser must not have USAGE privileges on system function with ID=52`},
+	} {
+		t.Run("", func(t *testing.T) {
+			desc := valid()
+			tc.mutate(&desc)
+			err := funcdesc.NewImmutable(desc).Validate()
+			if !testutils.IsError(err, tc.err) {
+				t.Errorf("expected %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
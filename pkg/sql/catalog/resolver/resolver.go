@@ -19,6 +19,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkeys"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catconstants"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
@@ -143,6 +144,24 @@ func ResolveMutableType(
 	return &tn, desc.(*typedesc.Mutable), nil
 }
 
+// ResolveMutableFunction resolves a user defined function descriptor for
+// mutable access. It returns the resolved descriptor, as well as the fully
+// qualified resolved function name.
+func ResolveMutableFunction(
+	ctx context.Context, sc SchemaResolver, un *tree.UnresolvedObjectName, required bool,
+) (*tree.FunctionName, *funcdesc.Mutable, error) {
+	lookupFlags := tree.ObjectLookupFlags{
+		CommonLookupFlags: tree.CommonLookupFlags{Required: required, RequireMutable: true},
+		DesiredObjectKind: tree.FunctionObject,
+	}
+	desc, prefix, err := ResolveExistingObject(ctx, sc, un, lookupFlags)
+	if err != nil || desc == nil {
+		return nil, nil, err
+	}
+	fn := tree.MakeNewQualifiedFunctionName(prefix.Catalog(), prefix.Schema(), un.Object())
+	return &fn, desc.(*funcdesc.Mutable), nil
+}
+
 // ResolveExistingObject resolves an object with the given flags.
 func ResolveExistingObject(
 	ctx context.Context,
@@ -165,6 +184,16 @@ func ResolveExistingObject(
 
 	obj := descI.(catalog.Descriptor)
 	switch lookupFlags.DesiredObjectKind {
+	case tree.FunctionObject:
+		_, isFunction := obj.(catalog.FunctionDescriptor)
+		if !isFunction {
+			fnName := tree.MakeFunctionNameFromPrefix(prefix, tree.Name(un.Object()))
+			return nil, prefix, sqlerrors.NewUndefinedFunctionError(&fnName)
+		}
+		if lookupFlags.RequireMutable {
+			return obj.(*funcdesc.Mutable), prefix, nil
+		}
+		return obj.(*funcdesc.Immutable), prefix, nil
 	case tree.TypeObject:
 		_, isType := obj.(catalog.TypeDescriptor)
 		if !isType {
@@ -0,0 +1,55 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package schemaexpr
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+)
+
+// DomainValueName is the name by which the CHECK constraints of a domain refer
+// to the value being checked.
+const DomainValueName tree.Name = "value"
+
+// ReplaceDomainValue returns a copy of the CHECK constraint expression of a
+// domain in which all references to VALUE are replaced with repl.
+func ReplaceDomainValue(expr tree.Expr, repl tree.Expr) (tree.Expr, error) {
+	return tree.SimpleVisit(expr, func(e tree.Expr) (recurse bool, newExpr tree.Expr, err error) {
+		if n, ok := e.(*tree.UnresolvedName); ok && !n.Star && n.NumParts == 1 &&
+			tree.Name(n.Parts[0]) == DomainValueName {
+			return false, repl, nil
+		}
+		return true, e, nil
+	})
+}
+
+// ValidateDomainCheck verifies that the CHECK constraint expression of a domain
+// over the given base type is valid. The expression must be a boolean
+// expression that refers to no variables other than VALUE, and that has no
+// stable or volatile operators. It returns the serialized expression.
+func ValidateDomainCheck(
+	ctx context.Context, expr tree.Expr, baseType *types.T, semaCtx *tree.SemaContext,
+) (string, error) {
+	// Replace VALUE with a dummyColumn of the base type so that the expression
+	// can be type-checked.
+	replaced, err := ReplaceDomainValue(expr, &dummyColumn{typ: baseType, name: DomainValueName})
+	if err != nil {
+		return "", err
+	}
+	if _, err := SanitizeVarFreeExpr(
+		ctx, replaced, types.Bool, "CHECK", semaCtx, tree.VolatilityImmutable,
+	); err != nil {
+		return "", err
+	}
+	return tree.Serialize(expr), nil
+}
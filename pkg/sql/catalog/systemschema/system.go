@@ -44,6 +44,8 @@ func ShouldSplitAtDesc(rawDesc *roachpb.Value) bool {
 		return false
 	case *descpb.Descriptor_Schema:
 		return false
+	case *descpb.Descriptor_Function:
+		return false
 	default:
 		panic(errors.AssertionFailedf("unexpected descriptor type %#v", &desc))
 	}
@@ -345,6 +347,22 @@ CREATE TABLE system.sqlliveness (
     expiration       DECIMAL NOT NULL,
   	FAMILY fam0_session_id_expiration (session_id, expiration)
 )`
+
+	// NotificationsTableSchema holds the notifications sent with NOTIFY until
+	// they are delivered to the listening sessions by each gateway, which
+	// watches the table with a rangefeed. The notifications sent by a
+	// transaction share the same sent_at, so id orders them. Old notifications
+	// are periodically deleted.
+	NotificationsTableSchema = `
+CREATE TABLE system.notifications (
+    sent_at    TIMESTAMP NOT NULL DEFAULT now(),
+    id         INT8 NOT NULL DEFAULT unique_rowid(),
+    channel    STRING NOT NULL,
+    payload    STRING NOT NULL,
+    node_id    INT8 NOT NULL,
+    PRIMARY KEY (sent_at, id),
+    FAMILY "primary" (sent_at, id, channel, payload, node_id)
+)`
 )
 
 func pk(name string) descpb.IndexDescriptor {
@@ -1690,6 +1708,46 @@ var (
 		FormatVersion:  descpb.InterleavedFormatVersion,
 		NextMutationID: 1,
 	})
+
+	// NotificationsTable is the descriptor for the notifications table.
+	NotificationsTable = tabledesc.NewImmutable(descpb.TableDescriptor{
+		Name:                    "notifications",
+		ID:                      keys.NotificationsTableID,
+		ParentID:                keys.SystemDatabaseID,
+		UnexposedParentSchemaID: keys.PublicSchemaID,
+		Version:                 1,
+		Columns: []descpb.ColumnDescriptor{
+			{Name: "sent_at", ID: 1, Type: types.Timestamp, DefaultExpr: &nowString},
+			{Name: "id", ID: 2, Type: types.Int, DefaultExpr: &uniqueRowIDString},
+			{Name: "channel", ID: 3, Type: types.String},
+			{Name: "payload", ID: 4, Type: types.String},
+			{Name: "node_id", ID: 5, Type: types.Int},
+		},
+		NextColumnID: 6,
+		Families: []descpb.ColumnFamilyDescriptor{
+			{
+				Name:        "primary",
+				ID:          0,
+				ColumnNames: []string{"sent_at", "id", "channel", "payload", "node_id"},
+				ColumnIDs:   []descpb.ColumnID{1, 2, 3, 4, 5},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: descpb.IndexDescriptor{
+			Name:             "primary",
+			ID:               1,
+			Unique:           true,
+			ColumnNames:      []string{"sent_at", "id"},
+			ColumnDirections: []descpb.IndexDescriptor_Direction{descpb.IndexDescriptor_ASC, descpb.IndexDescriptor_ASC},
+			ColumnIDs:        []descpb.ColumnID{1, 2},
+			Version:          descpb.EmptyArraysInInvertedIndexesVersion,
+		},
+		NextIndexID: 2,
+		Privileges: descpb.NewCustomSuperuserPrivilegeDescriptor(
+			descpb.SystemAllowedPrivileges[keys.NotificationsTableID], security.NodeUserName()),
+		FormatVersion:  descpb.InterleavedFormatVersion,
+		NextMutationID: 1,
+	})
 )
 
 // newCommentPrivilegeDescriptor returns a privilege descriptor for comment table
@@ -1279,6 +1279,21 @@ func (desc *Mutable) allocateIndexIDs(columnNames map[string]descpb.ColumnID) er
 			}
 		}
 	}
+
+	// Exclusion constraints that were added along with their backing index
+	// refer to it by name until it has been allocated an ID.
+	for i := range desc.ExclusionConstraints {
+		ec := &desc.ExclusionConstraints[i]
+		if ec.IndexID != 0 {
+			continue
+		}
+		for _, index := range indexes {
+			if index.Name == ec.Name {
+				ec.IndexID = index.ID
+				break
+			}
+		}
+	}
 	return nil
 }
 
@@ -2019,6 +2034,14 @@ func (desc *wrapper) ValidateTable(ctx context.Context) error {
 			return err
 		}
 
+		if err := desc.validateTriggers(); err != nil {
+			return err
+		}
+
+		if err := desc.validateExclusionConstraints(columnIDs); err != nil {
+			return err
+		}
+
 		if err := desc.validateTableIndexes(columnNames); err != nil {
 			return err
 		}
@@ -2260,6 +2283,91 @@ func (desc *wrapper) validateCheckConstraints(
 	return nil
 }
 
+// validateTriggers validates that the triggers have unique, non-empty names
+// and fire for at least one event, each of which appears only once.
+func (desc *wrapper) validateTriggers() error {
+	names := make(map[string]struct{}, len(desc.Triggers))
+	for i := range desc.Triggers {
+		t := &desc.Triggers[i]
+		if err := catalog.ValidateName(t.Name, "trigger"); err != nil {
+			return err
+		}
+		if _, ok := names[t.Name]; ok {
+			return fmt.Errorf("duplicate trigger name: %q", t.Name)
+		}
+		names[t.Name] = struct{}{}
+
+		if len(t.Events) == 0 {
+			return fmt.Errorf("trigger %q does not fire for any event", t.Name)
+		}
+		var seen util.FastIntSet
+		for _, e := range t.Events {
+			if seen.Contains(int(e)) {
+				return fmt.Errorf("trigger %q contains duplicate event %s", t.Name, e)
+			}
+			seen.Add(int(e))
+		}
+	}
+	return nil
+}
+
+// validateExclusionConstraints validates that exclusion constraints are well
+// formed. Checks include validating the names, column IDs and operators of the
+// constraints, and that their backing indexes exist.
+func (desc *wrapper) validateExclusionConstraints(
+	columnIDs map[descpb.ColumnID]*descpb.ColumnDescriptor,
+) error {
+	for i := range desc.ExclusionConstraints {
+		c := &desc.ExclusionConstraints[i]
+		if err := catalog.ValidateName(c.Name, "exclusion constraint"); err != nil {
+			return err
+		}
+
+		if len(c.ColumnIDs) == 0 {
+			return fmt.Errorf("exclusion constraint %q contains no columns", c.Name)
+		}
+		if len(c.Operators) != len(c.ColumnIDs) {
+			return fmt.Errorf(
+				"exclusion constraint %q has %d operators for %d columns",
+				c.Name, len(c.Operators), len(c.ColumnIDs),
+			)
+		}
+
+		var seen util.FastIntSet
+		for j, colID := range c.ColumnIDs {
+			if _, ok := columnIDs[colID]; !ok {
+				return fmt.Errorf(
+					"exclusion constraint %q contains unknown column \"%d\"", c.Name, colID,
+				)
+			}
+			if seen.Contains(int(colID)) {
+				return fmt.Errorf(
+					"exclusion constraint %q contains duplicate column \"%d\"", c.Name, colID,
+				)
+			}
+			seen.Add(int(colID))
+
+			valid := false
+			for _, op := range descpb.ExclusionOperators {
+				if op.String() == c.Operators[j] {
+					valid = true
+					break
+				}
+			}
+			if !valid {
+				return fmt.Errorf(
+					"exclusion constraint %q contains invalid operator %q", c.Name, c.Operators[j],
+				)
+			}
+		}
+
+		if _, err := desc.FindIndexByID(c.IndexID); err != nil {
+			return errors.Wrapf(err, "invalid index for exclusion constraint %q", c.Name)
+		}
+	}
+	return nil
+}
+
 // validateUniqueWithoutIndexConstraints validates that unique without index
 // constraints are well formed. Checks include validating the column IDs and
 // column names.
@@ -3254,6 +3362,11 @@ func (desc *Mutable) DropConstraint(
 		}
 		return errors.AssertionFailedf("constraint %q not found on table %q", name, desc.Name)
 
+	case descpb.ConstraintTypeExclusion:
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"cannot drop EXCLUDE constraint %q using ALTER TABLE DROP CONSTRAINT, use DROP INDEX CASCADE instead",
+			tree.ErrNameString(name))
+
 	default:
 		return unimplemented.Newf(fmt.Sprintf("drop-constraint-%s", detail.Kind),
 			"constraint %q has unsupported type", tree.ErrNameString(name))
@@ -3333,6 +3446,17 @@ func (desc *Mutable) RenameConstraint(
 		detail.CheckConstraint.Name = newName
 		return nil
 
+	case descpb.ConstraintTypeExclusion:
+		// The backing index of the constraint shares its name, so rename it as
+		// well.
+		if detail.Index != nil && detail.Index.Name == oldName {
+			if err := desc.RenameIndexDescriptor(detail.Index, newName); err != nil {
+				return err
+			}
+		}
+		detail.ExclusionConstraint.Name = newName
+		return nil
+
 	default:
 		return unimplemented.Newf(fmt.Sprintf("rename-constraint-%s", detail.Kind),
 			"constraint %q has unsupported type", tree.ErrNameString(oldName))
@@ -4209,6 +4333,14 @@ func (desc *wrapper) FindAllReferences() (map[descpb.ID]struct{}, error) {
 		refs[dest] = struct{}{}
 	}
 
+	for i := range desc.Triggers {
+		for _, ref := range desc.Triggers[i].DependsOn {
+			if ref.ID != desc.ID {
+				refs[ref.ID] = struct{}{}
+			}
+		}
+	}
+
 	for _, c := range desc.DependedOnBy {
 		refs[c.ID] = struct{}{}
 	}
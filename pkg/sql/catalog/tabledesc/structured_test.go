@@ -864,6 +864,72 @@ func TestValidateTableDesc(t *testing.T) {
 					},
 				},
 			}},
+		{`duplicate trigger name: "t"`,
+			descpb.TableDescriptor{
+				ID:            2,
+				ParentID:      1,
+				Name:          "foo",
+				FormatVersion: descpb.FamilyFormatVersion,
+				Columns: []descpb.ColumnDescriptor{
+					{ID: 1, Name: "bar"},
+				},
+				Families: []descpb.ColumnFamilyDescriptor{
+					{ID: 0, Name: "primary",
+						ColumnIDs:   []descpb.ColumnID{1},
+						ColumnNames: []string{"bar"},
+					},
+				},
+				NextColumnID: 2,
+				NextFamilyID: 1,
+				Triggers: []descpb.TriggerDescriptor{
+					{Name: "t", Events: []descpb.TriggerDescriptor_Event{descpb.TriggerDescriptor_INSERT}},
+					{Name: "t", Events: []descpb.TriggerDescriptor_Event{descpb.TriggerDescriptor_DELETE}},
+				},
+			}},
+		{`trigger "t" contains duplicate event UPDATE`,
+			descpb.TableDescriptor{
+				ID:            2,
+				ParentID:      1,
+				Name:          "foo",
+				FormatVersion: descpb.FamilyFormatVersion,
+				Columns: []descpb.ColumnDescriptor{
+					{ID: 1, Name: "bar"},
+				},
+				Families: []descpb.ColumnFamilyDescriptor{
+					{ID: 0, Name: "primary",
+						ColumnIDs:   []descpb.ColumnID{1},
+						ColumnNames: []string{"bar"},
+					},
+				},
+				NextColumnID: 2,
+				NextFamilyID: 1,
+				Triggers: []descpb.TriggerDescriptor{
+					{Name: "t", Events: []descpb.TriggerDescriptor_Event{
+						descpb.TriggerDescriptor_UPDATE, descpb.TriggerDescriptor_UPDATE,
+					}},
+				},
+			}},
+		{`exclusion constraint "e" contains invalid operator "<"`,
+			descpb.TableDescriptor{
+				ID:            2,
+				ParentID:      1,
+				Name:          "foo",
+				FormatVersion: descpb.FamilyFormatVersion,
+				Columns: []descpb.ColumnDescriptor{
+					{ID: 1, Name: "bar"},
+				},
+				Families: []descpb.ColumnFamilyDescriptor{
+					{ID: 0, Name: "primary",
+						ColumnIDs:   []descpb.ColumnID{1},
+						ColumnNames: []string{"bar"},
+					},
+				},
+				NextColumnID: 2,
+				NextFamilyID: 1,
+				ExclusionConstraints: []descpb.ExclusionConstraint{
+					{Name: "e", ColumnIDs: []descpb.ColumnID{1}, Operators: []string{"<"}},
+				},
+			}},
 		{`primary index column "v" cannot be virtual`,
 			descpb.TableDescriptor{
 				ID:            2,
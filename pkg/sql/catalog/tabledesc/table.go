@@ -329,9 +329,58 @@ func (desc *wrapper) collectConstraintInfo(
 		}
 		info[c.Name] = detail
 	}
+
+	for i := range desc.ExclusionConstraints {
+		ec := &desc.ExclusionConstraints[i]
+		if _, ok := info[ec.Name]; ok {
+			return nil, pgerror.Newf(pgcode.DuplicateObject,
+				"duplicate constraint name: %q", ec.Name)
+		}
+		def, err := MakeExclusionConstraintDef(desc, ec)
+		if err != nil {
+			return nil, err
+		}
+		detail := descpb.ConstraintDetail{Kind: descpb.ConstraintTypeExclusion}
+		detail.Columns, err = desc.NamesForColumnIDs(ec.ColumnIDs)
+		if err != nil {
+			return nil, err
+		}
+		detail.ExclusionConstraint = ec
+		detail.Details = tree.AsString(def)
+		// The backing index may have been dropped along with the constraint by
+		// a schema change in progress.
+		if idx, err := desc.FindIndexByID(ec.IndexID); err == nil {
+			detail.Index = idx
+		}
+		info[ec.Name] = detail
+	}
 	return info, nil
 }
 
+// MakeExclusionConstraintDef returns the definition of the given exclusion
+// constraint of the table, as it would be written in a CREATE TABLE statement.
+// The name of the returned constraint definition is not set.
+func MakeExclusionConstraintDef(
+	desc catalog.TableDescriptor, ec *descpb.ExclusionConstraint,
+) (*tree.ExclusionConstraintTableDef, error) {
+	names, err := desc.NamesForColumnIDs(ec.ColumnIDs)
+	if err != nil {
+		return nil, err
+	}
+	def := &tree.ExclusionConstraintTableDef{
+		AccessMethod: tree.Name(ec.AccessMethod),
+		Elems:        make(tree.ExclusionElemList, len(names)),
+	}
+	for i := range names {
+		op, err := ec.Operator(i)
+		if err != nil {
+			return nil, err
+		}
+		def.Elems[i] = tree.ExclusionElem{Column: tree.Name(names[i]), Operator: op}
+	}
+	return def, nil
+}
+
 // FindFKReferencedIndex finds the first index in the supplied referencedTable
 // that can satisfy a foreign key of the supplied column ids.
 func FindFKReferencedIndex(
@@ -91,6 +91,9 @@ var validationMap = []struct {
 			"DependsOn": {
 				status: todoIAmKnowinglyAddingTechDebt,
 				reason: "initial import: TODO(features): add validation"},
+			"DependsOnFunctions": {
+				status: todoIAmKnowinglyAddingTechDebt,
+				reason: "like DependsOn, the back-references are not validated"},
 			"DependedOnBy": {
 				status: todoIAmKnowinglyAddingTechDebt,
 				reason: "initial import: TODO(features): add validation"},
@@ -111,6 +114,8 @@ var validationMap = []struct {
 			"UniqueWithoutIndexConstraints": {status: iSolemnlySwearThisFieldIsValidated},
 			"Temporary":                     {status: thisFieldReferencesNoObjects},
 			"LocalityConfig":                {status: iSolemnlySwearThisFieldIsValidated},
+			"Triggers":                      {status: iSolemnlySwearThisFieldIsValidated},
+			"ExclusionConstraints":          {status: iSolemnlySwearThisFieldIsValidated},
 		},
 	},
 	{
@@ -193,6 +198,7 @@ var validationMap = []struct {
 			"OnDelete":          {status: thisFieldReferencesNoObjects},
 			"OnUpdate":          {status: thisFieldReferencesNoObjects},
 			"Match":             {status: thisFieldReferencesNoObjects},
+			"Deferrability":     {status: thisFieldReferencesNoObjects},
 		},
 	},
 	{
@@ -214,6 +220,8 @@ var validationMap = []struct {
 			"Privileges":               {status: iSolemnlySwearThisFieldIsValidated},
 			"OfflineReason":            {status: thisFieldReferencesNoObjects},
 			"RegionConfig":             {status: iSolemnlySwearThisFieldIsValidated},
+			"Composite":                {status: iSolemnlySwearThisFieldIsValidated},
+			"Domain":                   {status: iSolemnlySwearThisFieldIsValidated},
 		},
 	},
 }
@@ -442,10 +442,41 @@ func (desc *Immutable) Validate(ctx context.Context, dg catalog.DescGetter) erro
 		if desc.Alias == nil {
 			return errors.AssertionFailedf("ALIAS type desc has nil alias type")
 		}
+	case descpb.TypeDescriptor_COMPOSITE:
+		if desc.Composite == nil || desc.Composite.Family() != types.TupleFamily {
+			return errors.AssertionFailedf("COMPOSITE type desc has no tuple type")
+		}
+		if len(desc.Composite.TupleLabels()) != len(desc.Composite.TupleContents()) {
+			return errors.AssertionFailedf("COMPOSITE type desc has unlabeled fields")
+		}
+
+		// Validate the Privileges of the descriptor.
+		if err := desc.Privileges.Validate(desc.ID, privilege.Type); err != nil {
+			return err
+		}
+	case descpb.TypeDescriptor_DOMAIN:
+		if desc.Domain == nil || desc.Domain.BaseType == nil {
+			return errors.AssertionFailedf("DOMAIN type desc has no base type")
+		}
+		if desc.Domain.BaseType.UserDefined() {
+			return errors.AssertionFailedf("DOMAIN type desc has user defined base type")
+		}
+
+		// Validate the Privileges of the descriptor.
+		if err := desc.Privileges.Validate(desc.ID, privilege.Type); err != nil {
+			return err
+		}
 	default:
 		return errors.AssertionFailedf("invalid desc kind %s", desc.Kind.String())
 	}
 
+	if desc.Kind != descpb.TypeDescriptor_COMPOSITE && desc.Composite != nil {
+		return errors.AssertionFailedf("found composite type on %s type desc", desc.Kind.String())
+	}
+	if desc.Kind != descpb.TypeDescriptor_DOMAIN && desc.Domain != nil {
+		return errors.AssertionFailedf("found domain on %s type desc", desc.Kind.String())
+	}
+
 	switch desc.Kind {
 	case descpb.TypeDescriptor_MULTIREGION_ENUM:
 		if desc.RegionConfig == nil {
@@ -554,7 +585,8 @@ func (desc *Immutable) Validate(ctx context.Context, dg catalog.DescGetter) erro
 	}
 
 	switch desc.Kind {
-	case descpb.TypeDescriptor_ENUM, descpb.TypeDescriptor_MULTIREGION_ENUM:
+	case descpb.TypeDescriptor_ENUM, descpb.TypeDescriptor_MULTIREGION_ENUM,
+		descpb.TypeDescriptor_COMPOSITE, descpb.TypeDescriptor_DOMAIN:
 		// Ensure that the referenced array type exists.
 		reqs = append(reqs, desc.ArrayTypeID)
 		checks = append(checks, func(got catalog.Descriptor) error {
@@ -630,6 +662,27 @@ func (desc *Immutable) MakeTypesT(
 			return nil, err
 		}
 		return desc.Alias, nil
+	case descpb.TypeDescriptor_COMPOSITE:
+		typ := types.MakeComposite(
+			TypeIDToOID(desc.GetID()),
+			TypeIDToOID(desc.ArrayTypeID),
+			desc.Composite.TupleContents(),
+			desc.Composite.TupleLabels(),
+		)
+		if err := desc.HydrateTypeInfoWithName(ctx, typ, name, res); err != nil {
+			return nil, err
+		}
+		return typ, nil
+	case descpb.TypeDescriptor_DOMAIN:
+		typ := types.MakeDomain(
+			TypeIDToOID(desc.GetID()),
+			TypeIDToOID(desc.ArrayTypeID),
+			desc.Domain.BaseType,
+		)
+		if err := desc.HydrateTypeInfoWithName(ctx, typ, name, res); err != nil {
+			return nil, err
+		}
+		return typ, nil
 	default:
 		return nil, errors.AssertionFailedf("unknown type kind %s", t.String())
 	}
@@ -671,6 +724,29 @@ func HydrateTypesInTableDescriptor(
 	return nil
 }
 
+// HydrateTypesInFunctionDescriptor uses typeLookup to install metadata in the
+// argument and return types of a function descriptor.
+func HydrateTypesInFunctionDescriptor(
+	ctx context.Context, desc *descpb.FunctionDescriptor, res catalog.TypeDescriptorResolver,
+) error {
+	hydrate := func(typ *types.T) error {
+		if !typ.UserDefined() {
+			return nil
+		}
+		name, typDesc, err := res.GetTypeDescriptor(ctx, GetTypeDescID(typ))
+		if err != nil {
+			return err
+		}
+		return typDesc.HydrateTypeInfoWithName(ctx, typ, &name, res)
+	}
+	for i := range desc.Args {
+		if err := hydrate(desc.Args[i].Type); err != nil {
+			return err
+		}
+	}
+	return hydrate(desc.ReturnType)
+}
+
 // HydrateTypeInfoWithName fills in user defined type metadata for
 // a type and also sets the name in the metadata to the passed in name.
 // This is used when hydrating a type with a known qualified name.
@@ -720,6 +796,31 @@ func (desc *Immutable) HydrateTypeInfoWithName(
 			}
 		}
 		return nil
+	case descpb.TypeDescriptor_COMPOSITE:
+		if typ.Family() != types.TupleFamily {
+			return errors.New("cannot hydrate a non-tuple type with a composite type descriptor")
+		}
+		// The fields of composite types can't be user defined types, so there is
+		// nothing else to hydrate.
+		return nil
+	case descpb.TypeDescriptor_DOMAIN:
+		if !typ.IsDomain() {
+			return errors.New("cannot hydrate a non-domain type with a domain type descriptor")
+		}
+		checks := make([]types.DomainCheck, len(desc.Domain.Checks))
+		for i := range desc.Domain.Checks {
+			checks[i] = types.DomainCheck{
+				Name: desc.Domain.Checks[i].Name,
+				Expr: desc.Domain.Checks[i].Expr,
+			}
+		}
+		typ.TypeMeta.DomainData = &types.DomainMetadata{
+			BaseType:    desc.Domain.BaseType,
+			DefaultExpr: desc.Domain.DefaultExpr,
+			NotNull:     desc.Domain.NotNull,
+			Checks:      checks,
+		}
+		return nil
 	default:
 		return errors.AssertionFailedf("unknown type descriptor kind %s", desc.Kind)
 	}
@@ -759,6 +860,27 @@ func (desc *Immutable) IsCompatibleWith(other *Immutable) error {
 			}
 		}
 		return nil
+	case descpb.TypeDescriptor_COMPOSITE:
+		if other.Kind != desc.Kind {
+			return errors.Newf("%q of type %q is not compatible with type %q",
+				other.Name, other.Kind, desc.Kind)
+		}
+		// The fields are encoded positionally, so they must be the same.
+		if !desc.Composite.Identical(other.Composite) {
+			return errors.Newf("%q has differing fields", other.Name)
+		}
+		return nil
+	case descpb.TypeDescriptor_DOMAIN:
+		if other.Kind != desc.Kind {
+			return errors.Newf("%q of type %q is not compatible with type %q",
+				other.Name, other.Kind, desc.Kind)
+		}
+		// Values are encoded as values of the base type, so the base types must
+		// be the same. The constraints of the domains may differ.
+		if !desc.Domain.BaseType.Identical(other.Domain.BaseType) {
+			return errors.Newf("%q has a differing base type", other.Name)
+		}
+		return nil
 	default:
 		return errors.Newf("compatibility comparison unsupported for type kind %s", desc.Kind.String())
 	}
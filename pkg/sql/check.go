@@ -23,9 +23,11 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/errors"
@@ -223,17 +225,19 @@ func nonMatchingRowQuery(
 }
 
 // validateForeignKey verifies that all the rows in the srcTable
-// have a matching row in their referenced table.
+// have a matching row in their referenced table. If keys is non-nil, only the
+// rows whose values in the FK columns are one of the given keys are verified.
 //
 // It operates entirely on the current goroutine and is thus able to
 // reuse an existing client.Txn safely.
 func validateForeignKey(
 	ctx context.Context,
-	srcTable *tabledesc.Mutable,
+	srcTable catalog.TableDescriptor,
 	fk *descpb.ForeignKeyConstraint,
 	ie *InternalExecutor,
 	txn *kv.Txn,
 	codec keys.SQLCodec,
+	keys []tree.Datums,
 ) error {
 	desc, err := catalogkv.GetDescriptorByID(ctx, txn, codec, fk.ReferencedTableID, catalogkv.Immutable,
 		catalogkv.TableDescriptorKind, true /* required */)
@@ -242,6 +246,10 @@ func validateForeignKey(
 	}
 	targetTable := desc.(catalog.TableDescriptor)
 	nCols := len(fk.OriginColumnIDs)
+	keyColNames, err := srcTable.NamesForColumnIDs(fk.OriginColumnIDs)
+	if err != nil {
+		return err
+	}
 
 	referencedColumnNames, err := targetTable.NamesForColumnIDs(fk.ReferencedColumnIDs)
 	if err != nil {
@@ -253,7 +261,7 @@ func validateForeignKey(
 	// (The matching options only matter for FKs with more than one column.)
 	if nCols > 1 && fk.Match == descpb.ForeignKeyReference_FULL {
 		query, colNames, err := matchFullUnacceptableKeyQuery(
-			srcTable, fk, true, /* limitResults */
+			srcTable, fk, keys == nil, /* limitResults */
 		)
 		if err != nil {
 			return err
@@ -261,12 +269,15 @@ func validateForeignKey(
 
 		log.Infof(ctx, "validating MATCH FULL FK %q (%q [%v] -> %q [%v]) with query %q",
 			fk.Name,
-			srcTable.Name, colNames,
+			srcTable.GetName(), colNames,
 			targetTable.GetName(), referencedColumnNames,
 			query,
 		)
 
-		values, err := ie.QueryRow(ctx, "validate foreign key constraint", txn, query)
+		values, err := queryRowForKeys(
+			ctx, ie, txn, "validate foreign key constraint", query, keyColNames,
+			keysWithNulls(keys, true /* withNulls */),
+		)
 		if err != nil {
 			return err
 		}
@@ -279,7 +290,7 @@ func validateForeignKey(
 	}
 	query, colNames, err := nonMatchingRowQuery(
 		srcTable, fk, targetTable,
-		true, /* limitResults */
+		keys == nil, /* limitResults */
 	)
 	if err != nil {
 		return err
@@ -287,22 +298,161 @@ func validateForeignKey(
 
 	log.Infof(ctx, "validating FK %q (%q [%v] -> %q [%v]) with query %q",
 		fk.Name,
-		srcTable.Name, colNames, targetTable.GetName(), referencedColumnNames,
+		srcTable.GetName(), colNames, targetTable.GetName(), referencedColumnNames,
 		query,
 	)
 
-	values, err := ie.QueryRow(ctx, "validate fk constraint", txn, query)
+	values, err := queryRowForKeys(
+		ctx, ie, txn, "validate fk constraint", query, keyColNames,
+		keysWithNulls(keys, false /* withNulls */),
+	)
 	if err != nil {
 		return err
 	}
 	if values.Len() > 0 {
 		return pgerror.WithConstraintName(pgerror.Newf(pgcode.ForeignKeyViolation,
 			"foreign key violation: %q row %s has no match in %q",
-			srcTable.Name, formatValues(colNames, values), targetTable.GetName()), fk.Name)
+			srcTable.GetName(), formatValues(colNames, values), targetTable.GetName()), fk.Name)
 	}
 	return nil
 }
 
+// validateUniqueWithoutIndexConstraint verifies that there are no duplicate
+// values in the columns of the given UNIQUE WITHOUT INDEX constraint. Rows
+// with a NULL value in any of the columns are ignored. If keys is non-nil,
+// only the given values are verified.
+//
+// It operates entirely on the current goroutine and is thus able to
+// reuse an existing client.Txn safely.
+func validateUniqueWithoutIndexConstraint(
+	ctx context.Context,
+	tableDesc catalog.TableDescriptor,
+	uc *descpb.UniqueWithoutIndexConstraint,
+	ie *InternalExecutor,
+	txn *kv.Txn,
+	keys []tree.Datums,
+) error {
+	colNames, err := tableDesc.NamesForColumnIDs(uc.ColumnIDs)
+	if err != nil {
+		return err
+	}
+	srcCols := make([]string, len(colNames))
+	srcWhere := make([]string, len(colNames))
+	for i, n := range colNames {
+		srcCols[i] = tree.NameString(n)
+		srcWhere[i] = fmt.Sprintf("%s IS NOT NULL", srcCols[i])
+	}
+	limit := ""
+	if keys == nil {
+		limit = " LIMIT 1"
+	}
+	query := fmt.Sprintf(
+		`SELECT %[1]s FROM [%[2]d AS tbl] WHERE %[3]s GROUP BY %[1]s HAVING count(*) > 1%[4]s`,
+		strings.Join(srcCols, ", "),     // 1
+		tableDesc.GetID(),               // 2
+		strings.Join(srcWhere, " AND "), // 3
+		limit,                           // 4
+	)
+	log.Infof(ctx, "validating unique constraint %q (%q [%v]) with query %q",
+		uc.Name, tableDesc.GetName(), colNames, query,
+	)
+
+	values, err := queryRowForKeys(
+		ctx, ie, txn, "validate unique constraint", query, colNames,
+		keysWithNulls(keys, false /* withNulls */),
+	)
+	if err != nil {
+		return err
+	}
+	if values.Len() > 0 {
+		valStrs := make([]string, len(values))
+		for i := range values {
+			valStrs[i] = values[i].String()
+		}
+		return errors.WithDetailf(
+			pgerror.WithConstraintName(pgerror.Newf(pgcode.UniqueViolation,
+				"duplicate key value violates unique constraint %q", uc.Name,
+			), uc.Name),
+			"Key (%s)=(%s) already exists.",
+			strings.Join(colNames, ", "), strings.Join(valStrs, ", "),
+		)
+	}
+	return nil
+}
+
+// validateKeysBatchSize is the maximum number of keys verified by each query
+// run by queryRowForKeys.
+const validateKeysBatchSize = 100
+
+// queryRowForKeys runs the given validation query, and returns its first row,
+// if any. If keys is non-nil, the query is only run for the rows whose values
+// in the given columns, which are the first columns returned by the query, are
+// one of the given keys; the query must not have a LIMIT clause in this case,
+// since one is added.
+// A NULL in a key only matches NULL values.
+func queryRowForKeys(
+	ctx context.Context,
+	ie *InternalExecutor,
+	txn *kv.Txn,
+	opName string,
+	query string,
+	colNames []string,
+	keys []tree.Datums,
+) (tree.Datums, error) {
+	if keys == nil {
+		return ie.QueryRow(ctx, opName, txn, query)
+	}
+	for len(keys) > 0 {
+		batch := keys
+		if len(batch) > validateKeysBatchSize {
+			batch = batch[:validateKeysBatchSize]
+		}
+		keys = keys[len(batch):]
+
+		var args []interface{}
+		disjuncts := make([]string, len(batch))
+		for i, key := range batch {
+			conjuncts := make([]string, len(key))
+			for j, d := range key {
+				col := tree.NameString(colNames[j])
+				if d == tree.DNull {
+					conjuncts[j] = fmt.Sprintf("v.%s IS NULL", col)
+					continue
+				}
+				args = append(args, d)
+				conjuncts[j] = fmt.Sprintf("v.%s = $%d", col, len(args))
+			}
+			disjuncts[i] = "(" + strings.Join(conjuncts, " AND ") + ")"
+		}
+		values, err := ie.QueryRow(ctx, opName, txn, fmt.Sprintf(
+			`SELECT * FROM (%s) AS v WHERE %s LIMIT 1`, query, strings.Join(disjuncts, " OR "),
+		), args...)
+		if err != nil || values.Len() > 0 {
+			return values, err
+		}
+	}
+	return nil, nil
+}
+
+// keysWithNulls returns the keys that contain a NULL if withNulls is set, and
+// the ones that don't otherwise. It returns nil if keys is nil.
+func keysWithNulls(keys []tree.Datums, withNulls bool) []tree.Datums {
+	if keys == nil {
+		return nil
+	}
+	res := make([]tree.Datums, 0, len(keys))
+	for _, key := range keys {
+		hasNull := false
+		for _, d := range key {
+			hasNull = hasNull || d == tree.DNull
+		}
+		if hasNull == withNulls {
+			res = append(res, key)
+		}
+	}
+	return res
+}
+
 func formatValues(colNames []string, values tree.Datums) string {
 	var pairs bytes.Buffer
 	for i := range values {
@@ -369,5 +519,104 @@ func checkMutationInput(
 		}
 		colIdx++
 	}
+
+	if colIdx == checkOrds.Len() {
+		return nil
+	}
+	// The remaining checks were synthesized for columns of user defined types.
+	typeChecks, err := synthesizeTypeChecks(tabDesc.GetPublicColumns())
+	if err != nil {
+		return err
+	}
+	for i := range typeChecks {
+		if !checkOrds.Contains(len(checks) + i) {
+			continue
+		}
+
+		if res, err := tree.GetBool(checkVals[colIdx]); err != nil {
+			return err
+		} else if !res && checkVals[colIdx] != tree.DNull {
+			return typeChecks[i].violationError()
+		}
+		colIdx++
+	}
 	return nil
 }
+
+// typeCheck is a check constraint that is synthesized for a column of a user
+// defined type, rather than declared on the table.
+type typeCheck struct {
+	// expr is the serialized check expression.
+	expr string
+	// domain is set if the check enforces a constraint of the domain type of
+	// the column.
+	domain *types.T
+	// name is the name of the enforced CHECK constraint of the domain. It is
+	// empty if the check enforces the NOT NULL constraint of the domain.
+	name string
+}
+
+// violationError returns the error for a value that does not satisfy the
+// check.
+func (c *typeCheck) violationError() error {
+	if c.domain == nil {
+		return pgerror.Newf(pgcode.CheckViolation, "failed to satisfy CHECK constraint (%s)", c.expr)
+	}
+	if c.name == "" {
+		return pgerror.Newf(pgcode.NotNullViolation,
+			"domain %s does not allow null values", c.domain.SQLString())
+	}
+	return pgerror.WithConstraintName(pgerror.Newf(pgcode.CheckViolation,
+		"value for domain %s violates check constraint %q", c.domain.SQLString(), c.name,
+	), c.name)
+}
+
+// synthesizeTypeChecks returns the check constraints that are synthesized for
+// the given public columns of a table: an (x IN (v1, v2, v3...)) check for
+// each column of an enum type, followed by the constraints of the domain of
+// each column of a domain type. The optimizer appends these checks to the
+// check constraints of the table in the same order.
+func synthesizeTypeChecks(cols []descpb.ColumnDescriptor) ([]typeCheck, error) {
+	var checks []typeCheck
+	for i := range cols {
+		colType := cols[i].Type
+		if colType.UserDefined() && colType.Family() == types.EnumFamily {
+			expr := &tree.ComparisonExpr{
+				Operator: tree.In,
+				Left:     &tree.ColumnItem{ColumnName: tree.Name(cols[i].Name)},
+				Right:    tree.NewDTuple(colType, tree.MakeAllDEnumsInType(colType)...),
+			}
+			checks = append(checks, typeCheck{expr: tree.Serialize(expr)})
+		}
+	}
+	for i := range cols {
+		colType := cols[i].Type
+		domain := colType.TypeMeta.DomainData
+		if domain == nil {
+			continue
+		}
+		col := &tree.ColumnItem{ColumnName: tree.Name(cols[i].Name)}
+		if domain.NotNull {
+			checks = append(checks, typeCheck{
+				expr:   tree.Serialize(&tree.IsNotNullExpr{Expr: col}),
+				domain: colType,
+			})
+		}
+		for j := range domain.Checks {
+			expr, err := parser.ParseExpr(domain.Checks[j].Expr)
+			if err != nil {
+				return nil, err
+			}
+			expr, err = schemaexpr.ReplaceDomainValue(expr, col)
+			if err != nil {
+				return nil, err
+			}
+			checks = append(checks, typeCheck{
+				expr:   tree.Serialize(expr),
+				domain: colType,
+				name:   domain.Checks[j].Name,
+			})
+		}
+	}
+	return checks, nil
+}
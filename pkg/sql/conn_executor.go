@@ -426,6 +426,7 @@ func (s *Server) SetupConn(
 		ctx, sd, args.SessionDefaults, stmtBuf, clientComm, memMetrics, &s.Metrics,
 		s.sqlStats.getStatsForApplication(sd.ApplicationName),
 	)
+	ex.notifications.init(s.cfg.NotificationRegistry, stmtBuf, ex.sessionMon)
 	return ConnectionHandler{ex}, nil
 }
 
@@ -632,6 +633,7 @@ func (s *Server) newConnExecutor(
 		s.cfg.LeaseManager, s.cfg.Settings, sd, s.cfg.HydratedTables)
 	ex.extraTxnState.txnRewindPos = -1
 	ex.extraTxnState.schemaChangeJobsCache = make(map[descpb.ID]*jobs.Job)
+	ex.extraTxnState.localSessionVars.savepoints = &ex.extraTxnState.savepoints
 	ex.mu.ActiveQueries = make(map[ClusterWideID]*queryMeta)
 	ex.machine = fsm.MakeMachine(TxnStateTransitions, stateNoTxn{}, &ex.state)
 
@@ -859,6 +861,8 @@ func (ex *connExecutor) close(ctx context.Context, closeType closeType) {
 		ex.extraTxnState.prepStmtsNamespaceMemAcc.Close(ctx)
 	}
 
+	ex.notifications.close(ctx)
+
 	if ex.sessionTracing.Enabled() {
 		if err := ex.sessionTracing.StopTracing(); err != nil {
 			log.Warningf(ctx, "error stopping tracing: %s", err)
@@ -954,6 +958,14 @@ type connExecutor struct {
 		// queued up for the given ID.
 		schemaChangeJobsCache map[descpb.ID]*jobs.Job
 
+		// deferredConstraints tracks the checks of deferrable constraints that
+		// are performed when the transaction commits.
+		deferredConstraints deferredConstraints
+
+		// localSessionVars tracks the session variables modified by SET LOCAL,
+		// which are restored when the transaction ends.
+		localSessionVars localSessionVars
+
 		// autoRetryCounter keeps track of the which iteration of a transaction
 		// auto-retry we're currently in. It's 0 whenever the transaction state is not
 		// stateOpen.
@@ -1019,6 +1031,11 @@ type connExecutor struct {
 		// processing the command at position txnRewindPos. When rewinding, we're
 		// going to restore this snapshot.
 		savepointsAtTxnRewindPos savepointStack
+		// listenOpsAtTxnRewindPos is a snapshot of the LISTEN and UNLISTEN
+		// statements executed by the transaction before processing the command
+		// at position txnRewindPos. When rewinding, we're going to restore this
+		// snapshot.
+		listenOpsAtTxnRewindPos []listenOp
 
 		// transactionStatementIDs tracks all statement IDs that make up the current
 		// transaction. It's length is bound by the TxnStatsNumStmtIDsToRecord
@@ -1120,6 +1137,9 @@ type connExecutor struct {
 	// temporary schema, which requires special cleanup on close.
 	hasCreatedTemporarySchema bool
 
+	// notifications tracks the channels the session listens on.
+	notifications sessionNotifications
+
 	// stmtDiagnosticsRecorder is used to track which queries need to have
 	// information collected.
 	stmtDiagnosticsRecorder *stmtdiagnostics.Registry
@@ -1234,7 +1254,18 @@ func (ex *connExecutor) resetExtraTxnState(ctx context.Context, ev txnEvent) err
 
 	switch ev {
 	case txnCommit, txnRollback:
+		// On txnRestart, the state of the deferred constraints is restored by
+		// ROLLBACK TO SAVEPOINT. When rewinding, the pending keys of the previous
+		// attempt are checked again, which is harmless.
+		ex.extraTxnState.deferredConstraints.reset()
+		// On txnRestart, the LISTEN and UNLISTEN statements that are rolled back
+		// are discarded by ROLLBACK TO SAVEPOINT, or when rewinding.
+		if ev == txnCommit {
+			ex.notifications.commit()
+		}
+		ex.notifications.reset()
 		ex.extraTxnState.savepoints.clear()
+		ex.extraTxnState.localSessionVars.values = nil
 		// After txn is finished, we need to call onTxnFinish (if it's non-nil).
 		if ex.extraTxnState.onTxnFinish != nil {
 			ex.extraTxnState.onTxnFinish(ev)
@@ -1480,6 +1511,14 @@ func (ex *connExecutor) execCmd(ctx context.Context) error {
 				res = ex.clientComm.CreateErrorResult(pos)
 				return nil
 			}
+			if portal.cursor != nil {
+				err := pgerror.Newf(
+					pgcode.FeatureNotSupported, "cursor %q can only be used with FETCH", portalName)
+				ev = eventNonRetriableErr{IsCommit: fsm.False}
+				payload = eventNonRetriableErrPayload{err: err}
+				res = ex.clientComm.CreateErrorResult(pos)
+				return nil
+			}
 			if portal.Stmt.AST == nil {
 				res = ex.clientComm.CreateEmptyQueryResult(pos)
 				return nil
@@ -1546,7 +1585,11 @@ func (ex *connExecutor) execCmd(ctx context.Context) error {
 		payload = eventNonRetriableErrPayload{err: tcmd.Err}
 	case Sync:
 		// Note that the Sync result will flush results to the network connection.
-		res = ex.clientComm.CreateSyncResult(pos)
+		syncRes := ex.clientComm.CreateSyncResult(pos)
+		res = syncRes
+		if ex.idleConn() {
+			ex.notifications.deliver(ctx, syncRes)
+		}
 		if ex.draining {
 			// If we're draining, check whether this is a good time to finish the
 			// connection. If we're not inside a transaction, we stop processing
@@ -1579,6 +1622,15 @@ func (ex *connExecutor) execCmd(ctx context.Context) error {
 	case Flush:
 		// Closing the res will flush the connection's buffer.
 		res = ex.clientComm.CreateFlushResult(pos)
+	case DeliverNotifications:
+		// Like Postgres, we only deliver notifications between transactions. If
+		// we're inside a transaction, they are delivered by the Sync that
+		// follows its end.
+		notifyRes := ex.clientComm.CreateDeliverNotificationsResult(pos)
+		res = notifyRes
+		if ex.idleConn() {
+			ex.notifications.deliver(ctx, notifyRes)
+		}
 	default:
 		panic(errors.AssertionFailedf("unsupported command type: %T", cmd))
 	}
@@ -1640,6 +1692,7 @@ func (ex *connExecutor) execCmd(ctx context.Context) error {
 	case rewind:
 		ex.rewindPrepStmtNamespace(ctx)
 		ex.extraTxnState.savepoints = ex.extraTxnState.savepointsAtTxnRewindPos
+		ex.notifications.restorePendingOps(ex.extraTxnState.listenOpsAtTxnRewindPos)
 		advInfo.rewCap.rewindAndUnlock(ctx)
 	case stayInPlace:
 		// Nothing to do. The same statement will be executed again.
@@ -1758,6 +1811,8 @@ func (ex *connExecutor) updateTxnRewindPosMaybe(
 				canAdvance = true
 			case Flush:
 				canAdvance = true
+			case DeliverNotifications:
+				canAdvance = true
 			default:
 				panic(errors.AssertionFailedf("unsupported cmd: %T", cmd))
 			}
@@ -1782,6 +1837,7 @@ func (ex *connExecutor) setTxnRewindPos(ctx context.Context, pos CmdPos) {
 	ex.stmtBuf.ltrim(ctx, pos)
 	ex.commitPrepStmtNamespace(ctx)
 	ex.extraTxnState.savepointsAtTxnRewindPos = ex.extraTxnState.savepoints.clone()
+	ex.extraTxnState.listenOpsAtTxnRewindPos = ex.notifications.snapshotPendingOps()
 }
 
 // stmtDoesntNeedRetry returns true if the given statement does not need to be
@@ -2137,6 +2193,7 @@ func (ex *connExecutor) initEvalCtx(ctx context.Context, evalCtx *extendedEvalCo
 		SchemaChangeJobCache: ex.extraTxnState.schemaChangeJobsCache,
 		schemaAccessors:      scInterface,
 		sqlStatsCollector:    ex.statsCollector,
+		notifications:        &ex.notifications,
 	}
 }
 
@@ -2151,6 +2208,13 @@ func (ex *connExecutor) resetEvalCtx(evalCtx *extendedEvalContext, txn *kv.Txn,
 	evalCtx.TxnState = ex.getTransactionState()
 	evalCtx.TxnReadOnly = ex.state.readOnly
 	evalCtx.TxnImplicit = ex.implicitTxn()
+	// The checks of internal executors are never deferred: their transaction
+	// might be committed by another connExecutor.
+	evalCtx.deferredConstraints = nil
+	if ex.executorType != executorTypeInternal {
+		evalCtx.deferredConstraints = &ex.extraTxnState.deferredConstraints
+	}
+	evalCtx.localSessionVars = &ex.extraTxnState.localSessionVars
 	evalCtx.StmtTimestamp = stmtTS
 	evalCtx.TxnTimestamp = ex.state.sqlTimestamp
 	evalCtx.Placeholders = nil
@@ -2310,6 +2374,14 @@ func (ex *connExecutor) txnStateTransitionsApplyWrapper(
 
 		fallthrough
 	case txnRestart, txnRollback:
+		if advInfo.txnEvent != txnRestart {
+			// Undo the effects of SET LOCAL.
+			if err := ex.restoreSessionVars(
+				ex.Ctx(), ex.extraTxnState.localSessionVars.values, res,
+			); err != nil {
+				return advanceInfo{}, err
+			}
+		}
 		if err := ex.resetExtraTxnState(ex.Ctx(), advInfo.txnEvent); err != nil {
 			return advanceInfo{}, err
 		}
@@ -2338,6 +2410,10 @@ func (ex *connExecutor) initStatementResult(
 	if ast.StatementType() == tree.Rows {
 		// Note that this call is necessary even if cols is nil.
 		res.SetColumns(ctx, cols)
+		// The results that stream their rows to an InternalExecutor's
+		// rowsIterator fail if the iterator was closed, in which case the
+		// statement must not be executed.
+		return res.Err()
 	}
 	return nil
 }
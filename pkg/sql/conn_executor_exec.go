@@ -532,6 +532,35 @@ func (ex *connExecutor) execStmtInOpenState(
 		if s.DiscardRows {
 			ih.SetDiscardRows()
 		}
+
+	case *tree.DeclareCursor:
+		if os.ImplicitTxn.Get() {
+			err := pgerror.New(pgcode.NoActiveSQLTransaction,
+				"DECLARE CURSOR can only be used in transaction blocks")
+			return makeErrEvent(err)
+		}
+		if err := ex.execDeclareCursor(ctx, s, stmt); err != nil {
+			return makeErrEvent(err)
+		}
+		return nil, nil, nil
+
+	case *tree.FetchCursor:
+		if err := ex.execFetchCursor(ctx, &s.CursorStmt, false /* move */, res); err != nil {
+			return makeErrEvent(err)
+		}
+		return nil, nil, nil
+
+	case *tree.MoveCursor:
+		if err := ex.execFetchCursor(ctx, &s.CursorStmt, true /* move */, res); err != nil {
+			return makeErrEvent(err)
+		}
+		return nil, nil, nil
+
+	case *tree.CloseCursor:
+		if err := ex.execCloseCursor(ctx, s); err != nil {
+			return makeErrEvent(err)
+		}
+		return nil, nil, nil
 	}
 
 	p.semaCtx.Annotations = tree.MakeAnnotations(stmt.NumAnnotations)
@@ -728,6 +757,10 @@ func (ex *connExecutor) commitSQLTransaction(
 func (ex *connExecutor) commitSQLTransactionInternal(
 	ctx context.Context, ast tree.Statement,
 ) error {
+	if err := ex.validateDeferredConstraints(ctx); err != nil {
+		return err
+	}
+
 	if err := validatePrimaryKeys(&ex.extraTxnState.descCollection); err != nil {
 		return err
 	}
@@ -1156,7 +1189,7 @@ func (ex *connExecutor) execStmtInAbortedState(
 		return ex.rollbackSQLTransaction(ctx)
 
 	case *tree.RollbackToSavepoint:
-		return ex.execRollbackToSavepointInAbortedState(ctx, s)
+		return ex.execRollbackToSavepointInAbortedState(ctx, s, res)
 
 	case *tree.Savepoint:
 		if ex.isCommitOnReleaseSavepoint(s.Name) {
@@ -1171,7 +1204,7 @@ func (ex *connExecutor) execStmtInAbortedState(
 			// (Rust driver).
 			res.ResetStmtType((*tree.RollbackToSavepoint)(nil))
 			return ex.execRollbackToSavepointInAbortedState(
-				ctx, &tree.RollbackToSavepoint{Savepoint: s.Name})
+				ctx, &tree.RollbackToSavepoint{Savepoint: s.Name}, res)
 		}
 		return reject()
 
@@ -234,6 +234,16 @@ func (ex *connExecutor) populatePrepared(
 	}
 	p.extendedEvalCtx.PrepareOnly = true
 
+	if fetch, ok := stmt.AST.(*tree.FetchCursor); ok {
+		// The result columns of FETCH are the ones of the cursor.
+		c, err := ex.getCursor(fetch.Name)
+		if err != nil {
+			return 0, err
+		}
+		stmt.Prepared.Columns = c.cols
+		return 0, nil
+	}
+
 	protoTS, err := p.isAsOf(ctx, stmt.AST)
 	if err != nil {
 		return 0, err
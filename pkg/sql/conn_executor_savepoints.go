@@ -101,10 +101,12 @@ func (ex *connExecutor) execSavepointInOpenState(
 	}
 
 	sp := savepoint{
-		name:            s.Name,
-		commitOnRelease: commitOnRelease,
-		kvToken:         token,
-		numDDL:          ex.extraTxnState.numDDL,
+		name:                s.Name,
+		commitOnRelease:     commitOnRelease,
+		kvToken:             token,
+		numDDL:              ex.extraTxnState.numDDL,
+		numListenOps:        ex.notifications.numPendingOps(),
+		deferredConstraints: ex.extraTxnState.deferredConstraints.clone(),
 	}
 	savepoints.push(sp)
 
@@ -189,6 +191,11 @@ func (ex *connExecutor) execRollbackToSavepointInOpenState(
 	}
 
 	ex.extraTxnState.savepoints.popToIdx(idx)
+	if err := ex.rollbackSessionVarsToSavepoint(ctx, idx, res); err != nil {
+		return ex.makeErrEvent(err, s)
+	}
+	ex.notifications.rollbackTo(entry.numListenOps)
+	ex.extraTxnState.deferredConstraints.rollbackTo(&entry.deferredConstraints)
 
 	if entry.kvToken.Initial() {
 		return eventTxnRestart{}, nil
@@ -242,7 +249,7 @@ func (ex *connExecutor) checkRollbackValidity(
 }
 
 func (ex *connExecutor) execRollbackToSavepointInAbortedState(
-	ctx context.Context, s *tree.RollbackToSavepoint,
+	ctx context.Context, s *tree.RollbackToSavepoint, res RestrictedCommandResult,
 ) (fsm.Event, fsm.EventPayload) {
 	makeErr := func(err error) (fsm.Event, fsm.EventPayload) {
 		ev := eventNonRetriableErr{IsCommit: fsm.False}
@@ -267,6 +274,11 @@ func (ex *connExecutor) execRollbackToSavepointInAbortedState(
 	if err := ex.state.mu.txn.RollbackToSavepoint(ctx, entry.kvToken); err != nil {
 		return ex.makeErrEvent(err, s)
 	}
+	if err := ex.rollbackSessionVarsToSavepoint(ctx, idx, res); err != nil {
+		return ex.makeErrEvent(err, s)
+	}
+	ex.notifications.rollbackTo(entry.numListenOps)
+	ex.extraTxnState.deferredConstraints.rollbackTo(&entry.deferredConstraints)
 
 	if entry.kvToken.Initial() {
 		return eventTxnRestart{}, nil
@@ -274,6 +286,19 @@ func (ex *connExecutor) execRollbackToSavepointInAbortedState(
 	return eventSavepointRollback{}, nil
 }
 
+// rollbackSessionVarsToSavepoint restores the session variables that were
+// modified by SET LOCAL since the savepoint at the given index was created.
+func (ex *connExecutor) rollbackSessionVarsToSavepoint(
+	ctx context.Context, idx int, res RestrictedCommandResult,
+) error {
+	sp := &ex.extraTxnState.savepoints[idx]
+	vals := sp.sessionVars
+	// The map may be shared with the copy of the stack saved at the rewind
+	// position, so it is replaced rather than cleared.
+	sp.sessionVars = nil
+	return ex.restoreSessionVars(ctx, vals, res)
+}
+
 // isCommitOnReleaseSavepoint returns true if the savepoint name implies special
 // release semantics: releasing it commits the underlying KV txn.
 func (ex *connExecutor) isCommitOnReleaseSavepoint(savepoint tree.Name) bool {
@@ -312,6 +337,21 @@ type savepoint struct {
 	// more DDL statements were executed since the savepoint's creation.
 	// TODO(knz): support partial DDL cancellation in pending txns.
 	numDDL int
+
+	// sessionVars contains the values that the session variables modified by
+	// SET LOCAL since the creation of the savepoint had at that time. They are
+	// restored when rolling back to the savepoint.
+	sessionVars map[string]string
+
+	// The number of LISTEN and UNLISTEN statements that had been executed in
+	// the transaction at the time the savepoint was created. The statements
+	// executed since then are discarded when rolling back to the savepoint.
+	numListenOps int
+
+	// deferredConstraints is the state of the deferred constraints of the
+	// transaction at the time the savepoint was created. It is restored when
+	// rolling back to the savepoint.
+	deferredConstraints deferredConstraints
 }
 
 type savepointStack []savepoint
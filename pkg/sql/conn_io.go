@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/notify"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
@@ -346,6 +347,25 @@ func (DrainRequest) String() string {
 
 var _ Command = DrainRequest{}
 
+// DeliverNotifications is a command asking for the asynchronous notifications
+// received on the channels the session listens on to be delivered to the
+// client. It is pushed by the session itself when notifications are received,
+// and it is a no-op if the session is inside a transaction; the notifications
+// are then delivered by the first Sync processed outside of a transaction.
+//
+// DeliverNotifications commands don't produce results other than the
+// notifications.
+type DeliverNotifications struct{}
+
+// command implements the Command interface.
+func (DeliverNotifications) command() string { return "deliver notifications" }
+
+func (DeliverNotifications) String() string {
+	return "DeliverNotifications"
+}
+
+var _ Command = DeliverNotifications{}
+
 // SendError is a command that, upon execution, send a specific error to the
 // client. This is used by pgwire to schedule errors to be sent at an
 // appropriate time.
@@ -618,6 +638,9 @@ type ClientComm interface {
 	CreateCopyInResult(pos CmdPos) CopyInResult
 	// CreateDrainResult creates a result for a Drain command.
 	CreateDrainResult(pos CmdPos) DrainResult
+	// CreateDeliverNotificationsResult creates a result for a
+	// DeliverNotifications command.
+	CreateDeliverNotificationsResult(pos CmdPos) DeliverNotificationsResult
 
 	// lockCommunication ensures that no further results are delivered to the
 	// client. The returned ClientLock can be queried to see what results have
@@ -773,6 +796,7 @@ type DeleteResult interface {
 // flushed.
 type SyncResult interface {
 	ResultBase
+	NotificationBuffer
 }
 
 // FlushResult represents the result of a Flush command. When this result is
@@ -787,6 +811,24 @@ type DrainResult interface {
 	ResultBase
 }
 
+// DeliverNotificationsResult represents the result of a DeliverNotifications
+// command. When closed, the buffered notifications are flushed to the client.
+type DeliverNotificationsResult interface {
+	ResultBase
+	NotificationBuffer
+}
+
+// NotificationBuffer is implemented by the results that can carry
+// asynchronous notifications to the client.
+type NotificationBuffer interface {
+	// BufferNotification buffers a notification to be sent to the client when
+	// the result is closed.
+	BufferNotification(notify.Notification)
+	// BufferNotice buffers a notice to be sent to the client when the result
+	// is closed.
+	BufferNotice(pgnotice.Notice)
+}
+
 // EmptyQueryResult represents the result of an empty query (a query
 // representing a blank string).
 type EmptyQueryResult interface {
@@ -872,6 +914,9 @@ type bufferedCommandResult struct {
 
 	// closeCallback, if set, is called when Close()/Discard() is called.
 	closeCallback func(*bufferedCommandResult, resCloseType, error)
+
+	// it, if set, receives the columns and rows, which are not buffered.
+	it *rowsIterator
 }
 
 var _ RestrictedCommandResult = &bufferedCommandResult{}
@@ -883,6 +928,9 @@ func (r *bufferedCommandResult) SetColumns(_ context.Context, cols colinfo.Resul
 		panic("SetColumns() called when errOnly is set")
 	}
 	r.cols = cols
+	if r.it != nil && !r.it.send(rowsIteratorEvent{cols: cols}) {
+		r.err = errRowsIteratorClosed
+	}
 }
 
 // BufferParamStatusUpdate is part of the RestrictedCommandResult interface.
@@ -895,6 +943,11 @@ func (r *bufferedCommandResult) BufferNotice(notice pgnotice.Notice) {
 	panic("unimplemented")
 }
 
+// BufferNotification is part of the NotificationBuffer interface.
+func (r *bufferedCommandResult) BufferNotification(notify.Notification) {
+	panic("unimplemented")
+}
+
 // ResetStmtType is part of the RestrictedCommandResult interface.
 func (r *bufferedCommandResult) ResetStmtType(stmt tree.Statement) {
 	panic("unimplemented")
@@ -907,6 +960,12 @@ func (r *bufferedCommandResult) AddRow(ctx context.Context, row tree.Datums) err
 	}
 	rowCopy := make(tree.Datums, len(row))
 	copy(rowCopy, row)
+	if r.it != nil {
+		if !r.it.send(rowsIteratorEvent{row: rowCopy}) {
+			return errRowsIteratorClosed
+		}
+		return nil
+	}
 	r.rows = append(r.rows, rowCopy)
 	return nil
 }
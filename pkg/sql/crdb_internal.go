@@ -48,6 +48,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
@@ -1732,6 +1733,69 @@ CREATE TABLE crdb_internal.create_type_statements (
 				); err != nil {
 					return err
 				}
+			case descpb.TypeDescriptor_COMPOSITE:
+				name, err := tree.NewUnresolvedObjectName(2, [3]string{typeDesc.GetName(), sc}, 0)
+				if err != nil {
+					return err
+				}
+				node := &tree.CreateType{
+					Variety:           tree.Composite,
+					TypeName:          name,
+					CompositeTypeList: make([]tree.CompositeTypeElem, len(typeDesc.Composite.TupleContents())),
+				}
+				for i, typ := range typeDesc.Composite.TupleContents() {
+					node.CompositeTypeList[i] = tree.CompositeTypeElem{
+						Label: tree.Name(typeDesc.Composite.TupleLabels()[i]),
+						Type:  typ,
+					}
+				}
+				if err := addRow(
+					tree.NewDInt(tree.DInt(db.GetID())),       // database_id
+					tree.NewDString(db.GetName()),             // database_name
+					tree.NewDString(sc),                       // schema_name
+					tree.NewDInt(tree.DInt(typeDesc.GetID())), // descriptor_id
+					tree.NewDString(typeDesc.GetName()),       // descriptor_name
+					tree.NewDString(tree.AsString(node)),      // create_statement
+					tree.DNull,                                // enum_members
+				); err != nil {
+					return err
+				}
+			case descpb.TypeDescriptor_DOMAIN:
+				name, err := tree.NewUnresolvedObjectName(2, [3]string{typeDesc.GetName(), sc}, 0)
+				if err != nil {
+					return err
+				}
+				node := &tree.CreateDomain{
+					TypeName: name,
+					Type:     typeDesc.Domain.BaseType,
+					NotNull:  typeDesc.Domain.NotNull,
+				}
+				if typeDesc.Domain.DefaultExpr != nil {
+					if node.Default, err = parser.ParseExpr(*typeDesc.Domain.DefaultExpr); err != nil {
+						return err
+					}
+				}
+				for _, check := range typeDesc.Domain.Checks {
+					expr, err := parser.ParseExpr(check.Expr)
+					if err != nil {
+						return err
+					}
+					node.Checks = append(node.Checks, tree.DomainCheck{
+						Name: tree.Name(check.Name),
+						Expr: expr,
+					})
+				}
+				if err := addRow(
+					tree.NewDInt(tree.DInt(db.GetID())),       // database_id
+					tree.NewDString(db.GetName()),             // database_name
+					tree.NewDString(sc),                       // schema_name
+					tree.NewDInt(tree.DInt(typeDesc.GetID())), // descriptor_id
+					tree.NewDString(typeDesc.GetName()),       // descriptor_name
+					tree.NewDString(tree.AsString(node)),      // create_statement
+					tree.DNull,                                // enum_members
+				); err != nil {
+					return err
+				}
 			case descpb.TypeDescriptor_MULTIREGION_ENUM:
 				// Multi-region enums are created implicitly, so we don't have create
 				// statements for them.
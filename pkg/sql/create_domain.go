@@ -0,0 +1,209 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/lib/pq/oid"
+)
+
+type createDomainNode struct {
+	n        *tree.CreateDomain
+	typeName *tree.TypeName
+	dbDesc   catalog.DatabaseDescriptor
+}
+
+// Use to satisfy the linter.
+var _ planNode = &createDomainNode{n: nil}
+
+func (p *planner) CreateDomain(ctx context.Context, n *tree.CreateDomain) (planNode, error) {
+	if err := checkSchemaChangeEnabled(
+		ctx,
+		p.ExecCfg(),
+		"CREATE DOMAIN",
+	); err != nil {
+		return nil, err
+	}
+
+	// Resolve the desired new type name.
+	typeName, db, err := resolveNewTypeName(p.RunParams(ctx), n.TypeName)
+	if err != nil {
+		return nil, err
+	}
+	n.TypeName.SetAnnotation(&p.semaCtx.Annotations, typeName)
+	return &createDomainNode{
+		n:        n,
+		typeName: typeName,
+		dbDesc:   db,
+	}, nil
+}
+
+func (n *createDomainNode) startExec(params runParams) error {
+	p := params.p
+	if !p.ExecCfg().Settings.Version.IsActive(params.ctx, clusterversion.Domains) {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"not all nodes are the correct version for domain creation")
+	}
+
+	baseType, err := tree.ResolveType(params.ctx, n.n.Type, p.semaCtx.GetTypeResolver())
+	if err != nil {
+		return err
+	}
+	if err := validateDomainBaseType(baseType); err != nil {
+		return err
+	}
+	domain := &descpb.TypeDescriptor_Domain{
+		BaseType: baseType,
+		NotNull:  n.n.NotNull,
+	}
+
+	if n.n.Default != nil {
+		typedExpr, err := schemaexpr.SanitizeVarFreeExpr(
+			params.ctx, n.n.Default, baseType, "DEFAULT", &p.semaCtx, tree.VolatilityVolatile,
+		)
+		if err != nil {
+			return err
+		}
+		// A NULL default is the same as no default.
+		if typedExpr != tree.DNull {
+			s := tree.Serialize(typedExpr)
+			domain.DefaultExpr = &s
+		}
+	}
+
+	seenNames := make(map[string]struct{}, len(n.n.Checks))
+	for i := range n.n.Checks {
+		if name := string(n.n.Checks[i].Name); name != "" {
+			if _, ok := seenNames[name]; ok {
+				return pgerror.Newf(pgcode.DuplicateObject,
+					"constraint %q for domain %q already exists", name, n.typeName.Type())
+			}
+			seenNames[name] = struct{}{}
+		}
+	}
+	for i := range n.n.Checks {
+		check := &n.n.Checks[i]
+		expr, err := schemaexpr.ValidateDomainCheck(params.ctx, check.Expr, baseType, &p.semaCtx)
+		if err != nil {
+			return err
+		}
+		name := string(check.Name)
+		if name == "" {
+			// Generate a name in the same way as Postgres.
+			name = n.typeName.Type() + "_check"
+			for j := 1; ; j++ {
+				if _, ok := seenNames[name]; !ok {
+					break
+				}
+				name = fmt.Sprintf("%s_check%d", n.typeName.Type(), j)
+			}
+			seenNames[name] = struct{}{}
+		}
+		domain.Checks = append(domain.Checks, descpb.TypeDescriptor_DomainCheck{
+			Name: name,
+			Expr: expr,
+		})
+	}
+
+	// Generate a key in the namespace table and a new id for this type.
+	typeKey, schemaID, err := getCreateTypeParams(params, n.typeName, n.dbDesc)
+	if err != nil {
+		return err
+	}
+	id, err := catalogkv.GenerateUniqueDescID(
+		params.ctx, params.ExecCfg().DB, params.ExecCfg().Codec,
+	)
+	if err != nil {
+		return err
+	}
+	privs, err := p.makeTypePrivileges(params.ctx, schemaID)
+	if err != nil {
+		return err
+	}
+
+	typeDesc := typedesc.NewCreatedMutable(
+		descpb.TypeDescriptor{
+			Name:           n.typeName.Type(),
+			ID:             id,
+			ParentID:       n.dbDesc.GetID(),
+			ParentSchemaID: schemaID,
+			Kind:           descpb.TypeDescriptor_DOMAIN,
+			Domain:         domain,
+			Version:        1,
+			Privileges:     privs,
+		})
+
+	// Create the implicit array type for this type before finishing the type.
+	arrayTypeID, err := p.createArrayType(params, n.typeName, typeDesc, n.dbDesc, schemaID)
+	if err != nil {
+		return err
+	}
+	typeDesc.ArrayTypeID = arrayTypeID
+
+	if err := p.createDescriptorWithID(
+		params.ctx,
+		typeKey.Key(params.ExecCfg().Codec),
+		id,
+		typeDesc,
+		params.EvalContext().Settings,
+		n.typeName.String(),
+	); err != nil {
+		return err
+	}
+
+	return p.logEvent(params.ctx,
+		typeDesc.GetID(),
+		&eventpb.CreateType{
+			TypeName: n.typeName.FQString(),
+		})
+}
+
+// validateDomainBaseType checks that a domain can be defined over the given
+// type. Values of domains are represented by values of the base type, and
+// domains are told apart from their base type by their OID, so the base type
+// cannot be a type that is itself distinguished by its OID.
+func validateDomainBaseType(typ *types.T) error {
+	if typ.UserDefined() {
+		return unimplemented.NewWithIssueDetailf(27796, "domain-over-udt",
+			"domains over user defined type %s are not supported", typ.SQLString())
+	}
+	switch typ.Family() {
+	case types.ArrayFamily, types.TupleFamily, types.OidFamily:
+		return unimplemented.NewWithIssueDetailf(27796, "domain-over-"+typ.Family().Name(),
+			"domains over type %s are not supported", typ.SQLString())
+	}
+	switch typ.Oid() {
+	case oid.T_bpchar, oid.T_char, oid.T_name, oid.T_varbit:
+		return unimplemented.NewWithIssueDetailf(27796, "domain-over-"+typ.Name(),
+			"domains over type %s are not supported", typ.SQLString())
+	}
+	return colinfo.ValidateColumnDefType(typ)
+}
+
+func (n *createDomainNode) Next(params runParams) (bool, error) { return false, nil }
+func (n *createDomainNode) Values() tree.Datums                 { return tree.Datums{} }
+func (n *createDomainNode) Close(ctx context.Context)           {}
+func (n *createDomainNode) ReadingOwnWrites()                   {}
@@ -0,0 +1,418 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/cockroachdb/errors"
+)
+
+// createFunctionNode represents a CREATE FUNCTION statement. The body of the
+// function has already been checked by the optimizer.
+type createFunctionNode struct {
+	n      *tree.CreateFunction
+	dbDesc *dbdesc.Immutable
+	schema catalog.ResolvedSchema
+
+	// funcDeps contains the IDs of the user-defined functions called by the
+	// body of the function.
+	funcDeps []descpb.ID
+}
+
+// ReadingOwnWrites implements the planNodeReadingOwnWrites interface.
+// This is because CREATE FUNCTION performs multiple KV operations on
+// descriptors and expects to see its own writes.
+func (n *createFunctionNode) ReadingOwnWrites() {}
+
+func (n *createFunctionNode) startExec(params runParams) error {
+	if !params.ExecCfg().Settings.Version.IsActive(params.ctx, clusterversion.UserDefinedFunctions) {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"not all nodes are the correct version for user-defined function creation")
+	}
+	if n.n.Replace {
+		telemetry.Inc(sqltelemetry.SchemaChangeCreateCounter("or_replace_function"))
+	} else {
+		telemetry.Inc(sqltelemetry.SchemaChangeCreateCounter("function"))
+	}
+
+	if n.dbDesc.GetID() == keys.SystemDatabaseID {
+		return errors.New("cannot create a function in the system database")
+	}
+	switch n.schema.Kind {
+	case catalog.SchemaPublic, catalog.SchemaUserDefined:
+	case catalog.SchemaTemporary:
+		return pgerror.New(pgcode.FeatureNotSupported,
+			"cannot create a function in a temporary schema")
+	default:
+		return pgerror.Newf(pgcode.InsufficientPrivilege,
+			"schema cannot be modified: %q", n.schema.Name)
+	}
+	if n.schema.ID != keys.PublicSchemaID {
+		sqltelemetry.IncrementUserDefinedSchemaCounter(sqltelemetry.UserDefinedSchemaUsedByObject)
+	}
+
+	fnName := tree.MakeNewQualifiedFunctionName(n.dbDesc.GetName(), n.schema.Name, n.n.Name.Object())
+	args, returnType, err := n.resolveSignature(params)
+	if err != nil {
+		return err
+	}
+	volatility := descpb.FunctionDescriptor_VOLATILE
+	switch n.n.Volatility {
+	case tree.VolatilityImmutable:
+		volatility = descpb.FunctionDescriptor_IMMUTABLE
+	case tree.VolatilityStable:
+		volatility = descpb.FunctionDescriptor_STABLE
+	}
+
+	// Check whether an object with the same name exists already. Functions
+	// share the namespace of tables and types.
+	exists, collided, err := catalogkv.LookupObjectID(
+		params.ctx, params.p.txn, params.ExecCfg().Codec,
+		n.dbDesc.GetID(), n.schema.ID, fnName.Object(),
+	)
+	if err != nil {
+		return err
+	}
+	if exists {
+		desc, err := params.p.Descriptors().GetMutableDescriptorByID(params.ctx, collided, params.p.txn)
+		if err != nil {
+			return sqlerrors.WrapErrorWhileConstructingObjectAlreadyExistsErr(err)
+		}
+		fnDesc, isFunction := desc.(*funcdesc.Mutable)
+		if !isFunction || !n.n.Replace {
+			return sqlerrors.MakeObjectAlreadyExistsError(desc.DescriptorProto(), fnName.Object())
+		}
+		return n.replaceFunction(params, fnDesc, &fnName, args, returnType, volatility)
+	}
+
+	id, err := catalogkv.GenerateUniqueDescID(params.ctx, params.ExecCfg().DB, params.ExecCfg().Codec)
+	if err != nil {
+		return err
+	}
+	// The privileges of the schema are not inherited; like in Postgres,
+	// everyone can execute a new function.
+	privs := descpb.NewDefaultPrivilegeDescriptor(params.p.User())
+	privs.Grant(params.p.User(), privilege.List{privilege.ALL})
+	privs.Grant(security.PublicRoleName(), privilege.List{privilege.EXECUTE})
+
+	fnDesc := funcdesc.NewCreatedMutable(descpb.FunctionDescriptor{
+		Name:           fnName.Object(),
+		ID:             id,
+		ParentID:       n.dbDesc.GetID(),
+		ParentSchemaID: n.schema.ID,
+		Version:        1,
+		Privileges:     privs,
+		Args:           args,
+		ReturnType:     returnType,
+		Volatility:     volatility,
+		Body:           n.n.Body,
+
+		DependsOnFunctions: n.funcDeps,
+	})
+	key := catalogkv.MakeObjectNameKey(
+		params.ctx, params.ExecCfg().Settings, n.dbDesc.GetID(), n.schema.ID, fnName.Object(),
+	)
+	if err := params.p.createDescriptorWithID(
+		params.ctx,
+		key.Key(params.ExecCfg().Codec),
+		id,
+		fnDesc,
+		params.EvalContext().Settings,
+		tree.AsStringWithFQNames(n.n, params.Ann()),
+	); err != nil {
+		return err
+	}
+	if err := params.p.updateFunctionBackRefs(
+		params.ctx, fnDesc.ID, nil /* oldDeps */, fnDesc.DependsOnFunctions,
+		fmt.Sprintf("updating references for function %s", fnName.FQString()),
+	); err != nil {
+		return err
+	}
+
+	return params.p.logEvent(params.ctx,
+		fnDesc.GetID(),
+		&eventpb.CreateFunction{
+			FunctionName: fnName.FQString(),
+			Owner:        fnDesc.GetPrivileges().Owner().Normalized(),
+		})
+}
+
+// resolveSignature resolves the types of the arguments and of the return
+// value of the function.
+func (n *createFunctionNode) resolveSignature(
+	params runParams,
+) ([]descpb.FunctionDescriptor_Argument, *types.T, error) {
+	args := make([]descpb.FunctionDescriptor_Argument, len(n.n.Args))
+	for i := range n.n.Args {
+		typ, err := n.resolveType(params, n.n.Args[i].Type)
+		if err != nil {
+			return nil, nil, err
+		}
+		args[i] = descpb.FunctionDescriptor_Argument{Name: string(n.n.Args[i].Name), Type: typ}
+	}
+	returnType, err := n.resolveType(params, n.n.ReturnType)
+	if err != nil {
+		return nil, nil, err
+	}
+	return args, returnType, nil
+}
+
+func (n *createFunctionNode) resolveType(
+	params runParams, ref tree.ResolvableTypeReference,
+) (*types.T, error) {
+	typ, err := tree.ResolveType(params.ctx, ref, params.p.semaCtx.GetTypeResolver())
+	if err != nil {
+		return nil, err
+	}
+	if !typ.UserDefined() {
+		return typ, nil
+	}
+	// Functions cannot refer to types in other databases, like tables.
+	typName, typDesc, err := params.p.GetTypeDescriptor(
+		params.ctx, typedesc.UserDefinedTypeOIDToID(typ.Oid()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if typDesc.GetParentID() != n.dbDesc.GetID() {
+		return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+			"cross database type references are not supported: %s", typName.String())
+	}
+	return typ, nil
+}
+
+// replaceFunction replaces the definition of the given existing function in
+// CREATE OR REPLACE FUNCTION. Like in Postgres, the signature of the function
+// cannot be changed.
+func (n *createFunctionNode) replaceFunction(
+	params runParams,
+	fnDesc *funcdesc.Mutable,
+	fnName *tree.FunctionName,
+	args []descpb.FunctionDescriptor_Argument,
+	returnType *types.T,
+	volatility descpb.FunctionDescriptor_Volatility,
+) error {
+	if fnDesc.Dropped() {
+		return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			"function %q being dropped, try again later", fnDesc.Name)
+	}
+	if err := params.p.canModifyFunction(params.ctx, fnDesc); err != nil {
+		return err
+	}
+	if !sameFunctionArgTypes(fnDesc.Args, args) {
+		return pgerror.Newf(pgcode.InvalidFunctionDefinition,
+			"cannot change the argument types of function %s", fnName)
+	}
+	if !fnDesc.ReturnType.Identical(returnType) {
+		return pgerror.Newf(pgcode.InvalidFunctionDefinition,
+			"cannot change return type of existing function %s", fnName)
+	}
+	// A function which calls itself does not depend on itself.
+	oldDeps := fnDesc.DependsOnFunctions
+	newDeps := make([]descpb.ID, 0, len(n.funcDeps))
+	for _, id := range n.funcDeps {
+		if id != fnDesc.ID {
+			newDeps = append(newDeps, id)
+		}
+	}
+	fnDesc.Args = args
+	fnDesc.Volatility = volatility
+	fnDesc.Body = n.n.Body
+	fnDesc.DependsOnFunctions = newDeps
+	jobDesc := tree.AsStringWithFQNames(n.n, params.Ann())
+	if err := params.p.writeFunctionDescChange(params.ctx, fnDesc, jobDesc); err != nil {
+		return err
+	}
+	if err := params.p.updateFunctionBackRefs(
+		params.ctx, fnDesc.ID, oldDeps, newDeps, jobDesc,
+	); err != nil {
+		return err
+	}
+	return params.p.logEvent(params.ctx,
+		fnDesc.GetID(),
+		&eventpb.CreateFunction{
+			FunctionName: fnName.FQString(),
+			Owner:        fnDesc.GetPrivileges().Owner().Normalized(),
+			IsReplace:    true,
+		})
+}
+
+func (*createFunctionNode) Next(runParams) (bool, error) { return false, nil }
+func (*createFunctionNode) Values() tree.Datums          { return tree.Datums{} }
+func (*createFunctionNode) Close(context.Context)        {}
+
+// sameFunctionArgTypes returns whether the given function arguments have
+// the same types, ignoring their names.
+func sameFunctionArgTypes(a, b []descpb.FunctionDescriptor_Argument) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Type.Identical(b[i].Type) {
+			return false
+		}
+	}
+	return true
+}
+
+// canModifyFunction returns an error if the current user cannot replace or
+// drop the given function, which requires being its owner.
+func (p *planner) canModifyFunction(ctx context.Context, desc *funcdesc.Mutable) error {
+	hasAdmin, err := p.HasAdminRole(ctx)
+	if err != nil {
+		return err
+	}
+	if hasAdmin {
+		return nil
+	}
+
+	hasOwnership, err := p.HasOwnership(ctx, desc)
+	if err != nil {
+		return err
+	}
+	if !hasOwnership {
+		return pgerror.Newf(pgcode.InsufficientPrivilege,
+			"must be owner of function %s", tree.Name(desc.GetName()))
+	}
+	return nil
+}
+
+// writeFunctionDescChange writes a modified function descriptor and ensures
+// that a schema change job exists to wait for the leases on the previous
+// version of the function to be released, and to drain its old names.
+func (p *planner) writeFunctionDescChange(
+	ctx context.Context, desc *funcdesc.Mutable, jobDesc string,
+) error {
+	job, jobExists := p.extendedEvalCtx.SchemaChangeJobCache[desc.ID]
+	if jobExists {
+		// Update it.
+		if err := job.WithTxn(p.txn).SetDescription(ctx,
+			func(ctx context.Context, desc string) (string, error) {
+				return desc + "; " + jobDesc, nil
+			},
+		); err != nil {
+			return err
+		}
+		log.Infof(ctx, "job %d: updated with for change on function %d", *job.ID(), desc.ID)
+	} else {
+		// Or, create a new job.
+		jobRecord := jobs.Record{
+			Description:   jobDesc,
+			Username:      p.User(),
+			DescriptorIDs: descpb.IDs{desc.ID},
+			Details: jobspb.SchemaChangeDetails{
+				DescID: desc.ID,
+				// The version distinction for database jobs doesn't matter for
+				// function jobs.
+				FormatVersion: jobspb.DatabaseJobFormatVersion,
+			},
+			Progress: jobspb.SchemaChangeProgress{},
+		}
+		newJob, err := p.extendedEvalCtx.QueueJob(jobRecord)
+		if err != nil {
+			return err
+		}
+		p.extendedEvalCtx.SchemaChangeJobCache[desc.ID] = newJob
+		log.Infof(ctx, "queued new schema change job %d for function %d", *newJob.ID(), desc.ID)
+	}
+
+	return p.writeFunctionDesc(ctx, desc)
+}
+
+// writeFunctionDesc writes a modified function descriptor.
+func (p *planner) writeFunctionDesc(ctx context.Context, desc *funcdesc.Mutable) error {
+	if err := desc.Validate(); err != nil {
+		return err
+	}
+	b := p.txn.NewBatch()
+	if err := p.Descriptors().WriteDescToBatch(
+		ctx, p.extendedEvalCtx.Tracing.KVTracingEnabled(), desc, b,
+	); err != nil {
+		return err
+	}
+	return p.txn.Run(ctx, b)
+}
+
+// updateFunctionBackRefs updates the back-references of the user-defined
+// functions on which the view or function with the given ID depends, when
+// its dependencies change from oldDeps to newDeps. Functions that are being
+// dropped are left untouched.
+func (p *planner) updateFunctionBackRefs(
+	ctx context.Context, dependentID descpb.ID, oldDeps, newDeps []descpb.ID, jobDesc string,
+) error {
+	contains := func(ids []descpb.ID, id descpb.ID) bool {
+		for i := range ids {
+			if ids[i] == id {
+				return true
+			}
+		}
+		return false
+	}
+	update := func(id descpb.ID, fn func(*funcdesc.Mutable)) error {
+		desc, err := p.Descriptors().GetMutableDescriptorByID(ctx, id, p.txn)
+		if err != nil {
+			return err
+		}
+		fnDesc, ok := desc.(*funcdesc.Mutable)
+		if !ok {
+			return errors.AssertionFailedf("descriptor %d is not a function", errors.Safe(id))
+		}
+		if fnDesc.Dropped() {
+			return nil
+		}
+		fn(fnDesc)
+		return p.writeFunctionDescChange(ctx, fnDesc, jobDesc)
+	}
+	for _, id := range oldDeps {
+		if contains(newDeps, id) {
+			continue
+		}
+		if err := update(id, func(desc *funcdesc.Mutable) {
+			desc.RemoveDependedOnBy(dependentID)
+		}); err != nil {
+			return err
+		}
+	}
+	for _, id := range newDeps {
+		if contains(oldDeps, id) {
+			continue
+		}
+		if err := update(id, func(desc *funcdesc.Mutable) {
+			desc.AddDependedOnBy(dependentID)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -597,6 +597,15 @@ func (p *planner) MaybeUpgradeDependentOldForeignKeyVersionTables(
 	return nil
 }
 
+// errDeferrableUniqueIndex is returned for the deferrable unique constraints
+// that would be enforced by an index: the index entries are written by each
+// statement, so they can't violate the constraint until the end of the
+// transaction.
+var errDeferrableUniqueIndex = errors.WithHint(
+	pgerror.New(pgcode.FeatureNotSupported, "unique constraints with an index cannot be deferrable"),
+	"use UNIQUE WITHOUT INDEX to create a deferrable unique constraint",
+)
+
 // ResolveUniqueWithoutIndexConstraint looks up the columns mentioned in a
 // UNIQUE WITHOUT INDEX constraint and adds metadata representing that
 // constraint to the descriptor.
@@ -611,6 +620,7 @@ func ResolveUniqueWithoutIndexConstraint(
 	colNames []string,
 	ts TableState,
 	validationBehavior tree.ValidationBehavior,
+	deferrability tree.ConstraintDeferrability,
 ) error {
 	var colSet catalog.TableColSet
 	cols := make([]*descpb.ColumnDescriptor, len(colNames))
@@ -662,10 +672,11 @@ func ResolveUniqueWithoutIndexConstraint(
 	}
 
 	uc := descpb.UniqueWithoutIndexConstraint{
-		Name:      constraintName,
-		TableID:   tbl.ID,
-		ColumnIDs: columnIDs,
-		Validity:  validity,
+		Name:          constraintName,
+		TableID:       tbl.ID,
+		ColumnIDs:     columnIDs,
+		Validity:      validity,
+		Deferrability: descpb.ConstraintDeferrabilityValue[deferrability],
 	}
 
 	if ts == NewTable {
@@ -717,6 +728,9 @@ func ResolveFK(
 	validationBehavior tree.ValidationBehavior,
 	evalCtx *tree.EvalContext,
 ) error {
+	if err := checkDeferrableConstraintVersion(ctx, evalCtx.Settings, d.Deferrability); err != nil {
+		return err
+	}
 	var originColSet catalog.TableColSet
 	originCols := make([]*descpb.ColumnDescriptor, len(d.FromCols))
 	for i, col := range d.FromCols {
@@ -932,6 +946,7 @@ func ResolveFK(
 		OnDelete:            descpb.ForeignKeyReferenceActionValue[d.Actions.Delete],
 		OnUpdate:            descpb.ForeignKeyReferenceActionValue[d.Actions.Update],
 		Match:               descpb.CompositeKeyMatchMethodValue[d.Match],
+		Deferrability:       descpb.ConstraintDeferrabilityValue[d.Deferrability],
 	}
 
 	if ts == NewTable {
@@ -1584,6 +1599,9 @@ func NewTableDesc(
 				// We will add the unique constraint below.
 				break
 			}
+			if d.Deferrability != tree.ConstraintNotDeferrable {
+				return nil, errDeferrableUniqueIndex
+			}
 			idx := descpb.IndexDescriptor{
 				Name:             string(d.Name),
 				Unique:           true,
@@ -1634,7 +1652,8 @@ func NewTableDesc(
 			if d.Interleave != nil {
 				return nil, unimplemented.NewWithIssue(9148, "use CREATE INDEX to make interleaved indexes")
 			}
-		case *tree.CheckConstraintTableDef, *tree.ForeignKeyConstraintTableDef, *tree.FamilyTableDef:
+		case *tree.CheckConstraintTableDef, *tree.ForeignKeyConstraintTableDef, *tree.FamilyTableDef,
+			*tree.ExclusionConstraintTableDef:
 			// pass, handled below.
 
 		default:
@@ -1776,7 +1795,7 @@ func NewTableDesc(
 				// Add a unique constraint.
 				if err := ResolveUniqueWithoutIndexConstraint(
 					ctx, &desc, string(d.Unique.ConstraintName), []string{string(d.Name)}, NewTable,
-					tree.ValidationDefault,
+					tree.ValidationDefault, tree.ConstraintNotDeferrable,
 				); err != nil {
 					return nil, err
 				}
@@ -1821,8 +1840,11 @@ func NewTableDesc(
 				for i := range colNames {
 					colNames[i] = string(d.Columns[i].Column)
 				}
+				if err := checkDeferrableConstraintVersion(ctx, evalCtx.Settings, d.Deferrability); err != nil {
+					return nil, err
+				}
 				if err := ResolveUniqueWithoutIndexConstraint(
-					ctx, &desc, string(d.Name), colNames, NewTable, tree.ValidationDefault,
+					ctx, &desc, string(d.Name), colNames, NewTable, tree.ValidationDefault, d.Deferrability,
 				); err != nil {
 					return nil, err
 				}
@@ -1831,6 +1853,18 @@ func NewTableDesc(
 		case *tree.IndexTableDef, *tree.FamilyTableDef, *tree.LikeTableDef:
 			// Pass, handled above.
 
+		case *tree.ExclusionConstraintTableDef:
+			// The constraint is built once the column IDs have been allocated. The
+			// ID of its backing index is allocated below.
+			ec, idx, err := makeExclusionConstraint(ctx, st, &desc, d, indexEncodingVersion)
+			if err != nil {
+				return nil, err
+			}
+			if err := desc.AddIndex(idx, false /* primary */); err != nil {
+				return nil, err
+			}
+			desc.ExclusionConstraints = append(desc.ExclusionConstraints, ec)
+
 		case *tree.CheckConstraintTableDef:
 			ck, err := ckBuilder.Build(d)
 			if err != nil {
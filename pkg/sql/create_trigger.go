@@ -0,0 +1,236 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/norm"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/optbuilder"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+)
+
+type createTriggerNode struct {
+	n         *tree.CreateTrigger
+	tableDesc *tabledesc.Mutable
+
+	// deps contains the relations referenced by the body of the trigger.
+	deps []descpb.TableDescriptor_Reference
+}
+
+// CreateTrigger creates a row-level trigger on a table.
+// Privileges: CREATE on table.
+//   notes: postgres requires TRIGGER on the table.
+func (p *planner) CreateTrigger(ctx context.Context, n *tree.CreateTrigger) (planNode, error) {
+	if err := checkSchemaChangeEnabled(
+		ctx,
+		p.ExecCfg(),
+		"CREATE TRIGGER",
+	); err != nil {
+		return nil, err
+	}
+
+	tableDesc, err := p.ResolveMutableTableDescriptor(
+		ctx, &n.Table, true /* required */, tree.ResolveRequireTableDesc,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	if findTrigger(tableDesc, n.Name) != -1 {
+		return nil, pgerror.Newf(pgcode.DuplicateObject,
+			"trigger %q for table %q already exists", n.Name, tableDesc.GetName())
+	}
+
+	for i, event := range n.Events {
+		if n.Events[:i].Contains(event) {
+			return nil, pgerror.New(pgcode.Syntax, "duplicate trigger events specified")
+		}
+	}
+
+	// Build the body now, so that errors are reported when the trigger is
+	// created rather than when it fires.
+	deps, err := p.buildTriggerBody(ctx, n)
+	if err != nil {
+		return nil, err
+	}
+
+	return &createTriggerNode{n: n, tableDesc: tableDesc, deps: deps}, nil
+}
+
+// ReadingOwnWrites implements the planNodeReadingOwnWrites interface.
+// This is because CREATE TRIGGER performs multiple KV operations on
+// descriptors and expects to see its own writes.
+func (n *createTriggerNode) ReadingOwnWrites() {}
+
+func (n *createTriggerNode) startExec(params runParams) error {
+	if !params.ExecCfg().Settings.Version.IsActive(params.ctx, clusterversion.RowLevelTriggers) {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"not all nodes are the correct version for trigger creation")
+	}
+	telemetry.Inc(sqltelemetry.SchemaChangeCreateCounter("trigger"))
+
+	trigger := descpb.TriggerDescriptor{
+		Name:       string(n.n.Name),
+		ActionTime: descpb.TriggerDescriptor_ActionTime(n.n.ActionTime),
+		Body:       n.n.Body,
+		DependsOn:  n.deps,
+	}
+	for _, event := range n.n.Events {
+		trigger.Events = append(trigger.Events, descpb.TriggerDescriptor_Event(event))
+	}
+	n.tableDesc.Triggers = append(n.tableDesc.Triggers, trigger)
+
+	if err := n.tableDesc.Validate(
+		params.ctx, catalogkv.NewOneLevelUncachedDescGetter(params.p.txn, params.ExecCfg().Codec),
+	); err != nil {
+		return err
+	}
+
+	jobDesc := tree.AsStringWithFQNames(n.n, params.Ann())
+	if err := params.p.writeSchemaChange(
+		params.ctx, n.tableDesc, descpb.InvalidMutationID, jobDesc,
+	); err != nil {
+		return err
+	}
+	if err := params.p.updateTriggerBackRefs(
+		params.ctx, n.tableDesc, nil /* oldDeps */, jobDesc,
+	); err != nil {
+		return err
+	}
+
+	return params.p.logEvent(params.ctx,
+		n.tableDesc.ID,
+		&eventpb.CreateTrigger{
+			TableName:   n.n.Table.FQString(),
+			TriggerName: string(n.n.Name),
+		})
+}
+
+func (n *createTriggerNode) Next(runParams) (bool, error) { return false, nil }
+func (n *createTriggerNode) Values() tree.Datums          { return tree.Datums{} }
+func (n *createTriggerNode) Close(context.Context)        {}
+
+// buildTriggerBody builds the body of the trigger created by the given
+// statement with the optimizer, which resolves the names it references. See
+// optbuilder.BuildTriggerBody. It returns the relations referenced by the body.
+func (p *planner) buildTriggerBody(
+	ctx context.Context, n *tree.CreateTrigger,
+) ([]descpb.TableDescriptor_Reference, error) {
+	trigger := cat.Trigger{
+		Name:       n.Name,
+		ActionTime: n.ActionTime,
+		Events:     n.Events,
+		Body:       n.Body,
+	}
+	catalog := &p.optPlanningCtx.catalog
+	ds, _, err := catalog.ResolveDataSource(ctx, cat.Flags{}, &n.Table)
+	if err != nil {
+		return nil, err
+	}
+	var f norm.Factory
+	f.Init(p.EvalContext(), catalog)
+	viewDeps, err := optbuilder.BuildTriggerBody(
+		ctx, &p.semaCtx, p.EvalContext(), catalog, &f, ds.(cat.Table), &trigger,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return triggerDependencies(viewDeps)
+}
+
+// triggerDependencies returns the relations referenced by the body of a
+// trigger, given its dependencies as collected by the optimizer, with one
+// reference per relation. Sequences are not included, since the
+// back-references of a sequence to a table are used for the columns of the
+// table that use the sequence. Virtual tables cannot have back-references.
+func triggerDependencies(viewDeps opt.ViewDeps) ([]descpb.TableDescriptor_Reference, error) {
+	var ids []descpb.ID
+	cols := make(map[descpb.ID]*util.FastIntSet)
+	for _, d := range viewDeps {
+		desc, err := getDescForDataSource(d.DataSource)
+		if err != nil {
+			return nil, err
+		}
+		if desc.IsSequence() || desc.IsVirtualTable() {
+			continue
+		}
+		set, ok := cols[desc.ID]
+		if !ok {
+			set = &util.FastIntSet{}
+			cols[desc.ID] = set
+			ids = append(ids, desc.ID)
+		}
+		d.ColumnOrdinals.ForEach(func(ord int) {
+			if tab, ok := d.DataSource.(cat.Table); ok {
+				// Only ordinary columns are referenced by name.
+				if col := tab.Column(ord); col.Kind() == cat.Ordinary {
+					set.Add(int(col.ColID()))
+				}
+			} else {
+				set.Add(int(desc.Columns[ord].ID))
+			}
+		})
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	refs := make([]descpb.TableDescriptor_Reference, len(ids))
+	for i, id := range ids {
+		refs[i].ID = id
+		cols[id].ForEach(func(c int) {
+			refs[i].ColumnIDs = append(refs[i].ColumnIDs, descpb.ColumnID(c))
+		})
+	}
+	return refs, nil
+}
+
+// makeCreateTriggerAST returns the CREATE TRIGGER statement for the given
+// trigger of the table with the given name.
+func makeCreateTriggerAST(tn *tree.TableName, t *descpb.TriggerDescriptor) *tree.CreateTrigger {
+	n := &tree.CreateTrigger{
+		Name:       tree.Name(t.Name),
+		ActionTime: tree.TriggerActionTime(t.ActionTime),
+		Table:      *tn,
+		Body:       t.Body,
+	}
+	for _, e := range t.Events {
+		n.Events = append(n.Events, tree.TriggerEvent(e))
+	}
+	return n
+}
+
+// findTrigger returns the index of the trigger with the given name in the
+// table descriptor, or -1 if there is no such trigger.
+func findTrigger(tableDesc *tabledesc.Mutable, name tree.Name) int {
+	for i := range tableDesc.Triggers {
+		if tableDesc.Triggers[i].Name == string(name) {
+			return i
+		}
+	}
+	return -1
+}
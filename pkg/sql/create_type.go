@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkeys"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/enum"
@@ -98,6 +99,8 @@ func (n *createTypeNode) startExec(params runParams) error {
 	switch n.n.Variety {
 	case tree.Enum:
 		return params.p.createUserDefinedEnum(params, n)
+	case tree.Composite:
+		return params.p.createUserDefinedComposite(params, n)
 	default:
 		return unimplemented.NewWithIssue(25123, "CREATE TYPE")
 	}
@@ -243,6 +246,19 @@ func (p *planner) createArrayType(
 	switch t := typDesc.Kind; t {
 	case descpb.TypeDescriptor_ENUM, descpb.TypeDescriptor_MULTIREGION_ENUM:
 		elemTyp = types.MakeEnum(typedesc.TypeIDToOID(typDesc.GetID()), typedesc.TypeIDToOID(id))
+	case descpb.TypeDescriptor_COMPOSITE:
+		elemTyp = types.MakeComposite(
+			typedesc.TypeIDToOID(typDesc.GetID()),
+			typedesc.TypeIDToOID(id),
+			typDesc.Composite.TupleContents(),
+			typDesc.Composite.TupleLabels(),
+		)
+	case descpb.TypeDescriptor_DOMAIN:
+		elemTyp = types.MakeDomain(
+			typedesc.TypeIDToOID(typDesc.GetID()),
+			typedesc.TypeIDToOID(id),
+			typDesc.Domain.BaseType,
+		)
 	default:
 		return 0, errors.AssertionFailedf("cannot make array type for kind %s", t.String())
 	}
@@ -331,19 +347,11 @@ func (p *planner) createEnumWithID(
 		}
 	}
 
-	// Database privileges and Type privileges do not overlap so there is nothing
-	// to inherit.
-	// However having USAGE on a parent schema of the type
-	// gives USAGE privilege to the type.
-	privs := descpb.NewDefaultPrivilegeDescriptor(params.p.User())
-	resolvedSchema, err := p.Descriptors().ResolveSchemaByID(params.ctx, p.Txn(), schemaID)
+	privs, err := p.makeTypePrivileges(params.ctx, schemaID)
 	if err != nil {
 		return err
 	}
 
-	inheritUsagePrivilegeFromSchema(resolvedSchema, privs)
-	privs.Grant(params.p.User(), privilege.List{privilege.ALL})
-
 	enumKind := descpb.TypeDescriptor_ENUM
 	var regionConfig *descpb.TypeDescriptor_RegionConfig
 	if enumType == enumTypeMultiRegion {
@@ -404,6 +412,113 @@ func (p *planner) createEnumWithID(
 		})
 }
 
+func (p *planner) createUserDefinedComposite(params runParams, n *createTypeNode) error {
+	if !p.ExecCfg().Settings.Version.IsActive(params.ctx, clusterversion.CompositeTypes) {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"not all nodes are the correct version for composite type creation")
+	}
+
+	contents := make([]*types.T, len(n.n.CompositeTypeList))
+	labels := make([]string, len(n.n.CompositeTypeList))
+	seenLabels := make(map[string]struct{}, len(n.n.CompositeTypeList))
+	for i := range n.n.CompositeTypeList {
+		elem := &n.n.CompositeTypeList[i]
+		label := string(elem.Label)
+		if _, ok := seenLabels[label]; ok {
+			return pgerror.Newf(pgcode.DuplicateColumn,
+				"column %q specified more than once", label)
+		}
+		seenLabels[label] = struct{}{}
+
+		typ, err := tree.ResolveType(params.ctx, elem.Type, p.semaCtx.GetTypeResolver())
+		if err != nil {
+			return err
+		}
+		// The type descriptors of user defined types only track the tables
+		// that reference them, so they can't be used in composite types yet.
+		if typ.UserDefined() {
+			return unimplemented.NewWithIssueDetailf(27792, "composite-udt-field",
+				"user defined type %s cannot be used in a composite type", typ.SQLString())
+		}
+		if err := colinfo.ValidateColumnDefType(typ); err != nil {
+			return err
+		}
+		contents[i] = typ
+		labels[i] = label
+	}
+
+	// Generate a key in the namespace table and a new id for this type.
+	typeKey, schemaID, err := getCreateTypeParams(params, n.typeName, n.dbDesc)
+	if err != nil {
+		return err
+	}
+	id, err := catalogkv.GenerateUniqueDescID(
+		params.ctx, params.ExecCfg().DB, params.ExecCfg().Codec,
+	)
+	if err != nil {
+		return err
+	}
+	privs, err := p.makeTypePrivileges(params.ctx, schemaID)
+	if err != nil {
+		return err
+	}
+
+	typeDesc := typedesc.NewCreatedMutable(
+		descpb.TypeDescriptor{
+			Name:           n.typeName.Type(),
+			ID:             id,
+			ParentID:       n.dbDesc.GetID(),
+			ParentSchemaID: schemaID,
+			Kind:           descpb.TypeDescriptor_COMPOSITE,
+			Composite:      types.MakeLabeledTuple(contents, labels),
+			Version:        1,
+			Privileges:     privs,
+		})
+
+	// Create the implicit array type for this type before finishing the type.
+	arrayTypeID, err := p.createArrayType(params, n.typeName, typeDesc, n.dbDesc, schemaID)
+	if err != nil {
+		return err
+	}
+	typeDesc.ArrayTypeID = arrayTypeID
+
+	if err := p.createDescriptorWithID(
+		params.ctx,
+		typeKey.Key(params.ExecCfg().Codec),
+		id,
+		typeDesc,
+		params.EvalContext().Settings,
+		n.typeName.String(),
+	); err != nil {
+		return err
+	}
+
+	return p.logEvent(params.ctx,
+		typeDesc.GetID(),
+		&eventpb.CreateType{
+			TypeName: n.typeName.FQString(),
+		})
+}
+
+// makeTypePrivileges returns the privileges of a new type in the given schema.
+func (p *planner) makeTypePrivileges(
+	ctx context.Context, schemaID descpb.ID,
+) (*descpb.PrivilegeDescriptor, error) {
+	// Database privileges and Type privileges do not overlap so there is nothing
+	// to inherit.
+	// However having USAGE on a parent schema of the type
+	// gives USAGE privilege to the type.
+	privs := descpb.NewDefaultPrivilegeDescriptor(p.User())
+	resolvedSchema, err := p.Descriptors().ResolveSchemaByID(ctx, p.Txn(), schemaID)
+	if err != nil {
+		return nil, err
+	}
+
+	inheritUsagePrivilegeFromSchema(resolvedSchema, privs)
+	privs.Grant(p.User(), privilege.List{privilege.ALL})
+	return privs, nil
+}
+
 func (n *createTypeNode) Next(params runParams) (bool, error) { return false, nil }
 func (n *createTypeNode) Values() tree.Datums                 { return tree.Datums{} }
 func (n *createTypeNode) Close(ctx context.Context)           {}
@@ -52,6 +52,10 @@ type createViewNode struct {
 	// depends on. This is collected during the construction of
 	// the view query's logical plan.
 	planDeps planDependencies
+
+	// funcDeps contains the IDs of the user-defined functions called by the
+	// view query.
+	funcDeps []descpb.ID
 }
 
 // ReadingOwnWrites implements the planNodeReadingOwnWrites interface.
@@ -140,11 +144,13 @@ func (n *createViewNode) startExec(params runParams) error {
 	privs := CreateInheritedPrivilegesFromDBDesc(n.dbDesc, params.SessionData().User())
 
 	var newDesc *tabledesc.Mutable
+	var oldFuncDeps []descpb.ID
 
 	// If replacingDesc != nil, we found an existing view while resolving
 	// the name for our view. So instead of creating a new view, replace
 	// the existing one.
 	if replacingDesc != nil {
+		oldFuncDeps = replacingDesc.DependsOnFunctions
 		newDesc, err = params.p.replaceViewDesc(params.ctx, n, replacingDesc, backRefMutables)
 		if err != nil {
 			return err
@@ -203,6 +209,7 @@ func (n *createViewNode) startExec(params runParams) error {
 		for backrefID := range n.planDeps {
 			desc.DependsOn = append(desc.DependsOn, backrefID)
 		}
+		desc.DependsOnFunctions = n.funcDeps
 
 		// TODO (lucy): I think this needs a NodeFormatter implementation. For now,
 		// do some basic string formatting (not accurate in the general case).
@@ -246,6 +253,14 @@ func (n *createViewNode) startExec(params runParams) error {
 		}
 	}
 
+	// Persist the back-references in all referenced functions.
+	if err := params.p.updateFunctionBackRefs(
+		params.ctx, newDesc.ID, oldFuncDeps, n.funcDeps,
+		fmt.Sprintf("updating view reference %q in functions", n.viewName),
+	); err != nil {
+		return err
+	}
+
 	// Install back references to types used by this view.
 	if err := params.p.addBackRefsFromAllTypesInTable(params.ctx, newDesc); err != nil {
 		return err
@@ -372,6 +387,7 @@ func (p *planner) replaceViewDesc(
 	for backrefID := range n.planDeps {
 		toReplace.DependsOn = append(toReplace.DependsOn, backrefID)
 	}
+	toReplace.DependsOnFunctions = n.funcDeps
 
 	// Since we are replacing an existing view here, we need to write the new
 	// descriptor into place.
@@ -0,0 +1,363 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+)
+
+// deferredConstraints tracks the checks of the deferrable constraints of a
+// transaction.
+//
+// The checks of foreign key and unique constraints are planned as
+// post-queries of the mutations. When a post-query of a deferrable constraint
+// finds violations while the constraint is deferred, the statement does not
+// fail: instead, the key values of the violating rows are recorded as pending,
+// and they are checked again when the transaction commits, or when SET
+// CONSTRAINTS makes the constraint immediate. Only these keys need to be
+// checked, since the statements that run while the constraint is immediate
+// cannot introduce violations.
+type deferredConstraints struct {
+	// all is the mode set by SET CONSTRAINTS ALL, if any.
+	all constraintsMode
+	// modes contains the modes set by SET CONSTRAINTS for specific constraints
+	// since the last SET CONSTRAINTS ALL.
+	modes map[string]constraintsMode
+	// pending contains the constraints that need to be validated.
+	pending []pendingConstraint
+}
+
+// constraintsMode is the mode of a deferrable constraint set by SET
+// CONSTRAINTS.
+type constraintsMode int
+
+const (
+	// constraintsModeDefault means that the constraint is deferred if it is
+	// INITIALLY DEFERRED.
+	constraintsModeDefault constraintsMode = iota
+	constraintsModeImmediate
+	constraintsModeDeferred
+)
+
+// pendingConstraint identifies a constraint that needs to be validated before
+// the transaction commits.
+type pendingConstraint struct {
+	tableID       descpb.ID
+	name          string
+	deferrability tree.ConstraintDeferrability
+	// keys are the values of the constraint columns that violated the
+	// constraint, in the order of the columns of the constraint. For a foreign
+	// key, these are the values of the origin columns.
+	keys []tree.Datums
+	// seenKeys is used to deduplicate keys.
+	seenKeys map[string]struct{}
+}
+
+// addKey records that the given key needs to be checked.
+func (c *pendingConstraint) addKey(key tree.Datums) {
+	s := key.String()
+	if _, ok := c.seenKeys[s]; ok {
+		return
+	}
+	if c.seenKeys == nil {
+		c.seenKeys = make(map[string]struct{})
+	}
+	c.seenKeys[s] = struct{}{}
+	c.keys = append(c.keys, key)
+}
+
+// isDeferred returns whether the checks of the given constraint are currently
+// deferred.
+func (dc *deferredConstraints) isDeferred(
+	name string, deferrability tree.ConstraintDeferrability,
+) bool {
+	if deferrability == tree.ConstraintNotDeferrable {
+		return false
+	}
+	mode := dc.modes[name]
+	if mode == constraintsModeDefault {
+		mode = dc.all
+	}
+	switch mode {
+	case constraintsModeImmediate:
+		return false
+	case constraintsModeDeferred:
+		return true
+	}
+	return deferrability == tree.ConstraintInitiallyDeferred
+}
+
+// add records that the given key of the given constraint needs to be checked.
+func (dc *deferredConstraints) add(
+	tableID descpb.ID, name string, deferrability tree.ConstraintDeferrability, key tree.Datums,
+) {
+	for i := range dc.pending {
+		if c := &dc.pending[i]; c.tableID == tableID && c.name == name {
+			c.addKey(key)
+			return
+		}
+	}
+	dc.pending = append(dc.pending, pendingConstraint{
+		tableID:       tableID,
+		name:          name,
+		deferrability: deferrability,
+	})
+	dc.pending[len(dc.pending)-1].addKey(key)
+}
+
+// setMode implements SET CONSTRAINTS. An empty list of names stands for ALL.
+func (dc *deferredConstraints) setMode(names tree.NameList, deferred bool) {
+	mode := constraintsModeImmediate
+	if deferred {
+		mode = constraintsModeDeferred
+	}
+	if len(names) == 0 {
+		dc.all = mode
+		dc.modes = nil
+		return
+	}
+	if dc.modes == nil {
+		dc.modes = make(map[string]constraintsMode, len(names))
+	}
+	for _, n := range names {
+		dc.modes[string(n)] = mode
+	}
+}
+
+// reset forgets the state of the transaction.
+func (dc *deferredConstraints) reset() {
+	*dc = deferredConstraints{}
+}
+
+// clone returns a copy of the state of the transaction, which is saved by
+// savepoints.
+func (dc *deferredConstraints) clone() deferredConstraints {
+	res := deferredConstraints{all: dc.all}
+	if dc.modes != nil {
+		res.modes = make(map[string]constraintsMode, len(dc.modes))
+		for name, mode := range dc.modes {
+			res.modes[name] = mode
+		}
+	}
+	res.pending = make([]pendingConstraint, len(dc.pending))
+	for i, c := range dc.pending {
+		res.pending[i] = pendingConstraint{
+			tableID:       c.tableID,
+			name:          c.name,
+			deferrability: c.deferrability,
+			keys:          append([]tree.Datums(nil), c.keys...),
+		}
+	}
+	return res
+}
+
+// rollbackTo restores the state saved by a savepoint when rolling back to it:
+// the modes set by SET CONSTRAINTS since then are undone, and the keys that
+// were pending then, which may have been checked since, are pending again.
+// The keys recorded since then are kept; checking them again is harmless.
+func (dc *deferredConstraints) rollbackTo(saved *deferredConstraints) {
+	restored := saved.clone()
+	dc.all = restored.all
+	dc.modes = restored.modes
+	for i := range restored.pending {
+		c := &restored.pending[i]
+		for _, key := range c.keys {
+			dc.add(c.tableID, c.name, c.deferrability, key)
+		}
+	}
+}
+
+// validate validates the pending constraints. If onlyImmediate is set, the
+// constraints that are still deferred are left pending.
+//
+// The constraints are validated by the given InternalExecutor, which must be
+// able to see the schema changes of the transaction.
+func (dc *deferredConstraints) validate(
+	ctx context.Context,
+	ie *InternalExecutor,
+	txn *kv.Txn,
+	descsCol *descs.Collection,
+	onlyImmediate bool,
+) error {
+	remaining := dc.pending[:0]
+	for _, c := range dc.pending {
+		if onlyImmediate && dc.isDeferred(c.name, c.deferrability) {
+			remaining = append(remaining, c)
+			continue
+		}
+		if err := validatePendingConstraint(ctx, ie, txn, descsCol, c); err != nil {
+			return err
+		}
+	}
+	dc.pending = remaining
+	return nil
+}
+
+// validatePendingConstraint checks the keys of a single constraint.
+// Constraints that were dropped since they were recorded are ignored.
+func validatePendingConstraint(
+	ctx context.Context,
+	ie *InternalExecutor,
+	txn *kv.Txn,
+	descsCol *descs.Collection,
+	c pendingConstraint,
+) error {
+	table, err := descsCol.GetTableVersionByID(ctx, txn, c.tableID, tree.ObjectLookupFlags{
+		CommonLookupFlags: tree.CommonLookupFlags{IncludeDropped: true},
+	})
+	if err != nil {
+		if pgerror.GetPGCode(err) == pgcode.UndefinedTable {
+			return nil
+		}
+		return err
+	}
+	if table == nil || table.Dropped() {
+		return nil
+	}
+	for i := range table.OutboundFKs {
+		if fk := &table.OutboundFKs[i]; fk.Name == c.name {
+			return validateForeignKey(ctx, table, fk, ie, txn, ie.s.cfg.Codec, c.keys)
+		}
+	}
+	for i := range table.UniqueWithoutIndexConstraints {
+		if uc := &table.UniqueWithoutIndexConstraints[i]; uc.Name == c.name {
+			return validateUniqueWithoutIndexConstraint(ctx, table, uc, ie, txn, c.keys)
+		}
+	}
+	return nil
+}
+
+// validateDeferredConstraints validates the pending constraints of the
+// transaction before it commits.
+func (ex *connExecutor) validateDeferredConstraints(ctx context.Context) error {
+	dc := &ex.extraTxnState.deferredConstraints
+	if len(dc.pending) == 0 {
+		return nil
+	}
+	ie := MakeInternalExecutor(ctx, ex.server, ex.memMetrics, ex.server.cfg.Settings)
+	ie.SetSessionData(ex.sessionData)
+	ie.tcModifier = &ex.extraTxnState.descCollection
+	return dc.validate(
+		ctx, &ie, ex.state.mu.txn, &ex.extraTxnState.descCollection, false, /* onlyImmediate */
+	)
+}
+
+// DeferConstraintCheck is part of the tree.EvalPlanner interface.
+func (p *planner) DeferConstraintCheck(
+	tableID tree.ID,
+	constraintName string,
+	deferrability tree.ConstraintDeferrability,
+	keyVals tree.Datums,
+) bool {
+	dc := p.extendedEvalCtx.deferredConstraints
+	if dc == nil || !dc.isDeferred(constraintName, deferrability) {
+		return false
+	}
+	dc.add(descpb.ID(tableID), constraintName, deferrability, keyVals)
+	return true
+}
+
+// checkDeferrableConstraintVersion returns an error if a constraint with the
+// given deferrability cannot be created yet, because not all nodes know about
+// the deferrability of constraints.
+func checkDeferrableConstraintVersion(
+	ctx context.Context, st *cluster.Settings, deferrability tree.ConstraintDeferrability,
+) error {
+	if deferrability == tree.ConstraintNotDeferrable ||
+		st.Version.IsActive(ctx, clusterversion.DeferrableConstraints) {
+		return nil
+	}
+	return pgerror.Newf(pgcode.FeatureNotSupported,
+		"version %v must be finalized to use deferrable constraints",
+		clusterversion.DeferrableConstraints)
+}
+
+// SetConstraints implements the SET CONSTRAINTS statement.
+// See https://www.postgresql.org/docs/current/sql-set-constraints.html for
+// details.
+// Privileges: None.
+func (p *planner) SetConstraints(ctx context.Context, n *tree.SetConstraints) (planNode, error) {
+	return &setConstraintsNode{n: n}, nil
+}
+
+// setConstraintsNode represents a SET CONSTRAINTS statement.
+type setConstraintsNode struct {
+	n *tree.SetConstraints
+}
+
+func (n *setConstraintsNode) startExec(params runParams) error {
+	p := params.p
+	dc := p.extendedEvalCtx.deferredConstraints
+	if dc == nil || p.extendedEvalCtx.TxnImplicit {
+		p.BufferClientNotice(params.ctx, pgnotice.NewWithSeverityf(
+			"WARNING", "SET CONSTRAINTS can only be used in transaction blocks",
+		))
+		return nil
+	}
+	// The InternalExecutor needs to see the schema changes of the transaction.
+	ie := p.ExtendedEvalContext().InternalExecutor.(*InternalExecutor)
+	ie.tcModifier = p.Descriptors()
+	defer func() {
+		ie.tcModifier = nil
+	}()
+	for _, name := range n.n.Names {
+		if err := checkDeferrableConstraintExists(params.ctx, ie, p.txn, name); err != nil {
+			return err
+		}
+	}
+	dc.setMode(n.n.Names, n.n.Deferred)
+	if n.n.Deferred {
+		return nil
+	}
+	// Like in Postgres, the pending checks of the constraints that become
+	// immediate are performed right away.
+	return dc.validate(params.ctx, ie, p.txn, p.Descriptors(), true /* onlyImmediate */)
+}
+
+// checkDeferrableConstraintExists returns an error if there is no deferrable
+// constraint with the given name in the schemas of the search path.
+func checkDeferrableConstraintExists(
+	ctx context.Context, ie *InternalExecutor, txn *kv.Txn, name tree.Name,
+) error {
+	row, err := ie.QueryRowEx(
+		ctx, "check-deferrable-constraint", txn, sessiondata.InternalExecutorOverride{},
+		`SELECT bool_or(c.condeferrable)
+		   FROM pg_catalog.pg_constraint AS c
+		   JOIN pg_catalog.pg_namespace AS n ON c.connamespace = n.oid
+		  WHERE c.conname = $1 AND n.nspname = ANY (current_schemas(true))`,
+		string(name),
+	)
+	if err != nil {
+		return err
+	}
+	if row == nil || row[0] == tree.DNull {
+		return pgerror.Newf(pgcode.UndefinedObject, "constraint %q does not exist", name)
+	}
+	if !tree.MustBeDBool(row[0]) {
+		return pgerror.Newf(pgcode.WrongObjectType, "constraint %q is not deferrable", name)
+	}
+	return nil
+}
+
+func (*setConstraintsNode) Next(runParams) (bool, error) { return false, nil }
+func (*setConstraintsNode) Values() tree.Datums          { return nil }
+func (*setConstraintsNode) Close(context.Context)        {}
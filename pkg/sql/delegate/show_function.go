@@ -0,0 +1,53 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package delegate
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+)
+
+// delegateShowCreateFunction implements SHOW CREATE FUNCTION, which returns
+// the fully qualified name of a user-defined function along with a CREATE
+// FUNCTION statement that recreates it.
+// Privileges: any privilege on the function.
+func (d *delegator) delegateShowCreateFunction(n *tree.ShowCreateFunction) (tree.Statement, error) {
+	sqltelemetry.IncrementShowCounter(sqltelemetry.Create)
+
+	fn, fnName, err := d.catalog.ResolveFunction(d.ctx, n.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.catalog.CheckAnyPrivilege(d.ctx, fn); err != nil {
+		return nil, err
+	}
+
+	cf := &tree.CreateFunction{
+		Name:       fnName.ToUnresolvedObjectName(),
+		Args:       make(tree.FuncArgs, fn.ArgCount()),
+		ReturnType: fn.ReturnType(),
+		Language:   "sql",
+		Volatility: fn.Volatility(),
+		Body:       fn.Body(),
+	}
+	for i := range cf.Args {
+		cf.Args[i] = tree.FuncArg{Name: fn.ArgName(i), Type: fn.ArgType(i)}
+	}
+
+	const showCreateFunctionQuery = `SELECT %s AS function_name, %s AS create_statement`
+	return parse(fmt.Sprintf(showCreateFunctionQuery,
+		lex.EscapeSQLString(fnName.FQString()),
+		lex.EscapeSQLString(tree.AsStringWithFlags(cf, tree.FmtParsable)),
+	))
+}
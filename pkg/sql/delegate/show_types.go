@@ -17,9 +17,13 @@ func (d *delegator) delegateShowTypes() (tree.Statement, error) {
 	//  they should be added here.
 	return parse(`
 SELECT
-  schema, name, owner
+  nsp.nspname AS schema, types.typname AS name, rl.rolname AS owner
 FROM
-  [SHOW ENUMS]
+  pg_catalog.pg_type AS types
+  LEFT JOIN pg_catalog.pg_roles AS rl ON (types.typowner = rl.oid)
+  JOIN pg_catalog.pg_namespace AS nsp ON (types.typnamespace = nsp.oid)
+WHERE
+  types.typtype IN ('e', 'c', 'd')
 ORDER BY
-  (schema, name)`)
+  schema, name`)
 }
@@ -17,6 +17,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
@@ -34,6 +35,7 @@ type dropCascadeState struct {
 	td                      []toDelete
 	allTableObjectsToDelete []*tabledesc.Mutable
 	typesToDelete           []*typedesc.Mutable
+	functionsToDelete       []*funcdesc.Mutable
 
 	droppedNames []string
 }
@@ -152,8 +154,40 @@ func (d *dropCascadeState) resolveCollectedObjects(
 			if err != nil {
 				return err
 			}
-			// If we couldn't find the object at all, then continue.
 			if !found {
+				// If we couldn't resolve objName as a type either, it must be a
+				// function.
+				found, desc, err := p.LookupObject(
+					ctx,
+					tree.ObjectLookupFlags{
+						CommonLookupFlags: tree.CommonLookupFlags{
+							Required:       true,
+							RequireMutable: true,
+							IncludeOffline: true,
+						},
+						DesiredObjectKind: tree.FunctionObject,
+					},
+					objName.Catalog(),
+					objName.Schema(),
+					objName.Object(),
+				)
+				if err != nil {
+					return err
+				}
+				// If we couldn't find the object at all, then continue.
+				if !found {
+					continue
+				}
+				fnDesc, ok := desc.(*funcdesc.Mutable)
+				if !ok {
+					return errors.AssertionFailedf(
+						"descriptor for %q is not Mutable",
+						objName.Object(),
+					)
+				}
+				// The views and functions which depend on the function are
+				// dropped with it.
+				d.functionsToDelete = append(d.functionsToDelete, fnDesc)
 				continue
 			}
 			typDesc, ok := desc.(*typedesc.Mutable)
@@ -210,6 +244,19 @@ func (d *dropCascadeState) dropAllCollectedObjects(ctx context.Context, p *plann
 		}
 	}
 
+	// Finally delete all of the functions. They may already have been dropped
+	// as dependents of one another.
+	for _, fn := range d.functionsToDelete {
+		if fn.Dropped() {
+			continue
+		}
+		if err := p.dropFunctionImpl(
+			ctx, fn, "dropping function as part of a cascade",
+		); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,226 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/cockroachdb/errors"
+)
+
+type dropFunctionNode struct {
+	n   *tree.DropFunction
+	fns []funcToDrop
+}
+
+type funcToDrop struct {
+	name *tree.FunctionName
+	desc *funcdesc.Mutable
+}
+
+// Use to satisfy the linter.
+var _ planNode = &dropFunctionNode{n: nil}
+
+// DropFunction drops user-defined functions.
+// Privileges: ownership of the functions.
+func (p *planner) DropFunction(ctx context.Context, n *tree.DropFunction) (planNode, error) {
+	if err := checkSchemaChangeEnabled(
+		ctx,
+		p.ExecCfg(),
+		"DROP FUNCTION",
+	); err != nil {
+		return nil, err
+	}
+
+	node := &dropFunctionNode{n: n}
+	seen := make(map[descpb.ID]struct{}, len(n.Functions))
+	for i := range n.Functions {
+		fnObj := &n.Functions[i]
+		fnName, fnDesc, err := resolver.ResolveMutableFunction(ctx, p, fnObj.Name, !n.IfExists)
+		if err != nil {
+			return nil, err
+		}
+		if fnDesc == nil {
+			continue
+		}
+		if fnObj.ArgTypes != nil {
+			matches, err := p.functionArgTypesMatch(ctx, fnDesc, fnObj.ArgTypes)
+			if err != nil {
+				return nil, err
+			}
+			if !matches {
+				if n.IfExists {
+					continue
+				}
+				return nil, pgerror.Newf(pgcode.UndefinedFunction,
+					"function %s does not exist", tree.ErrString(fnObj))
+			}
+		}
+		if err := p.canModifyFunction(ctx, fnDesc); err != nil {
+			return nil, err
+		}
+		if _, ok := seen[fnDesc.ID]; ok {
+			continue
+		}
+		seen[fnDesc.ID] = struct{}{}
+		node.fns = append(node.fns, funcToDrop{name: fnName, desc: fnDesc})
+	}
+
+	// Without CASCADE, the functions can only be depended on by one another.
+	if n.DropBehavior != tree.DropCascade {
+		for _, fn := range node.fns {
+			for _, id := range fn.desc.DependedOnBy {
+				if _, ok := seen[id]; !ok {
+					return nil, p.dependentFunctionError(ctx, fn.desc, id)
+				}
+			}
+		}
+	}
+	return node, nil
+}
+
+// functionArgTypesMatch returns whether the argument types of the given
+// function are the given types.
+func (p *planner) functionArgTypesMatch(
+	ctx context.Context, desc *funcdesc.Mutable, argTypes []tree.ResolvableTypeReference,
+) (bool, error) {
+	if len(argTypes) != len(desc.Args) {
+		return false, nil
+	}
+	for i := range argTypes {
+		typ, err := tree.ResolveType(ctx, argTypes[i], p.semaCtx.GetTypeResolver())
+		if err != nil {
+			return false, err
+		}
+		if !typ.Identical(desc.Args[i].Type) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (n *dropFunctionNode) startExec(params runParams) error {
+	telemetry.Inc(sqltelemetry.SchemaChangeDropCounter("function"))
+
+	for _, fn := range n.fns {
+		if fn.desc.Dropped() {
+			// The function was dropped by a previous CASCADE in this statement.
+			continue
+		}
+		if err := params.p.dropFunctionImpl(
+			params.ctx, fn.desc, tree.AsStringWithFQNames(n.n, params.Ann()),
+		); err != nil {
+			return err
+		}
+		if err := params.p.logEvent(params.ctx,
+			fn.desc.ID,
+			&eventpb.DropFunction{FunctionName: fn.name.FQString()},
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropFunctionImpl marks a function as dropped and queues a schema change job
+// which drains its name and deletes its descriptor once no node holds a lease
+// on it anymore. The views and functions which depend on the function are
+// dropped with it; callers must check that this is allowed.
+func (p *planner) dropFunctionImpl(
+	ctx context.Context, desc *funcdesc.Mutable, jobDesc string,
+) error {
+	if desc.Dropped() {
+		return errors.Errorf("function %q is already being dropped", desc.Name)
+	}
+
+	// Remove the back-references from the functions this function calls.
+	if err := p.updateFunctionBackRefs(
+		ctx, desc.ID, desc.DependsOnFunctions, nil /* newDeps */, jobDesc,
+	); err != nil {
+		return err
+	}
+	desc.DependsOnFunctions = nil
+
+	// Mark the function as dropped before dropping its dependents, so that
+	// they leave its back-references alone.
+	desc.DrainingNames = append(desc.DrainingNames, descpb.NameInfo{
+		ParentID:       desc.ParentID,
+		ParentSchemaID: desc.ParentSchemaID,
+		Name:           desc.Name,
+	})
+	desc.SetDropped()
+	if err := p.writeFunctionDescChange(ctx, desc, jobDesc); err != nil {
+		return err
+	}
+
+	for _, id := range append([]descpb.ID(nil), desc.DependedOnBy...) {
+		dependent, err := p.Descriptors().GetMutableDescriptorByID(ctx, id, p.txn)
+		if err != nil {
+			return errors.Wrapf(err, "error resolving dependent object ID %d", id)
+		}
+		if dependent.Dropped() {
+			continue
+		}
+		switch d := dependent.(type) {
+		case *tabledesc.Mutable:
+			if _, err := p.dropViewImpl(
+				ctx, d, true /* queueJob */, "dropping dependent view", tree.DropCascade,
+			); err != nil {
+				return err
+			}
+		case *funcdesc.Mutable:
+			if err := p.dropFunctionImpl(ctx, d, "dropping dependent function"); err != nil {
+				return err
+			}
+		default:
+			return errors.AssertionFailedf(
+				"unexpected dependent %s %d of function %d",
+				dependent.TypeName(), errors.Safe(id), errors.Safe(desc.ID))
+		}
+	}
+	return nil
+}
+
+// dependentFunctionError returns the error reported when trying to drop the
+// given function without CASCADE while the object with the given ID depends
+// on it.
+func (p *planner) dependentFunctionError(
+	ctx context.Context, desc *funcdesc.Mutable, dependentID descpb.ID,
+) error {
+	dependent, err := p.Descriptors().GetMutableDescriptorByID(ctx, dependentID, p.txn)
+	if err != nil {
+		return errors.Wrapf(err, "error resolving dependent object ID %d", dependentID)
+	}
+	kind := "view"
+	if _, ok := dependent.(*funcdesc.Mutable); ok {
+		kind = "function"
+	}
+	return errors.WithHintf(
+		sqlerrors.NewDependentObjectErrorf("cannot drop function %q because %s %q depends on it",
+			desc.Name, kind, dependent.GetName()),
+		"you can drop %s instead, or use DROP FUNCTION ... CASCADE.", dependent.GetName())
+}
+
+func (n *dropFunctionNode) Next(params runParams) (bool, error) { return false, nil }
+func (n *dropFunctionNode) Values() tree.Datums                 { return tree.Datums{} }
+func (n *dropFunctionNode) Close(ctx context.Context)           {}
+func (n *dropFunctionNode) ReadingOwnWrites()                   {}
@@ -267,6 +267,26 @@ func (p *planner) dropIndexByName(
 		)
 	}
 
+	// An index that backs an exclusion constraint is dropped along with the
+	// constraint.
+	for i := range tableDesc.ExclusionConstraints {
+		ec := &tableDesc.ExclusionConstraints[i]
+		if ec.IndexID != idx.ID {
+			continue
+		}
+		if behavior != tree.DropCascade && constraintBehavior != ignoreIdxConstraint {
+			return errors.WithHint(
+				pgerror.Newf(pgcode.DependentObjectsStillExist,
+					"index %q is in use as exclusion constraint %q", idx.Name, ec.Name),
+				"use CASCADE if you really want to drop it.",
+			)
+		}
+		tableDesc.ExclusionConstraints = append(
+			tableDesc.ExclusionConstraints[:i], tableDesc.ExclusionConstraints[i+1:]...,
+		)
+		break
+	}
+
 	// Check if requires CCL binary for eventual zone config removal. Only
 	// necessary for the system tenant, because secondary tenants do not have
 	// zone configs for individual objects.
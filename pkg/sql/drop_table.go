@@ -331,6 +331,13 @@ func (p *planner) dropTableImpl(
 	// Copy out the set of dependencies as it may be overwritten in the loop.
 	dependedOnBy := append([]descpb.TableDescriptor_Reference(nil), tableDesc.DependedOnBy...)
 	for _, ref := range dependedOnBy {
+		if isTable, err := p.removeDependentTriggers(
+			ctx, ref.ID, tableDesc.ID, 0 /* colID */, "dropping dependent trigger",
+		); err != nil {
+			return droppedViews, err
+		} else if isTable {
+			continue
+		}
 		viewDesc, err := p.getViewDescForCascade(
 			ctx, tableDesc.TypeName(), tableDesc.Name, tableDesc.ParentID, ref.ID, tree.DropCascade,
 		)
@@ -364,6 +371,13 @@ func (p *planner) dropTableImpl(
 	}
 
 	err = p.initiateDropTable(ctx, tableDesc, !droppingParent, jobDesc, true /* drain name */)
+	if err != nil {
+		return droppedViews, err
+	}
+
+	// Remove the back-references from the relations that the triggers of this
+	// table depend on.
+	err = p.updateTriggerBackRefs(ctx, tableDesc, nil /* oldDeps */, jobDesc)
 	return droppedViews, err
 }
 
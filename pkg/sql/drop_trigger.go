@@ -0,0 +1,258 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/cockroachdb/errors"
+)
+
+type dropTriggerNode struct {
+	n         *tree.DropTrigger
+	tableDesc *tabledesc.Mutable
+}
+
+// DropTrigger drops a trigger of a table.
+// Privileges: CREATE on table.
+//   notes: postgres requires ownership of the table.
+func (p *planner) DropTrigger(ctx context.Context, n *tree.DropTrigger) (planNode, error) {
+	if err := checkSchemaChangeEnabled(
+		ctx,
+		p.ExecCfg(),
+		"DROP TRIGGER",
+	); err != nil {
+		return nil, err
+	}
+
+	tableDesc, err := p.ResolveMutableTableDescriptor(
+		ctx, &n.Table, !n.IfExists, tree.ResolveRequireTableDesc,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if tableDesc == nil {
+		return newZeroNode(nil /* columns */), nil
+	}
+
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	if findTrigger(tableDesc, n.Name) == -1 {
+		if n.IfExists {
+			return newZeroNode(nil /* columns */), nil
+		}
+		return nil, pgerror.Newf(pgcode.UndefinedObject,
+			"trigger %q for table %q does not exist", n.Name, tableDesc.GetName())
+	}
+
+	// Triggers do not have dependents, so CASCADE and RESTRICT behave the same.
+	return &dropTriggerNode{n: n, tableDesc: tableDesc}, nil
+}
+
+// ReadingOwnWrites implements the planNodeReadingOwnWrites interface.
+// This is because DROP TRIGGER performs multiple KV operations on descriptors
+// and expects to see its own writes.
+func (n *dropTriggerNode) ReadingOwnWrites() {}
+
+func (n *dropTriggerNode) startExec(params runParams) error {
+	telemetry.Inc(sqltelemetry.SchemaChangeDropCounter("trigger"))
+
+	idx := findTrigger(n.tableDesc, n.n.Name)
+	oldDeps := n.tableDesc.Triggers[idx].DependsOn
+	n.tableDesc.Triggers = append(n.tableDesc.Triggers[:idx], n.tableDesc.Triggers[idx+1:]...)
+
+	jobDesc := tree.AsStringWithFQNames(n.n, params.Ann())
+	if err := params.p.writeSchemaChange(
+		params.ctx, n.tableDesc, descpb.InvalidMutationID, jobDesc,
+	); err != nil {
+		return err
+	}
+	if err := params.p.updateTriggerBackRefs(params.ctx, n.tableDesc, oldDeps, jobDesc); err != nil {
+		return err
+	}
+
+	return params.p.logEvent(params.ctx,
+		n.tableDesc.ID,
+		&eventpb.DropTrigger{
+			TableName:   n.n.Table.FQString(),
+			TriggerName: string(n.n.Name),
+		})
+}
+
+func (n *dropTriggerNode) Next(runParams) (bool, error) { return false, nil }
+func (n *dropTriggerNode) Values() tree.Datums          { return tree.Datums{} }
+func (n *dropTriggerNode) Close(context.Context)        {}
+
+// updateTriggerBackRefs updates the back-references to the given table from
+// the relations that its triggers depend on, after the triggers have changed.
+// oldDeps are the dependencies of the triggers that were removed. The
+// triggers of a dropped table do not have back-references.
+func (p *planner) updateTriggerBackRefs(
+	ctx context.Context,
+	tableDesc *tabledesc.Mutable,
+	oldDeps []descpb.TableDescriptor_Reference,
+	jobDesc string,
+) error {
+	var ids []descpb.ID
+	seen := make(map[descpb.ID]struct{})
+	addID := func(id descpb.ID) {
+		if _, ok := seen[id]; ok || id == tableDesc.ID {
+			return
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	for _, ref := range oldDeps {
+		addID(ref.ID)
+	}
+	for i := range tableDesc.Triggers {
+		for _, ref := range tableDesc.Triggers[i].DependsOn {
+			addID(ref.ID)
+		}
+	}
+
+	for _, id := range ids {
+		desc, err := p.Descriptors().GetMutableTableVersionByID(ctx, id, p.txn)
+		if err != nil {
+			return errors.Wrapf(err, "error resolving dependency relation ID %d", id)
+		}
+		// The dependency is also being dropped, so we don't have to update its
+		// references.
+		if desc.Dropped() {
+			continue
+		}
+		desc.DependedOnBy = removeMatchingReferences(desc.DependedOnBy, tableDesc.ID)
+		if !tableDesc.Dropped() {
+			for i := range tableDesc.Triggers {
+				for _, ref := range tableDesc.Triggers[i].DependsOn {
+					if ref.ID == id {
+						desc.DependedOnBy = append(desc.DependedOnBy, descpb.TableDescriptor_Reference{
+							ID:        tableDesc.ID,
+							ColumnIDs: append([]descpb.ColumnID(nil), ref.ColumnIDs...),
+						})
+					}
+				}
+			}
+		}
+		if err := p.writeSchemaChange(
+			ctx, desc, descpb.InvalidMutationID,
+			fmt.Sprintf("updating references for triggers of table %s from %s(%d): %s",
+				tableDesc.Name, desc.Name, desc.ID, jobDesc),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// triggerDependsOn returns whether the given trigger depends on the relation
+// with the given ID, or on the given column of it if colID is not zero.
+func triggerDependsOn(t *descpb.TriggerDescriptor, relID descpb.ID, colID descpb.ColumnID) bool {
+	for _, ref := range t.DependsOn {
+		if ref.ID != relID {
+			continue
+		}
+		if colID == 0 {
+			return true
+		}
+		for _, id := range ref.ColumnIDs {
+			if id == colID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// canRemoveDependentTriggers checks whether the triggers of the table with the
+// given ID, which depend on the object being dropped, can be dropped with it.
+// It returns false if the dependent is a view rather than a table.
+func (p *planner) canRemoveDependentTriggers(
+	ctx context.Context, typeName, objName string, tableID descpb.ID, behavior tree.DropBehavior,
+) (bool, error) {
+	tableDesc, err := p.Descriptors().GetMutableTableVersionByID(ctx, tableID, p.txn)
+	if err != nil {
+		return false, errors.Wrapf(err, "error resolving dependent relation ID %d", tableID)
+	}
+	if tableDesc.IsView() {
+		return false, nil
+	}
+	if behavior != tree.DropCascade {
+		return true, dependentTriggerError(typeName, objName, tableDesc.Name, "drop")
+	}
+	return true, p.CheckPrivilege(ctx, tableDesc, privilege.CREATE)
+}
+
+// removeDependentTriggers drops the triggers of the table with the given ID
+// which depend on the relation with ID relID, or on the given column of it if
+// colID is not zero. It returns false if the dependent is a view rather than a
+// table, in which case nothing is done.
+func (p *planner) removeDependentTriggers(
+	ctx context.Context, tableID, relID descpb.ID, colID descpb.ColumnID, jobDesc string,
+) (bool, error) {
+	tableDesc, err := p.Descriptors().GetMutableTableVersionByID(ctx, tableID, p.txn)
+	if err != nil {
+		return false, errors.Wrapf(err, "error resolving dependent relation ID %d", tableID)
+	}
+	if tableDesc.IsView() {
+		return false, nil
+	}
+	// The back-references of a dropped table have already been removed.
+	if tableDesc.Dropped() {
+		return true, nil
+	}
+	oldDeps := removeTriggersDependingOn(tableDesc, relID, colID)
+	if err := p.writeSchemaChange(ctx, tableDesc, descpb.InvalidMutationID, jobDesc); err != nil {
+		return true, err
+	}
+	return true, p.updateTriggerBackRefs(ctx, tableDesc, oldDeps, jobDesc)
+}
+
+// removeTriggersDependingOn removes the triggers of the given table which
+// depend on the relation with ID relID, or on the given column of it if colID
+// is not zero, and returns their dependencies.
+func removeTriggersDependingOn(
+	tableDesc *tabledesc.Mutable, relID descpb.ID, colID descpb.ColumnID,
+) []descpb.TableDescriptor_Reference {
+	var oldDeps []descpb.TableDescriptor_Reference
+	triggers := tableDesc.Triggers[:0]
+	for _, t := range tableDesc.Triggers {
+		if triggerDependsOn(&t, relID, colID) {
+			oldDeps = append(oldDeps, t.DependsOn...)
+			continue
+		}
+		triggers = append(triggers, t)
+	}
+	tableDesc.Triggers = triggers
+	return oldDeps
+}
+
+// dependentTriggerError returns the error for an operation on an object which
+// a trigger of the given table depends on.
+func dependentTriggerError(typeName, objName, tableName, op string) error {
+	return errors.WithHintf(
+		sqlerrors.NewDependentObjectErrorf("cannot %s %s %q because a trigger on table %q depends on it",
+			op, typeName, objName, tableName),
+		"you can drop the trigger instead.")
+}
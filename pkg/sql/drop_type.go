@@ -29,7 +29,8 @@ import (
 )
 
 type dropTypeNode struct {
-	n  *tree.DropType
+	// n is either a *tree.DropType or a *tree.DropDomain.
+	n  tree.Statement
 	td map[descpb.ID]*typedesc.Mutable
 }
 
@@ -37,10 +38,23 @@ type dropTypeNode struct {
 var _ planNode = &dropTypeNode{n: nil}
 
 func (p *planner) DropType(ctx context.Context, n *tree.DropType) (planNode, error) {
+	return p.dropTypes(ctx, n, n.Names, n.IfExists, n.DropBehavior, false /* domainsOnly */)
+}
+
+// dropTypes plans the dropping of the named types. If domainsOnly is set, all
+// of the named types must be domains.
+func (p *planner) dropTypes(
+	ctx context.Context,
+	n tree.Statement,
+	names []*tree.UnresolvedObjectName,
+	ifExists bool,
+	behavior tree.DropBehavior,
+	domainsOnly bool,
+) (planNode, error) {
 	if err := checkSchemaChangeEnabled(
 		ctx,
 		p.ExecCfg(),
-		"DROP TYPE",
+		n.StatementTag(),
 	); err != nil {
 		return nil, err
 	}
@@ -49,12 +63,12 @@ func (p *planner) DropType(ctx context.Context, n *tree.DropType) (planNode, err
 		n:  n,
 		td: make(map[descpb.ID]*typedesc.Mutable),
 	}
-	if n.DropBehavior == tree.DropCascade {
-		return nil, unimplemented.NewWithIssue(51480, "DROP TYPE CASCADE is not yet supported")
+	if behavior == tree.DropCascade {
+		return nil, unimplemented.NewWithIssuef(51480, "%s CASCADE is not yet supported", n.StatementTag())
 	}
-	for _, name := range n.Names {
+	for _, name := range names {
 		// Resolve the desired type descriptor.
-		typeDesc, err := p.ResolveMutableTypeDescriptor(ctx, name, !n.IfExists)
+		typeDesc, err := p.ResolveMutableTypeDescriptor(ctx, name, !ifExists)
 		if err != nil {
 			return nil, err
 		}
@@ -65,6 +79,9 @@ func (p *planner) DropType(ctx context.Context, n *tree.DropType) (planNode, err
 		if _, ok := node.td[typeDesc.ID]; ok {
 			continue
 		}
+		if domainsOnly && typeDesc.Kind != descpb.TypeDescriptor_DOMAIN {
+			return nil, pgerror.Newf(pgcode.WrongObjectType, "%q is not a domain", name)
+		}
 		switch typeDesc.Kind {
 		case descpb.TypeDescriptor_ALIAS:
 			// The implicit array types are not directly droppable.
@@ -87,7 +104,7 @@ func (p *planner) DropType(ctx context.Context, n *tree.DropType) (planNode, err
 		}
 
 		// Check if we can drop the type.
-		if err := p.canDropTypeDesc(ctx, typeDesc, n.DropBehavior); err != nil {
+		if err := p.canDropTypeDesc(ctx, typeDesc, behavior); err != nil {
 			return nil, err
 		}
 
@@ -97,7 +114,7 @@ func (p *planner) DropType(ctx context.Context, n *tree.DropType) (planNode, err
 			return nil, err
 		}
 		// Ensure that we can drop the array type as well.
-		if err := p.canDropTypeDesc(ctx, mutArrayDesc, n.DropBehavior); err != nil {
+		if err := p.canDropTypeDesc(ctx, mutArrayDesc, behavior); err != nil {
 			return nil, err
 		}
 
@@ -150,6 +150,10 @@ func (p *planner) canRemoveDependentViewGeneric(
 	ref descpb.TableDescriptor_Reference,
 	behavior tree.DropBehavior,
 ) error {
+	// The dependent may be a table whose triggers depend on the object.
+	if isTable, err := p.canRemoveDependentTriggers(ctx, typeName, objName, ref.ID, behavior); err != nil || isTable {
+		return err
+	}
 	viewDesc, err := p.getViewDescForCascade(ctx, typeName, objName, parentID, ref.ID, behavior)
 	if err != nil {
 		return err
@@ -215,9 +219,25 @@ func (p *planner) dropViewImpl(
 	}
 	viewDesc.DependsOn = nil
 
+	// Remove back-references from the functions this view calls.
+	if err := p.updateFunctionBackRefs(
+		ctx, viewDesc.ID, viewDesc.DependsOnFunctions, nil, /* newDeps */
+		fmt.Sprintf("removing references for view %s from functions", viewDesc.Name),
+	); err != nil {
+		return cascadeDroppedViews, err
+	}
+	viewDesc.DependsOnFunctions = nil
+
 	if behavior == tree.DropCascade {
 		dependedOnBy := append([]descpb.TableDescriptor_Reference(nil), viewDesc.DependedOnBy...)
 		for _, ref := range dependedOnBy {
+			if isTable, err := p.removeDependentTriggers(
+				ctx, ref.ID, viewDesc.ID, 0 /* colID */, "dropping dependent trigger",
+			); err != nil {
+				return cascadeDroppedViews, err
+			} else if isTable {
+				continue
+			}
 			dependentDesc, err := p.getViewDescForCascade(
 				ctx, viewDesc.TypeName(), viewDesc.Name, viewDesc.ParentID, ref.ID, behavior,
 			)
@@ -22,8 +22,9 @@ import (
 type errorIfRowsNode struct {
 	plan planNode
 
-	// mkErr creates the error message, given the values of the first row
-	// produced.
+	// mkErr creates the error message, given the values of a row produced. It
+	// is called for each row until it returns an error; rows for which it
+	// returns nil are ignored (see execbuilder.Builder.maybeDeferCheck).
 	mkErr exec.MkErrFn
 
 	nexted bool
@@ -39,14 +40,15 @@ func (n *errorIfRowsNode) Next(params runParams) (bool, error) {
 	}
 	n.nexted = true
 
-	ok, err := n.plan.Next(params)
-	if err != nil {
-		return false, err
+	for {
+		ok, err := n.plan.Next(params)
+		if err != nil || !ok {
+			return false, err
+		}
+		if err := n.mkErr(n.plan.Values()); err != nil {
+			return false, err
+		}
 	}
-	if ok {
-		return false, n.mkErr(n.plan.Values())
-	}
-	return false, nil
 }
 
 func (n *errorIfRowsNode) Values() tree.Datums {
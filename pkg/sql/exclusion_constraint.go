@@ -0,0 +1,216 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/geo/geoindex"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+)
+
+const (
+	// exclusionAccessMethodBTree is the default access method of exclusion
+	// constraints. It only supports the = operator.
+	exclusionAccessMethodBTree = "btree"
+	// exclusionAccessMethodGiST supports the =, <> and && operators. Like in
+	// Postgres, it is the access method used for exclusion constraints on
+	// geospatial columns.
+	exclusionAccessMethodGiST = "gist"
+)
+
+// makeExclusionConstraint validates the definition of an EXCLUDE constraint on
+// the given table, and returns the constraint along with the index that backs
+// it. The backing index is used to speed up the check queries that enforce the
+// constraint: it is an inverted index on the first geospatial column compared
+// with &&, or otherwise a forward index on the columns compared with =.
+//
+// The index and the constraint share a name. The IndexID of the returned
+// constraint is not set, since the index has not been added to the table yet;
+// it is set by AllocateIDs.
+func makeExclusionConstraint(
+	ctx context.Context,
+	st *cluster.Settings,
+	desc *tabledesc.Mutable,
+	d *tree.ExclusionConstraintTableDef,
+	indexEncodingVersion descpb.IndexDescriptorVersion,
+) (descpb.ExclusionConstraint, descpb.IndexDescriptor, error) {
+	var ec descpb.ExclusionConstraint
+	var idx descpb.IndexDescriptor
+	if !st.Version.IsActive(ctx, clusterversion.ExclusionConstraints) {
+		return ec, idx, pgerror.Newf(pgcode.FeatureNotSupported,
+			"version %v must be finalized to use EXCLUDE constraints",
+			clusterversion.ExclusionConstraints)
+	}
+	if d.Predicate != nil {
+		return ec, idx, unimplemented.NewWithIssueDetail(46657, "exclude-where",
+			"EXCLUDE constraints with a WHERE clause are not supported")
+	}
+
+	method := strings.ToLower(string(d.AccessMethod))
+	switch method {
+	case "", exclusionAccessMethodBTree:
+		method = exclusionAccessMethodBTree
+	case exclusionAccessMethodGiST:
+	case "hash", "gin", "spgist", "brin", "inverted":
+		return ec, idx, pgerror.Newf(pgcode.FeatureNotSupported,
+			"access method %q does not support exclusion constraints", method)
+	default:
+		return ec, idx, pgerror.Newf(pgcode.UndefinedObject,
+			"access method %q does not exist", method)
+	}
+	ec.AccessMethod = method
+
+	var eqCols, indexableCols tree.IndexElemList
+	var invertedCol *descpb.ColumnDescriptor
+	for _, elem := range d.Elems {
+		col, err := desc.FindActiveColumnByName(string(elem.Column))
+		if err != nil {
+			return ec, idx, err
+		}
+		for _, id := range ec.ColumnIDs {
+			if id == col.ID {
+				return ec, idx, pgerror.Newf(pgcode.DuplicateColumn,
+					"column %q appears twice in exclusion constraint", col.Name)
+			}
+		}
+		if err := validateExclusionOperator(method, elem.Operator, col.Type); err != nil {
+			return ec, idx, err
+		}
+		ec.ColumnIDs = append(ec.ColumnIDs, col.ID)
+		ec.Operators = append(ec.Operators, elem.Operator.String())
+
+		colElem := tree.IndexElem{Column: tree.Name(col.Name)}
+		switch {
+		case elem.Operator == tree.Overlaps && colinfo.ColumnTypeIsInvertedIndexable(col.Type):
+			if invertedCol == nil {
+				invertedCol = col
+			}
+		case colinfo.ColumnTypeIsIndexable(col.Type):
+			if elem.Operator == tree.EQ {
+				eqCols = append(eqCols, colElem)
+			}
+			indexableCols = append(indexableCols, colElem)
+		}
+	}
+
+	ec.Name = string(d.Name)
+	if ec.Name == "" {
+		ec.Name = makeExclusionConstraintName(desc, ec.ColumnIDs)
+	}
+
+	idx = descpb.IndexDescriptor{
+		Name:    ec.Name,
+		Version: indexEncodingVersion,
+	}
+	var indexCols tree.IndexElemList
+	switch {
+	case invertedCol != nil:
+		idx.Type = descpb.IndexDescriptor_INVERTED
+		indexCols = tree.IndexElemList{{Column: tree.Name(invertedCol.Name)}}
+		switch invertedCol.Type.Family() {
+		case types.GeometryFamily:
+			config, err := geoindex.GeometryIndexConfigForSRID(invertedCol.Type.GeoSRIDOrZero())
+			if err != nil {
+				return ec, idx, err
+			}
+			idx.GeoConfig = *config
+		case types.GeographyFamily:
+			idx.GeoConfig = *geoindex.DefaultGeographyIndexConfig()
+		}
+	case len(eqCols) > 0:
+		indexCols = eqCols
+	case len(indexableCols) > 0:
+		indexCols = indexableCols
+	default:
+		return ec, idx, pgerror.Newf(pgcode.FeatureNotSupported,
+			"exclusion constraint %q cannot be backed by an index", ec.Name)
+	}
+	if err := idx.FillColumns(indexCols); err != nil {
+		return ec, idx, err
+	}
+	return ec, idx, nil
+}
+
+// validateExclusionOperator checks that the given operator can be used to
+// compare two values of the given type in an exclusion constraint that uses
+// the given access method.
+func validateExclusionOperator(method string, op tree.ComparisonOperator, typ *types.T) error {
+	supported := false
+	switch method {
+	case exclusionAccessMethodBTree:
+		supported = op == tree.EQ
+	case exclusionAccessMethodGiST:
+		for _, exclusionOp := range descpb.ExclusionOperators {
+			supported = supported || op == exclusionOp
+		}
+	}
+	if !supported {
+		return pgerror.Newf(pgcode.WrongObjectType,
+			"operator %s is not supported by access method %q", op, method)
+	}
+
+	// The <> operator is evaluated as the negation of =.
+	lookupOp := op
+	if op == tree.NE {
+		lookupOp = tree.EQ
+	}
+	if _, ok := tree.CmpOps[lookupOp].LookupImpl(typ, typ); !ok {
+		return pgerror.Newf(pgcode.UndefinedFunction,
+			"operator does not exist: %s %s %s", typ.SQLString(), op, typ.SQLString())
+	}
+	return nil
+}
+
+// makeExclusionConstraintName generates a name for an exclusion constraint
+// with the given columns in the same way as Postgres, e.g. t_a_b_excl. The name
+// does not conflict with any index or constraint of the table.
+func makeExclusionConstraintName(desc *tabledesc.Mutable, colIDs []descpb.ColumnID) string {
+	var buf strings.Builder
+	buf.WriteString(desc.Name)
+	for _, id := range colIDs {
+		col, err := desc.FindColumnByID(id)
+		if err != nil {
+			continue
+		}
+		buf.WriteByte('_')
+		buf.WriteString(col.Name)
+	}
+	buf.WriteString("_excl")
+	name := buf.String()
+
+	inUse := func(name string) bool {
+		if _, _, err := desc.FindIndexByName(name); err == nil {
+			return true
+		}
+		for i := range desc.ExclusionConstraints {
+			if desc.ExclusionConstraints[i].Name == name {
+				return true
+			}
+		}
+		return false
+	}
+	for i := 1; inUse(name); i++ {
+		name = fmt.Sprintf("%s%d", buf.String(), i)
+	}
+	return name
+}
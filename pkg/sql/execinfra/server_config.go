@@ -47,6 +47,16 @@ var SettingWorkMemBytes = settings.RegisterByteSizeSetting(
 	64*1024*1024, /* 64MB */
 )
 
+// ChangefeedMemLimit is a cluster setting that bounds the memory used by all
+// the changefeeds running on a node to buffer changes, see
+// ServerConfig.ChangefeedMonitor.
+var ChangefeedMemLimit = settings.RegisterByteSizeSetting(
+	"changefeed.memory.limit",
+	"the amount of memory which can be used to buffer the changes of all the changefeeds on each node",
+	1<<30, /* 1 GiB */
+	settings.PositiveInt,
+)
+
 // ServerConfig encompasses the configuration required to create a
 // DistSQLServer.
 type ServerConfig struct {
@@ -102,6 +112,12 @@ type ServerConfig struct {
 	// used by the column and index backfillers.
 	BackfillerMonitor *mon.BytesMonitor
 
+	// ChangefeedMonitor is the parent of the monitors used by changefeeds to
+	// account for the changes they buffer. It is a child of the root SQL
+	// monitor, so that all of the changefeeds on a node share its budget, and
+	// is limited by the changefeed.memory.limit setting.
+	ChangefeedMonitor *mon.BytesMonitor
+
 	// DiskMonitor is used to monitor temporary storage disk usage. Actual disk
 	// space used will be a small multiple (~1.1) of this because of RocksDB
 	// space amplification.
@@ -122,6 +122,21 @@ func (ep *DummyEvalPlanner) CompactEngineSpan(
 	return errors.WithStack(errEvalPlanner)
 }
 
+// SendNotification is part of the EvalPlanner interface.
+func (ep *DummyEvalPlanner) SendNotification(ctx context.Context, channel, payload string) error {
+	return errors.WithStack(errEvalPlanner)
+}
+
+// DeferConstraintCheck is part of the EvalPlanner interface.
+func (ep *DummyEvalPlanner) DeferConstraintCheck(
+	tableID tree.ID,
+	constraintName string,
+	deferrability tree.ConstraintDeferrability,
+	keyVals tree.Datums,
+) bool {
+	return false
+}
+
 var _ tree.EvalPlanner = &DummyEvalPlanner{}
 
 var errEvalPlanner = pgerror.New(pgcode.ScalarOperationCannotRunWithoutFullSessionContext,
@@ -206,7 +221,9 @@ func (ep *DummySessionAccessor) GetSessionVar(
 }
 
 // SetSessionVar is part of the tree.EvalSessionAccessor interface.
-func (ep *DummySessionAccessor) SetSessionVar(_ context.Context, _, _ string) error {
+func (ep *DummySessionAccessor) SetSessionVar(
+	_ context.Context, _, _ string, _ bool,
+) error {
 	return errors.WithStack(errEvalSessionVar)
 }
 
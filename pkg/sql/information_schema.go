@@ -1396,6 +1396,13 @@ CREATE TABLE information_schema.table_constraints (
 				tbNameStr := tree.NewDString(table.GetName())
 
 				for conName, c := range conInfo {
+					// Like Postgres, exclusion constraints are not included since they
+					// are not part of the SQL standard.
+					if c.Kind == descpb.ConstraintTypeExclusion {
+						continue
+					}
+					deferrable := c.Deferrability() != descpb.ConstraintDeferrability_NotDeferrable
+					deferred := c.Deferrability() == descpb.ConstraintDeferrability_InitiallyDeferred
 					if err := addRow(
 						dbNameStr,                       // constraint_catalog
 						scNameStr,                       // constraint_schema
@@ -1404,8 +1411,8 @@ CREATE TABLE information_schema.table_constraints (
 						scNameStr,                       // table_schema
 						tbNameStr,                       // table_name
 						tree.NewDString(string(c.Kind)), // constraint_type
-						yesOrNoDatum(false),             // is_deferrable
-						yesOrNoDatum(false),             // initially_deferred
+						yesOrNoDatum(deferrable),        // is_deferrable
+						yesOrNoDatum(deferred),          // initially_deferred
 					); err != nil {
 						return err
 					}
@@ -111,17 +111,22 @@ func (ie *InternalExecutor) SetSessionData(sessionData *sessiondata.SessionData)
 // If txn is not nil, the statement will be executed in the respective txn.
 //
 // sd will constitute the executor's session state.
+//
+// If it is not nil, the columns and rows of the statements are sent to it
+// rather than being buffered.
 func (ie *InternalExecutor) initConnEx(
 	ctx context.Context,
 	txn *kv.Txn,
 	sd *sessiondata.SessionData,
 	syncCallback func([]resWithPos),
 	errCallback func(error),
+	it *rowsIterator,
 ) (*StmtBuf, *sync.WaitGroup, error) {
 	clientComm := &internalClientComm{
 		sync: syncCallback,
 		// init lastDelivered below the position of the first result (0).
 		lastDelivered: -1,
+		it:            it,
 	}
 
 	// When the connEx is serving an internal executor, it can inherit the
@@ -448,7 +453,7 @@ func (ie *InternalExecutor) execInternal(
 		}
 		resCh <- result{err: err}
 	}
-	stmtBuf, wg, err := ie.initConnEx(ctx, txn, sd, syncCallback, errCallback)
+	stmtBuf, wg, err := ie.initConnEx(ctx, txn, sd, syncCallback, errCallback, nil /* it */)
 	if err != nil {
 		return result{}, err
 	}
@@ -508,6 +513,195 @@ func (ie *InternalExecutor) execInternal(
 	return res, nil
 }
 
+// queryIterator executes the given statement in txn and returns an iterator
+// over its rows, which are not buffered. Planning errors are returned
+// directly, execution errors are returned by the iterator. The iterator needs
+// to be closed.
+//
+// sessionDataOverride can be used to control select fields in the executor's
+// session data. It overrides what has been previously set through
+// SetSessionData(), if anything.
+func (ie *InternalExecutor) queryIterator(
+	ctx context.Context,
+	opName string,
+	txn *kv.Txn,
+	sessionDataOverride sessiondata.InternalExecutorOverride,
+	stmt parser.Statement,
+) (*rowsIterator, error) {
+	ctx = logtags.AddTag(ctx, "intExec", opName)
+
+	var sd *sessiondata.SessionData
+	if ie.sessionData != nil {
+		sdCopy := *ie.sessionData
+		sd = &sdCopy
+	} else {
+		sd = ie.s.newSessionData(SessionArgs{})
+	}
+	applyOverrides(sessionDataOverride, sd)
+	if sd.User().Undefined() {
+		return nil, errors.AssertionFailedf("no user specified for internal query")
+	}
+	if sd.ApplicationName == "" {
+		sd.ApplicationName = catconstants.InternalAppNamePrefix + "-" + opName
+	}
+	// The statement must not use the transaction while it is suspended, which
+	// would not be the case of remote flows.
+	sd.DistSQLMode = sessiondata.DistSQLOff
+
+	it := &rowsIterator{
+		eventCh: make(chan rowsIteratorEvent),
+		nextCh:  make(chan struct{}),
+		closeCh: make(chan struct{}),
+	}
+	var resultsReceived bool
+	syncCallback := func(results []resWithPos) {
+		resultsReceived = true
+		var err error
+		for _, res := range results {
+			if res.err != nil {
+				err = res.Err()
+				break
+			}
+		}
+		it.send(rowsIteratorEvent{done: true, err: err})
+	}
+	errCallback := func(err error) {
+		if resultsReceived {
+			return
+		}
+		it.send(rowsIteratorEvent{done: true, err: err})
+	}
+	var err error
+	it.stmtBuf, it.wg, err = ie.initConnEx(ctx, txn, sd, syncCallback, errCallback, it)
+	if err != nil {
+		return nil, err
+	}
+
+	timeReceived := timeutil.Now()
+	if err := it.stmtBuf.Push(ctx, ExecStmt{
+		Statement:    stmt,
+		TimeReceived: timeReceived,
+		ParseStart:   timeReceived,
+		ParseEnd:     timeReceived,
+	}); err != nil {
+		it.Close()
+		return nil, err
+	}
+	if err := it.stmtBuf.Push(ctx, Sync{}); err != nil {
+		it.Close()
+		return nil, err
+	}
+
+	// Wait for the statement to be planned.
+	ev := <-it.eventCh
+	if ev.done {
+		it.done, it.err = true, ev.err
+		if ev.err != nil {
+			it.Close()
+			return nil, ev.err
+		}
+		return it, nil
+	}
+	it.cols = ev.cols
+	return it, nil
+}
+
+// rowsIterator iterates over the rows of a statement executed by an
+// InternalExecutor. The statement runs on the goroutine of the connExecutor of
+// the InternalExecutor, which is suspended between the calls to Next: the
+// statement only makes progress while the caller waits for the next row, so
+// the caller and the statement never use the transaction concurrently.
+type rowsIterator struct {
+	// cols are the result columns of the statement.
+	cols colinfo.ResultColumns
+	// row is the current row.
+	row tree.Datums
+
+	// done is set once all the rows were returned or the statement failed, in
+	// which case err is set.
+	done bool
+	err  error
+
+	// eventCh is used by the connExecutor to send the result columns, the rows
+	// and the outcome of the statement.
+	eventCh chan rowsIteratorEvent
+	// nextCh is used to resume the statement after it sent the columns or a
+	// row.
+	nextCh chan struct{}
+	// closeCh is closed when the iterator is closed, to stop the statement.
+	closeCh chan struct{}
+	closed  bool
+
+	stmtBuf *StmtBuf
+	wg      *sync.WaitGroup
+}
+
+type rowsIteratorEvent struct {
+	cols colinfo.ResultColumns
+	row  tree.Datums
+	// done is set once the statement finished, in which case err is set if it
+	// failed.
+	done bool
+	err  error
+}
+
+// errRowsIteratorClosed is returned to the statement of a rowsIterator that is
+// closed before all the rows were consumed.
+var errRowsIteratorClosed = errors.New("rows iterator closed")
+
+// send is called by the connExecutor to send an event to the iterator. Unless
+// the event is the last one, it then waits until the next row is requested. It
+// returns false if the iterator was closed.
+func (it *rowsIterator) send(ev rowsIteratorEvent) bool {
+	select {
+	case it.eventCh <- ev:
+	case <-it.closeCh:
+		return false
+	}
+	if ev.done {
+		return true
+	}
+	select {
+	case <-it.nextCh:
+		return true
+	case <-it.closeCh:
+		return false
+	}
+}
+
+// Next advances the iterator to the next row. It returns false once all the
+// rows were returned, or if the statement failed.
+func (it *rowsIterator) Next() (bool, error) {
+	if it.done {
+		return false, it.err
+	}
+	it.nextCh <- struct{}{}
+	ev := <-it.eventCh
+	if ev.done {
+		it.done, it.err, it.row = true, ev.err, nil
+		return false, it.err
+	}
+	it.row = ev.row
+	return true, nil
+}
+
+// Cur returns the current row.
+func (it *rowsIterator) Cur() tree.Datums {
+	return it.row
+}
+
+// Close stops the statement if it is still running and waits for the
+// connExecutor to finish.
+func (it *rowsIterator) Close() {
+	if it.closed {
+		return
+	}
+	it.closed = true
+	close(it.closeCh)
+	it.stmtBuf.Close()
+	it.wg.Wait()
+}
+
 // internalClientComm is an implementation of ClientComm used by the
 // InternalExecutor. Result rows are buffered in memory.
 type internalClientComm struct {
@@ -520,6 +714,10 @@ type internalClientComm struct {
 	// sync, if set, is called whenever a Sync is executed. It returns all the
 	// results since the previous Sync.
 	sync func([]resWithPos)
+
+	// it, if set, receives the columns and rows of the statement results, which
+	// are not buffered.
+	it *rowsIterator
 }
 
 var _ ClientComm = &internalClientComm{}
@@ -541,7 +739,9 @@ func (icc *internalClientComm) CreateStatementResult(
 	_ string,
 	_ bool,
 ) CommandResult {
-	return icc.createRes(pos, nil /* onClose */)
+	res := icc.createRes(pos, nil /* onClose */)
+	res.it = icc.it
+	return res
 }
 
 // createRes creates a result. onClose, if not nil, is called when the result is
@@ -629,6 +829,13 @@ func (icc *internalClientComm) CreateDrainResult(pos CmdPos) DrainResult {
 	panic("unimplemented")
 }
 
+// CreateDeliverNotificationsResult is part of the ClientComm interface.
+func (icc *internalClientComm) CreateDeliverNotificationsResult(
+	pos CmdPos,
+) DeliverNotificationsResult {
+	panic("unimplemented")
+}
+
 // noopClientLock is an implementation of ClientLock that says that no results
 // have been communicated to the client.
 type noopClientLock internalClientComm
@@ -0,0 +1,228 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/sql/notify"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+)
+
+// sessionNotifications tracks the channels a session listens on and delivers
+// the notifications sent on them to the client. Notifications are only
+// available to the sessions of client connections; the registry is nil for
+// internal executors.
+type sessionNotifications struct {
+	registry *notify.Registry
+	stmtBuf  *StmtBuf
+	// sessionMon is the session monitor, to which the memory used by the queued
+	// notifications is accounted.
+	sessionMon *mon.BytesMonitor
+
+	// listener is created the first time the session starts listening on a
+	// channel.
+	listener *notify.Listener
+
+	// wakePending is set when a DeliverNotifications command was pushed on
+	// stmtBuf and was not executed yet. It is accessed atomically.
+	wakePending int32
+
+	// pendingOps are the LISTEN and UNLISTEN statements executed by the current
+	// transaction. Like in Postgres, they take effect when the transaction
+	// commits.
+	pendingOps []listenOp
+}
+
+// listenOp is a LISTEN or UNLISTEN statement.
+type listenOp struct {
+	// channel is empty for UNLISTEN *.
+	channel string
+	listen  bool
+}
+
+func (s *sessionNotifications) init(
+	registry *notify.Registry, stmtBuf *StmtBuf, sessionMon *mon.BytesMonitor,
+) {
+	s.registry = registry
+	s.stmtBuf = stmtBuf
+	s.sessionMon = sessionMon
+}
+
+// stage records a LISTEN or UNLISTEN statement, to be applied when the
+// transaction commits.
+func (s *sessionNotifications) stage(op listenOp) {
+	s.pendingOps = append(s.pendingOps, op)
+}
+
+// commit applies the LISTEN and UNLISTEN statements of the transaction that
+// just committed.
+func (s *sessionNotifications) commit() {
+	for _, op := range s.pendingOps {
+		switch {
+		case op.listen:
+			if s.listener == nil {
+				s.listener = s.registry.NewListener(s.sessionMon.MakeBoundAccount(), s.wake)
+			}
+			s.listener.Listen(op.channel)
+		case s.listener == nil:
+			// The session isn't listening on any channel.
+		case op.channel == "":
+			s.listener.UnlistenAll()
+		default:
+			s.listener.Unlisten(op.channel)
+		}
+	}
+}
+
+// reset discards the LISTEN and UNLISTEN statements of the current
+// transaction.
+func (s *sessionNotifications) reset() {
+	s.pendingOps = nil
+}
+
+// numPendingOps returns the number of LISTEN and UNLISTEN statements executed
+// by the current transaction so far. It is recorded by savepoints so that the
+// statements executed after them can be discarded by rollbackTo.
+func (s *sessionNotifications) numPendingOps() int {
+	return len(s.pendingOps)
+}
+
+// rollbackTo discards the LISTEN and UNLISTEN statements of the current
+// transaction that were executed after the first n ones, when rolling back to
+// a savepoint.
+func (s *sessionNotifications) rollbackTo(n int) {
+	s.pendingOps = s.pendingOps[:n]
+}
+
+// snapshotPendingOps returns a copy of the LISTEN and UNLISTEN statements
+// executed by the current transaction so far, which can be restored with
+// restorePendingOps when the transaction is rewound for an automatic retry.
+func (s *sessionNotifications) snapshotPendingOps() []listenOp {
+	return append([]listenOp(nil), s.pendingOps...)
+}
+
+// restorePendingOps restores a snapshot taken by snapshotPendingOps.
+func (s *sessionNotifications) restorePendingOps(ops []listenOp) {
+	s.pendingOps = append(s.pendingOps[:0], ops...)
+}
+
+// wake is called by the listener when notifications are queued. It pushes a
+// DeliverNotifications command so that the connExecutor delivers them even if
+// the client is not sending any statement.
+func (s *sessionNotifications) wake() {
+	if !atomic.CompareAndSwapInt32(&s.wakePending, 0, 1) {
+		return
+	}
+	// The buffer is only closed when the connection is going away, in which
+	// case the notifications don't need to be delivered.
+	_ = s.stmtBuf.Push(context.Background(), DeliverNotifications{})
+}
+
+// deliver buffers the queued notifications on res. If notifications were
+// dropped because the queue of the session was full, a notice reporting how
+// many were dropped is buffered as well.
+func (s *sessionNotifications) deliver(ctx context.Context, res NotificationBuffer) {
+	atomic.StoreInt32(&s.wakePending, 0)
+	if s.listener == nil {
+		return
+	}
+	queue, dropped := s.listener.Drain(ctx)
+	if dropped > 0 {
+		res.BufferNotice(pgnotice.Newf(
+			"%d notifications were dropped because the notification queue of the session was full",
+			dropped,
+		))
+	}
+	for _, n := range queue {
+		res.BufferNotification(n)
+	}
+}
+
+// close stops listening on all channels.
+func (s *sessionNotifications) close(ctx context.Context) {
+	if s.listener != nil {
+		s.listener.Close(ctx)
+		s.listener = nil
+	}
+}
+
+// Listen implements the LISTEN statement.
+// See https://www.postgresql.org/docs/current/sql-listen.html for details.
+// Privileges: None.
+func (p *planner) Listen(ctx context.Context, n *tree.Listen) (planNode, error) {
+	if p.extendedEvalCtx.notifications.registry == nil {
+		return nil, pgerror.New(pgcode.FeatureNotSupported,
+			"LISTEN is only supported by client sessions")
+	}
+	return &listenNode{op: listenOp{channel: string(n.ChannelName), listen: true}}, nil
+}
+
+// Unlisten implements the UNLISTEN statement.
+// See https://www.postgresql.org/docs/current/sql-unlisten.html for details.
+// Privileges: None.
+func (p *planner) Unlisten(ctx context.Context, n *tree.Unlisten) (planNode, error) {
+	return &listenNode{op: listenOp{channel: string(n.ChannelName)}}, nil
+}
+
+// listenNode represents a LISTEN or UNLISTEN statement.
+type listenNode struct {
+	op listenOp
+}
+
+func (n *listenNode) startExec(params runParams) error {
+	params.extendedEvalCtx.notifications.stage(n.op)
+	return nil
+}
+
+func (*listenNode) Next(runParams) (bool, error) { return false, nil }
+func (*listenNode) Values() tree.Datums          { return nil }
+func (*listenNode) Close(context.Context)        {}
+
+// Notify implements the NOTIFY statement.
+// See https://www.postgresql.org/docs/current/sql-notify.html for details.
+// Privileges: None.
+func (p *planner) Notify(ctx context.Context, n *tree.Notify) (planNode, error) {
+	return &notifyNode{channel: string(n.ChannelName), payload: n.Payload}, nil
+}
+
+// notifyNode represents a NOTIFY statement.
+type notifyNode struct {
+	channel string
+	payload string
+}
+
+func (n *notifyNode) startExec(params runParams) error {
+	return params.p.SendNotification(params.ctx, n.channel, n.payload)
+}
+
+func (*notifyNode) Next(runParams) (bool, error) { return false, nil }
+func (*notifyNode) Values() tree.Datums          { return nil }
+func (*notifyNode) Close(context.Context)        {}
+
+// SendNotification is part of the tree.EvalPlanner interface.
+func (p *planner) SendNotification(ctx context.Context, channel, payload string) error {
+	if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.NotificationsTable) {
+		return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			`sending notifications requires all nodes to be upgraded to %s`,
+			clusterversion.ByKey(clusterversion.NotificationsTable))
+	}
+	return notify.Send(
+		ctx, p.ExecCfg().InternalExecutor, p.txn,
+		int32(p.ExecCfg().NodeID.SQLInstanceID()), channel, payload,
+	)
+}
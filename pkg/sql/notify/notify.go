@@ -0,0 +1,120 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package notify implements the asynchronous notifications sent with NOTIFY
+// and received with LISTEN.
+//
+// Notifications are recorded in system.notifications by the transaction that
+// sends them, so they become visible only when that transaction commits. Each
+// SQL gateway watches the table with a rangefeed and dispatches the
+// notifications it sees to the sessions that listen on their channel, which
+// forward them to their client as NotificationResponse messages.
+package notify
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/systemschema"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/errors"
+)
+
+// MaxPayloadLength is the maximum length of the payload of a notification, in
+// bytes. It matches the limit of Postgres.
+const MaxPayloadLength = 8000
+
+// Notification is an asynchronous notification sent on a channel.
+type Notification struct {
+	Channel string
+	Payload string
+	// NodeID is the ID of the node on which the notification was sent. It is
+	// reported to the client in place of the process ID of the notifying
+	// backend.
+	NodeID int32
+}
+
+// Send records a notification in system.notifications using the given
+// transaction. The notification is delivered to the sessions listening on the
+// channel once the transaction commits.
+func Send(
+	ctx context.Context,
+	ie sqlutil.InternalExecutor,
+	txn *kv.Txn,
+	nodeID int32,
+	channel, payload string,
+) error {
+	if channel == "" {
+		return pgerror.New(pgcode.InvalidParameterValue, "channel name cannot be empty")
+	}
+	if len(payload) > MaxPayloadLength {
+		return pgerror.New(pgcode.InvalidParameterValue, "payload string too long")
+	}
+	_, err := ie.ExecEx(ctx, "send-notification", txn,
+		sessiondata.InternalExecutorOverride{User: security.NodeUserName()},
+		`INSERT INTO system.notifications (channel, payload, node_id) VALUES ($1, $2, $3)`,
+		channel, payload, nodeID,
+	)
+	return err
+}
+
+// tableSpan returns the span of system.notifications.
+func tableSpan(codec keys.SQLCodec) roachpb.Span {
+	prefix := codec.IndexPrefix(uint32(keys.NotificationsTableID), 1 /* indexID */)
+	return roachpb.Span{Key: prefix, EndKey: prefix.PrefixEnd()}
+}
+
+// decodeNotification decodes the value of a row of system.notifications.
+func decodeNotification(a *rowenc.DatumAlloc, value roachpb.Value) (Notification, error) {
+	var n Notification
+	b, err := value.GetTuple()
+	if err != nil {
+		return n, err
+	}
+	var colID descpb.ColumnID
+	for len(b) > 0 {
+		_, _, colIDDiff, _, err := encoding.DecodeValueTag(b)
+		if err != nil {
+			return n, err
+		}
+		colID += descpb.ColumnID(colIDDiff)
+		col, err := systemschema.NotificationsTable.FindColumnByID(colID)
+		if err != nil {
+			return n, err
+		}
+		var d tree.Datum
+		d, b, err = rowenc.DecodeTableValue(a, col.Type, b)
+		if err != nil {
+			return n, err
+		}
+		switch col.Name {
+		case "channel":
+			n.Channel = string(tree.MustBeDString(d))
+		case "payload":
+			n.Payload = string(tree.MustBeDString(d))
+		case "node_id":
+			n.NodeID = int32(tree.MustBeDInt(d))
+		}
+	}
+	if n.Channel == "" {
+		return n, errors.AssertionFailedf("notification without a channel")
+	}
+	return n, nil
+}
@@ -0,0 +1,195 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package notify_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/notify"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/testcluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/require"
+)
+
+// TestListenNotify checks that notifications sent on a node are delivered
+// asynchronously to the sessions listening on another node.
+func TestListenNotify(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	tc := testcluster.StartTestCluster(t, 2, base.TestClusterArgs{})
+	defer tc.Stopper().Stop(ctx)
+
+	pgURL, cleanup := sqlutils.PGUrl(
+		t, tc.Server(0).ServingSQLAddr(), "TestListenNotify", url.User(security.RootUser))
+	defer cleanup()
+	listener, err := pgx.Connect(ctx, pgURL.String())
+	require.NoError(t, err)
+	defer func() { _ = listener.Close(ctx) }()
+
+	notifier := sqlutils.MakeSQLRunner(tc.ServerConn(1))
+
+	waitForNotification := func() *pgconn.Notification {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(ctx, 45*time.Second)
+		defer cancel()
+		n, err := listener.WaitForNotification(ctx)
+		require.NoError(t, err)
+		return n
+	}
+	expectNoNotification := func() {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		n, err := listener.WaitForNotification(ctx)
+		require.Error(t, err, "unexpected notification %+v", n)
+	}
+
+	_, err = listener.Exec(ctx, "LISTEN foo")
+	require.NoError(t, err)
+
+	// Notifications sent by transactions that are rolled back are not delivered,
+	// and the ones sent on other channels are not delivered either.
+	notifier.Exec(t, "BEGIN; NOTIFY foo, 'rolled back'; ROLLBACK")
+	notifier.Exec(t, "NOTIFY bar, 'other channel'")
+	notifier.Exec(t, "NOTIFY foo, 'hello'")
+	n := waitForNotification()
+	require.Equal(t, "foo", n.Channel)
+	require.Equal(t, "hello", n.Payload)
+	require.Equal(t, uint32(tc.Server(1).NodeID()), n.PID)
+
+	// Notifications sent with pg_notify are delivered too, and the ones sent by
+	// a transaction are delivered in order when it commits.
+	notifier.Exec(t, "BEGIN; SELECT pg_notify('foo', 'a'); NOTIFY foo, 'b'; COMMIT")
+	require.Equal(t, "a", waitForNotification().Payload)
+	require.Equal(t, "b", waitForNotification().Payload)
+
+	// UNLISTEN only takes effect when the transaction commits.
+	tx, err := listener.Begin(ctx)
+	require.NoError(t, err)
+	_, err = tx.Exec(ctx, "UNLISTEN *")
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback(ctx))
+	notifier.Exec(t, "NOTIFY foo")
+	n = waitForNotification()
+	require.Equal(t, "foo", n.Channel)
+	require.Equal(t, "", n.Payload)
+
+	// Rolling back to a savepoint discards the LISTEN and UNLISTEN statements
+	// executed after it, and keeps the ones executed before it.
+	tx, err = listener.Begin(ctx)
+	require.NoError(t, err)
+	for _, stmt := range []string{"LISTEN baz", "SAVEPOINT s", "UNLISTEN baz", "LISTEN qux"} {
+		_, err = tx.Exec(ctx, stmt)
+		require.NoError(t, err)
+	}
+	_, err = tx.Exec(ctx, "SELECT 1/0")
+	require.Error(t, err)
+	_, err = tx.Exec(ctx, "ROLLBACK TO SAVEPOINT s")
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit(ctx))
+	notifier.Exec(t, "NOTIFY qux, 'rolled back'")
+	notifier.Exec(t, "NOTIFY baz, 'kept'")
+	n = waitForNotification()
+	require.Equal(t, "baz", n.Channel)
+	require.Equal(t, "kept", n.Payload)
+
+	_, err = listener.Exec(ctx, "UNLISTEN *")
+	require.NoError(t, err)
+	notifier.Exec(t, "NOTIFY foo, 'not listening'")
+	expectNoNotification()
+}
+
+// TestListenNotifyQueueFull checks that the notifications received when the
+// queue of a session is full are dropped, and that the client is told how many
+// were dropped.
+func TestListenNotifyQueueFull(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	notify.MaxQueuedPerSession.Override(&st.SV, 2)
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{Settings: st})
+	defer s.Stopper().Stop(ctx)
+
+	pgURL, cleanup := sqlutils.PGUrl(
+		t, s.ServingSQLAddr(), "TestListenNotifyQueueFull", url.User(security.RootUser))
+	defer cleanup()
+	connect := func(onNotice pgconn.NoticeHandler) *pgx.Conn {
+		t.Helper()
+		config, err := pgx.ParseConfig(pgURL.String())
+		require.NoError(t, err)
+		config.OnNotice = onNotice
+		conn, err := pgx.ConnectConfig(ctx, config)
+		require.NoError(t, err)
+		return conn
+	}
+	waitForNotification := func(conn *pgx.Conn) *pgconn.Notification {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(ctx, 45*time.Second)
+		defer cancel()
+		n, err := conn.WaitForNotification(ctx)
+		require.NoError(t, err)
+		return n
+	}
+
+	var notices []string
+	listener := connect(func(_ *pgconn.PgConn, n *pgconn.Notice) {
+		notices = append(notices, n.Message)
+	})
+	defer func() { _ = listener.Close(ctx) }()
+	// The notifications received by the other session are queued on the
+	// listener at the same time, so it is used to know when the listener's
+	// queue overflowed.
+	other := connect(nil /* onNotice */)
+	defer func() { _ = other.Close(ctx) }()
+	for _, conn := range []*pgx.Conn{listener, other} {
+		_, err := conn.Exec(ctx, "LISTEN foo")
+		require.NoError(t, err)
+	}
+
+	// Notifications are only delivered between transactions, so they stay
+	// queued on the listener until its transaction commits.
+	tx, err := listener.Begin(ctx)
+	require.NoError(t, err)
+	notifier := sqlutils.MakeSQLRunner(db)
+	payloads := []string{"a", "b", "c", "d", "e"}
+	for _, payload := range payloads {
+		notifier.Exec(t, "NOTIFY foo, '"+payload+"'")
+	}
+	for _, payload := range payloads {
+		require.Equal(t, payload, waitForNotification(other).Payload)
+	}
+	require.NoError(t, tx.Commit(ctx))
+
+	require.Equal(t, "a", waitForNotification(listener).Payload)
+	require.Equal(t, "b", waitForNotification(listener).Payload)
+	require.Equal(t, []string{
+		"3 notifications were dropped because the notification queue of the session was full",
+	}, notices)
+
+	// The queue is usable again once it was drained.
+	notifier.Exec(t, "NOTIFY foo, 'f'")
+	require.Equal(t, "f", waitForNotification(listener).Payload)
+}
@@ -0,0 +1,399 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package notify
+
+import (
+	"context"
+	"sort"
+	"time"
+	"unsafe"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/span"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// GCInterval is the interval at which each node deletes the notifications
+// that are older than the retention period.
+var GCInterval = settings.RegisterDurationSetting(
+	"sql.notifications.gc_interval",
+	"interval at which notifications older than sql.notifications.retention are deleted",
+	time.Minute,
+	settings.PositiveDuration,
+)
+
+// Retention is how long notifications are kept in system.notifications. It
+// only needs to be long enough for the rangefeeds of all the nodes to observe
+// them.
+var Retention = settings.RegisterDurationSetting(
+	"sql.notifications.retention",
+	"how long notifications sent with NOTIFY are kept before they are deleted",
+	10*time.Minute,
+	settings.NonNegativeDuration,
+)
+
+// MaxQueuedPerSession is the maximum number of notifications queued for a
+// session until they are delivered to its client. The notifications received
+// when the queue is full are dropped, and the client is told how many were
+// dropped with a notice.
+var MaxQueuedPerSession = settings.RegisterIntSetting(
+	"sql.notifications.max_queued_per_session",
+	"maximum number of notifications queued for a session before they are delivered to its client; "+
+		"notifications received when the queue is full are dropped",
+	10000,
+	settings.PositiveInt,
+)
+
+// Registry watches system.notifications and dispatches the notifications
+// sent in the cluster to the local sessions that listen on their channel.
+//
+// The table is only watched once a local session starts listening, so that
+// nodes on which LISTEN is never used don't run a rangefeed.
+type Registry struct {
+	distSender *kvcoord.DistSender
+	ie         sqlutil.InternalExecutor
+	codec      keys.SQLCodec
+	clock      *hlc.Clock
+	st         *cluster.Settings
+	stopper    *stop.Stopper
+
+	// startWatching is closed when the first Listener is created.
+	startWatching chan struct{}
+
+	mu struct {
+		syncutil.Mutex
+		watching  bool
+		listeners map[*Listener]struct{}
+		// frontier tracks the timestamp up to which the rangefeed has emitted
+		// all the notifications.
+		frontier *span.Frontier
+		// delivered contains the keys of the notifications that were dispatched
+		// and that are above the frontier. The rangefeed emits them again if it
+		// is restarted, so they are used to avoid dispatching them twice.
+		delivered map[string]hlc.Timestamp
+	}
+}
+
+// NewRegistry creates a Registry. It does not watch for notifications until
+// Start is called.
+func NewRegistry(
+	distSender *kvcoord.DistSender,
+	ie sqlutil.InternalExecutor,
+	codec keys.SQLCodec,
+	clock *hlc.Clock,
+	st *cluster.Settings,
+	stopper *stop.Stopper,
+) *Registry {
+	r := &Registry{
+		distSender: distSender,
+		ie:         ie,
+		codec:      codec,
+		clock:      clock,
+		st:         st,
+		stopper:    stopper,
+
+		startWatching: make(chan struct{}),
+	}
+	r.mu.listeners = make(map[*Listener]struct{})
+	r.mu.frontier = span.MakeFrontier(tableSpan(codec))
+	r.mu.delivered = make(map[string]hlc.Timestamp)
+	return r
+}
+
+// Start starts periodically deleting the old notifications, and watching
+// system.notifications for new notifications once a Listener is created.
+func (r *Registry) Start(ctx context.Context) {
+	ctx, _ = r.stopper.WithCancelOnQuiesce(ctx)
+	tableSpan := tableSpan(r.codec)
+
+	eventCh := make(chan *roachpb.RangeFeedEvent)
+	if err := r.stopper.RunAsyncTask(ctx, "notifications-rangefeed", func(ctx context.Context) {
+		select {
+		case <-r.startWatching:
+		case <-ctx.Done():
+			return
+		}
+		// Run the rangefeed in a loop in the case of failure, restarting it
+		// from the frontier.
+		restartLogEvery := log.Every(10 * time.Second)
+		for i, retrier := 1, retry.StartWithCtx(ctx, retry.Options{
+			InitialBackoff: 100 * time.Millisecond,
+			MaxBackoff:     2 * time.Second,
+			Closer:         r.stopper.ShouldQuiesce(),
+		}); retrier.Next(); i++ {
+			r.mu.Lock()
+			ts := r.mu.frontier.Frontier()
+			r.mu.Unlock()
+			const withDiff = false
+			err := r.distSender.RangeFeed(ctx, tableSpan, ts, withDiff, eventCh)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil && restartLogEvery.ShouldLog() {
+				log.Warningf(ctx, "notifications rangefeed failed %d times, restarting: %v",
+					log.Safe(i), err)
+			}
+		}
+	}); err != nil {
+		// The stopper is stopping.
+		return
+	}
+
+	_ = r.stopper.RunAsyncTask(ctx, "notifications-dispatch", func(ctx context.Context) {
+		var alloc rowenc.DatumAlloc
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev := <-eventCh:
+				switch {
+				case ev.Checkpoint != nil:
+					r.forward(ev.Checkpoint.Span, ev.Checkpoint.ResolvedTS)
+				case ev.Val != nil:
+					if len(ev.Val.Value.RawBytes) == 0 {
+						// A notification was deleted.
+						continue
+					}
+					n, err := decodeNotification(&alloc, ev.Val.Value)
+					if err != nil {
+						log.Warningf(ctx, "unable to decode notification %s: %v", ev.Val.Key, err)
+						continue
+					}
+					r.dispatch(ctx, ev.Val.Key, ev.Val.Value.Timestamp, n)
+				}
+			}
+		}
+	})
+
+	_ = r.stopper.RunAsyncTask(ctx, "notifications-gc", func(ctx context.Context) {
+		var timer timeutil.Timer
+		defer timer.Stop()
+		for {
+			timer.Reset(GCInterval.Get(&r.st.SV))
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+				timer.Read = true
+				r.deleteOldNotifications(ctx)
+			}
+		}
+	})
+}
+
+// forward records that the rangefeed emitted all the notifications up to the
+// given timestamp for the given span.
+func (r *Registry) forward(sp roachpb.Span, ts hlc.Timestamp) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.mu.frontier.Forward(sp, ts) {
+		return
+	}
+	frontier := r.mu.frontier.Frontier()
+	for k, ts := range r.mu.delivered {
+		if ts.Less(frontier) {
+			delete(r.mu.delivered, k)
+		}
+	}
+}
+
+// dispatch queues the notification stored at the given key and committed at
+// the given timestamp on the listeners that listen on its channel.
+func (r *Registry) dispatch(
+	ctx context.Context, key roachpb.Key, ts hlc.Timestamp, n Notification,
+) {
+	var toWake []*Listener
+	r.mu.Lock()
+	if _, ok := r.mu.delivered[string(key)]; ok || ts.Less(r.mu.frontier.Frontier()) {
+		r.mu.Unlock()
+		return
+	}
+	r.mu.delivered[string(key)] = ts
+	for l := range r.mu.listeners {
+		if l.enqueue(ctx, ts, n) {
+			toWake = append(toWake, l)
+		}
+	}
+	r.mu.Unlock()
+	for _, l := range toWake {
+		l.onNotify()
+	}
+}
+
+func (r *Registry) deleteOldNotifications(ctx context.Context) {
+	if !r.st.Version.IsActive(ctx, clusterversion.NotificationsTable) {
+		return
+	}
+	_, err := r.ie.ExecEx(ctx, "delete-old-notifications", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: security.NodeUserName()},
+		`DELETE FROM system.notifications WHERE sent_at < $1`,
+		r.clock.PhysicalTime().Add(-Retention.Get(&r.st.SV)),
+	)
+	if err != nil && ctx.Err() == nil {
+		log.Warningf(ctx, "unable to delete old notifications: %v", err)
+	}
+}
+
+// NewListener creates a Listener that is not listening on any channel yet.
+// The memory used by the queued notifications is accounted to acc, which the
+// Listener takes ownership of. onNotify is called whenever notifications are
+// queued on the Listener, or start being dropped. It must not block.
+func (r *Registry) NewListener(acc mon.BoundAccount, onNotify func()) *Listener {
+	l := &Listener{r: r, onNotify: onNotify}
+	l.mu.acc = acc
+	l.mu.channels = make(map[string]hlc.Timestamp)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.mu.watching {
+		// The rangefeed starts from the frontier, which therefore needs to be
+		// below the time at which the Listener starts listening on any channel.
+		r.mu.watching = true
+		r.mu.frontier.Forward(tableSpan(r.codec), r.clock.Now())
+		close(r.startWatching)
+	}
+	r.mu.listeners[l] = struct{}{}
+	return l
+}
+
+// Listener queues the notifications sent on the channels a session listens
+// on, until they are delivered to the client.
+type Listener struct {
+	r        *Registry
+	onNotify func()
+
+	mu struct {
+		syncutil.Mutex
+		// channels maps each channel the Listener listens on to the time at
+		// which it started listening. Only the notifications sent after that
+		// are queued.
+		channels map[string]hlc.Timestamp
+		queue    []Notification
+		// queueBytes is the memory used by queue, which is accounted to acc.
+		queueBytes int64
+		acc        mon.BoundAccount
+		// dropped is the number of notifications that were dropped since the
+		// queue was last drained, because it was full.
+		dropped int
+	}
+}
+
+// notificationOverhead is the memory used by a queued Notification, in
+// addition to its channel and payload.
+const notificationOverhead = int64(unsafe.Sizeof(Notification{}))
+
+func notificationSize(n Notification) int64 {
+	return notificationOverhead + int64(len(n.Channel)+len(n.Payload))
+}
+
+// Listen starts listening on the given channel. It is a no-op if the Listener
+// already listens on the channel.
+func (l *Listener) Listen(channel string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.mu.channels[channel]; !ok {
+		l.mu.channels[channel] = l.r.clock.Now()
+	}
+}
+
+// Unlisten stops listening on the given channel. The notifications already
+// queued for the channel are still delivered.
+func (l *Listener) Unlisten(channel string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.mu.channels, channel)
+}
+
+// UnlistenAll stops listening on all channels.
+func (l *Listener) UnlistenAll() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for channel := range l.mu.channels {
+		delete(l.mu.channels, channel)
+	}
+}
+
+// Channels returns the channels the Listener listens on, in sorted order.
+func (l *Listener) Channels() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	channels := make([]string, 0, len(l.mu.channels))
+	for channel := range l.mu.channels {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+// Drain returns the queued notifications, in the order in which they were
+// received, and empties the queue. It also returns the number of
+// notifications that were dropped since the last call because the queue was
+// full.
+func (l *Listener) Drain(ctx context.Context) ([]Notification, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	queue, dropped := l.mu.queue, l.mu.dropped
+	l.mu.queue, l.mu.dropped = nil, 0
+	l.mu.acc.Shrink(ctx, l.mu.queueBytes)
+	l.mu.queueBytes = 0
+	return queue, dropped
+}
+
+// Close stops the Listener from receiving notifications, and releases the
+// memory used by the notifications that are still queued.
+func (l *Listener) Close(ctx context.Context) {
+	l.r.mu.Lock()
+	delete(l.r.mu.listeners, l)
+	l.r.mu.Unlock()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.mu.queue, l.mu.queueBytes = nil, 0
+	l.mu.acc.Close(ctx)
+}
+
+// enqueue queues the notification committed at the given timestamp if the
+// Listener was listening on its channel at that time. The notification is
+// dropped if the queue is full or if its memory can't be accounted for. It
+// returns whether onNotify needs to be called, which is the case when the
+// notification was queued or when the first notification was dropped since
+// the queue was last drained.
+func (l *Listener) enqueue(ctx context.Context, ts hlc.Timestamp, n Notification) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	listenTS, ok := l.mu.channels[n.Channel]
+	if !ok || ts.LessEq(listenTS) {
+		return false
+	}
+	size := notificationSize(n)
+	if int64(len(l.mu.queue)) >= MaxQueuedPerSession.Get(&l.r.st.SV) ||
+		l.mu.acc.Grow(ctx, size) != nil {
+		l.mu.dropped++
+		return l.mu.dropped == 1
+	}
+	l.mu.queue = append(l.mu.queue, n)
+	l.mu.queueBytes += size
+	return true
+}
@@ -85,10 +85,14 @@ func buildOpaque(
 		plan, err = p.CommentOnTable(ctx, n)
 	case *tree.CreateDatabase:
 		plan, err = p.CreateDatabase(ctx, n)
+	case *tree.CreateDomain:
+		plan, err = p.CreateDomain(ctx, n)
 	case *tree.CreateIndex:
 		plan, err = p.CreateIndex(ctx, n)
 	case *tree.CreateSchema:
 		plan, err = p.CreateSchema(ctx, n)
+	case *tree.CreateTrigger:
+		plan, err = p.CreateTrigger(ctx, n)
 	case *tree.CreateType:
 		plan, err = p.CreateType(ctx, n)
 	case *tree.CreateRole:
@@ -103,6 +107,10 @@ func buildOpaque(
 		plan, err = p.Discard(ctx, n)
 	case *tree.DropDatabase:
 		plan, err = p.DropDatabase(ctx, n)
+	case *tree.DropDomain:
+		plan, err = p.DropDomain(ctx, n)
+	case *tree.DropFunction:
+		plan, err = p.DropFunction(ctx, n)
 	case *tree.DropIndex:
 		plan, err = p.DropIndex(ctx, n)
 	case *tree.DropOwnedBy:
@@ -115,6 +123,8 @@ func buildOpaque(
 		plan, err = p.DropSequence(ctx, n)
 	case *tree.DropTable:
 		plan, err = p.DropTable(ctx, n)
+	case *tree.DropTrigger:
+		plan, err = p.DropTrigger(ctx, n)
 	case *tree.DropType:
 		plan, err = p.DropType(ctx, n)
 	case *tree.DropView:
@@ -123,6 +133,10 @@ func buildOpaque(
 		plan, err = p.Grant(ctx, n)
 	case *tree.GrantRole:
 		plan, err = p.GrantRole(ctx, n)
+	case *tree.Listen:
+		plan, err = p.Listen(ctx, n)
+	case *tree.Notify:
+		plan, err = p.Notify(ctx, n)
 	case *tree.ReassignOwnedBy:
 		plan, err = p.ReassignOwnedBy(ctx, n)
 	case *tree.RefreshMaterializedView:
@@ -147,6 +161,8 @@ func buildOpaque(
 		plan, err = p.Scrub(ctx, n)
 	case *tree.SetClusterSetting:
 		plan, err = p.SetClusterSetting(ctx, n)
+	case *tree.SetConstraints:
+		plan, err = p.SetConstraints(ctx, n)
 	case *tree.SetZoneConfig:
 		plan, err = p.SetZoneConfig(ctx, n)
 	case *tree.SetVar:
@@ -171,6 +187,8 @@ func buildOpaque(
 		plan, err = p.ShowFingerprints(ctx, n)
 	case *tree.Truncate:
 		plan, err = p.Truncate(ctx, n)
+	case *tree.Unlisten:
+		plan, err = p.Unlisten(ctx, n)
 	case tree.CCLOnlyStatement:
 		plan, err = p.maybePlanHook(ctx, stmt)
 		if plan == nil && err == nil {
@@ -214,25 +232,32 @@ func init() {
 		&tree.CommentOnIndex{},
 		&tree.CommentOnTable{},
 		&tree.CreateDatabase{},
+		&tree.CreateDomain{},
 		&tree.CreateExtension{},
 		&tree.CreateIndex{},
 		&tree.CreateSchema{},
 		&tree.CreateSequence{},
+		&tree.CreateTrigger{},
 		&tree.CreateType{},
 		&tree.CreateRole{},
 		&tree.Deallocate{},
 		&tree.Discard{},
 		&tree.DropDatabase{},
+		&tree.DropDomain{},
+		&tree.DropFunction{},
 		&tree.DropIndex{},
 		&tree.DropOwnedBy{},
 		&tree.DropRole{},
 		&tree.DropSchema{},
 		&tree.DropSequence{},
 		&tree.DropTable{},
+		&tree.DropTrigger{},
 		&tree.DropType{},
 		&tree.DropView{},
 		&tree.Grant{},
 		&tree.GrantRole{},
+		&tree.Listen{},
+		&tree.Notify{},
 		&tree.ReassignOwnedBy{},
 		&tree.RefreshMaterializedView{},
 		&tree.RenameColumn{},
@@ -245,6 +270,7 @@ func init() {
 		&tree.Scatter{},
 		&tree.Scrub{},
 		&tree.SetClusterSetting{},
+		&tree.SetConstraints{},
 		&tree.SetZoneConfig{},
 		&tree.SetVar{},
 		&tree.SetTransaction{},
@@ -257,9 +283,11 @@ func init() {
 		&tree.ShowZoneConfig{},
 		&tree.ShowFingerprints{},
 		&tree.Truncate{},
+		&tree.Unlisten{},
 
 		// CCL statements (without Export which has an optimizer operator).
 		&tree.Backup{},
+		&tree.CompactBackup{},
 		&tree.ShowBackup{},
 		&tree.Restore{},
 		&tree.CreateChangefeed{},
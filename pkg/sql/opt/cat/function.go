@@ -0,0 +1,49 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cat
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+)
+
+// FunctionName is an alias for tree.FunctionName.
+type FunctionName = tree.FunctionName
+
+// Function is an interface to a user-defined SQL function, exposing only the
+// information needed by the query optimizer to inline calls to it.
+type Function interface {
+	Object
+
+	// Name returns the unqualified name of the function.
+	Name() tree.Name
+
+	// ArgCount returns the number of arguments of the function.
+	ArgCount() int
+
+	// ArgName returns the name of the ith argument, where i < ArgCount. It is
+	// empty if the argument is unnamed, in which case it can only be referenced
+	// positionally as $i+1.
+	ArgName(i int) tree.Name
+
+	// ArgType returns the type of the ith argument, where i < ArgCount.
+	ArgType(i int) *types.T
+
+	// ReturnType returns the type of the value returned by the function.
+	ReturnType() *types.T
+
+	// Body returns the SQL text of the SELECT query that constitutes the body of
+	// the function, as it was written in CREATE FUNCTION.
+	Body() string
+
+	// Volatility returns the declared volatility of the function.
+	Volatility() tree.Volatility
+}
@@ -131,6 +131,23 @@ type Table interface {
 	// Unique returns the ith unique constraint defined on this table, where
 	// i < UniqueCount.
 	Unique(i int) UniqueConstraint
+
+	// TriggerCount returns the number of row-level triggers defined on this
+	// table.
+	TriggerCount() int
+
+	// Trigger returns the ith trigger defined on this table, where
+	// i < TriggerCount. Triggers are ordered by name, which is the order in
+	// which they fire.
+	Trigger(i int) Trigger
+
+	// ExclusionCount returns the number of exclusion constraints defined on this
+	// table.
+	ExclusionCount() int
+
+	// Exclusion returns the ith exclusion constraint defined on this table,
+	// where i < ExclusionCount.
+	Exclusion(i int) ExclusionConstraint
 }
 
 // CheckConstraint contains the SQL text and the validity status for a check
@@ -145,6 +162,28 @@ type CheckConstraint struct {
 	Validated  bool
 }
 
+// Trigger contains the definition of a row-level trigger on a table. A trigger
+// runs its body once for each row affected by one of its events. For example,
+// this trigger records every deleted row of table a in table a_audit:
+//
+//   CREATE TRIGGER audit AFTER DELETE ON a FOR EACH ROW
+//     AS 'INSERT INTO a_audit VALUES (old.k, now())'
+//
+type Trigger struct {
+	Name       tree.Name
+	ActionTime tree.TriggerActionTime
+	Events     tree.TriggerEvents
+	// Body is the SQL text of the statement run by the trigger, as it was
+	// written in CREATE TRIGGER.
+	Body string
+}
+
+// Fires returns true if the trigger runs at the given time for the given
+// event.
+func (t *Trigger) Fires(actionTime tree.TriggerActionTime, event tree.TriggerEvent) bool {
+	return t.ActionTime == actionTime && t.Events.Contains(event)
+}
+
 // TableStatistic is an interface to a table statistic. Each statistic is
 // associated with a set of columns.
 type TableStatistic interface {
@@ -244,6 +283,10 @@ type ForeignKeyConstraint interface {
 	// UpdateReferenceAction returns the action to be performed if the foreign key
 	// constraint would be violated by an update.
 	UpdateReferenceAction() tree.ReferenceAction
+
+	// Deferrability returns whether the checks of the constraint can be
+	// deferred until the end of the transaction.
+	Deferrability() tree.ConstraintDeferrability
 }
 
 // UniqueConstraint represents a uniqueness constraint. UniqueConstraints may
@@ -277,4 +320,33 @@ type UniqueConstraint interface {
 	// cannot make any assumptions about the data. An unvalidated constraint still
 	// needs to be enforced on new mutations.
 	Validated() bool
+
+	// Deferrability returns whether the checks of the constraint can be
+	// deferred until the end of the transaction. Only unique constraints that
+	// are not enforced by an index can be deferrable.
+	Deferrability() tree.ConstraintDeferrability
+}
+
+// ExclusionConstraint represents an exclusion constraint. An exclusion
+// constraint guarantees that no two rows of the table compare true on all of
+// the constraint's operators. For example, the following statement ensures
+// that the areas of two rows with the same owner never overlap:
+//   ALTER TABLE t ADD CONSTRAINT e EXCLUDE USING gist (owner WITH =, area WITH &&);
+// Like UNIQUE WITHOUT INDEX constraints, exclusion constraints are enforced by
+// check queries that the optimizer adds as postqueries to any query that
+// inserts into or updates the constraint's columns.
+type ExclusionConstraint interface {
+	// Name of the exclusion constraint.
+	Name() string
+
+	// ColumnCount returns the number of columns in this constraint.
+	ColumnCount() int
+
+	// ColumnOrdinal returns the table column ordinal of the ith column in this
+	// constraint.
+	ColumnOrdinal(tab Table, i int) int
+
+	// Operator returns the operator used to compare the ith column of two rows.
+	// Two rows conflict if the operators of all columns return true.
+	Operator(i int) tree.ComparisonOperator
 }
@@ -173,6 +173,23 @@ func FormatTable(cat Catalog, tab Table, tp treeprinter.Node) {
 		)
 	}
 
+	for i := 0; i < tab.ExclusionCount(); i++ {
+		ec := tab.Exclusion(i)
+		var buf bytes.Buffer
+		for j := 0; j < ec.ColumnCount(); j++ {
+			if j > 0 {
+				buf.WriteString(", ")
+			}
+			fmt.Fprintf(&buf, "%s WITH %s", tab.Column(ec.ColumnOrdinal(tab, j)).ColName(), ec.Operator(j))
+		}
+		child.Childf("EXCLUDE %s (%s)", ec.Name(), buf.String())
+	}
+
+	for i := 0; i < tab.TriggerCount(); i++ {
+		t := tab.Trigger(i)
+		child.Childf("TRIGGER %s %s %s (%s)", t.Name, t.ActionTime, tree.AsString(&t.Events), t.Body)
+	}
+
 	// TODO(radu): show stats.
 }
 
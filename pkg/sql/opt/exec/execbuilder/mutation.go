@@ -123,6 +123,10 @@ func (b *Builder) buildInsert(ins *memo.InsertExpr) (execPlan, error) {
 		return execPlan{}, err
 	}
 
+	if err := b.buildFKCascades(ins.WithID, ins.FKCascades); err != nil {
+		return execPlan{}, err
+	}
+
 	return ep, nil
 }
 
@@ -156,6 +160,12 @@ func (b *Builder) tryBuildFastPathInsert(ins *memo.InsertExpr) (_ execPlan, ok b
 		return execPlan{}, false, nil
 	}
 
+	// We cannot use the fast path if there are any cascades (which is the case
+	// when the table has AFTER INSERT triggers).
+	if len(ins.FKCascades) > 0 {
+		return execPlan{}, false, nil
+	}
+
 	md := b.mem.Metadata()
 	tab := md.Table(ins.Table)
 
@@ -169,6 +179,10 @@ func (b *Builder) tryBuildFastPathInsert(ins *memo.InsertExpr) (_ execPlan, ok b
 			return execPlan{}, false, nil
 		}
 		fk := tab.OutboundForeignKey(c.FKOrdinal)
+		if fk.Deferrability() != tree.ConstraintNotDeferrable {
+			// The checks of deferrable FKs can't fail the insert.
+			return execPlan{}, false, nil
+		}
 		lookupJoin, isLookupJoin := c.Check.(*memo.LookupJoinExpr)
 		if !isLookupJoin || lookupJoin.JoinType != opt.AntiJoinOp {
 			// Not a lookup anti-join.
@@ -464,8 +478,14 @@ func (b *Builder) buildDelete(del *memo.DeleteExpr) (execPlan, error) {
 	//
 	// TODO(andyk): Using ensureColumns here can result in an extra Render.
 	// Upgrade execution engine to not require this.
-	colList := make(opt.ColList, 0, len(del.FetchCols)+len(del.PartialIndexDelCols))
+	colList := make(opt.ColList, 0, len(del.FetchCols)+len(del.PassthroughCols)+len(del.PartialIndexDelCols))
 	colList = appendColsWhenPresent(colList, del.FetchCols)
+	// The RETURNING clause of the Delete can refer to the columns in any of the
+	// USING tables. As a result, the Delete may need to passthrough those
+	// columns so the projection above can use them.
+	if del.NeedResults() {
+		colList = append(colList, del.PassthroughCols...)
+	}
 	colList = appendColsWhenPresent(colList, del.PartialIndexDelCols)
 
 	input, err := b.buildMutationInput(del, del.Input, colList, &del.MutationPrivate)
@@ -478,11 +498,22 @@ func (b *Builder) buildDelete(del *memo.DeleteExpr) (execPlan, error) {
 	tab := md.Table(del.Table)
 	fetchColOrds := ordinalSetFromColList(del.FetchCols)
 	returnColOrds := ordinalSetFromColList(del.ReturnCols)
+
+	// Construct the result columns for the passthrough set.
+	var passthroughCols colinfo.ResultColumns
+	if del.NeedResults() {
+		for _, passthroughCol := range del.PassthroughCols {
+			colMeta := b.mem.Metadata().ColumnMeta(passthroughCol)
+			passthroughCols = append(passthroughCols, colinfo.ResultColumn{Name: colMeta.Alias, Typ: colMeta.Type})
+		}
+	}
+
 	node, err := b.factory.ConstructDelete(
 		input.root,
 		tab,
 		fetchColOrds,
 		returnColOrds,
+		passthroughCols,
 		b.allowAutoCommit && len(del.FKChecks) == 0 && len(del.FKCascades) == 0,
 	)
 	if err != nil {
@@ -779,7 +810,7 @@ func mutationOutputColMap(mutation memo.RelExpr) opt.ColMap {
 }
 
 // buildUniqueChecks builds uniqueness check queries. These check queries are
-// used to enforce UNIQUE WITHOUT INDEX constraints.
+// used to enforce UNIQUE WITHOUT INDEX constraints and exclusion constraints.
 //
 // The checks consist of queries that will only return rows if a constraint is
 // violated. Those queries are each wrapped in an ErrorIfRows operator, which
@@ -794,13 +825,24 @@ func (b *Builder) buildUniqueChecks(checks memo.UniqueChecksExpr) error {
 			return err
 		}
 		// Wrap the query in an error node.
-		mkErr := func(row tree.Datums) error {
+		keyVals := func(row tree.Datums) tree.Datums {
 			keyVals := make(tree.Datums, len(c.KeyCols))
 			for i, col := range c.KeyCols {
 				keyVals[i] = row[query.getNodeColumnOrdinal(col)]
 			}
+			return keyVals
+		}
+		mkErr := func(row tree.Datums) error {
+			keyVals := keyVals(row)
+			if c.Exclusion {
+				return mkExclusionCheckErr(md, c, keyVals)
+			}
 			return mkUniqueCheckErr(md, c, keyVals)
 		}
+		if tab := md.Table(c.Table); !c.Exclusion {
+			uc := tab.Unique(c.CheckOrdinal)
+			mkErr = b.maybeDeferCheck(tab.ID(), uc.Name(), uc.Deferrability(), keyVals, mkErr)
+		}
 		node, err := b.factory.ConstructErrorIfRows(query.root, mkErr)
 		if err != nil {
 			return err
@@ -820,13 +862,24 @@ func (b *Builder) buildFKChecks(checks memo.FKChecksExpr) error {
 			return err
 		}
 		// Wrap the query in an error node.
-		mkErr := func(row tree.Datums) error {
+		keyVals := func(row tree.Datums) tree.Datums {
 			keyVals := make(tree.Datums, len(c.KeyCols))
 			for i, col := range c.KeyCols {
 				keyVals[i] = row[query.getNodeColumnOrdinal(col)]
 			}
-			return mkFKCheckErr(md, c, keyVals)
+			return keyVals
+		}
+		mkErr := func(row tree.Datums) error {
+			return mkFKCheckErr(md, c, keyVals(row))
 		}
+		origin := md.Table(c.OriginTable)
+		var fk cat.ForeignKeyConstraint
+		if c.FKOutbound {
+			fk = origin.OutboundForeignKey(c.FKOrdinal)
+		} else {
+			fk = md.Table(c.ReferencedTable).InboundForeignKey(c.FKOrdinal)
+		}
+		mkErr = b.maybeDeferCheck(origin.ID(), fk.Name(), fk.Deferrability(), keyVals, mkErr)
 		node, err := b.factory.ConstructErrorIfRows(query.root, mkErr)
 		if err != nil {
 			return err
@@ -836,6 +889,30 @@ func (b *Builder) buildFKChecks(checks memo.FKChecksExpr) error {
 	return nil
 }
 
+// maybeDeferCheck wraps the function that creates the error of a check query
+// for a constraint of the given table. If the constraint is deferrable and its
+// checks are deferred when the query finds a violation, the statement does not
+// fail: the key values of each violating row, returned by keyVals, are checked
+// again at the end of the transaction instead.
+func (b *Builder) maybeDeferCheck(
+	tableID cat.StableID,
+	constraintName string,
+	deferrability tree.ConstraintDeferrability,
+	keyVals func(row tree.Datums) tree.Datums,
+	mkErr exec.MkErrFn,
+) exec.MkErrFn {
+	if deferrability == tree.ConstraintNotDeferrable || b.evalCtx == nil || b.evalCtx.Planner == nil {
+		return mkErr
+	}
+	planner := b.evalCtx.Planner
+	return func(row tree.Datums) error {
+		if planner.DeferConstraintCheck(tree.ID(tableID), constraintName, deferrability, keyVals(row)) {
+			return nil
+		}
+		return mkErr(row)
+	}
+}
+
 // mkUniqueCheckErr generates a user-friendly error describing a uniqueness
 // violation. The keyVals are the values that correspond to the
 // cat.UniqueConstraint columns.
@@ -878,6 +955,48 @@ func mkUniqueCheckErr(md *opt.Metadata, c *memo.UniqueChecksItem, keyVals tree.D
 	)
 }
 
+// mkExclusionCheckErr generates a user-friendly error describing an exclusion
+// constraint violation. The keyVals are the values that correspond to the
+// cat.ExclusionConstraint columns.
+func mkExclusionCheckErr(md *opt.Metadata, c *memo.UniqueChecksItem, keyVals tree.Datums) error {
+	tabMeta := md.TableMeta(c.Table)
+	ec := tabMeta.Table.Exclusion(c.CheckOrdinal)
+	constraintName := ec.Name()
+	var msg, details bytes.Buffer
+
+	// Generate an error of the form:
+	//   ERROR:  conflicting key value violates exclusion constraint "foo"
+	//   DETAIL: Key (k)=(2) conflicts with existing key.
+	msg.WriteString("conflicting key value violates exclusion constraint ")
+	lexbase.EncodeEscapedSQLIdent(&msg, constraintName)
+
+	details.WriteString("Key (")
+	for i := 0; i < ec.ColumnCount(); i++ {
+		if i > 0 {
+			details.WriteString(", ")
+		}
+		col := tabMeta.Table.Column(ec.ColumnOrdinal(tabMeta.Table, i))
+		details.WriteString(string(col.ColName()))
+	}
+	details.WriteString(")=(")
+	for i, d := range keyVals {
+		if i > 0 {
+			details.WriteString(", ")
+		}
+		details.WriteString(d.String())
+	}
+
+	details.WriteString(") conflicts with existing key.")
+
+	return errors.WithDetail(
+		pgerror.WithConstraintName(
+			pgerror.Newf(pgcode.ExclusionViolation, "%s", msg.String()),
+			constraintName,
+		),
+		details.String(),
+	)
+}
+
 // mkFKCheckErr generates a user-friendly error describing a foreign key
 // violation. The keyVals are the values that correspond to the
 // cat.ForeignKeyConstraint columns.
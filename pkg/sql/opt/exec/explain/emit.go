@@ -253,6 +253,7 @@ var nodeNames = [...]string{
 	cancelSessionsOp:       "cancel sessions",
 	controlJobsOp:          "control jobs",
 	controlSchedulesOp:     "control schedules",
+	createFunctionOp:       "create function",
 	createStatisticsOp:     "create statistics",
 	createTableOp:          "create table",
 	createTableAsOp:        "create table as",
@@ -680,6 +681,7 @@ func (e *emitter) emitNodeAttributes(n *Node) error {
 		createTableOp,
 		createTableAsOp,
 		createViewOp,
+		createFunctionOp,
 		sequenceSelectOp,
 		saveTableOp,
 		errorIfRowsOp,
@@ -141,7 +141,10 @@ func getResultColumns(
 
 	case deleteOp:
 		a := args.(*deleteArgs)
-		return tableColumns(a.Table, a.ReturnCols), nil
+		return appendColumns(
+			tableColumns(a.Table, a.ReturnCols),
+			a.Passthrough...,
+		), nil
 
 	case opaqueOp:
 		return args.(*opaqueArgs).Metadata.Columns(), nil
@@ -173,8 +176,9 @@ func getResultColumns(
 		}
 		return colinfo.ShowTraceColumns, nil
 
-	case createTableOp, createTableAsOp, createViewOp, controlJobsOp, controlSchedulesOp,
-		cancelQueriesOp, cancelSessionsOp, createStatisticsOp, errorIfRowsOp, deleteRangeOp:
+	case createTableOp, createTableAsOp, createViewOp, createFunctionOp, controlJobsOp,
+		controlSchedulesOp, cancelQueriesOp, cancelSessionsOp, createStatisticsOp, errorIfRowsOp,
+		deleteRangeOp:
 		// These operations produce no columns.
 		return nil, nil
 
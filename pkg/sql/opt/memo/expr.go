@@ -913,8 +913,10 @@ type FKCascades []FKCascade
 
 // FKCascade stores metadata necessary for building a cascading query.
 // Cascading queries are built as needed, after the original query is executed.
+// AFTER triggers are also planned as cascades of the mutation that fires them.
 type FKCascade struct {
-	// FKName is the name of the FK constraint.
+	// FKName is the name of the FK constraint, or of the trigger for an AFTER
+	// trigger.
 	FKName string
 
 	// Builder is an object that can be used as the "optbuilder" for the cascading
@@ -207,7 +207,7 @@ func (f *ExprFmtCtx) formatRelational(e RelExpr, tp treeprinter.Node) {
 		*WindowExpr, *OpaqueRelExpr, *OpaqueMutationExpr, *OpaqueDDLExpr,
 		*AlterTableSplitExpr, *AlterTableUnsplitExpr, *AlterTableUnsplitAllExpr,
 		*AlterTableRelocateExpr, *ControlJobsExpr, *CancelQueriesExpr,
-		*CancelSessionsExpr, *CreateViewExpr, *ExportExpr:
+		*CancelSessionsExpr, *CreateViewExpr, *CreateFunctionExpr, *ExportExpr:
 		fmt.Fprintf(f.Buffer, "%v", e.Op())
 		FormatPrivate(f, e.Private(), required)
 
@@ -584,6 +584,9 @@ func (f *ExprFmtCtx) formatRelational(e RelExpr, tp treeprinter.Node) {
 	case *CreateTableExpr:
 		tp.Child(t.Syntax.String())
 
+	case *CreateFunctionExpr:
+		tp.Child(t.Syntax.Body)
+
 	case *CreateViewExpr:
 		tp.Child(t.ViewQuery)
 
@@ -1013,14 +1016,27 @@ func (f *ExprFmtCtx) formatScalarPrivate(scalar opt.ScalarExpr) {
 
 	case *UniqueChecksItem:
 		tab := f.Memo.metadata.TableMeta(t.Table)
-		constraint := tab.Table.Unique(t.CheckOrdinal)
 		fmt.Fprintf(f.Buffer, ": %s(", tab.Alias.ObjectName)
-		for i := 0; i < constraint.ColumnCount(); i++ {
-			if i > 0 {
-				f.Buffer.WriteByte(',')
+		if t.Exclusion {
+			// Print the exclusion constraint as:
+			//   t(a WITH =,b WITH &&)
+			constraint := tab.Table.Exclusion(t.CheckOrdinal)
+			for i := 0; i < constraint.ColumnCount(); i++ {
+				if i > 0 {
+					f.Buffer.WriteByte(',')
+				}
+				col := tab.Table.Column(constraint.ColumnOrdinal(tab.Table, i))
+				fmt.Fprintf(f.Buffer, "%s WITH %s", col.ColName(), constraint.Operator(i))
+			}
+		} else {
+			constraint := tab.Table.Unique(t.CheckOrdinal)
+			for i := 0; i < constraint.ColumnCount(); i++ {
+				if i > 0 {
+					f.Buffer.WriteByte(',')
+				}
+				col := tab.Table.Column(constraint.ColumnOrdinal(tab.Table, i))
+				f.Buffer.WriteString(string(col.ColName()))
 			}
-			col := tab.Table.Column(constraint.ColumnOrdinal(tab.Table, i))
-			f.Buffer.WriteString(string(col.ColName()))
 		}
 		f.Buffer.WriteByte(')')
 
@@ -1406,6 +1422,10 @@ func FormatPrivate(f *ExprFmtCtx, private interface{}, physProps *physical.Requi
 		schema := f.Memo.Metadata().Schema(t.Schema)
 		fmt.Fprintf(f.Buffer, " %s.%s", schema.Name(), t.ViewName)
 
+	case *CreateFunctionPrivate:
+		schema := f.Memo.Metadata().Schema(t.Schema)
+		fmt.Fprintf(f.Buffer, " %s.%s", schema.Name(), t.Syntax.Name.Object())
+
 	case *JoinPrivate:
 		// Nothing to show; flags are shown separately.
 
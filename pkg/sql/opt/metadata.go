@@ -107,6 +107,10 @@ type Metadata struct {
 	// we want to verify the resolution of both names.
 	deps []mdDep
 
+	// funcDeps stores information about all user-defined functions depended on
+	// by the query. Like deps, any name/function pair shows up at most once.
+	funcDeps []mdFuncDep
+
 	// views stores the list of referenced views. This information is only
 	// needed for EXPLAIN (opt, env).
 	views []cat.View
@@ -130,6 +134,17 @@ type mdDep struct {
 	privileges privilegeBitmap
 }
 
+type mdFuncDep struct {
+	fn cat.Function
+
+	// name is the name that was used in the query to resolve the function.
+	name tree.UnresolvedObjectName
+
+	// direct is true if the function is called by the query itself, rather
+	// than by the body of another function or view it references.
+	direct bool
+}
+
 // MDDepName stores either the unresolved DataSourceName or the StableID from
 // the query that was used to resolve a data source.
 type MDDepName struct {
@@ -175,6 +190,11 @@ func (md *Metadata) Init() {
 	}
 	md.deps = md.deps[:0]
 
+	for i := range md.funcDeps {
+		md.funcDeps[i] = mdFuncDep{}
+	}
+	md.funcDeps = md.funcDeps[:0]
+
 	for i := range md.views {
 		md.views[i] = nil
 	}
@@ -194,7 +214,7 @@ func (md *Metadata) Init() {
 // the copy.
 func (md *Metadata) CopyFrom(from *Metadata) {
 	if len(md.schemas) != 0 || len(md.cols) != 0 || len(md.tables) != 0 ||
-		len(md.sequences) != 0 || len(md.deps) != 0 || len(md.views) != 0 ||
+		len(md.sequences) != 0 || len(md.deps) != 0 || len(md.funcDeps) != 0 || len(md.views) != 0 ||
 		len(md.userDefinedTypes) != 0 || len(md.userDefinedTypesSlice) != 0 {
 		panic(errors.AssertionFailedf("CopyFrom requires empty destination"))
 	}
@@ -221,6 +241,7 @@ func (md *Metadata) CopyFrom(from *Metadata) {
 
 	md.sequences = append(md.sequences, from.sequences...)
 	md.deps = append(md.deps, from.deps...)
+	md.funcDeps = append(md.funcDeps, from.funcDeps...)
 	md.views = append(md.views, from.views...)
 	md.currUniqueID = from.currUniqueID
 
@@ -259,6 +280,24 @@ func (md *Metadata) AddDependency(name MDDepName, ds cat.DataSource, priv privil
 	})
 }
 
+// AddFunctionDependency tracks one of the user-defined functions on which the
+// query depends. If the Memo using this metadata is cached, then a call to
+// CheckDependencies can detect if the name resolves to a different function
+// now, if the function was replaced, or if the user lost the privilege to
+// execute it. direct is true if the function is called by the query itself,
+// rather than by the body of another function or view it references.
+func (md *Metadata) AddFunctionDependency(
+	name *tree.UnresolvedObjectName, fn cat.Function, direct bool,
+) {
+	for i := range md.funcDeps {
+		if md.funcDeps[i].fn == fn && md.funcDeps[i].name == *name {
+			md.funcDeps[i].direct = md.funcDeps[i].direct || direct
+			return
+		}
+	}
+	md.funcDeps = append(md.funcDeps, mdFuncDep{fn: fn, name: *name, direct: direct})
+}
+
 // CheckDependencies resolves (again) each data source on which this metadata
 // depends, in order to check that all data source names resolve to the same
 // objects, and that the user still has sufficient privileges to access the
@@ -307,6 +346,23 @@ func (md *Metadata) CheckDependencies(
 			privs &= ^(1 << priv)
 		}
 	}
+	// Check that all of the user-defined functions still resolve to the same
+	// version of the same function, and can still be executed.
+	for i := range md.funcDeps {
+		toCheck, _, err := catalog.ResolveFunction(ctx, &md.funcDeps[i].name)
+		if err != nil {
+			if pgerror.GetPGCode(err) == pgcode.UndefinedFunction {
+				return false, nil
+			}
+			return false, err
+		}
+		if !toCheck.Equals(md.funcDeps[i].fn) {
+			return false, nil
+		}
+		if err := catalog.CheckPrivilege(ctx, toCheck, privilege.EXECUTE); err != nil {
+			return false, err
+		}
+	}
 	// Check that all of the user defined types present have not changed.
 	for _, typ := range md.AllUserDefinedTypes() {
 		toCheck, err := catalog.ResolveTypeByOID(ctx, typ.Oid())
@@ -634,6 +690,30 @@ func (md *Metadata) AllViews() []cat.View {
 	return md.views
 }
 
+// DirectFunctions returns the user-defined functions called directly by the
+// query, deduplicated by ID. Functions only called by the body of another
+// function or view are not included.
+func (md *Metadata) DirectFunctions() []cat.Function {
+	var fns []cat.Function
+	for i := range md.funcDeps {
+		if !md.funcDeps[i].direct {
+			continue
+		}
+		fn := md.funcDeps[i].fn
+		seen := false
+		for j := range fns {
+			if fns[j].ID() == fn.ID() {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			fns = append(fns, fn)
+		}
+	}
+	return fns
+}
+
 // AllDataSourceNames returns the fully qualified names of all datasources
 // referenced by the metadata.
 func (md *Metadata) AllDataSourceNames(
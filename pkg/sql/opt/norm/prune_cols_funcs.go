@@ -141,6 +141,17 @@ func neededMutationFetchCols(
 		}
 	}
 
+	// Triggers can refer to the old values of all the columns of the table. The
+	// AFTER triggers are planned as cascades, which read the old values from
+	// the buffered mutation input.
+	if tabMeta.Table.TriggerCount() > 0 {
+		for ord, col := range private.FetchCols {
+			if col != 0 && tabMeta.Table.Column(ord).Kind() == cat.Ordinary {
+				cols.Add(tabMeta.MetaID.ColumnID(ord))
+			}
+		}
+	}
+
 	switch op {
 	case opt.UpdateOp, opt.UpsertOp:
 		// Determine set of target table columns that need to be updated.
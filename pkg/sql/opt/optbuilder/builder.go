@@ -127,6 +127,25 @@ type Builder struct {
 	// isCorrelated is set to true if we already reported to telemetry that the
 	// query contains a correlated subquery.
 	isCorrelated bool
+
+	// udfInlineCount is the number of calls to user-defined functions that have
+	// been inlined so far.
+	udfInlineCount int
+
+	// udfBodies contains the subqueries holding the bodies of the inlined calls
+	// to user-defined functions.
+	udfBodies map[*tree.Subquery]struct{}
+
+	// inIndirectScope is set while building the body of an inlined user-defined
+	// function or of a view, whose calls to user-defined functions are not
+	// direct dependencies of the statement.
+	inIndirectScope bool
+
+	// triggerRowScope is set while building the body of an AFTER trigger, and
+	// contains the new and old values of the rows that fired the trigger. It is
+	// consumed (and reset) when the input of the body is built. See
+	// afterTriggerBuilder.
+	triggerRowScope *scope
 }
 
 // New creates a new Builder structure initialized with the given
@@ -252,7 +271,7 @@ func (b *Builder) buildStmt(
 		// A blocklist of statements that can't be used from inside a view.
 		switch stmt := stmt.(type) {
 		case *tree.Delete, *tree.Insert, *tree.Update, *tree.CreateTable, *tree.CreateView,
-			*tree.Split, *tree.Unsplit, *tree.Relocate,
+			*tree.CreateFunction, *tree.Split, *tree.Unsplit, *tree.Relocate,
 			*tree.ControlJobs, *tree.ControlSchedules, *tree.CancelQueries, *tree.CancelSessions:
 			panic(pgerror.Newf(
 				pgcode.Syntax, "%s cannot be used inside a view definition", stmt.StatementTag(),
@@ -288,6 +307,9 @@ func (b *Builder) buildStmt(
 	case *tree.CreateView:
 		return b.buildCreateView(stmt, inScope)
 
+	case *tree.CreateFunction:
+		return b.buildCreateFunction(stmt, inScope)
+
 	case *tree.Explain:
 		return b.buildExplain(stmt, inScope)
 
@@ -0,0 +1,77 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+)
+
+func (b *Builder) buildCreateFunction(cf *tree.CreateFunction, inScope *scope) (outScope *scope) {
+	b.DisableMemoReuse = true
+	if cf.Language != "sql" {
+		panic(pgerror.Newf(pgcode.UndefinedObject, "language %q does not exist", cf.Language))
+	}
+	tn := cf.Name.ToTableName()
+	sch, _ := b.resolveSchemaForCreate(&tn)
+	schID := b.factory.Metadata().AddSchema(sch)
+
+	// Resolve the types of the arguments and of the return value; this also
+	// records the user-defined types among them as dependencies.
+	typeResolver := b.semaCtx.GetTypeResolver()
+	argCols := make(tree.NameList, len(cf.Args))
+	args := make(tree.Exprs, len(cf.Args))
+	for i := range cf.Args {
+		argCols[i] = udfArgColName(cf.Args[i].Name, i)
+		typ, err := tree.ResolveType(b.ctx, cf.Args[i].Type, typeResolver)
+		if err != nil {
+			panic(err)
+		}
+		args[i] = &tree.CastExpr{Expr: tree.DNull, Type: udfTypeReference(typ), SyntaxMode: tree.CastShort}
+	}
+	returnType, err := tree.ResolveType(b.ctx, cf.ReturnType, typeResolver)
+	if err != nil {
+		panic(err)
+	}
+
+	// We build the body the same way calls to the function are inlined, with
+	// NULL arguments, to check it semantically. The result is not otherwise
+	// used: the body is stored as written and its names are resolved when the
+	// function is called.
+	sub, _, err := makeUDFSubquery(tree.Name(cf.Name.Object()), argCols, cf.Body, args)
+	if err != nil {
+		panic(err)
+	}
+	b.pushWithFrame()
+	defScope := inScope.push()
+	body := defScope.resolveType(
+		defScope.replaceSubquery(sub, false /* wrapInTuple */, 1 /* desiredNumColumns */, noExtraColsAllowed),
+		types.Any,
+	)
+	b.popWithFrame(defScope)
+	if bodyType := body.ResolvedType(); bodyType.Family() != types.UnknownFamily &&
+		!bodyType.Equivalent(returnType) {
+		panic(pgerror.Newf(pgcode.InvalidFunctionDefinition,
+			"return type mismatch in function declared to return %s", returnType.SQLString()))
+	}
+
+	outScope = b.allocScope()
+	outScope.expr = b.factory.ConstructCreateFunction(
+		&memo.CreateFunctionPrivate{
+			Schema: schID,
+			Syntax: cf,
+		},
+	)
+	return outScope
+}
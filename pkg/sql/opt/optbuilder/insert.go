@@ -363,6 +363,11 @@ func (mb *mutationBuilder) needExistingRows() bool {
 		return true
 	}
 
+	// Triggers need to know whether each row was inserted or updated.
+	if mb.tab.TriggerCount() > 0 {
+		return true
+	}
+
 	// Key columns are never updated and are assumed to be the same as the insert
 	// values.
 	// TODO(andyk): This is not true in the case of composite key encodings. See
@@ -537,6 +542,16 @@ func (mb *mutationBuilder) addTargetTableColsForInsert(maxCols int) {
 // buildInputForInsert constructs the memo group for the input expression and
 // constructs a new output scope containing that expression's output columns.
 func (mb *mutationBuilder) buildInputForInsert(inScope *scope, inputRows *tree.Select) {
+	// If this is the body of an AFTER trigger, the input rows are built in the
+	// scope of the trigger row, and are produced once for each row that fired
+	// the trigger.
+	if rowScope := mb.b.triggerRowScope; rowScope != nil {
+		mb.b.triggerRowScope = nil
+		mb.buildInputForInsert(rowScope, inputRows)
+		mb.outScope = mb.b.joinTriggerRow(rowScope, inScope, mb.outScope)
+		return
+	}
+
 	// Handle DEFAULT VALUES case by creating a single empty row as input.
 	if inputRows == nil {
 		mb.outScope = inScope.push()
@@ -617,6 +632,9 @@ func (mb *mutationBuilder) addSynthesizedColsForInsert() {
 	// may depend on non-computed columns.
 	mb.addSynthesizedDefaultCols(mb.insertColIDs, true /* includeOrdinary */)
 
+	// Run the BEFORE INSERT triggers, which can modify the non-computed columns.
+	mb.buildBeforeTriggers(tree.TriggerInsert)
+
 	// Possibly round DECIMAL-related columns containing insertion values (whether
 	// synthesized or not).
 	mb.roundDecimalValues(mb.insertColIDs, false /* roundComputedCols */)
@@ -648,8 +666,12 @@ func (mb *mutationBuilder) buildInsert(returning tree.ReturningExprs) {
 
 	mb.buildUniqueChecksForInsert()
 
+	mb.buildExclusionChecksForInsert()
+
 	mb.buildFKChecksForInsert()
 
+	mb.buildAfterTriggers(tree.TriggerInsert)
+
 	private := mb.makeMutationPrivate(returning != nil)
 	mb.outScope.expr = mb.b.factory.ConstructInsert(
 		mb.outScope.expr, mb.uniqueChecks, mb.fkChecks, private,
@@ -1076,8 +1098,12 @@ func (mb *mutationBuilder) buildUpsert(returning tree.ReturningExprs) {
 
 	mb.buildUniqueChecksForUpsert()
 
+	mb.buildExclusionChecksForUpsert()
+
 	mb.buildFKChecksForUpsert()
 
+	mb.buildAfterTriggers(tree.TriggerInsert)
+
 	private := mb.makeMutationPrivate(returning != nil)
 	mb.outScope.expr = mb.b.factory.ConstructUpsert(
 		mb.outScope.expr, mb.uniqueChecks, mb.fkChecks, private,
@@ -154,7 +154,8 @@ type mutationBuilder struct {
 	// reuse.
 	parsedIndexExprs []tree.Expr
 
-	// uniqueChecks contains unique check queries; see buildUnique* methods.
+	// uniqueChecks contains unique and exclusion check queries; see
+	// buildUnique* and buildExclusion* methods.
 	uniqueChecks memo.UniqueChecksExpr
 
 	// fkChecks contains foreign key check queries; see buildFK* methods.
@@ -177,6 +178,9 @@ type mutationBuilder struct {
 
 	// uniqueCheckHelper is used to prevent allocating the helper separately.
 	uniqueCheckHelper uniqueCheckHelper
+
+	// exclusionCheckHelper is used to prevent allocating the helper separately.
+	exclusionCheckHelper exclusionCheckHelper
 }
 
 func (mb *mutationBuilder) init(b *Builder, opName string, tab cat.Table, alias tree.TableName) {
@@ -202,6 +206,11 @@ func (mb *mutationBuilder) init(b *Builder, opName string, tab cat.Table, alias
 
 	// Add the table and its columns (including mutation columns) to metadata.
 	mb.tabID = mb.md.AddTable(tab, &mb.alias)
+
+	// The body of a trigger depends on the tables it mutates.
+	if b.trackViewDeps {
+		b.viewDeps = append(b.viewDeps, opt.ViewDep{DataSource: tab})
+	}
 }
 
 // setFetchColIDs sets the list of columns that are fetched in order to provide
@@ -282,21 +291,7 @@ func (mb *mutationBuilder) buildInputForUpdate(
 	// together with the table being updated.
 	fromClausePresent := len(from) > 0
 	if fromClausePresent {
-		fromScope := mb.b.buildFromTables(from, noRowLocking, inScope)
-
-		// Check that the same table name is not used multiple times.
-		mb.b.validateJoinTableNames(mb.outScope, fromScope)
-
-		// The FROM table columns can be accessed by the RETURNING clause of the
-		// query and so we have to make them accessible.
-		mb.extraAccessibleCols = fromScope.cols
-
-		// Add the columns in the FROM scope.
-		mb.outScope.appendColumnsFromScope(fromScope)
-
-		left := mb.outScope.expr.(memo.RelExpr)
-		right := fromScope.expr.(memo.RelExpr)
-		mb.outScope.expr = mb.b.factory.ConstructInnerJoin(left, right, memo.TrueFilter, memo.EmptyJoinPrivate)
+		mb.joinFromTables(inScope, from)
 	}
 
 	// WHERE
@@ -319,25 +314,55 @@ func (mb *mutationBuilder) buildInputForUpdate(
 	// Build a distinct on to ensure there is at most one row in the joined output
 	// for every row in the table.
 	if fromClausePresent {
-		var pkCols opt.ColSet
-
-		// We need to ensure that the join has a maximum of one row for every row
-		// in the table and we ensure this by constructing a distinct on the primary
-		// key columns.
-		primaryIndex := mb.tab.Index(cat.PrimaryIndex)
-		for i := 0; i < primaryIndex.KeyColumnCount(); i++ {
-			// If the primary key column is hidden, then we don't need to use it
-			// for the distinct on.
-			if col := primaryIndex.Column(i); !col.IsHidden() {
-				pkCols.Add(mb.fetchColIDs[col.Ordinal()])
-			}
-		}
+		mb.buildDistinctOnPrimaryKey(false /* includeHidden */)
+	}
+}
+
+// joinFromTables builds the tables in the FROM clause of an UPDATE or the
+// USING clause of a DELETE, and joins them with the target table. The tables
+// cannot reference the target table.
+func (mb *mutationBuilder) joinFromTables(inScope *scope, from tree.TableExprs) {
+	fromScope := mb.b.buildFromTables(from, noRowLocking, inScope)
+
+	// Check that the same table name is not used multiple times.
+	mb.b.validateJoinTableNames(mb.outScope, fromScope)
+
+	// The FROM table columns can be accessed by the RETURNING clause of the
+	// query and so we have to make them accessible.
+	mb.extraAccessibleCols = fromScope.cols
+
+	// Add the columns in the FROM scope.
+	mb.outScope.appendColumnsFromScope(fromScope)
 
-		if !pkCols.Empty() {
-			mb.outScope = mb.b.buildDistinctOn(
-				pkCols, mb.outScope, false /* nullsAreDistinct */, "" /* errorOnDup */)
+	left := mb.outScope.expr.(memo.RelExpr)
+	right := fromScope.expr.(memo.RelExpr)
+	mb.outScope.expr = mb.b.factory.ConstructInnerJoin(left, right, memo.TrueFilter, memo.EmptyJoinPrivate)
+}
+
+// buildDistinctOnPrimaryKey builds a distinct on the primary key columns of
+// the target table, which ensures that there is at most one row in the output
+// of a join with the FROM or USING tables for every row in the table. The
+// values of the other columns are taken from an arbitrary joined row.
+//
+// Hidden primary key columns, such as rowid, are only included when
+// includeHidden is true. A DELETE must include them, since every deleted row
+// is returned and counted.
+func (mb *mutationBuilder) buildDistinctOnPrimaryKey(includeHidden bool) {
+	var pkCols opt.ColSet
+
+	primaryIndex := mb.tab.Index(cat.PrimaryIndex)
+	for i := 0; i < primaryIndex.KeyColumnCount(); i++ {
+		// If the primary key column is hidden, then we don't need to use it
+		// for the distinct on of an UPDATE.
+		if col := primaryIndex.Column(i); includeHidden || !col.IsHidden() {
+			pkCols.Add(mb.fetchColIDs[col.Ordinal()])
 		}
 	}
+
+	if !pkCols.Empty() {
+		mb.outScope = mb.b.buildDistinctOn(
+			pkCols, mb.outScope, false /* nullsAreDistinct */, "" /* errorOnDup */)
+	}
 }
 
 // buildInputForDelete constructs a Select expression from the fields in
@@ -350,9 +375,16 @@ func (mb *mutationBuilder) buildInputForUpdate(
 //   LIMIT <limit>
 //
 // All columns from the table to update are added to fetchColList.
+// If a USING clause is defined, the tables in it are joined with the target
+// table in the same way as the FROM tables of an UPDATE.
 // TODO(andyk): Do needed column analysis to project fewer columns if possible.
 func (mb *mutationBuilder) buildInputForDelete(
-	inScope *scope, texpr tree.TableExpr, where *tree.Where, limit *tree.Limit, orderBy tree.OrderBy,
+	inScope *scope,
+	texpr tree.TableExpr,
+	using tree.TableExprs,
+	where *tree.Where,
+	limit *tree.Limit,
+	orderBy tree.OrderBy,
 ) {
 	var indexFlags *tree.IndexFlags
 	if source, ok := texpr.(*tree.AliasedTableExpr); ok && source.IndexFlags != nil {
@@ -383,8 +415,24 @@ func (mb *mutationBuilder) buildInputForDelete(
 	)
 	mb.outScope = mb.fetchScope
 
+	// Set list of columns that will be fetched by the input expression.
+	mb.setFetchColIDs(mb.outScope.cols)
+
+	// If there is a USING clause present, we must join all the tables
+	// together with the table being deleted from.
+	usingClausePresent := len(using) > 0
+	if usingClausePresent {
+		mb.joinFromTables(inScope, using)
+	}
+
 	// WHERE
-	mb.b.buildWhere(where, mb.outScope)
+	if rowScope := mb.b.triggerRowScope; rowScope != nil {
+		// This is the body of an AFTER trigger.
+		mb.b.triggerRowScope = nil
+		mb.b.buildWhereForTriggerRow(where, rowScope, mb.outScope)
+	} else {
+		mb.b.buildWhere(where, mb.outScope)
+	}
 
 	// SELECT + ORDER BY (which may add projected expressions)
 	projectionsScope := mb.outScope.replace()
@@ -400,8 +448,11 @@ func (mb *mutationBuilder) buildInputForDelete(
 
 	mb.outScope = projectionsScope
 
-	// Set list of columns that will be fetched by the input expression.
-	mb.setFetchColIDs(mb.outScope.cols)
+	// Build a distinct on to ensure that every row of the table is deleted at
+	// most once.
+	if usingClausePresent {
+		mb.buildDistinctOnPrimaryKey(true /* includeHidden */)
+	}
 }
 
 // addTargetColsByName adds one target column for each of the names in the given
@@ -447,6 +498,12 @@ func (mb *mutationBuilder) addTargetCol(ord int) {
 	mb.targetColSet.Add(colID)
 
 	mb.targetColList = append(mb.targetColList, colID)
+
+	if mb.b.trackViewDeps {
+		dep := opt.ViewDep{DataSource: mb.tab}
+		dep.ColumnOrdinals.Add(ord)
+		mb.b.viewDeps = append(mb.b.viewDeps, dep)
+	}
 }
 
 // extractValuesInput tests whether the given input is a VALUES clause with no
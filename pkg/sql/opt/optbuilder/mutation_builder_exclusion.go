@@ -0,0 +1,248 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+// buildExclusionChecksForInsert builds exclusion check queries for an insert.
+// These check queries are used to enforce EXCLUDE constraints.
+func (mb *mutationBuilder) buildExclusionChecksForInsert() {
+	if mb.tab.ExclusionCount() == 0 {
+		return
+	}
+
+	mb.ensureWithID()
+	h := &mb.exclusionCheckHelper
+
+	for i, n := 0, mb.tab.ExclusionCount(); i < n; i++ {
+		if h.init(mb, i) {
+			mb.uniqueChecks = append(mb.uniqueChecks, h.buildInsertionCheck())
+		}
+	}
+}
+
+// buildExclusionChecksForUpdate builds exclusion check queries for an update.
+// These check queries are used to enforce EXCLUDE constraints.
+func (mb *mutationBuilder) buildExclusionChecksForUpdate() {
+	if mb.tab.ExclusionCount() == 0 {
+		return
+	}
+
+	mb.ensureWithID()
+	h := &mb.exclusionCheckHelper
+
+	for i, n := 0, mb.tab.ExclusionCount(); i < n; i++ {
+		// If the constraint doesn't include the updated columns we don't need to
+		// plan a check.
+		if mb.exclusionColsUpdated(i) && h.init(mb, i) {
+			// The insertion check works for updates too since it simply checks that
+			// the newly inserted or updated rows do not conflict with any existing
+			// rows. The check prevents rows from conflicting with themselves by
+			// adding a filter based on the primary key.
+			mb.uniqueChecks = append(mb.uniqueChecks, h.buildInsertionCheck())
+		}
+	}
+}
+
+// buildExclusionChecksForUpsert builds exclusion check queries for an upsert.
+// These check queries are used to enforce EXCLUDE constraints.
+func (mb *mutationBuilder) buildExclusionChecksForUpsert() {
+	if mb.tab.ExclusionCount() == 0 {
+		return
+	}
+
+	mb.ensureWithID()
+	h := &mb.exclusionCheckHelper
+
+	for i, n := 0, mb.tab.ExclusionCount(); i < n; i++ {
+		// The insertion check works for upserts too; see
+		// buildExclusionChecksForUpdate.
+		if h.init(mb, i) {
+			mb.uniqueChecks = append(mb.uniqueChecks, h.buildInsertionCheck())
+		}
+	}
+}
+
+// exclusionColsUpdated returns true if any of the columns for an exclusion
+// constraint are being updated (according to updateColIDs).
+func (mb *mutationBuilder) exclusionColsUpdated(exclusionOrdinal int) bool {
+	ec := mb.tab.Exclusion(exclusionOrdinal)
+	for i, n := 0, ec.ColumnCount(); i < n; i++ {
+		if ord := ec.ColumnOrdinal(mb.tab, i); mb.updateColIDs[ord] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// exclusionCheckHelper is a type associated with a single exclusion constraint
+// and is used to build the "leaves" of an exclusion check expression, namely
+// the WithScan of the mutation input and the Scan of the table.
+type exclusionCheckHelper struct {
+	mb *mutationBuilder
+
+	exclusion        cat.ExclusionConstraint
+	exclusionOrdinal int
+
+	// tabOrdinals are the table ordinals of the columns that are scanned by the
+	// check. It contains the columns of the exclusion constraint followed by
+	// any primary key columns that are not part of the constraint.
+	tabOrdinals []int
+
+	// exclusionCols are the positions in tabOrdinals of the columns of the
+	// exclusion constraint. They correspond 1-to-1 to the columns in the
+	// ExclusionConstraint.
+	exclusionCols []int
+
+	// primaryKeyCols are the positions in tabOrdinals of the primary key
+	// columns.
+	primaryKeyCols []int
+}
+
+// init initializes the helper with an exclusion constraint.
+//
+// Returns false if the constraint should be ignored (e.g. because the new
+// values for one of the columns are known to be always NULL, in which case the
+// operators never return true).
+func (h *exclusionCheckHelper) init(mb *mutationBuilder, exclusionOrdinal int) bool {
+	*h = exclusionCheckHelper{
+		mb:               mb,
+		exclusion:        mb.tab.Exclusion(exclusionOrdinal),
+		exclusionOrdinal: exclusionOrdinal,
+	}
+
+	colCount := h.exclusion.ColumnCount()
+	h.exclusionCols = make([]int, colCount)
+	for i := 0; i < colCount; i++ {
+		h.exclusionCols[i] = h.addOrdinal(h.exclusion.ColumnOrdinal(mb.tab, i))
+	}
+	primaryIndex := mb.tab.Index(cat.PrimaryIndex)
+	for i, n := 0, primaryIndex.LaxKeyColumnCount(); i < n; i++ {
+		h.primaryKeyCols = append(h.primaryKeyCols, h.addOrdinal(primaryIndex.Column(i).Ordinal()))
+	}
+
+	for i := 0; i < colCount; i++ {
+		colID := mb.mapToReturnColID(h.tabOrdinals[h.exclusionCols[i]])
+		if memo.OutputColumnIsAlwaysNull(mb.outScope.expr, colID) {
+			return false
+		}
+	}
+	return true
+}
+
+// addOrdinal adds the given table ordinal to tabOrdinals if it is not already
+// present, and returns its position in tabOrdinals.
+func (h *exclusionCheckHelper) addOrdinal(tabOrd int) int {
+	for i, ord := range h.tabOrdinals {
+		if ord == tabOrd {
+			return i
+		}
+	}
+	h.tabOrdinals = append(h.tabOrdinals, tabOrd)
+	return len(h.tabOrdinals) - 1
+}
+
+// buildInsertionCheck creates an exclusion check for rows which are added to a
+// table. The input to the insertion check will be produced from the input to
+// the mutation operator.
+func (h *exclusionCheckHelper) buildInsertionCheck() memo.UniqueChecksItem {
+	checkInput, withScanCols, _ := h.mb.makeCheckInputScan(checkInputScanNewVals, h.tabOrdinals)
+
+	f := h.mb.b.factory
+
+	// Build a self semi-join, with the new values on the left and the
+	// existing values on the right.
+	tabMeta := h.mb.b.addTable(h.mb.tab, tree.NewUnqualifiedTableName(h.mb.tab.Name()))
+	scanScope := h.mb.b.buildScan(
+		tabMeta,
+		h.tabOrdinals,
+		nil, /* indexFlags */
+		noRowLocking,
+		h.mb.b.allocScope(),
+	)
+
+	// Build the join filters:
+	//   (new_a = existing_a) AND (new_b && existing_b) AND ...
+	//
+	// The capacity is one more than the number of columns in the constraint
+	// since we add one additional condition to prevent rows from conflicting
+	// with themselves (see below).
+	semiJoinFilters := make(memo.FiltersExpr, 0, len(h.exclusionCols)+1)
+	keyCols := make(opt.ColList, len(h.exclusionCols))
+	for i, pos := range h.exclusionCols {
+		newVal := f.ConstructVariable(withScanCols[pos])
+		existingVal := f.ConstructVariable(scanScope.cols[pos].id)
+		semiJoinFilters = append(semiJoinFilters, f.ConstructFiltersItem(
+			h.constructComparison(h.exclusion.Operator(i), newVal, existingVal, scanScope.cols[pos].typ),
+		))
+		keyCols[i] = withScanCols[pos]
+	}
+
+	// We need to prevent rows from conflicting with themselves in the semi
+	// join. We can do this by adding another filter that uses the primary keys
+	// to check if two rows are identical:
+	//    (new_pk1 != existing_pk1) OR (new_pk2 != existing_pk2) OR ...
+	var pkFilter opt.ScalarExpr
+	for _, pos := range h.primaryKeyCols {
+		pkFilterLocal := f.ConstructNe(
+			f.ConstructVariable(withScanCols[pos]),
+			f.ConstructVariable(scanScope.cols[pos].id),
+		)
+		if pkFilter == nil {
+			pkFilter = pkFilterLocal
+		} else {
+			pkFilter = f.ConstructOr(pkFilter, pkFilterLocal)
+		}
+	}
+	semiJoinFilters = append(semiJoinFilters, f.ConstructFiltersItem(pkFilter))
+
+	semiJoin := f.ConstructSemiJoin(checkInput, scanScope.expr, semiJoinFilters, &memo.JoinPrivate{})
+
+	return f.ConstructUniqueChecksItem(semiJoin, &memo.UniqueChecksItemPrivate{
+		Table:        h.mb.tabID,
+		CheckOrdinal: h.exclusionOrdinal,
+		Exclusion:    true,
+		KeyCols:      keyCols,
+		OpName:       h.mb.opName,
+	})
+}
+
+// constructComparison builds the scalar expression that compares a column of
+// a new row with the same column of an existing row using the given operator
+// of the exclusion constraint.
+func (h *exclusionCheckHelper) constructComparison(
+	op tree.ComparisonOperator, newVal, existingVal opt.ScalarExpr, typ *types.T,
+) opt.ScalarExpr {
+	f := h.mb.b.factory
+	switch op {
+	case tree.EQ:
+		return f.ConstructEq(newVal, existingVal)
+	case tree.NE:
+		return f.ConstructNe(newVal, existingVal)
+	case tree.Overlaps:
+		if typ.Family() == types.GeometryFamily || typ.Family() == types.Box2DFamily {
+			// The && operator means "intersects" when used with geometry or
+			// bounding box operands. Building a BBoxIntersects expression allows
+			// the check to use an inverted index on the column.
+			return f.ConstructBBoxIntersects(newVal, existingVal)
+		}
+		return f.ConstructOverlaps(newVal, existingVal)
+	}
+	panic(errors.AssertionFailedf("unhandled exclusion operator: %s", log.Safe(op)))
+}
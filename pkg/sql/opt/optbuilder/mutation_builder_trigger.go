@@ -0,0 +1,270 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/schemaexpr"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/errors"
+)
+
+// This file contains methods that build the row-level triggers of the target
+// table of a mutation.
+//
+// BEFORE triggers are built as part of the input of the mutation. The body of
+// a BEFORE trigger is a SELECT statement that can refer to the new and old
+// values of each row through the "new" and "old" data sources (see
+// Builder.buildTriggerRow), and it is joined with the mutation input using an
+// apply join. For INSERT and UPDATE, the output columns of the body replace
+// the new values of the table columns with the same name, and the row is
+// skipped if the body returns no rows; only the first row returned by the body
+// is used. For example, with the trigger:
+//
+//   CREATE TRIGGER t BEFORE INSERT ON ab FOR EACH ROW
+//     AS 'SELECT new.b * 2 AS b WHERE new.a > 0'
+//
+// the statement INSERT INTO ab VALUES (1, 10) is built like:
+//
+//   insert ab
+//    ├── insert-mapping:
+//    │    ├── column1:4 => ab.a:1
+//    │    └── b:8 => ab.b:2
+//    └── inner-join-apply
+//         ├── project
+//         │    ├── values
+//         │    │    └── (1, 10)
+//         │    └── projections
+//         │         ├── CAST(NULL AS INT8) [as=a:6]
+//         │         └── CAST(NULL AS INT8) [as=b:7]
+//         ├── limit
+//         │    ├── project
+//         │    │    ├── select
+//         │    │    │    ├── values
+//         │    │    │    │    └── ()
+//         │    │    │    └── filters
+//         │    │    │         └── column1:4 > 0
+//         │    │    └── projections
+//         │    │         └── column2:5 * 2 [as=b:8]
+//         │    └── 1
+//         └── filters (true)
+//
+// where a:6 and b:7 are the (NULL) columns of the "old" data source.
+//
+// For DELETE, the row is deleted only if the body returns at least one row,
+// which is built as a semi-join.
+//
+// AFTER triggers are planned as cascades of the mutation, since they run after
+// the rows have been written. See afterTriggerBuilder.
+
+// buildBeforeTriggers builds the BEFORE triggers of the target table that fire
+// for the given event, in name order. For INSERT, it is called after the
+// default values have been synthesized but before computed columns are, so
+// that the triggers can assign columns that computed columns depend on.
+func (mb *mutationBuilder) buildBeforeTriggers(event tree.TriggerEvent) {
+	for i, n := 0, mb.tab.TriggerCount(); i < n; i++ {
+		t := mb.tab.Trigger(i)
+		if !t.Fires(tree.TriggerBefore, event) {
+			continue
+		}
+		if event == tree.TriggerUpdate && mb.canaryColID != 0 {
+			panic(unimplemented.NewWithIssue(28296,
+				"BEFORE UPDATE triggers are not supported with UPSERT and INSERT ... ON CONFLICT DO UPDATE",
+			))
+		}
+		telemetry.Inc(sqltelemetry.TriggerUseCounter)
+		sel, err := triggerBodySelect(parseTriggerBody(&t))
+		if err != nil {
+			panic(err)
+		}
+		limitToFirstRow(sel)
+
+		var newColIDs, oldColIDs opt.OptionalColList
+		switch event {
+		case tree.TriggerInsert:
+			newColIDs = mb.insertColIDs
+		case tree.TriggerUpdate:
+			newColIDs = make(opt.OptionalColList, len(mb.updateColIDs))
+			for ord := range newColIDs {
+				newColIDs[ord] = mb.updateColIDs[ord]
+				if newColIDs[ord] == 0 {
+					newColIDs[ord] = mb.fetchColIDs[ord]
+				}
+			}
+			oldColIDs = mb.fetchColIDs
+		case tree.TriggerDelete:
+			oldColIDs = mb.fetchColIDs
+		}
+
+		rowScope := mb.b.buildTriggerRow(mb.tab, mb.outScope, newColIDs, oldColIDs)
+		bodyScope := mb.b.buildStmt(sel, nil /* desiredTypes */, rowScope)
+		left := rowScope.expr.(memo.RelExpr)
+		right := bodyScope.expr.(memo.RelExpr)
+		if event == tree.TriggerDelete {
+			mb.outScope.expr = mb.b.factory.ConstructSemiJoinApply(
+				left, right, memo.TrueFilter, memo.EmptyJoinPrivate,
+			)
+			continue
+		}
+		mb.outScope.expr = mb.b.factory.ConstructInnerJoinApply(
+			left, right, memo.TrueFilter, memo.EmptyJoinPrivate,
+		)
+
+		// Replace the new values of the columns returned by the body.
+		ords := resolveBeforeTriggerCols(mb.tab, &t, bodyScope)
+		for j, ord := range ords {
+			bodyCol := &bodyScope.cols[j]
+			tabCol := mb.tab.Column(ord)
+			switch event {
+			case tree.TriggerInsert:
+				mb.insertColIDs[ord] = bodyCol.id
+			case tree.TriggerUpdate:
+				if mb.updateColIDs[ord] == 0 {
+					mb.addTargetCol(ord)
+				}
+				mb.updateColIDs[ord] = bodyCol.id
+			}
+			mb.outScope.cols = append(mb.outScope.cols, scopeColumn{
+				name: tabCol.ColName(),
+				typ:  bodyCol.typ,
+				id:   bodyCol.id,
+			})
+		}
+
+		// Make sure that the table column names refer to the new values.
+		mb.disambiguateColumns()
+	}
+}
+
+// resolveBeforeTriggerCols returns the ordinals of the table columns that are
+// assigned by the output columns of the body of the given BEFORE trigger,
+// which is built in bodyScope. It panics if an output column does not match a
+// public column of the table that can be written.
+func resolveBeforeTriggerCols(tab cat.Table, t *cat.Trigger, bodyScope *scope) []int {
+	tabName := tab.Name()
+	ords := make([]int, len(bodyScope.cols))
+	var assigned util.FastIntSet
+	for j := range bodyScope.cols {
+		bodyCol := &bodyScope.cols[j]
+		ord := findPublicTableColumnByName(tab, bodyCol.name)
+		if ord == -1 {
+			panic(errors.Wrapf(
+				pgerror.Newf(pgcode.UndefinedColumn,
+					"column %q of table %q does not exist",
+					tree.ErrString(&bodyCol.name), tree.ErrString(&tabName),
+				),
+				"trigger %s", tree.ErrString(&t.Name),
+			))
+		}
+		tabCol := tab.Column(ord)
+		if tabCol.Kind() == cat.System {
+			panic(pgerror.Newf(pgcode.InvalidColumnReference,
+				"cannot modify system column %q", tabCol.ColName()))
+		}
+		if tabCol.IsComputed() {
+			panic(schemaexpr.CannotWriteToComputedColError(string(tabCol.ColName())))
+		}
+		if assigned.Contains(ord) {
+			panic(pgerror.Newf(pgcode.Syntax,
+				"multiple assignments to the same column %q", tabCol.ColName()))
+		}
+		assigned.Add(ord)
+		checkDatumTypeFitsColumnType(tabCol, bodyCol.typ)
+		ords[j] = ord
+	}
+	return ords
+}
+
+// buildAfterTriggers plans the AFTER triggers of the target table that fire
+// for the given event as cascades of the mutation. The new and old values of
+// each row are passed to the cascades in the NewValues and OldValues columns,
+// which correspond to the columns of the table that have a value in the
+// mutation input. See afterTriggerBuilder.
+//
+// For an upsert, the event is INSERT, and the triggers that fire for UPDATE
+// events are planned as well. Each trigger only runs for the rows that were
+// inserted or updated, depending on its events. The NewValues columns hold the
+// insert values followed by the update values, and the trigger uses the canary
+// column to choose between them.
+//
+// Must be called after all the columns of the mutation input have been built.
+func (mb *mutationBuilder) buildAfterTriggers(event tree.TriggerEvent) {
+	isUpsert := mb.canaryColID != 0
+	for i, n := 0, mb.tab.TriggerCount(); i < n; i++ {
+		t := mb.tab.Trigger(i)
+		fires := t.Fires(tree.TriggerAfter, event)
+		if isUpsert {
+			fires = fires || t.Fires(tree.TriggerAfter, tree.TriggerUpdate)
+		}
+		if !fires {
+			continue
+		}
+		telemetry.Inc(sqltelemetry.TriggerUseCounter)
+		mb.ensureWithID()
+
+		builder := &afterTriggerBuilder{tab: mb.tab, trigger: t, canaryIdx: -1}
+		var newValues, oldValues, updateValues opt.ColList
+		for ord, n := 0, mb.tab.ColumnCount(); ord < n; ord++ {
+			if mb.tab.Column(ord).Kind() != cat.Ordinary {
+				continue
+			}
+			if isUpsert {
+				// The upsert columns are only buffered if they are returned, so the
+				// insert and update values are passed separately, like for the
+				// cascades of an upsert (see buildFKChecksForUpsert).
+				if colID := mb.insertColIDs[ord]; colID != 0 {
+					updateColID := mb.updateColIDs[ord]
+					if updateColID == 0 {
+						updateColID = mb.fetchColIDs[ord]
+					}
+					if updateColID == 0 {
+						updateColID = colID
+					}
+					builder.newOrds = append(builder.newOrds, ord)
+					newValues = append(newValues, colID)
+					updateValues = append(updateValues, updateColID)
+				}
+			} else if event != tree.TriggerDelete {
+				if colID := mb.mapToReturnColID(ord); colID != 0 {
+					builder.newOrds = append(builder.newOrds, ord)
+					newValues = append(newValues, colID)
+				}
+			}
+			if colID := mb.fetchColIDs[ord]; colID != 0 {
+				if colID == mb.canaryColID {
+					builder.canaryIdx = len(oldValues)
+				}
+				builder.oldOrds = append(builder.oldOrds, ord)
+				oldValues = append(oldValues, colID)
+			}
+		}
+		if isUpsert && builder.canaryIdx == -1 {
+			panic(errors.AssertionFailedf("canary column not found"))
+		}
+		newValues = append(newValues, updateValues...)
+
+		mb.cascades = append(mb.cascades, memo.FKCascade{
+			FKName:    string(t.Name),
+			Builder:   builder,
+			WithID:    mb.withID,
+			OldValues: oldValues,
+			NewValues: newValues,
+		})
+	}
+}
@@ -932,6 +932,13 @@ func (s *scope) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
 	case *tree.FuncExpr:
 		def, err := t.Func.Resolve(s.builder.semaCtx.SearchPath)
 		if err != nil {
+			// Builtin functions take precedence over user-defined functions.
+			if pgerror.GetPGCode(err) == pgcode.UndefinedFunction {
+				if udf, ok := s.replaceUDF(t); ok {
+					expr = udf
+					break
+				}
+			}
 			panic(err)
 		}
 
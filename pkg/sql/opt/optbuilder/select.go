@@ -279,6 +279,10 @@ func (b *Builder) buildView(
 		b.skipSelectPrivilegeChecks = true
 		defer func() { b.skipSelectPrivilegeChecks = false }()
 	}
+	if !b.inIndirectScope {
+		b.inIndirectScope = true
+		defer func() { b.inIndirectScope = false }()
+	}
 	trackDeps := b.trackViewDeps
 	if trackDeps {
 		// We are only interested in the direct dependency on this view descriptor.
@@ -214,6 +214,13 @@ func (s *subquery) buildSubquery(desiredTypes []*types.T) {
 	defer func() { s.scope.builder.subquery = outer }()
 	s.scope.builder.subquery = s
 
+	// The calls made by the body of an inlined function are not direct
+	// dependencies of the statement.
+	if _, ok := s.scope.builder.udfBodies[s.Subquery]; ok && !s.scope.builder.inIndirectScope {
+		s.scope.builder.inIndirectScope = true
+		defer func() { s.scope.builder.inIndirectScope = false }()
+	}
+
 	// We must push() here so that the columns in s.scope are correctly identified
 	// as outer columns.
 	outScope := s.scope.builder.buildStmt(s.Subquery.Select, desiredTypes, s.scope.push())
@@ -0,0 +1,443 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/norm"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/props"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+// triggerNewName and triggerOldName are the names of the data sources through
+// which the body of a trigger refers to the new and old values of the row that
+// fired it.
+var (
+	triggerNewName = tree.MakeUnqualifiedTableName("new")
+	triggerOldName = tree.MakeUnqualifiedTableName("old")
+)
+
+// ValidateTriggerBody returns an error if the given statement cannot be the
+// body of a trigger that runs at the given time:
+//
+//   - the body of a BEFORE trigger must be a SELECT statement. Its output
+//     columns replace the columns of the new row that have the same name, and
+//     the row is skipped if it returns no rows.
+//   - the body of an AFTER trigger must be an INSERT, UPSERT or DELETE
+//     statement without a WITH, ORDER BY, LIMIT or RETURNING clause. UPDATE
+//     is not supported, because the rows it updates cannot be correlated with
+//     each row that fired the trigger; UPSERT can be used instead.
+//
+func ValidateTriggerBody(actionTime tree.TriggerActionTime, stmt tree.Statement) error {
+	if actionTime == tree.TriggerBefore {
+		_, err := triggerBodySelect(stmt)
+		return err
+	}
+	switch t := stmt.(type) {
+	case *tree.Insert:
+		if t.With != nil {
+			return unimplementedTriggerBodyClause("WITH")
+		}
+		if resultsNeeded(t.Returning) {
+			return unimplementedTriggerBodyClause("RETURNING")
+		}
+		return nil
+	case *tree.Delete:
+		if t.With != nil {
+			return unimplementedTriggerBodyClause("WITH")
+		}
+		if t.OrderBy != nil || t.Limit != nil {
+			return unimplementedTriggerBodyClause("ORDER BY and LIMIT")
+		}
+		if resultsNeeded(t.Returning) {
+			return unimplementedTriggerBodyClause("RETURNING")
+		}
+		return nil
+	case *tree.Update:
+		return pgerror.New(pgcode.FeatureNotSupported,
+			"UPDATE is not supported in the body of an AFTER trigger; use UPSERT instead")
+	default:
+		return pgerror.Newf(pgcode.InvalidObjectDefinition,
+			"the body of an AFTER trigger must be an INSERT, UPSERT or DELETE statement, found %s",
+			stmt.StatementTag())
+	}
+}
+
+func unimplementedTriggerBodyClause(clause string) error {
+	return pgerror.Newf(pgcode.FeatureNotSupported,
+		"%s is not supported in the body of a trigger", clause)
+}
+
+// triggerBodySelect returns the given statement as a SELECT statement, or an
+// error if it is not one.
+func triggerBodySelect(stmt tree.Statement) (*tree.Select, error) {
+	switch t := stmt.(type) {
+	case *tree.Select:
+		return t, nil
+	case *tree.ParenSelect:
+		return &tree.Select{Select: t}, nil
+	default:
+		return nil, pgerror.Newf(pgcode.InvalidObjectDefinition,
+			"the body of a BEFORE trigger must be a SELECT statement, found %s", stmt.StatementTag())
+	}
+}
+
+// parseTriggerBody parses and validates the body of the given trigger.
+func parseTriggerBody(t *cat.Trigger) tree.Statement {
+	stmt, err := parser.ParseOne(t.Body)
+	if err == nil {
+		err = ValidateTriggerBody(t.ActionTime, stmt.AST)
+	}
+	if err != nil {
+		panic(errors.Wrapf(err, "trigger %s", tree.ErrString(&t.Name)))
+	}
+	return stmt.AST
+}
+
+// BuildTriggerBody builds the body of the given trigger on the given table, with
+// NULL values for the new and old rows. It is used when the trigger is created,
+// so that errors in the body that depend on the schema (e.g. unknown tables or
+// columns) are reported then rather than when the trigger fires.
+//
+// It returns the dependencies of the body, in the same form as the
+// dependencies of a view. References to the new and old rows are dependencies
+// on the columns of the given table.
+func BuildTriggerBody(
+	ctx context.Context,
+	semaCtx *tree.SemaContext,
+	evalCtx *tree.EvalContext,
+	catalog cat.Catalog,
+	factory *norm.Factory,
+	tab cat.Table,
+	trigger *cat.Trigger,
+) (opt.ViewDeps, error) {
+	var deps opt.ViewDeps
+	_, err := buildCascadeHelper(ctx, semaCtx, evalCtx, catalog, factory, func(b *Builder) memo.RelExpr {
+		b.trackViewDeps = true
+		defer func() {
+			deps = b.viewDeps
+			b.trackViewDeps = false
+			b.viewDeps = nil
+		}()
+		stmt := parseTriggerBody(trigger)
+		inScope := b.allocScope()
+		inScope.expr = b.factory.ConstructValues(memo.ScalarListWithEmptyTuple, &memo.ValuesPrivate{
+			Cols: opt.ColList{},
+			ID:   b.factory.Metadata().NextUniqueID(),
+		})
+		rowScope := b.buildTriggerRow(tab, inScope, nil /* newColIDs */, nil /* oldColIDs */)
+
+		if trigger.ActionTime == tree.TriggerBefore {
+			sel, err := triggerBodySelect(stmt)
+			if err != nil {
+				panic(err)
+			}
+			bodyScope := b.buildStmt(sel, nil /* desiredTypes */, rowScope)
+			// The output columns of the body are only used by INSERT and UPDATE
+			// events.
+			if trigger.Events.Contains(tree.TriggerInsert) || trigger.Events.Contains(tree.TriggerUpdate) {
+				resolveBeforeTriggerCols(tab, trigger, bodyScope)
+			}
+			return bodyScope.expr
+		}
+
+		b.triggerRowScope = rowScope
+		outScope := b.buildStmt(stmt, nil /* desiredTypes */, b.allocScope())
+		if b.triggerRowScope != nil {
+			panic(errors.AssertionFailedf("trigger row was not used"))
+		}
+		return outScope.expr
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deps, nil
+}
+
+// buildTriggerRow returns a scope through which the body of a trigger on the
+// given table can refer to the new and old values of the row that fired it, as
+// the columns of the "new" and "old" data sources. newColIDs and oldColIDs map
+// table column ordinals to the columns of inScope that hold these values; a
+// column that is zero in either list (or a nil list) is NULL in the
+// corresponding data source.
+//
+// The returned scope has no parent, so that the body cannot refer to any other
+// column of the statement that fired the trigger. Its expression is the
+// expression of inScope, possibly wrapped in a Project that synthesizes the
+// NULL columns. Expressions built in the returned scope (or its descendants)
+// are correlated with that expression, and are meant to be joined with it using
+// an apply join.
+func (b *Builder) buildTriggerRow(
+	tab cat.Table, inScope *scope, newColIDs, oldColIDs opt.OptionalColList,
+) *scope {
+	projectionsScope := inScope.replace()
+	projectionsScope.appendColumnsFromScope(inScope)
+
+	rowScope := b.allocScope()
+	rowScope.context = exprKindLateralJoin
+	var dep opt.ViewDep
+	if b.trackViewDeps {
+		dep = opt.ViewDep{DataSource: tab, ColumnIDToOrd: make(map[opt.ColumnID]int)}
+	}
+	addCols := func(tn tree.TableName, colIDs opt.OptionalColList) {
+		for i, n := 0, tab.ColumnCount(); i < n; i++ {
+			col := tab.Column(i)
+			if col.Kind() != cat.Ordinary {
+				continue
+			}
+			var colID opt.ColumnID
+			if colIDs != nil {
+				colID = colIDs[i]
+			}
+			if colID == 0 {
+				colID = b.synthesizeColumn(
+					projectionsScope, string(col.ColName()), col.DatumType(),
+					nil /* expr */, b.factory.ConstructNull(col.DatumType()),
+				).id
+			}
+			rowScope.cols = append(rowScope.cols, scopeColumn{
+				name:   col.ColName(),
+				table:  tn,
+				typ:    col.DatumType(),
+				id:     colID,
+				hidden: col.IsHidden(),
+			})
+			if b.trackViewDeps {
+				dep.ColumnIDToOrd[colID] = i
+			}
+		}
+	}
+	addCols(triggerNewName, newColIDs)
+	addCols(triggerOldName, oldColIDs)
+	if b.trackViewDeps {
+		// The columns of the new and old rows which are referenced by the body
+		// are added to the dependency on the table as they are resolved.
+		b.viewDeps = append(b.viewDeps, dep)
+	}
+
+	if len(projectionsScope.cols) > len(inScope.cols) {
+		b.constructProjectForScope(inScope, projectionsScope)
+		rowScope.expr = projectionsScope.expr
+	} else {
+		rowScope.expr = inScope.expr
+	}
+	return rowScope
+}
+
+// joinTriggerRow joins the expression of the given scope, which has been built
+// in the scope of the trigger row of an AFTER trigger, with the rows that fired
+// the trigger using an apply join, so that it is evaluated once per row. It
+// returns a scope with the same columns whose parent is inScope, so that the
+// trigger row is not visible to the rest of the statement. See
+// afterTriggerBuilder.
+func (b *Builder) joinTriggerRow(rowScope, inScope, s *scope) *scope {
+	outScope := inScope.push()
+	outScope.cols = s.cols
+	outScope.expr = b.factory.ConstructInnerJoinApply(
+		rowScope.expr.(memo.RelExpr), s.expr.(memo.RelExpr), memo.TrueFilter, memo.EmptyJoinPrivate,
+	)
+	return outScope
+}
+
+// buildWhereForTriggerRow is similar to buildWhere, but is used for the WHERE
+// clause of a DELETE statement that is the body of an AFTER trigger. The WHERE
+// clause can refer to the trigger row in rowScope, and the expression of
+// inScope is wrapped in a semi-join with the rows that fired the trigger, using
+// the WHERE clause as the join condition.
+func (b *Builder) buildWhereForTriggerRow(where *tree.Where, rowScope, inScope *scope) {
+	// The columns of inScope take precedence over the columns of the trigger
+	// row with the same name.
+	filterScope := rowScope.push()
+	filterScope.appendColumnsFromScope(inScope)
+
+	on := memo.TrueFilter
+	if where != nil {
+		filter := b.resolveAndBuildScalar(
+			where.Expr,
+			types.Bool,
+			exprKindWhere,
+			tree.RejectGenerators|tree.RejectWindowApplications,
+			filterScope,
+		)
+		on = memo.FiltersExpr{b.factory.ConstructFiltersItem(filter)}
+	}
+	inScope.expr = b.factory.ConstructSemiJoin(
+		inScope.expr.(memo.RelExpr), rowScope.expr.(memo.RelExpr), on, memo.EmptyJoinPrivate,
+	)
+}
+
+// afterTriggerBuilder is a memo.CascadeBuilder implementation for AFTER
+// triggers. Like a cascade, the body of an AFTER trigger runs after the
+// mutation that fired it, and its input is the buffered input of that
+// mutation.
+//
+// The body is built with the trigger row (see buildTriggerRow) in scope, and
+// is correlated with the rows that fired the trigger as follows:
+//
+//   - the input of an INSERT or UPSERT body is joined with the trigger rows
+//     using an apply join, so that it is evaluated once per row. For example,
+//     an AFTER trigger with the body:
+//
+//       INSERT INTO audit VALUES (new.k, now())
+//
+//     is built like:
+//
+//       INSERT INTO audit
+//       SELECT new.k, now() FROM <trigger rows> AS new
+//
+//   - the rows deleted by a DELETE body are the rows that satisfy its WHERE
+//     clause for any of the trigger rows, which is built as a semi-join.
+//
+type afterTriggerBuilder struct {
+	tab     cat.Table
+	trigger cat.Trigger
+
+	// newOrds and oldOrds are the ordinals of the table columns that
+	// correspond 1-to-1 to the newValues and oldValues columns passed to Build.
+	// For an upsert, newValues holds the insert values followed by the update
+	// values, and each half corresponds 1-to-1 to newOrds.
+	newOrds, oldOrds []int
+
+	// canaryIdx is the index in oldOrds of the canary column of an upsert,
+	// which is NULL for the rows that were inserted. It is used to choose
+	// between the insert and update values, and, if the trigger does not fire
+	// for both INSERT and UPDATE events, the rows are filtered on it. It is -1
+	// if the mutation is not an upsert.
+	canaryIdx int
+}
+
+var _ memo.CascadeBuilder = &afterTriggerBuilder{}
+
+// buildUpsertNewValues is used when the trigger was fired by an upsert. It only
+// keeps the rows that the trigger fires for, and sets the columns of newColIDs
+// to the insert or update values of each row, depending on whether it was
+// inserted or updated. outCols are the columns of inScope, which holds the
+// buffered input of the upsert.
+func (tb *afterTriggerBuilder) buildUpsertNewValues(
+	b *Builder, inScope *scope, outCols opt.ColList, newColIDs opt.OptionalColList,
+) *scope {
+	canaryCol := outCols[2*len(tb.newOrds)+tb.canaryIdx]
+	canary := b.factory.ConstructVariable(canaryCol)
+	onInsert := tb.trigger.Events.Contains(tree.TriggerInsert)
+	onUpdate := tb.trigger.Events.Contains(tree.TriggerUpdate)
+	if onInsert && onUpdate {
+		projectionsScope := inScope.replace()
+		projectionsScope.appendColumnsFromScope(inScope)
+		for i, ord := range tb.newOrds {
+			insertCol, updateCol := outCols[i], outCols[len(tb.newOrds)+i]
+			caseExpr := b.factory.ConstructCase(
+				memo.TrueSingleton,
+				memo.ScalarListExpr{
+					b.factory.ConstructWhen(
+						b.factory.ConstructIs(canary, memo.NullSingleton),
+						b.factory.ConstructVariable(insertCol),
+					),
+				},
+				b.factory.ConstructVariable(updateCol),
+			)
+			col := tb.tab.Column(ord)
+			newColIDs[ord] = b.synthesizeColumn(
+				projectionsScope, string(col.ColName()), col.DatumType(), nil /* expr */, caseExpr,
+			).id
+		}
+		b.constructProjectForScope(inScope, projectionsScope)
+		return projectionsScope
+	}
+
+	// Only keep the rows that were inserted or updated.
+	var filter opt.ScalarExpr
+	if onInsert {
+		filter = b.factory.ConstructIs(canary, memo.NullSingleton)
+	} else {
+		filter = b.factory.ConstructIsNot(canary, memo.NullSingleton)
+		for i, ord := range tb.newOrds {
+			newColIDs[ord] = outCols[len(tb.newOrds)+i]
+		}
+	}
+	inScope.expr = b.factory.ConstructSelect(
+		inScope.expr.(memo.RelExpr),
+		memo.FiltersExpr{b.factory.ConstructFiltersItem(filter)},
+	)
+	return inScope
+}
+
+// Build is part of the memo.CascadeBuilder interface.
+func (tb *afterTriggerBuilder) Build(
+	ctx context.Context,
+	semaCtx *tree.SemaContext,
+	evalCtx *tree.EvalContext,
+	catalog cat.Catalog,
+	factoryI interface{},
+	binding opt.WithID,
+	bindingProps *props.Relational,
+	oldValues, newValues opt.ColList,
+) (_ memo.RelExpr, err error) {
+	return buildCascadeHelper(ctx, semaCtx, evalCtx, catalog, factoryI, func(b *Builder) memo.RelExpr {
+		stmt := parseTriggerBody(&tb.trigger)
+
+		md := b.factory.Metadata()
+		inCols := make(opt.ColList, 0, len(newValues)+len(oldValues))
+		inCols = append(inCols, newValues...)
+		inCols = append(inCols, oldValues...)
+		outCols := make(opt.ColList, len(inCols))
+		inScope := b.allocScope()
+		inScope.cols = make([]scopeColumn, len(inCols))
+		for i := range outCols {
+			c := md.ColumnMeta(inCols[i])
+			outCols[i] = md.AddColumn(c.Alias, c.Type)
+			inScope.cols[i] = scopeColumn{
+				name: tree.Name(c.Alias),
+				id:   outCols[i],
+				typ:  c.Type,
+			}
+		}
+
+		// Construct a dummy operator as the binding.
+		md.AddWithBinding(binding, b.factory.ConstructFakeRel(&memo.FakeRelPrivate{
+			Props: bindingProps,
+		}))
+		inScope.expr = b.factory.ConstructWithScan(&memo.WithScanPrivate{
+			With:    binding,
+			InCols:  inCols,
+			OutCols: outCols,
+			ID:      md.NextUniqueID(),
+		})
+
+		newColIDs := make(opt.OptionalColList, tb.tab.ColumnCount())
+		oldColIDs := make(opt.OptionalColList, tb.tab.ColumnCount())
+		for i, ord := range tb.newOrds {
+			newColIDs[ord] = outCols[i]
+		}
+		for i, ord := range tb.oldOrds {
+			oldColIDs[ord] = outCols[len(newValues)+i]
+		}
+
+		if tb.canaryIdx != -1 {
+			inScope = tb.buildUpsertNewValues(b, inScope, outCols, newColIDs)
+		}
+
+		b.triggerRowScope = b.buildTriggerRow(tb.tab, inScope, newColIDs, oldColIDs)
+		outScope := b.buildStmt(stmt, nil /* desiredTypes */, b.allocScope())
+		if b.triggerRowScope != nil {
+			panic(errors.AssertionFailedf("trigger row was not used"))
+		}
+		return outScope.expr
+	})
+}
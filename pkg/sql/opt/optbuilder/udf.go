@@ -0,0 +1,361 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+)
+
+// maxUDFInlineCount is the maximum number of calls to user-defined functions
+// that can be inlined in a single statement. Since calls are inlined
+// unconditionally, a recursive function would otherwise be inlined forever.
+const maxUDFInlineCount = 1000
+
+// replaceUDF returns the expression that the given call to a user-defined
+// function is inlined as. ok is false if there is no user-defined function
+// with the given name, in which case the caller should report the error
+// encountered when resolving the function as a builtin.
+//
+// A call f(x, y) to a function declared as
+//
+//   CREATE FUNCTION f(a INT, b STRING) RETURNS STRING LANGUAGE SQL AS '<body>'
+//
+// is inlined as the scalar subquery:
+//
+//   CAST((SELECT (<body> LIMIT 1) FROM (VALUES (x::INT, y::STRING)) AS f(a, b)) AS STRING)
+//
+// The arguments are thus visible to the body both by name and qualified with
+// the function name, and columns of the tables in the body take precedence
+// over arguments with the same name, like in Postgres. Positional references
+// to the arguments ($1, $2) are rewritten to references to the columns of the
+// VALUES clause. Only the first row returned by the body is used.
+//
+// Since a subquery which does not depend on the enclosing query is evaluated
+// only once per statement, calls to VOLATILE functions whose body is a single
+// expression are instead inlined as that expression, with the arguments
+// substituted for the references to them; see inlineVolatileUDF. This way,
+// each call is evaluated for every row, like in Postgres. Calls to volatile
+// functions with other bodies are still evaluated once per statement unless
+// their arguments depend on the enclosing query.
+func (s *scope) replaceUDF(f *tree.FuncExpr) (_ tree.Expr, ok bool) {
+	un, isName := f.Func.FunctionReference.(*tree.UnresolvedName)
+	if !isName {
+		return nil, false
+	}
+	name, err := un.ToUnresolvedObjectName(tree.NoAnnotation)
+	if err != nil {
+		return nil, false
+	}
+	fn, _, err := s.builder.catalog.ResolveFunction(s.builder.ctx, name)
+	if err != nil {
+		if pgerror.GetPGCode(err) == pgcode.UndefinedFunction {
+			return nil, false
+		}
+		panic(err)
+	}
+	if err := s.builder.catalog.CheckPrivilege(s.builder.ctx, fn, privilege.EXECUTE); err != nil {
+		panic(err)
+	}
+	s.builder.factory.Metadata().AddFunctionDependency(name, fn, !s.builder.inIndirectScope)
+
+	if f.Type != 0 || f.Filter != nil || f.WindowDef != nil || len(f.OrderBy) > 0 {
+		panic(pgerror.Newf(pgcode.WrongObjectType,
+			"%s is not an aggregate or window function", tree.ErrString(&f.Func)))
+	}
+	if len(f.Exprs) != fn.ArgCount() {
+		panic(pgerror.Newf(pgcode.UndefinedFunction,
+			"function %s takes %d argument(s), but %d were given",
+			tree.ErrString(&f.Func), fn.ArgCount(), len(f.Exprs)))
+	}
+	s.builder.udfInlineCount++
+	if s.builder.udfInlineCount > maxUDFInlineCount {
+		panic(pgerror.Newf(pgcode.ProgramLimitExceeded,
+			"too many calls to user-defined functions; note that recursive functions are not supported"))
+	}
+
+	args := make(tree.Exprs, len(f.Exprs))
+	for i := range args {
+		args[i] = &tree.CastExpr{
+			Expr: f.Exprs[i], Type: udfTypeReference(fn.ArgType(i)), SyntaxMode: tree.CastShort,
+		}
+	}
+	if fn.Volatility() == tree.VolatilityVolatile {
+		if expr, ok := s.inlineVolatileUDF(fn, args); ok {
+			return &tree.CastExpr{
+				Expr: expr, Type: udfTypeReference(fn.ReturnType()), SyntaxMode: tree.CastShort,
+			}, true
+		}
+	}
+	sub, body, err := makeUDFSubquery(fn.Name(), udfArgColNames(fn), fn.Body(), args)
+	if err != nil {
+		panic(err)
+	}
+	// The functions called by the body are not direct dependencies of the
+	// statement; see buildSubquery.
+	if s.builder.udfBodies == nil {
+		s.builder.udfBodies = make(map[*tree.Subquery]struct{})
+	}
+	s.builder.udfBodies[body] = struct{}{}
+	return &tree.CastExpr{
+		Expr: s.replaceSubquery(
+			sub, false /* wrapInTuple */, 1 /* desiredNumColumns */, noExtraColsAllowed,
+		),
+		Type:       udfTypeReference(fn.ReturnType()),
+		SyntaxMode: tree.CastShort,
+	}, true
+}
+
+// inlineVolatileUDF returns the expression that a call to the given volatile
+// function is inlined as, given the (already cast) arguments of the call. ok is
+// false if the body of the function is not a SELECT of a single expression
+// without a FROM clause, or if inlining it as an expression could change the
+// result of the call, in which case the call is inlined as a subquery.
+//
+// The expression may not contain subqueries, calls to aggregate, window,
+// generator or user-defined functions, nor references to anything but the
+// arguments. An argument which is referenced more than once must be a
+// constant or a column reference, so that it is still evaluated once.
+func (s *scope) inlineVolatileUDF(fn cat.Function, args tree.Exprs) (_ tree.Expr, ok bool) {
+	argCols := udfArgColNames(fn)
+	sel, err := parseUDFBody(fn.Name(), argCols, fn.Body())
+	if err != nil {
+		panic(err)
+	}
+	for sel.With == nil && sel.OrderBy == nil && sel.Limit == nil {
+		paren, isParen := sel.Select.(*tree.ParenSelect)
+		if !isParen {
+			break
+		}
+		sel = paren.Select
+	}
+	clause, isClause := sel.Select.(*tree.SelectClause)
+	if !isClause || sel.With != nil || sel.OrderBy != nil || sel.Limit != nil ||
+		clause.Distinct || clause.DistinctOn != nil || len(clause.Exprs) != 1 ||
+		len(clause.From.Tables) != 0 || clause.From.AsOf.Expr != nil || clause.Where != nil ||
+		clause.GroupBy != nil || clause.Having != nil || clause.Window != nil {
+		return nil, false
+	}
+
+	refs := make([]int, len(args))
+	inlinable := true
+	expr, err := tree.SimpleVisit(clause.Exprs[0].Expr, func(e tree.Expr) (bool, tree.Expr, error) {
+		switch t := e.(type) {
+		case *tree.UnresolvedName:
+			if i, isArg := udfArgRef(fn.Name(), argCols, t); isArg {
+				refs[i]++
+				return false, args[i], nil
+			}
+			inlinable = false
+
+		case *tree.FuncExpr:
+			def, err := t.Func.Resolve(s.builder.semaCtx.SearchPath)
+			if err != nil || t.WindowDef != nil ||
+				isAggregate(def) || isWindow(def) || isGenerator(def) {
+				inlinable = false
+			}
+
+		case *tree.Subquery, *tree.ColumnItem, *tree.Placeholder:
+			inlinable = false
+		}
+		return inlinable, e, nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	if !inlinable {
+		return nil, false
+	}
+	for i := range refs {
+		if refs[i] > 1 && !isSimpleUDFArg(args[i]) {
+			return nil, false
+		}
+	}
+	return &tree.ParenExpr{Expr: expr}, true
+}
+
+// udfArgRef returns the index of the argument of the function with the given
+// name and argument columns that the given name refers to, if any.
+func udfArgRef(fnName tree.Name, argCols tree.NameList, n *tree.UnresolvedName) (int, bool) {
+	if n.Star || n.NumParts > 2 || (n.NumParts == 2 && tree.Name(n.Parts[1]) != fnName) {
+		return 0, false
+	}
+	for i := range argCols {
+		if tree.Name(n.Parts[0]) == argCols[i] {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// isSimpleUDFArg returns true if the given (cast) argument of a call to a
+// user-defined function can be evaluated several times without changing the
+// result of the call.
+func isSimpleUDFArg(arg tree.Expr) bool {
+	if cast, ok := arg.(*tree.CastExpr); ok {
+		arg = cast.Expr
+	}
+	switch arg.(type) {
+	case tree.Datum, tree.Constant, *tree.UnresolvedName, *tree.ColumnItem, *tree.Placeholder:
+		return true
+	}
+	return false
+}
+
+// udfTypeReference returns a reference to the given type that can be used in
+// the AST. User-defined types are referenced by OID so that the type resolver
+// used by the builder tracks them.
+func udfTypeReference(typ *types.T) tree.ResolvableTypeReference {
+	if typ.UserDefined() {
+		return &tree.OIDTypeReference{OID: typ.Oid()}
+	}
+	return typ
+}
+
+// udfArgColNames returns the names of the columns of the VALUES clause holding
+// the arguments of the given function when it is inlined.
+func udfArgColNames(fn cat.Function) tree.NameList {
+	cols := make(tree.NameList, fn.ArgCount())
+	for i := range cols {
+		cols[i] = udfArgColName(fn.ArgName(i), i)
+	}
+	return cols
+}
+
+// udfArgColName returns the name of the column of the VALUES clause holding
+// the ith argument, which has the given (possibly empty) name.
+func udfArgColName(argName tree.Name, i int) tree.Name {
+	if argName != "" {
+		return argName
+	}
+	return tree.Name(fmt.Sprintf("$%d", i+1))
+}
+
+// makeUDFSubquery returns the uncast scalar subquery that a call to the
+// function with the given name, argument columns and body is inlined as, given
+// the (already cast) arguments of the call. See replaceUDF. bodySub is the
+// subquery nested in sub which holds the body alone.
+func makeUDFSubquery(
+	fnName tree.Name, argCols tree.NameList, body string, args tree.Exprs,
+) (sub, bodySub *tree.Subquery, _ error) {
+	sel, err := parseUDFBody(fnName, argCols, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	limitToFirstRow(sel)
+	bodySub = &tree.Subquery{Select: &tree.ParenSelect{Select: sel}}
+	if len(args) == 0 {
+		return bodySub, bodySub, nil
+	}
+	sub = &tree.Subquery{Select: &tree.ParenSelect{Select: &tree.Select{
+		Select: &tree.SelectClause{
+			Exprs: tree.SelectExprs{{Expr: bodySub}},
+			From: tree.From{Tables: tree.TableExprs{&tree.AliasedTableExpr{
+				Expr: &tree.Subquery{Select: &tree.ValuesClause{Rows: []tree.Exprs{args}}},
+				As:   tree.AliasClause{Alias: fnName, Cols: argCols},
+			}}},
+		},
+	}}}
+	return sub, bodySub, nil
+}
+
+// parseUDFBody parses the body of a function, and rewrites the positional
+// references to its arguments into references to the columns of the VALUES
+// clause of the inlined call.
+func parseUDFBody(fnName tree.Name, argCols tree.NameList, body string) (*tree.Select, error) {
+	stmt, err := parser.ParseOne(body)
+	if err != nil {
+		return nil, err
+	}
+	sel, err := udfBodySelect(stmt.AST)
+	if err != nil {
+		return nil, err
+	}
+
+	var hasPlaceholders bool
+	var placeholderErr error
+	fmtCtx := tree.NewFmtCtx(tree.FmtParsable)
+	fmtCtx.SetPlaceholderFormat(func(ctx *tree.FmtCtx, p *tree.Placeholder) {
+		hasPlaceholders = true
+		if int(p.Idx) >= len(argCols) {
+			if placeholderErr == nil {
+				placeholderErr = pgerror.Newf(pgcode.UndefinedParameter,
+					"there is no parameter %s", p)
+			}
+			return
+		}
+		ctx.FormatNode(&fnName)
+		ctx.WriteByte('.')
+		ctx.FormatNode(&argCols[p.Idx])
+	})
+	fmtCtx.FormatNode(sel)
+	if placeholderErr != nil {
+		return nil, placeholderErr
+	}
+	if !hasPlaceholders {
+		return sel, nil
+	}
+	stmt, err = parser.ParseOne(fmtCtx.CloseAndGetString())
+	if err != nil {
+		return nil, err
+	}
+	return udfBodySelect(stmt.AST)
+}
+
+// udfBodySelect returns the given statement as a SELECT statement, or an error
+// if it is not one.
+func udfBodySelect(stmt tree.Statement) (*tree.Select, error) {
+	switch t := stmt.(type) {
+	case *tree.Select:
+		return t, nil
+	case *tree.ParenSelect:
+		return &tree.Select{Select: t}, nil
+	default:
+		return nil, pgerror.Newf(pgcode.InvalidFunctionDefinition,
+			"the body of a function must be a SELECT statement, found %s", stmt.StatementTag())
+	}
+}
+
+// limitToFirstRow modifies the given SELECT statement so that it returns at
+// most one row, which is the first row it would have returned otherwise.
+func limitToFirstRow(sel *tree.Select) {
+	// Find the LIMIT clause, which may be nested inside parentheses.
+	limitSel := sel
+	for s := sel; ; {
+		if s.Limit != nil {
+			limitSel = s
+			break
+		}
+		paren, ok := s.Select.(*tree.ParenSelect)
+		if !ok {
+			break
+		}
+		s = paren.Select
+	}
+	one := tree.NewDInt(1)
+	switch {
+	case limitSel.Limit == nil:
+		limitSel.Limit = &tree.Limit{Count: one}
+	case limitSel.Limit.Count == nil || limitSel.Limit.LimitAll:
+		limitSel.Limit.Count, limitSel.Limit.LimitAll = one, false
+	default:
+		limitSel.Limit.Count = &tree.FuncExpr{
+			Func: tree.WrapFunction("least"), Exprs: tree.Exprs{limitSel.Limit.Count, one},
+		}
+	}
+}
@@ -300,6 +300,9 @@ func (mb *mutationBuilder) addSynthesizedColsForUpdate() {
 		mb.outScope.cols[i].mutation = false
 	}
 
+	// Run the BEFORE UPDATE triggers, which can modify the non-computed columns.
+	mb.buildBeforeTriggers(tree.TriggerUpdate)
+
 	// Add non-computed columns that are being dropped or added (mutated) to the
 	// table. These are not visible to queries, and will always be updated to
 	// their default values. This is necessary because they may not yet have been
@@ -341,8 +344,12 @@ func (mb *mutationBuilder) buildUpdate(returning tree.ReturningExprs) {
 
 	mb.buildUniqueChecksForUpdate()
 
+	mb.buildExclusionChecksForUpdate()
+
 	mb.buildFKChecksForUpdate()
 
+	mb.buildAfterTriggers(tree.TriggerUpdate)
+
 	private := mb.makeMutationPrivate(returning != nil)
 	for _, col := range mb.extraAccessibleCols {
 		if col.id != 0 {
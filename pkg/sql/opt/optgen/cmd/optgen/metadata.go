@@ -219,6 +219,7 @@ func newMetadata(compiled *lang.CompiledExpr, pkg string) *metadata {
 		"Subquery":          {fullName: "tree.Subquery", isPointer: true, usePointerIntern: true},
 		"CreateTable":       {fullName: "tree.CreateTable", isPointer: true, usePointerIntern: true},
 		"CreateStats":       {fullName: "tree.CreateStats", isPointer: true, usePointerIntern: true},
+		"CreateFunction":    {fullName: "tree.CreateFunction", isPointer: true, usePointerIntern: true},
 		"TableName":         {fullName: "tree.TableName", isPointer: true, usePointerIntern: true},
 		"Constraint":        {fullName: "constraint.Constraint", isPointer: true, usePointerIntern: true},
 		"FuncProps":         {fullName: "tree.FunctionProperties", isPointer: true, usePointerIntern: true},
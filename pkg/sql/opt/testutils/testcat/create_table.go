@@ -202,6 +202,9 @@ func (tc *Catalog) CreateTable(stmt *tree.CreateTable) *Table {
 		case *tree.FamilyTableDef:
 			tab.addFamily(def)
 
+		case *tree.ExclusionConstraintTableDef:
+			tab.addExclusionConstraint(def)
+
 		case *tree.ColumnTableDef:
 			if def.Unique.IsUnique {
 				if def.Unique.WithoutIndex {
@@ -453,6 +456,7 @@ func (tc *Catalog) resolveFK(tab *Table, d *tree.ForeignKeyConstraintTableDef) {
 		matchMethod:              d.Match,
 		deleteAction:             d.Actions.Delete,
 		updateAction:             d.Actions.Update,
+		deferrability:            d.Deferrability,
 	}
 	tab.outboundFKs = append(tab.outboundFKs, fk)
 	targetTable.inboundFKs = append(targetTable.inboundFKs, fk)
@@ -485,6 +489,44 @@ func (tt *Table) addUniqueConstraint(
 	tt.uniqueConstraints = append(tt.uniqueConstraints, u)
 }
 
+// addExclusionConstraint adds an exclusion constraint to the table, along with
+// the index that backs it. If one of the columns is a geospatial column
+// compared with the && operator the index is an inverted index on that column.
+// Otherwise it is a forward index on the columns compared with =.
+func (tt *Table) addExclusionConstraint(def *tree.ExclusionConstraintTableDef) {
+	name := string(def.Name)
+	if name == "" {
+		name = fmt.Sprintf("%s_excl", tt.TabName.Table())
+	}
+	e := ExclusionConstraint{name: name, tabID: tt.TabID}
+	var indexCols tree.IndexElemList
+	inverted := false
+	for _, elem := range def.Elems {
+		ord := tt.FindOrdinal(string(elem.Column))
+		e.columnOrdinals = append(e.columnOrdinals, ord)
+		e.operators = append(e.operators, elem.Operator)
+		switch elem.Operator {
+		case tree.EQ:
+			if !inverted {
+				indexCols = append(indexCols, tree.IndexElem{Column: elem.Column})
+			}
+		case tree.Overlaps:
+			if !inverted && colinfo.ColumnTypeIsInvertedIndexable(tt.Columns[ord].DatumType()) {
+				indexCols = tree.IndexElemList{{Column: elem.Column}}
+				inverted = true
+			}
+		}
+	}
+	tt.exclusionConstraints = append(tt.exclusionConstraints, e)
+	if len(indexCols) > 0 {
+		tt.addIndex(&tree.IndexTableDef{
+			Name:     tree.Name(name),
+			Columns:  indexCols,
+			Inverted: inverted,
+		}, nonUniqueIndex)
+	}
+}
+
 func (tt *Table) addColumn(def *tree.ColumnTableDef) {
 	ordinal := len(tt.Columns)
 	nullable := !def.PrimaryKey.IsPrimaryKey && def.Nullable.Nullability != tree.NotNull
@@ -0,0 +1,42 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package testcat
+
+import (
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/errors"
+)
+
+// CreateTrigger handles the CREATE TRIGGER statement. The body of the trigger
+// is not validated.
+func (tc *Catalog) CreateTrigger(stmt *tree.CreateTrigger) {
+	tn := stmt.Table
+	tc.qualifyTableName(&tn)
+	tab := tc.Table(&tn)
+
+	for i := range tab.Triggers {
+		if tab.Triggers[i].Name == stmt.Name {
+			panic(errors.Newf(`trigger "%s" already exists`, stmt.Name))
+		}
+	}
+	tab.Triggers = append(tab.Triggers, cat.Trigger{
+		Name:       stmt.Name,
+		ActionTime: stmt.ActionTime,
+		Events:     stmt.Events,
+		Body:       stmt.Body,
+	})
+	sort.Slice(tab.Triggers, func(i, j int) bool {
+		return tab.Triggers[i].Name < tab.Triggers[j].Name
+	})
+}
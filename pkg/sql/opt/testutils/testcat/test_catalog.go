@@ -178,6 +178,14 @@ func (tc *Catalog) ResolveTypeByOID(context.Context, oid.Oid) (*types.T, error)
 	return nil, errors.Newf("test catalog cannot handle user defined types")
 }
 
+// ResolveFunction is part of the cat.Catalog interface.
+func (tc *Catalog) ResolveFunction(
+	_ context.Context, name *tree.UnresolvedObjectName,
+) (cat.Function, cat.FunctionName, error) {
+	return nil, cat.FunctionName{}, pgerror.Newf(pgcode.UndefinedFunction,
+		"function %s does not exist", tree.ErrString(name))
+}
+
 // CheckPrivilege is part of the cat.Catalog interface.
 func (tc *Catalog) CheckPrivilege(ctx context.Context, o cat.Object, priv privilege.Kind) error {
 	return tc.CheckAnyPrivilege(ctx, o)
@@ -395,6 +403,10 @@ func (tc *Catalog) ExecuteDDLWithIndexVersion(
 		tc.CreateSequence(stmt)
 		return "", nil
 
+	case *tree.CreateTrigger:
+		tc.CreateTrigger(stmt)
+		return "", nil
+
 	case *tree.SetZoneConfig:
 		tc.SetZoneConfig(stmt)
 		return "", nil
@@ -579,6 +591,7 @@ type Table struct {
 	Indexes    []*Index
 	Stats      TableStats
 	Checks     []cat.CheckConstraint
+	Triggers   []cat.Trigger
 	Families   []*Family
 	IsVirtual  bool
 	Catalog    cat.Catalog
@@ -596,7 +609,8 @@ type Table struct {
 	outboundFKs []ForeignKeyConstraint
 	inboundFKs  []ForeignKeyConstraint
 
-	uniqueConstraints []UniqueConstraint
+	uniqueConstraints    []UniqueConstraint
+	exclusionConstraints []ExclusionConstraint
 }
 
 var _ cat.Table = &Table{}
@@ -736,6 +750,26 @@ func (tt *Table) Unique(i int) cat.UniqueConstraint {
 	return &tt.uniqueConstraints[i]
 }
 
+// TriggerCount is part of the cat.Table interface.
+func (tt *Table) TriggerCount() int {
+	return len(tt.Triggers)
+}
+
+// Trigger is part of the cat.Table interface.
+func (tt *Table) Trigger(i int) cat.Trigger {
+	return tt.Triggers[i]
+}
+
+// ExclusionCount is part of the cat.Table interface.
+func (tt *Table) ExclusionCount() int {
+	return len(tt.exclusionConstraints)
+}
+
+// Exclusion is part of the cat.Table interface.
+func (tt *Table) Exclusion(i int) cat.ExclusionConstraint {
+	return &tt.exclusionConstraints[i]
+}
+
 // FindOrdinal returns the ordinal of the column with the given name.
 func (tt *Table) FindOrdinal(name string) int {
 	for i, col := range tt.Columns {
@@ -1071,10 +1105,11 @@ type ForeignKeyConstraint struct {
 	originColumnOrdinals     []int
 	referencedColumnOrdinals []int
 
-	validated    bool
-	matchMethod  tree.CompositeKeyMatchMethod
-	deleteAction tree.ReferenceAction
-	updateAction tree.ReferenceAction
+	validated     bool
+	matchMethod   tree.CompositeKeyMatchMethod
+	deleteAction  tree.ReferenceAction
+	updateAction  tree.ReferenceAction
+	deferrability tree.ConstraintDeferrability
 }
 
 var _ cat.ForeignKeyConstraint = &ForeignKeyConstraint{}
@@ -1142,6 +1177,11 @@ func (fk *ForeignKeyConstraint) UpdateReferenceAction() tree.ReferenceAction {
 	return fk.updateAction
 }
 
+// Deferrability is part of the cat.ForeignKeyConstraint interface.
+func (fk *ForeignKeyConstraint) Deferrability() tree.ConstraintDeferrability {
+	return fk.deferrability
+}
+
 // UniqueConstraint implements cat.UniqueConstraint. See that interface
 // for more information on the fields.
 type UniqueConstraint struct {
@@ -1190,6 +1230,49 @@ func (u *UniqueConstraint) Validated() bool {
 	return u.validated
 }
 
+// Deferrability is part of the cat.UniqueConstraint interface. The test
+// catalog does not support deferrable unique constraints.
+func (u *UniqueConstraint) Deferrability() tree.ConstraintDeferrability {
+	return tree.ConstraintNotDeferrable
+}
+
+// ExclusionConstraint implements cat.ExclusionConstraint. See
+// cat.ExclusionConstraint for details.
+type ExclusionConstraint struct {
+	name           string
+	tabID          cat.StableID
+	columnOrdinals []int
+	operators      []tree.ComparisonOperator
+}
+
+var _ cat.ExclusionConstraint = &ExclusionConstraint{}
+
+// Name is part of the cat.ExclusionConstraint interface.
+func (e *ExclusionConstraint) Name() string {
+	return e.name
+}
+
+// ColumnCount is part of the cat.ExclusionConstraint interface.
+func (e *ExclusionConstraint) ColumnCount() int {
+	return len(e.columnOrdinals)
+}
+
+// ColumnOrdinal is part of the cat.ExclusionConstraint interface.
+func (e *ExclusionConstraint) ColumnOrdinal(tab cat.Table, i int) int {
+	if tab.ID() != e.tabID {
+		panic(errors.AssertionFailedf(
+			"invalid table %d passed to ColumnOrdinal (expected %d)",
+			tab.ID(), e.tabID,
+		))
+	}
+	return e.columnOrdinals[i]
+}
+
+// Operator is part of the cat.ExclusionConstraint interface.
+func (e *ExclusionConstraint) Operator(i int) tree.ComparisonOperator {
+	return e.operators[i]
+}
+
 // Sequence implements the cat.Sequence interface for testing purposes.
 type Sequence struct {
 	SeqID      cat.StableID
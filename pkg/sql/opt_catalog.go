@@ -13,6 +13,7 @@ package sql
 import (
 	"context"
 	"math"
+	"sort"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/config"
@@ -26,8 +27,10 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/dbdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/funcdesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
@@ -39,6 +42,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/errors"
 	"github.com/lib/pq/oid"
 )
@@ -259,10 +263,43 @@ func (oc *optCatalog) ResolveType(
 	return oc.planner.ResolveType(ctx, name)
 }
 
+// ResolveFunction is part of the cat.Catalog interface.
+func (oc *optCatalog) ResolveFunction(
+	ctx context.Context, name *tree.UnresolvedObjectName,
+) (cat.Function, cat.FunctionName, error) {
+	// Functions are leased like types; CREATE OR REPLACE FUNCTION and DROP
+	// FUNCTION wait for the leases on the previous version to be released.
+	lookupFlags := tree.ObjectLookupFlags{
+		CommonLookupFlags: tree.CommonLookupFlags{Required: true},
+		DesiredObjectKind: tree.FunctionObject,
+	}
+	desc, prefix, err := resolver.ResolveExistingObject(ctx, oc.planner, name, lookupFlags)
+	if err != nil {
+		return nil, cat.FunctionName{}, err
+	}
+	fnDesc := desc.(*funcdesc.Immutable)
+
+	// Ensure that the current user can access the target schema.
+	if err := oc.planner.canResolveDescUnderSchema(ctx, fnDesc.GetParentSchemaID(), fnDesc); err != nil {
+		return nil, cat.FunctionName{}, err
+	}
+
+	// Install the metadata of user defined types used in the signature of the
+	// function on a copy of the descriptor, so that they can be formatted.
+	fnDesc = funcdesc.NewImmutable(*protoutil.Clone(fnDesc.FuncDesc()).(*descpb.FunctionDescriptor))
+	if err := typedesc.HydrateTypesInFunctionDescriptor(ctx, fnDesc.FuncDesc(), oc.planner); err != nil {
+		return nil, cat.FunctionName{}, err
+	}
+	fn := tree.MakeNewQualifiedFunctionName(prefix.Catalog(), prefix.Schema(), name.Object())
+	return newOptFunction(fnDesc), fn, nil
+}
+
 func getDescFromCatalogObjectForPermissions(o cat.Object) (catalog.Descriptor, error) {
 	switch t := o.(type) {
 	case *optSchema:
 		return t.getDescriptorForPermissionsCheck(), nil
+	case *optFunction:
+		return t.desc, nil
 	case *optTable:
 		return t.desc, nil
 	case *optVirtualTable:
@@ -556,6 +593,79 @@ func (os *optSequence) Name() tree.Name {
 // SequenceMarker is part of the cat.Sequence interface.
 func (os *optSequence) SequenceMarker() {}
 
+// optFunction is a wrapper around funcdesc.Immutable that implements the
+// cat.Object and cat.Function interfaces.
+type optFunction struct {
+	desc *funcdesc.Immutable
+}
+
+var _ cat.Function = &optFunction{}
+
+func newOptFunction(desc *funcdesc.Immutable) *optFunction {
+	return &optFunction{desc: desc}
+}
+
+// ID is part of the cat.Object interface.
+func (of *optFunction) ID() cat.StableID {
+	return cat.StableID(of.desc.ID)
+}
+
+// PostgresDescriptorID is part of the cat.Object interface.
+func (of *optFunction) PostgresDescriptorID() cat.StableID {
+	return cat.StableID(of.desc.ID)
+}
+
+// Equals is part of the cat.Object interface.
+func (of *optFunction) Equals(other cat.Object) bool {
+	otherFn, ok := other.(*optFunction)
+	if !ok {
+		return false
+	}
+	return of.desc.ID == otherFn.desc.ID && of.desc.Version == otherFn.desc.Version
+}
+
+// Name is part of the cat.Function interface.
+func (of *optFunction) Name() tree.Name {
+	return tree.Name(of.desc.Name)
+}
+
+// ArgCount is part of the cat.Function interface.
+func (of *optFunction) ArgCount() int {
+	return len(of.desc.Args)
+}
+
+// ArgName is part of the cat.Function interface.
+func (of *optFunction) ArgName(i int) tree.Name {
+	return tree.Name(of.desc.Args[i].Name)
+}
+
+// ArgType is part of the cat.Function interface.
+func (of *optFunction) ArgType(i int) *types.T {
+	return of.desc.Args[i].Type
+}
+
+// ReturnType is part of the cat.Function interface.
+func (of *optFunction) ReturnType() *types.T {
+	return of.desc.ReturnType
+}
+
+// Body is part of the cat.Function interface.
+func (of *optFunction) Body() string {
+	return of.desc.Body
+}
+
+// Volatility is part of the cat.Function interface.
+func (of *optFunction) Volatility() tree.Volatility {
+	switch of.desc.Volatility {
+	case descpb.FunctionDescriptor_IMMUTABLE:
+		return tree.VolatilityImmutable
+	case descpb.FunctionDescriptor_STABLE:
+		return tree.VolatilityStable
+	default:
+		return tree.VolatilityVolatile
+	}
+}
+
 // optTable is a wrapper around sqlbase.Immutable that caches
 // index wrappers and maintains a ColumnID => Column mapping for fast lookup.
 type optTable struct {
@@ -597,6 +707,8 @@ type optTable struct {
 
 	uniqueConstraints []optUniqueConstraint
 
+	exclusionConstraints []optExclusionConstraint
+
 	outboundFKs []optForeignKeyConstraint
 	inboundFKs  []optForeignKeyConstraint
 
@@ -605,6 +717,9 @@ type optTable struct {
 	// constraints for user defined types.
 	checkConstraints []cat.CheckConstraint
 
+	// triggers is the set of row-level triggers for this table, sorted by name.
+	triggers []cat.Trigger
+
 	// colMap is a mapping from unique ColumnID to column ordinal within the
 	// table. This is a common lookup that needs to be fast.
 	colMap catalog.TableColMap
@@ -652,6 +767,13 @@ func newOptTable(
 			kind = cat.DeleteOnly
 		}
 		if !desc.Virtual {
+			defaultExpr := desc.DefaultExpr
+			if domain := desc.Type.TypeMeta.DomainData; domain != nil &&
+				defaultExpr == nil && desc.ComputeExpr == nil {
+				// Columns of a domain type use the default of the domain, unless they
+				// have their own default.
+				defaultExpr = domain.DefaultExpr
+			}
 			ot.columns[ordinal].InitNonVirtual(
 				ordinal,
 				cat.StableID(desc.ID),
@@ -660,7 +782,7 @@ func newOptTable(
 				desc.Type,
 				desc.Nullable,
 				desc.Hidden,
-				desc.DefaultExpr,
+				defaultExpr,
 				desc.ComputeExpr,
 			)
 		} else {
@@ -773,11 +895,22 @@ func newOptTable(
 			name:         u.Name,
 			table:        ot.ID(),
 			columns:      u.ColumnIDs,
-			withoutIndex: true,
-			validity:     u.Validity,
+			withoutIndex:  true,
+			validity:      u.Validity,
+			deferrability: u.Deferrability,
 		})
 	}
 
+	if exclusions := desc.GetExclusionConstraints(); len(exclusions) > 0 {
+		ot.exclusionConstraints = make([]optExclusionConstraint, len(exclusions))
+		for i := range exclusions {
+			ot.exclusionConstraints[i] = optExclusionConstraint{
+				desc:  &exclusions[i],
+				table: ot.ID(),
+			}
+		}
+	}
+
 	for i := range ot.desc.OutboundFKs {
 		fk := &ot.desc.OutboundFKs[i]
 		ot.outboundFKs = append(ot.outboundFKs, optForeignKeyConstraint{
@@ -790,6 +923,7 @@ func newOptTable(
 			match:             fk.Match,
 			deleteAction:      fk.OnDelete,
 			updateAction:      fk.OnUpdate,
+			deferrability:     fk.Deferrability,
 		})
 	}
 	for i := range ot.desc.InboundFKs {
@@ -804,6 +938,7 @@ func newOptTable(
 			match:             fk.Match,
 			deleteAction:      fk.OnDelete,
 			updateAction:      fk.OnUpdate,
+			deferrability:     fk.Deferrability,
 		})
 	}
 
@@ -813,29 +948,18 @@ func newOptTable(
 		ot.families[i].init(ot, &desc.Families[i+1])
 	}
 
-	// Synthesize any check constraints for user defined types.
-	var synthesizedChecks []cat.CheckConstraint
-	for i := 0; i < ot.ColumnCount(); i++ {
-		col := ot.Column(i)
-		if col.IsMutation() {
-			// We do not synthesize check constraints for mutation columns.
-			continue
-		}
-		colType := col.DatumType()
-		if colType.UserDefined() {
-			switch colType.Family() {
-			case types.EnumFamily:
-				// We synthesize an (x IN (v1, v2, v3...)) check for enum types.
-				expr := &tree.ComparisonExpr{
-					Operator: tree.In,
-					Left:     &tree.ColumnItem{ColumnName: col.ColName()},
-					Right:    tree.NewDTuple(colType, tree.MakeAllDEnumsInType(colType)...),
-				}
-				synthesizedChecks = append(synthesizedChecks, cat.CheckConstraint{
-					Constraint: tree.Serialize(expr),
-					Validated:  true,
-				})
-			}
+	// Synthesize any check constraints for user defined types. We do not
+	// synthesize check constraints for mutation columns.
+	typeChecks, err := synthesizeTypeChecks(desc.Columns)
+	if err != nil {
+		return nil, err
+	}
+	synthesizedChecks := make([]cat.CheckConstraint, len(typeChecks))
+	for i := range typeChecks {
+		synthesizedChecks[i] = cat.CheckConstraint{
+			Constraint: typeChecks[i].expr,
+			// Only the enum checks are known to hold for all existing rows.
+			Validated: typeChecks[i].domain == nil,
 		}
 	}
 	// Move all existing and synthesized checks into the opt table.
@@ -849,6 +973,24 @@ func newOptTable(
 	}
 	ot.checkConstraints = append(ot.checkConstraints, synthesizedChecks...)
 
+	if triggers := desc.GetTriggers(); len(triggers) > 0 {
+		ot.triggers = make([]cat.Trigger, len(triggers))
+		for i := range triggers {
+			t := &triggers[i]
+			ot.triggers[i] = cat.Trigger{
+				Name:       tree.Name(t.Name),
+				ActionTime: tree.TriggerActionTime(t.ActionTime),
+				Body:       t.Body,
+			}
+			for _, e := range t.Events {
+				ot.triggers[i].Events = append(ot.triggers[i].Events, tree.TriggerEvent(e))
+			}
+		}
+		sort.Slice(ot.triggers, func(i, j int) bool {
+			return ot.triggers[i].Name < ot.triggers[j].Name
+		})
+	}
+
 	// Add stats last, now that other metadata is initialized.
 	if stats != nil {
 		ot.stats = make([]optTableStat, len(stats))
@@ -1033,6 +1175,26 @@ func (ot *optTable) Check(i int) cat.CheckConstraint {
 	return ot.checkConstraints[i]
 }
 
+// TriggerCount is part of the cat.Table interface.
+func (ot *optTable) TriggerCount() int {
+	return len(ot.triggers)
+}
+
+// Trigger is part of the cat.Table interface.
+func (ot *optTable) Trigger(i int) cat.Trigger {
+	return ot.triggers[i]
+}
+
+// ExclusionCount is part of the cat.Table interface.
+func (ot *optTable) ExclusionCount() int {
+	return len(ot.exclusionConstraints)
+}
+
+// Exclusion is part of the cat.Table interface.
+func (ot *optTable) Exclusion(i int) cat.ExclusionConstraint {
+	return &ot.exclusionConstraints[i]
+}
+
 // FamilyCount is part of the cat.Table interface.
 func (ot *optTable) FamilyCount() int {
 	return 1 + len(ot.families)
@@ -1474,8 +1636,9 @@ type optUniqueConstraint struct {
 	table   cat.StableID
 	columns []descpb.ColumnID
 
-	withoutIndex bool
-	validity     descpb.ConstraintValidity
+	withoutIndex  bool
+	validity      descpb.ConstraintValidity
+	deferrability descpb.ConstraintDeferrability
 }
 
 var _ cat.UniqueConstraint = &optUniqueConstraint{}
@@ -1518,6 +1681,53 @@ func (u *optUniqueConstraint) Validated() bool {
 	return u.validity == descpb.ConstraintValidity_Validated
 }
 
+// Deferrability is part of the cat.UniqueConstraint interface.
+func (u *optUniqueConstraint) Deferrability() tree.ConstraintDeferrability {
+	return descpb.ConstraintDeferrabilityType[u.deferrability]
+}
+
+// optExclusionConstraint implements cat.ExclusionConstraint and represents an
+// EXCLUDE constraint of a table.
+type optExclusionConstraint struct {
+	desc *descpb.ExclusionConstraint
+
+	table cat.StableID
+}
+
+var _ cat.ExclusionConstraint = &optExclusionConstraint{}
+
+// Name is part of the cat.ExclusionConstraint interface.
+func (e *optExclusionConstraint) Name() string {
+	return e.desc.Name
+}
+
+// ColumnCount is part of the cat.ExclusionConstraint interface.
+func (e *optExclusionConstraint) ColumnCount() int {
+	return len(e.desc.ColumnIDs)
+}
+
+// ColumnOrdinal is part of the cat.ExclusionConstraint interface.
+func (e *optExclusionConstraint) ColumnOrdinal(tab cat.Table, i int) int {
+	if tab.ID() != e.table {
+		panic(errors.AssertionFailedf(
+			"invalid table %d passed to ColumnOrdinal (expected %d)",
+			tab.ID(), e.table,
+		))
+	}
+	optTab := tab.(*optTable)
+	ord, _ := optTab.lookupColumnOrdinal(e.desc.ColumnIDs[i])
+	return ord
+}
+
+// Operator is part of the cat.ExclusionConstraint interface.
+func (e *optExclusionConstraint) Operator(i int) tree.ComparisonOperator {
+	op, err := e.desc.Operator(i)
+	if err != nil {
+		panic(err)
+	}
+	return op
+}
+
 // optForeignKeyConstraint implements cat.ForeignKeyConstraint and represents a
 // foreign key relationship. Both the origin and the referenced table store the
 // same optForeignKeyConstraint (as an outbound and inbound reference,
@@ -1531,10 +1741,11 @@ type optForeignKeyConstraint struct {
 	referencedTable   cat.StableID
 	referencedColumns []descpb.ColumnID
 
-	validity     descpb.ConstraintValidity
-	match        descpb.ForeignKeyReference_Match
-	deleteAction descpb.ForeignKeyReference_Action
-	updateAction descpb.ForeignKeyReference_Action
+	validity      descpb.ConstraintValidity
+	match         descpb.ForeignKeyReference_Match
+	deleteAction  descpb.ForeignKeyReference_Action
+	updateAction  descpb.ForeignKeyReference_Action
+	deferrability descpb.ConstraintDeferrability
 }
 
 var _ cat.ForeignKeyConstraint = &optForeignKeyConstraint{}
@@ -1606,6 +1817,11 @@ func (fk *optForeignKeyConstraint) UpdateReferenceAction() tree.ReferenceAction
 	return descpb.ForeignKeyReferenceActionType[fk.updateAction]
 }
 
+// Deferrability is part of the cat.ForeignKeyConstraint interface.
+func (fk *optForeignKeyConstraint) Deferrability() tree.ConstraintDeferrability {
+	return descpb.ConstraintDeferrabilityType[fk.deferrability]
+}
+
 // optVirtualTable is similar to optTable but is used with virtual tables.
 type optVirtualTable struct {
 	desc *tabledesc.Immutable
@@ -1900,6 +2116,26 @@ func (ot *optVirtualTable) Unique(i int) cat.UniqueConstraint {
 	panic(errors.AssertionFailedf("no unique constraints"))
 }
 
+// TriggerCount is part of the cat.Table interface.
+func (ot *optVirtualTable) TriggerCount() int {
+	return 0
+}
+
+// Trigger is part of the cat.Table interface.
+func (ot *optVirtualTable) Trigger(i int) cat.Trigger {
+	panic(errors.AssertionFailedf("no triggers"))
+}
+
+// ExclusionCount is part of the cat.Table interface.
+func (ot *optVirtualTable) ExclusionCount() int {
+	return 0
+}
+
+// Exclusion is part of the cat.Table interface.
+func (ot *optVirtualTable) Exclusion(i int) cat.ExclusionConstraint {
+	panic(errors.AssertionFailedf("no exclusion constraints"))
+}
+
 // optVirtualIndex is a dummy implementation of cat.Index for the indexes
 // reported by a virtual table. The index assumes that table column 0 is a dummy
 // PK column.
@@ -1495,6 +1495,7 @@ func (ef *execFactory) ConstructDelete(
 	table cat.Table,
 	fetchColOrdSet exec.TableColumnOrdinalSet,
 	returnColOrdSet exec.TableColumnOrdinalSet,
+	passthrough colinfo.ResultColumns,
 	autoCommit bool,
 ) (exec.Node, error) {
 	// Derive table and column descriptors.
@@ -1518,7 +1519,8 @@ func (ef *execFactory) ConstructDelete(
 		source: input.(planNode),
 		run: deleteRun{
 			td:                        tableDeleter{rd: rd, alloc: ef.planner.alloc},
-			partialIndexDelValsOffset: len(rd.FetchCols),
+			partialIndexDelValsOffset: len(rd.FetchCols) + len(passthrough),
+			numPassthrough:            len(passthrough),
 		},
 	}
 
@@ -1528,6 +1530,8 @@ func (ef *execFactory) ConstructDelete(
 		// Delete returns the non-mutation columns specified, in the same
 		// order they are defined in the table.
 		del.columns = colinfo.ResultColumnsFromColDescs(tabDesc.GetID(), returnColDescs)
+		// Add the passthrough columns to the returning columns.
+		del.columns = append(del.columns, passthrough...)
 
 		del.run.rowIdxToRetIdx = row.ColMapping(rd.FetchCols, returnColDescs)
 		del.run.rowsNeeded = true
@@ -1635,6 +1639,7 @@ func (ef *execFactory) ConstructCreateView(
 	viewQuery string,
 	columns colinfo.ResultColumns,
 	deps opt.ViewDeps,
+	funcDeps []cat.Function,
 ) (exec.Node, error) {
 
 	if err := checkSchemaChangeEnabled(
@@ -1678,9 +1683,40 @@ func (ef *execFactory) ConstructCreateView(
 		dbDesc:       schema.(*optSchema).database,
 		columns:      columns,
 		planDeps:     planDeps,
+		funcDeps:     functionDepIDs(funcDeps),
+	}, nil
+}
+
+// ConstructCreateFunction is part of the exec.Factory interface.
+func (ef *execFactory) ConstructCreateFunction(
+	schema cat.Schema, cf *tree.CreateFunction, funcDeps []cat.Function,
+) (exec.Node, error) {
+	if err := checkSchemaChangeEnabled(
+		ef.planner.EvalContext().Context,
+		ef.planner.ExecCfg(),
+		"CREATE FUNCTION",
+	); err != nil {
+		return nil, err
+	}
+
+	return &createFunctionNode{
+		n:        cf,
+		dbDesc:   schema.(*optSchema).database,
+		schema:   schema.(*optSchema).schema,
+		funcDeps: functionDepIDs(funcDeps),
 	}, nil
 }
 
+// functionDepIDs returns the descriptor IDs of the given user-defined
+// functions.
+func functionDepIDs(fns []cat.Function) []descpb.ID {
+	ids := make([]descpb.ID, len(fns))
+	for i := range fns {
+		ids[i] = fns[i].(*optFunction).desc.GetID()
+	}
+	return ids
+}
+
 // ConstructSequenceSelect is part of the exec.Factory interface.
 func (ef *execFactory) ConstructSequenceSelect(sequence cat.Sequence) (exec.Node, error) {
 	return ef.planner.SequenceSelectNode(sequence.(*optSequence).desc)
@@ -143,8 +143,24 @@ func TestContextualHelp(t *testing.T) {
 		{`CREATE TABLE blah AS SELECT 1 ??`, `SELECT`},
 
 		{`CREATE TYPE blah AS ENUM ??`, `CREATE TYPE`},
+		{`CREATE TYPE blah AS (a INT ??`, `CREATE TYPE`},
 		{`DROP TYPE ??`, `DROP TYPE`},
 
+		{`CREATE DOMAIN ??`, `CREATE DOMAIN`},
+		{`CREATE DOMAIN d AS INT CHECK ??`, `CREATE DOMAIN`},
+		{`DROP DOMAIN ??`, `DROP DOMAIN`},
+
+		{`CREATE FUNCTION ??`, `CREATE FUNCTION`},
+		{`CREATE FUNCTION f(a INT) ??`, `CREATE FUNCTION`},
+		{`CREATE OR REPLACE FUNCTION ??`, `CREATE FUNCTION`},
+		{`DROP FUNCTION ??`, `DROP FUNCTION`},
+		{`DROP FUNCTION f ??`, `DROP FUNCTION`},
+
+		{`CREATE TRIGGER ??`, `CREATE TRIGGER`},
+		{`CREATE TRIGGER t BEFORE ??`, `CREATE TRIGGER`},
+		{`DROP TRIGGER ??`, `DROP TRIGGER`},
+		{`DROP TRIGGER t ON ??`, `DROP TRIGGER`},
+
 		{`CREATE SCHEMA IF ??`, `CREATE SCHEMA`},
 		{`CREATE SCHEMA IF NOT ??`, `CREATE SCHEMA`},
 		{`CREATE SCHEMA bli ??`, `CREATE SCHEMA`},
@@ -157,6 +173,15 @@ func TestContextualHelp(t *testing.T) {
 		{`DISCARD ALL ??`, `DISCARD`},
 		{`DISCARD ??`, `DISCARD`},
 
+		{`DECLARE ??`, `DECLARE`},
+		{`FETCH ??`, `FETCH`},
+		{`MOVE ??`, `MOVE`},
+		{`CLOSE ??`, `CLOSE`},
+
+		{`LISTEN ??`, `LISTEN`},
+		{`NOTIFY ??`, `NOTIFY`},
+		{`UNLISTEN ??`, `UNLISTEN`},
+
 		{`DROP ??`, `DROP`},
 
 		{`DROP DATABASE IF ??`, `DROP DATABASE`},
@@ -386,6 +411,11 @@ func TestContextualHelp(t *testing.T) {
 		{`SET SESSION TIME ZONE 'UTC' ??`, `SET SESSION`},
 		{`SET SESSION blah TO ??`, `SET SESSION`},
 		{`SET SESSION blah TO 42 ??`, `SET SESSION`},
+		{`SET LOCAL ??`, `SET SESSION`},
+		{`SET LOCAL blah TO 42 ??`, `SET SESSION`},
+
+		{`SET CONSTRAINTS ??`, `SET CONSTRAINTS`},
+		{`SET CONSTRAINTS ALL ??`, `SET CONSTRAINTS`},
 
 		{`SET TRANSACTION ??`, `SET TRANSACTION`},
 		{`SET TRANSACTION ISOLATION LEVEL SNAPSHOT ??`, `SET TRANSACTION`},
@@ -432,6 +462,9 @@ func TestContextualHelp(t *testing.T) {
 		{`RESTORE foo FROM 'bar' ??`, `RESTORE`},
 		{`RESTORE DATABASE ??`, `RESTORE`},
 
+		{`COMPACT BACKUP 'foo' IN 'bar' ??`, `COMPACT BACKUP`},
+		{`COMPACT ??`, `COMPACT BACKUP`},
+
 		{`IMPORT TABLE foo CREATE USING 'foo.sql' CSV DATA ('foo') ??`, `IMPORT`},
 		{`IMPORT TABLE ??`, `IMPORT`},
 
@@ -167,6 +167,39 @@ func (u *sqlSymUnion) nameList() tree.NameList {
 func (u *sqlSymUnion) enumValueList() tree.EnumValueList {
     return u.val.(tree.EnumValueList)
 }
+func (u *sqlSymUnion) compositeTypeElem() tree.CompositeTypeElem {
+    return u.val.(tree.CompositeTypeElem)
+}
+func (u *sqlSymUnion) compositeTypeList() []tree.CompositeTypeElem {
+    return u.val.([]tree.CompositeTypeElem)
+}
+func (u *sqlSymUnion) funcArg() tree.FuncArg {
+    return u.val.(tree.FuncArg)
+}
+func (u *sqlSymUnion) funcArgs() tree.FuncArgs {
+    return u.val.(tree.FuncArgs)
+}
+func (u *sqlSymUnion) functionOption() tree.FunctionOption {
+    return u.val.(tree.FunctionOption)
+}
+func (u *sqlSymUnion) functionOptions() tree.FunctionOptions {
+    return u.val.(tree.FunctionOptions)
+}
+func (u *sqlSymUnion) funcObj() tree.FuncObj {
+    return u.val.(tree.FuncObj)
+}
+func (u *sqlSymUnion) funcObjs() []tree.FuncObj {
+    return u.val.([]tree.FuncObj)
+}
+func (u *sqlSymUnion) triggerActionTime() tree.TriggerActionTime {
+    return u.val.(tree.TriggerActionTime)
+}
+func (u *sqlSymUnion) triggerEvent() tree.TriggerEvent {
+    return u.val.(tree.TriggerEvent)
+}
+func (u *sqlSymUnion) triggerEvents() tree.TriggerEvents {
+    return u.val.(tree.TriggerEvents)
+}
 func (u *sqlSymUnion) unresolvedName() *tree.UnresolvedName {
     return u.val.(*tree.UnresolvedName)
 }
@@ -224,6 +257,9 @@ func (u *sqlSymUnion) slct() *tree.Select {
 func (u *sqlSymUnion) selectStmt() tree.SelectStatement {
     return u.val.(tree.SelectStatement)
 }
+func (u *sqlSymUnion) cursorStmt() tree.CursorStmt {
+    return u.val.(tree.CursorStmt)
+}
 func (u *sqlSymUnion) colDef() *tree.ColumnTableDef {
     return u.val.(*tree.ColumnTableDef)
 }
@@ -362,6 +398,12 @@ func (u *sqlSymUnion) targetList() tree.TargetList {
 func (u *sqlSymUnion) targetListPtr() *tree.TargetList {
     return u.val.(*tree.TargetList)
 }
+func (u *sqlSymUnion) changefeedTargets() tree.ChangefeedTargets {
+    return u.val.(tree.ChangefeedTargets)
+}
+func (u *sqlSymUnion) changefeedTarget() tree.ChangefeedTarget {
+    return u.val.(tree.ChangefeedTarget)
+}
 func (u *sqlSymUnion) privilegeType() privilege.Kind {
     return u.val.(privilege.Kind)
 }
@@ -434,6 +476,12 @@ func (u *sqlSymUnion) idxElem() tree.IndexElem {
 func (u *sqlSymUnion) idxElems() tree.IndexElemList {
     return u.val.(tree.IndexElemList)
 }
+func (u *sqlSymUnion) exclusionElem() tree.ExclusionElem {
+    return u.val.(tree.ExclusionElem)
+}
+func (u *sqlSymUnion) exclusionElems() tree.ExclusionElemList {
+    return u.val.(tree.ExclusionElemList)
+}
 func (u *sqlSymUnion) dropBehavior() tree.DropBehavior {
     return u.val.(tree.DropBehavior)
 }
@@ -509,6 +557,9 @@ func (u *sqlSymUnion) transactionModes() tree.TransactionModes {
 func (u *sqlSymUnion) compositeKeyMatchMethod() tree.CompositeKeyMatchMethod {
   return u.val.(tree.CompositeKeyMatchMethod)
 }
+func (u *sqlSymUnion) constraintDeferrability() tree.ConstraintDeferrability {
+  return u.val.(tree.ConstraintDeferrability)
+}
 func (u *sqlSymUnion) referenceAction() tree.ReferenceAction {
     return u.val.(tree.ReferenceAction)
 }
@@ -594,11 +645,11 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 // below; search this file for "Keyword category lists".
 
 // Ordinary key words in alphabetical order.
-%token <str> ABORT ACCESS ACTION ADD ADMIN AFFINITY AFTER AGGREGATE
+%token <str> ABORT ABSOLUTE ACCESS ACTION ADD ADMIN AFFINITY AFTER AGGREGATE
 %token <str> ALL ALTER ALWAYS ANALYSE ANALYZE AND AND_AND ANY ANNOTATE_TYPE ARRAY AS ASC
 %token <str> ASYMMETRIC AT ATTRIBUTE AUTHORIZATION AUTOMATIC
 
-%token <str> BACKUP BACKUPS BEFORE BEGIN BETWEEN BIGINT BIGSERIAL BINARY BIT
+%token <str> BACKUP BACKUPS BACKWARD BEFORE BEGIN BETWEEN BIGINT BIGSERIAL BINARY BIT
 %token <str> BUCKET_COUNT
 %token <str> BOOLEAN BOTH BOX2D BUNDLE BY
 
@@ -610,13 +661,13 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 %token <str> CONVERSION CONVERT COPY COVERING CREATE CREATEDB CREATELOGIN CREATEROLE
 %token <str> CROSS CUBE CURRENT CURRENT_CATALOG CURRENT_DATE CURRENT_SCHEMA
 %token <str> CURRENT_ROLE CURRENT_TIME CURRENT_TIMESTAMP
-%token <str> CURRENT_USER CYCLE
+%token <str> CURRENT_USER CURSOR CYCLE
 
 %token <str> DATA DATABASE DATABASES DATE DAY DEC DECIMAL DEFAULT DEFAULTS
 %token <str> DEALLOCATE DECLARE DEFERRABLE DEFERRED DELETE DESC DESTINATION DETACHED
 %token <str> DISCARD DISTINCT DO DOMAIN DOUBLE DROP
 
-%token <str> ELSE ENCODING ENCRYPTION_PASSPHRASE END ENUM ENUMS ESCAPE EXCEPT EXCLUDE EXCLUDING
+%token <str> EACH ELSE ENCODING ENCRYPTION_PASSPHRASE END ENUM ENUMS ESCAPE EXCEPT EXCLUDE EXCLUDING
 %token <str> EXISTS EXECUTE EXECUTION EXPERIMENTAL
 %token <str> EXPERIMENTAL_FINGERPRINTS EXPERIMENTAL_REPLICA
 %token <str> EXPERIMENTAL_AUDIT
@@ -624,16 +675,16 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 
 %token <str> FAILURE FALSE FAMILY FETCH FETCHVAL FETCHTEXT FETCHVAL_PATH FETCHTEXT_PATH
 %token <str> FILES FILTER
-%token <str> FIRST FLOAT FLOAT4 FLOAT8 FLOORDIV FOLLOWING FOR FORCE_INDEX FOREIGN FROM FULL FUNCTION
+%token <str> FIRST FLOAT FLOAT4 FLOAT8 FLOORDIV FOLLOWING FOR FORCE_INDEX FOREIGN FORWARD FROM FULL FUNCTION
 
 %token <str> GENERATED GEOGRAPHY GEOMETRY GEOMETRYM GEOMETRYZ GEOMETRYZM
 %token <str> GEOMETRYCOLLECTION GEOMETRYCOLLECTIONM GEOMETRYCOLLECTIONZ GEOMETRYCOLLECTIONZM
 %token <str> GLOBAL GOAL GRANT GRANTS GREATEST GROUP GROUPING GROUPS
 
-%token <str> HAVING HASH HIGH HISTOGRAM HOUR
+%token <str> HAVING HASH HIGH HISTOGRAM HOLD HOUR
 
 %token <str> IDENTITY
-%token <str> IF IFERROR IFNULL IGNORE_FOREIGN_KEYS ILIKE IMMEDIATE IMPORT IN INCLUDE INCLUDING INCREMENT INCREMENTAL
+%token <str> IF IFERROR IFNULL IGNORE_FOREIGN_KEYS ILIKE IMMEDIATE IMMUTABLE IMPORT IN INCLUDE INCLUDING INCREMENT INCREMENTAL
 %token <str> INET INET_CONTAINED_BY_OR_EQUALS
 %token <str> INET_CONTAINS_OR_EQUALS INDEX INDEXES INHERITS INJECT INTERLEAVE INITIALLY
 %token <str> INNER INSERT INT INTEGER
@@ -646,23 +697,23 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 %token <str> LANGUAGE LAST LATERAL LATEST LC_CTYPE LC_COLLATE
 %token <str> LEADING LEASE LEAST LEFT LESS LEVEL LIKE LIMIT
 %token <str> LINESTRING LINESTRINGM LINESTRINGZ LINESTRINGZM
-%token <str> LIST LOCAL LOCALITY LOCALTIME LOCALTIMESTAMP LOCKED LOGIN LOOKUP LOW LSHIFT
+%token <str> LIST LISTEN LOCAL LOCALITY LOCALTIME LOCALTIMESTAMP LOCKED LOGIN LOOKUP LOW LSHIFT
 
-%token <str> MATCH MATERIALIZED MERGE MINVALUE MAXVALUE METHOD MINUTE MODIFYCLUSTERSETTING MONTH
+%token <str> MATCH MATERIALIZED MERGE MINVALUE MAXVALUE METHOD MINUTE MODIFYCLUSTERSETTING MONTH MOVE
 %token <str> MULTILINESTRING MULTILINESTRINGM MULTILINESTRINGZ MULTILINESTRINGZM
 %token <str> MULTIPOINT MULTIPOINTM MULTIPOINTZ MULTIPOINTZM
 %token <str> MULTIPOLYGON MULTIPOLYGONM MULTIPOLYGONZ MULTIPOLYGONZM
 
 %token <str> NAN NAME NAMES NATURAL NEVER NEXT NO NOCANCELQUERY NOCONTROLCHANGEFEED NOCONTROLJOB
 %token <str> NOCREATEDB NOCREATELOGIN NOCREATEROLE NOLOGIN NOMODIFYCLUSTERSETTING NO_INDEX_JOIN
-%token <str> NONE NORMAL NOT NOTHING NOTNULL NOVIEWACTIVITY NOWAIT NULL NULLIF NULLS NUMERIC
+%token <str> NONE NORMAL NOT NOTHING NOTIFY NOTNULL NOVIEWACTIVITY NOWAIT NULL NULLIF NULLS NUMERIC
 
 %token <str> OF OFF OFFSET OID OIDS OIDVECTOR ON ONLY OPT OPTION OPTIONS OR
 %token <str> ORDER ORDINALITY OTHERS OUT OUTER OVER OVERLAPS OVERLAY OWNED OWNER OPERATOR
 
 %token <str> PARENT PARTIAL PARTITION PARTITIONS PASSWORD PAUSE PAUSED PHYSICAL PLACING
 %token <str> PLAN PLANS POINT POINTM POINTZ POINTZM POLYGON POLYGONM POLYGONZ POLYGONZM
-%token <str> POSITION PRECEDING PRECISION PREPARE PRESERVE PRIMARY PRIORITY PRIVILEGES
+%token <str> POSITION PRECEDING PRECISION PREPARE PRESERVE PRIMARY PRIOR PRIORITY PRIVILEGES
 %token <str> PROCEDURAL PUBLIC PUBLICATION
 
 %token <str> QUERIES QUERY
@@ -670,15 +721,15 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 %token <str> RANGE RANGES READ REAL REASSIGN RECURSIVE RECURRING REF REFERENCES REFRESH
 %token <str> REGCLASS REGION REGIONAL REGIONS REGPROC REGPROCEDURE REGNAMESPACE REGTYPE REINDEX
 %token <str> REMOVE_PATH RENAME REPEATABLE REPLACE
-%token <str> RELEASE RESET RESTORE RESTRICT RESUME RETURNING RETRY REVISION_HISTORY REVOKE RIGHT
+%token <str> RELATIVE RELEASE RESET RESTORE RESTRICT RESUME RETURNING RETURNS RETRY REVISION_HISTORY REVOKE RIGHT
 %token <str> ROLE ROLES ROLLBACK ROLLUP ROW ROWS RSHIFT RULE RUNNING
 
-%token <str> SAVEPOINT SCATTER SCHEDULE SCHEDULES SCHEMA SCHEMAS SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
+%token <str> SAVEPOINT SCATTER SCHEDULE SCHEDULES SCHEMA SCHEMAS SCROLL SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
 %token <str> SERIALIZABLE SERVER SESSION SESSIONS SESSION_USER SET SETS SETTING SETTINGS
 %token <str> SHARE SHOW SIMILAR SIMPLE SKIP SKIP_MISSING_FOREIGN_KEYS
 %token <str> SKIP_MISSING_SEQUENCES SKIP_MISSING_SEQUENCE_OWNERS SKIP_MISSING_VIEWS SMALLINT SMALLSERIAL SNAPSHOT SOME SPLIT SQL
 
-%token <str> START STATISTICS STATUS STDIN STRICT STRING STORAGE STORE STORED STORING SUBSTRING
+%token <str> STABLE START STATISTICS STATUS STDIN STRICT STRING STORAGE STORE STORED STORING SUBSTRING
 %token <str> SURVIVE SURVIVAL SYMMETRIC SYNTAX SYSTEM SQRT SUBSCRIPTION
 
 %token <str> TABLE TABLES TABLESPACE TEMP TEMPLATE TEMPORARY TENANT TESTING_RELOCATE EXPERIMENTAL_RELOCATE TEXT THEN
@@ -687,10 +738,10 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 %token <str> TRUNCATE TRUSTED TYPE TYPES
 %token <str> TRACING
 
-%token <str> UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN UNLOGGED UNSPLIT
+%token <str> UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN UNLISTEN UNLOGGED UNSPLIT
 %token <str> UPDATE UPSERT UNTIL USE USER USERS USING UUID
 
-%token <str> VALID VALIDATE VALUE VALUES VARBIT VARCHAR VARIADIC VIEW VARYING VIEWACTIVITY VIRTUAL
+%token <str> VALID VALIDATE VALUE VALUES VARBIT VARCHAR VARIADIC VIEW VARYING VIEWACTIVITY VIRTUAL VOLATILE
 
 %token <str> WHEN WHERE WINDOW WITH WITHIN WITHOUT WORK WRITE
 
@@ -781,6 +832,7 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 %type <tree.Statement> alter_sequence_set_schema_stmt
 
 %type <tree.Statement> backup_stmt
+%type <tree.Statement> compact_backup_stmt
 %type <tree.Statement> begin_stmt
 
 %type <tree.Statement> cancel_stmt
@@ -820,6 +872,9 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 %type <*tree.CreateStatsOptions> create_stats_option
 
 %type <tree.Statement> create_type_stmt
+%type <tree.Statement> create_domain_stmt
+%type <tree.Statement> create_func_stmt
+%type <tree.Statement> create_trigger_stmt
 %type <tree.Statement> delete_stmt
 %type <tree.Statement> discard_stmt
 
@@ -831,6 +886,9 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 %type <tree.Statement> drop_schema_stmt
 %type <tree.Statement> drop_table_stmt
 %type <tree.Statement> drop_type_stmt
+%type <tree.Statement> drop_domain_stmt
+%type <tree.Statement> drop_func_stmt
+%type <tree.Statement> drop_trigger_stmt
 %type <tree.Statement> drop_view_stmt
 %type <tree.Statement> drop_sequence_stmt
 
@@ -845,6 +903,8 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 %type <tree.Statement> grant_stmt
 %type <tree.Statement> insert_stmt
 %type <tree.Statement> import_stmt
+%type <tree.Statement> listen_stmt
+%type <tree.Statement> notify_stmt
 %type <tree.Statement> pause_stmt pause_jobs_stmt pause_schedules_stmt
 %type <*tree.Select>   for_schedules_clause
 %type <tree.Statement> reassign_owned_by_stmt
@@ -862,11 +922,13 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 %type <tree.Statement> abort_stmt
 %type <tree.Statement> rollback_stmt
 %type <tree.Statement> savepoint_stmt
+%type <tree.Statement> unlisten_stmt
 
 %type <tree.Statement> preparable_set_stmt nonpreparable_set_stmt
 %type <tree.Statement> set_session_stmt
 %type <tree.Statement> set_csetting_stmt
 %type <tree.Statement> set_transaction_stmt
+%type <tree.Statement> set_constraints_stmt
 %type <tree.Statement> set_exprs_internal
 %type <tree.Statement> generic_set
 %type <tree.Statement> set_rest_more
@@ -921,6 +983,10 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 
 %type <tree.Statement> close_cursor_stmt
 %type <tree.Statement> declare_cursor_stmt
+%type <tree.Statement> fetch_cursor_stmt
+%type <tree.Statement> move_cursor_stmt
+%type <tree.CursorStmt> fetch_args
+%type <bool> opt_hold
 %type <tree.Statement> reindex_stmt
 
 %type <[]string> opt_incremental
@@ -979,7 +1045,19 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 %type <str> family_name opt_family_name table_alias_name constraint_name target_name zone_name partition_name collation_name
 %type <str> db_object_name_component
 %type <*tree.UnresolvedObjectName> table_name standalone_index_name sequence_name type_name view_name db_object_name simple_db_object_name complex_db_object_name
-%type <[]*tree.UnresolvedObjectName> type_name_list
+%type <[]*tree.UnresolvedObjectName> type_name_list func_name_list
+%type <tree.FuncArgs> opt_func_arg_list func_arg_list
+%type <tree.FuncArg> func_arg
+%type <tree.CompositeTypeElem> composite_type_elem
+%type <[]tree.CompositeTypeElem> opt_composite_type_list composite_type_list
+%type <tree.FunctionOptions> func_option_list
+%type <tree.FunctionOption> func_option
+%type <[]tree.FuncObj> func_obj_list
+%type <tree.FuncObj> func_obj
+%type <tree.TriggerActionTime> trigger_action_time
+%type <tree.TriggerEvent> trigger_event
+%type <tree.TriggerEvents> trigger_event_list
+%type <[]tree.ResolvableTypeReference> opt_func_arg_types
 %type <str> schema_name
 %type <tree.ObjectNamePrefix>  qualifiable_schema_name opt_schema_name
 %type <tree.ObjectNamePrefixList> schema_name_list
@@ -1015,11 +1093,17 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 %type <tree.OrderBy> sort_clause single_sort_clause opt_sort_clause
 %type <[]*tree.Order> sortby_list
 %type <tree.IndexElemList> index_params create_as_params
+%type <tree.ExclusionElem> exclude_elem
+%type <tree.ExclusionElemList> exclude_elems
+%type <tree.ComparisonOperator> exclude_op
+%type <str> opt_exclude_access_method
 %type <tree.NameList> name_list privilege_list
 %type <[]int32> opt_array_bounds
 %type <tree.From> from_clause
-%type <tree.TableExprs> from_list rowsfrom_list opt_from_list
-%type <tree.TablePatterns> table_pattern_list single_table_pattern_list
+%type <tree.TableExprs> from_list rowsfrom_list opt_from_list opt_using_clause
+%type <tree.TablePatterns> table_pattern_list
+%type <tree.ChangefeedTargets> changefeed_targets changefeed_target_list
+%type <tree.ChangefeedTarget> changefeed_target
 %type <tree.TableNames> table_name_list opt_locked_rels
 %type <tree.Exprs> expr_list opt_expr_list tuple1_ambiguous_values tuple1_unambiguous_values
 %type <*tree.Tuple> expr_tuple1_ambiguous expr_tuple_unambiguous
@@ -1034,13 +1118,13 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 %type <*tree.Limit> select_limit opt_select_limit
 %type <tree.TableNames> relation_expr_list
 %type <tree.ReturningClause> returning_clause
-%type <empty> opt_using_clause
 %type <tree.RefreshDataOption> opt_clear_data
 
 %type <[]tree.SequenceOption> sequence_option_list opt_sequence_option_list
 %type <tree.SequenceOption> sequence_option_elem
 
 %type <bool> all_or_distinct
+%type <bool> constraints_set_mode
 %type <bool> with_comment
 %type <empty> join_outer
 %type <tree.JoinCond> join_qual
@@ -1163,6 +1247,7 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 %type <tree.NamedColumnQualification> col_qualification create_as_col_qualification
 %type <tree.ColumnQualification> col_qualification_elem create_as_col_qualification_elem
 %type <tree.CompositeKeyMatchMethod> key_match
+%type <tree.ConstraintDeferrability> opt_deferrable
 %type <tree.ReferenceActions> reference_actions
 %type <tree.ReferenceAction> reference_action reference_on_delete reference_on_update
 
@@ -1187,7 +1272,7 @@ func (u *sqlSymUnion) objectNamePrefixList() tree.ObjectNamePrefixList {
 
 %type <[]tree.ColumnID> opt_tableref_col_list tableref_col_list
 
-%type <tree.TargetList> targets targets_roles target_types changefeed_targets
+%type <tree.TargetList> targets targets_roles target_types
 %type <*tree.TargetList> opt_on_targets_roles opt_backup_targets
 %type <tree.NameList> for_grantee_clause
 %type <privilege.List> privileges
@@ -1291,6 +1376,9 @@ stmt:
 | execute_stmt              // EXTEND WITH HELP: EXECUTE
 | deallocate_stmt           // EXTEND WITH HELP: DEALLOCATE
 | discard_stmt              // EXTEND WITH HELP: DISCARD
+| listen_stmt               // EXTEND WITH HELP: LISTEN
+| notify_stmt               // EXTEND WITH HELP: NOTIFY
+| unlisten_stmt             // EXTEND WITH HELP: UNLISTEN
 | grant_stmt                // EXTEND WITH HELP: GRANT
 | prepare_stmt              // EXTEND WITH HELP: PREPARE
 | revoke_stmt               // EXTEND WITH HELP: REVOKE
@@ -1301,8 +1389,10 @@ stmt:
 | refresh_stmt              // EXTEND WITH HELP: REFRESH
 | nonpreparable_set_stmt    // help texts in sub-rule
 | transaction_stmt          // help texts in sub-rule
-| close_cursor_stmt
-| declare_cursor_stmt
+| close_cursor_stmt         // EXTEND WITH HELP: CLOSE
+| declare_cursor_stmt       // EXTEND WITH HELP: DECLARE
+| fetch_cursor_stmt         // EXTEND WITH HELP: FETCH
+| move_cursor_stmt          // EXTEND WITH HELP: MOVE
 | reindex_stmt
 | /* EMPTY */
   {
@@ -2334,6 +2424,32 @@ backup_stmt:
   }
 | BACKUP error // SHOW HELP: BACKUP
 
+// %Help: COMPACT BACKUP - merge a backup and its incremental layers
+// %Category: CCL
+// %Text:
+// COMPACT BACKUP <subdir> IN <collection>
+//        [ WITH <option> [= <value>] [, ...] ]
+//
+// Writes the full backup in <subdir> and all of its incremental layers out as
+// a single full backup in a new subdirectory of the collection.
+//
+// Options:
+//    encryption_passphrase="secret": decrypt and re-encrypt the backup
+//    kms="[kms_provider]://[kms_host]/[master_key_identifier]?[parameters]" : decrypt and re-encrypt the backup using KMS
+//    detached: execute compaction job asynchronously, without waiting for its completion
+//
+// %SeeAlso: BACKUP, SHOW BACKUP
+compact_backup_stmt:
+  COMPACT BACKUP string_or_placeholder IN string_or_placeholder opt_with_options
+  {
+    $$.val = &tree.CompactBackup{
+      Subdir:       $3.expr(),
+      InCollection: $5.expr(),
+      Options:      $6.kvOptions(),
+    }
+  }
+| COMPACT error // SHOW HELP: COMPACT BACKUP
+
 opt_backup_targets:
   /* EMPTY -- full cluster */
   {
@@ -2546,6 +2662,9 @@ opt_with_schedule_options:
 // RESTORE <targets...> FROM <location...>
 //         [ AS OF SYSTEM TIME <expr> ]
 //         [ WITH <option> [= <value>] [, ...] ]
+// RESTORE TABLE <tablename> AS <tablename> FROM <location...>
+//         [ AS OF SYSTEM TIME <expr> ]
+//         [ WITH <option> [= <value>] [, ...] ]
 //
 // Targets:
 //    TABLE <pattern> [, ...]
@@ -2603,6 +2722,27 @@ restore_stmt:
       Options: *($8.restoreOptions()),
     }
   }
+| RESTORE TABLE table_name AS table_name FROM list_of_string_or_placeholder_opt_list opt_as_of_clause opt_with_restore_options
+  {
+    $$.val = &tree.Restore{
+      Targets: tree.TargetList{Tables: tree.TablePatterns{$3.unresolvedObjectName().ToUnresolvedName()}},
+      AsTable: $5.unresolvedObjectName(),
+      From: $7.listOfStringOrPlaceholderOptList(),
+      AsOf: $8.asOfClause(),
+      Options: *($9.restoreOptions()),
+    }
+  }
+| RESTORE TABLE table_name AS table_name FROM string_or_placeholder IN list_of_string_or_placeholder_opt_list opt_as_of_clause opt_with_restore_options
+  {
+    $$.val = &tree.Restore{
+      Targets: tree.TargetList{Tables: tree.TablePatterns{$3.unresolvedObjectName().ToUnresolvedName()}},
+      AsTable: $5.unresolvedObjectName(),
+      Subdir: $7.expr(),
+      From: $9.listOfStringOrPlaceholderOptList(),
+      AsOf: $10.asOfClause(),
+      Options: *($11.restoreOptions()),
+    }
+  }
 | RESTORE error // SHOW HELP: RESTORE
 
 string_or_placeholder_opt_list:
@@ -3099,8 +3239,6 @@ create_unsupported:
 | CREATE DEFAULT CONVERSION error { return unimplemented(sqllex, "create def conv") }
 | CREATE FOREIGN TABLE error { return unimplemented(sqllex, "create foreign table") }
 | CREATE FOREIGN DATA error { return unimplemented(sqllex, "create fdw") }
-| CREATE FUNCTION error { return unimplementedWithIssueDetail(sqllex, 17511, "create function") }
-| CREATE OR REPLACE FUNCTION error { return unimplementedWithIssueDetail(sqllex, 17511, "create function") }
 | CREATE opt_or_replace opt_trusted opt_procedural LANGUAGE name error { return unimplementedWithIssueDetail(sqllex, 17511, "create language " + $6) }
 | CREATE OPERATOR error { return unimplemented(sqllex, "create operator") }
 | CREATE PUBLICATION error { return unimplemented(sqllex, "create publication") }
@@ -3109,7 +3247,6 @@ create_unsupported:
 | CREATE SUBSCRIPTION error { return unimplemented(sqllex, "create subscription") }
 | CREATE TABLESPACE error { return unimplementedWithIssueDetail(sqllex, 54113, "create tablespace") }
 | CREATE TEXT error { return unimplementedWithIssueDetail(sqllex, 7821, "create text") }
-| CREATE TRIGGER error { return unimplementedWithIssueDetail(sqllex, 28296, "create") }
 
 opt_or_replace:
   OR REPLACE {}
@@ -3129,12 +3266,10 @@ drop_unsupported:
 | DROP CAST error { return unimplemented(sqllex, "drop cast") }
 | DROP COLLATION error { return unimplemented(sqllex, "drop collation") }
 | DROP CONVERSION error { return unimplemented(sqllex, "drop conversion") }
-| DROP DOMAIN error { return unimplementedWithIssueDetail(sqllex, 27796, "drop") }
 | DROP EXTENSION IF EXISTS name error { return unimplemented(sqllex, "drop extension " + $5) }
 | DROP EXTENSION name error { return unimplemented(sqllex, "drop extension " + $3) }
 | DROP FOREIGN TABLE error { return unimplemented(sqllex, "drop foreign table") }
 | DROP FOREIGN DATA error { return unimplemented(sqllex, "drop fdw") }
-| DROP FUNCTION error { return unimplementedWithIssueDetail(sqllex, 17511, "drop function") }
 | DROP opt_procedural LANGUAGE name error { return unimplementedWithIssueDetail(sqllex, 17511, "drop language " + $4) }
 | DROP OPERATOR error { return unimplemented(sqllex, "drop operator") }
 | DROP PUBLICATION error { return unimplemented(sqllex, "drop publication") }
@@ -3142,7 +3277,6 @@ drop_unsupported:
 | DROP SERVER error { return unimplemented(sqllex, "drop server") }
 | DROP SUBSCRIPTION error { return unimplemented(sqllex, "drop subscription") }
 | DROP TEXT error { return unimplementedWithIssueDetail(sqllex, 7821, "drop text") }
-| DROP TRIGGER error { return unimplementedWithIssueDetail(sqllex, 28296, "drop") }
 
 create_ddl_stmt:
   create_changefeed_stmt
@@ -3154,6 +3288,9 @@ create_ddl_stmt:
 // Error case for both CREATE TABLE and CREATE TABLE ... AS in one
 | CREATE opt_persistence_temp_table TABLE error   // SHOW HELP: CREATE TABLE
 | create_type_stmt     // EXTEND WITH HELP: CREATE TYPE
+| create_domain_stmt   // EXTEND WITH HELP: CREATE DOMAIN
+| create_func_stmt     // EXTEND WITH HELP: CREATE FUNCTION
+| create_trigger_stmt  // EXTEND WITH HELP: CREATE TRIGGER
 | create_view_stmt     // EXTEND WITH HELP: CREATE VIEW
 | create_sequence_stmt // EXTEND WITH HELP: CREATE SEQUENCE
 
@@ -3256,7 +3393,7 @@ create_changefeed_stmt:
   CREATE CHANGEFEED FOR changefeed_targets opt_changefeed_sink opt_with_options
   {
     $$.val = &tree.CreateChangefeed{
-      Targets: $4.targetList(),
+      Targets: $4.changefeedTargets(),
       SinkURI: $5.expr(),
       Options: $6.kvOptions(),
     }
@@ -3265,32 +3402,55 @@ create_changefeed_stmt:
   {
     /* SKIP DOC */
     $$.val = &tree.CreateChangefeed{
-      Targets: $4.targetList(),
+      Targets: $4.changefeedTargets(),
       Options: $5.kvOptions(),
     }
   }
+| CREATE CHANGEFEED opt_changefeed_sink opt_with_options AS SELECT target_list FROM table_name opt_where_clause
+  {
+    name := $9.unresolvedObjectName().ToTableName()
+    $$.val = &tree.CreateChangefeed{
+      Targets: tree.ChangefeedTargets{{TableName: $9.unresolvedObjectName().ToUnresolvedName()}},
+      SinkURI: $3.expr(),
+      Options: $4.kvOptions(),
+      Select: &tree.SelectClause{
+        Exprs: $7.selExprs(),
+        From:  tree.From{Tables: tree.TableExprs{&tree.AliasedTableExpr{Expr: &name}}},
+        Where: tree.NewWhere(tree.AstWhere, $10.expr()),
+      },
+    }
+  }
 
 changefeed_targets:
-  single_table_pattern_list
+  changefeed_target_list
+| TABLE changefeed_target_list
   {
-    $$.val = tree.TargetList{Tables: $1.tablePatterns()}
+    $$.val = $2.changefeedTargets()
   }
-| TABLE single_table_pattern_list
+
+changefeed_target_list:
+  changefeed_target
   {
-    $$.val = tree.TargetList{Tables: $2.tablePatterns()}
+    $$.val = tree.ChangefeedTargets{$1.changefeedTarget()}
+  }
+| changefeed_target_list ',' changefeed_target
+  {
+    $$.val = append($1.changefeedTargets(), $3.changefeedTarget())
   }
 
-single_table_pattern_list:
+changefeed_target:
   table_name
   {
-    $$.val = tree.TablePatterns{$1.unresolvedObjectName().ToUnresolvedName()}
+    $$.val = tree.ChangefeedTarget{TableName: $1.unresolvedObjectName().ToUnresolvedName()}
   }
-| single_table_pattern_list ',' table_name
+| table_name FAMILY family_name
   {
-    $$.val = append($1.tablePatterns(), $3.unresolvedObjectName().ToUnresolvedName())
+    $$.val = tree.ChangefeedTarget{
+      TableName:  $1.unresolvedObjectName().ToUnresolvedName(),
+      FamilyName: tree.Name($3),
+    }
   }
 
-
 opt_changefeed_sink:
   INTO string_or_placeholder
   {
@@ -3304,7 +3464,7 @@ opt_changefeed_sink:
 
 // %Help: DELETE - delete rows from a table
 // %Category: DML
-// %Text: DELETE FROM <tablename> [WHERE <expr>]
+// %Text: DELETE FROM <tablename> [USING <tablerefs...>] [WHERE <expr>]
 //               [ORDER BY <exprs...>]
 //               [LIMIT <expr>]
 //               [RETURNING <exprs...>]
@@ -3315,6 +3475,7 @@ delete_stmt:
     $$.val = &tree.Delete{
       With: $1.with(),
       Table: $4.tblExpr(),
+      Using: $5.tblExprs(),
       Where: tree.NewWhere(tree.AstWhere, $6.expr()),
       OrderBy: $7.orderBy(),
       Limit: $8.limit(),
@@ -3324,8 +3485,14 @@ delete_stmt:
 | opt_with_clause DELETE error // SHOW HELP: DELETE
 
 opt_using_clause:
-  USING from_list { return unimplementedWithIssueDetail(sqllex, 40963, "delete using") }
-| /* EMPTY */ { }
+  USING from_list
+  {
+    $$.val = $2.tblExprs()
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.TableExprs{}
+  }
 
 
 // %Help: DISCARD - reset the session to its initial state
@@ -3342,6 +3509,47 @@ discard_stmt:
 | DISCARD TEMPORARY { return unimplemented(sqllex, "discard temp") }
 | DISCARD error // SHOW HELP: DISCARD
 
+// %Help: LISTEN - listen for notifications on a channel
+// %Category: Misc
+// %Text: LISTEN <channel>
+// %SeeAlso: NOTIFY, UNLISTEN
+listen_stmt:
+  LISTEN name
+  {
+    $$.val = &tree.Listen{ChannelName: tree.Name($2)}
+  }
+| LISTEN error // SHOW HELP: LISTEN
+
+// %Help: NOTIFY - send a notification on a channel
+// %Category: Misc
+// %Text: NOTIFY <channel> [, <payload>]
+// %SeeAlso: LISTEN, UNLISTEN
+notify_stmt:
+  NOTIFY name
+  {
+    $$.val = &tree.Notify{ChannelName: tree.Name($2)}
+  }
+| NOTIFY name ',' SCONST
+  {
+    $$.val = &tree.Notify{ChannelName: tree.Name($2), Payload: $4}
+  }
+| NOTIFY error // SHOW HELP: NOTIFY
+
+// %Help: UNLISTEN - stop listening for notifications
+// %Category: Misc
+// %Text: UNLISTEN { <channel> | * }
+// %SeeAlso: LISTEN, NOTIFY
+unlisten_stmt:
+  UNLISTEN name
+  {
+    $$.val = &tree.Unlisten{ChannelName: tree.Name($2)}
+  }
+| UNLISTEN '*'
+  {
+    $$.val = &tree.Unlisten{}
+  }
+| UNLISTEN error // SHOW HELP: UNLISTEN
+
 // %Help: DROP
 // %Category: Group
 // %Text:
@@ -3362,6 +3570,9 @@ drop_ddl_stmt:
 | drop_sequence_stmt // EXTEND WITH HELP: DROP SEQUENCE
 | drop_schema_stmt   // EXTEND WITH HELP: DROP SCHEMA
 | drop_type_stmt     // EXTEND WITH HELP: DROP TYPE
+| drop_domain_stmt   // EXTEND WITH HELP: DROP DOMAIN
+| drop_func_stmt     // EXTEND WITH HELP: DROP FUNCTION
+| drop_trigger_stmt  // EXTEND WITH HELP: DROP TRIGGER
 
 // %Help: DROP VIEW - remove a view
 // %Category: DDL
@@ -3496,6 +3707,107 @@ drop_type_stmt:
   }
 | DROP TYPE error // SHOW HELP: DROP TYPE
 
+// %Help: DROP DOMAIN - remove a domain
+// %Category: DDL
+// %Text: DROP DOMAIN [IF EXISTS] <type_name> [, ...] [CASCADE | RESTRICT]
+// %SeeAlso: CREATE DOMAIN
+drop_domain_stmt:
+  DROP DOMAIN type_name_list opt_drop_behavior
+  {
+    $$.val = &tree.DropDomain{
+      Names: $3.unresolvedObjectNames(),
+      IfExists: false,
+      DropBehavior: $4.dropBehavior(),
+    }
+  }
+| DROP DOMAIN IF EXISTS type_name_list opt_drop_behavior
+  {
+    $$.val = &tree.DropDomain{
+      Names: $5.unresolvedObjectNames(),
+      IfExists: true,
+      DropBehavior: $6.dropBehavior(),
+    }
+  }
+| DROP DOMAIN error // SHOW HELP: DROP DOMAIN
+
+// %Help: DROP FUNCTION - remove a user defined function
+// %Category: DDL
+// %Text: DROP FUNCTION [IF EXISTS] <func_name> [ ( [ <argtype> [, ...] ] ) ] [, ...] [CASCADE | RESTRICT]
+// %SeeAlso: CREATE FUNCTION
+drop_func_stmt:
+  DROP FUNCTION func_obj_list opt_drop_behavior
+  {
+    $$.val = &tree.DropFunction{
+      Functions: $3.funcObjs(),
+      IfExists: false,
+      DropBehavior: $4.dropBehavior(),
+    }
+  }
+| DROP FUNCTION IF EXISTS func_obj_list opt_drop_behavior
+  {
+    $$.val = &tree.DropFunction{
+      Functions: $5.funcObjs(),
+      IfExists: true,
+      DropBehavior: $6.dropBehavior(),
+    }
+  }
+| DROP FUNCTION error // SHOW HELP: DROP FUNCTION
+
+// %Help: DROP TRIGGER - remove a trigger
+// %Category: DDL
+// %Text: DROP TRIGGER [IF EXISTS] <trigger_name> ON <tablename> [CASCADE | RESTRICT]
+// %SeeAlso: CREATE TRIGGER
+drop_trigger_stmt:
+  DROP TRIGGER name ON table_name opt_drop_behavior
+  {
+    $$.val = &tree.DropTrigger{
+      Name: tree.Name($3),
+      Table: $5.unresolvedObjectName().ToTableName(),
+      IfExists: false,
+      DropBehavior: $6.dropBehavior(),
+    }
+  }
+| DROP TRIGGER IF EXISTS name ON table_name opt_drop_behavior
+  {
+    $$.val = &tree.DropTrigger{
+      Name: tree.Name($5),
+      Table: $7.unresolvedObjectName().ToTableName(),
+      IfExists: true,
+      DropBehavior: $8.dropBehavior(),
+    }
+  }
+| DROP TRIGGER error // SHOW HELP: DROP TRIGGER
+
+func_obj_list:
+  func_obj
+  {
+    $$.val = []tree.FuncObj{$1.funcObj()}
+  }
+| func_obj_list ',' func_obj
+  {
+    $$.val = append($1.funcObjs(), $3.funcObj())
+  }
+
+func_obj:
+  db_object_name opt_func_arg_types
+  {
+    $$.val = tree.FuncObj{Name: $1.unresolvedObjectName(), ArgTypes: $2.typeReferences()}
+  }
+
+opt_func_arg_types:
+  '(' type_list ')'
+  {
+    $$.val = $2.typeReferences()
+  }
+| '(' ')'
+  {
+    $$.val = []tree.ResolvableTypeReference{}
+  }
+| /* EMPTY */
+  {
+    $$.val = []tree.ResolvableTypeReference(nil)
+  }
+
 target_types:
   type_name_list
   {
@@ -3512,6 +3824,16 @@ type_name_list:
     $$.val = append($1.unresolvedObjectNames(), $3.unresolvedObjectName())
   }
 
+func_name_list:
+  db_object_name
+  {
+    $$.val = []*tree.UnresolvedObjectName{$1.unresolvedObjectName()}
+  }
+| func_name_list ',' db_object_name
+  {
+    $$.val = append($1.unresolvedObjectNames(), $3.unresolvedObjectName())
+  }
+
 // %Help: DROP SCHEMA - remove a schema
 // %Category: DDL
 // %Text: DROP SCHEMA [IF EXISTS] <schema_name> [, ...] [CASCADE | RESTRICT]
@@ -3666,6 +3988,7 @@ preparable_stmt:
   alter_stmt     // help texts in sub-rule
 | backup_stmt    // EXTEND WITH HELP: BACKUP
 | cancel_stmt    // help texts in sub-rule
+| compact_backup_stmt // EXTEND WITH HELP: COMPACT BACKUP
 | create_stmt    // help texts in sub-rule
 | delete_stmt    // EXTEND WITH HELP: DELETE
 | drop_stmt      // help texts in sub-rule
@@ -3811,13 +4134,14 @@ deallocate_stmt:
 //   GRANT <roles...> TO <grantees...> [WITH ADMIN OPTION]
 //
 // Privileges:
-//   CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE
+//   CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE, EXECUTE
 //
 // Targets:
 //   DATABASE <databasename> [, ...]
 //   [TABLE] [<databasename> .] { <tablename> | * } [, ...]
 //   TYPE <typename> [, <typename>]...
 //   SCHEMA [<databasename> .]<schemaname> [, [<databasename> .]<schemaname>]...
+//   FUNCTION [<databasename> .][<schemaname> .]<funcname> [, ...]
 //
 // %SeeAlso: REVOKE, WEBDOCS/grant.html
 grant_stmt:
@@ -3847,6 +4171,16 @@ grant_stmt:
       Grantees: $7.nameList(),
     }
   }
+| GRANT privileges ON FUNCTION func_name_list TO name_list
+  {
+    $$.val = &tree.Grant{
+      Privileges: $2.privilegeList(),
+      Targets: tree.TargetList{
+        Functions: $5.unresolvedObjectNames(),
+      },
+      Grantees: $7.nameList(),
+    }
+  }
 | GRANT error // SHOW HELP: GRANT
 
 // %Help: REVOKE - remove access privileges and role memberships
@@ -3858,13 +4192,14 @@ grant_stmt:
 //   REVOKE [ADMIN OPTION FOR] <roles...> FROM <grantees...>
 //
 // Privileges:
-//   CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE
+//   CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE, EXECUTE
 //
 // Targets:
 //   DATABASE <databasename> [, <databasename>]...
 //   [TABLE] [<databasename> .] { <tablename> | * } [, ...]
 //   TYPE <typename> [, <typename>]...
 //   SCHEMA [<databasename> .]<schemaname> [, [<databasename> .]<schemaname]...
+//   FUNCTION [<databasename> .][<schemaname> .]<funcname> [, ...]
 //
 // %SeeAlso: GRANT, WEBDOCS/revoke.html
 revoke_stmt:
@@ -3894,6 +4229,16 @@ revoke_stmt:
       Grantees: $7.nameList(),
     }
   }
+| REVOKE privileges ON FUNCTION func_name_list FROM name_list
+  {
+    $$.val = &tree.Revoke{
+      Privileges: $2.privilegeList(),
+      Targets: tree.TargetList{
+        Functions: $5.unresolvedObjectNames(),
+      },
+      Grantees: $7.nameList(),
+    }
+  }
 | REVOKE error // SHOW HELP: REVOKE
 
 // ALL can either be by itself, or with the optional PRIVILEGES keyword (which no-ops)
@@ -3978,8 +4323,7 @@ use_stmt:
 nonpreparable_set_stmt:
   set_transaction_stmt // EXTEND WITH HELP: SET TRANSACTION
 | set_exprs_internal   { /* SKIP DOC */ }
-| SET CONSTRAINTS error { return unimplemented(sqllex, "set constraints") }
-| SET LOCAL error { return unimplementedWithIssue(sqllex, 32562) }
+| set_constraints_stmt // EXTEND WITH HELP: SET CONSTRAINTS
 
 // SET SESSION / SET CLUSTER SETTING
 preparable_set_stmt:
@@ -4116,11 +4460,14 @@ set_exprs_internal:
 // %Help: SET SESSION - change a session variable
 // %Category: Cfg
 // %Text:
-// SET [SESSION] <var> { TO | = } <values...>
-// SET [SESSION] TIME ZONE <tz>
+// SET [SESSION | LOCAL] <var> { TO | = } <values...>
+// SET [SESSION | LOCAL] TIME ZONE <tz>
 // SET [SESSION] CHARACTERISTICS AS TRANSACTION ISOLATION LEVEL { SNAPSHOT | SERIALIZABLE }
 // SET [SESSION] TRACING { TO | = } { on | off | cluster | kv | results } [,...]
 //
+// The effects of SET LOCAL only last until the end of the current
+// transaction.
+//
 // %SeeAlso: SHOW SESSION, RESET, DISCARD, SHOW, SET CLUSTER SETTING, SET TRANSACTION,
 // WEBDOCS/set-vars.html
 set_session_stmt:
@@ -4132,6 +4479,15 @@ set_session_stmt:
   {
     $$.val = $2.stmt()
   }
+| SET LOCAL set_rest_more
+  {
+    setVar, ok := $3.stmt().(*tree.SetVar)
+    if !ok {
+      return unimplementedWithIssueDetail(sqllex, 32562, "set local")
+    }
+    setVar.Local = true
+    $$.val = setVar
+  }
 // Special form for pg compatibility:
 | SET SESSION CHARACTERISTICS AS TRANSACTION transaction_mode_list
   {
@@ -4163,6 +4519,37 @@ set_transaction_stmt:
   }
 | SET SESSION TRANSACTION error // SHOW HELP: SET TRANSACTION
 
+// %Help: SET CONSTRAINTS - set when the checks of deferrable constraints run
+// %Category: Txn
+// %Text:
+// SET CONSTRAINTS { ALL | <name> [, ...] } { DEFERRED | IMMEDIATE }
+//
+// The checks of the deferrable constraints that are DEFERRED run when the
+// transaction commits instead of at the end of each statement. Pending checks
+// of constraints made IMMEDIATE run when SET CONSTRAINTS is executed.
+//
+// %SeeAlso: SET TRANSACTION, CREATE TABLE
+set_constraints_stmt:
+  SET CONSTRAINTS ALL constraints_set_mode
+  {
+    $$.val = &tree.SetConstraints{Deferred: $4.bool()}
+  }
+| SET CONSTRAINTS name_list constraints_set_mode
+  {
+    $$.val = &tree.SetConstraints{Names: $3.nameList(), Deferred: $4.bool()}
+  }
+| SET CONSTRAINTS error // SHOW HELP: SET CONSTRAINTS
+
+constraints_set_mode:
+  DEFERRED
+  {
+    $$.val = true
+  }
+| IMMEDIATE
+  {
+    $$.val = false
+  }
+
 generic_set:
   var_name to_or_eq var_list
   {
@@ -4390,14 +4777,189 @@ show_stmt:
 | SHOW error                // SHOW HELP: SHOW
 | show_last_query_stats_stmt
 
-// Cursors are not yet supported by CockroachDB. CLOSE ALL is safe to no-op
-// since there will be no open cursors.
-close_cursor_stmt:
-	CLOSE ALL { }
-| CLOSE cursor_name { return unimplementedWithIssue(sqllex, 41412) }
-
+// %Help: DECLARE - declare a cursor
+// %Category: Misc
+// %Text:
+// DECLARE <name> [NO SCROLL] CURSOR [WITHOUT HOLD] FOR <selectclause>
+//
+// Cursors can only be declared in explicit transactions and are closed when
+// the transaction ends. They can only move forward. WITH HOLD and SCROLL
+// cursors are not supported.
+// %SeeAlso: FETCH, MOVE, CLOSE
 declare_cursor_stmt:
-	DECLARE { return unimplementedWithIssue(sqllex, 41412) }
+  DECLARE cursor_name opt_no_scroll CURSOR opt_hold FOR select_stmt
+  {
+    if $5.bool() {
+      return unimplementedWithIssueDetail(sqllex, 41412, "with hold")
+    }
+    $$.val = &tree.DeclareCursor{Name: tree.Name($2), Select: $7.slct()}
+  }
+| DECLARE cursor_name SCROLL error
+  {
+    return unimplementedWithIssueDetail(sqllex, 41412, "scroll")
+  }
+| DECLARE cursor_name BINARY error
+  {
+    return unimplementedWithIssueDetail(sqllex, 41412, "binary")
+  }
+| DECLARE error // SHOW HELP: DECLARE
+
+opt_no_scroll:
+  NO SCROLL {}
+| /* EMPTY */ {}
+
+opt_hold:
+  WITH HOLD
+  {
+    $$.val = true
+  }
+| WITHOUT HOLD
+  {
+    $$.val = false
+  }
+| /* EMPTY */
+  {
+    $$.val = false
+  }
+
+// %Help: FETCH - retrieve rows from a cursor
+// %Category: Misc
+// %Text:
+// FETCH [<direction>] [FROM | IN] <name>
+//
+// Direction:
+//   NEXT | FIRST | ABSOLUTE <count> | RELATIVE <count>
+//   <count> | ALL | FORWARD | FORWARD <count> | FORWARD ALL
+// %SeeAlso: DECLARE, MOVE, CLOSE
+fetch_cursor_stmt:
+  FETCH fetch_args
+  {
+    $$.val = &tree.FetchCursor{CursorStmt: $2.cursorStmt()}
+  }
+| FETCH error // SHOW HELP: FETCH
+
+// %Help: MOVE - move a cursor without retrieving rows
+// %Category: Misc
+// %Text:
+// MOVE [<direction>] [FROM | IN] <name>
+//
+// The directions are the same as for FETCH.
+// %SeeAlso: DECLARE, FETCH, CLOSE
+move_cursor_stmt:
+  MOVE fetch_args
+  {
+    $$.val = &tree.MoveCursor{CursorStmt: $2.cursorStmt()}
+  }
+| MOVE error // SHOW HELP: MOVE
+
+fetch_args:
+  cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($1), Direction: tree.FetchForward, Count: 1}
+  }
+| from_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($2), Direction: tree.FetchForward, Count: 1}
+  }
+| NEXT opt_from_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($3), Direction: tree.FetchForward, Count: 1}
+  }
+| PRIOR opt_from_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($3), Direction: tree.FetchBackward, Count: 1}
+  }
+| FIRST opt_from_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($3), Direction: tree.FetchAbsolute, Count: 1}
+  }
+| LAST opt_from_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($3), Direction: tree.FetchAbsolute, Count: -1}
+  }
+| ABSOLUTE signed_iconst opt_from_in cursor_name
+  {
+    count, err := $2.numVal().AsInt64()
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = tree.CursorStmt{Name: tree.Name($4), Direction: tree.FetchAbsolute, Count: count}
+  }
+| RELATIVE signed_iconst opt_from_in cursor_name
+  {
+    count, err := $2.numVal().AsInt64()
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = tree.CursorStmt{Name: tree.Name($4), Direction: tree.FetchRelative, Count: count}
+  }
+| signed_iconst opt_from_in cursor_name
+  {
+    count, err := $1.numVal().AsInt64()
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = tree.CursorStmt{Name: tree.Name($3), Direction: tree.FetchForward, Count: count}
+  }
+| ALL opt_from_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($3), Direction: tree.FetchForward, Count: tree.FetchAll}
+  }
+| FORWARD opt_from_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($3), Direction: tree.FetchForward, Count: 1}
+  }
+| FORWARD signed_iconst opt_from_in cursor_name
+  {
+    count, err := $2.numVal().AsInt64()
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = tree.CursorStmt{Name: tree.Name($4), Direction: tree.FetchForward, Count: count}
+  }
+| FORWARD ALL opt_from_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($4), Direction: tree.FetchForward, Count: tree.FetchAll}
+  }
+| BACKWARD opt_from_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($3), Direction: tree.FetchBackward, Count: 1}
+  }
+| BACKWARD signed_iconst opt_from_in cursor_name
+  {
+    count, err := $2.numVal().AsInt64()
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = tree.CursorStmt{Name: tree.Name($4), Direction: tree.FetchBackward, Count: count}
+  }
+| BACKWARD ALL opt_from_in cursor_name
+  {
+    $$.val = tree.CursorStmt{Name: tree.Name($4), Direction: tree.FetchBackward, Count: tree.FetchAll}
+  }
+
+from_in:
+  FROM {}
+| IN {}
+
+opt_from_in:
+  from_in {}
+| /* EMPTY */ {}
+
+// %Help: CLOSE - close a cursor
+// %Category: Misc
+// %Text: CLOSE { <name> | ALL }
+// %SeeAlso: DECLARE, FETCH, MOVE
+close_cursor_stmt:
+  CLOSE ALL
+  {
+    $$.val = &tree.CloseCursor{All: true}
+  }
+| CLOSE cursor_name
+  {
+    $$.val = &tree.CloseCursor{Name: tree.Name($2)}
+  }
+| CLOSE error // SHOW HELP: CLOSE
 
 reindex_stmt:
   REINDEX TABLE error
@@ -4493,7 +5055,9 @@ show_histogram_stmt:
 
 // %Help: SHOW BACKUP - list backup contents
 // %Category: CCL
-// %Text: SHOW BACKUP [SCHEMAS|FILES|RANGES] <location>
+// %Text:
+// SHOW BACKUP [SCHEMAS|FILES|RANGES|VALIDATE] <location>
+// SHOW BACKUP ROWS FOR TABLE <tablename> KEYS (<key> [, ...]) FROM <location>
 // %SeeAlso: WEBDOCS/show-backup.html
 show_backup_stmt:
   SHOW BACKUPS IN string_or_placeholder
@@ -4546,6 +5110,35 @@ show_backup_stmt:
       Options: $5.kvOptions(),
     }
   }
+| SHOW BACKUP VALIDATE string_or_placeholder opt_with_options
+  {
+    $$.val = &tree.ShowBackup{
+      Details: tree.BackupValidateDetails,
+      Path:    $4.expr(),
+      Options: $5.kvOptions(),
+    }
+  }
+| SHOW BACKUP ROWS FOR TABLE table_name KEYS '(' expr_list ')' FROM string_or_placeholder opt_with_options
+  {
+    $$.val = &tree.ShowBackup{
+      Details: tree.BackupRowDetails,
+      Table:   $6.unresolvedObjectName(),
+      Keys:    $9.exprs(),
+      Path:    $12.expr(),
+      Options: $13.kvOptions(),
+    }
+  }
+| SHOW BACKUP ROWS FOR TABLE table_name KEYS '(' expr_list ')' FROM string_or_placeholder IN string_or_placeholder opt_with_options
+  {
+    $$.val = &tree.ShowBackup{
+      Details:      tree.BackupRowDetails,
+      Table:        $6.unresolvedObjectName(),
+      Keys:         $9.exprs(),
+      Path:         $12.expr(),
+      InCollection: $14.expr(),
+      Options:      $15.kvOptions(),
+    }
+  }
 | SHOW BACKUP error // SHOW HELP: SHOW BACKUP
 
 // %Help: SHOW CLUSTER SETTING - display cluster settings
@@ -5031,15 +5624,21 @@ show_transaction_stmt:
   }
 | SHOW TRANSACTION error // SHOW HELP: SHOW TRANSACTION
 
-// %Help: SHOW CREATE - display the CREATE statement for a table, sequence or view
+// %Help: SHOW CREATE - display the CREATE statement for a table, sequence, view or function
 // %Category: DDL
-// %Text: SHOW CREATE [ TABLE | SEQUENCE | VIEW ] <tablename>
+// %Text:
+// SHOW CREATE [ TABLE | SEQUENCE | VIEW ] <tablename>
+// SHOW CREATE FUNCTION <func_name>
 // %SeeAlso: WEBDOCS/show-create-table.html
 show_create_stmt:
   SHOW CREATE table_name
   {
     $$.val = &tree.ShowCreate{Name: $3.unresolvedObjectName()}
   }
+| SHOW CREATE FUNCTION db_object_name
+  {
+    $$.val = &tree.ShowCreateFunction{Name: $4.unresolvedObjectName()}
+  }
 | SHOW CREATE create_kw table_name
   {
     /* SKIP DOC */
@@ -6111,7 +6710,7 @@ col_qualification_elem:
   {
     $$.val = &tree.ColumnDefault{Expr: $2.expr()}
   }
-| REFERENCES table_name opt_name_parens key_match reference_actions
+| REFERENCES table_name opt_name_parens key_match reference_actions opt_deferrable
  {
     name := $2.unresolvedObjectName().ToTableName()
     $$.val = &tree.ColumnFKConstraint{
@@ -6119,6 +6718,7 @@ col_qualification_elem:
       Col: tree.Name($3),
       Actions: $5.referenceActions(),
       Match: $4.compositeKeyMatchMethod(),
+      Deferrability: $6.constraintDeferrability(),
     }
  }
 | generated_as '(' a_expr ')' STORED
@@ -6217,6 +6817,9 @@ table_constraint:
 constraint_elem:
   CHECK '(' a_expr ')' opt_deferrable
   {
+    if $5.constraintDeferrability() != tree.ConstraintNotDeferrable {
+      return purposelyUnimplemented(sqllex, "check deferrable", "CHECK constraints cannot be marked DEFERRABLE")
+    }
     $$.val = &tree.CheckConstraintTableDef{
       Expr: $3.expr(),
     }
@@ -6233,6 +6836,7 @@ constraint_elem:
         PartitionBy: $8.partitionBy(),
         Predicate: $10.expr(),
       },
+      Deferrability: $9.constraintDeferrability(),
     }
   }
 | PRIMARY KEY '(' index_params ')' opt_hash_sharded opt_interleave
@@ -6256,13 +6860,49 @@ constraint_elem:
       ToCols: $8.nameList(),
       Match: $9.compositeKeyMatchMethod(),
       Actions: $10.referenceActions(),
+      Deferrability: $11.constraintDeferrability(),
     }
   }
-| EXCLUDE USING error
+| EXCLUDE opt_exclude_access_method '(' exclude_elems ')' opt_where_clause
   {
-    return unimplementedWithIssueDetail(sqllex, 46657, "add constraint exclude using")
+    $$.val = &tree.ExclusionConstraintTableDef{
+      AccessMethod: tree.Name($2),
+      Elems:        $4.exclusionElems(),
+      Predicate:    $6.expr(),
+    }
+  }
+
+opt_exclude_access_method:
+  USING name
+  {
+    $$ = $2
+  }
+| /* EMPTY */
+  {
+    $$ = ""
+  }
+
+exclude_elems:
+  exclude_elem
+  {
+    $$.val = tree.ExclusionElemList{$1.exclusionElem()}
+  }
+| exclude_elems ',' exclude_elem
+  {
+    $$.val = append($1.exclusionElems(), $3.exclusionElem())
   }
 
+exclude_elem:
+  name WITH exclude_op
+  {
+    $$.val = tree.ExclusionElem{Column: tree.Name($1), Operator: $3.cmpOp()}
+  }
+
+exclude_op:
+  '='        { $$.val = tree.EQ }
+| NOT_EQUALS { $$.val = tree.NE }
+| AND_AND    { $$.val = tree.Overlaps }
+
 
 create_as_opt_col_list:
   '(' create_as_table_defs ')'
@@ -6366,12 +7006,12 @@ create_as_col_qualification_elem:
   }
 
 opt_deferrable:
-  /* EMPTY */ { /* no error */ }
-| DEFERRABLE { return unimplementedWithIssueDetail(sqllex, 31632, "deferrable") }
-| DEFERRABLE INITIALLY DEFERRED { return unimplementedWithIssueDetail(sqllex, 31632, "def initially deferred") }
-| DEFERRABLE INITIALLY IMMEDIATE { return unimplementedWithIssueDetail(sqllex, 31632, "def initially immediate") }
-| INITIALLY DEFERRED { return unimplementedWithIssueDetail(sqllex, 31632, "initially deferred") }
-| INITIALLY IMMEDIATE { return unimplementedWithIssueDetail(sqllex, 31632, "initially immediate") }
+  /* EMPTY */ { $$.val = tree.ConstraintNotDeferrable }
+| DEFERRABLE { $$.val = tree.ConstraintInitiallyImmediate }
+| DEFERRABLE INITIALLY DEFERRED { $$.val = tree.ConstraintInitiallyDeferred }
+| DEFERRABLE INITIALLY IMMEDIATE { $$.val = tree.ConstraintInitiallyImmediate }
+| INITIALLY DEFERRED { $$.val = tree.ConstraintInitiallyDeferred }
+| INITIALLY IMMEDIATE { $$.val = tree.ConstraintNotDeferrable }
 
 storing:
   COVERING
@@ -6835,9 +7475,160 @@ opt_view_recursive:
 | RECURSIVE { return unimplemented(sqllex, "create recursive view") }
 
 
+// %Help: CREATE FUNCTION - create a user defined function
+// %Category: DDL
+// %Text:
+// CREATE [OR REPLACE] FUNCTION <func_name> ( [ [<argname>] <argtype> [, ...] ] )
+//   RETURNS <rettype>
+//   LANGUAGE SQL
+//   [ IMMUTABLE | STABLE | VOLATILE ]
+//   AS '<select_stmt>'
+// %SeeAlso: DROP FUNCTION, SHOW CREATE
+create_func_stmt:
+  CREATE FUNCTION db_object_name '(' opt_func_arg_list ')' RETURNS typename func_option_list
+  {
+    n, err := tree.MakeCreateFunction($3.unresolvedObjectName(), false /* replace */, $5.funcArgs(), $8.typeReference(), $9.functionOptions())
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = n
+  }
+| CREATE OR REPLACE FUNCTION db_object_name '(' opt_func_arg_list ')' RETURNS typename func_option_list
+  {
+    n, err := tree.MakeCreateFunction($5.unresolvedObjectName(), true /* replace */, $7.funcArgs(), $10.typeReference(), $11.functionOptions())
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = n
+  }
+| CREATE FUNCTION error // SHOW HELP: CREATE FUNCTION
+| CREATE OR REPLACE FUNCTION error // SHOW HELP: CREATE FUNCTION
+
+opt_func_arg_list:
+  func_arg_list
+| /* EMPTY */
+  {
+    $$.val = tree.FuncArgs(nil)
+  }
+
+func_arg_list:
+  func_arg
+  {
+    $$.val = tree.FuncArgs{$1.funcArg()}
+  }
+| func_arg_list ',' func_arg
+  {
+    $$.val = append($1.funcArgs(), $3.funcArg())
+  }
+
+func_arg:
+  type_function_name typename
+  {
+    $$.val = tree.FuncArg{Name: tree.Name($1), Type: $2.typeReference()}
+  }
+| typename
+  {
+    $$.val = tree.FuncArg{Type: $1.typeReference()}
+  }
+
+func_option_list:
+  func_option
+  {
+    $$.val = tree.FunctionOptions{$1.functionOption()}
+  }
+| func_option_list func_option
+  {
+    $$.val = append($1.functionOptions(), $2.functionOption())
+  }
+
+func_option:
+  LANGUAGE name
+  {
+    $$.val = tree.FunctionLanguage($2)
+  }
+| IMMUTABLE
+  {
+    $$.val = tree.VolatilityImmutable
+  }
+| STABLE
+  {
+    $$.val = tree.VolatilityStable
+  }
+| VOLATILE
+  {
+    $$.val = tree.VolatilityVolatile
+  }
+| AS SCONST
+  {
+    $$.val = tree.FunctionBody($2)
+  }
+
+// %Help: CREATE TRIGGER - create a row-level trigger
+// %Category: DDL
+// %Text:
+// CREATE TRIGGER <trigger_name> { BEFORE | AFTER } <event> [ OR <event> ... ]
+//   ON <tablename> FOR EACH ROW AS '<stmt>'
+//
+// Events:
+//   INSERT, UPDATE, DELETE
+//
+// The body of a BEFORE trigger is a SELECT statement whose output columns
+// replace the columns of the same name in the new row. The body of an AFTER
+// trigger is an INSERT, UPSERT or DELETE statement. Both can refer to the
+// affected row as "new" and "old".
+// %SeeAlso: DROP TRIGGER
+create_trigger_stmt:
+  CREATE TRIGGER name trigger_action_time trigger_event_list ON table_name FOR EACH ROW AS SCONST
+  {
+    $$.val = &tree.CreateTrigger{
+      Name: tree.Name($3),
+      ActionTime: $4.triggerActionTime(),
+      Events: $5.triggerEvents(),
+      Table: $7.unresolvedObjectName().ToTableName(),
+      Body: $12,
+    }
+  }
+| CREATE TRIGGER error // SHOW HELP: CREATE TRIGGER
+
+trigger_action_time:
+  BEFORE
+  {
+    $$.val = tree.TriggerBefore
+  }
+| AFTER
+  {
+    $$.val = tree.TriggerAfter
+  }
+
+trigger_event_list:
+  trigger_event
+  {
+    $$.val = tree.TriggerEvents{$1.triggerEvent()}
+  }
+| trigger_event_list OR trigger_event
+  {
+    $$.val = append($1.triggerEvents(), $3.triggerEvent())
+  }
+
+trigger_event:
+  INSERT
+  {
+    $$.val = tree.TriggerInsert
+  }
+| UPDATE
+  {
+    $$.val = tree.TriggerUpdate
+  }
+| DELETE
+  {
+    $$.val = tree.TriggerDelete
+  }
+
 // %Help: CREATE TYPE -- create a type
 // %Category: DDL
-// %Text: CREATE TYPE [IF NOT EXISTS] <type_name> AS ENUM (...)
+// %Text:
+// CREATE TYPE [IF NOT EXISTS] <type_name> AS ENUM (...)
+// CREATE TYPE [IF NOT EXISTS] <type_name> AS ( <field_name> <type> [, ...] )
 create_type_stmt:
   // Enum types.
   CREATE TYPE type_name AS ENUM '(' opt_enum_val_list ')'
@@ -6857,17 +7648,52 @@ create_type_stmt:
       IfNotExists: true,
     }
   }
-| CREATE TYPE error // SHOW HELP: CREATE TYPE
   // Record/Composite types.
-| CREATE TYPE type_name AS '(' error      { return unimplementedWithIssue(sqllex, 27792) }
+| CREATE TYPE type_name AS '(' opt_composite_type_list ')'
+  {
+    $$.val = &tree.CreateType{
+      TypeName: $3.unresolvedObjectName(),
+      Variety: tree.Composite,
+      CompositeTypeList: $6.compositeTypeList(),
+    }
+  }
+| CREATE TYPE IF NOT EXISTS type_name AS '(' opt_composite_type_list ')'
+  {
+    $$.val = &tree.CreateType{
+      TypeName: $6.unresolvedObjectName(),
+      Variety: tree.Composite,
+      CompositeTypeList: $9.compositeTypeList(),
+      IfNotExists: true,
+    }
+  }
+| CREATE TYPE error // SHOW HELP: CREATE TYPE
   // Range types.
 | CREATE TYPE type_name AS RANGE error    { return unimplementedWithIssue(sqllex, 27791) }
   // Base (primitive) types.
 | CREATE TYPE type_name '(' error         { return unimplementedWithIssueDetail(sqllex, 27793, "base") }
   // Shell types, gateway to define base types using the previous syntax.
 | CREATE TYPE type_name                   { return unimplementedWithIssueDetail(sqllex, 27793, "shell") }
-  // Domain types.
-| CREATE DOMAIN type_name error           { return unimplementedWithIssueDetail(sqllex, 27796, "create") }
+
+// %Help: CREATE DOMAIN -- create a domain
+// %Category: DDL
+// %Text:
+// CREATE DOMAIN <type_name> [AS] <type>
+//   [DEFAULT <expr>] [[CONSTRAINT <name>] {NOT NULL | NULL | CHECK (<expr>)} ...]
+// %SeeAlso: DROP DOMAIN, CREATE TYPE
+create_domain_stmt:
+  CREATE DOMAIN type_name opt_as typename col_qual_list
+  {
+    domain, err := tree.NewCreateDomain($3.unresolvedObjectName(), $5.typeReference(), $6.colQuals())
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = domain
+  }
+| CREATE DOMAIN error // SHOW HELP: CREATE DOMAIN
+
+opt_as:
+  AS {}
+| /* EMPTY */ {}
 
 opt_enum_val_list:
   enum_val_list
@@ -6879,6 +7705,32 @@ opt_enum_val_list:
     $$.val = tree.EnumValueList(nil)
   }
 
+opt_composite_type_list:
+  composite_type_list
+  {
+    $$.val = $1.compositeTypeList()
+  }
+| /* EMPTY */
+  {
+    $$.val = []tree.CompositeTypeElem(nil)
+  }
+
+composite_type_list:
+  composite_type_elem
+  {
+    $$.val = []tree.CompositeTypeElem{$1.compositeTypeElem()}
+  }
+| composite_type_list ',' composite_type_elem
+  {
+    $$.val = append($1.compositeTypeList(), $3.compositeTypeElem())
+  }
+
+composite_type_elem:
+  name typename
+  {
+    $$.val = tree.CompositeTypeElem{Label: tree.Name($1), Type: $2.typeReference()}
+  }
+
 enum_val_list:
   SCONST
   {
@@ -11991,6 +12843,7 @@ unrestricted_name:
 // "Unreserved" keywords --- available for use as any kind of name.
 unreserved_keyword:
   ABORT
+| ABSOLUTE
 | ACTION
 | ACCESS
 | ADD
@@ -12005,6 +12858,7 @@ unreserved_keyword:
 | AUTOMATIC
 | BACKUP
 | BACKUPS
+| BACKWARD
 | BEFORE
 | BEGIN
 | BINARY
@@ -12042,6 +12896,7 @@ unreserved_keyword:
 | CREATEROLE
 | CUBE
 | CURRENT
+| CURSOR
 | CYCLE
 | DATA
 | DATABASE
@@ -12058,6 +12913,7 @@ unreserved_keyword:
 | DOMAIN
 | DOUBLE
 | DROP
+| EACH
 | ENCODING
 | ENCRYPTION_PASSPHRASE
 | ENUM
@@ -12082,6 +12938,7 @@ unreserved_keyword:
 | FIRST
 | FOLLOWING
 | FORCE_INDEX
+| FORWARD
 | FUNCTION
 | GENERATED
 | GEOMETRYM
@@ -12098,9 +12955,11 @@ unreserved_keyword:
 | HASH
 | HIGH
 | HISTOGRAM
+| HOLD
 | HOUR
 | IDENTITY
 | IMMEDIATE
+| IMMUTABLE
 | IMPORT
 | INCLUDE
 | INCLUDING
@@ -12131,6 +12990,7 @@ unreserved_keyword:
 | LEVEL
 | LINESTRING
 | LIST
+| LISTEN
 | LOCAL
 | LOCKED
 | LOGIN
@@ -12145,6 +13005,7 @@ unreserved_keyword:
 | MINUTE
 | MINVALUE
 | MODIFYCLUSTERSETTING
+| MOVE
 | MULTILINESTRING
 | MULTILINESTRINGM
 | MULTILINESTRINGZ
@@ -12174,6 +13035,7 @@ unreserved_keyword:
 | NOLOGIN
 | NOMODIFYCLUSTERSETTING
 | NOVIEWACTIVITY
+| NOTIFY
 | NOWAIT
 | NULLS
 | IGNORE_FOREIGN_KEYS
@@ -12208,6 +13070,7 @@ unreserved_keyword:
 | PRECEDING
 | PREPARE
 | PRESERVE
+| PRIOR
 | PRIORITY
 | PRIVILEGES
 | PUBLIC
@@ -12226,6 +13089,7 @@ unreserved_keyword:
 | REGIONAL
 | REGIONS
 | REINDEX
+| RELATIVE
 | RELEASE
 | RENAME
 | REPEATABLE
@@ -12235,6 +13099,7 @@ unreserved_keyword:
 | RESTRICT
 | RESUME
 | RETRY
+| RETURNS
 | REVISION_HISTORY
 | REVOKE
 | ROLE
@@ -12246,6 +13111,7 @@ unreserved_keyword:
 | RUNNING
 | SCHEDULE
 | SCHEDULES
+| SCROLL
 | SETTING
 | SETTINGS
 | STATUS
@@ -12275,6 +13141,7 @@ unreserved_keyword:
 | SNAPSHOT
 | SPLIT
 | SQL
+| STABLE
 | START
 | STATISTICS
 | STDIN
@@ -12309,6 +13176,7 @@ unreserved_keyword:
 | UNBOUNDED
 | UNCOMMITTED
 | UNKNOWN
+| UNLISTEN
 | UNLOGGED
 | UNSPLIT
 | UNTIL
@@ -12322,6 +13190,7 @@ unreserved_keyword:
 | VARYING
 | VIEW
 | VIEWACTIVITY
+| VOLATILE
 | WITHIN
 | WITHOUT
 | WRITE
@@ -706,7 +706,6 @@ var (
 
 	// Avoid unused warning for constants.
 	_ = conTypeTrigger
-	_ = conTypeExclusion
 
 	fkActionNone       = tree.NewDString("a")
 	fkActionRestrict   = tree.NewDString("r")
@@ -850,6 +849,10 @@ func populateTableConstraints(
 				}
 				f.WriteString(strings.Join(colNames, ", "))
 				f.WriteByte(')')
+				if d := con.UniqueWithoutIndexConstraint.Deferrability; d != descpb.ConstraintDeferrability_NotDeferrable {
+					f.WriteByte(' ')
+					f.WriteString(descpb.ConstraintDeferrabilityType[d].String())
+				}
 			} else {
 				return errors.AssertionFailedf(
 					"Index or UniqueWithoutIndexConstraint must be non-nil for a unique constraint",
@@ -874,15 +877,30 @@ func populateTableConstraints(
 				validity = " NOT VALID"
 			}
 			condef = tree.NewDString(fmt.Sprintf("CHECK ((%s))%s", displayExpr, validity))
+
+		case descpb.ConstraintTypeExclusion:
+			oid = h.ExclusionConstraintOid(db.GetID(), scName, table.GetID(), con.ExclusionConstraint)
+			contype = conTypeExclusion
+			if con.Index != nil {
+				conindid = h.IndexOid(table.GetID(), con.Index.ID)
+			}
+			if conkey, err = colIDArrayToDatum(con.ExclusionConstraint.ColumnIDs); err != nil {
+				return err
+			}
+			condef = tree.NewDString(con.Details)
 		}
 
+		deferrability := con.Deferrability()
+		condeferrable := tree.MakeDBool(tree.DBool(deferrability != descpb.ConstraintDeferrability_NotDeferrable))
+		condeferred := tree.MakeDBool(tree.DBool(deferrability == descpb.ConstraintDeferrability_InitiallyDeferred))
+
 		if err := addRow(
 			oid,                  // oid
 			dNameOrNull(conName), // conname
 			namespaceOid,         // connamespace
 			contype,              // contype
-			tree.DBoolFalse,      // condeferrable
-			tree.DBoolFalse,      // condeferred
+			condeferrable,        // condeferrable
+			condeferred,          // condeferred
 			tree.MakeDBool(tree.DBool(!con.Unvalidated)), // convalidated
 			tblOid,         // conrelid
 			oidZero,        // contypid
@@ -2240,8 +2258,6 @@ var (
 	typTypeRange     = tree.NewDString("r")
 
 	// Avoid unused warning for constants.
-	_ = typTypeComposite
-	_ = typTypeDomain
 	_ = typTypePseudo
 	_ = typTypeRange
 
@@ -2263,7 +2279,6 @@ var (
 	typCategoryUnknown     = tree.NewDString("X")
 
 	// Avoid unused warning for constants.
-	_ = typCategoryComposite
 	_ = typCategoryEnum
 	_ = typCategoryGeometric
 	_ = typCategoryRange
@@ -2306,9 +2321,24 @@ func addPGTypeRow(
 		builtinPrefix = "enum_"
 		typType = typTypeEnum
 	}
+	if typ.Family() == types.TupleFamily && typ.UserDefined() {
+		typType = typTypeComposite
+		cat = typCategoryComposite
+	}
 	if cat == typCategoryPseudo {
 		typType = typTypePseudo
 	}
+	typNotNull := tree.DBoolFalse
+	typBaseType := oidZero
+	typDefault := tree.DNull
+	if domain := typ.TypeMeta.DomainData; domain != nil {
+		typType = typTypeDomain
+		typNotNull = tree.MakeDBool(tree.DBool(domain.NotNull))
+		typBaseType = tree.NewDOid(tree.DInt(domain.BaseType.Oid()))
+		if domain.DefaultExpr != nil {
+			typDefault = tree.NewDString(*domain.DefaultExpr)
+		}
+	}
 	typname := typ.PGName()
 
 	return addRow(
@@ -2338,13 +2368,13 @@ func addPGTypeRow(
 
 		tree.DNull,      // typalign
 		tree.DNull,      // typstorage
-		tree.DBoolFalse, // typnotnull
-		oidZero,         // typbasetype
+		typNotNull,      // typnotnull
+		typBaseType,     // typbasetype
 		negOneVal,       // typtypmod
 		zeroVal,         // typndims
 		typColl(typ, h), // typcollation
 		tree.DNull,      // typdefaultbin
-		tree.DNull,      // typdefault
+		typDefault,      // typdefault
 		tree.DNull,      // typacl
 	)
 }
@@ -2757,6 +2787,7 @@ const (
 	collationTypeTag
 	operatorTypeTag
 	enumEntryTypeTag
+	exclusionConstraintTypeTag
 )
 
 func (h oidHasher) writeTypeTag(tag oidTypeTag) {
@@ -2790,6 +2821,11 @@ func (h oidHasher) writeUniqueConstraint(uc *descpb.UniqueWithoutIndexConstraint
 	h.writeStr(uc.Name)
 }
 
+func (h oidHasher) writeExclusionConstraint(ec *descpb.ExclusionConstraint) {
+	h.writeUInt32(uint32(ec.IndexID))
+	h.writeStr(ec.Name)
+}
+
 func (h oidHasher) writeCheckConstraint(check *descpb.TableDescriptor_CheckConstraint) {
 	h.writeStr(check.Name)
 	h.writeStr(check.Expr)
@@ -2865,6 +2901,17 @@ func (h oidHasher) UniqueWithoutIndexConstraintOid(
 	return h.getOid()
 }
 
+func (h oidHasher) ExclusionConstraintOid(
+	dbID descpb.ID, scName string, tableID descpb.ID, ec *descpb.ExclusionConstraint,
+) *tree.DOid {
+	h.writeTypeTag(exclusionConstraintTypeTag)
+	h.writeDB(dbID)
+	h.writeSchema(scName)
+	h.writeTable(tableID)
+	h.writeExclusionConstraint(ec)
+	return h.getOid()
+}
+
 func (h oidHasher) UniqueConstraintOid(
 	dbID descpb.ID, scName string, tableID descpb.ID, indexID descpb.IndexID,
 ) *tree.DOid {
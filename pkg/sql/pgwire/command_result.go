@@ -17,6 +17,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/notify"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -57,6 +58,7 @@ type commandResult struct {
 	buffer struct {
 		notices            []pgnotice.Notice
 		paramStatusUpdates []paramStatusUpdate
+		notifications      []notify.Notification
 	}
 
 	err error
@@ -137,6 +139,12 @@ func (r *commandResult) Close(ctx context.Context, t sql.TransactionStatusIndica
 		}
 	}
 
+	for _, n := range r.buffer.notifications {
+		if err := r.conn.bufferNotification(n); err != nil {
+			panic(errors.AssertionFailedf("unexpected err when sending notification: %s", err))
+		}
+	}
+
 	// Send a completion message, specific to the type of result.
 	switch r.typ {
 	case commandComplete:
@@ -232,6 +240,11 @@ func (r *commandResult) BufferNotice(notice pgnotice.Notice) {
 	r.buffer.notices = append(r.buffer.notices, notice)
 }
 
+// BufferNotification is part of the sql.NotificationBuffer interface.
+func (r *commandResult) BufferNotification(n notify.Notification) {
+	r.buffer.notifications = append(r.buffer.notifications, n)
+}
+
 // SetColumns is part of the CommandResult interface.
 func (r *commandResult) SetColumns(ctx context.Context, cols colinfo.ResultColumns) {
 	r.assertNotReleased()
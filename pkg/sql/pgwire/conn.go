@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/notify"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
@@ -642,6 +643,14 @@ func (c *conn) bufferNotice(ctx context.Context, noticeErr pgnotice.Notice) erro
 	return writeErrFields(ctx, c.sv, noticeErr, &c.msgBuilder, &c.writerState.buf)
 }
 
+func (c *conn) bufferNotification(n notify.Notification) error {
+	c.msgBuilder.initMsg(pgwirebase.ServerMsgNotificationResponse)
+	c.msgBuilder.putInt32(n.NodeID)
+	c.msgBuilder.writeTerminatedString(n.Channel)
+	c.msgBuilder.writeTerminatedString(n.Payload)
+	return c.msgBuilder.finishMsg(&c.writerState.buf)
+}
+
 func (c *conn) sendInitialConnData(
 	ctx context.Context, sqlServer *sql.Server,
 ) (sql.ConnectionHandler, error) {
@@ -1452,6 +1461,11 @@ func (c *conn) CreateDrainResult(pos sql.CmdPos) sql.DrainResult {
 	return c.newMiscResult(pos, noCompletionMsg)
 }
 
+// CreateDeliverNotificationsResult is part of the sql.ClientComm interface.
+func (c *conn) CreateDeliverNotificationsResult(pos sql.CmdPos) sql.DeliverNotificationsResult {
+	return c.newMiscResult(pos, flush)
+}
+
 // CreateBindResult is part of the sql.ClientComm interface.
 func (c *conn) CreateBindResult(pos sql.CmdPos) sql.BindResult {
 	return c.newMiscResult(pos, bindComplete)
@@ -302,6 +302,10 @@ func validateArrayDimensions(nDimensions int, nElements int) error {
 func DecodeDatum(
 	evalCtx *tree.EvalContext, t *types.T, code FormatCode, b []byte,
 ) (tree.Datum, error) {
+	if base := t.DomainBaseType(); base != nil {
+		// Values of domains are received as values of the base type.
+		t = base
+	}
 	id := t.Oid()
 	switch code {
 	case FormatText:
@@ -500,6 +504,9 @@ func DecodeDatum(
 			return tree.NewDString(string(b)), nil
 		}
 	case FormatBinary:
+		if t.Family() == types.TupleFamily && t.UserDefined() {
+			return decodeBinaryTuple(evalCtx, t, b)
+		}
 		switch id {
 		case oid.T_bool:
 			if len(b) > 0 {
@@ -951,6 +958,51 @@ func decodeBinaryArray(
 	return arr, nil
 }
 
+// decodeBinaryTuple decodes the binary format of a composite type. See
+// record_recv in Postgres for the format.
+func decodeBinaryTuple(evalCtx *tree.EvalContext, t *types.T, b []byte) (tree.Datum, error) {
+	r := bytes.NewBuffer(b)
+	var numFields int32
+	if err := binary.Read(r, binary.BigEndian, &numFields); err != nil {
+		return nil, NewInvalidBinaryRepresentationErrorf("insufficient data left in message")
+	}
+	contents := t.TupleContents()
+	if int(numFields) != len(contents) {
+		return nil, NewInvalidBinaryRepresentationErrorf(
+			"wrong number of columns: %d, expected %d", numFields, len(contents))
+	}
+	tup := tree.NewDTupleWithLen(t, len(contents))
+	var field struct {
+		Oid int32
+		Len int32
+	}
+	for i := range contents {
+		if err := binary.Read(r, binary.BigEndian, &field); err != nil {
+			return nil, NewInvalidBinaryRepresentationErrorf("insufficient data left in message")
+		}
+		if contents[i].Oid() != oid.Oid(field.Oid) {
+			return nil, pgerror.Newf(pgcode.DatatypeMismatch,
+				"wrong data type: %d, expected %d", field.Oid, contents[i].Oid())
+		}
+		if field.Len < 0 {
+			tup.D[i] = tree.DNull
+			continue
+		}
+		if r.Len() < int(field.Len) {
+			return nil, NewInvalidBinaryRepresentationErrorf("insufficient data left in message")
+		}
+		d, err := DecodeDatum(evalCtx, contents[i], FormatBinary, r.Next(int(field.Len)))
+		if err != nil {
+			return nil, err
+		}
+		tup.D[i] = d
+	}
+	if r.Len() > 0 {
+		return nil, NewInvalidBinaryRepresentationErrorf("improper binary format in record")
+	}
+	return tup, nil
+}
+
 var invalidUTF8Error = pgerror.Newf(pgcode.CharacterNotInRepertoire, "invalid UTF-8 sequence")
 
 var (
@@ -55,6 +55,10 @@ type pgType struct {
 }
 
 func pgTypeForParserType(t *types.T) pgType {
+	if base := t.DomainBaseType(); base != nil {
+		// Values of domains are sent as values of the base type.
+		t = base
+	}
 	size := -1
 	if s, variable := tree.DatumTypeSize(t); !variable {
 		size = int(s)
@@ -220,6 +224,9 @@ func (b *writeBuffer) writeBinaryDatum(
 	if log.V(2) {
 		log.Infof(ctx, "pgwire writing BINARY datum of type: %T, %#v", d, d)
 	}
+	if base := t.DomainBaseType(); base != nil {
+		t = base
+	}
 	if d == tree.DNull {
 		// NULL is encoded as -1; all other values have a length prefix.
 		b.putInt32(-1)
@@ -459,10 +466,16 @@ func (b *writeBuffer) writeBinaryDatum(
 		subWriter := newWriteBuffer(nil /* bytecount */)
 		// Put the number of datums.
 		subWriter.putInt32(int32(len(v.D)))
-		for _, elem := range v.D {
-			oid := elem.ResolvedType().Oid()
-			subWriter.putInt32(int32(oid))
-			subWriter.writeBinaryDatum(ctx, elem, sessionLoc, elem.ResolvedType())
+		// Use the field types of the tuple so that NULL fields of composite types
+		// are sent with their declared type.
+		contents := v.ResolvedType().TupleContents()
+		for i, elem := range v.D {
+			typ := elem.ResolvedType()
+			if i < len(contents) && elem == tree.DNull {
+				typ = contents[i]
+			}
+			subWriter.putInt32(int32(typ.Oid()))
+			subWriter.writeBinaryDatum(ctx, elem, sessionLoc, typ)
 		}
 		b.writeLengthPrefixedBuffer(&subWriter.wrapped)
 
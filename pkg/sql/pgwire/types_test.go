@@ -13,6 +13,7 @@ package pgwire
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"fmt"
 	"math/rand"
 	"reflect"
@@ -20,6 +21,9 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -30,6 +34,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/lib/pq/oid"
+	"github.com/stretchr/testify/require"
 )
 
 // The assertions in this test should also be caught by the integration tests on
@@ -146,6 +152,142 @@ func TestIntArrayRoundTrip(t *testing.T) {
 	}
 }
 
+func TestCompositeBinaryRoundTrip(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	evalCtx := tree.NewTestingEvalContext(cluster.MakeTestingClusterSettings())
+	defer evalCtx.Stop(ctx)
+	typ := types.MakeComposite(
+		typedesc.TypeIDToOID(100), typedesc.TypeIDToOID(101),
+		[]*types.T{types.Int, types.String}, []string{"a", "b"},
+	)
+
+	for _, d := range []*tree.DTuple{
+		tree.NewDTuple(typ, tree.NewDInt(1), tree.NewDString("foo")),
+		tree.NewDTuple(typ, tree.DNull, tree.NewDString("bar")),
+		tree.NewDTuple(typ, tree.DNull, tree.DNull),
+	} {
+		t.Run(d.String(), func(t *testing.T) {
+			buf := newWriteBuffer(nil /* bytecount */)
+			buf.writeBinaryDatum(ctx, d, time.UTC, typ)
+			require.NoError(t, buf.err)
+			b := buf.wrapped.Bytes()
+
+			// NULL fields are sent with the OID of their declared type and a
+			// length of -1.
+			for i, elem := range d.D {
+				if elem != tree.DNull {
+					continue
+				}
+				header := b[8:]
+				for j := 0; j < i; j++ {
+					if n := int32(binary.BigEndian.Uint32(header[4:])); n > 0 {
+						header = header[n:]
+					}
+					header = header[8:]
+				}
+				require.Equal(t, typ.TupleContents()[i].Oid(), oid.Oid(binary.BigEndian.Uint32(header)))
+				require.Equal(t, int32(-1), int32(binary.BigEndian.Uint32(header[4:])))
+			}
+
+			got, err := pgwirebase.DecodeDatum(evalCtx, typ, pgwirebase.FormatBinary, b[4:])
+			require.NoError(t, err)
+			require.Equal(t, 0, got.Compare(evalCtx, d), "expected %s, got %s", d, got)
+			require.Equal(t, typ, got.ResolvedType())
+		})
+	}
+
+	type field struct {
+		oid  oid.Oid
+		data []byte
+	}
+	// encode returns the binary format of a composite value with the given
+	// number of fields, followed by the given fields and trailing bytes.
+	encode := func(numFields int32, fields []field, trailing []byte) []byte {
+		buf := newWriteBuffer(nil /* bytecount */)
+		buf.putInt32(numFields)
+		for _, f := range fields {
+			buf.putInt32(int32(f.oid))
+			if f.data == nil {
+				buf.putInt32(-1)
+				continue
+			}
+			buf.putInt32(int32(len(f.data)))
+			buf.write(f.data)
+		}
+		buf.write(trailing)
+		return buf.wrapped.Bytes()
+	}
+	one := []byte{0, 0, 0, 0, 0, 0, 0, 1}
+	for _, tc := range []struct {
+		name        string
+		encoded     []byte
+		expectedErr string
+		code        pgcode.Code
+	}{
+		{
+			name:        "too few fields",
+			encoded:     encode(1, []field{{oid.T_int8, one}}, nil),
+			expectedErr: "wrong number of columns: 1, expected 2",
+			code:        pgcode.InvalidBinaryRepresentation,
+		},
+		{
+			name: "too many fields",
+			encoded: encode(3, []field{
+				{oid.T_int8, one}, {oid.T_text, []byte("foo")}, {oid.T_int8, nil},
+			}, nil),
+			expectedErr: "wrong number of columns: 3, expected 2",
+			code:        pgcode.InvalidBinaryRepresentation,
+		},
+		{
+			name:        "wrong field OID",
+			encoded:     encode(2, []field{{oid.T_int8, one}, {oid.T_int8, one}}, nil),
+			expectedErr: "wrong data type: 20, expected 25",
+			code:        pgcode.DatatypeMismatch,
+		},
+		{
+			name:        "wrong NULL field OID",
+			encoded:     encode(2, []field{{oid.T_text, nil}, {oid.T_text, nil}}, nil),
+			expectedErr: "wrong data type: 25, expected 20",
+			code:        pgcode.DatatypeMismatch,
+		},
+		{
+			name:        "truncated field count",
+			encoded:     []byte{0, 0},
+			expectedErr: "insufficient data left in message",
+			code:        pgcode.InvalidBinaryRepresentation,
+		},
+		{
+			name:        "truncated field header",
+			encoded:     encode(2, []field{{oid.T_int8, one}, {oid.T_text, []byte("foo")}}, nil)[:24],
+			expectedErr: "insufficient data left in message",
+			code:        pgcode.InvalidBinaryRepresentation,
+		},
+		{
+			name:        "truncated field data",
+			encoded:     encode(2, []field{{oid.T_int8, one}, {oid.T_text, []byte("foo")}}, nil)[:30],
+			expectedErr: "insufficient data left in message",
+			code:        pgcode.InvalidBinaryRepresentation,
+		},
+		{
+			name: "trailing bytes",
+			encoded: encode(2, []field{
+				{oid.T_int8, one}, {oid.T_text, []byte("foo")},
+			}, []byte{0}),
+			expectedErr: "improper binary format in record",
+			code:        pgcode.InvalidBinaryRepresentation,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := pgwirebase.DecodeDatum(evalCtx, typ, pgwirebase.FormatBinary, tc.encoded)
+			require.EqualError(t, err, tc.expectedErr)
+			require.Equal(t, tc.code, pgerror.GetPGCode(err))
+		})
+	}
+}
+
 func TestFloatConversion(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -96,6 +96,18 @@ type extendedEvalContext struct {
 	schemaAccessors *schemaInterface
 
 	sqlStatsCollector *sqlStatsCollector
+
+	// notifications refers to the session's LISTEN and NOTIFY state.
+	notifications *sessionNotifications
+
+	// deferredConstraints refers to the deferred constraint checks of the
+	// transaction. It is nil for internal executors, whose constraint checks
+	// are never deferred.
+	deferredConstraints *deferredConstraints
+
+	// localSessionVars refers to the session variables modified by SET LOCAL
+	// in the current transaction.
+	localSessionVars *localSessionVars
 }
 
 // copy returns a deep copy of ctx.
@@ -137,6 +137,10 @@ type PreparedPortal struct {
 	// meaning that any additional attempts to execute it should return no
 	// rows.
 	exhausted bool
+
+	// cursor is set if the portal was created with DECLARE. It is closed when
+	// the portal is.
+	cursor *sqlCursor
 }
 
 // makePreparedPortal creates a new PreparedPortal.
@@ -184,6 +188,9 @@ func (p *PreparedPortal) decRef(
 	if p.refCount == 0 {
 		prepStmtsNamespaceMemAcc.Shrink(ctx, p.size(portalName))
 		p.Stmt.decRef(ctx)
+		if p.cursor != nil {
+			p.cursor.close(ctx)
+		}
 	}
 }
 
@@ -40,6 +40,7 @@ const (
 	UPDATE
 	USAGE
 	ZONECONFIG
+	EXECUTE
 )
 
 // ObjectType represents objects that can have privileges.
@@ -56,16 +57,19 @@ const (
 	Table ObjectType = "table"
 	// Type represents a type object.
 	Type ObjectType = "type"
+	// Function represents a user defined function object.
+	Function ObjectType = "function"
 )
 
 // Predefined sets of privileges.
 var (
-	AllPrivileges     = List{ALL, CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE, ZONECONFIG}
-	ReadData          = List{GRANT, SELECT}
-	ReadWriteData     = List{GRANT, SELECT, INSERT, DELETE, UPDATE}
-	DBTablePrivileges = List{ALL, CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, ZONECONFIG}
-	SchemaPrivileges  = List{ALL, GRANT, CREATE, USAGE}
-	TypePrivileges    = List{ALL, GRANT, USAGE}
+	AllPrivileges      = List{ALL, CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE, ZONECONFIG, EXECUTE}
+	ReadData           = List{GRANT, SELECT}
+	ReadWriteData      = List{GRANT, SELECT, INSERT, DELETE, UPDATE}
+	DBTablePrivileges  = List{ALL, CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, ZONECONFIG}
+	SchemaPrivileges   = List{ALL, GRANT, CREATE, USAGE}
+	TypePrivileges     = List{ALL, GRANT, USAGE}
+	FunctionPrivileges = List{ALL, GRANT, EXECUTE}
 )
 
 // Mask returns the bitmask for a given privilege.
@@ -75,7 +79,7 @@ func (k Kind) Mask() uint32 {
 
 // ByValue is just an array of privilege kinds sorted by value.
 var ByValue = [...]Kind{
-	ALL, CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE, ZONECONFIG,
+	ALL, CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, USAGE, ZONECONFIG, EXECUTE,
 }
 
 // ByName is a map of string -> kind value.
@@ -90,6 +94,7 @@ var ByName = map[string]Kind{
 	"UPDATE":     UPDATE,
 	"ZONECONFIG": ZONECONFIG,
 	"USAGE":      USAGE,
+	"EXECUTE":    EXECUTE,
 }
 
 // List is a list of privileges.
@@ -226,6 +231,8 @@ func GetValidPrivilegesForObject(objectType ObjectType) List {
 		return SchemaPrivileges
 	case Type:
 		return TypePrivileges
+	case Function:
+		return FunctionPrivileges
 	case Any:
 		return AllPrivileges
 	default:
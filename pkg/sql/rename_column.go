@@ -123,6 +123,11 @@ func (p *planner) renameColumn(
 			)
 		}
 	}
+	for i := range tableDesc.Triggers {
+		if triggerDependsOn(&tableDesc.Triggers[i], tableDesc.ID, col.ID) {
+			return false, dependentTriggerError("column", oldName.String(), tableDesc.Name, "rename")
+		}
+	}
 	if *oldName == *newName {
 		// Noop.
 		return false, nil
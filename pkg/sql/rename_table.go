@@ -280,6 +280,9 @@ func (p *planner) dependentViewError(
 	if err != nil {
 		return err
 	}
+	if !viewDesc.IsView() {
+		return dependentTriggerError(typeName, objName, viewDesc.GetName(), op)
+	}
 	viewName := viewDesc.Name
 	if viewDesc.ParentID != parentID {
 		viewFQName, err := p.getQualifiedTableName(ctx, viewDesc)
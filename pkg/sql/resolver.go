@@ -321,6 +321,22 @@ func getDescriptorsFromTargetListForPrivilegeChange(
 		return descs, nil
 	}
 
+	if targets.Functions != nil {
+		if len(targets.Functions) == 0 {
+			return nil, errNoFunction
+		}
+		descs := make([]catalog.Descriptor, 0, len(targets.Functions))
+		for _, fn := range targets.Functions {
+			_, descriptor, err := resolver.ResolveMutableFunction(ctx, p, fn, true /* required */)
+			if err != nil {
+				return nil, err
+			}
+
+			descs = append(descs, descriptor)
+		}
+		return descs, nil
+	}
+
 	if targets.Schemas != nil {
 		if len(targets.Schemas) == 0 {
 			return nil, errNoSchema
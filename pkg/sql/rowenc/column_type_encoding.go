@@ -829,6 +829,15 @@ func MarshalColumnValue(col *descpb.ColumnDescriptor, val tree.Datum) (roachpb.V
 			r.SetBytes(v.PhysicalRep)
 			return r, nil
 		}
+	case types.TupleFamily:
+		if v, ok := val.(*tree.DTuple); ok {
+			b, err := encodeUntaggedTuple(v, nil /* appendTo */, nil /* scratch */)
+			if err != nil {
+				return r, err
+			}
+			r.SetBytes(b)
+			return r, nil
+		}
 	default:
 		return r, errors.AssertionFailedf("unsupported column type: %s", col.Type.Family())
 	}
@@ -1008,6 +1017,13 @@ func UnmarshalColumnValue(a *DatumAlloc, typ *types.T, value roachpb.Value) (tre
 			return nil, err
 		}
 		return a.NewDEnum(tree.DEnum{EnumTyp: typ, PhysicalRep: phys, LogicalRep: log}), nil
+	case types.TupleFamily:
+		v, err := value.GetBytes()
+		if err != nil {
+			return nil, err
+		}
+		datum, _, err := decodeTuple(a, typ, v)
+		return datum, err
 	default:
 		return nil, errors.Errorf("unsupported column type: %s", typ.Family())
 	}
@@ -1016,6 +1032,12 @@ func UnmarshalColumnValue(a *DatumAlloc, typ *types.T, value roachpb.Value) (tre
 // encodeTuple produces the value encoding for a tuple.
 func encodeTuple(t *tree.DTuple, appendTo []byte, colID uint32, scratch []byte) ([]byte, error) {
 	appendTo = encoding.EncodeValueTag(appendTo, colID, encoding.Tuple)
+	return encodeUntaggedTuple(t, appendTo, scratch)
+}
+
+// encodeUntaggedTuple produces the value encoding for a tuple without a value
+// tag.
+func encodeUntaggedTuple(t *tree.DTuple, appendTo []byte, scratch []byte) ([]byte, error) {
 	appendTo = encoding.EncodeNonsortingUvarint(appendTo, uint64(len(t.D)))
 
 	var err error
@@ -1048,6 +1070,10 @@ func decodeTuple(a *DatumAlloc, tupTyp *types.T, b []byte) (tree.Datum, []byte,
 		}
 		result.D[i] = datum
 	}
+	if tupTyp.UserDefined() {
+		// Keep the type of composite values, so that their labels are preserved.
+		return tree.NewDTuple(tupTyp, result.D...), b, nil
+	}
 	return a.NewDTuple(result), b, nil
 }
 
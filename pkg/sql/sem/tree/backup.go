@@ -126,6 +126,9 @@ type Restore struct {
 	AsOf               AsOfClause
 	Options            RestoreOptions
 	Subdir             Expr
+	// AsTable, if set, is the name under which the single table in Targets is
+	// restored, alongside the table it was backed up from.
+	AsTable *UnresolvedObjectName
 }
 
 var _ Statement = &Restore{}
@@ -137,6 +140,11 @@ func (node *Restore) Format(ctx *FmtCtx) {
 		ctx.FormatNode(&node.Targets)
 		ctx.WriteString(" ")
 	}
+	if node.AsTable != nil {
+		ctx.WriteString("AS ")
+		ctx.FormatNode(node.AsTable)
+		ctx.WriteString(" ")
+	}
 	ctx.WriteString("FROM ")
 	if node.Subdir != nil {
 		ctx.FormatNode(node.Subdir)
@@ -158,6 +166,29 @@ func (node *Restore) Format(ctx *FmtCtx) {
 	}
 }
 
+// CompactBackup represents a COMPACT BACKUP statement.
+type CompactBackup struct {
+	// Subdir is the path of the full backup, relative to InCollection, whose
+	// incremental layers are merged into it.
+	Subdir       Expr
+	InCollection Expr
+	Options      KVOptions
+}
+
+var _ Statement = &CompactBackup{}
+
+// Format implements the NodeFormatter interface.
+func (node *CompactBackup) Format(ctx *FmtCtx) {
+	ctx.WriteString("COMPACT BACKUP ")
+	ctx.FormatNode(node.Subdir)
+	ctx.WriteString(" IN ")
+	ctx.FormatNode(node.InCollection)
+	if len(node.Options) > 0 {
+		ctx.WriteString(" WITH ")
+		ctx.FormatNode(&node.Options)
+	}
+}
+
 // KVOption is a key-value option.
 type KVOption struct {
 	Key   Name
@@ -1283,6 +1283,28 @@ func performCastWithoutPrecisionTruncation(ctx *EvalContext, d Datum, t *types.T
 		case *DString:
 			return ParseDOid(ctx, string(*v), t)
 		}
+	case types.TupleFamily:
+		// A cast to tuple{} leaves the tuple unchanged.
+		if t.Identical(types.AnyTuple) {
+			if v, ok := d.(*DTuple); ok {
+				return v, nil
+			}
+		}
+		if v, ok := d.(*DTuple); ok && len(v.D) == len(t.TupleContents()) {
+			ret := NewDTupleWithLen(t, len(v.D))
+			for i, e := range v.D {
+				ecast := DNull
+				if e != DNull {
+					var err error
+					ecast, err = PerformCast(ctx, e, t.TupleContents()[i])
+					if err != nil {
+						return nil, err
+					}
+				}
+				ret.D[i] = ecast
+			}
+			return ret, nil
+		}
 	}
 
 	return nil, pgerror.Newf(
@@ -12,15 +12,33 @@ package tree
 
 // CreateChangefeed represents a CREATE CHANGEFEED statement.
 type CreateChangefeed struct {
-	Targets TargetList
+	Targets ChangefeedTargets
 	SinkURI Expr
 	Options KVOptions
+	// Select is set for the CREATE CHANGEFEED ... AS SELECT form of the
+	// statement, which filters and projects the rows of its single target
+	// table. Targets then contains the table of the FROM clause.
+	Select *SelectClause
 }
 
 var _ Statement = &CreateChangefeed{}
 
 // Format implements the NodeFormatter interface.
 func (node *CreateChangefeed) Format(ctx *FmtCtx) {
+	if node.Select != nil {
+		ctx.WriteString("CREATE CHANGEFEED")
+		if node.SinkURI != nil {
+			ctx.WriteString(" INTO ")
+			ctx.FormatNode(node.SinkURI)
+		}
+		if node.Options != nil {
+			ctx.WriteString(" WITH ")
+			ctx.FormatNode(&node.Options)
+		}
+		ctx.WriteString(" AS ")
+		ctx.FormatNode(node.Select)
+		return
+	}
 	if node.SinkURI != nil {
 		ctx.WriteString("CREATE ")
 	} else {
@@ -28,7 +46,7 @@ func (node *CreateChangefeed) Format(ctx *FmtCtx) {
 		// prefix. They're also still EXPERIMENTAL, so they get marked as such.
 		ctx.WriteString("EXPERIMENTAL ")
 	}
-	ctx.WriteString("CHANGEFEED FOR ")
+	ctx.WriteString("CHANGEFEED FOR TABLE ")
 	ctx.FormatNode(&node.Targets)
 	if node.SinkURI != nil {
 		ctx.WriteString(" INTO ")
@@ -39,3 +57,34 @@ func (node *CreateChangefeed) Format(ctx *FmtCtx) {
 		ctx.FormatNode(&node.Options)
 	}
 }
+
+// ChangefeedTarget represents a table, or one column family of a table,
+// watched by a changefeed.
+type ChangefeedTarget struct {
+	TableName TablePattern
+	// FamilyName is the column family of the table to watch, if any. When it
+	// is empty, all the column families of the table are watched.
+	FamilyName Name
+}
+
+// Format implements the NodeFormatter interface.
+func (ct *ChangefeedTarget) Format(ctx *FmtCtx) {
+	ctx.FormatNode(ct.TableName)
+	if ct.FamilyName != "" {
+		ctx.WriteString(" FAMILY ")
+		ctx.FormatNode(&ct.FamilyName)
+	}
+}
+
+// ChangefeedTargets represents a list of changefeed targets.
+type ChangefeedTargets []ChangefeedTarget
+
+// Format implements the NodeFormatter interface.
+func (cts *ChangefeedTargets) Format(ctx *FmtCtx) {
+	for i := range *cts {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		ctx.FormatNode(&(*cts)[i])
+	}
+}
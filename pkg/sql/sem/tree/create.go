@@ -309,6 +309,9 @@ type CreateType struct {
 	Variety  CreateTypeVariety
 	// EnumLabels is set when this represents a CREATE TYPE ... AS ENUM statement.
 	EnumLabels EnumValueList
+	// CompositeTypeList is set when this represents a CREATE TYPE ... AS (...)
+	// statement.
+	CompositeTypeList []CompositeTypeElem
 	// IfNotExists is true if IF NOT EXISTS was requested.
 	IfNotExists bool
 }
@@ -328,13 +331,337 @@ func (node *CreateType) Format(ctx *FmtCtx) {
 		ctx.WriteString("AS ENUM (")
 		ctx.FormatNode(&node.EnumLabels)
 		ctx.WriteString(")")
+	case Composite:
+		ctx.WriteString("AS (")
+		for i := range node.CompositeTypeList {
+			if i > 0 {
+				ctx.WriteString(", ")
+			}
+			elem := &node.CompositeTypeList[i]
+			ctx.FormatNode(&elem.Label)
+			ctx.WriteByte(' ')
+			ctx.FormatTypeReference(elem.Type)
+		}
+		ctx.WriteString(")")
 	}
 }
 
+// CompositeTypeElem is a single field of a composite type.
+type CompositeTypeElem struct {
+	Label Name
+	Type  ResolvableTypeReference
+}
+
 func (node *CreateType) String() string {
 	return AsString(node)
 }
 
+// CreateDomain represents a CREATE DOMAIN statement.
+type CreateDomain struct {
+	TypeName *UnresolvedObjectName
+	Type     ResolvableTypeReference
+	// Default is the default value of the domain, if any.
+	Default Expr
+	// NotNull is true if the domain does not allow NULL values.
+	NotNull bool
+	// Checks contains the CHECK constraints of the domain.
+	Checks []DomainCheck
+}
+
+// DomainCheck represents a CHECK constraint of a domain. The value being
+// checked is referred to as VALUE in the expression.
+type DomainCheck struct {
+	// Name is empty if the constraint was not named.
+	Name Name
+	Expr Expr
+}
+
+var _ Statement = &CreateDomain{}
+
+// NewCreateDomain constructs a CreateDomain from the column qualifications
+// that follow the base type in a CREATE DOMAIN statement. Only DEFAULT,
+// [NOT] NULL and CHECK qualifications are allowed.
+func NewCreateDomain(
+	name *UnresolvedObjectName, typRef ResolvableTypeReference, quals []NamedColumnQualification,
+) (*CreateDomain, error) {
+	d := &CreateDomain{TypeName: name, Type: typRef}
+	var null bool
+	for _, q := range quals {
+		switch t := q.Qualification.(type) {
+		case *ColumnDefault:
+			if d.Default != nil {
+				return nil, pgerror.Newf(pgcode.Syntax,
+					"multiple default values specified for domain %q", name)
+			}
+			d.Default = t.Expr
+		case NotNullConstraint:
+			if null {
+				return nil, pgerror.Newf(pgcode.Syntax,
+					"conflicting NULL/NOT NULL declarations for domain %q", name)
+			}
+			d.NotNull = true
+		case NullConstraint:
+			if d.NotNull {
+				return nil, pgerror.Newf(pgcode.Syntax,
+					"conflicting NULL/NOT NULL declarations for domain %q", name)
+			}
+			null = true
+		case *ColumnCheckConstraint:
+			d.Checks = append(d.Checks, DomainCheck{Name: q.Name, Expr: t.Expr})
+		default:
+			return nil, pgerror.Newf(pgcode.Syntax,
+				"only DEFAULT, NULL, NOT NULL and CHECK constraints are supported for domains")
+		}
+	}
+	return d, nil
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateDomain) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE DOMAIN ")
+	ctx.FormatNode(node.TypeName)
+	ctx.WriteString(" AS ")
+	ctx.FormatTypeReference(node.Type)
+	if node.Default != nil {
+		ctx.WriteString(" DEFAULT ")
+		ctx.FormatNode(node.Default)
+	}
+	if node.NotNull {
+		ctx.WriteString(" NOT NULL")
+	}
+	for i := range node.Checks {
+		c := &node.Checks[i]
+		if c.Name != "" {
+			ctx.WriteString(" CONSTRAINT ")
+			ctx.FormatNode(&c.Name)
+		}
+		ctx.WriteString(" CHECK (")
+		ctx.FormatNode(c.Expr)
+		ctx.WriteByte(')')
+	}
+}
+
+// FuncArg represents a single argument in a CREATE FUNCTION statement.
+type FuncArg struct {
+	// Name is empty for unnamed arguments.
+	Name Name
+	Type ResolvableTypeReference
+}
+
+// Format implements the NodeFormatter interface.
+func (node *FuncArg) Format(ctx *FmtCtx) {
+	if node.Name != "" {
+		ctx.FormatNode(&node.Name)
+		ctx.WriteByte(' ')
+	}
+	ctx.FormatTypeReference(node.Type)
+}
+
+// FuncArgs represents a list of function arguments.
+type FuncArgs []FuncArg
+
+// Format implements the NodeFormatter interface.
+func (node *FuncArgs) Format(ctx *FmtCtx) {
+	for i := range *node {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		ctx.FormatNode(&(*node)[i])
+	}
+}
+
+// FunctionOption is an option of a CREATE FUNCTION statement: its language,
+// its volatility or its body.
+type FunctionOption interface {
+	functionOption()
+}
+
+// FunctionLanguage is the LANGUAGE option of CREATE FUNCTION.
+type FunctionLanguage string
+
+// FunctionBody is the AS option of CREATE FUNCTION.
+type FunctionBody string
+
+func (FunctionLanguage) functionOption() {}
+func (FunctionBody) functionOption()     {}
+func (Volatility) functionOption()       {}
+
+// FunctionOptions is a list of CREATE FUNCTION options.
+type FunctionOptions []FunctionOption
+
+// CreateFunction represents a CREATE FUNCTION statement.
+type CreateFunction struct {
+	Name       *UnresolvedObjectName
+	Replace    bool
+	Args       FuncArgs
+	ReturnType ResolvableTypeReference
+	// Language is the lower-cased LANGUAGE of the function. Only "sql" is
+	// supported.
+	Language   string
+	Volatility Volatility
+	Body       string
+}
+
+var _ Statement = &CreateFunction{}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateFunction) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE ")
+	if node.Replace {
+		ctx.WriteString("OR REPLACE ")
+	}
+	ctx.WriteString("FUNCTION ")
+	ctx.FormatNode(node.Name)
+	ctx.WriteByte('(')
+	ctx.FormatNode(&node.Args)
+	ctx.WriteString(") RETURNS ")
+	ctx.FormatTypeReference(node.ReturnType)
+	ctx.WriteString(" LANGUAGE ")
+	ctx.WriteString(node.Language)
+	if node.Volatility != 0 {
+		ctx.WriteByte(' ')
+		ctx.WriteString(strings.ToUpper(node.Volatility.String()))
+	}
+	ctx.WriteString(" AS ")
+	if ctx.flags.HasFlags(FmtAnonymize) || ctx.flags.HasFlags(FmtHideConstants) {
+		ctx.WriteByte('_')
+	} else {
+		lex.EncodeSQLString(&ctx.Buffer, node.Body)
+	}
+}
+
+// MakeCreateFunction constructs a CreateFunction from the options in the
+// order they were given. An error is returned if an option is repeated or if
+// the body is missing.
+func MakeCreateFunction(
+	name *UnresolvedObjectName,
+	replace bool,
+	args FuncArgs,
+	returnType ResolvableTypeReference,
+	options FunctionOptions,
+) (*CreateFunction, error) {
+	n := &CreateFunction{Name: name, Replace: replace, Args: args, ReturnType: returnType}
+	var hasLanguage, hasBody bool
+	for _, o := range options {
+		redundant := false
+		switch t := o.(type) {
+		case FunctionLanguage:
+			redundant = hasLanguage
+			hasLanguage = true
+			n.Language = strings.ToLower(string(t))
+		case Volatility:
+			redundant = n.Volatility != 0
+			n.Volatility = t
+		case FunctionBody:
+			redundant = hasBody
+			hasBody = true
+			n.Body = string(t)
+		}
+		if redundant {
+			return nil, pgerror.New(pgcode.Syntax, "conflicting or redundant options")
+		}
+	}
+	if !hasBody {
+		return nil, pgerror.New(pgcode.InvalidFunctionDefinition, "no function body specified")
+	}
+	if !hasLanguage {
+		return nil, pgerror.New(pgcode.InvalidFunctionDefinition, "no language specified")
+	}
+	return n, nil
+}
+
+// TriggerActionTime specifies whether a trigger runs before or after the row
+// that fired it is written.
+type TriggerActionTime uint8
+
+// The values for TriggerActionTime.
+const (
+	TriggerBefore TriggerActionTime = iota
+	TriggerAfter
+)
+
+// String implements the fmt.Stringer interface.
+func (t TriggerActionTime) String() string {
+	if t == TriggerAfter {
+		return "AFTER"
+	}
+	return "BEFORE"
+}
+
+// TriggerEvent is a kind of mutation that fires a trigger.
+type TriggerEvent uint8
+
+// The values for TriggerEvent.
+const (
+	TriggerInsert TriggerEvent = iota
+	TriggerUpdate
+	TriggerDelete
+)
+
+var triggerEventName = [...]string{
+	TriggerInsert: "INSERT",
+	TriggerUpdate: "UPDATE",
+	TriggerDelete: "DELETE",
+}
+
+// String implements the fmt.Stringer interface.
+func (e TriggerEvent) String() string {
+	return triggerEventName[e]
+}
+
+// TriggerEvents is a list of trigger events.
+type TriggerEvents []TriggerEvent
+
+// Contains returns whether the list contains the given event.
+func (l TriggerEvents) Contains(e TriggerEvent) bool {
+	for _, x := range l {
+		if x == e {
+			return true
+		}
+	}
+	return false
+}
+
+// Format implements the NodeFormatter interface.
+func (l *TriggerEvents) Format(ctx *FmtCtx) {
+	for i, e := range *l {
+		if i > 0 {
+			ctx.WriteString(" OR ")
+		}
+		ctx.WriteString(e.String())
+	}
+}
+
+// CreateTrigger represents a CREATE TRIGGER statement.
+type CreateTrigger struct {
+	Name       Name
+	ActionTime TriggerActionTime
+	Events     TriggerEvents
+	Table      TableName
+	// Body is the statement run for each row, as written by the user.
+	Body string
+}
+
+var _ Statement = &CreateTrigger{}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateTrigger) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE TRIGGER ")
+	ctx.FormatNode(&node.Name)
+	ctx.WriteByte(' ')
+	ctx.WriteString(node.ActionTime.String())
+	ctx.WriteByte(' ')
+	ctx.FormatNode(&node.Events)
+	ctx.WriteString(" ON ")
+	ctx.FormatNode(&node.Table)
+	ctx.WriteString(" FOR EACH ROW AS ")
+	if ctx.flags.HasFlags(FmtAnonymize) || ctx.flags.HasFlags(FmtHideConstants) {
+		ctx.WriteByte('_')
+	} else {
+		lex.EncodeSQLString(&ctx.Buffer, node.Body)
+	}
+}
+
 // TableDef represents a column, index or constraint definition within a CREATE
 // TABLE statement.
 type TableDef interface {
@@ -349,6 +676,7 @@ func (*IndexTableDef) tableDef()                {}
 func (*FamilyTableDef) tableDef()               {}
 func (*ForeignKeyConstraintTableDef) tableDef() {}
 func (*CheckConstraintTableDef) tableDef()      {}
+func (*ExclusionConstraintTableDef) tableDef()  {}
 func (*LikeTableDef) tableDef()                 {}
 
 // TableDefs represents a list of table definitions.
@@ -406,6 +734,7 @@ type ColumnTableDef struct {
 		ConstraintName Name
 		Actions        ReferenceActions
 		Match          CompositeKeyMatchMethod
+		Deferrability  ConstraintDeferrability
 	}
 	Computed struct {
 		Computed bool
@@ -535,6 +864,7 @@ func NewColumnTableDef(
 			d.References.ConstraintName = c.Name
 			d.References.Actions = t.Actions
 			d.References.Match = t.Match
+			d.References.Deferrability = t.Deferrability
 		case *ColumnComputedDef:
 			d.Computed.Computed = true
 			d.Computed.Expr = t.Expr
@@ -652,6 +982,7 @@ func (node *ColumnTableDef) Format(ctx *FmtCtx) {
 			ctx.WriteString(node.References.Match.String())
 		}
 		ctx.FormatNode(&node.References.Actions)
+		ctx.FormatNode(&node.References.Deferrability)
 	}
 	if node.IsComputed() {
 		ctx.WriteString(" AS (")
@@ -756,10 +1087,11 @@ type ColumnCheckConstraint struct {
 
 // ColumnFKConstraint represents a FK-constaint on a column.
 type ColumnFKConstraint struct {
-	Table   TableName
-	Col     Name // empty-string means use PK
-	Actions ReferenceActions
-	Match   CompositeKeyMatchMethod
+	Table         TableName
+	Col           Name // empty-string means use PK
+	Actions       ReferenceActions
+	Match         CompositeKeyMatchMethod
+	Deferrability ConstraintDeferrability
 }
 
 // ColumnComputedDef represents the description of a computed column.
@@ -842,13 +1174,15 @@ type ConstraintTableDef interface {
 func (*UniqueConstraintTableDef) constraintTableDef()     {}
 func (*ForeignKeyConstraintTableDef) constraintTableDef() {}
 func (*CheckConstraintTableDef) constraintTableDef()      {}
+func (*ExclusionConstraintTableDef) constraintTableDef()  {}
 
 // UniqueConstraintTableDef represents a unique constraint within a CREATE
 // TABLE statement.
 type UniqueConstraintTableDef struct {
 	IndexTableDef
-	PrimaryKey   bool
-	WithoutIndex bool
+	PrimaryKey    bool
+	WithoutIndex  bool
+	Deferrability ConstraintDeferrability
 }
 
 // SetName implements the TableDef interface.
@@ -888,6 +1222,7 @@ func (node *UniqueConstraintTableDef) Format(ctx *FmtCtx) {
 	if node.PartitionBy != nil {
 		ctx.FormatNode(node.PartitionBy)
 	}
+	ctx.FormatNode(&node.Deferrability)
 	if node.Predicate != nil {
 		ctx.WriteString(" WHERE ")
 		ctx.FormatNode(node.Predicate)
@@ -961,14 +1296,49 @@ func (c CompositeKeyMatchMethod) String() string {
 	return compositeKeyMatchMethodName[c]
 }
 
+// ConstraintDeferrability specifies whether the checks of a constraint can be
+// deferred until the end of the transaction with SET CONSTRAINTS, and whether
+// they are deferred by default.
+type ConstraintDeferrability int
+
+// The values for ConstraintDeferrability.
+const (
+	// ConstraintNotDeferrable is NOT DEFERRABLE, the default.
+	ConstraintNotDeferrable ConstraintDeferrability = iota
+	// ConstraintInitiallyImmediate is DEFERRABLE INITIALLY IMMEDIATE.
+	ConstraintInitiallyImmediate
+	// ConstraintInitiallyDeferred is DEFERRABLE INITIALLY DEFERRED.
+	ConstraintInitiallyDeferred
+)
+
+var constraintDeferrabilityName = [...]string{
+	ConstraintNotDeferrable:      "NOT DEFERRABLE",
+	ConstraintInitiallyImmediate: "DEFERRABLE",
+	ConstraintInitiallyDeferred:  "DEFERRABLE INITIALLY DEFERRED",
+}
+
+func (d ConstraintDeferrability) String() string {
+	return constraintDeferrabilityName[d]
+}
+
+// Format implements the NodeFormatter interface. NOT DEFERRABLE is omitted
+// because it is the default.
+func (d *ConstraintDeferrability) Format(ctx *FmtCtx) {
+	if *d != ConstraintNotDeferrable {
+		ctx.WriteByte(' ')
+		ctx.WriteString(d.String())
+	}
+}
+
 // ForeignKeyConstraintTableDef represents a FOREIGN KEY constraint in the AST.
 type ForeignKeyConstraintTableDef struct {
-	Name     Name
-	Table    TableName
-	FromCols NameList
-	ToCols   NameList
-	Actions  ReferenceActions
-	Match    CompositeKeyMatchMethod
+	Name          Name
+	Table         TableName
+	FromCols      NameList
+	ToCols        NameList
+	Actions       ReferenceActions
+	Match         CompositeKeyMatchMethod
+	Deferrability ConstraintDeferrability
 }
 
 // Format implements the NodeFormatter interface.
@@ -996,6 +1366,7 @@ func (node *ForeignKeyConstraintTableDef) Format(ctx *FmtCtx) {
 	}
 
 	ctx.FormatNode(&node.Actions)
+	ctx.FormatNode(&node.Deferrability)
 }
 
 // SetName implements the ConstraintTableDef interface.
@@ -1028,6 +1399,71 @@ func (node *CheckConstraintTableDef) Format(ctx *FmtCtx) {
 	ctx.WriteByte(')')
 }
 
+// ExclusionConstraintTableDef represents an EXCLUDE constraint within a
+// CREATE TABLE statement.
+type ExclusionConstraintTableDef struct {
+	Name Name
+	// AccessMethod is the access method of the index backing the constraint,
+	// or empty if it was not specified.
+	AccessMethod Name
+	Elems        ExclusionElemList
+	Predicate    Expr
+}
+
+// SetName implements the ConstraintTableDef interface.
+func (node *ExclusionConstraintTableDef) SetName(name Name) {
+	node.Name = name
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ExclusionConstraintTableDef) Format(ctx *FmtCtx) {
+	if node.Name != "" {
+		ctx.WriteString("CONSTRAINT ")
+		ctx.FormatNode(&node.Name)
+		ctx.WriteByte(' ')
+	}
+	ctx.WriteString("EXCLUDE ")
+	if node.AccessMethod != "" {
+		ctx.WriteString("USING ")
+		ctx.FormatNode(&node.AccessMethod)
+		ctx.WriteByte(' ')
+	}
+	ctx.WriteByte('(')
+	ctx.FormatNode(&node.Elems)
+	ctx.WriteByte(')')
+	if node.Predicate != nil {
+		ctx.WriteString(" WHERE ")
+		ctx.FormatNode(node.Predicate)
+	}
+}
+
+// ExclusionElem is a single element of an EXCLUDE constraint: a column
+// together with the operator used to compare its values.
+type ExclusionElem struct {
+	Column   Name
+	Operator ComparisonOperator
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ExclusionElem) Format(ctx *FmtCtx) {
+	ctx.FormatNode(&node.Column)
+	ctx.WriteString(" WITH ")
+	ctx.WriteString(node.Operator.String())
+}
+
+// ExclusionElemList is a list of ExclusionElems.
+type ExclusionElemList []ExclusionElem
+
+// Format implements the NodeFormatter interface.
+func (l *ExclusionElemList) Format(ctx *FmtCtx) {
+	for i := range *l {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		ctx.FormatNode(&(*l)[i])
+	}
+}
+
 // FamilyTableDef represents a family definition within a CREATE TABLE
 // statement.
 type FamilyTableDef struct {
@@ -1339,12 +1775,13 @@ func (node *CreateTable) HoistConstraints() {
 					targetCol = append(targetCol, col.References.Col)
 				}
 				node.Defs = append(node.Defs, &ForeignKeyConstraintTableDef{
-					Table:    *col.References.Table,
-					FromCols: NameList{col.Name},
-					ToCols:   targetCol,
-					Name:     col.References.ConstraintName,
-					Actions:  col.References.Actions,
-					Match:    col.References.Match,
+					Table:         *col.References.Table,
+					FromCols:      NameList{col.Name},
+					ToCols:        targetCol,
+					Name:          col.References.ConstraintName,
+					Actions:       col.References.Actions,
+					Match:         col.References.Match,
+					Deferrability: col.References.Deferrability,
 				})
 				col.References.Table = nil
 			}
@@ -0,0 +1,123 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+import (
+	"math"
+	"strconv"
+)
+
+// DeclareCursor represents a DECLARE statement.
+type DeclareCursor struct {
+	Name   Name
+	Select *Select
+}
+
+var _ Statement = &DeclareCursor{}
+
+// Format implements the NodeFormatter interface.
+func (node *DeclareCursor) Format(ctx *FmtCtx) {
+	ctx.WriteString("DECLARE ")
+	ctx.FormatNode(&node.Name)
+	ctx.WriteString(" CURSOR FOR ")
+	ctx.FormatNode(node.Select)
+}
+
+// FetchDirection is the direction in which FETCH and MOVE move a cursor.
+type FetchDirection int
+
+const (
+	// FetchForward moves the cursor forward by Count rows.
+	FetchForward FetchDirection = iota
+	// FetchBackward moves the cursor backward by Count rows.
+	FetchBackward
+	// FetchAbsolute moves the cursor to the row at position Count.
+	FetchAbsolute
+	// FetchRelative moves the cursor to the row Count rows after the current
+	// one.
+	FetchRelative
+)
+
+// FetchAll is the Count of FETCH ALL and FETCH BACKWARD ALL.
+const FetchAll = math.MaxInt64
+
+// CursorStmt contains the arguments of a FETCH or MOVE statement. The
+// variants of FETCH are normalized the same way as in Postgres: NEXT, PRIOR,
+// FIRST and LAST are respectively FORWARD 1, BACKWARD 1, ABSOLUTE 1 and
+// ABSOLUTE -1.
+type CursorStmt struct {
+	Name      Name
+	Direction FetchDirection
+	Count     int64
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CursorStmt) Format(ctx *FmtCtx) {
+	switch node.Direction {
+	case FetchBackward:
+		ctx.WriteString("BACKWARD ")
+	case FetchAbsolute:
+		ctx.WriteString("ABSOLUTE ")
+	case FetchRelative:
+		ctx.WriteString("RELATIVE ")
+	}
+	if node.Count == FetchAll && (node.Direction == FetchForward || node.Direction == FetchBackward) {
+		ctx.WriteString("ALL")
+	} else {
+		ctx.WriteString(strconv.FormatInt(node.Count, 10))
+	}
+	ctx.WriteByte(' ')
+	ctx.FormatNode(&node.Name)
+}
+
+// FetchCursor represents a FETCH statement.
+type FetchCursor struct {
+	CursorStmt
+}
+
+var _ Statement = &FetchCursor{}
+
+// Format implements the NodeFormatter interface.
+func (node *FetchCursor) Format(ctx *FmtCtx) {
+	ctx.WriteString("FETCH ")
+	ctx.FormatNode(&node.CursorStmt)
+}
+
+// MoveCursor represents a MOVE statement.
+type MoveCursor struct {
+	CursorStmt
+}
+
+var _ Statement = &MoveCursor{}
+
+// Format implements the NodeFormatter interface.
+func (node *MoveCursor) Format(ctx *FmtCtx) {
+	ctx.WriteString("MOVE ")
+	ctx.FormatNode(&node.CursorStmt)
+}
+
+// CloseCursor represents a CLOSE statement.
+type CloseCursor struct {
+	Name Name
+	All  bool
+}
+
+var _ Statement = &CloseCursor{}
+
+// Format implements the NodeFormatter interface.
+func (node *CloseCursor) Format(ctx *FmtCtx) {
+	ctx.WriteString("CLOSE ")
+	if node.All {
+		ctx.WriteString("ALL")
+	} else {
+		ctx.FormatNode(&node.Name)
+	}
+}
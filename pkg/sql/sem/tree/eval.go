@@ -3072,15 +3072,29 @@ type EvalPlanner interface {
 	CompactEngineSpan(
 		ctx context.Context, nodeID int32, storeID int32, startKey []byte, endKey []byte,
 	) error
+
+	// SendNotification sends a notification on the given channel to the
+	// sessions listening on it, once the current transaction commits.
+	SendNotification(ctx context.Context, channel, payload string) error
+
+	// DeferConstraintCheck is called when a statement violates a deferrable
+	// constraint of the given table for the given key values. It returns true
+	// if the checks of the constraint are deferred, in which case the key is
+	// checked again at the end of the transaction instead of failing the
+	// statement.
+	DeferConstraintCheck(
+		tableID ID, constraintName string, deferrability ConstraintDeferrability, keyVals Datums,
+	) bool
 }
 
 // EvalSessionAccessor is a limited interface to access session variables.
 type EvalSessionAccessor interface {
-	// SetConfig sets a session variable to a new value.
+	// SetConfig sets a session variable to a new value. If isLocal is set, the
+	// previous value is restored when the current transaction ends.
 	//
 	// This interface only supports strings as this is sufficient for
 	// pg_catalog.set_config().
-	SetSessionVar(ctx context.Context, settingName, newValue string) error
+	SetSessionVar(ctx context.Context, settingName, newValue string, isLocal bool) error
 
 	// GetSessionVar retrieves the current value of a session variable.
 	GetSessionVar(ctx context.Context, settingName string, missingOk bool) (bool, string, error)
@@ -3839,6 +3853,10 @@ func (expr *ColumnAccessExpr) Eval(ctx *EvalContext) (Datum, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Accessing a field of a NULL composite value results in NULL.
+	if d == DNull {
+		return d, nil
+	}
 	return d.(*DTuple).D[expr.ColIndex], nil
 }
 
@@ -81,3 +81,64 @@ type FunctionReference interface {
 
 func (*UnresolvedName) functionReference()     {}
 func (*FunctionDefinition) functionReference() {}
+
+// FunctionName corresponds to the name of a user defined function in a CREATE
+// FUNCTION or DROP FUNCTION statement, or in an error message.
+type FunctionName struct {
+	objName
+}
+
+// Function returns the unqualified name of this FunctionName.
+func (f *FunctionName) Function() string {
+	return string(f.ObjectName)
+}
+
+// Format implements the NodeFormatter interface.
+func (f *FunctionName) Format(ctx *FmtCtx) {
+	f.ObjectNamePrefix.Format(ctx)
+	if f.ExplicitSchema || ctx.alwaysFormatTablePrefix() {
+		ctx.WriteByte('.')
+	}
+	ctx.FormatNode(&f.ObjectName)
+}
+
+// String implements the Stringer interface.
+func (f *FunctionName) String() string {
+	return AsString(f)
+}
+
+// FQString renders the function name in full, not omitting the prefix
+// schema and catalog names. Suitable for logging, etc.
+func (f *FunctionName) FQString() string {
+	ctx := NewFmtCtx(FmtSimple)
+	ctx.FormatNode(&f.CatalogName)
+	ctx.WriteByte('.')
+	ctx.FormatNode(&f.SchemaName)
+	ctx.WriteByte('.')
+	ctx.FormatNode(&f.ObjectName)
+	return ctx.CloseAndGetString()
+}
+
+func (f *FunctionName) objectName() {}
+
+// MakeFunctionNameFromPrefix creates a function name from an unqualified name
+// and a resolved prefix.
+func MakeFunctionNameFromPrefix(prefix ObjectNamePrefix, object Name) FunctionName {
+	return FunctionName{objName{
+		ObjectNamePrefix: prefix,
+		ObjectName:       object,
+	}}
+}
+
+// MakeNewQualifiedFunctionName creates a fully qualified function name.
+func MakeNewQualifiedFunctionName(db, schema, fn string) FunctionName {
+	return FunctionName{objName{
+		ObjectNamePrefix: ObjectNamePrefix{
+			ExplicitCatalog: true,
+			ExplicitSchema:  true,
+			CatalogName:     Name(db),
+			SchemaName:      Name(schema),
+		},
+		ObjectName: Name(fn),
+	}}
+}
@@ -41,6 +41,7 @@ type TargetList struct {
 	Tables    TablePatterns
 	Tenant    roachpb.TenantID
 	Types     []*UnresolvedObjectName
+	Functions []*UnresolvedObjectName
 
 	// ForRoles and Roles are used internally in the parser and not used
 	// in the AST. Therefore they do not participate in pretty-printing,
@@ -67,6 +68,14 @@ func (tl *TargetList) Format(ctx *FmtCtx) {
 			}
 			ctx.FormatNode(typ)
 		}
+	} else if tl.Functions != nil {
+		ctx.WriteString("FUNCTION ")
+		for i, fn := range tl.Functions {
+			if i != 0 {
+				ctx.WriteString(", ")
+			}
+			ctx.FormatNode(fn)
+		}
 	} else {
 		ctx.WriteString("TABLE ")
 		ctx.FormatNode(&tl.Tables)
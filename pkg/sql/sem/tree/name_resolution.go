@@ -607,6 +607,9 @@ const (
 	TableObject DesiredObjectKind = iota
 	// TypeObject is used when a type-like object is desired from resolution.
 	TypeObject
+	// FunctionObject is used when a user defined function is desired from
+	// resolution.
+	FunctionObject
 )
 
 // NewQualifiedObjectName returns an ObjectName of the corresponding kind.
@@ -620,6 +623,9 @@ func NewQualifiedObjectName(catalog, schema, object string, kind DesiredObjectKi
 	case TypeObject:
 		name := MakeNewQualifiedTypeName(catalog, schema, object)
 		return &name
+	case FunctionObject:
+		name := MakeNewQualifiedFunctionName(catalog, schema, object)
+		return &name
 	}
 	return nil
 }
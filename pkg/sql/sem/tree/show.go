@@ -83,6 +83,10 @@ const (
 	BackupRangeDetails
 	// BackupFileDetails identifies a SHOW BACKUP FILES statement.
 	BackupFileDetails
+	// BackupValidateDetails identifies a SHOW BACKUP VALIDATE statement.
+	BackupValidateDetails
+	// BackupRowDetails identifies a SHOW BACKUP ROWS statement.
+	BackupRowDetails
 )
 
 // ShowBackup represents a SHOW BACKUP statement.
@@ -92,6 +96,11 @@ type ShowBackup struct {
 	Details              BackupDetails
 	ShouldIncludeSchemas bool
 	Options              KVOptions
+
+	// Table and Keys are the table and the primary keys of the rows shown by
+	// a SHOW BACKUP ROWS statement.
+	Table *UnresolvedObjectName
+	Keys  Exprs
 }
 
 // Format implements the NodeFormatter interface.
@@ -106,6 +115,14 @@ func (node *ShowBackup) Format(ctx *FmtCtx) {
 		ctx.WriteString("RANGES ")
 	} else if node.Details == BackupFileDetails {
 		ctx.WriteString("FILES ")
+	} else if node.Details == BackupValidateDetails {
+		ctx.WriteString("VALIDATE ")
+	} else if node.Details == BackupRowDetails {
+		ctx.WriteString("ROWS FOR TABLE ")
+		ctx.FormatNode(node.Table)
+		ctx.WriteString(" KEYS (")
+		ctx.FormatNode(&node.Keys)
+		ctx.WriteString(") FROM ")
 	}
 	if node.ShouldIncludeSchemas {
 		ctx.WriteString("SCHEMAS ")
@@ -481,6 +498,17 @@ func (node *ShowCreate) Format(ctx *FmtCtx) {
 	ctx.FormatNode(node.Name)
 }
 
+// ShowCreateFunction represents a SHOW CREATE FUNCTION statement.
+type ShowCreateFunction struct {
+	Name *UnresolvedObjectName
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ShowCreateFunction) Format(ctx *FmtCtx) {
+	ctx.WriteString("SHOW CREATE FUNCTION ")
+	ctx.FormatNode(node.Name)
+}
+
 // ShowSyntax represents a SHOW SYNTAX statement.
 // This the most lightweight thing that can be done on a statement
 // server-side: just report the statement that was entered without
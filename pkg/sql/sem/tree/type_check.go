@@ -422,6 +422,11 @@ func isCastDeepValid(castFrom, castTo *types.T) (bool, telemetry.Counter, Volati
 	case toFamily == types.EnumFamily && fromFamily == types.EnumFamily:
 		// Casts from ENUM to ENUM type can only succeed if the two enums
 		return castFrom.Equivalent(castTo), sqltelemetry.EnumCastCounter, VolatilityImmutable
+	case toFamily == types.TupleFamily && fromFamily == types.TupleFamily:
+		// Casts between tuples, such as casts to composite types, are valid if
+		// all the fields can be cast.
+		v, ok := LookupCastVolatility(castFrom, castTo)
+		return ok, sqltelemetry.TupleCastCounter, v
 	}
 
 	cast := lookupCast(fromFamily, toFamily)
@@ -12,6 +12,7 @@ package sql
 
 import (
 	"context"
+	"sort"
 	"strings"
 	"time"
 
@@ -28,12 +29,14 @@ import (
 	"github.com/cockroachdb/errors"
 )
 
-// setVarNode represents a SET SESSION statement.
+// setVarNode represents a SET SESSION or SET LOCAL statement.
 type setVarNode struct {
 	name string
 	v    sessionVar
 	// typedValues == nil means RESET.
 	typedValues []tree.TypedExpr
+	// local is set for SET LOCAL.
+	local bool
 }
 
 // SetVar sets session variables.
@@ -94,7 +97,7 @@ func (p *planner) SetVar(ctx context.Context, n *tree.SetVar) (planNode, error)
 		}
 	}
 
-	return &setVarNode{name: name, v: v, typedValues: typedValues}, nil
+	return &setVarNode{name: name, v: v, typedValues: typedValues, local: n.Local}, nil
 }
 
 func (n *setVarNode) startExec(params runParams) error {
@@ -133,7 +136,117 @@ func (n *setVarNode) startExec(params runParams) error {
 	if n.v.RuntimeSet != nil {
 		return n.v.RuntimeSet(params.ctx, params.extendedEvalCtx, strVal)
 	}
-	return n.v.Set(params.ctx, params.p.sessionDataMutator, strVal)
+	if n.local && params.extendedEvalCtx.TxnImplicit {
+		// Like in Postgres, SET LOCAL has no effect outside of a transaction
+		// block.
+		params.p.BufferClientNotice(params.ctx, pgnotice.NewWithSeverityf(
+			"WARNING", "SET LOCAL can only be used in transaction blocks",
+		))
+	}
+	return params.p.applySessionVar(params.ctx, n.name, n.v, strVal, n.local)
+}
+
+// applySessionVar sets the given session variable. If isLocal is set, the
+// previous value of the variable is restored when the transaction ends.
+func (p *planner) applySessionVar(
+	ctx context.Context, name string, v sessionVar, val string, isLocal bool,
+) error {
+	lv := p.extendedEvalCtx.localSessionVars
+	if v.Get == nil {
+		// The previous value of the variable can't be recorded.
+		if isLocal {
+			return pgerror.Newf(pgcode.FeatureNotSupported,
+				"SET LOCAL is not supported for %s", name)
+		}
+		lv = nil
+	}
+	if isLocal && lv != nil {
+		lv.recordLocal(name, v.Get(&p.extendedEvalCtx))
+	}
+	if err := v.Set(ctx, p.sessionDataMutator, val); err != nil {
+		return err
+	}
+	if !isLocal && lv != nil {
+		lv.recordSession(name, v.Get(&p.extendedEvalCtx))
+	}
+	return nil
+}
+
+// localSessionVars tracks the session variables modified by SET LOCAL in the
+// current transaction.
+type localSessionVars struct {
+	// values maps the names of the variables modified by SET LOCAL to the
+	// values they are restored to when the transaction ends.
+	values map[string]string
+	// savepoints is the savepoint stack of the transaction. Each savepoint
+	// records the values that the variables modified by SET LOCAL since its
+	// creation are restored to when the transaction rolls back to it.
+	savepoints *savepointStack
+}
+
+// recordLocal records the current value of a variable modified by SET LOCAL,
+// unless the transaction and its savepoints already know what to restore the
+// variable to.
+func (lv *localSessionVars) recordLocal(name, cur string) {
+	if _, ok := lv.values[name]; !ok {
+		if lv.values == nil {
+			lv.values = make(map[string]string)
+		}
+		lv.values[name] = cur
+	}
+	for i := range *lv.savepoints {
+		sp := &(*lv.savepoints)[i]
+		if _, ok := sp.sessionVars[name]; !ok {
+			if sp.sessionVars == nil {
+				sp.sessionVars = make(map[string]string)
+			}
+			sp.sessionVars[name] = cur
+		}
+	}
+}
+
+// recordSession is called when a variable is modified by SET SESSION. The
+// effects of SET SESSION outlive the transaction, so the new value replaces
+// the value that the variable would be restored to.
+func (lv *localSessionVars) recordSession(name, val string) {
+	if _, ok := lv.values[name]; ok {
+		lv.values[name] = val
+	}
+	for i := range *lv.savepoints {
+		if _, ok := (*lv.savepoints)[i].sessionVars[name]; ok {
+			(*lv.savepoints)[i].sessionVars[name] = val
+		}
+	}
+}
+
+// restoreSessionVars sets the given session variables back to the given
+// values, reporting the changes to the client through res if possible.
+func (ex *connExecutor) restoreSessionVars(
+	ctx context.Context, vals map[string]string, res interface{},
+) error {
+	if len(vals) == 0 || ex.dataMutator == nil {
+		return nil
+	}
+	if u, ok := res.(paramStatusUpdater); ok {
+		ex.dataMutator.paramStatusUpdater = u
+	} else {
+		ex.dataMutator.paramStatusUpdater = &noopParamStatusUpdater{}
+	}
+	names := make([]string, 0, len(vals))
+	for name := range vals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		_, v, err := getSessionVar(name, false /* missingOk */)
+		if err != nil {
+			return err
+		}
+		if err := v.Set(ctx, ex.dataMutator, vals[name]); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // getSessionVarDefaultString retrieves a string suitable to pass to a
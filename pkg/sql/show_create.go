@@ -145,6 +145,11 @@ func ShowCreateTable(
 		*desc.GetPrimaryIndex())
 	for i := range allIdx {
 		idx := &allIdx[i]
+		// Indexes that back exclusion constraints are shown as part of the
+		// constraints.
+		if isExclusionConstraintIndex(desc, idx.ID) {
+			continue
+		}
 		// Only add indexes to the create_statement column, and not to the
 		// create_nofks column if they are not associated with an INTERLEAVE
 		// statement.
@@ -203,6 +208,8 @@ func ShowCreateTable(
 		return "", err
 	}
 
+	showTriggers(tn, desc, &f.Buffer)
+
 	if !displayOptions.IgnoreComments {
 		if err := showComments(tn, desc, selectComment(ctx, p, desc.GetID()), &f.Buffer); err != nil {
 			return "", err
@@ -256,3 +263,14 @@ func (p *planner) ShowCreate(
 
 	return stmt, err
 }
+
+// isExclusionConstraintIndex returns true if the index with the given ID backs
+// an exclusion constraint of the table.
+func isExclusionConstraintIndex(desc catalog.TableDescriptor, indexID descpb.IndexID) bool {
+	for _, ec := range desc.GetExclusionConstraints() {
+		if ec.IndexID == indexID {
+			return true
+		}
+	}
+	return false
+}
@@ -103,6 +103,21 @@ func ShowCreateView(
 
 // showComments prints out the COMMENT statements sufficient to populate a
 // table's comments, including its index and column comments.
+// showTriggers adds the CREATE TRIGGER statements for the triggers of the
+// table to buf.
+func showTriggers(tn *tree.TableName, table catalog.TableDescriptor, buf *bytes.Buffer) {
+	triggers := table.GetTriggers()
+	if len(triggers) == 0 {
+		return
+	}
+	f := tree.NewFmtCtx(tree.FmtSimple)
+	for i := range triggers {
+		f.WriteString(";\n")
+		f.FormatNode(makeCreateTriggerAST(tn, &triggers[i]))
+	}
+	buf.WriteString(f.CloseAndGetString())
+}
+
 func showComments(
 	tn *tree.TableName, table catalog.TableDescriptor, tc *tableComments, buf *bytes.Buffer,
 ) error {
@@ -215,6 +230,11 @@ func showForeignKeyConstraint(
 		buf.WriteString(" ON UPDATE ")
 		buf.WriteString(fk.OnUpdate.String())
 	}
+	// We omit NOT DEFERRABLE because it is the default.
+	if fk.Deferrability != descpb.ConstraintDeferrability_NotDeferrable {
+		buf.WriteByte(' ')
+		buf.WriteString(descpb.ConstraintDeferrabilityType[fk.Deferrability].String())
+	}
 	if fk.Validity != descpb.ConstraintValidity_Validated {
 		buf.WriteString(" NOT VALID")
 	}
@@ -456,10 +476,24 @@ func showConstraintClause(
 		}
 		f.WriteString(strings.Join(colNames, ", "))
 		f.WriteString(")")
+		if c.Deferrability != descpb.ConstraintDeferrability_NotDeferrable {
+			f.WriteString(" ")
+			f.WriteString(descpb.ConstraintDeferrabilityType[c.Deferrability].String())
+		}
 		if c.Validity != descpb.ConstraintValidity_Validated {
 			f.WriteString(" NOT VALID")
 		}
 	}
+	exclusions := desc.GetExclusionConstraints()
+	for i := range exclusions {
+		def, err := tabledesc.MakeExclusionConstraintDef(desc, &exclusions[i])
+		if err != nil {
+			return err
+		}
+		def.Name = tree.Name(exclusions[i].Name)
+		f.WriteString(",\n\t")
+		f.FormatNode(def)
+	}
 	f.WriteString("\n)")
 	return nil
 }
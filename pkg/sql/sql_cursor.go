@@ -0,0 +1,330 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowcontainer"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// sqlCursor is a cursor created with DECLARE.
+//
+// Like in Postgres, cursors are portals: they share the namespace of the
+// portals created through the extended protocol, and they are closed when the
+// transaction that declared them ends. WITH HOLD cursors, which outlive their
+// transaction, are not supported.
+//
+// The query of a cursor runs to completion in the transaction that declared
+// it, through an InternalExecutor, and its rows are kept until the cursor is
+// closed, in memory up to the work_mem limit and on disk beyond it. Like in
+// Postgres, a cursor therefore does not observe the writes performed by its
+// transaction after it was declared. Cursors can only move forward.
+type sqlCursor struct {
+	cols colinfo.ResultColumns
+	rows *rowcontainer.DiskBackedRowContainer
+	// memMon and diskMon account for the memory and disk used by rows.
+	memMon, diskMon *mon.BytesMonitor
+	// iter iterates over rows, and is positioned on the row the cursor is on
+	// once it has moved.
+	iter rowcontainer.RowIterator
+	// curRow is the row the cursor is on, or nil.
+	curRow tree.Datums
+	alloc  rowenc.DatumAlloc
+	// pos is the position of the cursor: 0 before the first row, n on the n-th
+	// row, and one past the last row once all the rows have been fetched.
+	pos int64
+	// atEnd is set once all the rows have been fetched.
+	atEnd bool
+}
+
+// next moves the cursor to the next row. It returns false if there are no more
+// rows.
+func (c *sqlCursor) next(ctx context.Context) (bool, error) {
+	if c.atEnd {
+		return false, nil
+	}
+	c.pos++
+	c.curRow = nil
+	if c.pos > int64(c.rows.Len()) {
+		c.atEnd = true
+		return false, nil
+	}
+	if c.iter == nil {
+		c.iter = c.rows.NewIterator(ctx)
+		c.iter.Rewind()
+	} else {
+		c.iter.Next()
+	}
+	if ok, err := c.iter.Valid(); err != nil || !ok {
+		return false, errors.CombineErrors(
+			errors.AssertionFailedf("cursor row %d is missing", c.pos), err)
+	}
+	row, err := c.iter.Row()
+	if err != nil {
+		return false, err
+	}
+	c.curRow = make(tree.Datums, len(row))
+	for i := range row {
+		if err := row[i].EnsureDecoded(c.cols[i].Typ, &c.alloc); err != nil {
+			return false, err
+		}
+		c.curRow[i] = row[i].Datum
+	}
+	return true, nil
+}
+
+// cur returns the row the cursor is on, or nil if it is before the first row
+// or after the last one.
+func (c *sqlCursor) cur() tree.Datums {
+	return c.curRow
+}
+
+func (c *sqlCursor) close(ctx context.Context) {
+	if c.iter != nil {
+		c.iter.Close()
+	}
+	c.rows.Close(ctx)
+	c.memMon.Stop(ctx)
+	c.diskMon.Stop(ctx)
+}
+
+var errCursorBackwardScan = pgerror.New(
+	pgcode.ObjectNotInPrerequisiteState, "cursor can only scan forward")
+
+// fetch moves the cursor as requested by a FETCH or MOVE statement, and calls
+// emit for each row that FETCH returns.
+func (c *sqlCursor) fetch(
+	ctx context.Context, s *tree.CursorStmt, emit func(tree.Datums) error,
+) error {
+	// fetchCurrent emits the row the cursor is on.
+	fetchCurrent := func() error {
+		if row := c.cur(); row != nil {
+			return emit(row)
+		}
+		return nil
+	}
+	// skipAndFetch skips n-1 rows and emits the n-th one.
+	skipAndFetch := func(n int64) error {
+		for i := int64(0); i < n; i++ {
+			if ok, err := c.next(ctx); err != nil || !ok {
+				return err
+			}
+		}
+		return fetchCurrent()
+	}
+
+	switch s.Direction {
+	case tree.FetchForward, tree.FetchBackward:
+		n := s.Count
+		if s.Direction == tree.FetchBackward {
+			n = -n
+		}
+		switch {
+		case n < 0:
+			return errCursorBackwardScan
+		case n == 0:
+			return fetchCurrent()
+		}
+		for i := int64(0); i < n; i++ {
+			ok, err := c.next(ctx)
+			if err != nil || !ok {
+				return err
+			}
+			if err := emit(c.cur()); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case tree.FetchRelative:
+		switch {
+		case s.Count < 0:
+			return errCursorBackwardScan
+		case s.Count == 0:
+			return fetchCurrent()
+		}
+		return skipAndFetch(s.Count)
+
+	case tree.FetchAbsolute:
+		// Negative positions are relative to the end of the results, which
+		// requires a backward scan.
+		if s.Count < 0 || s.Count < c.pos || (c.atEnd && s.Count != c.pos) {
+			return errCursorBackwardScan
+		}
+		if s.Count == c.pos {
+			return fetchCurrent()
+		}
+		return skipAndFetch(s.Count - c.pos)
+	}
+	return nil
+}
+
+// getCursor returns the cursor with the given name.
+func (ex *connExecutor) getCursor(name tree.Name) (*sqlCursor, error) {
+	portal, ok := ex.extraTxnState.prepStmtsNamespace.portals[string(name)]
+	if !ok || portal.cursor == nil {
+		return nil, pgerror.Newf(pgcode.InvalidCursorName, "cursor %q does not exist", name)
+	}
+	return portal.cursor, nil
+}
+
+// execDeclareCursor executes a DECLARE statement: the query of the cursor is
+// run on a new InternalExecutor, and its rows are kept until they are fetched.
+func (ex *connExecutor) execDeclareCursor(
+	ctx context.Context, s *tree.DeclareCursor, stmt Statement,
+) error {
+	name := string(s.Name)
+	if _, ok := ex.extraTxnState.prepStmtsNamespace.portals[name]; ok {
+		return pgerror.Newf(pgcode.DuplicateCursor, "cursor %q already exists", name)
+	}
+	if stmt.NumPlaceholders > 0 {
+		return pgerror.New(pgcode.FeatureNotSupported,
+			"DECLARE CURSOR does not support placeholders")
+	}
+
+	query := parser.Statement{
+		SQL:            tree.AsStringWithFlags(s.Select, tree.FmtParsable),
+		AST:            s.Select,
+		NumAnnotations: stmt.NumAnnotations,
+	}
+	ie := MakeInternalExecutor(ctx, ex.server, ex.memMetrics, ex.server.cfg.Settings)
+	ie.SetSessionData(ex.sessionData)
+	// The query needs to see the schema changes performed by the transaction.
+	ie.tcModifier = &ex.extraTxnState.descCollection
+	it, err := ie.queryIterator(
+		ctx, "sql-cursor", ex.state.mu.txn, sessiondata.InternalExecutorOverride{}, query,
+	)
+	if err != nil {
+		return err
+	}
+	// The rows are read now, so that the cursor does not observe the writes
+	// performed by the transaction after it was declared.
+	c, err := ex.materializeCursorRows(ctx, it)
+	if err != nil {
+		return err
+	}
+
+	// The portal of the cursor describes its query, which is not prepared on
+	// this connExecutor.
+	prepared := &PreparedStatement{
+		memAcc:    ex.sessionMon.MakeBoundAccount(),
+		refCount:  1,
+		createdAt: timeutil.Now(),
+		origin:    PreparedStatementOriginSQL,
+	}
+	prepared.Statement = query
+	prepared.Columns = it.cols
+	defer prepared.decRef(ctx)
+	portal, err := ex.makePreparedPortal(ctx, name, prepared, nil /* qargs */, nil /* outFormats */)
+	if err != nil {
+		c.close(ctx)
+		return err
+	}
+	portal.cursor = c
+	ex.extraTxnState.prepStmtsNamespace.portals[name] = portal
+	return nil
+}
+
+// execFetchCursor executes a FETCH statement, or a MOVE statement if move is
+// set.
+func (ex *connExecutor) execFetchCursor(
+	ctx context.Context, s *tree.CursorStmt, move bool, res RestrictedCommandResult,
+) error {
+	c, err := ex.getCursor(s.Name)
+	if err != nil {
+		return err
+	}
+	if move {
+		return c.fetch(ctx, s, func(tree.Datums) error {
+			res.IncrementRowsAffected(1)
+			return nil
+		})
+	}
+	res.SetColumns(ctx, c.cols)
+	return c.fetch(ctx, s, func(row tree.Datums) error {
+		return res.AddRow(ctx, row)
+	})
+}
+
+// execCloseCursor executes a CLOSE statement.
+func (ex *connExecutor) execCloseCursor(ctx context.Context, s *tree.CloseCursor) error {
+	if s.All {
+		for name, portal := range ex.extraTxnState.prepStmtsNamespace.portals {
+			if portal.cursor != nil {
+				ex.deletePortal(ctx, name)
+			}
+		}
+		return nil
+	}
+	if _, err := ex.getCursor(s.Name); err != nil {
+		return err
+	}
+	ex.deletePortal(ctx, string(s.Name))
+	return nil
+}
+
+// materializeCursorRows reads all the rows of the given iterator into a new
+// cursor, and closes the iterator. The memory used by the rows is accounted
+// for by the session monitor, and rows are spilled to disk beyond the work_mem
+// limit.
+func (ex *connExecutor) materializeCursorRows(
+	ctx context.Context, it *rowsIterator,
+) (_ *sqlCursor, retErr error) {
+	defer it.Close()
+	cfg := &ex.server.cfg.DistSQLSrv.ServerConfig
+	c := &sqlCursor{
+		cols:    it.cols,
+		rows:    &rowcontainer.DiskBackedRowContainer{},
+		memMon:  execinfra.NewLimitedMonitor(ctx, ex.sessionMon, cfg, "cursor-mem"),
+		diskMon: execinfra.NewMonitor(ctx, cfg.DiskMonitor, "cursor-disk"),
+	}
+	typs := make([]*types.T, len(it.cols))
+	for i := range typs {
+		typs[i] = it.cols[i].Typ
+	}
+	c.rows.Init(
+		nil /* ordering */, typs, ex.planner.EvalContext(), cfg.TempStorage, c.memMon, c.diskMon,
+	)
+	defer func() {
+		if retErr != nil {
+			c.close(ctx)
+		}
+	}()
+	row := make(rowenc.EncDatumRow, len(typs))
+	for {
+		ok, err := it.Next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return c, nil
+		}
+		for i, d := range it.Cur() {
+			row[i] = rowenc.DatumToEncDatum(typs[i], d)
+		}
+		if err := c.rows.AddRow(ctx, row); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql_test
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/jackc/pgx"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCursorExtendedProtocol checks that the rows of a cursor can be fetched
+// in batches with a prepared FETCH statement, while the transaction that
+// declared the cursor is used by other statements.
+func TestCursorExtendedProtocol(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, sqlDB, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	const numRows = 2500
+	const batchSize = 1000
+	runner := sqlutils.MakeSQLRunner(sqlDB)
+	runner.Exec(t, "CREATE TABLE t (a INT PRIMARY KEY)")
+	runner.Exec(t, "INSERT INTO t SELECT generate_series(1, $1)", numRows)
+	runner.Exec(t, "CREATE TABLE log (batch INT)")
+
+	pgURL, cleanup := sqlutils.PGUrl(t, s.ServingSQLAddr(), "TestCursorExtendedProtocol", url.User("root"))
+	defer cleanup()
+	conf, err := pgx.ParseConnectionString(pgURL.String())
+	require.NoError(t, err)
+	conn, err := pgx.Connect(conf)
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	tx, err := conn.Begin()
+	require.NoError(t, err)
+	_, err = tx.Exec("DECLARE c CURSOR FOR SELECT a FROM t ORDER BY a")
+	require.NoError(t, err)
+	_, err = conn.Prepare("fetch", "FETCH 1000 c")
+	require.NoError(t, err)
+
+	var next int64 = 1
+	for batch := 0; ; batch++ {
+		rows, err := tx.Query("fetch")
+		require.NoError(t, err)
+		var n int
+		for rows.Next() {
+			var a int64
+			require.NoError(t, rows.Scan(&a))
+			require.Equal(t, next, a)
+			next++
+			n++
+		}
+		require.NoError(t, rows.Err())
+		if n == 0 {
+			break
+		}
+		require.LessOrEqual(t, n, batchSize)
+		_, err = tx.Exec("INSERT INTO log VALUES ($1)", batch)
+		require.NoError(t, err)
+	}
+	require.Equal(t, int64(numRows+1), next)
+
+	require.NoError(t, tx.Commit())
+	runner.CheckQueryResults(t, "SELECT count(*) FROM log", [][]string{{"3"}})
+}
+
+// TestCursorLargerThanMemoryBudget checks that a cursor can hold more rows
+// than fit in the memory budget of the server, since they spill to disk.
+func TestCursorLargerThanMemoryBudget(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const lowMemoryBudget = 500000
+	ctx := context.Background()
+	s, sqlDB, _ := serverutils.StartServer(t, base.TestServerArgs{
+		SQLMemoryPoolSize: lowMemoryBudget,
+	})
+	defer s.Stopper().Stop(ctx)
+
+	// The rows of the cursor take about ten times the memory budget.
+	const numRows = 5000
+	const rowSize = 1000
+	tx, err := sqlDB.Begin()
+	require.NoError(t, err)
+	_, err = tx.Exec(fmt.Sprintf(
+		"DECLARE c CURSOR FOR SELECT i, repeat('a', %d) FROM generate_series(1, %d) AS g(i)",
+		rowSize, numRows,
+	))
+	require.NoError(t, err)
+
+	var next int64 = 1
+	for {
+		rows, err := tx.Query("FETCH 100 c")
+		require.NoError(t, err)
+		var n int
+		for rows.Next() {
+			var i int64
+			var s string
+			require.NoError(t, rows.Scan(&i, &s))
+			require.Equal(t, next, i)
+			require.Len(t, s, rowSize)
+			next++
+			n++
+		}
+		require.NoError(t, rows.Err())
+		if n == 0 {
+			break
+		}
+	}
+	require.Equal(t, int64(numRows+1), next)
+	require.NoError(t, tx.Commit())
+}
@@ -45,6 +45,10 @@ var ForeignKeyChecksUseCounter = telemetry.GetCounterOnce("sql.plan.fk.checks")
 // involves a cascade.
 var ForeignKeyCascadesUseCounter = telemetry.GetCounterOnce("sql.plan.fk.cascades")
 
+// TriggerUseCounter is to be incremented every time a mutation fires a
+// row-level trigger.
+var TriggerUseCounter = telemetry.GetCounterOnce("sql.plan.triggers")
+
 // LateralJoinUseCounter is to be incremented whenever a query uses the
 // LATERAL keyword.
 var LateralJoinUseCounter = telemetry.GetCounterOnce("sql.plan.lateral-join")
@@ -56,6 +56,10 @@ var ArrayCastCounter = telemetry.GetCounterOnce("sql.plan.ops.cast.arrays")
 // are between enums.
 var EnumCastCounter = telemetry.GetCounterOnce("sql.plan.ops.cast.enums")
 
+// TupleCastCounter is to be incremented when typechecking casts that
+// are between tuples, such as casts to composite types.
+var TupleCastCounter = telemetry.GetCounterOnce("sql.plan.ops.cast.tuples")
+
 // ArrayConstructorCounter is to be incremented upon type checking
 // of ARRAY[...] expressions/
 var ArrayConstructorCounter = telemetry.GetCounterOnce("sql.plan.ops.array.cons")
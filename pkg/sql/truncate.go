@@ -211,6 +211,11 @@ func (p *planner) truncateTable(
 		index.ID = descpb.IndexID(0)
 		tableDesc.SetPublicNonPrimaryIndex(i+1, index)
 	}
+	// The exclusion constraints are pointed at their new backing indexes when
+	// the IDs are allocated.
+	for i := range tableDesc.ExclusionConstraints {
+		tableDesc.ExclusionConstraints[i].IndexID = 0
+	}
 	// Create new ID's for all of the indexes in the table.
 	if err := tableDesc.AllocateIDs(ctx); err != nil {
 		return err
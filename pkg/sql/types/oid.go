@@ -205,6 +205,16 @@ func calcArrayOid(elemTyp *T) oid.Oid {
 
 	case EnumFamily:
 		return elemTyp.UserDefinedArrayOID()
+
+	case TupleFamily:
+		if elemTyp.UserDefined() {
+			return elemTyp.UserDefinedArrayOID()
+		}
+
+	default:
+		if elemTyp.IsDomain() {
+			return elemTyp.UserDefinedArrayOID()
+		}
 	}
 
 	// Map the OID of the array element type to the corresponding array OID.
@@ -194,6 +194,9 @@ type UserDefinedTypeMetadata struct {
 
 	// enumData is non-nil iff the metadata is for an ENUM type.
 	EnumData *EnumMetadata
+
+	// DomainData is non-nil iff the metadata is for a domain.
+	DomainData *DomainMetadata
 }
 
 // EnumMetadata is metadata about an ENUM needed for evaluation.
@@ -212,6 +215,28 @@ type EnumMetadata struct {
 	//  should occur, if at all.
 }
 
+// DomainMetadata is metadata about a domain needed for planning. The
+// constraints of a domain are enforced by the checks that the optimizer
+// synthesizes for columns of the domain when they are written. Casts to a
+// domain do not enforce them.
+type DomainMetadata struct {
+	// BaseType is the type that the domain is defined over.
+	BaseType *T
+	// DefaultExpr is the default expression of the domain, if any.
+	DefaultExpr *string
+	// NotNull is true if the domain does not allow NULL values.
+	NotNull bool
+	// Checks contains the CHECK constraints of the domain.
+	Checks []DomainCheck
+}
+
+// DomainCheck is a CHECK constraint of a domain. The checked value is
+// referred to as VALUE in the expression.
+type DomainCheck struct {
+	Name string
+	Expr string
+}
+
 func (e *EnumMetadata) debugString() string {
 	return fmt.Sprintf(
 		"PhysicalReps: %v; LogicalReps: %s",
@@ -1092,6 +1117,32 @@ func MakeLabeledTuple(contents []*T, labels []string) *T {
 	}}
 }
 
+// MakeComposite constructs a new instance of a TupleFamily type for a user
+// defined composite type with the given stable type ID, field types and
+// labels. Note that it does not hydrate cached fields on the type.
+func MakeComposite(typeOID, arrayTypeOID oid.Oid, contents []*T, labels []string) *T {
+	typ := MakeLabeledTuple(contents, labels)
+	typ.InternalType.Oid = typeOID
+	typ.InternalType.UDTMetadata = &PersistentUserDefinedTypeMetadata{
+		ArrayTypeOID: arrayTypeOID,
+	}
+	return typ
+}
+
+// MakeDomain constructs a new instance of a domain type with the given stable
+// type ID over the given base type. The domain has the family, width and other
+// properties of the base type, but the OID of the domain. Note that it does
+// not hydrate cached fields on the type.
+func MakeDomain(typeOID, arrayTypeOID oid.Oid, base *T) *T {
+	typ := *base
+	typ.InternalType.Oid = typeOID
+	typ.InternalType.UDTMetadata = &PersistentUserDefinedTypeMetadata{
+		ArrayTypeOID: arrayTypeOID,
+	}
+	typ.TypeMeta = UserDefinedTypeMetadata{}
+	return &typ
+}
+
 // Family specifies a group of types that are compatible with one another. Types
 // in the same family can be compared, assigned, etc., but may differ from one
 // another in width, precision, locale, and other attributes. For example, it is
@@ -1262,6 +1313,25 @@ func (t *T) UserDefined() bool {
 	return IsOIDUserDefinedType(t.Oid())
 }
 
+// IsDomain returns whether or not t is a domain. Domains are the only user
+// defined types that belong to the family of a built-in type.
+func (t *T) IsDomain() bool {
+	switch t.Family() {
+	case EnumFamily, TupleFamily, ArrayFamily:
+		return false
+	}
+	return t.UserDefined()
+}
+
+// DomainBaseType returns the type that the domain t is defined over. It
+// returns nil if t is not a domain, or if its metadata has not been hydrated.
+func (t *T) DomainBaseType() *T {
+	if t.TypeMeta.DomainData == nil {
+		return nil
+	}
+	return t.TypeMeta.DomainData.BaseType
+}
+
 // IsOIDUserDefinedType returns whether or not o corresponds to a user
 // defined type.
 func IsOIDUserDefinedType(o oid.Oid) bool {
@@ -1317,6 +1387,13 @@ func (f Family) Name() string {
 //
 // TODO(andyk): Should these be changed to be the same as SQLStandardName?
 func (t *T) Name() string {
+	if t.IsDomain() {
+		// This can be nil during unit testing.
+		if t.TypeMeta.Name == nil {
+			return "unknown_domain"
+		}
+		return t.TypeMeta.Name.Basename()
+	}
 	switch fam := t.Family(); fam {
 	case AnyFamily:
 		return "anyelement"
@@ -1378,7 +1455,14 @@ func (t *T) Name() string {
 		panic(errors.AssertionFailedf("unexpected OID: %d", t.Oid()))
 
 	case TupleFamily:
-		// Tuple types are currently anonymous, with no name.
+		if t.UserDefined() {
+			// This can be nil during unit testing.
+			if t.TypeMeta.Name == nil {
+				return "unknown_composite"
+			}
+			return t.TypeMeta.Name.Basename()
+		}
+		// Other tuple types are anonymous, with no name.
 		return ""
 
 	case EnumFamily:
@@ -1457,6 +1541,9 @@ func (t *T) TelemetryName() string {
 // This function is full of special cases. See backend/utils/adt/format_type.c
 // in Postgres.
 func (t *T) SQLStandardNameWithTypmod(haveTypmod bool, typmod int) string {
+	if t.IsDomain() {
+		return t.Name()
+	}
 	var buf strings.Builder
 	switch t.Family() {
 	case AnyFamily:
@@ -1610,6 +1697,9 @@ func (t *T) SQLStandardNameWithTypmod(haveTypmod bool, typmod int) string {
 		}
 		return fmt.Sprintf("timestamp(%d) with time zone", typmod)
 	case TupleFamily:
+		if t.UserDefined() {
+			return t.TypeMeta.Name.Basename()
+		}
 		return "record"
 	case UnknownFamily:
 		return "unknown"
@@ -1639,6 +1729,9 @@ func (t *T) InformationSchemaName() string {
 // reproduce the type via parsing the string as a type. It is used in error
 // messages and also to produce the output of SHOW CREATE.
 func (t *T) SQLString() string {
+	if t.IsDomain() && t.TypeMeta.Name != nil {
+		return t.TypeMeta.Name.FQName()
+	}
 	switch t.Family() {
 	case BitFamily:
 		o := t.Oid()
@@ -1733,6 +1826,10 @@ func (t *T) SQLString() string {
 			return "anyenum"
 		}
 		return t.TypeMeta.Name.FQName()
+	case TupleFamily:
+		if t.UserDefined() {
+			return t.TypeMeta.Name.FQName()
+		}
 	}
 	return strings.ToUpper(t.Name())
 }
@@ -1977,6 +2074,13 @@ func (t *T) Unmarshal(data []byte) error {
 // setting required values. This is necessary to preserve backwards-
 // compatibility with older formats (e.g. restoring database from old backup).
 func (t *T) upgradeType() error {
+	// Domains have the OID of the domain rather than the OID that corresponds
+	// to their family and width, so it must not be overwritten below.
+	var domainOID oid.Oid
+	if t.IsDomain() {
+		domainOID = t.Oid()
+	}
+
 	switch t.Family() {
 	case IntFamily:
 		// Check VisibleType field that was populated in previous versions.
@@ -2151,6 +2255,9 @@ func (t *T) upgradeType() error {
 	if t.InternalType.Oid == 0 {
 		t.InternalType.Oid = familyToOid[t.Family()]
 	}
+	if domainOID != 0 {
+		t.InternalType.Oid = domainOID
+	}
 
 	// Clear the deprecated visible types, since they are now handled by the
 	// Width or Oid fields.
@@ -2228,7 +2335,9 @@ func (t *T) downgradeType() error {
 		case oid.T_name:
 			t.InternalType.Family = name
 		default:
-			return errors.AssertionFailedf("unexpected Oid: %d", t.Oid())
+			if !t.IsDomain() {
+				return errors.AssertionFailedf("unexpected Oid: %d", t.Oid())
+			}
 		}
 
 	case ArrayFamily:
@@ -2272,6 +2381,9 @@ func (t *T) downgradeType() error {
 // TODO(andyk): It'd be nice to have this return SqlString() method output,
 // since that is more descriptive.
 func (t *T) String() string {
+	if t.IsDomain() {
+		return t.Name()
+	}
 	switch t.Family() {
 	case CollatedStringFamily:
 		if t.Locale() == "" {
@@ -2288,6 +2400,9 @@ func (t *T) String() string {
 		return t.ArrayContents().String() + "[]"
 
 	case TupleFamily:
+		if t.UserDefined() {
+			return t.Name()
+		}
 		var buf bytes.Buffer
 		buf.WriteString("tuple")
 		if len(t.TupleContents()) != 0 && !IsWildcardTupleType(t) {
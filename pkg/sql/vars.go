@@ -1394,7 +1394,9 @@ func (p *planner) GetSessionVar(
 }
 
 // SetSessionVar implements the EvalSessionAccessor interface.
-func (p *planner) SetSessionVar(ctx context.Context, varName, newVal string) error {
+func (p *planner) SetSessionVar(
+	ctx context.Context, varName, newVal string, isLocal bool,
+) error {
 	name := strings.ToLower(varName)
 	_, v, err := getSessionVar(name, false /* missingOk */)
 	if err != nil {
@@ -1407,5 +1409,5 @@ func (p *planner) SetSessionVar(ctx context.Context, varName, newVal string) err
 	if v.RuntimeSet != nil {
 		return v.RuntimeSet(ctx, &p.extendedEvalCtx, newVal)
 	}
-	return v.Set(ctx, p.sessionDataMutator, newVal)
+	return p.applySessionVar(ctx, name, v, newVal, isLocal)
 }
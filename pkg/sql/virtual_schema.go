@@ -367,6 +367,10 @@ func (v *virtualSchemaEntry) GetObjectByName(
 			desc:    typedesc.MakeSimpleAlias(typ, catconstants.PgCatalogID),
 			mutable: flags.RequireMutable,
 		}, nil
+	case tree.FunctionObject:
+		// Functions in virtual schemas are builtins, which are resolved
+		// separately.
+		return nil, nil
 	default:
 		return nil, errors.AssertionFailedf("unknown desired object kind %d", flags.DesiredObjectKind)
 	}
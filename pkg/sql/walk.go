@@ -343,12 +343,15 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&controlJobsNode{}):             "control jobs",
 	reflect.TypeOf(&controlSchedulesNode{}):        "control schedules",
 	reflect.TypeOf(&createDatabaseNode{}):          "create database",
+	reflect.TypeOf(&createDomainNode{}):            "create domain",
 	reflect.TypeOf(&createExtensionNode{}):         "create extension",
+	reflect.TypeOf(&createFunctionNode{}):          "create function",
 	reflect.TypeOf(&createIndexNode{}):             "create index",
 	reflect.TypeOf(&createSequenceNode{}):          "create sequence",
 	reflect.TypeOf(&createSchemaNode{}):            "create schema",
 	reflect.TypeOf(&createStatsNode{}):             "create statistics",
 	reflect.TypeOf(&createTableNode{}):             "create table",
+	reflect.TypeOf(&createTriggerNode{}):           "create trigger",
 	reflect.TypeOf(&createTypeNode{}):              "create type",
 	reflect.TypeOf(&CreateRoleNode{}):              "create user/role",
 	reflect.TypeOf(&createViewNode{}):              "create view",
@@ -357,10 +360,12 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&deleteRangeNode{}):             "delete range",
 	reflect.TypeOf(&distinctNode{}):                "distinct",
 	reflect.TypeOf(&dropDatabaseNode{}):            "drop database",
+	reflect.TypeOf(&dropFunctionNode{}):            "drop function",
 	reflect.TypeOf(&dropIndexNode{}):               "drop index",
 	reflect.TypeOf(&dropSequenceNode{}):            "drop sequence",
 	reflect.TypeOf(&dropSchemaNode{}):              "drop schema",
 	reflect.TypeOf(&dropTableNode{}):               "drop table",
+	reflect.TypeOf(&dropTriggerNode{}):             "drop trigger",
 	reflect.TypeOf(&dropTypeNode{}):                "drop type",
 	reflect.TypeOf(&DropRoleNode{}):                "drop user/role",
 	reflect.TypeOf(&dropViewNode{}):                "drop view",
@@ -379,8 +384,10 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&invertedJoinNode{}):            "inverted join",
 	reflect.TypeOf(&joinNode{}):                    "join",
 	reflect.TypeOf(&limitNode{}):                   "limit",
+	reflect.TypeOf(&listenNode{}):                  "listen",
 	reflect.TypeOf(&lookupJoinNode{}):              "lookup join",
 	reflect.TypeOf(&max1RowNode{}):                 "max1row",
+	reflect.TypeOf(&notifyNode{}):                  "notify",
 	reflect.TypeOf(&ordinalityNode{}):              "ordinality",
 	reflect.TypeOf(&projectSetNode{}):              "project set",
 	reflect.TypeOf(&reassignOwnedByNode{}):         "reassign owned by",
@@ -405,6 +412,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&sequenceSelectNode{}):          "sequence select",
 	reflect.TypeOf(&serializeNode{}):               "run",
 	reflect.TypeOf(&setClusterSettingNode{}):       "set cluster setting",
+	reflect.TypeOf(&setConstraintsNode{}):          "set constraints",
 	reflect.TypeOf(&setVarNode{}):                  "set",
 	reflect.TypeOf(&setZoneConfigNode{}):           "configure zone",
 	reflect.TypeOf(&showFingerprintsNode{}):        "show fingerprints",
@@ -330,6 +330,13 @@ var backwardCompatibleMigrations = []migrationDescriptor{
 		// Introduced in v20.2.
 		name: "mark non-terminal schema change jobs with a pre-20.1 format version as failed",
 	},
+	{
+		// Introduced in v21.1.
+		name:                "create new system.notifications table",
+		workFn:              createNotificationsTable,
+		includedInBootstrap: clusterversion.ByKey(clusterversion.NotificationsTable),
+		newDescriptorIDs:    staticIDs(keys.NotificationsTableID),
+	},
 }
 
 func staticIDs(
@@ -1242,6 +1249,10 @@ func createScheduledJobsTable(ctx context.Context, r runner) error {
 	return createSystemTable(ctx, r, systemschema.ScheduledJobsTable)
 }
 
+func createNotificationsTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, systemschema.NotificationsTable)
+}
+
 func alterSystemJobsAddSqllivenessColumnsAddNewSystemSqllivenessTable(
 	ctx context.Context, r runner,
 ) error {
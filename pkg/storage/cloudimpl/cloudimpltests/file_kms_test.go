@@ -0,0 +1,109 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+package cloudimpltests
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptDecryptFileKMS(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	baseDir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+	dir := filepath.Join(baseDir, "extern")
+	require.NoError(t, os.Mkdir(dir, 0755))
+
+	writeKey := func(name string, contents string) string {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0600))
+		return "/" + name
+	}
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	keyPath := writeKey("key", hex.EncodeToString(key)+"\n")
+
+	// A directory whose name has the external IO directory as a prefix.
+	siblingDir := filepath.Join(baseDir, "extern-evil")
+	require.NoError(t, os.Mkdir(siblingDir, 0755))
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(siblingDir, "key"), []byte(hex.EncodeToString(key)), 0600))
+	settings := cluster.MakeTestingClusterSettings()
+	settings.ExternalIODir = dir
+	env := testKMSEnv{settings, &base.ExternalIODirConfig{}}
+
+	testEncryptDecrypt(t, "file://"+keyPath, env)
+
+	t.Run("master-key-id", func(t *testing.T) {
+		kms, err := cloud.KMSFromURI("file://"+keyPath, &env)
+		require.NoError(t, err)
+		id, err := kms.MasterKeyID()
+		require.NoError(t, err)
+		require.Equal(t, keyPath, id)
+	})
+
+	t.Run("wrong-key", func(t *testing.T) {
+		ctx := context.Background()
+		kms, err := cloud.KMSFromURI("file://"+keyPath, &env)
+		require.NoError(t, err)
+		ciphertext, err := kms.Encrypt(ctx, []byte("hello world"))
+		require.NoError(t, err)
+
+		otherKey := make([]byte, 32)
+		otherPath := writeKey("other", hex.EncodeToString(otherKey))
+		other, err := cloud.KMSFromURI("file://"+otherPath, &env)
+		require.NoError(t, err)
+		_, err = other.Decrypt(ctx, ciphertext)
+		require.Error(t, err)
+	})
+
+	t.Run("no-external-io-dir", func(t *testing.T) {
+		noDirEnv := testKMSEnv{cluster.MakeTestingClusterSettings(), &base.ExternalIODirConfig{}}
+		_, err := cloud.KMSFromURI("file://"+keyPath, &noDirEnv)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "local file access is disabled")
+	})
+
+	for _, tc := range []struct {
+		name   string
+		uri    string
+		errMsg string
+	}{
+		{"host", "file://somehost" + keyPath, "must not specify a host"},
+		{"outside", "file:///../key", "must be within the external IO directory"},
+		{"sibling", "file:///../extern-evil/key", "must be within the external IO directory"},
+		{"missing", "file:///missing", "invalid key file"},
+		{"not-hex", "file://" + writeKey("not-hex", "not a key"), "invalid key file"},
+		{"short", "file://" + writeKey("short", "abcd"), "invalid key file"},
+	} {
+		t.Run(fmt.Sprintf("invalid-%s", tc.name), func(t *testing.T) {
+			_, err := cloud.KMSFromURI(tc.uri, &env)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.errMsg)
+			// The error must not leak anything about the contents of the file.
+			require.NotContains(t, err.Error(), "not a key")
+			require.NotContains(t, err.Error(), "abcd")
+		})
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+package cloudimpltests
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/storage/cloudimpl"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVaultTransit is a minimal stand-in for the Vault transit secrets engine.
+// Its "ciphertext" is just the plaintext tagged with the key name, which is
+// enough to verify the request plumbing.
+func fakeVaultTransit(token, mount, keyName string) *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeErr := func(status int, msg string) {
+			w.WriteHeader(status)
+			_ = json.NewEncoder(w).Encode(map[string][]string{"errors": {msg}})
+		}
+		if r.Header.Get("X-Vault-Token") != token {
+			writeErr(http.StatusForbidden, "permission denied")
+			return
+		}
+		var req map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErr(http.StatusBadRequest, err.Error())
+			return
+		}
+		prefix := fmt.Sprintf("vault:v1:%s:", keyName)
+		var data map[string]string
+		switch r.URL.Path {
+		case fmt.Sprintf("/v1/%s/encrypt/%s", mount, keyName):
+			data = map[string]string{"ciphertext": prefix + req["plaintext"]}
+		case fmt.Sprintf("/v1/%s/decrypt/%s", mount, keyName):
+			if !strings.HasPrefix(req["ciphertext"], prefix) {
+				writeErr(http.StatusBadRequest, "invalid ciphertext")
+				return
+			}
+			data = map[string]string{"plaintext": strings.TrimPrefix(req["ciphertext"], prefix)}
+		default:
+			writeErr(http.StatusNotFound, "no handler for route")
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+}
+
+func TestEncryptDecryptVault(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const token = "s.testtoken"
+	srv := fakeVaultTransit(token, "custom-transit", "backup-key")
+	defer srv.Close()
+	srvURL, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	settings := cluster.MakeTestingClusterSettings()
+	u := settings.MakeUpdater()
+	require.NoError(t, u.Set(
+		cloudimpl.CloudstorageHTTPCASetting,
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})),
+		"s",
+	))
+	env := testKMSEnv{settings, &base.ExternalIODirConfig{}}
+
+	makeURI := func(keyName string, tok string) string {
+		q := make(url.Values)
+		q.Add(cloudimpl.VaultTokenParam, tok)
+		q.Add(cloudimpl.VaultMountParam, "custom-transit")
+		return fmt.Sprintf("vault://%s/%s?%s", srvURL.Host, keyName, q.Encode())
+	}
+
+	testEncryptDecrypt(t, makeURI("backup-key", token), env)
+
+	t.Run("master-key-id", func(t *testing.T) {
+		kms, err := cloud.KMSFromURI(makeURI("backup-key", token), &env)
+		require.NoError(t, err)
+		defer kms.Close()
+		id, err := kms.MasterKeyID()
+		require.NoError(t, err)
+		require.Equal(t, "custom-transit/backup-key", id)
+	})
+
+	t.Run("redacted", func(t *testing.T) {
+		redacted, err := cloudimpl.RedactKMSURI(makeURI("backup-key", token))
+		require.NoError(t, err)
+		require.NotContains(t, redacted, token)
+		require.NotContains(t, redacted, "backup-key")
+	})
+
+	t.Run("bad-token", func(t *testing.T) {
+		kms, err := cloud.KMSFromURI(makeURI("backup-key", "wrong"), &env)
+		require.NoError(t, err)
+		defer kms.Close()
+		_, err = kms.Encrypt(context.Background(), []byte("hello world"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "permission denied")
+	})
+
+	t.Run("missing-token", func(t *testing.T) {
+		uri := fmt.Sprintf("vault://%s/backup-key", srvURL.Host)
+		_, err := cloud.KMSFromURI(uri, &env)
+		require.EqualError(t, err,
+			fmt.Sprintf("%s must be set for vault kms", cloudimpl.VaultTokenParam))
+	})
+
+	t.Run("disable-http", func(t *testing.T) {
+		_, err := cloud.KMSFromURI(makeURI("backup-key", token),
+			&testKMSEnv{settings, &base.ExternalIODirConfig{DisableHTTP: true}})
+		require.EqualError(t, err, "vault kms disallowed due to --external-io-disable-http flag")
+	})
+}
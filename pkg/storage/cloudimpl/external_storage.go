@@ -86,6 +86,16 @@ const (
 	// the Google Application Credentials JSON file.
 	CredentialsParam = "CREDENTIALS"
 
+	// VaultTokenParam is the query parameter for the token used to authenticate
+	// with a HashiCorp Vault server in a vault KMS URI.
+	VaultTokenParam = "VAULT_TOKEN"
+	// VaultMountParam is the query parameter for the path at which the transit
+	// secrets engine is mounted in a vault KMS URI.
+	VaultMountParam = "VAULT_MOUNT"
+	// VaultNamespaceParam is the query parameter for the Vault Enterprise
+	// namespace in a vault KMS URI.
+	VaultNamespaceParam = "VAULT_NAMESPACE"
+
 	cloudstoragePrefix = "cloudstorage"
 	cloudstorageGS     = cloudstoragePrefix + ".gs"
 	cloudstorageHTTP   = cloudstoragePrefix + ".http"
@@ -112,6 +122,7 @@ var redactedQueryParams = map[string]struct{}{
 	AWSTempTokenParam:    {},
 	AzureAccountKeyParam: {},
 	CredentialsParam:     {},
+	VaultTokenParam:      {},
 }
 
 // ErrListingUnsupported is a marker for indicating listing is unsupported.
@@ -367,6 +378,9 @@ func URINeedsGlobExpansion(uri string) bool {
 //
 // - nodelocal: this is the node's shared filesystem and so only a super user
 // should be able to interact with it.
+//
+// The same rules apply to KMS URIs: a file KMS key is read from the node's
+// filesystem, and a Vault KMS is reached from the server's network.
 func AccessIsWithExplicitAuth(path string) (bool, string, error) {
 	uri, err := url.Parse(path)
 	if err != nil {
@@ -388,7 +402,13 @@ func AccessIsWithExplicitAuth(path string) (bool, string, error) {
 		// Azure does not support implicit authentication i.e. all credentials have
 		// to be specified as part of the URI.
 		hasExplicitAuth = true
-	case "http", "https", "nodelocal":
+	case awsScheme:
+		// Unlike S3 storage, an AWS KMS URI without an AUTH parameter uses the
+		// credentials specified in the URI.
+		auth := uri.Query().Get(AuthParam)
+		hasExplicitAuth = auth == "" || auth == AuthParamSpecified
+		hasExplicitAuth = hasExplicitAuth && uri.Query().Get(AWSEndpointParam) == ""
+	case "http", "https", "nodelocal", fileKMSScheme, vaultScheme:
 		hasExplicitAuth = false
 	case "experimental-workload", "workload", "userfile":
 		hasExplicitAuth = true
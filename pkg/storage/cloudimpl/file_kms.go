@@ -0,0 +1,142 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cloudimpl
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/errors"
+)
+
+const fileKMSScheme = "file"
+
+// fileKMSKeyLength is the length in bytes of the AES-256 master key that a
+// file KMS key file must contain.
+const fileKMSKeyLength = 32
+
+// errInvalidFileKMSKey is returned for any key file which cannot be read or
+// does not hold a valid key. It deliberately does not describe the contents of
+// the file, since the path is chosen by the user issuing the statement.
+var errInvalidFileKMSKey = errors.New("invalid key file")
+
+// fileKMS is a KMS backed by a master key stored in a file under the
+// external IO directory of every node. It is primarily intended for testing
+// and for on-prem deployments which do not have access to a hosted KMS.
+type fileKMS struct {
+	path string
+	aead cipher.AEAD
+}
+
+var _ cloud.KMS = &fileKMS{}
+
+func init() {
+	cloud.RegisterKMSFromURIFactory(MakeFileKMS, fileKMSScheme)
+}
+
+// MakeFileKMS is the factory method which returns a configured, ready-to-use
+// file KMS object. The URI is of the form file:///path/to/key, where the path
+// is relative to the node's external IO directory and the file contains a
+// hex-encoded 256-bit key. The file must be present at the same path on every
+// node in the cluster.
+func MakeFileKMS(uri string, env cloud.KMSEnv) (cloud.KMS, error) {
+	kmsURI, err := url.ParseRequestURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	if kmsURI.Host != "" {
+		return nil, errors.Errorf(
+			"file KMS URI must not specify a host, got %q; use file:///path/to/key", kmsURI.Host)
+	}
+	localPath, err := fileKMSLocalPath(env.ClusterSettings().ExternalIODir, kmsURI.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err := ioutil.ReadFile(localPath)
+	if err != nil {
+		return nil, errInvalidFileKMSKey
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(contents)))
+	if err != nil || len(key) != fileKMSKeyLength {
+		return nil, errInvalidFileKMSKey
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &fileKMS{path: kmsURI.Path, aead: aead}, nil
+}
+
+// fileKMSLocalPath resolves the path of a file KMS URI against the external
+// IO directory, refusing paths which would escape it.
+func fileKMSLocalPath(externalIODir, path string) (string, error) {
+	if externalIODir == "" {
+		return "", errors.New("file KMS requires an external IO directory; local file access is disabled")
+	}
+	dir, err := filepath.Abs(externalIODir)
+	if err != nil {
+		return "", err
+	}
+	localPath := filepath.Join(dir, path)
+	rel, err := filepath.Rel(dir, localPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.Errorf(
+			"file KMS key path must be within the external IO directory, got %q", path)
+	}
+	return localPath, nil
+}
+
+// MasterKeyID implements the KMS interface.
+func (k *fileKMS) MasterKeyID() (string, error) {
+	return k.path, nil
+}
+
+// Encrypt implements the KMS interface. The returned ciphertext is prefixed
+// with the randomly generated nonce used to seal it.
+func (k *fileKMS) Encrypt(ctx context.Context, data []byte) ([]byte, error) {
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return k.aead.Seal(nonce, nonce, data, nil), nil
+}
+
+// Decrypt implements the KMS interface.
+func (k *fileKMS) Decrypt(ctx context.Context, data []byte) ([]byte, error) {
+	nonceSize := k.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, errors.New("file KMS ciphertext is too short")
+	}
+	plaintext, err := k.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "file KMS decryption failed")
+	}
+	return plaintext, nil
+}
+
+// Close implements the KMS interface.
+func (k *fileKMS) Close() error {
+	return nil
+}
@@ -0,0 +1,196 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cloudimpl
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/errors"
+)
+
+const vaultScheme = "vault"
+
+// defaultVaultTransitMount is the path at which Vault mounts the transit
+// secrets engine unless configured otherwise.
+const defaultVaultTransitMount = "transit"
+
+// vaultKMS is a KMS backed by the transit secrets engine of a HashiCorp Vault
+// server, or any server exposing a compatible API.
+type vaultKMS struct {
+	client    *http.Client
+	endpoint  url.URL
+	token     string
+	namespace string
+	mount     string
+	keyName   string
+}
+
+var _ cloud.KMS = &vaultKMS{}
+
+func init() {
+	cloud.RegisterKMSFromURIFactory(MakeVaultKMS, vaultScheme)
+}
+
+// MakeVaultKMS is the factory method which returns a configured, ready-to-use
+// Vault transit KMS object. The URI is of the form
+// vault://host:port/key-name?VAULT_TOKEN=token, and requests are always sent
+// over HTTPS. The CA used to verify the server can be configured through the
+// cloudstorage.http.custom_ca cluster setting.
+func MakeVaultKMS(uri string, env cloud.KMSEnv) (cloud.KMS, error) {
+	kmsURI, err := url.ParseRequestURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	if env.KMSConfig().DisableHTTP {
+		return nil, errors.New(
+			"vault kms disallowed due to --external-io-disable-http flag")
+	}
+	if kmsURI.Host == "" {
+		return nil, errors.New("vault KMS URI must specify the vault server host")
+	}
+	keyName := strings.TrimPrefix(kmsURI.Path, "/")
+	if keyName == "" || strings.Contains(keyName, "/") {
+		return nil, errors.Errorf("invalid vault transit key name %q", keyName)
+	}
+
+	token := kmsURI.Query().Get(VaultTokenParam)
+	if token == "" {
+		return nil, errors.Errorf("%s must be set for vault kms", VaultTokenParam)
+	}
+	mount := strings.Trim(kmsURI.Query().Get(VaultMountParam), "/")
+	if mount == "" {
+		mount = defaultVaultTransitMount
+	}
+
+	client, err := makeHTTPClient(env.ClusterSettings())
+	if err != nil {
+		return nil, err
+	}
+	return &vaultKMS{
+		client:    client,
+		endpoint:  url.URL{Scheme: "https", Host: kmsURI.Host},
+		token:     token,
+		namespace: kmsURI.Query().Get(VaultNamespaceParam),
+		mount:     mount,
+		keyName:   keyName,
+	}, nil
+}
+
+// MasterKeyID implements the KMS interface. The ID does not include the
+// server address so that the same key can be reached through a different
+// address, e.g. after a failover, without breaking existing backups.
+func (k *vaultKMS) MasterKeyID() (string, error) {
+	return path.Join(k.mount, k.keyName), nil
+}
+
+type vaultEncryptRequest struct {
+	Plaintext string `json:"plaintext"`
+}
+
+type vaultDecryptRequest struct {
+	Ciphertext string `json:"ciphertext"`
+}
+
+type vaultTransitResponse struct {
+	Data struct {
+		Ciphertext string `json:"ciphertext"`
+		Plaintext  string `json:"plaintext"`
+	} `json:"data"`
+	Errors []string `json:"errors"`
+}
+
+// Encrypt implements the KMS interface. The returned ciphertext is the vault
+// ciphertext string, which embeds the version of the key used to produce it.
+func (k *vaultKMS) Encrypt(ctx context.Context, data []byte) ([]byte, error) {
+	resp, err := k.post(ctx, "encrypt",
+		vaultEncryptRequest{Plaintext: base64.StdEncoding.EncodeToString(data)})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Data.Ciphertext == "" {
+		return nil, errors.New("vault encrypt response did not contain a ciphertext")
+	}
+	return []byte(resp.Data.Ciphertext), nil
+}
+
+// Decrypt implements the KMS interface.
+func (k *vaultKMS) Decrypt(ctx context.Context, data []byte) ([]byte, error) {
+	resp, err := k.post(ctx, "decrypt", vaultDecryptRequest{Ciphertext: string(data)})
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(resp.Data.Plaintext)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding vault decrypt response")
+	}
+	return plaintext, nil
+}
+
+// post sends a request to the given transit operation for the configured key
+// and decodes the response.
+func (k *vaultKMS) post(
+	ctx context.Context, op string, body interface{},
+) (*vaultTransitResponse, error) {
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	u := k.endpoint
+	u.Path = path.Join("/v1", k.mount, op, k.keyName)
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Token", k.token)
+	if k.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", k.namespace)
+	}
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "vault %s", op)
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading vault %s response", op)
+	}
+
+	var transitResp vaultTransitResponse
+	if err := json.Unmarshal(respBody, &transitResp); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, errors.Wrapf(err, "decoding vault %s response", op)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if len(transitResp.Errors) > 0 {
+			return nil, errors.Errorf("vault %s failed with status %s: %s",
+				op, resp.Status, strings.Join(transitResp.Errors, "; "))
+		}
+		return nil, errors.Errorf("vault %s failed with status %s", op, resp.Status)
+	}
+	return &transitResp, nil
+}
+
+// Close implements the KMS interface.
+func (k *vaultKMS) Close() error {
+	k.client.CloseIdleConnections()
+	return nil
+}
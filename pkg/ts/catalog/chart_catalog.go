@@ -1006,6 +1006,12 @@ var charts = []sectionDescription{
 	{
 		Organization: [][]string{{ReplicationLayer, "Changefeed"}},
 		Charts: []chartDescription{
+			{
+				Title: "Buffer Pushback Time",
+				Metrics: []string{
+					"changefeed.buffer_pushback_nanos",
+				},
+			},
 			{
 				Title: "Emitted Bytes",
 				Metrics: []string{
@@ -1044,6 +1050,12 @@ var charts = []sectionDescription{
 					"changefeed.max_behind_nanos",
 				},
 			},
+			{
+				Title: "Memory Usage",
+				Metrics: []string{
+					"changefeed.mem.current",
+				},
+			},
 			{
 				Title: "Min High Water",
 				Metrics: []string{
@@ -2284,6 +2296,7 @@ var charts = []sectionDescription{
 				Metrics: []string{
 					"jobs.auto_create_stats.currently_running",
 					"jobs.backup.currently_running",
+					"jobs.backup_compaction.currently_running",
 					"jobs.changefeed.currently_running",
 					"jobs.create_stats.currently_running",
 					"jobs.import.currently_running",
@@ -2317,6 +2330,18 @@ var charts = []sectionDescription{
 				},
 				Rate: DescribeDerivative_NON_NEGATIVE_DERIVATIVE,
 			},
+			{
+				Title: "Backup Compaction",
+				Metrics: []string{
+					"jobs.backup_compaction.fail_or_cancel_completed",
+					"jobs.backup_compaction.fail_or_cancel_failed",
+					"jobs.backup_compaction.fail_or_cancel_retry_error",
+					"jobs.backup_compaction.resume_completed",
+					"jobs.backup_compaction.resume_failed",
+					"jobs.backup_compaction.resume_retry_error",
+				},
+				Rate: DescribeDerivative_NON_NEGATIVE_DERIVATIVE,
+			},
 			{
 				Title: "Changefeed",
 				Metrics: []string{
@@ -193,6 +193,15 @@ type BytesMonitor struct {
 		// maxBytesHist is the metric object used to track the high watermark of bytes
 		// allocated by the monitor during its lifetime.
 		maxBytesHist *metric.Histogram
+
+		// limit specifies a hard limit on the number of bytes a monitor allows
+		// to be allocated. Note that this limit will not be observed if
+		// allocations hit constraints on the owner monitor. This is useful to
+		// limit allocations when an owner monitor has a larger capacity than
+		// wanted but should still keep track of allocations made through this
+		// monitor. Note that child monitors are affected by this limit. It is
+		// protected by the mutex because it can be changed by SetLimit.
+		limit int64
 	}
 
 	// name identifies this monitor in logging messages.
@@ -211,14 +220,6 @@ type BytesMonitor struct {
 	// upon Stop.
 	reserved BoundAccount
 
-	// limit specifies a hard limit on the number of bytes a monitor allows to
-	// be allocated. Note that this limit will not be observed if allocations
-	// hit constraints on the owner monitor. This is useful to limit allocations
-	// when an owner monitor has a larger capacity than wanted but should still
-	// keep track of allocations made through this monitor. Note that child
-	// monitors are affected by this limit.
-	limit int64
-
 	// poolAllocationSize specifies the allocation unit for requests to the
 	// pool.
 	poolAllocationSize int64
@@ -295,13 +296,13 @@ func NewMonitorWithLimit(
 	m := &BytesMonitor{
 		name:                 name,
 		resource:             res,
-		limit:                limit,
 		noteworthyUsageBytes: noteworthy,
 		poolAllocationSize:   increment,
 		settings:             settings,
 	}
 	m.mu.curBytesCount = curCount
 	m.mu.maxBytesHist = maxHist
+	m.mu.limit = limit
 	return m
 }
 
@@ -322,6 +323,33 @@ func NewMonitorInheritWithLimit(name string, limit int64, m *BytesMonitor) *Byte
 	)
 }
 
+// SetLimit changes the limit local to this monitor. Allocations which are
+// already registered are not affected, even if they exceed the new limit. A
+// limit of 0 or lower removes the limit.
+func (mm *BytesMonitor) SetLimit(limit int64) {
+	if limit <= 0 {
+		limit = math.MaxInt64
+	}
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	mm.mu.limit = limit
+}
+
+// AllocationExceedsLimit returns whether an allocation of the given size would
+// be refused by this monitor even if nothing else was allocated, because it
+// exceeds the limit local to the monitor or, if the monitor has no pool, its
+// pre-reserved budget. Otherwise, the allocation may succeed once other
+// allocations from this monitor or its pool are released.
+func (mm *BytesMonitor) AllocationExceedsLimit(x int64) bool {
+	x = mm.roundSize(x)
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	if mm.mu.curBudget.mon == nil && x > mm.reserved.used {
+		return true
+	}
+	return x > mm.mu.limit
+}
+
 // Start begins a monitoring region.
 // Arguments:
 // - pool is the upstream monitor that provision allocations exceeding the
@@ -370,7 +398,6 @@ func NewUnlimitedMonitor(
 	m := &BytesMonitor{
 		name:                 name,
 		resource:             res,
-		limit:                math.MaxInt64,
 		noteworthyUsageBytes: noteworthy,
 		poolAllocationSize:   DefaultPoolAllocationSize,
 		reserved:             MakeStandaloneBudget(math.MaxInt64),
@@ -378,6 +405,7 @@ func NewUnlimitedMonitor(
 	}
 	m.mu.curBytesCount = curCount
 	m.mu.maxBytesHist = maxHist
+	m.mu.limit = math.MaxInt64
 	return m
 }
 
@@ -604,13 +632,13 @@ func (mm *BytesMonitor) reserveBytes(ctx context.Context, x int64) error {
 	defer mm.mu.Unlock()
 	// Check the local limit first. NB: The condition is written in this manner
 	// so that it handles overflow correctly. Consider what happens if
-	// x==math.MaxInt64. mm.limit-x will be a large negative number.
+	// x==math.MaxInt64. mm.mu.limit-x will be a large negative number.
 	//
 	// TODO(knz): make the monitor name reportable in telemetry, after checking
 	// that the name is never constructed from user data.
-	if mm.mu.curAllocated > mm.limit-x {
+	if mm.mu.curAllocated > mm.mu.limit-x {
 		return errors.Wrapf(
-			mm.resource.NewBudgetExceededError(x, mm.mu.curAllocated, mm.limit), "%s", mm.name,
+			mm.resource.NewBudgetExceededError(x, mm.mu.curAllocated, mm.mu.limit), "%s", mm.name,
 		)
 	}
 	// Check whether we need to request an increase of our budget.
@@ -321,7 +321,40 @@ func TestBytesMonitor(t *testing.T) {
 	if err := limitedMonitor.reserveBytes(ctx, 1); err == nil {
 		t.Fatal("limited monitor allowed allocation over limit")
 	}
+
+	// Allocations are only bounded by the monitor itself if they exceed its
+	// limit, or its reserved budget if it has no pool.
+	for _, tc := range []struct {
+		m        *BytesMonitor
+		x        int64
+		expected bool
+	}{
+		{m, 100, false},
+		{m, 101, true},
+		{limitedMonitor, 10, false},
+		{limitedMonitor, 11, true},
+	} {
+		if res := tc.m.AllocationExceedsLimit(tc.x); res != tc.expected {
+			t.Fatalf("%s: AllocationExceedsLimit(%d) = %t, expected %t", tc.m.name, tc.x, res, tc.expected)
+		}
+	}
+
+	// Raising the limit allows more allocations, lowering it doesn't affect
+	// the existing ones.
+	limitedMonitor.SetLimit(20)
+	if err := limitedMonitor.reserveBytes(ctx, 10); err != nil {
+		t.Fatalf("limited monitor refused allocation under raised limit: %v", err)
+	}
+	limitedMonitor.SetLimit(15)
+	if err := limitedMonitor.reserveBytes(ctx, 1); err == nil {
+		t.Fatal("limited monitor allowed allocation over lowered limit")
+	}
 	limitedMonitor.releaseBytes(ctx, 10)
+	limitedMonitor.SetLimit(0)
+	if err := limitedMonitor.reserveBytes(ctx, 80); err != nil {
+		t.Fatalf("unlimited monitor refused allocation: %v", err)
+	}
+	limitedMonitor.releaseBytes(ctx, 90)
 
 	limitedMonitor.Stop(ctx)
 	m.Stop(ctx)